@@ -16,6 +16,8 @@ import (
 	"google.golang.org/protobuf/types/known/timestamppb"
 
 	rdkcli "go.viam.com/rdk/cli"
+	"go.viam.com/rdk/services/datamanager/datacapture"
+	"go.viam.com/rdk/services/datamanager/datacapture/export"
 )
 
 const (
@@ -38,6 +40,11 @@ const (
 
 	dataTypeBinary  = "binary"
 	dataTypeTabular = "tabular"
+
+	exportFlagSource      = "source"
+	exportFlagDestination = "destination"
+	exportFlagFormat      = "format"
+	exportFlagKey         = "encryption_key"
 )
 
 func main() {
@@ -296,6 +303,53 @@ func main() {
 				},
 				Action: DataCommand,
 			},
+			{
+				Name:  "export",
+				Usage: "export local data capture files to open formats",
+				UsageText: fmt.Sprintf("viam export <%s> <%s> [%s]",
+					exportFlagSource, exportFlagDestination, exportFlagFormat),
+				Flags: []cli.Flag{
+					&cli.StringFlag{
+						Name:     exportFlagSource,
+						Required: true,
+						Usage:    "data capture file or directory of them, such as the capture directory of the data manager",
+					},
+					&cli.StringFlag{
+						Name:     exportFlagDestination,
+						Required: true,
+						Usage:    "output directory for exported data",
+					},
+					&cli.StringFlag{
+						Name:  exportFlagFormat,
+						Value: string(export.FormatCSV),
+						Usage: "format tabular data is exported to: csv, jsonl or parquet. Images and point clouds are exported to their own files",
+					},
+					&cli.StringFlag{
+						Name:  exportFlagKey,
+						Usage: "hex encoded encryption key of the data manager, to export encrypted data capture files",
+					},
+				},
+				Action: func(c *cli.Context) error {
+					var opts []datacapture.FileOption
+					if c.String(exportFlagKey) != "" {
+						key, err := datacapture.ParseEncryptionKey(c.String(exportFlagKey))
+						if err != nil {
+							return err
+						}
+						opts = append(opts, datacapture.WithDecryptionKey(key))
+					}
+					if err := export.Export(
+						c.String(exportFlagSource),
+						c.String(exportFlagDestination),
+						export.Format(c.String(exportFlagFormat)),
+						opts...,
+					); err != nil {
+						return err
+					}
+					fmt.Fprintf(c.App.Writer, "Exported %s to %s\n", c.String(exportFlagSource), c.String(exportFlagDestination))
+					return nil
+				},
+			},
 			{
 				Name:  "robots",
 				Usage: "work with robots",
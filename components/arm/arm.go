@@ -109,6 +109,17 @@ type LocalArm interface {
 	resource.MovingCheckable
 }
 
+// A TrajectoryFollower is an arm that can be streamed a trajectory of timestamped joint positions, which it follows without
+// stopping at each of them the way it would if it were sent them one at a time through GoToInputs.
+type TrajectoryFollower interface {
+	// JointLimits returns the limits on the velocity, acceleration and jerk of each of the arm's joints, in the units of its inputs.
+	JointLimits(ctx context.Context) ([]motionplan.JointLimit, error)
+
+	// FollowTrajectory moves the arm's joints along the given trajectory, which starts at their current positions.
+	// This will block until done or a new operation cancels this one
+	FollowTrajectory(ctx context.Context, trajectory motionplan.Trajectory) error
+}
+
 var (
 	_ = Arm(&reconfigurableArm{})
 	_ = LocalArm(&reconfigurableLocalArm{})
@@ -351,11 +362,24 @@ func WrapWithReconfigurable(r interface{}, name resource.Name) (resource.Reconfi
 
 // Move is a helper function to abstract away movement for general arms.
 func Move(ctx context.Context, r robot.Robot, a Arm, dst spatialmath.Pose, worldState *commonpb.WorldState) error {
-	solution, err := Plan(ctx, r, a, dst, worldState)
+	solution, fs, err := plan(ctx, r, a, dst, worldState)
+	if err != nil {
+		return err
+	}
+	follower, ok := utils.UnwrapProxy(a).(TrajectoryFollower)
+	if !ok || fs == nil {
+		return GoToWaypoints(ctx, a, solution)
+	}
+	seedMap, _, err := framesystem.RobotFsCurrentInputs(ctx, r, fs)
 	if err != nil {
 		return err
 	}
-	return GoToWaypoints(ctx, a, solution)
+	armFrame := fs.Frame(a.ModelFrame().Name())
+	return followWaypoints(ctx, a, follower, solution, func(current []referenceframe.Input, trajectory motionplan.Trajectory) error {
+		seedMap[armFrame.Name()] = current
+		trajectories := map[string]motionplan.Trajectory{armFrame.Name(): trajectory}
+		return motionplan.CheckTrajectories(armFrame, seedMap, trajectories, fs, worldState, r.Logger())
+	})
 }
 
 // Plan is a helper function to be called by arm implementations to abstract away the default procedure for using the
@@ -367,10 +391,23 @@ func Plan(
 	dst spatialmath.Pose,
 	worldState *commonpb.WorldState,
 ) ([][]referenceframe.Input, error) {
+	solution, _, err := plan(ctx, r, a, dst, worldState)
+	return solution, err
+}
+
+// plan returns the waypoints of Plan along with the frame system they were planned in, which is nil if the arm is not part of the
+// frame system of the robot.
+func plan(
+	ctx context.Context,
+	r robot.Robot,
+	a Arm,
+	dst spatialmath.Pose,
+	worldState *commonpb.WorldState,
+) ([][]referenceframe.Input, referenceframe.FrameSystem, error) {
 	// build the framesystem
 	fs, err := framesystem.RobotFrameSystem(ctx, r, worldState.GetTransforms())
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 	armName := a.ModelFrame().Name()
 	destination := referenceframe.NewPoseInFrame(armName+"_origin", dst)
@@ -379,25 +416,31 @@ func Plan(
 	if fs.Frame(armName) == nil {
 		if worldState != nil {
 			if len(worldState.Obstacles) != 0 || len(worldState.InteractionSpaces) != 0 || len(worldState.Transforms) != 0 {
-				return nil, errors.New("arm must be in frame system to use worldstate")
+				return nil, nil, errors.New("arm must be in frame system to use worldstate")
 			}
 		}
 		armFrame := a.ModelFrame()
 		jp, err := a.JointPositions(ctx, nil)
 		if err != nil {
-			return nil, err
+			return nil, nil, err
 		}
-		return motionplan.PlanFrameMotion(ctx, r.Logger(), dst, armFrame, armFrame.InputFromProtobuf(jp), defaultArmPlannerOptions)
+		solution, err := motionplan.PlanFrameMotion(ctx, r.Logger(), dst, armFrame, armFrame.InputFromProtobuf(jp), defaultArmPlannerOptions)
+		return solution, nil, err
 	}
 	solutionMap, err := motionplan.PlanRobotMotion(ctx, destination, a.ModelFrame(), r, fs, worldState, defaultArmPlannerOptions)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
-	return motionplan.FrameStepsFromRobotPath(a.ModelFrame().Name(), solutionMap)
+	solution, err := motionplan.FrameStepsFromRobotPath(a.ModelFrame().Name(), solutionMap)
+	return solution, fs, err
 }
 
-// GoToWaypoints will visit in turn each of the joint position waypoints generated by a motion planner.
+// GoToWaypoints will visit in turn each of the joint position waypoints generated by a motion planner. Arms that are
+// TrajectoryFollowers are instead streamed a trajectory through the waypoints, so that they do not stop at each of them.
 func GoToWaypoints(ctx context.Context, a Arm, waypoints [][]referenceframe.Input) error {
+	if follower, ok := utils.UnwrapProxy(a).(TrajectoryFollower); ok {
+		return FollowWaypoints(ctx, a, follower, waypoints)
+	}
 	for _, waypoint := range waypoints {
 		err := ctx.Err() // make sure we haven't been cancelled
 		if err != nil {
@@ -411,3 +454,57 @@ func GoToWaypoints(ctx context.Context, a Arm, waypoints [][]referenceframe.Inpu
 	}
 	return nil
 }
+
+// FollowWaypoints streams the given trajectory follower a trajectory from the current position of the arm through the joint
+// position waypoints generated by a motion planner, which is as fast as the limits of the arm's joints allow. The trajectory cuts
+// the corners at the waypoints, which the planner did not check, so unless it keeps the arm clear of itself the arm is streamed a
+// trajectory to each waypoint in turn instead. Those keep to the straight lines between the waypoints.
+func FollowWaypoints(ctx context.Context, a Arm, follower TrajectoryFollower, waypoints [][]referenceframe.Input) error {
+	return followWaypoints(ctx, a, follower, waypoints, func(current []referenceframe.Input, trajectory motionplan.Trajectory) error {
+		model := a.ModelFrame()
+		fs := referenceframe.NewEmptySimpleFrameSystem("")
+		if err := fs.AddFrame(model, fs.World()); err != nil {
+			return err
+		}
+		seedMap := map[string][]referenceframe.Input{model.Name(): current}
+		trajectories := map[string]motionplan.Trajectory{model.Name(): trajectory}
+		return motionplan.CheckTrajectories(model, seedMap, trajectories, fs, nil, golog.Global())
+	})
+}
+
+// followWaypoints streams the follower a trajectory through the waypoints if check passes it, or else a trajectory to each of
+// the waypoints in turn.
+func followWaypoints(
+	ctx context.Context,
+	a Arm,
+	follower TrajectoryFollower,
+	waypoints [][]referenceframe.Input,
+	check func(current []referenceframe.Input, trajectory motionplan.Trajectory) error,
+) error {
+	current, err := a.CurrentInputs(ctx)
+	if err != nil {
+		return err
+	}
+	limits, err := follower.JointLimits(ctx)
+	if err != nil {
+		return err
+	}
+	trajectory, err := motionplan.NewTrajectory(append([][]referenceframe.Input{current}, waypoints...), limits, motionplan.TrajectoryOptions{})
+	if err != nil {
+		return err
+	}
+	if len(waypoints) < 2 || check(current, trajectory) == nil {
+		return follower.FollowTrajectory(ctx, trajectory)
+	}
+	for _, waypoint := range waypoints {
+		trajectory, err := motionplan.NewTrajectory([][]referenceframe.Input{current, waypoint}, limits, motionplan.TrajectoryOptions{})
+		if err != nil {
+			return err
+		}
+		if err := follower.FollowTrajectory(ctx, trajectory); err != nil {
+			return err
+		}
+		current = waypoint
+	}
+	return nil
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"testing"
 
+	"github.com/edaniels/golog"
 	"github.com/golang/geo/r3"
 	"github.com/mitchellh/mapstructure"
 	"github.com/pkg/errors"
@@ -13,7 +14,12 @@ import (
 	"go.viam.com/utils/protoutils"
 
 	"go.viam.com/rdk/components/arm"
+	fakearm "go.viam.com/rdk/components/arm/fake"
+	"go.viam.com/rdk/components/arm/xarm"
 	"go.viam.com/rdk/components/sensor"
+	"go.viam.com/rdk/config"
+	"go.viam.com/rdk/motionplan"
+	"go.viam.com/rdk/referenceframe"
 	"go.viam.com/rdk/registry"
 	"go.viam.com/rdk/resource"
 	"go.viam.com/rdk/spatialmath"
@@ -393,3 +399,75 @@ func (m *mockLocal) Close(ctx context.Context) error { m.reconfCount++; return n
 func (m *mockLocal) DoCommand(ctx context.Context, cmd map[string]interface{}) (map[string]interface{}, error) {
 	return cmd, nil
 }
+
+// recordingFollower records the trajectories it is streamed.
+type recordingFollower struct {
+	trajectories []motionplan.Trajectory
+}
+
+func (f *recordingFollower) JointLimits(ctx context.Context) ([]motionplan.JointLimit, error) {
+	limits := make([]motionplan.JointLimit, 6)
+	for i := range limits {
+		limits[i] = motionplan.JointLimit{MaxVelocity: 1, MaxAcceleration: 1}
+	}
+	return limits, nil
+}
+
+func (f *recordingFollower) FollowTrajectory(ctx context.Context, trajectory motionplan.Trajectory) error {
+	f.trajectories = append(f.trajectories, trajectory)
+	return nil
+}
+
+func TestFollowWaypoints(t *testing.T) {
+	ctx := context.Background()
+	a, err := fakearm.NewArm(config.Component{
+		Name:                testArmName,
+		ConvertedAttributes: &fakearm.AttrConfig{ArmModel: xarm.ModelName6DOF},
+	}, golog.NewTestLogger(t))
+	test.That(t, err, test.ShouldBeNil)
+	current, err := a.CurrentInputs(ctx)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, current, test.ShouldHaveLength, 6)
+	waypoints := [][]referenceframe.Input{
+		referenceframe.FloatsToInputs([]float64{0.5, 0, 0, 0, 0, 0}),
+		referenceframe.FloatsToInputs([]float64{0.5, 0.5, 0, 0, 0, 0}),
+	}
+
+	// a trajectory that passes the check is streamed through all the waypoints at once
+	follower := &recordingFollower{}
+	var checked motionplan.Trajectory
+	err = arm.FollowWaypointsWithCheck(ctx, a, follower, waypoints,
+		func(start []referenceframe.Input, trajectory motionplan.Trajectory) error {
+			test.That(t, start, test.ShouldResemble, current)
+			checked = trajectory
+			return nil
+		})
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, follower.trajectories, test.ShouldHaveLength, 1)
+	test.That(t, follower.trajectories[0], test.ShouldResemble, checked)
+
+	// one that cuts a corner into a collision is replaced by a trajectory to each of the waypoints in turn, each of which keeps to
+	// the straight line to its waypoint
+	follower = &recordingFollower{}
+	err = arm.FollowWaypointsWithCheck(ctx, a, follower, waypoints,
+		func(start []referenceframe.Input, trajectory motionplan.Trajectory) error {
+			return errors.New("collides")
+		})
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, follower.trajectories, test.ShouldHaveLength, len(waypoints))
+	from := current
+	for i, trajectory := range follower.trajectories {
+		to := waypoints[i]
+		test.That(t, trajectory[0].Inputs, test.ShouldResemble, from)
+		test.That(t, trajectory[len(trajectory)-1].Inputs, test.ShouldResemble, to)
+		for _, point := range trajectory {
+			// every point lies on the line between the waypoints
+			for j := range point.Inputs {
+				if to[j].Value == from[j].Value {
+					test.That(t, point.Inputs[j].Value, test.ShouldAlmostEqual, from[j].Value)
+				}
+			}
+		}
+		from = to
+	}
+}
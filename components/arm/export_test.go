@@ -0,0 +1,5 @@
+// export_test.go adds functionality to the arm package that we only want to use and expose during testing.
+package arm
+
+// FollowWaypointsWithCheck exposes followWaypoints, which streams a trajectory through the waypoints only if it passes the check.
+var FollowWaypointsWithCheck = followWaypoints
@@ -3,6 +3,7 @@ package fake
 
 import (
 	"context"
+	"math"
 	// for arm model.
 	_ "embed"
 
@@ -27,6 +28,12 @@ import (
 // ModelName is the string used to refer to the fake arm model.
 const ModelName = "fake"
 
+// the joint limits reported by a fake arm, in radians per second and radians per second squared.
+const (
+	fakeMaxJointVelocity     = math.Pi
+	fakeMaxJointAcceleration = 2 * math.Pi
+)
+
 //go:embed fake_model.json
 var fakeModelJSON []byte
 
@@ -159,6 +166,23 @@ func (a *Arm) MoveToJointPositions(ctx context.Context, joints *pb.JointPosition
 	return nil
 }
 
+// JointLimits returns the same velocity and acceleration limits for every joint, with no limit on jerk.
+func (a *Arm) JointLimits(ctx context.Context) ([]motionplan.JointLimit, error) {
+	limits := make([]motionplan.JointLimit, len(a.model.DoF()))
+	for i := range limits {
+		limits[i] = motionplan.JointLimit{MaxVelocity: fakeMaxJointVelocity, MaxAcceleration: fakeMaxJointAcceleration}
+	}
+	return limits, nil
+}
+
+// FollowTrajectory sets the joints to the end of the trajectory.
+func (a *Arm) FollowTrajectory(ctx context.Context, trajectory motionplan.Trajectory) error {
+	if len(trajectory) == 0 {
+		return nil
+	}
+	return a.GoToInputs(ctx, trajectory[len(trajectory)-1].Inputs)
+}
+
 // JointPositions returns joints.
 func (a *Arm) JointPositions(ctx context.Context, extra map[string]interface{}) (*pb.JointPositions, error) {
 	retJoint := &pb.JointPositions{Values: a.joints.Values}
@@ -25,6 +25,7 @@ const ModelName = "wrapper_arm"
 
 // AttrConfig is used for converting config attributes.
 type AttrConfig struct {
+	// ModelPath is a kinematics file in the JSON format or, if it ends in .urdf or .xml, in URDF.
 	ModelPath string `json:"model-path"`
 	ArmName   string `json:"arm-name"`
 }
@@ -68,7 +69,7 @@ type Arm struct {
 
 // NewWrapperArm returns a wrapper component for another arm.
 func NewWrapperArm(cfg config.Component, r robot.Robot, logger golog.Logger) (arm.LocalArm, error) {
-	model, err := referenceframe.ParseModelJSONFile(cfg.ConvertedAttributes.(*AttrConfig).ModelPath, cfg.Name)
+	model, err := referenceframe.ParseModelFile(cfg.ConvertedAttributes.(*AttrConfig).ModelPath, cfg.Name)
 	if err != nil {
 		return nil, err
 	}
@@ -360,23 +360,7 @@ func (x *xArm) MoveToJointPositions(ctx context.Context, newPositions *pb.JointP
 	nSteps := int((diff / float64(x.speed)) * x.moveHZ)
 	for i := 1; i <= nSteps; i++ {
 		step := referenceframe.InputsToFloats(referenceframe.InterpolateInputs(from, to, float64(i)/float64(nSteps)))
-
-		c := x.newCmd(regMap["MoveJoints"])
-		jFloatBytes := make([]byte, 4)
-		for _, jRad := range step {
-			binary.LittleEndian.PutUint32(jFloatBytes, math.Float32bits(float32(jRad)))
-			c.params = append(c.params, jFloatBytes...)
-		}
-		// xarm 6 has 6 joints, but protocol needs 7- add 4 bytes for a blank 7th joint
-		for dof := x.dof; dof < 7; dof++ {
-			c.params = append(c.params, 0, 0, 0, 0)
-		}
-		// When in servoj mode, motion time, speed, and acceleration are not handled by the control box
-		c.params = append(c.params, 0, 0, 0, 0)
-		c.params = append(c.params, 0, 0, 0, 0)
-		c.params = append(c.params, 0, 0, 0, 0)
-		_, err := x.send(ctx, c, true)
-		if err != nil {
+		if err := x.moveJoints(ctx, step); err != nil {
 			return err
 		}
 		if !utils.SelectContextOrWait(ctx, time.Duration(1000000./x.moveHZ)*time.Microsecond) {
@@ -386,6 +370,61 @@ func (x *xArm) MoveToJointPositions(ctx context.Context, newPositions *pb.JointP
 	return nil
 }
 
+// JointLimits returns the configured speed and acceleration as the limits of every joint, the xArm does not limit jerk.
+func (x *xArm) JointLimits(ctx context.Context) ([]motionplan.JointLimit, error) {
+	limits := make([]motionplan.JointLimit, x.dof)
+	for i := range limits {
+		limits[i] = motionplan.JointLimit{MaxVelocity: float64(x.speed), MaxAcceleration: float64(x.accel)}
+	}
+	return limits, nil
+}
+
+// FollowTrajectory streams the joint positions along the trajectory to the arm at its move rate.
+func (x *xArm) FollowTrajectory(ctx context.Context, trajectory motionplan.Trajectory) error {
+	ctx, done := x.opMgr.New(ctx)
+	defer done()
+	if !x.started {
+		if err := x.start(ctx); err != nil {
+			return err
+		}
+	}
+	period := time.Duration(1000000./x.moveHZ) * time.Microsecond
+	start := time.Now()
+	for i := 1; ; i++ {
+		// the positions are sent at a fixed rate from the start, so that the time taken to send them does not add up
+		elapsed := time.Duration(i) * period
+		if !utils.SelectContextOrWait(ctx, time.Until(start.Add(elapsed-period))) {
+			return ctx.Err()
+		}
+		if err := x.moveJoints(ctx, referenceframe.InputsToFloats(trajectory.At(elapsed).Inputs)); err != nil {
+			return err
+		}
+		if elapsed >= trajectory.Duration() {
+			return nil
+		}
+	}
+}
+
+// moveJoints sends the arm a single set of joint positions, in radians, which in servo mode it moves to as fast as it can.
+func (x *xArm) moveJoints(ctx context.Context, joints []float64) error {
+	c := x.newCmd(regMap["MoveJoints"])
+	jFloatBytes := make([]byte, 4)
+	for _, jRad := range joints {
+		binary.LittleEndian.PutUint32(jFloatBytes, math.Float32bits(float32(jRad)))
+		c.params = append(c.params, jFloatBytes...)
+	}
+	// xarm 6 has 6 joints, but protocol needs 7- add 4 bytes for a blank 7th joint
+	for dof := x.dof; dof < 7; dof++ {
+		c.params = append(c.params, 0, 0, 0, 0)
+	}
+	// When in servoj mode, motion time, speed, and acceleration are not handled by the control box
+	c.params = append(c.params, 0, 0, 0, 0)
+	c.params = append(c.params, 0, 0, 0, 0)
+	c.params = append(c.params, 0, 0, 0, 0)
+	_, err := x.send(ctx, c, true)
+	return err
+}
+
 // EndPosition computes and returns the current cartesian position.
 func (x *xArm) EndPosition(ctx context.Context, extra map[string]interface{}) (spatialmath.Pose, error) {
 	joints, err := x.JointPositions(ctx, extra)
@@ -16,6 +16,7 @@ import (
 
 	"go.viam.com/rdk/components/generic"
 	"go.viam.com/rdk/config"
+	"go.viam.com/rdk/data"
 	"go.viam.com/rdk/registry"
 	"go.viam.com/rdk/resource"
 	"go.viam.com/rdk/robot"
@@ -40,7 +41,10 @@ func init() {
 		},
 	})
 
-	// TODO(RSDK-562): Add RegisterCollector
+	data.RegisterCollector(data.MethodMetadata{
+		Subtype:    SubtypeName,
+		MethodName: chunks.String(),
+	}, newChunksCollector)
 }
 
 // SubtypeName is a constant that identifies the audio input resource subtype string.
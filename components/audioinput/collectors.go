@@ -0,0 +1,223 @@
+package audioinput
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"sync"
+	"time"
+
+	"github.com/pion/mediadevices/pkg/wave"
+	"github.com/pkg/errors"
+	viamutils "go.viam.com/utils"
+	"google.golang.org/protobuf/types/known/anypb"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+
+	"go.viam.com/rdk/data"
+)
+
+type method int64
+
+const (
+	chunks method = iota
+)
+
+func (m method) String() string {
+	if m == chunks {
+		return "Chunks"
+	}
+	return "Unknown"
+}
+
+// The formats audio is captured in, chosen by the format method parameter. Both are 16 bit PCM, but WAV has a header
+// describing the channels and sampling rate of the audio so that it can be played as is.
+const (
+	formatParam = "format"
+	formatWAV   = "wav"
+	formatPCM   = "pcm"
+)
+
+// maxRecorded is the most audio that is held between captures. Older audio is dropped.
+const maxRecorded = time.Minute
+
+// newChunksCollector captures all audio since the previous capture, so that the captured chunks add up to a
+// continuous recording.
+func newChunksCollector(resource interface{}, params data.CollectorParams) (data.Collector, error) {
+	audioInput, err := assertAudioInput(resource)
+	if err != nil {
+		return nil, err
+	}
+	format := formatWAV
+	if param, ok := params.MethodParams[formatParam]; ok && param != nil {
+		formatStr := new(wrapperspb.StringValue)
+		if err := param.UnmarshalTo(formatStr); err != nil {
+			return nil, err
+		}
+		format = formatStr.Value
+	}
+	if format != formatWAV && format != formatPCM {
+		return nil, errors.Errorf("audio format must be %s or %s, got %q", formatWAV, formatPCM, format)
+	}
+
+	recorder := &audioRecorder{input: audioInput, recorded: make(chan struct{}, 1)}
+	cFunc := data.CaptureFunc(func(ctx context.Context, _ map[string]*anypb.Any) (interface{}, error) {
+		samples, info, err := recorder.next(ctx)
+		if err != nil {
+			return nil, data.FailedToReadErr(params.ComponentName, chunks.String(), err)
+		}
+		if format == formatPCM {
+			return encodePCM(samples), nil
+		}
+		return encodeWAV(samples, info), nil
+	})
+	return data.NewCollector(cFunc, params)
+}
+
+// audioRecorder continuously reads the audio of an audio input, and holds it until it is taken.
+type audioRecorder struct {
+	input AudioInput
+
+	mu        sync.Mutex
+	recording bool
+	samples   []int16
+	info      wave.ChunkInfo
+	err       error
+	// recorded is signaled when samples are recorded or recording fails.
+	recorded chan struct{}
+}
+
+// next returns the interleaved samples recorded since it was last called, waiting for some if there are none. The
+// recording starts on the first call and lasts until ctx is done, so ctx should be that of the collector rather
+// than of a single capture.
+func (r *audioRecorder) next(ctx context.Context) ([]int16, wave.ChunkInfo, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if !r.recording {
+		if err := r.startLocked(ctx); err != nil {
+			return nil, wave.ChunkInfo{}, err
+		}
+	}
+	for len(r.samples) == 0 {
+		if r.err != nil {
+			err := r.err
+			r.err = nil
+			return nil, wave.ChunkInfo{}, err
+		}
+		r.mu.Unlock()
+		select {
+		case <-ctx.Done():
+			r.mu.Lock()
+			return nil, wave.ChunkInfo{}, ctx.Err()
+		case <-r.recorded:
+		}
+		r.mu.Lock()
+	}
+	samples := r.samples
+	r.samples = nil
+	return samples, r.info, nil
+}
+
+func (r *audioRecorder) startLocked(ctx context.Context) error {
+	stream, err := r.input.Stream(ctx)
+	if err != nil {
+		return err
+	}
+	r.recording = true
+	viamutils.PanicCapturingGo(func() {
+		defer func() {
+			viamutils.UncheckedError(stream.Close(context.Background()))
+		}()
+		for {
+			chunk, release, err := stream.Next(ctx)
+			r.mu.Lock()
+			if err != nil {
+				// Restart the recording on the next capture.
+				r.recording = false
+				if ctx.Err() == nil {
+					r.err = err
+				}
+				r.mu.Unlock()
+				r.signal()
+				return
+			}
+			r.appendLocked(chunk)
+			r.mu.Unlock()
+			release()
+			r.signal()
+		}
+	})
+	return nil
+}
+
+// appendLocked converts chunk to 16 bit samples and appends them to the recording.
+func (r *audioRecorder) appendLocked(chunk wave.Audio) {
+	info := chunk.ChunkInfo()
+	if info.Channels != r.info.Channels || info.SamplingRate != r.info.SamplingRate {
+		// Samples of different formats cannot be in the same capture.
+		r.samples = nil
+	}
+	r.info = wave.ChunkInfo{Channels: info.Channels, SamplingRate: info.SamplingRate}
+	for i := 0; i < info.Len; i++ {
+		for ch := 0; ch < info.Channels; ch++ {
+			r.samples = append(r.samples, int16(wave.Int16SampleFormat.Convert(chunk.At(i, ch)).(wave.Int16Sample)))
+		}
+	}
+	if maxSamples := int(maxRecorded.Seconds()) * info.SamplingRate * info.Channels; len(r.samples) > maxSamples {
+		r.samples = append(r.samples[:0], r.samples[len(r.samples)-maxSamples:]...)
+	}
+}
+
+func (r *audioRecorder) signal() {
+	select {
+	case r.recorded <- struct{}{}:
+	default:
+	}
+}
+
+// encodePCM encodes samples as little endian 16 bit PCM.
+func encodePCM(samples []int16) []byte {
+	var buf bytes.Buffer
+	buf.Grow(len(samples) * 2)
+	//nolint:errcheck
+	binary.Write(&buf, binary.LittleEndian, samples)
+	return buf.Bytes()
+}
+
+// encodeWAV encodes samples as a 16 bit PCM WAV file.
+func encodeWAV(samples []int16, info wave.ChunkInfo) []byte {
+	const headerSize, bitsPerSample = 44, 16
+	dataSize := len(samples) * 2
+	blockAlign := info.Channels * bitsPerSample / 8
+
+	var buf bytes.Buffer
+	buf.Grow(headerSize + dataSize)
+	buf.WriteString("RIFF")
+	//nolint:errcheck
+	binary.Write(&buf, binary.LittleEndian, uint32(headerSize-8+dataSize))
+	buf.WriteString("WAVEfmt ")
+	for _, v := range []interface{}{
+		uint32(16), // size of the fmt chunk
+		uint16(1),  // PCM
+		uint16(info.Channels),
+		uint32(info.SamplingRate),
+		uint32(info.SamplingRate * blockAlign), // bytes per second
+		uint16(blockAlign),
+		uint16(bitsPerSample),
+	} {
+		//nolint:errcheck
+		binary.Write(&buf, binary.LittleEndian, v)
+	}
+	buf.WriteString("data")
+	//nolint:errcheck
+	binary.Write(&buf, binary.LittleEndian, uint32(dataSize))
+	buf.Write(encodePCM(samples))
+	return buf.Bytes()
+}
+
+func assertAudioInput(resource interface{}) (AudioInput, error) {
+	audioInput, ok := resource.(AudioInput)
+	if !ok {
+		return nil, data.InvalidInterfaceErr(SubtypeName)
+	}
+	return audioInput, nil
+}
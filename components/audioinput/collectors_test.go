@@ -0,0 +1,74 @@
+package audioinput
+
+import (
+	"context"
+	"encoding/binary"
+	"testing"
+
+	"github.com/edaniels/gostream"
+	"github.com/pion/mediadevices/pkg/wave"
+	"go.viam.com/test"
+)
+
+// chunkStream returns its chunks in order and then blocks until its context is done.
+type chunkStream struct {
+	chunks []wave.Audio
+	closed bool
+}
+
+func (s *chunkStream) Next(ctx context.Context) (wave.Audio, func(), error) {
+	if len(s.chunks) == 0 {
+		<-ctx.Done()
+		return nil, nil, ctx.Err()
+	}
+	chunk := s.chunks[0]
+	s.chunks = s.chunks[1:]
+	return chunk, func() {}, nil
+}
+
+func (s *chunkStream) Close(ctx context.Context) error {
+	s.closed = true
+	return nil
+}
+
+type chunkInput struct {
+	AudioInput
+	stream *chunkStream
+}
+
+func (i *chunkInput) Stream(ctx context.Context, errHandlers ...gostream.ErrorHandler) (gostream.AudioStream, error) {
+	return i.stream, nil
+}
+
+func TestAudioRecorder(t *testing.T) {
+	chunk := wave.NewInt16Interleaved(wave.ChunkInfo{Len: 2, Channels: 2, SamplingRate: 16000})
+	chunk.Data = []int16{1, -1, 2, -2}
+	input := &chunkInput{stream: &chunkStream{chunks: []wave.Audio{chunk}}}
+	recorder := &audioRecorder{input: input, recorded: make(chan struct{}, 1)}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	samples, info, err := recorder.next(ctx)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, samples, test.ShouldResemble, []int16{1, -1, 2, -2})
+	test.That(t, info.Channels, test.ShouldEqual, 2)
+	test.That(t, info.SamplingRate, test.ShouldEqual, 16000)
+
+	encoded := encodeWAV(samples, info)
+	test.That(t, encoded, test.ShouldHaveLength, 44+8)
+	test.That(t, string(encoded[:4]), test.ShouldEqual, "RIFF")
+	test.That(t, binary.LittleEndian.Uint32(encoded[4:]), test.ShouldEqual, 44)
+	test.That(t, string(encoded[8:16]), test.ShouldEqual, "WAVEfmt ")
+	test.That(t, binary.LittleEndian.Uint16(encoded[22:]), test.ShouldEqual, 2)
+	test.That(t, binary.LittleEndian.Uint32(encoded[24:]), test.ShouldEqual, 16000)
+	test.That(t, binary.LittleEndian.Uint32(encoded[28:]), test.ShouldEqual, 64000)
+	test.That(t, string(encoded[36:40]), test.ShouldEqual, "data")
+	test.That(t, encoded[44:], test.ShouldResemble, encodePCM(samples))
+	test.That(t, encodePCM(samples), test.ShouldResemble, []byte{1, 0, 0xff, 0xff, 2, 0, 0xfe, 0xff})
+
+	// Without new audio, captures wait until their context is done.
+	shortCtx, shortCancel := context.WithCancel(ctx)
+	shortCancel()
+	_, _, err = recorder.next(shortCtx)
+	test.That(t, err, test.ShouldBeError, context.Canceled)
+}
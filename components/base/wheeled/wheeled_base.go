@@ -317,6 +317,28 @@ func (base *wheeledBase) Width(ctx context.Context) (int, error) {
 	return base.widthMm, nil
 }
 
+// Geometry is the layout of the wheels of a wheeled base. Left and Right are the motors driving each side.
+type Geometry struct {
+	Left                 []motor.Motor
+	Right                []motor.Motor
+	WidthMM              int
+	WheelCircumferenceMM int
+}
+
+// GeometryOf returns the geometry of the base, which must be a wheeled base.
+func GeometryOf(b base.Base) (Geometry, error) {
+	wb, ok := rdkutils.UnwrapProxy(b).(*wheeledBase)
+	if !ok {
+		return Geometry{}, errors.Errorf("expected a wheeled base but got %T", rdkutils.UnwrapProxy(b))
+	}
+	return Geometry{
+		Left:                 wb.left,
+		Right:                wb.right,
+		WidthMM:              wb.widthMm,
+		WheelCircumferenceMM: wb.wheelCircumferenceMm,
+	}, nil
+}
+
 // CreateWheeledBase returns a new wheeled base defined by the given config.
 func CreateWheeledBase(
 	ctx context.Context,
@@ -9,9 +9,11 @@ import (
 	"github.com/edaniels/golog"
 	"go.viam.com/test"
 
+	"go.viam.com/rdk/components/base"
 	"go.viam.com/rdk/components/motor"
 	"go.viam.com/rdk/components/motor/fake"
 	"go.viam.com/rdk/registry"
+	"go.viam.com/rdk/testutils/inject"
 )
 
 func fakeMotorDependencies(t *testing.T, deps []string) registry.Dependencies {
@@ -319,6 +321,32 @@ func TestWheeledBaseConstructor(t *testing.T) {
 	test.That(t, len(base.allMotors), test.ShouldEqual, 4)
 }
 
+func TestGeometryOf(t *testing.T) {
+	cfg := &Config{
+		WidthMM:              100,
+		WheelCircumferenceMM: 1000,
+		Left:                 []string{"fl-m", "bl-m"},
+		Right:                []string{"fr-m", "br-m"},
+	}
+	deps, err := cfg.Validate("path")
+	test.That(t, err, test.ShouldBeNil)
+	wb, err := CreateWheeledBase(context.Background(), fakeMotorDependencies(t, deps), cfg, golog.NewTestLogger(t))
+	test.That(t, err, test.ShouldBeNil)
+
+	// the geometry is read through the proxy dependents get the base as
+	reconfBase, err := base.WrapWithReconfigurable(wb, base.Named("base"))
+	test.That(t, err, test.ShouldBeNil)
+	geometry, err := GeometryOf(reconfBase.(base.Base))
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, geometry.WidthMM, test.ShouldEqual, 100)
+	test.That(t, geometry.WheelCircumferenceMM, test.ShouldEqual, 1000)
+	test.That(t, geometry.Left, test.ShouldResemble, wb.(*wheeledBase).left)
+	test.That(t, geometry.Right, test.ShouldResemble, wb.(*wheeledBase).right)
+
+	_, err = GeometryOf(&inject.Base{})
+	test.That(t, err, test.ShouldNotBeNil)
+}
+
 func TestValidate(t *testing.T) {
 	cfg := &Config{}
 	deps, err := cfg.Validate("path")
@@ -0,0 +1,127 @@
+package board
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+	"google.golang.org/protobuf/types/known/anypb"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+
+	"go.viam.com/rdk/data"
+)
+
+type method int64
+
+const (
+	analogs method = iota
+	digitalInterrupts
+)
+
+func (m method) String() string {
+	switch m {
+	case analogs:
+		return "Analogs"
+	case digitalInterrupts:
+		return "DigitalInterrupts"
+	}
+	return "Unknown"
+}
+
+// Method parameters that limit capture to a single analog reader or digital interrupt. Without them, all of those
+// the board knows are captured.
+const (
+	readerNameParam    = "reader_name"
+	interruptNameParam = "interrupt_name"
+)
+
+// Readings wraps the values of the analog readers or digital interrupts of a board, by name.
+type Readings struct {
+	Readings map[string]int64
+}
+
+func newAnalogsCollector(resource interface{}, params data.CollectorParams) (data.Collector, error) {
+	board, err := assertBoard(resource)
+	if err != nil {
+		return nil, err
+	}
+	name, err := nameParam(params.MethodParams, readerNameParam)
+	if err != nil {
+		return nil, err
+	}
+
+	cFunc := data.CaptureFunc(func(ctx context.Context, _ map[string]*anypb.Any) (interface{}, error) {
+		names := board.AnalogReaderNames()
+		if name != "" {
+			names = []string{name}
+		}
+		readings := make(map[string]int64, len(names))
+		for _, n := range names {
+			reader, ok := board.AnalogReaderByName(n)
+			if !ok {
+				return nil, data.FailedToReadErr(params.ComponentName, analogs.String(),
+					errors.Errorf("no analog reader named %q", n))
+			}
+			v, err := reader.Read(ctx, nil)
+			if err != nil {
+				return nil, data.FailedToReadErr(params.ComponentName, analogs.String(), err)
+			}
+			readings[n] = int64(v)
+		}
+		return Readings{Readings: readings}, nil
+	})
+	return data.NewCollector(cFunc, params)
+}
+
+func newDigitalInterruptsCollector(resource interface{}, params data.CollectorParams) (data.Collector, error) {
+	board, err := assertBoard(resource)
+	if err != nil {
+		return nil, err
+	}
+	name, err := nameParam(params.MethodParams, interruptNameParam)
+	if err != nil {
+		return nil, err
+	}
+
+	cFunc := data.CaptureFunc(func(ctx context.Context, _ map[string]*anypb.Any) (interface{}, error) {
+		names := board.DigitalInterruptNames()
+		if name != "" {
+			names = []string{name}
+		}
+		readings := make(map[string]int64, len(names))
+		for _, n := range names {
+			interrupt, ok := board.DigitalInterruptByName(n)
+			if !ok {
+				return nil, data.FailedToReadErr(params.ComponentName, digitalInterrupts.String(),
+					errors.Errorf("no digital interrupt named %q", n))
+			}
+			v, err := interrupt.Value(ctx, nil)
+			if err != nil {
+				return nil, data.FailedToReadErr(params.ComponentName, digitalInterrupts.String(), err)
+			}
+			readings[n] = v
+		}
+		return Readings{Readings: readings}, nil
+	})
+	return data.NewCollector(cFunc, params)
+}
+
+// nameParam returns the string method parameter key, or "" if it is not set.
+func nameParam(methodParams map[string]*anypb.Any, key string) (string, error) {
+	param, ok := methodParams[key]
+	if !ok || param == nil {
+		return "", nil
+	}
+	name := new(wrapperspb.StringValue)
+	if err := param.UnmarshalTo(name); err != nil {
+		return "", errors.Wrapf(err, "method parameter %s must be a string", key)
+	}
+	return name.Value, nil
+}
+
+func assertBoard(resource interface{}) (Board, error) {
+	board, ok := resource.(Board)
+	if !ok {
+		return nil, data.InvalidInterfaceErr(SubtypeName)
+	}
+	return board, nil
+}
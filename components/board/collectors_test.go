@@ -0,0 +1,98 @@
+package board_test
+
+import (
+	"context"
+	"testing"
+
+	"go.viam.com/test"
+	"google.golang.org/protobuf/types/known/anypb"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+
+	"go.viam.com/rdk/components/board"
+	"go.viam.com/rdk/data"
+	"go.viam.com/rdk/testutils"
+	"go.viam.com/rdk/testutils/inject"
+)
+
+// collectorBoard is a board of fixed analog readers and digital interrupts. Unlike inject.Board it can be used
+// concurrently, as collectors do.
+type collectorBoard struct {
+	board.LocalBoard
+}
+
+func (b *collectorBoard) AnalogReaderNames() []string {
+	return []string{"a1", "a2"}
+}
+
+func (b *collectorBoard) AnalogReaderByName(name string) (board.AnalogReader, bool) {
+	v, ok := map[string]int{"a1": 12, "a2": 34}[name]
+	if !ok {
+		return nil, false
+	}
+	return &inject.AnalogReader{ReadFunc: func(ctx context.Context, extra map[string]interface{}) (int, error) {
+		return v, nil
+	}}, true
+}
+
+func (b *collectorBoard) DigitalInterruptNames() []string {
+	return []string{"i1", "i2"}
+}
+
+func (b *collectorBoard) DigitalInterruptByName(name string) (board.DigitalInterrupt, bool) {
+	v, ok := map[string]int64{"i1": 5, "i2": 6}[name]
+	if !ok {
+		return nil, false
+	}
+	return &inject.DigitalInterrupt{ValueFunc: func(ctx context.Context, extra map[string]interface{}) (int64, error) {
+		return v, nil
+	}}, true
+}
+
+func nameParams(t *testing.T, key, name string) map[string]*anypb.Any {
+	t.Helper()
+	param, err := anypb.New(wrapperspb.String(name))
+	test.That(t, err, test.ShouldBeNil)
+	return map[string]*anypb.Any{key: param}
+}
+
+func TestAnalogsCollector(t *testing.T) {
+	b := &collectorBoard{}
+	method := data.MethodMetadata{Subtype: board.SubtypeName, MethodName: "Analogs"}
+
+	// Without a reader name all analog readers are captured.
+	reading, err := testutils.CaptureReading(t, method, b, nil)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, reading.GetStruct().AsMap(), test.ShouldResemble, map[string]interface{}{
+		"Readings": map[string]interface{}{"a1": 12.0, "a2": 34.0},
+	})
+
+	reading, err = testutils.CaptureReading(t, method, b, nameParams(t, "reader_name", "a2"))
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, reading.GetStruct().AsMap(), test.ShouldResemble, map[string]interface{}{
+		"Readings": map[string]interface{}{"a2": 34.0},
+	})
+
+	wrongType, err := anypb.New(wrapperspb.Int64(2))
+	test.That(t, err, test.ShouldBeNil)
+	_, err = testutils.CaptureReading(t, method, b, map[string]*anypb.Any{"reader_name": wrongType})
+	test.That(t, err, test.ShouldNotBeNil)
+	_, err = testutils.CaptureReading(t, method, "not a board", nil)
+	test.That(t, err, test.ShouldNotBeNil)
+}
+
+func TestDigitalInterruptsCollector(t *testing.T) {
+	b := &collectorBoard{}
+	method := data.MethodMetadata{Subtype: board.SubtypeName, MethodName: "DigitalInterrupts"}
+
+	reading, err := testutils.CaptureReading(t, method, b, nil)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, reading.GetStruct().AsMap(), test.ShouldResemble, map[string]interface{}{
+		"Readings": map[string]interface{}{"i1": 5.0, "i2": 6.0},
+	})
+
+	reading, err = testutils.CaptureReading(t, method, b, nameParams(t, "interrupt_name", "i1"))
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, reading.GetStruct().AsMap(), test.ShouldResemble, map[string]interface{}{
+		"Readings": map[string]interface{}{"i1": 5.0},
+	})
+}
@@ -5,6 +5,8 @@ import (
 	// for cameras.
 	_ "go.viam.com/rdk/components/camera/fake"
 	_ "go.viam.com/rdk/components/camera/ffmpeg"
+	_ "go.viam.com/rdk/components/camera/replay"
+	_ "go.viam.com/rdk/components/camera/rosbag"
 	_ "go.viam.com/rdk/components/camera/transformpipeline"
 	_ "go.viam.com/rdk/components/camera/velodyne"
 	_ "go.viam.com/rdk/components/camera/videosource"
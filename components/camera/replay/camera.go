@@ -0,0 +1,124 @@
+// Package replay implements a camera that replays the images and point clouds of a camera from data capture files.
+package replay
+
+import (
+	"bytes"
+	"context"
+	"image"
+
+	"github.com/edaniels/golog"
+	"github.com/edaniels/gostream"
+	"github.com/pkg/errors"
+
+	"go.viam.com/rdk/components/camera"
+	"go.viam.com/rdk/config"
+	"go.viam.com/rdk/pointcloud"
+	"go.viam.com/rdk/registry"
+	// Register raw RGBA images so that they are decoded.
+	_ "go.viam.com/rdk/rimage"
+	"go.viam.com/rdk/rimage/transform"
+	"go.viam.com/rdk/services/datamanager/datacapture/replay"
+)
+
+const modelname = "replay"
+
+// The methods whose readings the data manager captures from cameras.
+const (
+	readImage      = "ReadImage"
+	nextPointCloud = "NextPointCloud"
+)
+
+func init() {
+	registry.RegisterComponent(
+		camera.Subtype,
+		modelname,
+		registry.Component{Constructor: func(
+			ctx context.Context,
+			_ registry.Dependencies,
+			cfg config.Component,
+			logger golog.Logger,
+		) (interface{}, error) {
+			return newCamera(ctx, *cfg.ConvertedAttributes.(*replay.Config))
+		}})
+
+	config.RegisterComponentAttributeMapConverter(camera.SubtypeName, modelname,
+		func(attributes config.AttributeMap) (interface{}, error) {
+			var conf replay.Config
+			return config.TransformAttributeMapToStruct(&conf, attributes)
+		},
+		&replay.Config{})
+}
+
+func newCamera(ctx context.Context, cfg replay.Config) (camera.Camera, error) {
+	r, err := replay.New(cfg, camera.SubtypeName)
+	if err != nil {
+		return nil, err
+	}
+	return &Camera{replay: r}, nil
+}
+
+// Camera replays the images and point clouds captured from a camera. Its streams read the next image only when
+// they are asked for it, unlike those of cameras made from readers, so that step replays skip no images.
+type Camera struct {
+	replay *replay.Replay
+}
+
+// Stream returns a stream of the images of the replay.
+func (c *Camera) Stream(ctx context.Context, errHandlers ...gostream.ErrorHandler) (gostream.VideoStream, error) {
+	return &stream{camera: c}, nil
+}
+
+// read returns the image of the replay at its current time.
+func (c *Camera) read(ctx context.Context) (image.Image, func(), error) {
+	reading, err := c.replay.Next(ctx, readImage)
+	if err != nil {
+		return nil, nil, err
+	}
+	img, _, err := image.Decode(bytes.NewReader(reading.GetBinary()))
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "failed to decode replayed image")
+	}
+	return img, func() {}, nil
+}
+
+// NextPointCloud returns the point cloud of the replay at its current time.
+func (c *Camera) NextPointCloud(ctx context.Context) (pointcloud.PointCloud, error) {
+	reading, err := c.replay.Next(ctx, nextPointCloud)
+	if err != nil {
+		return nil, err
+	}
+	return pointcloud.ReadPCD(bytes.NewReader(reading.GetBinary()))
+}
+
+// Projector is unimplemented since the intrinsics of cameras are not captured.
+func (c *Camera) Projector(ctx context.Context) (transform.Projector, error) {
+	return nil, transform.NewNoIntrinsicsError("replayed cameras have no intrinsics")
+}
+
+// Properties returns whether point clouds were captured.
+func (c *Camera) Properties(ctx context.Context) (camera.Properties, error) {
+	return camera.Properties{SupportsPCD: c.replay.HasMethod(nextPointCloud)}, nil
+}
+
+// DoCommand seeks the replay.
+func (c *Camera) DoCommand(ctx context.Context, cmd map[string]interface{}) (map[string]interface{}, error) {
+	return c.replay.DoCommand(ctx, cmd)
+}
+
+// Close closes the files being replayed.
+func (c *Camera) Close(ctx context.Context) error {
+	return c.replay.Close()
+}
+
+// stream reads an image of its camera every time it is asked for one.
+type stream struct {
+	camera *Camera
+}
+
+func (s *stream) Next(ctx context.Context) (image.Image, func(), error) {
+	return s.camera.read(ctx)
+}
+
+func (s *stream) Close(ctx context.Context) error {
+	return nil
+}
@@ -0,0 +1,83 @@
+package replay
+
+import (
+	"bytes"
+	"context"
+	"image"
+	"image/color"
+	"image/png"
+	"testing"
+	"time"
+
+	"github.com/golang/geo/r3"
+	v1 "go.viam.com/api/app/datasync/v1"
+	"go.viam.com/test"
+
+	"go.viam.com/rdk/pointcloud"
+	"go.viam.com/rdk/services/datamanager/datacapture/replay"
+	"go.viam.com/rdk/testutils"
+)
+
+var captureStart = time.Date(2022, 11, 1, 12, 0, 0, 0, time.UTC)
+
+func writeCapture(t *testing.T, dir, method string, readings ...[]byte) {
+	t.Helper()
+	captured := make([]*v1.SensorData, 0, len(readings))
+	for i, reading := range readings {
+		captured = append(captured, testutils.BinaryReading(captureStart.Add(time.Duration(i)*time.Second), reading))
+	}
+	testutils.WriteCaptureFile(t, dir, &v1.DataCaptureMetadata{
+		ComponentType: "camera",
+		ComponentName: "cam1",
+		MethodName:    method,
+		Type:          v1.DataType_DATA_TYPE_BINARY_SENSOR,
+	}, captured...)
+}
+
+func TestReplayCamera(t *testing.T) {
+	dir := t.TempDir()
+	var images [][]byte
+	for _, width := range []int{2, 3} {
+		img := image.NewNRGBA(image.Rect(0, 0, width, 1))
+		img.Set(0, 0, color.NRGBA{R: 255, A: 255})
+		var buf bytes.Buffer
+		test.That(t, png.Encode(&buf, img), test.ShouldBeNil)
+		images = append(images, buf.Bytes())
+	}
+	writeCapture(t, dir, readImage, images...)
+	pc := pointcloud.New()
+	test.That(t, pc.Set(r3.Vector{X: 1, Y: 2, Z: 3}, nil), test.ShouldBeNil)
+	var pcd bytes.Buffer
+	test.That(t, pointcloud.ToPCD(pc, &pcd, pointcloud.PCDBinary), test.ShouldBeNil)
+	writeCapture(t, dir, nextPointCloud, pcd.Bytes())
+
+	ctx := context.Background()
+	cam, err := newCamera(ctx, replay.Config{Source: dir, Step: true})
+	test.That(t, err, test.ShouldBeNil)
+	defer func() {
+		test.That(t, cam.Close(ctx), test.ShouldBeNil)
+	}()
+
+	stream, err := cam.Stream(ctx)
+	test.That(t, err, test.ShouldBeNil)
+	for _, width := range []int{2, 3} {
+		img, _, err := stream.Next(ctx)
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, img.Bounds().Dx(), test.ShouldEqual, width)
+	}
+	_, _, err = stream.Next(ctx)
+	test.That(t, err, test.ShouldBeError, replay.ErrEnd)
+
+	replayed, err := cam.NextPointCloud(ctx)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, replayed.Size(), test.ShouldEqual, 1)
+	_, got := replayed.At(1, 2, 3)
+	test.That(t, got, test.ShouldBeTrue)
+
+	// Seeking back replays the recording again.
+	_, err = cam.DoCommand(ctx, map[string]interface{}{"seek": 0.0})
+	test.That(t, err, test.ShouldBeNil)
+	img, _, err := stream.Next(ctx)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, img.Bounds().Dx(), test.ShouldEqual, 2)
+}
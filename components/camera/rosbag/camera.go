@@ -0,0 +1,152 @@
+// Package rosbag implements a camera that plays the images or point clouds of a topic of a ROS bag.
+package rosbag
+
+import (
+	"context"
+	"encoding/json"
+	"image"
+
+	"github.com/edaniels/golog"
+	"github.com/edaniels/gostream"
+	"github.com/pkg/errors"
+
+	"go.viam.com/rdk/components/camera"
+	"go.viam.com/rdk/config"
+	"go.viam.com/rdk/pointcloud"
+	"go.viam.com/rdk/registry"
+	"go.viam.com/rdk/rimage/transform"
+	"go.viam.com/rdk/ros"
+)
+
+const modelname = "rosbag"
+
+func init() {
+	registry.RegisterComponent(
+		camera.Subtype,
+		modelname,
+		registry.Component{Constructor: func(
+			ctx context.Context,
+			_ registry.Dependencies,
+			cfg config.Component,
+			logger golog.Logger,
+		) (interface{}, error) {
+			return newCamera(*cfg.ConvertedAttributes.(*ros.TopicConfig))
+		}})
+
+	config.RegisterComponentAttributeMapConverter(camera.SubtypeName, modelname,
+		func(attributes config.AttributeMap) (interface{}, error) {
+			var conf ros.TopicConfig
+			return config.TransformAttributeMapToStruct(&conf, attributes)
+		},
+		&ros.TopicConfig{})
+}
+
+func newCamera(cfg ros.TopicConfig) (camera.Camera, error) {
+	player, err := ros.NewPlayer(cfg)
+	if err != nil {
+		return nil, err
+	}
+	switch player.MessageType() {
+	case ros.ImageType, ros.CompressedImageType, ros.PointCloud2Type:
+	default:
+		return nil, errors.Errorf("cannot play topic %s of type %s as a camera, must be of type %s, %s or %s",
+			cfg.Topic, player.MessageType(), ros.ImageType, ros.CompressedImageType, ros.PointCloud2Type)
+	}
+	return &Camera{player: player, topic: cfg.Topic}, nil
+}
+
+// Camera plays the sensor_msgs/Image, sensor_msgs/CompressedImage or sensor_msgs/PointCloud2 messages of a topic of a
+// ROS bag. Its streams return each image when it was recorded.
+type Camera struct {
+	player *ros.Player
+	topic  string
+}
+
+// Stream returns a stream of the images of the topic.
+func (c *Camera) Stream(ctx context.Context, errHandlers ...gostream.ErrorHandler) (gostream.VideoStream, error) {
+	if c.player.MessageType() == ros.PointCloud2Type {
+		return nil, errors.Errorf("topic %s has point clouds rather than images", c.topic)
+	}
+	return &stream{camera: c, last: -1}, nil
+}
+
+// read waits for the image after image number after and decodes it.
+func (c *Camera) read(ctx context.Context, after int) (image.Image, int, error) {
+	msg, n, err := c.player.Next(ctx, after)
+	if err != nil {
+		return nil, n, err
+	}
+	var img image.Image
+	if c.player.MessageType() == ros.CompressedImageType {
+		var compressed ros.CompressedImage
+		if err := json.Unmarshal(msg.Data, &compressed); err != nil {
+			return nil, n, err
+		}
+		img, err = compressed.ToImage()
+	} else {
+		var raw ros.Image
+		if err := json.Unmarshal(msg.Data, &raw); err != nil {
+			return nil, n, err
+		}
+		img, err = raw.ToImage()
+	}
+	if err != nil {
+		return nil, n, errors.Wrapf(err, "failed to decode image of topic %s", c.topic)
+	}
+	return img, n, nil
+}
+
+// NextPointCloud returns the latest point cloud of the topic.
+func (c *Camera) NextPointCloud(ctx context.Context) (pointcloud.PointCloud, error) {
+	if c.player.MessageType() != ros.PointCloud2Type {
+		return nil, errors.Errorf("topic %s has images rather than point clouds", c.topic)
+	}
+	msg, _, err := c.player.Next(ctx, -1)
+	if err != nil {
+		return nil, err
+	}
+	var pc ros.PointCloud2
+	if err := json.Unmarshal(msg.Data, &pc); err != nil {
+		return nil, err
+	}
+	return pc.ToPointCloud()
+}
+
+// Projector is unimplemented since the camera info of the topic is not played.
+func (c *Camera) Projector(ctx context.Context) (transform.Projector, error) {
+	return nil, transform.NewNoIntrinsicsError("ros bag cameras have no intrinsics")
+}
+
+// Properties returns whether the topic has point clouds.
+func (c *Camera) Properties(ctx context.Context) (camera.Properties, error) {
+	return camera.Properties{SupportsPCD: c.player.MessageType() == ros.PointCloud2Type}, nil
+}
+
+// DoCommand restarts the topic.
+func (c *Camera) DoCommand(ctx context.Context, cmd map[string]interface{}) (map[string]interface{}, error) {
+	return c.player.DoCommand(ctx, cmd)
+}
+
+// Close does nothing since the bag is read when the camera is created.
+func (c *Camera) Close(ctx context.Context) error {
+	return nil
+}
+
+// stream returns the images of its camera in order, skipping those whose time passed before they were asked for.
+type stream struct {
+	camera *Camera
+	last   int
+}
+
+func (s *stream) Next(ctx context.Context) (image.Image, func(), error) {
+	img, n, err := s.camera.read(ctx, s.last)
+	if err != nil {
+		return nil, nil, err
+	}
+	s.last = n
+	return img, func() {}, nil
+}
+
+func (s *stream) Close(ctx context.Context) error {
+	return nil
+}
@@ -0,0 +1,92 @@
+package generic
+
+import (
+	"context"
+	"fmt"
+
+	pb "go.viam.com/api/component/generic/v1"
+	"go.viam.com/utils/protoutils"
+	"go.viam.com/utils/rpc"
+	"google.golang.org/grpc"
+
+	"go.viam.com/rdk/resource"
+)
+
+// A CommandHandler handles a command sent to the named resource of a subtype.
+type CommandHandler func(ctx context.Context, name string, cmd map[string]interface{}) (map[string]interface{}, error)
+
+// commandServer is what the handlers of a subtype command service are registered as.
+type commandServer interface {
+	doCommand(ctx context.Context, req *pb.DoCommandRequest) (*pb.DoCommandResponse, error)
+}
+
+func (h CommandHandler) doCommand(ctx context.Context, req *pb.DoCommandRequest) (*pb.DoCommandResponse, error) {
+	result, err := h(ctx, req.Name, req.Command.AsMap())
+	if err != nil {
+		return nil, err
+	}
+	res, err := protoutils.StructToStructPb(result)
+	if err != nil {
+		return nil, err
+	}
+	return &pb.DoCommandResponse{Result: res}, nil
+}
+
+// commandServiceName is the name of the gRPC service that carries commands for the subtype.
+func commandServiceName(s resource.Subtype) string {
+	return fmt.Sprintf("%s.%s.%s.CommandService", s.Namespace, s.ResourceType, s.ResourceSubtype)
+}
+
+// RegisterCommandService registers a gRPC service that passes commands for resources of the subtype to
+// the handler. This gives a remote path to the parts of a subtype that its API has no messages for yet. The
+// service reuses the request and response messages of the generic DoCommand.
+func RegisterCommandService(ctx context.Context, rpcServer rpc.Server, s resource.Subtype, handler CommandHandler) error {
+	serviceName := commandServiceName(s)
+	desc := &grpc.ServiceDesc{
+		ServiceName: serviceName,
+		HandlerType: (*commandServer)(nil),
+		Methods: []grpc.MethodDesc{
+			{
+				MethodName: "DoCommand",
+				Handler: func(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (
+					interface{}, error,
+				) {
+					in := new(pb.DoCommandRequest)
+					if err := dec(in); err != nil {
+						return nil, err
+					}
+					if interceptor == nil {
+						return srv.(commandServer).doCommand(ctx, in)
+					}
+					info := &grpc.UnaryServerInfo{Server: srv, FullMethod: "/" + serviceName + "/DoCommand"}
+					return interceptor(ctx, in, info, func(ctx context.Context, req interface{}) (interface{}, error) {
+						return srv.(commandServer).doCommand(ctx, req.(*pb.DoCommandRequest))
+					})
+				},
+			},
+		},
+		Streams: []grpc.StreamDesc{},
+	}
+	return rpcServer.RegisterServiceServer(ctx, desc, handler)
+}
+
+// DoFromCommandService sends a command to the named resource of the subtype through the service registered
+// by RegisterCommandService.
+func DoFromCommandService(
+	ctx context.Context,
+	conn rpc.ClientConn,
+	s resource.Subtype,
+	name string,
+	cmd map[string]interface{},
+) (map[string]interface{}, error) {
+	command, err := protoutils.StructToStructPb(cmd)
+	if err != nil {
+		return nil, err
+	}
+	req := &pb.DoCommandRequest{Name: name, Command: command}
+	resp := &pb.DoCommandResponse{}
+	if err := conn.Invoke(ctx, "/"+commandServiceName(s)+"/DoCommand", req, resp); err != nil {
+		return nil, err
+	}
+	return resp.Result.AsMap(), nil
+}
@@ -122,7 +122,14 @@ func newEncodedMotor(
 	}
 
 	if len(motorConfig.ControlLoop.Blocks) != 0 {
-		cLoop, err := control.NewLoop(logger, motorConfig.ControlLoop, em)
+		setPoint, trapezoidal, err := moveBlocks(motorConfig.ControlLoop)
+		if err != nil {
+			return nil, err
+		}
+		em.loopSetPoint = setPoint
+		em.loopTrapezoidal = trapezoidal.Name
+		em.loopPosWindow = trapezoidal.Attribute.Float64("pos_window", defaultPosWindow)
+		cLoop, err := control.NewLoop(logger, motorConfig.ControlLoop, loopControllable{m: em})
 		if err != nil {
 			return nil, err
 		}
@@ -186,6 +193,12 @@ type EncodedMotor struct {
 	cancelCtx       context.Context
 	cancel          func()
 	loop            *control.Loop
+	// loopSetPoint and loopTrapezoidal are the names of the set point and velocity profile blocks of the
+	// loop and loopPosWindow is how close in ticks to its set point a move under the control of the loop
+	// needs to get to be done.
+	loopSetPoint    string
+	loopTrapezoidal string
+	loopPosWindow   float64
 	opMgr           operation.SingleOperationManager
 
 	generic.Unimplemented
@@ -199,6 +212,7 @@ type EncodedMotorState struct {
 	currentRPM   float64
 	lastPowerPct float64
 	setPoint     int64
+	controlled   bool // the control loop is driving the motor
 }
 
 // Position returns the position of the motor.
@@ -276,6 +290,7 @@ func (m *EncodedMotor) setPower(ctx context.Context, powerPct float64, internal
 	if !internal {
 		m.state.desiredRPM = 0    // if we're setting power externally, don't control RPM
 		m.state.regulated = false // user wants direct control, so we stop trying to control the world
+		m.state.controlled = false
 	}
 	m.state.lastPowerPct = m.fixPowerPct(powerPct)
 	return m.real.SetPower(ctx, m.state.lastPowerPct, nil)
@@ -356,6 +371,11 @@ func (m *EncodedMotor) rpmMonitorPass(pos, lastPos, now, lastTime int64, rpmDebu
 	currentRPM := m.computeRPM(pos, lastPos, now, lastTime)
 	m.state.currentRPM = currentRPM
 
+	if m.loop != nil {
+		m.loopMonitorPassInLock(pos, rpmDebug)
+		return
+	}
+
 	if !m.state.regulated && math.Abs(m.state.desiredRPM) > 0.001 {
 		m.rpmMonitorPassSetRpmInLock(currentRPM, m.state.desiredRPM, -1, rpmDebug)
 		return
@@ -494,6 +514,9 @@ func (m *EncodedMotor) GoFor(ctx context.Context, rpm, revolutions float64, extr
 		return err
 	}
 
+	if m.loop != nil {
+		return m.opMgr.WaitTillNotPowered(ctx, time.Millisecond, loopControllable{m: m}, m.Stop)
+	}
 	return m.opMgr.WaitTillNotPowered(ctx, time.Millisecond, m, m.Stop)
 }
 
@@ -510,6 +533,10 @@ func (m *EncodedMotor) goForInternal(ctx context.Context, rpm, revolutions float
 	revolutions = math.Abs(revolutions)
 	rpm = math.Abs(rpm) * float64(d)
 
+	if m.loop != nil {
+		return m.goForLoop(ctx, rpm, revolutions, d)
+	}
+
 	m.stateMu.Lock()
 	defer m.stateMu.Unlock()
 
@@ -558,6 +585,7 @@ func (m *EncodedMotor) goForInternal(ctx context.Context, rpm, revolutions float
 func (m *EncodedMotor) off(ctx context.Context) error {
 	m.state.desiredRPM = 0
 	m.state.regulated = false
+	m.state.controlled = false
 	return m.real.Stop(ctx, nil)
 }
 
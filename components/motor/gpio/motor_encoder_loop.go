@@ -0,0 +1,181 @@
+package gpio
+
+import (
+	"context"
+	"math"
+
+	"github.com/pkg/errors"
+
+	"go.viam.com/rdk/control"
+)
+
+// An EncodedMotor with a control loop drives the motor through the loop instead of through its own
+// ramping. The loop works in encoder ticks and must contain an endpoint reporting the position of the motor
+// and applying the power the loop computes, and a trapezoidal velocity profile that depends on the
+// endpoint and on a constant holding the position the motor should go to. Blocks are found by their types
+// and dependencies, so they can have any names. Anything in between the profile and the endpoint,
+// typically a velocity PID, is up to the config, including other velocity profiles.
+const (
+	// loopSetPointBlock is the name the set point constant is preferred by if the profile depends on more
+	// than one constant.
+	loopSetPointBlock = "set_point"
+
+	constantType                   = "constant"
+	endpointType                   = "endpoint"
+	trapezoidalVelocityProfileType = "trapezoidalVelocityProfile"
+	// defaultPosWindow is the pos_window default of the trapezoidal velocity profile block.
+	defaultPosWindow = 10.
+
+	// moving forever is a move to a set point so far away that it is never reached.
+	loopForeverTicks = 1e12
+)
+
+// loopControllable is what the control loop of an EncodedMotor drives. Positions are in encoder ticks, and
+// power is only applied while a move is under the control of the loop so that the loop can keep running
+// in between moves.
+type loopControllable struct {
+	m *EncodedMotor
+}
+
+func (c loopControllable) SetPower(ctx context.Context, powerPct float64, extra map[string]interface{}) error {
+	c.m.stateMu.Lock()
+	defer c.m.stateMu.Unlock()
+	if !c.m.state.controlled {
+		return nil
+	}
+	return c.m.setPower(ctx, powerPct*float64(c.m.flip), true)
+}
+
+func (c loopControllable) Position(ctx context.Context, extra map[string]interface{}) (float64, error) {
+	ticks, err := c.m.encoder.TicksCount(ctx, extra)
+	if err != nil {
+		return 0, err
+	}
+	return float64(ticks), nil
+}
+
+// IsPowered reports whether a move is still under the control of the loop, which is what GoFor waits on.
+func (c loopControllable) IsPowered(ctx context.Context, extra map[string]interface{}) (bool, float64, error) {
+	c.m.stateMu.RLock()
+	defer c.m.stateMu.RUnlock()
+	return c.m.state.controlled, c.m.state.lastPowerPct, nil
+}
+
+// moveBlocks finds the blocks of the control config that an EncodedMotor drives its moves through: the
+// trapezoidal velocity profile of the moves and the constant that holds their set point.
+func moveBlocks(cfg control.Config) (setPoint string, trapezoidal control.BlockConfig, err error) {
+	types := make(map[string]string, len(cfg.Blocks))
+	var endpoints []string
+	for _, b := range cfg.Blocks {
+		types[b.Name] = string(b.Type)
+		if b.Type == endpointType {
+			endpoints = append(endpoints, b.Name)
+		}
+	}
+	if len(endpoints) != 1 {
+		return "", control.BlockConfig{}, errors.Errorf("control config needs exactly one %s block but has %d", endpointType, len(endpoints))
+	}
+
+	var candidates []control.BlockConfig
+	var candidateSetPoints []string
+	for _, b := range cfg.Blocks {
+		if b.Type != trapezoidalVelocityProfileType {
+			continue
+		}
+		var constants []string
+		var onEndpoint bool
+		for _, dep := range b.DependsOn {
+			switch types[dep] {
+			case constantType:
+				constants = append(constants, dep)
+			case endpointType:
+				onEndpoint = true
+			}
+		}
+		if !onEndpoint || len(constants) == 0 {
+			continue
+		}
+		constant := constants[0]
+		if len(constants) > 1 {
+			constant = ""
+			for _, c := range constants {
+				if c == loopSetPointBlock {
+					constant = c
+				}
+			}
+			if constant == "" {
+				return "", control.BlockConfig{}, errors.Errorf(
+					"control block %s depends on more than one constant, name the set point %s", b.Name, loopSetPointBlock)
+			}
+		}
+		candidates = append(candidates, b)
+		candidateSetPoints = append(candidateSetPoints, constant)
+	}
+	if len(candidates) != 1 {
+		return "", control.BlockConfig{}, errors.Errorf(
+			"control config needs exactly one %s block that depends on a %s and the %s but has %d",
+			trapezoidalVelocityProfileType, constantType, endpointType, len(candidates))
+	}
+	return candidateSetPoints[0], candidates[0], nil
+}
+
+// goForLoop hands a move over to the control loop. The set point is updated before the velocity profile
+// since reconfiguring the profile is what makes it start a new move.
+func (m *EncodedMotor) goForLoop(ctx context.Context, rpm, revolutions float64, d int64) error {
+	m.stateMu.Lock()
+	m.state.controlled = false
+	m.stateMu.Unlock()
+
+	pos, err := m.encoder.TicksCount(ctx, nil)
+	if err != nil {
+		return err
+	}
+	numTicks := int64(revolutions * float64(m.cfg.TicksPerRotation))
+	if revolutions == 0 {
+		numTicks = loopForeverTicks
+	}
+	setPoint := pos + d*numTicks*m.flip
+
+	if err := m.loop.UpdateAttributesAt(ctx, m.loopSetPoint, map[string]interface{}{"constant_val": float64(setPoint)}); err != nil {
+		return err
+	}
+	// the profile works in ticks per second
+	maxVel := math.Abs(rpm) * float64(m.cfg.TicksPerRotation) / 60
+	if err := m.loop.UpdateAttributesAt(ctx, m.loopTrapezoidal, map[string]interface{}{"max_vel": maxVel}); err != nil {
+		return err
+	}
+
+	m.stateMu.Lock()
+	defer m.stateMu.Unlock()
+	m.state.setPoint = setPoint
+	m.state.desiredRPM = rpm
+	m.state.regulated = revolutions != 0
+	m.state.controlled = true
+	return nil
+}
+
+// loopMonitorPassInLock stops the motor once a move under the control of the loop is within the position
+// window of the velocity profile.
+func (m *EncodedMotor) loopMonitorPassInLock(pos int64, rpmDebug bool) {
+	if !m.state.controlled || !m.state.regulated {
+		return
+	}
+	ticksLeft := math.Abs(float64(m.state.setPoint - pos))
+	if rpmDebug {
+		m.logger.Debugf("ticksLeft %.0f", ticksLeft)
+	}
+	if ticksLeft > m.loopPosWindow {
+		return
+	}
+	if err := m.off(m.cancelCtx); err != nil {
+		m.logger.Warnf("error turning motor off from after hit set point: %v", err)
+	}
+}
+
+// DoCommand exposes the control loop of the motor for tuning. See the control package for the commands.
+func (m *EncodedMotor) DoCommand(ctx context.Context, cmd map[string]interface{}) (map[string]interface{}, error) {
+	if m.loop == nil || !control.IsCommand(cmd) {
+		return m.Unimplemented.DoCommand(ctx, cmd)
+	}
+	return m.loop.DoCommand(ctx, cmd)
+}
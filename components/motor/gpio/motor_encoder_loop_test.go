@@ -0,0 +1,305 @@
+package gpio
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/edaniels/golog"
+	"go.viam.com/test"
+	"go.viam.com/utils"
+	"go.viam.com/utils/testutils"
+
+	fakeencoder "go.viam.com/rdk/components/encoder/fake"
+	"go.viam.com/rdk/components/generic"
+	fakemotor "go.viam.com/rdk/components/motor/fake"
+	"go.viam.com/rdk/config"
+	"go.viam.com/rdk/control"
+)
+
+// integratingEncoder counts the ticks a fake motor would have turned through given the power it has been
+// set to, so that its position is smooth no matter how often it is read.
+type integratingEncoder struct {
+	mu               sync.Mutex
+	m                *fakemotor.Motor
+	ticksPerRotation float64
+	position         float64
+	lastRead         time.Time
+
+	generic.Unimplemented
+}
+
+func (e *integratingEncoder) TicksCount(ctx context.Context, extra map[string]interface{}) (int64, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	now := time.Now()
+	if !e.lastRead.IsZero() {
+		rpm := e.m.PowerPct() * e.m.MaxRPM
+		e.position += rpm / 60 * e.ticksPerRotation * now.Sub(e.lastRead).Seconds()
+	}
+	e.lastRead = now
+	return int64(e.position), nil
+}
+
+func (e *integratingEncoder) Reset(ctx context.Context, offset int64, extra map[string]interface{}) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.position = float64(offset)
+	return nil
+}
+
+// cascadedControlConfig is a position profile feeding a velocity PI loop with feedforward, for a motor
+// with 1000 ticks per rotation and a max rpm of 600.
+func cascadedControlConfig() control.Config {
+	return control.Config{
+		Frequency: 20,
+		Blocks: []control.BlockConfig{
+			{
+				Name:      "set_point",
+				Type:      "constant",
+				Attribute: config.AttributeMap{"constant_val": 0.},
+			},
+			{
+				Name:      "endpoint",
+				Type:      "endpoint",
+				Attribute: config.AttributeMap{"motor_name": "motor"},
+				DependsOn: []string{"power"},
+			},
+			{
+				Name:      "trapezoid",
+				Type:      "trapezoidalVelocityProfile",
+				Attribute: config.AttributeMap{"max_acc": 5000., "max_vel": 1000., "pos_window": 20.},
+				DependsOn: []string{"set_point", "endpoint"},
+			},
+			{
+				Name:      "target_rpm",
+				Type:      "gain",
+				Attribute: config.AttributeMap{"gain": 60. / 1000.},
+				DependsOn: []string{"trapezoid"},
+			},
+			{
+				Name:      "velocity",
+				Type:      "encoderToRpm",
+				Attribute: config.AttributeMap{"ticks_per_revolution": 1000},
+				DependsOn: []string{"endpoint"},
+			},
+			{
+				Name:      "error",
+				Type:      "sum",
+				Attribute: config.AttributeMap{"sum_string": "+-"},
+				DependsOn: []string{"target_rpm", "velocity"},
+			},
+			{
+				Name: "pid",
+				Type: "PID",
+				Attribute: config.AttributeMap{
+					"kP": 0.0005, "kI": 0.001, "limit_up": 1., "limit_lo": -1., "int_sat_lim_up": 1., "int_sat_lim_lo": -1.,
+				},
+				DependsOn: []string{"error"},
+			},
+			{
+				Name:      "feedforward",
+				Type:      "gain",
+				Attribute: config.AttributeMap{"gain": 1. / 600.},
+				DependsOn: []string{"target_rpm"},
+			},
+			{
+				Name:      "power",
+				Type:      "sum",
+				Attribute: config.AttributeMap{"sum_string": "++"},
+				DependsOn: []string{"pid", "feedforward"},
+			},
+		},
+	}
+}
+
+func TestMotorEncoderControlLoop(t *testing.T) {
+	logger := golog.NewTestLogger(t)
+	undo := SetRPMSleepDebug(1, false)
+	defer undo()
+
+	fakeMotor := &fakemotor.Motor{
+		MaxRPM:           600,
+		Logger:           logger,
+		TicksPerRotation: 1000,
+	}
+	fakeEncoder := &integratingEncoder{m: fakeMotor, ticksPerRotation: 1000}
+
+	cfg := Config{TicksPerRotation: 1000, ControlLoop: cascadedControlConfig()}
+	m, err := NewEncodedMotor(config.Component{}, cfg, fakeMotor, fakeEncoder, logger)
+	test.That(t, err, test.ShouldBeNil)
+	_motor := m.(*EncodedMotor)
+	defer func() {
+		test.That(t, utils.TryClose(context.Background(), _motor), test.ShouldBeNil)
+	}()
+	ctx := context.Background()
+
+	t.Run("loop does not drive the motor between moves", func(t *testing.T) {
+		isOn, _, err := _motor.IsPowered(ctx, nil)
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, isOn, test.ShouldBeFalse)
+	})
+
+	t.Run("GoFor and GoTo", func(t *testing.T) {
+		test.That(t, _motor.GoFor(ctx, 60, 2, nil), test.ShouldBeNil)
+		pos, err := _motor.Position(ctx, nil)
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, pos, test.ShouldAlmostEqual, 2, .05)
+		isOn, _, err := _motor.IsPowered(ctx, nil)
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, isOn, test.ShouldBeFalse)
+
+		test.That(t, _motor.GoTo(ctx, 120, 1, nil), test.ShouldBeNil)
+		pos, err = _motor.Position(ctx, nil)
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, pos, test.ShouldAlmostEqual, 1, .05)
+	})
+
+	t.Run("velocity control", func(t *testing.T) {
+		test.That(t, _motor.goForInternal(ctx, 60, 0), test.ShouldBeNil)
+		testutils.WaitForAssertion(t, func(tb testing.TB) {
+			tb.Helper()
+			velocity, err := _motor.DoCommand(ctx, map[string]interface{}{"command": "control_outputs"})
+			test.That(tb, err, test.ShouldBeNil)
+			test.That(tb, velocity["velocity"], test.ShouldAlmostEqual, 60, 10)
+		})
+		test.That(t, _motor.Stop(ctx, nil), test.ShouldBeNil)
+		isOn, _, err := _motor.IsPowered(ctx, nil)
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, isOn, test.ShouldBeFalse)
+
+		// direct power control takes over from the loop
+		test.That(t, _motor.goForInternal(ctx, 60, 0), test.ShouldBeNil)
+		test.That(t, _motor.SetPower(ctx, .25, nil), test.ShouldBeNil)
+		for i := 0; i < 10; i++ {
+			test.That(t, fakeMotor.PowerPct(), test.ShouldEqual, .25)
+		}
+		test.That(t, _motor.Stop(ctx, nil), test.ShouldBeNil)
+	})
+
+	t.Run("tuning through DoCommand", func(t *testing.T) {
+		resp, err := _motor.DoCommand(ctx, map[string]interface{}{"command": "control_blocks"})
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, resp["blocks"], test.ShouldHaveLength, 9)
+
+		ticks, err := fakeEncoder.TicksCount(ctx, nil)
+		test.That(t, err, test.ShouldBeNil)
+		testutils.WaitForAssertion(t, func(tb testing.TB) {
+			tb.Helper()
+			resp, err := _motor.DoCommand(ctx, map[string]interface{}{"command": "control_outputs"})
+			test.That(tb, err, test.ShouldBeNil)
+			test.That(tb, resp["endpoint"], test.ShouldEqual, float64(ticks))
+		})
+
+		_, err = _motor.DoCommand(ctx, map[string]interface{}{
+			"command":    "set_control_config",
+			"block":      "pid",
+			"attributes": map[string]interface{}{"kP": 0.001},
+		})
+		test.That(t, err, test.ShouldBeNil)
+		resp, err = _motor.DoCommand(ctx, map[string]interface{}{"command": "control_config", "block": "pid"})
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, resp["type"], test.ShouldEqual, "PID")
+		test.That(t, resp["depends_on"], test.ShouldResemble, []interface{}{"error"})
+		attributes := resp["attributes"].(map[string]interface{})
+		test.That(t, attributes["kP"], test.ShouldEqual, 0.001)
+		test.That(t, attributes["kI"], test.ShouldEqual, 0.001)
+
+		_, err = _motor.DoCommand(ctx, map[string]interface{}{"command": "control_config", "block": "nope"})
+		test.That(t, err, test.ShouldNotBeNil)
+		_, err = _motor.DoCommand(ctx, map[string]interface{}{"command": "nope"})
+		test.That(t, err, test.ShouldNotBeNil)
+	})
+}
+
+func TestMotorEncoderControlLoopConfig(t *testing.T) {
+	logger := golog.NewTestLogger(t)
+	fakeMotor := &fakemotor.Motor{Logger: logger}
+	e := &fakeencoder.Encoder{}
+
+	for _, missing := range []string{"set_point", "endpoint", "trapezoid"} {
+		cfg := Config{TicksPerRotation: 1000, ControlLoop: cascadedControlConfig()}
+		for i, b := range cfg.ControlLoop.Blocks {
+			if b.Name == missing {
+				cfg.ControlLoop.Blocks[i].Name = "renamed"
+				cfg.ControlLoop.Blocks[i].Type = "gain"
+			}
+		}
+		_, err := NewEncodedMotor(config.Component{}, cfg, fakeMotor, e, logger)
+		test.That(t, err, test.ShouldNotBeNil)
+	}
+
+	// a second profile that is not between a constant and the endpoint is ambiguous with neither
+	cfg := Config{TicksPerRotation: 1000, ControlLoop: cascadedControlConfig()}
+	cfg.ControlLoop.Blocks = append(cfg.ControlLoop.Blocks, control.BlockConfig{
+		Name:      "trapezoid2",
+		Type:      "trapezoidalVelocityProfile",
+		Attribute: config.AttributeMap{"max_acc": 5000., "max_vel": 1000.},
+		DependsOn: []string{"set_point", "endpoint"},
+	})
+	_, err := NewEncodedMotor(config.Component{}, cfg, fakeMotor, e, logger)
+	test.That(t, err, test.ShouldNotBeNil)
+	test.That(t, err.Error(), test.ShouldContainSubstring, "exactly one trapezoidalVelocityProfile")
+
+	// without a loop there is nothing to tune
+	m, err := NewEncodedMotor(config.Component{}, Config{TicksPerRotation: 1000}, fakeMotor, e, logger)
+	test.That(t, err, test.ShouldBeNil)
+	_, err = m.DoCommand(context.Background(), map[string]interface{}{"command": "control_blocks"})
+	test.That(t, err, test.ShouldNotBeNil)
+	test.That(t, utils.TryClose(context.Background(), m), test.ShouldBeNil)
+}
+
+func TestMoveBlocks(t *testing.T) {
+	setPoint, trapezoidal, err := moveBlocks(cascadedControlConfig())
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, setPoint, test.ShouldEqual, "set_point")
+	test.That(t, trapezoidal.Name, test.ShouldEqual, "trapezoid")
+
+	t.Run("any block names", func(t *testing.T) {
+		cfg := cascadedControlConfig()
+		renames := map[string]string{"set_point": "target", "endpoint": "motor_out", "trapezoid": "profile"}
+		for i, b := range cfg.Blocks {
+			if name, ok := renames[b.Name]; ok {
+				cfg.Blocks[i].Name = name
+			}
+			for j, dep := range b.DependsOn {
+				if name, ok := renames[dep]; ok {
+					cfg.Blocks[i].DependsOn[j] = name
+				}
+			}
+		}
+		setPoint, trapezoidal, err := moveBlocks(cfg)
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, setPoint, test.ShouldEqual, "target")
+		test.That(t, trapezoidal.Name, test.ShouldEqual, "profile")
+	})
+
+	t.Run("other velocity profiles", func(t *testing.T) {
+		cfg := cascadedControlConfig()
+		cfg.Blocks = append(cfg.Blocks,
+			control.BlockConfig{Name: "speed_limit", Type: "constant", Attribute: config.AttributeMap{"constant_val": 0.}},
+			control.BlockConfig{
+				Name:      "smoothing",
+				Type:      "trapezoidalVelocityProfile",
+				Attribute: config.AttributeMap{"max_acc": 100., "max_vel": 100.},
+				DependsOn: []string{"speed_limit", "velocity"},
+			})
+		_, trapezoidal, err := moveBlocks(cfg)
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, trapezoidal.Name, test.ShouldEqual, "trapezoid")
+	})
+
+	t.Run("set point among constants", func(t *testing.T) {
+		cfg := cascadedControlConfig()
+		cfg.Blocks = append(cfg.Blocks, control.BlockConfig{Name: "offset", Type: "constant", Attribute: config.AttributeMap{"constant_val": 0.}})
+		for i, b := range cfg.Blocks {
+			if b.Name == "trapezoid" {
+				cfg.Blocks[i].DependsOn = []string{"offset", "set_point", "endpoint"}
+			}
+		}
+		setPoint, _, err := moveBlocks(cfg)
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, setPoint, test.ShouldEqual, "set_point")
+	})
+}
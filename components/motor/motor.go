@@ -10,6 +10,7 @@ import (
 	"go.viam.com/utils/rpc"
 
 	"go.viam.com/rdk/components/generic"
+	"go.viam.com/rdk/control"
 	"go.viam.com/rdk/data"
 	"go.viam.com/rdk/registry"
 	"go.viam.com/rdk/resource"
@@ -45,6 +46,10 @@ func init() {
 		Subtype:    SubtypeName,
 		MethodName: isPowered.String(),
 	}, newIsPoweredCollector)
+	data.RegisterCollector(data.MethodMetadata{
+		Subtype:    SubtypeName,
+		MethodName: control.TelemetryMethodName,
+	}, control.NewTelemetryCollector)
 }
 
 // SubtypeName is a constant that identifies the component resource subtype string "motor".
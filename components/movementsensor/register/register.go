@@ -9,4 +9,7 @@ import (
 	_ "go.viam.com/rdk/components/movementsensor/gpsrtk"
 	_ "go.viam.com/rdk/components/movementsensor/imuvectornav"
 	_ "go.viam.com/rdk/components/movementsensor/imuwit"
+	_ "go.viam.com/rdk/components/movementsensor/replay"
+	_ "go.viam.com/rdk/components/movementsensor/rosbag"
+	_ "go.viam.com/rdk/components/movementsensor/wheeledodometry"
 )
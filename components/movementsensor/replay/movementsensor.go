@@ -0,0 +1,159 @@
+// Package replay implements a movement sensor that replays the readings of a movement sensor from data capture
+// files.
+package replay
+
+import (
+	"context"
+	"strings"
+
+	"github.com/edaniels/golog"
+	"github.com/golang/geo/r3"
+	geo "github.com/kellydunn/golang-geo"
+	"google.golang.org/protobuf/types/known/structpb"
+
+	"go.viam.com/rdk/components/movementsensor"
+	"go.viam.com/rdk/config"
+	"go.viam.com/rdk/registry"
+	"go.viam.com/rdk/services/datamanager/datacapture/replay"
+	"go.viam.com/rdk/spatialmath"
+)
+
+const modelname = "replay"
+
+// The methods whose readings the data manager captures from movement sensors.
+const (
+	position        = "Position"
+	linearVelocity  = "LinearVelocity"
+	angularVelocity = "AngularVelocity"
+	compassHeading  = "CompassHeading"
+)
+
+func init() {
+	registry.RegisterComponent(
+		movementsensor.Subtype,
+		modelname,
+		registry.Component{Constructor: func(
+			ctx context.Context,
+			deps registry.Dependencies,
+			cfg config.Component,
+			logger golog.Logger,
+		) (interface{}, error) {
+			return newMovementSensor(*cfg.ConvertedAttributes.(*replay.Config))
+		}})
+
+	config.RegisterComponentAttributeMapConverter(movementsensor.SubtypeName, modelname,
+		func(attributes config.AttributeMap) (interface{}, error) {
+			var conf replay.Config
+			return config.TransformAttributeMapToStruct(&conf, attributes)
+		},
+		&replay.Config{})
+}
+
+func newMovementSensor(cfg replay.Config) (movementsensor.MovementSensor, error) {
+	r, err := replay.New(cfg, movementsensor.SubtypeName)
+	if err != nil {
+		return nil, err
+	}
+	return &MovementSensor{replay: r}, nil
+}
+
+// MovementSensor replays the readings captured from a movement sensor. Only the methods that were captured are
+// supported.
+type MovementSensor struct {
+	replay *replay.Replay
+}
+
+// next returns the fields of the reading of method at the current time of the replay, or unimplemented if the
+// recording has none.
+func (ms *MovementSensor) next(ctx context.Context, method string, unimplemented error) (map[string]*structpb.Value, error) {
+	if !ms.replay.HasMethod(method) {
+		return nil, unimplemented
+	}
+	reading, err := ms.replay.Next(ctx, method)
+	if err != nil {
+		return nil, err
+	}
+	return reading.GetStruct().GetFields(), nil
+}
+
+// field returns the number named name in fields, whatever the case of its name, since structs without JSON tags are
+// captured with the names of their Go fields.
+func field(fields map[string]*structpb.Value, name string) float64 {
+	for k, v := range fields {
+		if strings.EqualFold(k, name) {
+			return v.GetNumberValue()
+		}
+	}
+	return 0
+}
+
+// Position returns the replayed position. Altitude is not captured and is always 0.
+func (ms *MovementSensor) Position(ctx context.Context, extra map[string]interface{}) (*geo.Point, float64, error) {
+	fields, err := ms.next(ctx, position, movementsensor.ErrMethodUnimplementedPosition)
+	if err != nil {
+		return nil, 0, err
+	}
+	return geo.NewPoint(field(fields, "lat"), field(fields, "lng")), 0, nil
+}
+
+// LinearVelocity returns the replayed linear velocity.
+func (ms *MovementSensor) LinearVelocity(ctx context.Context, extra map[string]interface{}) (r3.Vector, error) {
+	fields, err := ms.next(ctx, linearVelocity, movementsensor.ErrMethodUnimplementedLinearVelocity)
+	if err != nil {
+		return r3.Vector{}, err
+	}
+	return r3.Vector{X: field(fields, "x"), Y: field(fields, "y"), Z: field(fields, "z")}, nil
+}
+
+// AngularVelocity returns the replayed angular velocity.
+func (ms *MovementSensor) AngularVelocity(ctx context.Context, extra map[string]interface{}) (spatialmath.AngularVelocity, error) {
+	fields, err := ms.next(ctx, angularVelocity, movementsensor.ErrMethodUnimplementedAngularVelocity)
+	if err != nil {
+		return spatialmath.AngularVelocity{}, err
+	}
+	return spatialmath.AngularVelocity{X: field(fields, "x"), Y: field(fields, "y"), Z: field(fields, "z")}, nil
+}
+
+// CompassHeading returns the replayed compass heading.
+func (ms *MovementSensor) CompassHeading(ctx context.Context, extra map[string]interface{}) (float64, error) {
+	fields, err := ms.next(ctx, compassHeading, movementsensor.ErrMethodUnimplementedCompassHeading)
+	if err != nil {
+		return 0, err
+	}
+	return field(fields, "heading"), nil
+}
+
+// Orientation is unimplemented since the data manager does not capture it.
+func (ms *MovementSensor) Orientation(ctx context.Context, extra map[string]interface{}) (spatialmath.Orientation, error) {
+	return nil, movementsensor.ErrMethodUnimplementedOrientation
+}
+
+// Accuracy is unimplemented since the data manager does not capture it.
+func (ms *MovementSensor) Accuracy(ctx context.Context, extra map[string]interface{}) (map[string]float32, error) {
+	return nil, movementsensor.ErrMethodUnimplementedAccuracy
+}
+
+// Readings returns the readings of all captured methods.
+func (ms *MovementSensor) Readings(ctx context.Context, extra map[string]interface{}) (map[string]interface{}, error) {
+	return movementsensor.Readings(ctx, ms, extra)
+}
+
+// Properties returns which methods were captured.
+func (ms *MovementSensor) Properties(ctx context.Context, extra map[string]interface{}) (*movementsensor.Properties, error) {
+	return &movementsensor.Properties{
+		PositionSupported:        ms.replay.HasMethod(position),
+		LinearVelocitySupported:  ms.replay.HasMethod(linearVelocity),
+		AngularVelocitySupported: ms.replay.HasMethod(angularVelocity),
+		CompassHeadingSupported:  ms.replay.HasMethod(compassHeading),
+	}, nil
+}
+
+// DoCommand seeks the replay.
+func (ms *MovementSensor) DoCommand(ctx context.Context, cmd map[string]interface{}) (map[string]interface{}, error) {
+	return ms.replay.DoCommand(ctx, cmd)
+}
+
+// Close closes the files being replayed.
+func (ms *MovementSensor) Close() error {
+	return ms.replay.Close()
+}
@@ -0,0 +1,61 @@
+package replay
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/golang/geo/r3"
+	v1 "go.viam.com/api/app/datasync/v1"
+	"go.viam.com/test"
+
+	"go.viam.com/rdk/components/movementsensor"
+	"go.viam.com/rdk/services/datamanager/datacapture/replay"
+	"go.viam.com/rdk/testutils"
+)
+
+func TestReplayMovementSensor(t *testing.T) {
+	dir := t.TempDir()
+	captured := time.Date(2022, 11, 1, 12, 0, 0, 0, time.UTC)
+	// Readings are captured the way the movement sensor collectors capture them.
+	for method, reading := range map[string]interface{}{
+		position:       struct{ Lat, Lng float64 }{Lat: 40.7, Lng: -73.98},
+		linearVelocity: r3.Vector{Y: 5.4},
+		compassHeading: struct{ Heading float64 }{Heading: 25},
+	} {
+		testutils.WriteCaptureFile(t, dir, &v1.DataCaptureMetadata{
+			ComponentType: string(movementsensor.SubtypeName),
+			ComponentName: "gps",
+			MethodName:    method,
+		}, testutils.TabularReading(t, captured, reading))
+	}
+
+	ctx := context.Background()
+	ms, err := newMovementSensor(replay.Config{Source: dir})
+	test.That(t, err, test.ShouldBeNil)
+	defer ms.(*MovementSensor).Close()
+
+	props, err := ms.Properties(ctx, nil)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, props, test.ShouldResemble, &movementsensor.Properties{
+		PositionSupported:       true,
+		LinearVelocitySupported: true,
+		CompassHeadingSupported: true,
+	})
+	p, _, err := ms.Position(ctx, nil)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, p.Lat(), test.ShouldEqual, 40.7)
+	test.That(t, p.Lng(), test.ShouldEqual, -73.98)
+	v, err := ms.LinearVelocity(ctx, nil)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, v, test.ShouldResemble, r3.Vector{Y: 5.4})
+	heading, err := ms.CompassHeading(ctx, nil)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, heading, test.ShouldEqual, 25)
+	_, err = ms.AngularVelocity(ctx, nil)
+	test.That(t, err, test.ShouldBeError, movementsensor.ErrMethodUnimplementedAngularVelocity)
+
+	readings, err := ms.Readings(ctx, nil)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, readings["compass"], test.ShouldEqual, 25)
+}
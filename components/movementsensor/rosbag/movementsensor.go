@@ -0,0 +1,189 @@
+// Package rosbag implements a movement sensor that plays the IMU, GPS or odometry messages of a topic of a ROS bag.
+package rosbag
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/edaniels/golog"
+	"github.com/golang/geo/r3"
+	geo "github.com/kellydunn/golang-geo"
+	"github.com/pkg/errors"
+
+	"go.viam.com/rdk/components/movementsensor"
+	"go.viam.com/rdk/config"
+	"go.viam.com/rdk/registry"
+	"go.viam.com/rdk/ros"
+	"go.viam.com/rdk/spatialmath"
+	"go.viam.com/rdk/utils"
+)
+
+const modelname = "rosbag"
+
+func init() {
+	registry.RegisterComponent(
+		movementsensor.Subtype,
+		modelname,
+		registry.Component{Constructor: func(
+			ctx context.Context,
+			deps registry.Dependencies,
+			cfg config.Component,
+			logger golog.Logger,
+		) (interface{}, error) {
+			return newMovementSensor(*cfg.ConvertedAttributes.(*ros.TopicConfig))
+		}})
+
+	config.RegisterComponentAttributeMapConverter(movementsensor.SubtypeName, modelname,
+		func(attributes config.AttributeMap) (interface{}, error) {
+			var conf ros.TopicConfig
+			return config.TransformAttributeMapToStruct(&conf, attributes)
+		},
+		&ros.TopicConfig{})
+}
+
+func newMovementSensor(cfg ros.TopicConfig) (movementsensor.MovementSensor, error) {
+	player, err := ros.NewPlayer(cfg)
+	if err != nil {
+		return nil, err
+	}
+	switch player.MessageType() {
+	case ros.ImuType, ros.NavSatFixType, ros.OdometryType:
+	default:
+		return nil, errors.Errorf("cannot play topic %s of type %s as a movement sensor, must be of type %s, %s or %s",
+			cfg.Topic, player.MessageType(), ros.ImuType, ros.NavSatFixType, ros.OdometryType)
+	}
+	return &MovementSensor{player: player}, nil
+}
+
+// MovementSensor plays the sensor_msgs/Imu, sensor_msgs/NavSatFix or nav_msgs/Odometry messages of a topic of a ROS
+// bag. Only the methods that messages of the type of the topic have readings for are supported.
+type MovementSensor struct {
+	player *ros.Player
+}
+
+// latest unmarshals the latest message of the topic into msg if it is of type msgType, or returns unimplemented.
+func (ms *MovementSensor) latest(ctx context.Context, msgType string, msg interface{}, unimplemented error) error {
+	if ms.player.MessageType() != msgType {
+		return unimplemented
+	}
+	bagMsg, _, err := ms.player.Next(ctx, -1)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(bagMsg.Data, msg)
+}
+
+// Position returns the position and altitude in meters of a NavSatFix topic.
+func (ms *MovementSensor) Position(ctx context.Context, extra map[string]interface{}) (*geo.Point, float64, error) {
+	var fix ros.NavSatFix
+	if err := ms.latest(ctx, ros.NavSatFixType, &fix, movementsensor.ErrMethodUnimplementedPosition); err != nil {
+		return nil, 0, err
+	}
+	return geo.NewPoint(fix.Latitude, fix.Longitude), fix.Altitude, nil
+}
+
+// LinearVelocity returns the linear velocity of an Odometry topic in mm/s.
+func (ms *MovementSensor) LinearVelocity(ctx context.Context, extra map[string]interface{}) (r3.Vector, error) {
+	var odom ros.Odometry
+	if err := ms.latest(ctx, ros.OdometryType, &odom, movementsensor.ErrMethodUnimplementedLinearVelocity); err != nil {
+		return r3.Vector{}, err
+	}
+	return metersToMillimeters(odom.Twist.Twist.Linear), nil
+}
+
+// AngularVelocity returns the angular velocity of an Imu or Odometry topic.
+func (ms *MovementSensor) AngularVelocity(ctx context.Context, extra map[string]interface{}) (spatialmath.AngularVelocity, error) {
+	var vel ros.Vector3
+	switch ms.player.MessageType() {
+	case ros.ImuType:
+		var imu ros.ImuData
+		if err := ms.latest(ctx, ros.ImuType, &imu, nil); err != nil {
+			return spatialmath.AngularVelocity{}, err
+		}
+		vel = imu.AngularVelocity
+	case ros.OdometryType:
+		var odom ros.Odometry
+		if err := ms.latest(ctx, ros.OdometryType, &odom, nil); err != nil {
+			return spatialmath.AngularVelocity{}, err
+		}
+		vel = odom.Twist.Twist.Angular
+	default:
+		return spatialmath.AngularVelocity{}, movementsensor.ErrMethodUnimplementedAngularVelocity
+	}
+	return spatialmath.AngularVelocity{X: utils.RadToDeg(vel.X), Y: utils.RadToDeg(vel.Y), Z: utils.RadToDeg(vel.Z)}, nil
+}
+
+// CompassHeading is unimplemented since none of the messages played have a heading.
+func (ms *MovementSensor) CompassHeading(ctx context.Context, extra map[string]interface{}) (float64, error) {
+	return 0, movementsensor.ErrMethodUnimplementedCompassHeading
+}
+
+// Orientation returns the orientation of an Imu topic that has one or of an Odometry topic.
+func (ms *MovementSensor) Orientation(ctx context.Context, extra map[string]interface{}) (spatialmath.Orientation, error) {
+	switch ms.player.MessageType() {
+	case ros.ImuType:
+		var imu ros.ImuData
+		if err := ms.latest(ctx, ros.ImuType, &imu, nil); err != nil {
+			return nil, err
+		}
+		// IMUs without an orientation estimate set the first element of its covariance to -1.
+		if imu.OrientationCovariance[0] == -1 {
+			return nil, movementsensor.ErrMethodUnimplementedOrientation
+		}
+		return imu.Orientation.Orientation(), nil
+	case ros.OdometryType:
+		var odom ros.Odometry
+		if err := ms.latest(ctx, ros.OdometryType, &odom, nil); err != nil {
+			return nil, err
+		}
+		return odom.Pose.Pose.Orientation.Orientation(), nil
+	default:
+		return nil, movementsensor.ErrMethodUnimplementedOrientation
+	}
+}
+
+// Accuracy is unimplemented.
+func (ms *MovementSensor) Accuracy(ctx context.Context, extra map[string]interface{}) (map[string]float32, error) {
+	return nil, movementsensor.ErrMethodUnimplementedAccuracy
+}
+
+// Readings returns the readings of all supported methods, and the linear acceleration of an Imu topic in mm/s^2.
+func (ms *MovementSensor) Readings(ctx context.Context, extra map[string]interface{}) (map[string]interface{}, error) {
+	readings, err := movementsensor.Readings(ctx, ms, extra)
+	if err != nil {
+		return nil, err
+	}
+	if ms.player.MessageType() == ros.ImuType {
+		var imu ros.ImuData
+		if err := ms.latest(ctx, ros.ImuType, &imu, nil); err != nil {
+			return nil, err
+		}
+		readings["linear_acceleration"] = metersToMillimeters(imu.LinearAcceleration)
+	}
+	return readings, nil
+}
+
+// Properties returns which methods are supported by the type of the topic.
+func (ms *MovementSensor) Properties(ctx context.Context, extra map[string]interface{}) (*movementsensor.Properties, error) {
+	msgType := ms.player.MessageType()
+	return &movementsensor.Properties{
+		PositionSupported:        msgType == ros.NavSatFixType,
+		LinearVelocitySupported:  msgType == ros.OdometryType,
+		AngularVelocitySupported: msgType == ros.ImuType || msgType == ros.OdometryType,
+		OrientationSupported:     msgType == ros.ImuType || msgType == ros.OdometryType,
+	}, nil
+}
+
+// DoCommand restarts the topic.
+func (ms *MovementSensor) DoCommand(ctx context.Context, cmd map[string]interface{}) (map[string]interface{}, error) {
+	return ms.player.DoCommand(ctx, cmd)
+}
+
+// Close does nothing since the bag is read when the movement sensor is created.
+func (ms *MovementSensor) Close() error {
+	return nil
+}
+
+func metersToMillimeters(v ros.Vector3) r3.Vector {
+	return r3.Vector{X: 1000 * v.X, Y: 1000 * v.Y, Z: 1000 * v.Z}
+}
@@ -0,0 +1,371 @@
+// Package wheeledodometry implements a movement sensor that dead reckons the pose of a wheeled base from
+// the positions of its motors, optionally correcting its heading with the yaw of an IMU.
+package wheeledodometry
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/edaniels/golog"
+	"github.com/golang/geo/r3"
+	geo "github.com/kellydunn/golang-geo"
+	"github.com/pkg/errors"
+	"go.viam.com/utils"
+
+	"go.viam.com/rdk/components/base"
+	"go.viam.com/rdk/components/base/wheeled"
+	"go.viam.com/rdk/components/generic"
+	"go.viam.com/rdk/components/motor"
+	"go.viam.com/rdk/components/movementsensor"
+	"go.viam.com/rdk/config"
+	"go.viam.com/rdk/registry"
+	"go.viam.com/rdk/spatialmath"
+	rdkutils "go.viam.com/rdk/utils"
+)
+
+const (
+	modelname             = "wheeled-odometry"
+	defaultUpdateInterval = 50 * time.Millisecond
+	resetCommand          = "reset"
+)
+
+// AttrConfig is used for converting config attributes of a wheeled odometry movement sensor. The motors
+// and the geometry of the wheels are read from the wheeled base.
+type AttrConfig struct {
+	Base string `json:"base"`
+	// IMU is an optional movement sensor whose yaw corrects the heading, weighted by IMUYawWeight between 0
+	// for wheels only and 1, the default, for the IMU only.
+	IMU          string   `json:"imu,omitempty"`
+	IMUYawWeight *float64 `json:"imu_yaw_weight,omitempty"`
+	// The pose starts at the origin facing the origin heading, in degrees clockwise from north, so that
+	// positions can be reported as coordinates.
+	OriginLatitude    float64 `json:"origin_latitude,omitempty"`
+	OriginLongitude   float64 `json:"origin_longitude,omitempty"`
+	OriginHeadingDegs float64 `json:"origin_heading_degs,omitempty"`
+	UpdateIntervalMs  int     `json:"update_interval_msec,omitempty"`
+}
+
+// Validate ensures all parts of the config are valid.
+func (cfg *AttrConfig) Validate(path string) ([]string, error) {
+	var deps []string
+	if cfg.Base == "" {
+		return nil, utils.NewConfigValidationFieldRequiredError(path, "base")
+	}
+	if cfg.IMUYawWeight != nil && (*cfg.IMUYawWeight < 0 || *cfg.IMUYawWeight > 1) {
+		return nil, utils.NewConfigValidationError(path, errors.New("imu_yaw_weight should be between 0 and 1"))
+	}
+	if cfg.UpdateIntervalMs < 0 {
+		return nil, utils.NewConfigValidationError(path, errors.New("update_interval_msec cannot be negative"))
+	}
+	deps = append(deps, cfg.Base)
+	if cfg.IMU != "" {
+		deps = append(deps, cfg.IMU)
+	}
+	return deps, nil
+}
+
+func init() {
+	registry.RegisterComponent(
+		movementsensor.Subtype,
+		modelname,
+		registry.Component{
+			Constructor: func(
+				ctx context.Context,
+				deps registry.Dependencies,
+				config config.Component,
+				logger golog.Logger,
+			) (interface{}, error) {
+				return newWheeledOdometry(ctx, deps, config, logger)
+			},
+		})
+	config.RegisterComponentAttributeMapConverter(
+		movementsensor.SubtypeName,
+		modelname,
+		func(attributes config.AttributeMap) (interface{}, error) {
+			var conf AttrConfig
+			return config.TransformAttributeMapToStruct(&conf, attributes)
+		},
+		&AttrConfig{})
+}
+
+// pose is where the base is relative to where it started, with x pointing the way it was facing and y to
+// its left, in mm. theta is its heading in radians counterclockwise from the way it was facing.
+type pose struct {
+	x, y, theta float64
+}
+
+type wheeledOdometry struct {
+	generic.Unimplemented
+	left, right          []motor.Motor
+	widthMM              float64
+	wheelCircumferenceMM float64
+	imu                  movementsensor.MovementSensor
+	imuYawWeight         float64
+	origin               *geo.Point
+	originHeading        float64
+
+	mu             sync.Mutex
+	pose           pose
+	linearVel      float64
+	angularVel     float64
+	lastLeft       float64
+	lastRight      float64
+	lastIMUYaw     float64
+	imuYawOffset   float64
+	lastUpdate     time.Time
+	lastErr        error
+	initialized    bool
+	imuInitialized bool
+
+	cancelFunc              func()
+	activeBackgroundWorkers sync.WaitGroup
+	logger                  golog.Logger
+}
+
+func newWheeledOdometry(
+	ctx context.Context,
+	deps registry.Dependencies,
+	config config.Component,
+	logger golog.Logger,
+) (movementsensor.MovementSensor, error) {
+	conf, ok := config.ConvertedAttributes.(*AttrConfig)
+	if !ok {
+		return nil, rdkutils.NewUnexpectedTypeError(conf, config.ConvertedAttributes)
+	}
+
+	b, err := base.FromDependencies(deps, conf.Base)
+	if err != nil {
+		return nil, err
+	}
+	geometry, err := wheeled.GeometryOf(b)
+	if err != nil {
+		return nil, errors.Wrapf(err, "base (%s)", conf.Base)
+	}
+
+	o := &wheeledOdometry{
+		left:                 geometry.Left,
+		right:                geometry.Right,
+		widthMM:              float64(geometry.WidthMM),
+		wheelCircumferenceMM: float64(geometry.WheelCircumferenceMM),
+		imuYawWeight:         1,
+		origin:               geo.NewPoint(conf.OriginLatitude, conf.OriginLongitude),
+		originHeading:        conf.OriginHeadingDegs,
+		logger:               logger,
+	}
+	if conf.IMU != "" {
+		imu, err := movementsensor.FromDependencies(deps, conf.IMU)
+		if err != nil {
+			return nil, err
+		}
+		o.imu = imu
+		if conf.IMUYawWeight != nil {
+			o.imuYawWeight = *conf.IMUYawWeight
+		}
+	}
+
+	// the first update is where the pose starts from
+	if err := o.update(ctx, time.Now()); err != nil {
+		return nil, err
+	}
+
+	interval := defaultUpdateInterval
+	if conf.UpdateIntervalMs > 0 {
+		interval = time.Duration(conf.UpdateIntervalMs) * time.Millisecond
+	}
+	var cancelCtx context.Context
+	cancelCtx, o.cancelFunc = context.WithCancel(context.Background())
+	o.activeBackgroundWorkers.Add(1)
+	utils.ManagedGo(func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-cancelCtx.Done():
+				return
+			case now := <-ticker.C:
+				if err := o.update(cancelCtx, now); err != nil && cancelCtx.Err() == nil {
+					o.logger.Debugw("error updating wheeled odometry", "error", err)
+				}
+			}
+		}
+	}, o.activeBackgroundWorkers.Done)
+	return o, nil
+}
+
+// averagePosition returns the average position of the motors, in revolutions.
+func averagePosition(ctx context.Context, motors []motor.Motor) (float64, error) {
+	sum := 0.
+	for _, m := range motors {
+		pos, err := m.Position(ctx, nil)
+		if err != nil {
+			return 0, err
+		}
+		sum += pos
+	}
+	return sum / float64(len(motors)), nil
+}
+
+// update reads the motors, and the IMU if there is one, and integrates the motion since the last update.
+func (o *wheeledOdometry) update(ctx context.Context, now time.Time) error {
+	left, right, yaw, err := o.read(ctx)
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.lastErr = err
+	if err != nil {
+		return err
+	}
+	o.integrate(left, right, yaw, now)
+	return nil
+}
+
+// read returns the average positions of the left and right motors and the yaw of the IMU, if there is one.
+func (o *wheeledOdometry) read(ctx context.Context) (float64, float64, float64, error) {
+	left, err := averagePosition(ctx, o.left)
+	if err != nil {
+		return 0, 0, 0, err
+	}
+	right, err := averagePosition(ctx, o.right)
+	if err != nil {
+		return 0, 0, 0, err
+	}
+	if o.imu == nil {
+		return left, right, 0, nil
+	}
+	orientation, err := o.imu.Orientation(ctx, nil)
+	if err != nil {
+		return 0, 0, 0, err
+	}
+	return left, right, orientation.EulerAngles().Yaw, nil
+}
+
+// integrate moves the pose along an arc from the last readings to these ones.
+func (o *wheeledOdometry) integrate(left, right, imuYaw float64, now time.Time) {
+	if !o.initialized {
+		o.lastLeft, o.lastRight, o.lastUpdate = left, right, now
+		o.initialized = true
+	}
+	if o.imu != nil && !o.imuInitialized {
+		o.imuYawOffset = imuYaw - o.pose.theta
+		o.lastIMUYaw = imuYaw
+		o.imuInitialized = true
+	}
+
+	leftMM := (left - o.lastLeft) * o.wheelCircumferenceMM
+	rightMM := (right - o.lastRight) * o.wheelCircumferenceMM
+	distance := (leftMM + rightMM) / 2
+	dTheta := (rightMM - leftMM) / o.widthMM
+	if o.imu != nil {
+		// the IMU yaw is unwrapped so that it can be blended with the continuous wheel heading
+		o.lastIMUYaw += wrapAngle(imuYaw - o.lastIMUYaw)
+		predicted := o.pose.theta + dTheta
+		dTheta += o.imuYawWeight * (o.lastIMUYaw - o.imuYawOffset - predicted)
+	}
+
+	// the base moves along the chord of the arc, which is shorter than the arc the wheels rolled along
+	chord := distance
+	if dTheta != 0 {
+		chord *= math.Sin(dTheta/2) / (dTheta / 2)
+	}
+	mid := o.pose.theta + dTheta/2
+	o.pose.x += chord * math.Cos(mid)
+	o.pose.y += chord * math.Sin(mid)
+	o.pose.theta += dTheta
+
+	if dt := now.Sub(o.lastUpdate).Seconds(); dt > 0 {
+		o.linearVel = distance / dt
+		o.angularVel = rdkutils.RadToDeg(dTheta) / dt
+	}
+	o.lastLeft, o.lastRight, o.lastUpdate = left, right, now
+}
+
+// wrapAngle returns the angle in (-pi, pi].
+func wrapAngle(a float64) float64 {
+	a = math.Mod(a+math.Pi, 2*math.Pi)
+	if a <= 0 {
+		a += 2 * math.Pi
+	}
+	return a - math.Pi
+}
+
+// Position returns the dead reckoned position relative to the configured origin. The altitude is always 0.
+func (o *wheeledOdometry) Position(ctx context.Context, extra map[string]interface{}) (*geo.Point, float64, error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	distanceKm := math.Hypot(o.pose.x, o.pose.y) / 1e6
+	bearing := o.originHeading - rdkutils.RadToDeg(math.Atan2(o.pose.y, o.pose.x))
+	return o.origin.PointAtDistanceAndBearing(distanceKm, bearing), 0, o.lastErr
+}
+
+// LinearVelocity returns the forward velocity of the base in mm/sec along the y axis.
+func (o *wheeledOdometry) LinearVelocity(ctx context.Context, extra map[string]interface{}) (r3.Vector, error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	return r3.Vector{Y: o.linearVel}, o.lastErr
+}
+
+// AngularVelocity returns the counterclockwise turning rate of the base in degrees/sec around the z axis.
+func (o *wheeledOdometry) AngularVelocity(ctx context.Context, extra map[string]interface{}) (spatialmath.AngularVelocity, error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	return spatialmath.AngularVelocity{Z: o.angularVel}, o.lastErr
+}
+
+// CompassHeading returns the heading of the base in degrees clockwise from north.
+func (o *wheeledOdometry) CompassHeading(ctx context.Context, extra map[string]interface{}) (float64, error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	heading := math.Mod(o.originHeading-rdkutils.RadToDeg(o.pose.theta), 360)
+	if heading < 0 {
+		heading += 360
+	}
+	return heading, o.lastErr
+}
+
+// Orientation returns the yaw of the base relative to the way it was facing when it started.
+func (o *wheeledOdometry) Orientation(ctx context.Context, extra map[string]interface{}) (spatialmath.Orientation, error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	return &spatialmath.EulerAngles{Yaw: wrapAngle(o.pose.theta)}, o.lastErr
+}
+
+func (o *wheeledOdometry) Accuracy(ctx context.Context, extra map[string]interface{}) (map[string]float32, error) {
+	return map[string]float32{}, movementsensor.ErrMethodUnimplementedAccuracy
+}
+
+func (o *wheeledOdometry) Readings(ctx context.Context, extra map[string]interface{}) (map[string]interface{}, error) {
+	return movementsensor.Readings(ctx, o, extra)
+}
+
+func (o *wheeledOdometry) Properties(ctx context.Context, extra map[string]interface{}) (*movementsensor.Properties, error) {
+	return &movementsensor.Properties{
+		PositionSupported:        true,
+		OrientationSupported:     true,
+		CompassHeadingSupported:  true,
+		AngularVelocitySupported: true,
+		LinearVelocitySupported:  true,
+	}, nil
+}
+
+// DoCommand supports "reset", which moves the pose back to the origin.
+func (o *wheeledOdometry) DoCommand(ctx context.Context, cmd map[string]interface{}) (map[string]interface{}, error) {
+	name, ok := cmd["command"]
+	if !ok {
+		return nil, errors.New("missing 'command' value")
+	}
+	if name != resetCommand {
+		return nil, errors.Errorf("no such command: %s", name)
+	}
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.pose = pose{}
+	o.imuInitialized = false
+	return map[string]interface{}{}, nil
+}
+
+// Close stops updating the pose.
+func (o *wheeledOdometry) Close() {
+	o.cancelFunc()
+	o.activeBackgroundWorkers.Wait()
+}
@@ -0,0 +1,293 @@
+package wheeledodometry
+
+import (
+	"context"
+	"math"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/edaniels/golog"
+	geo "github.com/kellydunn/golang-geo"
+	"github.com/pkg/errors"
+	"go.viam.com/test"
+
+	"go.viam.com/rdk/components/base"
+	"go.viam.com/rdk/components/base/wheeled"
+	"go.viam.com/rdk/components/motor"
+	"go.viam.com/rdk/components/movementsensor"
+	"go.viam.com/rdk/config"
+	"go.viam.com/rdk/registry"
+	"go.viam.com/rdk/spatialmath"
+	"go.viam.com/rdk/testutils/inject"
+)
+
+// wheels holds the positions, in revolutions, of the fake motors and the yaw of the fake IMU.
+type wheels struct {
+	mu          sync.Mutex
+	left, right float64
+	yaw         float64
+	err         error
+}
+
+func (w *wheels) set(left, right, yaw float64) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.left, w.right, w.yaw = left, right, yaw
+}
+
+func (w *wheels) motor(right bool) *inject.Motor {
+	m := &inject.Motor{}
+	m.PositionFunc = func(ctx context.Context, extra map[string]interface{}) (float64, error) {
+		w.mu.Lock()
+		defer w.mu.Unlock()
+		if right {
+			return w.right, w.err
+		}
+		return w.left, w.err
+	}
+	return m
+}
+
+func (w *wheels) dependencies(t *testing.T) registry.Dependencies {
+	t.Helper()
+	motors := make(registry.Dependencies)
+	for _, name := range []string{"fl", "bl"} {
+		motors[motor.Named(name)] = w.motor(false)
+	}
+	for _, name := range []string{"fr", "br"} {
+		motors[motor.Named(name)] = w.motor(true)
+	}
+	b, err := wheeled.CreateWheeledBase(context.Background(), motors, &wheeled.Config{
+		Left:                 []string{"fl", "bl"},
+		Right:                []string{"fr", "br"},
+		WidthMM:              500,
+		WheelCircumferenceMM: 1000,
+	}, golog.NewTestLogger(t))
+	test.That(t, err, test.ShouldBeNil)
+
+	deps := registry.Dependencies{base.Named("base"): b}
+	imu := &inject.MovementSensor{}
+	imu.OrientationFunc = func(ctx context.Context, extra map[string]interface{}) (spatialmath.Orientation, error) {
+		w.mu.Lock()
+		defer w.mu.Unlock()
+		return &spatialmath.EulerAngles{Yaw: w.yaw}, nil
+	}
+	deps[movementsensor.Named("imu")] = imu
+	return deps
+}
+
+func testConfig() *AttrConfig {
+	return &AttrConfig{
+		Base:             "base",
+		OriginLatitude:   40.7,
+		OriginLongitude:  -74,
+		UpdateIntervalMs: int(time.Hour / time.Millisecond),
+	}
+}
+
+func newTestOdometry(t *testing.T, w *wheels, cfg *AttrConfig) *wheeledOdometry {
+	t.Helper()
+	_, err := cfg.Validate("path")
+	test.That(t, err, test.ShouldBeNil)
+	ms, err := newWheeledOdometry(
+		context.Background(),
+		w.dependencies(t),
+		config.Component{Name: "odometry", ConvertedAttributes: cfg},
+		golog.NewTestLogger(t),
+	)
+	test.That(t, err, test.ShouldBeNil)
+	return ms.(*wheeledOdometry)
+}
+
+func TestValidate(t *testing.T) {
+	cfg := testConfig()
+	deps, err := cfg.Validate("path")
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, deps, test.ShouldResemble, []string{"base"})
+
+	cfg.IMU = "imu"
+	deps, err = cfg.Validate("path")
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, deps, test.ShouldResemble, []string{"base", "imu"})
+
+	weight := 1.5
+	cfg.IMUYawWeight = &weight
+	_, err = cfg.Validate("path")
+	test.That(t, err, test.ShouldNotBeNil)
+
+	for _, mutate := range []func(cfg *AttrConfig){
+		func(cfg *AttrConfig) { cfg.Base = "" },
+		func(cfg *AttrConfig) { cfg.UpdateIntervalMs = -1 },
+	} {
+		cfg := testConfig()
+		mutate(cfg)
+		_, err := cfg.Validate("path")
+		test.That(t, err, test.ShouldNotBeNil)
+	}
+}
+
+func TestNotWheeledBase(t *testing.T) {
+	w := &wheels{}
+	deps := w.dependencies(t)
+	deps[base.Named("base")] = &inject.Base{}
+	_, err := newWheeledOdometry(
+		context.Background(),
+		deps,
+		config.Component{Name: "odometry", ConvertedAttributes: testConfig()},
+		golog.NewTestLogger(t),
+	)
+	test.That(t, err, test.ShouldNotBeNil)
+	test.That(t, err.Error(), test.ShouldContainSubstring, "wheeled base")
+}
+
+func TestOdometry(t *testing.T) {
+	ctx := context.Background()
+	w := &wheels{}
+	o := newTestOdometry(t, w, testConfig())
+	defer o.Close()
+	start := o.lastUpdate
+
+	props, err := o.Properties(ctx, nil)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, props.PositionSupported, test.ShouldBeTrue)
+	_, err = o.Accuracy(ctx, nil)
+	test.That(t, err, test.ShouldEqual, movementsensor.ErrMethodUnimplementedAccuracy)
+
+	t.Run("straight", func(t *testing.T) {
+		// one revolution of each wheel drives a meter north in a second
+		w.set(1, 1, 0)
+		test.That(t, o.update(ctx, start.Add(time.Second)), test.ShouldBeNil)
+
+		vel, err := o.LinearVelocity(ctx, nil)
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, vel.Y, test.ShouldAlmostEqual, 1000)
+		angVel, err := o.AngularVelocity(ctx, nil)
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, angVel.Z, test.ShouldAlmostEqual, 0)
+		heading, err := o.CompassHeading(ctx, nil)
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, heading, test.ShouldAlmostEqual, 0)
+
+		pos, alt, err := o.Position(ctx, nil)
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, alt, test.ShouldEqual, 0)
+		origin := geo.NewPoint(40.7, -74)
+		test.That(t, origin.GreatCircleDistance(pos), test.ShouldAlmostEqual, 0.001, 1e-6)
+		test.That(t, origin.BearingTo(pos), test.ShouldAlmostEqual, 0, 1e-3)
+	})
+
+	t.Run("spin", func(t *testing.T) {
+		// a quarter turn counterclockwise takes the right wheels a quarter of the circle of the base further
+		quarter := math.Pi * 500 / 4 / 1000
+		w.set(1-quarter, 1+quarter, 0)
+		test.That(t, o.update(ctx, start.Add(2*time.Second)), test.ShouldBeNil)
+
+		vel, err := o.LinearVelocity(ctx, nil)
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, vel.Y, test.ShouldAlmostEqual, 0)
+		angVel, err := o.AngularVelocity(ctx, nil)
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, angVel.Z, test.ShouldAlmostEqual, 90)
+		heading, err := o.CompassHeading(ctx, nil)
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, heading, test.ShouldAlmostEqual, 270)
+		orientation, err := o.Orientation(ctx, nil)
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, orientation.EulerAngles().Yaw, test.ShouldAlmostEqual, math.Pi/2)
+	})
+
+	t.Run("arc", func(t *testing.T) {
+		// half a circle of radius 1m to the left, taken in small steps, ends 2m west of where it started
+		o.pose = pose{}
+		lastLeft, lastRight := o.lastLeft, o.lastRight
+		steps := 100
+		arc := math.Pi * 1000 / 1000
+		for i := 1; i <= steps; i++ {
+			frac := float64(i) / float64(steps)
+			w.set(lastLeft+frac*arc*750/1000, lastRight+frac*arc*1250/1000, 0)
+			test.That(t, o.update(ctx, start.Add(2*time.Second+time.Duration(i)*10*time.Millisecond)), test.ShouldBeNil)
+		}
+		test.That(t, o.pose.x, test.ShouldAlmostEqual, 0, 1e-6)
+		test.That(t, o.pose.y, test.ShouldAlmostEqual, 2000, 1e-6)
+		test.That(t, o.pose.theta, test.ShouldAlmostEqual, math.Pi, 1e-9)
+		vel, err := o.LinearVelocity(ctx, nil)
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, vel.Y, test.ShouldAlmostEqual, arc*1000/float64(steps)/0.01, 1e-6)
+	})
+
+	t.Run("reset", func(t *testing.T) {
+		resp, err := o.DoCommand(ctx, map[string]interface{}{"command": "reset"})
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, resp, test.ShouldBeEmpty)
+		pos, _, err := o.Position(ctx, nil)
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, pos.Lat(), test.ShouldAlmostEqual, 40.7)
+		test.That(t, pos.Lng(), test.ShouldAlmostEqual, -74)
+
+		_, err = o.DoCommand(ctx, map[string]interface{}{"command": "nope"})
+		test.That(t, err, test.ShouldNotBeNil)
+		_, err = o.DoCommand(ctx, map[string]interface{}{})
+		test.That(t, err, test.ShouldNotBeNil)
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		w.mu.Lock()
+		w.err = errors.New("no encoder")
+		w.mu.Unlock()
+		test.That(t, o.update(ctx, start.Add(time.Hour)), test.ShouldNotBeNil)
+		_, err := o.LinearVelocity(ctx, nil)
+		test.That(t, err, test.ShouldNotBeNil)
+
+		w.mu.Lock()
+		w.err = nil
+		w.mu.Unlock()
+		test.That(t, o.update(ctx, start.Add(time.Hour)), test.ShouldBeNil)
+		readings, err := o.Readings(ctx, nil)
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, readings["compass"], test.ShouldNotBeNil)
+	})
+}
+
+func TestIMUFusion(t *testing.T) {
+	ctx := context.Background()
+	w := &wheels{}
+	w.set(0, 0, 3)
+	cfg := testConfig()
+	cfg.IMU = "imu"
+	cfg.OriginHeadingDegs = 90
+	o := newTestOdometry(t, w, cfg)
+	defer o.Close()
+	start := o.lastUpdate
+
+	// the wheels slip through a quarter turn but the IMU only turns an eighth, across the wrap around
+	quarter := math.Pi * 500 / 4 / 1000
+	w.set(-quarter, quarter, 3+math.Pi/4-2*math.Pi)
+	test.That(t, o.update(ctx, start.Add(time.Second)), test.ShouldBeNil)
+	orientation, err := o.Orientation(ctx, nil)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, orientation.EulerAngles().Yaw, test.ShouldAlmostEqual, math.Pi/4)
+	heading, err := o.CompassHeading(ctx, nil)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, heading, test.ShouldAlmostEqual, 45)
+	angVel, err := o.AngularVelocity(ctx, nil)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, angVel.Z, test.ShouldAlmostEqual, 45)
+
+	// with half the weight on the IMU the heading splits the difference
+	weight := 0.5
+	cfg.IMUYawWeight = &weight
+	w.set(0, 0, 1)
+	o2 := newTestOdometry(t, w, cfg)
+	defer o2.Close()
+	w.set(-quarter, quarter, 1+math.Pi/4)
+	test.That(t, o2.update(ctx, o2.lastUpdate.Add(time.Second)), test.ShouldBeNil)
+	test.That(t, o2.pose.theta, test.ShouldAlmostEqual, 3*math.Pi/8)
+
+	// after a reset the IMU is referenced again from the current yaw
+	_, err = o.DoCommand(ctx, map[string]interface{}{"command": "reset"})
+	test.That(t, err, test.ShouldBeNil)
+	w.set(-quarter, quarter, 1)
+	test.That(t, o.update(ctx, start.Add(2*time.Second)), test.ShouldBeNil)
+	test.That(t, o.pose.theta, test.ShouldAlmostEqual, 0)
+}
@@ -0,0 +1,46 @@
+package sensor
+
+import (
+	"context"
+
+	"google.golang.org/protobuf/types/known/anypb"
+
+	"go.viam.com/rdk/data"
+)
+
+type method int64
+
+const (
+	readings method = iota
+)
+
+func (m method) String() string {
+	if m == readings {
+		return "Readings"
+	}
+	return "Unknown"
+}
+
+func newReadingsCollector(resource interface{}, params data.CollectorParams) (data.Collector, error) {
+	sensor, err := assertSensor(resource)
+	if err != nil {
+		return nil, err
+	}
+
+	cFunc := data.CaptureFunc(func(ctx context.Context, _ map[string]*anypb.Any) (interface{}, error) {
+		v, err := sensor.Readings(ctx, nil)
+		if err != nil {
+			return nil, data.FailedToReadErr(params.ComponentName, readings.String(), err)
+		}
+		return v, nil
+	})
+	return data.NewCollector(cFunc, params)
+}
+
+func assertSensor(resource interface{}) (Sensor, error) {
+	sensor, ok := resource.(Sensor)
+	if !ok {
+		return nil, data.InvalidInterfaceErr(SubtypeName)
+	}
+	return sensor, nil
+}
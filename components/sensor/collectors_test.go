@@ -0,0 +1,28 @@
+package sensor_test
+
+import (
+	"context"
+	"testing"
+
+	"go.viam.com/test"
+
+	"go.viam.com/rdk/components/sensor"
+	"go.viam.com/rdk/data"
+	"go.viam.com/rdk/testutils"
+	"go.viam.com/rdk/testutils/inject"
+)
+
+func TestReadingsCollector(t *testing.T) {
+	injectSensor := &inject.Sensor{}
+	injectSensor.ReadingsFunc = func(ctx context.Context, extra map[string]interface{}) (map[string]interface{}, error) {
+		return map[string]interface{}{"temperature": 21.5, "unit": "celsius"}, nil
+	}
+	method := data.MethodMetadata{Subtype: sensor.SubtypeName, MethodName: "Readings"}
+
+	reading, err := testutils.CaptureReading(t, method, injectSensor, nil)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, reading.GetStruct().AsMap(), test.ShouldResemble, map[string]interface{}{"temperature": 21.5, "unit": "celsius"})
+
+	_, err = testutils.CaptureReading(t, method, "not a sensor", nil)
+	test.That(t, err, test.ShouldNotBeNil)
+}
@@ -7,5 +7,7 @@ import (
 	_ "go.viam.com/rdk/components/sensor/charge"
 	_ "go.viam.com/rdk/components/sensor/ds18b20"
 	_ "go.viam.com/rdk/components/sensor/fake"
+	_ "go.viam.com/rdk/components/sensor/replay"
+	_ "go.viam.com/rdk/components/sensor/rosbag"
 	_ "go.viam.com/rdk/components/sensor/ultrasonic"
 )
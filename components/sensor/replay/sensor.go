@@ -0,0 +1,67 @@
+// Package replay implements a sensor that replays the readings of a sensor from data capture files.
+package replay
+
+import (
+	"context"
+
+	"github.com/edaniels/golog"
+
+	"go.viam.com/rdk/components/sensor"
+	"go.viam.com/rdk/config"
+	"go.viam.com/rdk/registry"
+	"go.viam.com/rdk/services/datamanager/datacapture/replay"
+)
+
+const modelname = "replay"
+
+func init() {
+	registry.RegisterComponent(
+		sensor.Subtype,
+		modelname,
+		registry.Component{Constructor: func(
+			ctx context.Context,
+			deps registry.Dependencies,
+			config config.Component,
+			logger golog.Logger,
+		) (interface{}, error) {
+			return newSensor(*config.ConvertedAttributes.(*replay.Config))
+		}})
+
+	config.RegisterComponentAttributeMapConverter(sensor.SubtypeName, modelname,
+		func(attributes config.AttributeMap) (interface{}, error) {
+			var conf replay.Config
+			return config.TransformAttributeMapToStruct(&conf, attributes)
+		}, &replay.Config{})
+}
+
+func newSensor(cfg replay.Config) (sensor.Sensor, error) {
+	r, err := replay.New(cfg, sensor.SubtypeName)
+	if err != nil {
+		return nil, err
+	}
+	return &Sensor{replay: r}, nil
+}
+
+// Sensor replays the readings captured from a sensor.
+type Sensor struct {
+	replay *replay.Replay
+}
+
+// Readings returns the readings of the replay at its current time.
+func (s *Sensor) Readings(ctx context.Context, extra map[string]interface{}) (map[string]interface{}, error) {
+	reading, err := s.replay.Next(ctx, "Readings")
+	if err != nil {
+		return nil, err
+	}
+	return reading.GetStruct().AsMap(), nil
+}
+
+// DoCommand seeks the replay.
+func (s *Sensor) DoCommand(ctx context.Context, cmd map[string]interface{}) (map[string]interface{}, error) {
+	return s.replay.DoCommand(ctx, cmd)
+}
+
+// Close closes the files being replayed.
+func (s *Sensor) Close() error {
+	return s.replay.Close()
+}
@@ -0,0 +1,50 @@
+package replay
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	v1 "go.viam.com/api/app/datasync/v1"
+	"go.viam.com/test"
+
+	"go.viam.com/rdk/components/sensor"
+	"go.viam.com/rdk/services/datamanager/datacapture/replay"
+	"go.viam.com/rdk/testutils"
+)
+
+func TestReplaySensor(t *testing.T) {
+	dir := t.TempDir()
+	start := time.Date(2022, 11, 1, 12, 0, 0, 0, time.UTC)
+	testutils.WriteCaptureFile(t, dir, &v1.DataCaptureMetadata{
+		ComponentType: string(sensor.SubtypeName),
+		ComponentName: "sensor1",
+		MethodName:    "Readings",
+		Type:          v1.DataType_DATA_TYPE_TABULAR_SENSOR,
+	},
+		testutils.TabularReading(t, start, map[string]interface{}{"temp": 20.5}),
+		testutils.TabularReading(t, start.Add(time.Second), map[string]interface{}{"temp": 21.5}),
+	)
+
+	ctx := context.Background()
+	s, err := newSensor(replay.Config{Source: dir, Step: true})
+	test.That(t, err, test.ShouldBeNil)
+	defer func() {
+		test.That(t, s.(*Sensor).Close(), test.ShouldBeNil)
+	}()
+
+	for _, temp := range []float64{20.5, 21.5} {
+		readings, err := s.Readings(ctx, nil)
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, readings, test.ShouldResemble, map[string]interface{}{"temp": temp})
+	}
+	_, err = s.Readings(ctx, nil)
+	test.That(t, err, test.ShouldBeError, replay.ErrEnd)
+
+	// Seeking back to the start replays the readings again.
+	_, err = s.DoCommand(ctx, map[string]interface{}{"seek": 0.0})
+	test.That(t, err, test.ShouldBeNil)
+	readings, err := s.Readings(ctx, nil)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, readings, test.ShouldResemble, map[string]interface{}{"temp": 20.5})
+}
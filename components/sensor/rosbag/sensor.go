@@ -0,0 +1,67 @@
+// Package rosbag implements a sensor that plays the messages of a topic of a ROS bag.
+package rosbag
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/edaniels/golog"
+
+	"go.viam.com/rdk/components/sensor"
+	"go.viam.com/rdk/config"
+	"go.viam.com/rdk/registry"
+	"go.viam.com/rdk/ros"
+)
+
+const modelname = "rosbag"
+
+func init() {
+	registry.RegisterComponent(
+		sensor.Subtype,
+		modelname,
+		registry.Component{Constructor: func(
+			ctx context.Context,
+			deps registry.Dependencies,
+			config config.Component,
+			logger golog.Logger,
+		) (interface{}, error) {
+			return newSensor(*config.ConvertedAttributes.(*ros.TopicConfig))
+		}})
+
+	config.RegisterComponentAttributeMapConverter(sensor.SubtypeName, modelname,
+		func(attributes config.AttributeMap) (interface{}, error) {
+			var conf ros.TopicConfig
+			return config.TransformAttributeMapToStruct(&conf, attributes)
+		}, &ros.TopicConfig{})
+}
+
+func newSensor(cfg ros.TopicConfig) (sensor.Sensor, error) {
+	player, err := ros.NewPlayer(cfg)
+	if err != nil {
+		return nil, err
+	}
+	return &Sensor{player: player}, nil
+}
+
+// Sensor plays the messages of a topic of a ROS bag of any type.
+type Sensor struct {
+	player *ros.Player
+}
+
+// Readings returns the fields of the latest message of the topic.
+func (s *Sensor) Readings(ctx context.Context, extra map[string]interface{}) (map[string]interface{}, error) {
+	msg, _, err := s.player.Next(ctx, -1)
+	if err != nil {
+		return nil, err
+	}
+	readings := map[string]interface{}{}
+	if err := json.Unmarshal(msg.Data, &readings); err != nil {
+		return nil, err
+	}
+	return readings, nil
+}
+
+// DoCommand restarts the topic.
+func (s *Sensor) DoCommand(ctx context.Context, cmd map[string]interface{}) (map[string]interface{}, error) {
+	return s.player.DoCommand(ctx, cmd)
+}
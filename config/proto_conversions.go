@@ -128,9 +128,28 @@ func ComponentConfigFromProto(proto *pb.ComponentConfig) (*Component, error) {
 	return &component, nil
 }
 
+// serviceConfigAttribute is the attribute that the ServiceConfig of a Service is carried under in the attributes of its
+// proto, which has no field for it yet.
+const serviceConfigAttribute = "_service_config"
+
 // ServiceConfigToProto converts Service to proto equivalent.
 func ServiceConfigToProto(service *Service) (*pb.ServiceConfig, error) {
-	attributes, err := protoutils.StructToStructPb(service.Attributes)
+	attributes := map[string]interface{}(service.Attributes)
+	if len(service.ServiceConfig) > 0 {
+		attributes = make(map[string]interface{}, len(service.Attributes)+1)
+		for k, v := range service.Attributes {
+			attributes[k] = v
+		}
+		serviceConfigs := make([]interface{}, 0, len(service.ServiceConfig))
+		for _, sc := range service.ServiceConfig {
+			serviceConfigs = append(serviceConfigs, map[string]interface{}{
+				"type":       string(sc.Type),
+				"attributes": map[string]interface{}(sc.Attributes),
+			})
+		}
+		attributes[serviceConfigAttribute] = serviceConfigs
+	}
+	attributesPb, err := protoutils.StructToStructPb(attributes)
 	if err != nil {
 		return nil, err
 	}
@@ -139,7 +158,8 @@ func ServiceConfigToProto(service *Service) (*pb.ServiceConfig, error) {
 		Name:       service.Name,
 		Namespace:  string(service.Namespace),
 		Type:       string(service.Type),
-		Attributes: attributes,
+		Model:      service.Model,
+		Attributes: attributesPb,
 		DependsOn:  service.DependsOn,
 	}
 
@@ -148,17 +168,54 @@ func ServiceConfigToProto(service *Service) (*pb.ServiceConfig, error) {
 
 // ServiceConfigFromProto creates Service from proto equivalent.
 func ServiceConfigFromProto(proto *pb.ServiceConfig) (*Service, error) {
+	attributes := proto.GetAttributes().AsMap()
+	serviceConfigs, err := serviceConfigsFromAttribute(attributes)
+	if err != nil {
+		return nil, err
+	}
 	service := Service{
-		Name:       proto.GetName(),
-		Namespace:  resource.Namespace(proto.GetNamespace()),
-		Type:       ServiceType(proto.GetType()),
-		Attributes: proto.GetAttributes().AsMap(),
-		DependsOn:  proto.GetDependsOn(),
+		Name:          proto.GetName(),
+		Namespace:     resource.Namespace(proto.GetNamespace()),
+		Type:          ServiceType(proto.GetType()),
+		Model:         proto.GetModel(),
+		Attributes:    attributes,
+		DependsOn:     proto.GetDependsOn(),
+		ServiceConfig: serviceConfigs,
 	}
 
 	return &service, nil
 }
 
+// serviceConfigsFromAttribute removes the ServiceConfig that ServiceConfigToProto put into attributes and returns it.
+func serviceConfigsFromAttribute(attributes map[string]interface{}) ([]ResourceLevelServiceConfig, error) {
+	raw, ok := attributes[serviceConfigAttribute]
+	if !ok {
+		return nil, nil
+	}
+	delete(attributes, serviceConfigAttribute)
+	list, ok := raw.([]interface{})
+	if !ok {
+		return nil, errors.Errorf("expected %s to be a list but got %T", serviceConfigAttribute, raw)
+	}
+	serviceConfigs := make([]ResourceLevelServiceConfig, 0, len(list))
+	for i, r := range list {
+		encoded, ok := r.(map[string]interface{})
+		if !ok {
+			return nil, errors.Errorf("expected service config %d to be a map but got %T", i, r)
+		}
+		subtype, ok := encoded["type"].(string)
+		if !ok {
+			return nil, errors.Errorf("service config %d is missing its type", i)
+		}
+		sc := ResourceLevelServiceConfig{Type: resource.SubtypeName(subtype)}
+		if attrs, ok := encoded["attributes"].(map[string]interface{}); ok {
+			sc.Attributes = attrs
+		}
+		serviceConfigs = append(serviceConfigs, sc)
+	}
+	return serviceConfigs, nil
+}
+
 // ProcessConfigToProto converts ProcessConfig to proto equivalent.
 func ProcessConfigToProto(process *pexec.ProcessConfig) (*pb.ProcessConfig, error) {
 	return &pb.ProcessConfig{
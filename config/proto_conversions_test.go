@@ -8,6 +8,7 @@ import (
 	"go.viam.com/test"
 	"go.viam.com/utils/pexec"
 	"go.viam.com/utils/rpc"
+	"google.golang.org/protobuf/types/known/structpb"
 
 	spatial "go.viam.com/rdk/spatialmath"
 )
@@ -92,8 +93,21 @@ var testService = Service{
 	Name:      "some-name",
 	Namespace: "some-namespace",
 	Type:      "some-type",
+	Model:     "some-model",
+	DependsOn: []string{"dep1"},
 	Attributes: AttributeMap{
 		"attr1": 1,
+		"attr2": "attr-string",
+	},
+	ServiceConfig: []ResourceLevelServiceConfig{
+		{
+			Type: "data_manager",
+			Attributes: AttributeMap{
+				"capture_methods": []interface{}{
+					map[string]interface{}{"method": "Readings", "capture_frequency_hz": 1.0},
+				},
+			},
+		},
 	},
 }
 
@@ -330,6 +344,13 @@ func validateService(t *testing.T, actual, expected Service) {
 	test.That(t, actual.DependsOn, test.ShouldResemble, expected.DependsOn)
 	test.That(t, actual.Attributes.Int("attr1", 0), test.ShouldEqual, expected.Attributes.Int("attr1", -1))
 	test.That(t, actual.Attributes.String("attr2"), test.ShouldEqual, expected.Attributes.String("attr2"))
+	test.That(t, actual.Attributes.Has(serviceConfigAttribute), test.ShouldBeFalse)
+
+	test.That(t, actual.ServiceConfig, test.ShouldHaveLength, len(expected.ServiceConfig))
+	for i, sc := range expected.ServiceConfig {
+		test.That(t, actual.ServiceConfig[i].Type, test.ShouldEqual, sc.Type)
+		test.That(t, actual.ServiceConfig[i].Attributes, test.ShouldResemble, sc.Attributes)
+	}
 }
 
 func TestServiceConfigToProto(t *testing.T) {
@@ -340,6 +361,19 @@ func TestServiceConfigToProto(t *testing.T) {
 	test.That(t, err, test.ShouldBeNil)
 
 	validateService(t, *out, testService)
+	test.That(t, testService.Attributes.Has(serviceConfigAttribute), test.ShouldBeFalse)
+
+	// Services without service configs have no attribute for them.
+	proto, err = ServiceConfigToProto(&Service{Name: "some-name", Attributes: AttributeMap{"attr1": 1}})
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, proto.Attributes.AsMap(), test.ShouldResemble, map[string]interface{}{"attr1": 1.0})
+	out, err = ServiceConfigFromProto(proto)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, out.ServiceConfig, test.ShouldBeNil)
+
+	proto.Attributes.Fields[serviceConfigAttribute] = structpb.NewStringValue("data_manager")
+	_, err = ServiceConfigFromProto(proto)
+	test.That(t, err, test.ShouldNotBeNil)
 }
 
 func TestProcessConfigToProto(t *testing.T) {
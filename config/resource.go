@@ -233,6 +233,10 @@ type Service struct {
 	Attributes          AttributeMap `json:"attributes"`
 	ConvertedAttributes interface{}  `json:"-"`
 	ImplicitDependsOn   []string     `json:"-"`
+
+	// ServiceConfig configures other services for this service, such as what the data manager captures from it.
+	// The proto of service configs has no field for it yet, so it is carried in their attributes.
+	ServiceConfig []ResourceLevelServiceConfig `json:"service_config"`
 }
 
 // Ensure Service conforms to flag.Value.
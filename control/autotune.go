@@ -0,0 +1,358 @@
+package control
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/edaniels/golog"
+	"github.com/pkg/errors"
+)
+
+// TuneMethod is how the plant is excited to identify it while auto tuning a PID block.
+type TuneMethod string
+
+const (
+	// TuneMethodRelay replaces the PID with a relay switching its output around a bias on the sign of its
+	// input (Åström–Hägglund), which makes the loop oscillate at its ultimate period.
+	TuneMethodRelay TuneMethod = "relay"
+	// TuneMethodStep applies a step to the plant and fits a first order model to the response, as the
+	// tuning that is triggered by configuring a PID block with no gains does.
+	TuneMethodStep TuneMethod = "step"
+)
+
+const (
+	defaultTuneCycles      = 4
+	defaultTuneMaxDuration = 30 * time.Second
+	// defaultTuneAmplitude is the fraction of the output range of the PID the relay switches by.
+	defaultTuneAmplitude = 0.1
+)
+
+// TuneConfig configures an auto tuning run of a PID block.
+type TuneConfig struct {
+	// Method defaults to TuneMethodRelay.
+	Method TuneMethod
+	// Rule is one of the tune_method rules of the PID block used to turn the identified plant into gains. The
+	// Cohen-Coon rules need the step method.
+	Rule string
+	// Amplitude is how far the relay output swings either side of Bias. It defaults to a tenth of the output
+	// range of the PID, and the swing has to stay within the limits of the PID.
+	Amplitude float64
+	// Bias is the output the relay switches around. It defaults to the output of the PID when tuning starts,
+	// which is close to what holds the plant at its set point when the loop was settled.
+	Bias *float64
+	// Hysteresis is how far the input of the PID has to cross zero before the relay switches, which keeps
+	// noise from switching it.
+	Hysteresis float64
+	// Cycles is how many oscillations the relay measures, after one to let the loop settle.
+	Cycles int
+	// StepPct and SSRValue are the tune_step_pct and tune_ssr_value of the step method.
+	StepPct  float64
+	SSRValue float64
+	// MaxInput aborts tuning when the input of the PID gets further than this from zero. Zero means no limit.
+	MaxInput float64
+	// MaxDuration aborts tuning when it has not finished in time. It defaults to 30 seconds.
+	MaxDuration time.Duration
+	// Apply writes the computed gains to the config of the PID block.
+	Apply bool
+}
+
+// TuneResult is what an auto tuning run identified about the plant and the gains it computed from that.
+// Parameters the method does not identify are left at zero.
+type TuneResult struct {
+	Method TuneMethod
+	Rule   string
+	// UltimateGain and UltimatePeriod are where the loop oscillates under proportional control alone.
+	UltimateGain   float64
+	UltimatePeriod time.Duration
+	// ProcessGain, DeadTime and TimeConstant are the first order model fitted to a step response.
+	ProcessGain  float64
+	DeadTime     time.Duration
+	TimeConstant time.Duration
+	KP           float64
+	KI           float64
+	KD           float64
+	// Applied is whether the gains were written to the config of the PID block.
+	Applied bool
+}
+
+// Tune auto tunes the PID block with the given name while the loop runs. The name can be left empty when
+// the loop has a single PID block. The PID stops regulating while it is tuned and goes back to its previous
+// gains afterwards unless the new ones are applied. Tuning is aborted when it exceeds its limits, when the
+// context is done or when the PID block is reconfigured.
+func (l *Loop) Tune(ctx context.Context, name string, cfg TuneConfig) (TuneResult, error) {
+	pid, name, err := l.pidBlock(name)
+	if err != nil {
+		return TuneResult{}, err
+	}
+	if cfg.Method == "" {
+		cfg.Method = TuneMethodRelay
+	}
+	if cfg.Rule == "" {
+		cfg.Rule = string(tuneMethodZiegerNicholsPI)
+	}
+	if cfg.MaxDuration == 0 {
+		cfg.MaxDuration = defaultTuneMaxDuration
+	}
+	if cfg.Cycles == 0 {
+		cfg.Cycles = defaultTuneCycles
+	}
+
+	tuner, err := pid.startAutoTune(cfg)
+	if err != nil {
+		return TuneResult{}, err
+	}
+	l.logger.Infof("auto tuning pid block %s with the %s method", name, cfg.Method)
+	timer := time.NewTimer(cfg.MaxDuration)
+	defer timer.Stop()
+	select {
+	case <-tuner.done:
+	case <-timer.C:
+		pid.stopAutoTune(tuner, errors.Errorf("tuning pid block %s did not finish within %v", name, cfg.MaxDuration))
+	case <-ctx.Done():
+		pid.stopAutoTune(tuner, ctx.Err())
+	}
+	if tuner.err != nil {
+		return TuneResult{}, tuner.err
+	}
+
+	result := tuner.result
+	l.logger.Infof("calculated gains of pid block %s are Kp %1.6f, Ki: %1.6f, Kd: %1.6f", name, result.KP, result.KI, result.KD)
+	if cfg.Apply {
+		if err := l.UpdateAttributesAt(ctx, name, map[string]interface{}{
+			"kP": result.KP, "kI": result.KI, "kD": result.KD,
+		}); err != nil {
+			return TuneResult{}, err
+		}
+		result.Applied = true
+	}
+	return result, nil
+}
+
+// pidBlock returns the PID block with the given name, or the only one of the loop when name is empty.
+func (l *Loop) pidBlock(name string) (*basicPID, string, error) {
+	if name == "" {
+		for n, b := range l.blocks {
+			if b.blockType != blockPID {
+				continue
+			}
+			if name != "" {
+				return nil, "", errors.New("loop has more than one pid block, need the name of the one to tune")
+			}
+			name = n
+		}
+		if name == "" {
+			return nil, "", errors.New("loop has no pid block to tune")
+		}
+	}
+	b, ok := l.blocks[name]
+	if !ok {
+		return nil, "", errors.Errorf("cannot tune non existing block %s", name)
+	}
+	pid, ok := b.blk.(*basicPID)
+	if !ok {
+		return nil, "", errors.Errorf("block %s is a %s, only pid blocks can be tuned", name, b.blockType)
+	}
+	return pid, name, nil
+}
+
+// autoTuner runs in place of a PID while it is being tuned. next is called from the Next of the PID with
+// its input and returns the output to apply until it is finished.
+type autoTuner struct {
+	cfg    TuneConfig
+	rule   tuneCalcMethod
+	logger golog.Logger
+	limLo  float64
+	limUp  float64
+	bias   float64
+	output float64
+
+	// relay
+	elapsed   time.Duration
+	high      bool
+	started   bool
+	lastRise  time.Duration
+	risen     bool
+	peakHi    float64
+	peakLo    float64
+	periods   []time.Duration
+	peakPeaks []float64
+
+	// step
+	step *pidTuner
+
+	once   sync.Once
+	done   chan struct{}
+	result TuneResult
+	err    error
+}
+
+func newAutoTuner(cfg TuneConfig, limLo, limUp, output float64, logger golog.Logger) (*autoTuner, error) {
+	rule := tuneCalcMethod(cfg.Rule)
+	switch rule {
+	case tuneMethodZiegerNicholsPI, tuneMethodZiegerNicholsPID, tuneMethodZiegerNicholsSomeOvershoot,
+		tuneMethodZiegerNicholsNoOvershoot, tuneMethodTyreusLuybenPI, tuneMethodTyreusLuybenPID:
+	case tuneMethodCohenCoonsPI, tuneMethodCohenCoonsPID:
+		if cfg.Method != TuneMethodStep {
+			return nil, errors.Errorf("tuning rule %s needs the %s method", rule, TuneMethodStep)
+		}
+	default:
+		return nil, errors.Errorf("unknown tuning rule %s", rule)
+	}
+	if cfg.MaxDuration < 0 || cfg.MaxInput < 0 || cfg.Hysteresis < 0 || cfg.Cycles < 0 {
+		return nil, errors.New("tuning limits cannot be negative")
+	}
+	a := &autoTuner{cfg: cfg, rule: rule, logger: logger, limLo: limLo, limUp: limUp, done: make(chan struct{})}
+
+	switch cfg.Method {
+	case TuneMethodRelay:
+		a.bias = output
+		if cfg.Bias != nil {
+			a.bias = *cfg.Bias
+		}
+		if a.cfg.Amplitude == 0 {
+			a.cfg.Amplitude = defaultTuneAmplitude * (limUp - limLo)
+		}
+		if a.cfg.Amplitude < 0 {
+			return nil, errors.New("relay amplitude cannot be negative")
+		}
+		if a.bias+a.cfg.Amplitude > limUp || a.bias-a.cfg.Amplitude < limLo {
+			return nil, errors.Errorf("relay output %1.4f±%1.4f would exceed the pid limits [%1.4f, %1.4f]",
+				a.bias, a.cfg.Amplitude, limLo, limUp)
+		}
+		if a.cfg.Hysteresis >= a.cfg.MaxInput && a.cfg.MaxInput != 0 {
+			return nil, errors.New("relay hysteresis should be below the input limit")
+		}
+		a.output = a.bias
+	case TuneMethodStep:
+		step := &pidTuner{
+			limUp:      limUp,
+			limLo:      limLo,
+			ssRValue:   cfg.SSRValue,
+			tuneMethod: rule,
+			stepPct:    cfg.StepPct,
+		}
+		if step.ssRValue == 0 {
+			step.ssRValue = 2.0
+		}
+		if step.stepPct == 0 {
+			step.stepPct = 0.35
+		}
+		if step.stepPct > 1 || step.stepPct < 0 {
+			return nil, errors.New("step percentage should be between 0-1")
+		}
+		if err := step.reset(); err != nil {
+			return nil, err
+		}
+		a.step = step
+	default:
+		return nil, errors.Errorf("unknown tuning method %s", cfg.Method)
+	}
+	return a, nil
+}
+
+// next returns the output the PID should apply for input x and whether tuning is over.
+func (a *autoTuner) next(x float64, dt time.Duration) (float64, bool) {
+	if a.cfg.MaxInput != 0 && math.Abs(x) > a.cfg.MaxInput {
+		a.finish(TuneResult{}, errors.Errorf("tuning aborted, pid input %1.4f is beyond the limit of %1.4f", x, a.cfg.MaxInput))
+		return a.bias, true
+	}
+	if a.step != nil {
+		return a.nextStep(x)
+	}
+	return a.nextRelay(x, dt)
+}
+
+func (a *autoTuner) nextRelay(x float64, dt time.Duration) (float64, bool) {
+	a.elapsed += dt
+	if !a.started {
+		a.started = true
+		a.high = x > 0
+		a.peakHi, a.peakLo = x, x
+	}
+	a.peakHi = math.Max(a.peakHi, x)
+	a.peakLo = math.Min(a.peakLo, x)
+	switch {
+	case a.high && x < -a.cfg.Hysteresis:
+		a.high = false
+	case !a.high && x > a.cfg.Hysteresis:
+		a.high = true
+		// a cycle is measured from one switch to the high side to the next
+		if a.risen {
+			a.periods = append(a.periods, a.elapsed-a.lastRise)
+			a.peakPeaks = append(a.peakPeaks, a.peakHi-a.peakLo)
+		}
+		a.risen = true
+		a.lastRise = a.elapsed
+		a.peakHi, a.peakLo = x, x
+	}
+
+	// the first cycle is left out as the loop is still settling into the oscillation
+	if len(a.periods) > a.cfg.Cycles {
+		var period time.Duration
+		amplitude := 0.
+		for i := 1; i < len(a.periods); i++ {
+			period += a.periods[i]
+			amplitude += a.peakPeaks[i] / 2
+		}
+		period /= time.Duration(len(a.periods) - 1)
+		amplitude /= float64(len(a.periods) - 1)
+		if amplitude <= a.cfg.Hysteresis {
+			a.finish(TuneResult{}, errors.New("tuning failed, the relay did not make the loop oscillate"))
+			return a.bias, true
+		}
+		kU := 4 * a.cfg.Amplitude / (math.Pi * math.Sqrt(amplitude*amplitude-a.cfg.Hysteresis*a.cfg.Hysteresis))
+		kP, kI, kD := ultimateGains(a.rule, kU, period.Seconds())
+		a.finish(TuneResult{
+			Method:         TuneMethodRelay,
+			Rule:           string(a.rule),
+			UltimateGain:   kU,
+			UltimatePeriod: period,
+			KP:             kP,
+			KI:             kI,
+			KD:             kD,
+		}, nil)
+		return a.bias, true
+	}
+
+	if a.high {
+		a.output = a.bias + a.cfg.Amplitude
+	} else {
+		a.output = a.bias - a.cfg.Amplitude
+	}
+	return a.output, false
+}
+
+func (a *autoTuner) nextStep(x float64) (float64, bool) {
+	out, _ := a.step.pidTunerStep(math.Abs(x), a.logger)
+	if a.step.currentPhase != end {
+		return out, false
+	}
+	if a.step.kP == 0 && a.step.kI == 0 && a.step.kD == 0 {
+		a.finish(TuneResult{}, errors.New("tuning failed, the step response did not reach a steady state"))
+		return out, true
+	}
+	deadTime, timeConstant, processGain := a.step.firstOrderModel()
+	a.finish(TuneResult{
+		Method:         TuneMethodStep,
+		Rule:           string(a.rule),
+		UltimateGain:   a.step.kU,
+		UltimatePeriod: time.Duration(a.step.pU * float64(time.Second)),
+		ProcessGain:    processGain,
+		DeadTime:       time.Duration(deadTime * float64(time.Second)),
+		TimeConstant:   time.Duration(timeConstant * float64(time.Second)),
+		KP:             a.step.kP,
+		KI:             a.step.kI,
+		KD:             a.step.kD,
+	}, nil)
+	return out, true
+}
+
+func (a *autoTuner) finish(result TuneResult, err error) {
+	a.once.Do(func() {
+		a.result = result
+		a.err = err
+		close(a.done)
+	})
+}
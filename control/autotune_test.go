@@ -0,0 +1,242 @@
+package control
+
+import (
+	"context"
+	"math"
+	"math/rand"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/edaniels/golog"
+	"go.viam.com/test"
+
+	"go.viam.com/rdk/config"
+)
+
+// lagPlant is a gain followed by two first order lags, integrated over the time between calls. Its output
+// is read as the position of the endpoint.
+type lagPlant struct {
+	mu    sync.Mutex
+	gain  float64
+	tau   float64
+	noise float64
+	rnd   *rand.Rand
+	power float64
+	x     float64
+	y     float64
+	last  time.Time
+}
+
+func (p *lagPlant) advance() {
+	now := time.Now()
+	if !p.last.IsZero() {
+		for dt := now.Sub(p.last).Seconds(); dt > 0; dt -= 0.001 {
+			h := math.Min(dt, 0.001)
+			p.x += (p.gain*p.power - p.x) / p.tau * h
+			p.y += (p.x - p.y) / p.tau * h
+		}
+	}
+	p.last = now
+}
+
+func (p *lagPlant) SetPower(ctx context.Context, power float64, extra map[string]interface{}) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.advance()
+	p.power = power
+	return nil
+}
+
+func (p *lagPlant) Position(ctx context.Context, extra map[string]interface{}) (float64, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.advance()
+	return p.y + p.noise*p.rnd.NormFloat64(), nil
+}
+
+// piLoop regulates the plant to a set point of 1 with a PI controller.
+func piLoop(t *testing.T, plant *lagPlant) *Loop {
+	t.Helper()
+	cfg := Config{
+		Blocks: []BlockConfig{
+			{
+				Name:      "set_point",
+				Type:      "constant",
+				Attribute: config.AttributeMap{"constant_val": 1.0},
+			},
+			{
+				Name:      "endpoint",
+				Type:      "endpoint",
+				Attribute: config.AttributeMap{"motor_name": "plant"},
+				DependsOn: []string{"pid"},
+			},
+			{
+				Name:      "error",
+				Type:      "sum",
+				Attribute: config.AttributeMap{"sum_string": "+-"},
+				DependsOn: []string{"set_point", "endpoint"},
+			},
+			{
+				Name: "pid",
+				Type: "PID",
+				Attribute: config.AttributeMap{
+					"kP": 0.2, "kI": 2.0, "limit_up": 1.0, "limit_lo": -1.0, "int_sat_lim_up": 1.0, "int_sat_lim_lo": -1.0,
+				},
+				DependsOn: []string{"error"},
+			},
+		},
+		Frequency: 50,
+	}
+	cLoop, err := NewLoop(golog.NewTestLogger(t), cfg, plant)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, cLoop.Start(), test.ShouldBeNil)
+	return cLoop
+}
+
+func TestRelayTuner(t *testing.T) {
+	logger := golog.NewTestLogger(t)
+	for _, hysteresis := range []float64{0, 0.1} {
+		a, err := newAutoTuner(TuneConfig{
+			Method:     TuneMethodRelay,
+			Rule:       string(tuneMethodZiegerNicholsPID),
+			Amplitude:  0.5,
+			Hysteresis: hysteresis,
+			Cycles:     4,
+		}, -1, 1, 0, logger)
+		test.That(t, err, test.ShouldBeNil)
+
+		// an oscillation of amplitude 0.2 and period 0.5s
+		dt := time.Millisecond
+		var done bool
+		var out float64
+		for i := 1; !done; i++ {
+			x := 0.2 * math.Sin(2*math.Pi*float64(i)*dt.Seconds()/0.5)
+			out, done = a.next(x, dt)
+			if !done {
+				test.That(t, math.Abs(out), test.ShouldEqual, 0.5)
+			}
+			test.That(t, i, test.ShouldBeLessThan, 5000)
+		}
+		test.That(t, a.err, test.ShouldBeNil)
+		kU := 4 * 0.5 / (math.Pi * math.Sqrt(0.04-hysteresis*hysteresis))
+		test.That(t, a.result.UltimateGain, test.ShouldAlmostEqual, kU, 1e-3)
+		test.That(t, a.result.UltimatePeriod.Seconds(), test.ShouldAlmostEqual, 0.5, 1e-3)
+		test.That(t, a.result.KP, test.ShouldAlmostEqual, 0.6*kU, 1e-3)
+		test.That(t, a.result.KI, test.ShouldAlmostEqual, 1.2*kU/0.5, 1e-2)
+		test.That(t, a.result.KD, test.ShouldAlmostEqual, 0.075*kU*0.5, 1e-3)
+	}
+}
+
+func TestAutoTunerConfig(t *testing.T) {
+	logger := golog.NewTestLogger(t)
+	for _, cfg := range []TuneConfig{
+		{Method: TuneMethodRelay, Rule: "nope"},
+		{Method: "nope", Rule: string(tuneMethodZiegerNicholsPI)},
+		{Method: TuneMethodRelay, Rule: string(tuneMethodCohenCoonsPI)},
+		{Method: TuneMethodRelay, Rule: string(tuneMethodZiegerNicholsPI), Amplitude: 1.5},
+		{Method: TuneMethodRelay, Rule: string(tuneMethodZiegerNicholsPI), Amplitude: -0.5},
+		{Method: TuneMethodRelay, Rule: string(tuneMethodZiegerNicholsPI), Hysteresis: 0.5, MaxInput: 0.2},
+		{Method: TuneMethodStep, Rule: string(tuneMethodZiegerNicholsPI), StepPct: 2},
+	} {
+		_, err := newAutoTuner(cfg, -1, 1, 0, logger)
+		test.That(t, err, test.ShouldNotBeNil)
+	}
+
+	a, err := newAutoTuner(TuneConfig{Method: TuneMethodRelay, Rule: string(tuneMethodZiegerNicholsPI), MaxInput: 1}, -1, 1, 0, logger)
+	test.That(t, err, test.ShouldBeNil)
+	out, done := a.next(0.5, time.Millisecond)
+	test.That(t, done, test.ShouldBeFalse)
+	test.That(t, out, test.ShouldEqual, 0.2)
+	_, done = a.next(-1.5, time.Millisecond)
+	test.That(t, done, test.ShouldBeTrue)
+	test.That(t, a.err, test.ShouldNotBeNil)
+}
+
+func TestLoopTune(t *testing.T) {
+	ctx := context.Background()
+	plant := &lagPlant{gain: 2, tau: 0.05, rnd: rand.New(rand.NewSource(1))}
+	cLoop := piLoop(t, plant)
+	defer cLoop.Stop()
+	// let the loop settle on its set point so that the relay switches around the output that holds it there
+	time.Sleep(time.Second)
+
+	_, err := cLoop.Tune(ctx, "error", TuneConfig{})
+	test.That(t, err, test.ShouldNotBeNil)
+	_, err = cLoop.Tune(ctx, "nope", TuneConfig{})
+	test.That(t, err, test.ShouldNotBeNil)
+
+	t.Run("duration limit", func(t *testing.T) {
+		_, err := cLoop.Tune(ctx, "", TuneConfig{Amplitude: 0.3, MaxDuration: 100 * time.Millisecond})
+		test.That(t, err, test.ShouldNotBeNil)
+		test.That(t, err.Error(), test.ShouldContainSubstring, "did not finish")
+		cfg, err := cLoop.ConfigAt(ctx, "pid")
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, cfg.Attribute["kP"], test.ShouldEqual, 0.2)
+	})
+
+	t.Run("relay", func(t *testing.T) {
+		time.Sleep(time.Second)
+		resp, err := cLoop.DoCommand(ctx, map[string]interface{}{
+			"command":           TuneCommand,
+			"method":            "relay",
+			"rule":              "ziegerNicholsPI",
+			"amplitude":         0.3,
+			"cycles":            3.0,
+			"max_duration_secs": 20.0,
+			"apply":             true,
+		})
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, resp["method"], test.ShouldEqual, "relay")
+		test.That(t, resp["ultimate_gain"], test.ShouldBeGreaterThan, 0)
+		test.That(t, resp["ultimate_period_secs"], test.ShouldBeBetween, 0.05, 2)
+		test.That(t, resp["kP"], test.ShouldAlmostEqual, 0.4545*resp["ultimate_gain"].(float64))
+		test.That(t, resp["applied"], test.ShouldBeTrue)
+
+		cfg, err := cLoop.ConfigAt(ctx, "pid")
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, cfg.Attribute["kP"], test.ShouldEqual, resp["kP"])
+		test.That(t, cfg.Attribute["kI"], test.ShouldEqual, resp["kI"])
+		test.That(t, cfg.Attribute["limit_up"], test.ShouldEqual, 1.0)
+	})
+}
+
+func TestLoopTuneStep(t *testing.T) {
+	ctx := context.Background()
+	plant := &lagPlant{gain: 2, tau: 0.05, noise: 0.01, rnd: rand.New(rand.NewSource(1))}
+	// the step method reads the plant output straight into the pid
+	cfg := Config{
+		Blocks: []BlockConfig{
+			{
+				Name:      "endpoint",
+				Type:      "endpoint",
+				Attribute: config.AttributeMap{"motor_name": "plant"},
+				DependsOn: []string{"pid"},
+			},
+			{
+				Name: "pid",
+				Type: "PID",
+				Attribute: config.AttributeMap{
+					"kP": 0.1, "limit_up": 1.0, "limit_lo": 0.0, "int_sat_lim_up": 1.0, "int_sat_lim_lo": 0.0,
+				},
+				DependsOn: []string{"endpoint"},
+			},
+		},
+		Frequency: 50,
+	}
+	cLoop, err := NewLoop(golog.NewTestLogger(t), cfg, plant)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, cLoop.Start(), test.ShouldBeNil)
+	defer cLoop.Stop()
+
+	result, err := cLoop.Tune(ctx, "pid", TuneConfig{Method: TuneMethodStep, Rule: string(tuneMethodCohenCoonsPI), StepPct: 0.5})
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, result.Method, test.ShouldEqual, TuneMethodStep)
+	test.That(t, result.ProcessGain, test.ShouldAlmostEqual, 2, 0.3)
+	test.That(t, result.KP, test.ShouldNotEqual, 0)
+	test.That(t, result.Applied, test.ShouldBeFalse)
+
+	cfgAfter, err := cLoop.ConfigAt(ctx, "pid")
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, cfgAfter.Attribute["kP"], test.ShouldEqual, 0.1)
+}
@@ -0,0 +1,44 @@
+package control
+
+import (
+	"context"
+	"sync"
+
+	"google.golang.org/protobuf/types/known/anypb"
+
+	"go.viam.com/rdk/components/generic"
+	"go.viam.com/rdk/data"
+)
+
+// TelemetryMethodName is the name data capture knows the telemetry of a control loop by.
+const TelemetryMethodName = "ControlTelemetry"
+
+// NewTelemetryCollector returns a data capture collector for a resource that passes the control commands
+// on to a loop it owns. Each capture holds every sample the loop took since the previous capture, so
+// nothing is lost as long as captures are less than the loop's buffer apart.
+func NewTelemetryCollector(resource interface{}, params data.CollectorParams) (data.Collector, error) {
+	g, ok := resource.(generic.Generic)
+	if !ok {
+		return nil, data.InvalidInterfaceErr(generic.SubtypeName)
+	}
+
+	var mu sync.Mutex
+	var since string
+	cFunc := data.CaptureFunc(func(ctx context.Context, _ map[string]*anypb.Any) (interface{}, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		cmd := map[string]interface{}{"command": TelemetryCommand}
+		if since != "" {
+			cmd["since"] = since
+		}
+		resp, err := g.DoCommand(ctx, cmd)
+		if err != nil {
+			return nil, data.FailedToReadErr(params.ComponentName, TelemetryMethodName, err)
+		}
+		if latest, ok := resp["latest"].(string); ok {
+			since = latest
+		}
+		return resp, nil
+	})
+	return data.NewCollector(cFunc, params)
+}
@@ -0,0 +1,199 @@
+package control
+
+import (
+	"context"
+	"time"
+
+	"github.com/mitchellh/mapstructure"
+	"github.com/pkg/errors"
+
+	"go.viam.com/rdk/config"
+)
+
+// Commands a component that owns a loop can pass on to Loop.DoCommand so that the loop can be inspected
+// and tuned remotely.
+const (
+	// BlocksCommand returns the names of the blocks of the loop under "blocks".
+	BlocksCommand = "control_blocks"
+	// OutputsCommand returns the current output of every block, keyed by block name.
+	OutputsCommand = "control_outputs"
+	// ConfigCommand returns the config of the block named by "block".
+	ConfigCommand = "control_config"
+	// SetConfigCommand sets the "attributes" of the block named by "block", leaving its other attributes
+	// as they are. This is how gains are changed on a running loop.
+	SetConfigCommand = "set_control_config"
+	// TelemetryCommand returns every sample the loop has buffered that was taken after the RFC 3339 time
+	// in "since", or all of them if it is missing, under "samples". The time of the latest sample is
+	// returned under "latest" so that it can be passed as "since" on the next call.
+	TelemetryCommand = "control_telemetry"
+	// TuneCommand auto tunes the PID block named by "block", or the only one of the loop, and returns what
+	// it found. The other arguments are those of TuneConfig in snake case, with "max_duration_secs" for the
+	// duration limit. The command blocks until tuning is done.
+	TuneCommand = "control_tune"
+)
+
+// IsCommand returns whether the command is one that Loop.DoCommand handles.
+func IsCommand(cmd map[string]interface{}) bool {
+	switch cmd["command"] {
+	case BlocksCommand, OutputsCommand, ConfigCommand, SetConfigCommand, TelemetryCommand, TuneCommand:
+		return true
+	default:
+		return false
+	}
+}
+
+// DoCommand handles the commands for inspecting and tuning the loop.
+func (l *Loop) DoCommand(ctx context.Context, cmd map[string]interface{}) (map[string]interface{}, error) {
+	name, ok := cmd["command"]
+	if !ok {
+		return nil, errors.New("missing 'command' value")
+	}
+	switch name {
+	case BlocksCommand:
+		blocks, err := l.BlockList(ctx)
+		if err != nil {
+			return nil, err
+		}
+		names := make([]interface{}, 0, len(blocks))
+		for _, b := range blocks {
+			names = append(names, b)
+		}
+		return map[string]interface{}{"blocks": names}, nil
+	case OutputsCommand:
+		outputs := map[string]interface{}{}
+		for name, v := range l.sample(ctx, time.Now()).Signals {
+			outputs[name] = v
+		}
+		return outputs, nil
+	case ConfigCommand:
+		block, ok := cmd["block"].(string)
+		if !ok {
+			return nil, errors.New("need a block name")
+		}
+		cfg, err := l.ConfigAt(ctx, block)
+		if err != nil {
+			return nil, err
+		}
+		dependsOn := make([]interface{}, 0, len(cfg.DependsOn))
+		for _, dep := range cfg.DependsOn {
+			dependsOn = append(dependsOn, dep)
+		}
+		return map[string]interface{}{
+			"name":       cfg.Name,
+			"type":       string(cfg.Type),
+			"attributes": map[string]interface{}(cfg.Attribute),
+			"depends_on": dependsOn,
+		}, nil
+	case SetConfigCommand:
+		block, ok := cmd["block"].(string)
+		if !ok {
+			return nil, errors.New("need a block name")
+		}
+		attributes, ok := cmd["attributes"].(map[string]interface{})
+		if !ok {
+			return nil, errors.New("need attributes to set")
+		}
+		return nil, l.UpdateAttributesAt(ctx, block, attributes)
+	case TelemetryCommand:
+		var since time.Time
+		if raw, ok := cmd["since"]; ok {
+			str, ok := raw.(string)
+			if !ok {
+				return nil, errors.New("since must be an RFC 3339 time")
+			}
+			var err error
+			if since, err = time.Parse(time.RFC3339Nano, str); err != nil {
+				return nil, err
+			}
+		}
+		samples := l.Telemetry(since)
+		encoded := make([]interface{}, 0, len(samples))
+		latest := since
+		for _, s := range samples {
+			signals := make(map[string]interface{}, len(s.Signals))
+			for name, v := range s.Signals {
+				signals[name] = v
+			}
+			encoded = append(encoded, map[string]interface{}{"time": s.Time.Format(time.RFC3339Nano), "signals": signals})
+			latest = s.Time
+		}
+		resp := map[string]interface{}{"samples": encoded}
+		if !latest.IsZero() {
+			resp["latest"] = latest.Format(time.RFC3339Nano)
+		}
+		return resp, nil
+	case TuneCommand:
+		var args tuneArgs
+		decoder, err := mapstructure.NewDecoder(&mapstructure.DecoderConfig{TagName: "json", WeaklyTypedInput: true, Result: &args})
+		if err != nil {
+			return nil, err
+		}
+		if err := decoder.Decode(cmd); err != nil {
+			return nil, err
+		}
+		result, err := l.Tune(ctx, args.Block, TuneConfig{
+			Method:      TuneMethod(args.Method),
+			Rule:        args.Rule,
+			Amplitude:   args.Amplitude,
+			Bias:        args.Bias,
+			Hysteresis:  args.Hysteresis,
+			Cycles:      args.Cycles,
+			StepPct:     args.StepPct,
+			SSRValue:    args.SSRValue,
+			MaxInput:    args.MaxInput,
+			MaxDuration: time.Duration(args.MaxDurationSecs * float64(time.Second)),
+			Apply:       args.Apply,
+		})
+		if err != nil {
+			return nil, err
+		}
+		return map[string]interface{}{
+			"method":               string(result.Method),
+			"rule":                 result.Rule,
+			"ultimate_gain":        result.UltimateGain,
+			"ultimate_period_secs": result.UltimatePeriod.Seconds(),
+			"process_gain":         result.ProcessGain,
+			"dead_time_secs":       result.DeadTime.Seconds(),
+			"time_constant_secs":   result.TimeConstant.Seconds(),
+			"kP":                   result.KP,
+			"kI":                   result.KI,
+			"kD":                   result.KD,
+			"applied":              result.Applied,
+		}, nil
+	default:
+		return nil, errors.Errorf("no such command: %s", name)
+	}
+}
+
+// tuneArgs are the arguments of TuneCommand.
+type tuneArgs struct {
+	Block           string   `json:"block"`
+	Method          string   `json:"method"`
+	Rule            string   `json:"rule"`
+	Amplitude       float64  `json:"amplitude"`
+	Bias            *float64 `json:"bias"`
+	Hysteresis      float64  `json:"hysteresis"`
+	Cycles          int      `json:"cycles"`
+	StepPct         float64  `json:"step_pct"`
+	SSRValue        float64  `json:"ssr_value"`
+	MaxInput        float64  `json:"max_input"`
+	MaxDurationSecs float64  `json:"max_duration_secs"`
+	Apply           bool     `json:"apply"`
+}
+
+// UpdateAttributesAt sets the given attributes on the block, keeping its other attributes.
+func (l *Loop) UpdateAttributesAt(ctx context.Context, name string, attributes map[string]interface{}) error {
+	cfg, err := l.ConfigAt(ctx, name)
+	if err != nil {
+		return err
+	}
+	updated := config.AttributeMap{}
+	for k, v := range cfg.Attribute {
+		updated[k] = v
+	}
+	for k, v := range attributes {
+		updated[k] = v
+	}
+	cfg.Attribute = updated
+	return l.SetConfigAt(ctx, name, cfg)
+}
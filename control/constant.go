@@ -26,6 +26,8 @@ func newConstant(config BlockConfig, logger golog.Logger) (Block, error) {
 }
 
 func (b *constant) Next(ctx context.Context, x []Signal, dt time.Duration) ([]Signal, bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
 	return b.y, true
 }
 
@@ -57,6 +59,8 @@ func (b *constant) UpdateConfig(ctx context.Context, config BlockConfig) error {
 }
 
 func (b *constant) Output(ctx context.Context) []Signal {
+	b.mu.Lock()
+	defer b.mu.Unlock()
 	return b.y
 }
 
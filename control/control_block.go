@@ -22,6 +22,11 @@ const (
 	blockSum                        controlBlockType = "sum"
 	blockConstant                   controlBlockType = "constant"
 	blockEncoderToRPM               controlBlockType = "encoderToRpm"
+	blockFeedforward                controlBlockType = "feedforward"
+	blockSaturation                 controlBlockType = "saturation"
+	blockDeadband                   controlBlockType = "deadband"
+	blockScheduledGain              controlBlockType = "scheduledGain"
+	blockAntiWindupPID              controlBlockType = "antiWindupPID"
 )
 
 // BlockConfig configuration of a given block.
@@ -107,6 +112,36 @@ func createBlock(cfg BlockConfig, logger golog.Logger) (Block, error) {
 			return nil, err
 		}
 		return b, nil
+	case blockFeedforward:
+		b, err := newFeedforward(cfg, logger)
+		if err != nil {
+			return nil, err
+		}
+		return b, nil
+	case blockSaturation:
+		b, err := newSaturation(cfg, logger)
+		if err != nil {
+			return nil, err
+		}
+		return b, nil
+	case blockDeadband:
+		b, err := newDeadband(cfg, logger)
+		if err != nil {
+			return nil, err
+		}
+		return b, nil
+	case blockScheduledGain:
+		b, err := newScheduledGain(cfg, logger)
+		if err != nil {
+			return nil, err
+		}
+		return b, nil
+	case blockAntiWindupPID:
+		b, err := newAntiWindupPID(cfg, logger)
+		if err != nil {
+			return nil, err
+		}
+		return b, nil
 	}
 	return nil, errors.Errorf("unsupported block type %s", t)
 }
@@ -37,6 +37,7 @@ type Loop struct {
 	cancelCtx               context.Context
 	cancel                  context.CancelFunc
 	running                 bool
+	telemetry               *telemetry
 }
 
 // NewLoop construct a new control loop for a specific endpoint.
@@ -54,6 +55,7 @@ func createLoop(logger golog.Logger, cfg Config, m Controllable) (*Loop, error)
 		cancelCtx:               cancelCtx,
 		cancel:                  cancel,
 		running:                 false,
+		telemetry:               newTelemetry(),
 	}
 	if l.cfg.Frequency == 0.0 || l.cfg.Frequency > 200 {
 		return nil, errors.New("loop frequency shouldn't be 0 or above 200Hz")
@@ -113,12 +115,18 @@ func createLoop(logger golog.Logger, cfg Config, m Controllable) (*Loop, error)
 			utils.ManagedGo(func() {
 				b := b
 				nInputs := len(b.ins)
+				// the outputs of an endpoint belong to the goroutine driven by the ticker, which is the only one
+				// sending on them, so only that goroutine may close them
+				ownsOuts := b.blk.Config(l.cancelCtx).Type != blockEndpoint
 				close(waitCh)
 				for {
 					sw := make([]Signal, nInputs)
 					for i, c := range b.ins {
 						r, ok := <-c
 						if !ok {
+							if !ownsOuts {
+								return
+							}
 							b.mu.Lock()
 							for _, out := range b.outs {
 								close(out)
@@ -137,7 +145,7 @@ func createLoop(logger golog.Logger, cfg Config, m Controllable) (*Loop, error)
 						}
 					}
 					v, ok := b.blk.Next(l.cancelCtx, sw, l.dt)
-					if ok {
+					if ok && ownsOuts {
 						for _, out := range b.outs {
 							out <- v
 						}
@@ -207,6 +215,8 @@ func (l *Loop) Start() error {
 		ct := l.ct
 		ts := l.ts
 		close(waitCh)
+		// the outputs of the blocks are sampled on every tick and stamped with the tick that produced them
+		var lastTick time.Time
 		for {
 			if l.cancelCtx.Err() != nil {
 				for _, c := range ts {
@@ -216,9 +226,13 @@ func (l *Loop) Start() error {
 			}
 			select {
 			case t := <-ct.ticker.C:
+				if !lastTick.IsZero() {
+					l.telemetry.record(l.sample(l.cancelCtx, lastTick))
+				}
 				for _, c := range ts {
 					c <- t
 				}
+				lastTick = t
 			case <-ct.stop:
 				for _, c := range ts {
 					close(c)
@@ -324,3 +324,44 @@ func TestControlLoop(t *testing.T) {
 	}
 	cLoop.Stop()
 }
+
+func TestControlLoopStop(t *testing.T) {
+	logger := golog.NewTestLogger(t)
+	cfg := Config{
+		Blocks: []BlockConfig{
+			{
+				Name:      "S",
+				Type:      "constant",
+				Attribute: config.AttributeMap{"constant_val": 1.0},
+			},
+			{
+				Name:      "G",
+				Type:      "gain",
+				Attribute: config.AttributeMap{"gain": 0.5},
+				DependsOn: []string{"S"},
+			},
+			{
+				Name:      "A",
+				Type:      "endpoint",
+				Attribute: config.AttributeMap{"motor_name": "plant"},
+				DependsOn: []string{"G"},
+			},
+			{
+				Name:      "B",
+				Type:      "sum",
+				Attribute: config.AttributeMap{"sum_string": "+-"},
+				DependsOn: []string{"S", "A"},
+			},
+		},
+		Frequency: 200,
+	}
+	// the inputs of the endpoint close while its ticker goroutine may still be sending its position, which
+	// must neither send on a closed channel nor block the loop from stopping
+	for i := 0; i < 200; i++ {
+		cLoop, err := NewLoop(logger, cfg, &lagPlant{gain: 1, tau: 0.1, rnd: rand.New(rand.NewSource(int64(i)))})
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, cLoop.Start(), test.ShouldBeNil)
+		time.Sleep(time.Duration(i%5) * time.Millisecond)
+		cLoop.Stop()
+	}
+}
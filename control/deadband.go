@@ -0,0 +1,85 @@
+package control
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/edaniels/golog"
+	"github.com/pkg/errors"
+)
+
+// deadband outputs 0 while its input is within width of 0 and the input moved towards 0 by width
+// otherwise, so that the output stays continuous.
+type deadband struct {
+	mu     sync.Mutex
+	cfg    BlockConfig
+	y      []Signal
+	width  float64
+	logger golog.Logger
+}
+
+func newDeadband(config BlockConfig, logger golog.Logger) (Block, error) {
+	d := &deadband{cfg: config, logger: logger}
+	if err := d.reset(); err != nil {
+		return nil, err
+	}
+	return d, nil
+}
+
+func (b *deadband) Next(ctx context.Context, x []Signal, dt time.Duration) ([]Signal, bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if len(x) != 1 {
+		return b.y, false
+	}
+	in := x[0].GetSignalValueAt(0)
+	switch {
+	case in > b.width:
+		b.y[0].SetSignalValueAt(0, in-b.width)
+	case in < -b.width:
+		b.y[0].SetSignalValueAt(0, in+b.width)
+	default:
+		b.y[0].SetSignalValueAt(0, 0)
+	}
+	return b.y, true
+}
+
+func (b *deadband) reset() error {
+	if !b.cfg.Attribute.Has("width") {
+		return errors.Errorf("deadband block %s doesn't have a width field", b.cfg.Name)
+	}
+	if len(b.cfg.DependsOn) != 1 {
+		return errors.Errorf("invalid number of inputs for deadband block %s expected 1 got %d", b.cfg.Name, len(b.cfg.DependsOn))
+	}
+	b.width = b.cfg.Attribute.Float64("width", 0)
+	if b.width < 0 {
+		return errors.Errorf("deadband block %s width cannot be negative", b.cfg.Name)
+	}
+	b.y = make([]Signal, 1)
+	b.y[0] = makeSignal(b.cfg.Name)
+	return nil
+}
+
+func (b *deadband) Reset(ctx context.Context) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.reset()
+}
+
+func (b *deadband) UpdateConfig(ctx context.Context, config BlockConfig) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.cfg = config
+	return b.reset()
+}
+
+func (b *deadband) Output(ctx context.Context) []Signal {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.y
+}
+
+func (b *deadband) Config(ctx context.Context) BlockConfig {
+	return b.cfg
+}
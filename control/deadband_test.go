@@ -0,0 +1,91 @@
+package control
+
+import (
+	"context"
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/edaniels/golog"
+	"go.viam.com/test"
+	"go.viam.com/utils/testutils"
+
+	"go.viam.com/rdk/config"
+)
+
+func TestDeadbandConfig(t *testing.T) {
+	logger := golog.NewTestLogger(t)
+	for _, c := range []struct {
+		conf BlockConfig
+		err  string
+	}{
+		{
+			BlockConfig{Name: "DB", Attribute: config.AttributeMap{"width": 0.1}, DependsOn: []string{"A"}},
+			"",
+		},
+		{
+			BlockConfig{Name: "DB", Attribute: config.AttributeMap{"widths": 0.1}, DependsOn: []string{"A"}},
+			"deadband block DB doesn't have a width field",
+		},
+		{
+			BlockConfig{Name: "DB", Attribute: config.AttributeMap{"width": 0.1}, DependsOn: []string{}},
+			"invalid number of inputs for deadband block DB expected 1 got 0",
+		},
+		{
+			BlockConfig{Name: "DB", Attribute: config.AttributeMap{"width": -0.1}, DependsOn: []string{"A"}},
+			"deadband block DB width cannot be negative",
+		},
+	} {
+		_, err := newDeadband(c.conf, logger)
+		if c.err == "" {
+			test.That(t, err, test.ShouldBeNil)
+		} else {
+			test.That(t, err, test.ShouldNotBeNil)
+			test.That(t, err.Error(), test.ShouldEqual, c.err)
+		}
+	}
+}
+
+func TestDeadbandNext(t *testing.T) {
+	ctx := context.Background()
+	b, err := newDeadband(BlockConfig{
+		Name:      "DB",
+		Attribute: config.AttributeMap{"width": 0.1},
+		DependsOn: []string{"A"},
+	}, golog.NewTestLogger(t))
+	test.That(t, err, test.ShouldBeNil)
+	for _, c := range []struct{ in, out float64 }{
+		{0, 0},
+		{0.05, 0},
+		{-0.1, 0},
+		{0.5, 0.4},
+		{-0.5, -0.4},
+	} {
+		out, ok := b.Next(ctx, inputSignals(c.in), time.Millisecond)
+		test.That(t, ok, test.ShouldBeTrue)
+		test.That(t, out[0].GetSignalValueAt(0), test.ShouldAlmostEqual, c.out)
+	}
+}
+
+func TestDeadbandLoop(t *testing.T) {
+	plant := &lagPlant{gain: 1, tau: 0.05, rnd: rand.New(rand.NewSource(1))}
+	cLoop := sourceLoop(t, plant, BlockConfig{
+		Type:      "deadband",
+		Attribute: config.AttributeMap{"width": 0.25},
+	}, 1)
+	defer cLoop.Stop()
+	testutils.WaitForAssertion(t, func(tb testing.TB) {
+		tb.Helper()
+		test.That(tb, plant.appliedPower(), test.ShouldEqual, 0.75)
+	})
+
+	// within the band the loop holds the plant still
+	cfg, err := cLoop.ConfigAt(context.Background(), "a")
+	test.That(t, err, test.ShouldBeNil)
+	cfg.Attribute = config.AttributeMap{"constant_val": 0.2}
+	test.That(t, cLoop.SetConfigAt(context.Background(), "a", cfg), test.ShouldBeNil)
+	testutils.WaitForAssertion(t, func(tb testing.TB) {
+		tb.Helper()
+		test.That(tb, plant.appliedPower(), test.ShouldEqual, 0)
+	})
+}
@@ -86,6 +86,8 @@ func derive(x []float64, dt time.Duration, stencil *derivativeStencil) (float64,
 }
 
 func (d *derivative) Next(ctx context.Context, x []Signal, dt time.Duration) ([]Signal, bool) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
 	if d.stencil.Type == "backward" {
 		for idx, s := range x {
 			d.px[idx] = append(d.px[idx][1:], s.GetSignalValueAt(0))
@@ -144,6 +146,8 @@ func (d *derivative) Reset(ctx context.Context) error {
 }
 
 func (d *derivative) Output(ctx context.Context) []Signal {
+	d.mu.Lock()
+	defer d.mu.Unlock()
 	return d.y
 }
 
@@ -27,6 +27,8 @@ func newEncoderSpeed(config BlockConfig, logger golog.Logger) (Block, error) {
 }
 
 func (b *encoderToRPM) Next(ctx context.Context, x []Signal, dt time.Duration) ([]Signal, bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
 	currEncCount := int(x[0].GetSignalValueAt(0))
 	b.y[0].SetSignalValueAt(0, (float64(currEncCount-b.prevEncCount)/float64(b.ticksPerRevolution))*60.0/(dt.Seconds()))
 	b.prevEncCount = currEncCount
@@ -61,6 +63,8 @@ func (b *encoderToRPM) UpdateConfig(ctx context.Context, config BlockConfig) err
 }
 
 func (b *encoderToRPM) Output(ctx context.Context) []Signal {
+	b.mu.Lock()
+	defer b.mu.Unlock()
 	return b.y
 }
 
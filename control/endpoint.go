@@ -26,6 +26,8 @@ func newEndpoint(config BlockConfig, logger golog.Logger, ctr Controllable) (Blo
 }
 
 func (e *endpoint) Next(ctx context.Context, x []Signal, dt time.Duration) ([]Signal, bool) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
 	if len(x) == 1 {
 		power := x[0].GetSignalValueAt(0)
 		if e.ctr != nil {
@@ -72,6 +74,8 @@ func (e *endpoint) UpdateConfig(ctx context.Context, config BlockConfig) error {
 }
 
 func (e *endpoint) Output(ctx context.Context) []Signal {
+	e.mu.Lock()
+	defer e.mu.Unlock()
 	return e.y
 }
 
@@ -0,0 +1,97 @@
+package control
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/edaniels/golog"
+	"github.com/pkg/errors"
+)
+
+// feedforward computes the output needed to follow a reference velocity from a model of the plant rather
+// than from the error of the loop: kS overcomes static friction in the direction of motion, kV is
+// proportional to the velocity and kA to its rate of change.
+type feedforward struct {
+	mu      sync.Mutex
+	cfg     BlockConfig
+	y       []Signal
+	kS      float64
+	kV      float64
+	kA      float64
+	lastV   float64
+	started bool
+	logger  golog.Logger
+}
+
+func newFeedforward(config BlockConfig, logger golog.Logger) (Block, error) {
+	f := &feedforward{cfg: config, logger: logger}
+	if err := f.reset(); err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+func (b *feedforward) Next(ctx context.Context, x []Signal, dt time.Duration) ([]Signal, bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if len(x) != 1 {
+		return b.y, false
+	}
+	v := x[0].GetSignalValueAt(0)
+	acc := 0.0
+	if b.started && dt > 0 {
+		acc = (v - b.lastV) / dt.Seconds()
+	}
+	b.lastV = v
+	b.started = true
+	out := b.kV*v + b.kA*acc
+	switch {
+	case v > 0:
+		out += b.kS
+	case v < 0:
+		out -= b.kS
+	}
+	b.y[0].SetSignalValueAt(0, out)
+	return b.y, true
+}
+
+func (b *feedforward) reset() error {
+	if !b.cfg.Attribute.Has("kS") && !b.cfg.Attribute.Has("kV") && !b.cfg.Attribute.Has("kA") {
+		return errors.Errorf("feedforward block %s should have at least one kS, kV or kA field", b.cfg.Name)
+	}
+	if len(b.cfg.DependsOn) != 1 {
+		return errors.Errorf("invalid number of inputs for feedforward block %s expected 1 got %d", b.cfg.Name, len(b.cfg.DependsOn))
+	}
+	b.kS = b.cfg.Attribute.Float64("kS", 0.0)
+	b.kV = b.cfg.Attribute.Float64("kV", 0.0)
+	b.kA = b.cfg.Attribute.Float64("kA", 0.0)
+	b.lastV = 0
+	b.started = false
+	b.y = make([]Signal, 1)
+	b.y[0] = makeSignal(b.cfg.Name)
+	return nil
+}
+
+func (b *feedforward) Reset(ctx context.Context) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.reset()
+}
+
+func (b *feedforward) UpdateConfig(ctx context.Context, config BlockConfig) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.cfg = config
+	return b.reset()
+}
+
+func (b *feedforward) Output(ctx context.Context) []Signal {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.y
+}
+
+func (b *feedforward) Config(ctx context.Context) BlockConfig {
+	return b.cfg
+}
@@ -0,0 +1,130 @@
+package control
+
+import (
+	"context"
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/edaniels/golog"
+	"go.viam.com/test"
+	"go.viam.com/utils/testutils"
+
+	"go.viam.com/rdk/config"
+)
+
+// inputSignals returns one input signal per value, as a block gets them from the blocks it depends on.
+func inputSignals(values ...float64) []Signal {
+	signals := make([]Signal, 0, len(values))
+	for _, v := range values {
+		s := makeSignal("in")
+		s.SetSignalValueAt(0, v)
+		signals = append(signals, s)
+	}
+	return signals
+}
+
+// appliedPower returns the power the loop last set.
+func (p *lagPlant) appliedPower() float64 {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.power
+}
+
+// startPlantLoop starts a 50Hz loop of the blocks driving the plant through an endpoint named "endpoint".
+func startPlantLoop(t *testing.T, plant *lagPlant, blocks []BlockConfig) *Loop {
+	t.Helper()
+	cLoop, err := NewLoop(golog.NewTestLogger(t), Config{Blocks: blocks, Frequency: 50}, plant)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, cLoop.Start(), test.ShouldBeNil)
+	return cLoop
+}
+
+// sourceLoop starts a loop driving the plant with the output of the block, fed the given constants.
+func sourceLoop(t *testing.T, plant *lagPlant, blk BlockConfig, constants ...float64) *Loop {
+	t.Helper()
+	blk.Name = "block"
+	blocks := []BlockConfig{
+		blk,
+		{
+			Name:      "endpoint",
+			Type:      "endpoint",
+			Attribute: config.AttributeMap{"motor_name": "plant"},
+			DependsOn: []string{"block"},
+		},
+	}
+	blocks[0].DependsOn = nil
+	for i, c := range constants {
+		name := string(rune('a' + i))
+		blocks[0].DependsOn = append(blocks[0].DependsOn, name)
+		blocks = append(blocks, BlockConfig{Name: name, Type: "constant", Attribute: config.AttributeMap{"constant_val": c}})
+	}
+	return startPlantLoop(t, plant, blocks)
+}
+
+func TestFeedforwardConfig(t *testing.T) {
+	logger := golog.NewTestLogger(t)
+	for _, c := range []struct {
+		conf BlockConfig
+		err  string
+	}{
+		{
+			BlockConfig{Name: "FF", Attribute: config.AttributeMap{"kV": 0.5}, DependsOn: []string{"A"}},
+			"",
+		},
+		{
+			BlockConfig{Name: "FF", Attribute: config.AttributeMap{"kX": 0.5}, DependsOn: []string{"A"}},
+			"feedforward block FF should have at least one kS, kV or kA field",
+		},
+		{
+			BlockConfig{Name: "FF", Attribute: config.AttributeMap{"kV": 0.5}, DependsOn: []string{"A", "B"}},
+			"invalid number of inputs for feedforward block FF expected 1 got 2",
+		},
+	} {
+		_, err := newFeedforward(c.conf, logger)
+		if c.err == "" {
+			test.That(t, err, test.ShouldBeNil)
+		} else {
+			test.That(t, err, test.ShouldNotBeNil)
+			test.That(t, err.Error(), test.ShouldEqual, c.err)
+		}
+	}
+}
+
+func TestFeedforwardNext(t *testing.T) {
+	ctx := context.Background()
+	b, err := newFeedforward(BlockConfig{
+		Name:      "FF",
+		Attribute: config.AttributeMap{"kS": 0.1, "kV": 0.5, "kA": 0.01},
+		DependsOn: []string{"A"},
+	}, golog.NewTestLogger(t))
+	test.That(t, err, test.ShouldBeNil)
+	dt := 100 * time.Millisecond
+
+	out, ok := b.Next(ctx, inputSignals(0), dt)
+	test.That(t, ok, test.ShouldBeTrue)
+	test.That(t, out[0].GetSignalValueAt(0), test.ShouldEqual, 0)
+	// accelerating to 1 in 100ms
+	out, _ = b.Next(ctx, inputSignals(1), dt)
+	test.That(t, out[0].GetSignalValueAt(0), test.ShouldAlmostEqual, 0.1+0.5+0.1)
+	out, _ = b.Next(ctx, inputSignals(1), dt)
+	test.That(t, out[0].GetSignalValueAt(0), test.ShouldAlmostEqual, 0.1+0.5)
+	out, _ = b.Next(ctx, inputSignals(-1), dt)
+	test.That(t, out[0].GetSignalValueAt(0), test.ShouldAlmostEqual, -0.1-0.5-0.2)
+
+	_, ok = b.Next(ctx, inputSignals(1, 2), dt)
+	test.That(t, ok, test.ShouldBeFalse)
+}
+
+func TestFeedforwardLoop(t *testing.T) {
+	plant := &lagPlant{gain: 1, tau: 0.05, rnd: rand.New(rand.NewSource(1))}
+	cLoop := sourceLoop(t, plant, BlockConfig{
+		Type:      "feedforward",
+		Attribute: config.AttributeMap{"kS": 0.1, "kV": 0.5},
+	}, 2)
+	defer cLoop.Stop()
+	testutils.WaitForAssertion(t, func(tb testing.TB) {
+		tb.Helper()
+		test.That(tb, plant.appliedPower(), test.ShouldAlmostEqual, 1.1)
+	})
+}
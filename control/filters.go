@@ -135,6 +135,8 @@ func (f *filterStruct) initFilter() error {
 }
 
 func (f *filterStruct) Next(ctx context.Context, x []Signal, dt time.Duration) ([]Signal, bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
 	if len(x) == 1 {
 		xFlt, ok := f.filter.Next(x[0].GetSignalValueAt(0))
 		f.y[0].SetSignalValueAt(0, xFlt)
@@ -161,5 +163,7 @@ func (f *filterStruct) UpdateConfig(ctx context.Context, config BlockConfig) err
 }
 
 func (f *filterStruct) Output(ctx context.Context) []Signal {
+	f.mu.Lock()
+	defer f.mu.Unlock()
 	return f.y
 }
@@ -26,6 +26,8 @@ func newGain(config BlockConfig, logger golog.Logger) (Block, error) {
 }
 
 func (b *gain) Next(ctx context.Context, x []Signal, dt time.Duration) ([]Signal, bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
 	if len(x) != 1 {
 		return b.y, false
 	}
@@ -64,6 +66,8 @@ func (b *gain) UpdateConfig(ctx context.Context, config BlockConfig) error {
 }
 
 func (b *gain) Output(ctx context.Context) []Signal {
+	b.mu.Lock()
+	defer b.mu.Unlock()
 	return b.y
 }
 
@@ -35,6 +35,7 @@ type basicPID struct {
 	limLo    float64
 	tuner    pidTuner
 	tuning   bool
+	autoTune *autoTuner
 	logger   golog.Logger
 }
 
@@ -44,7 +45,14 @@ type basicPID struct {
 func (p *basicPID) Next(ctx context.Context, x []Signal, dt time.Duration) ([]Signal, bool) {
 	p.mu.Lock()
 	defer p.mu.Unlock()
-	if p.tuning {
+	if p.autoTune != nil {
+		out, done := p.autoTune.next(x[0].GetSignalValueAt(0), dt)
+		if done {
+			p.autoTune = nil
+			p.error = x[0].GetSignalValueAt(0)
+		}
+		p.y[0].SetSignalValueAt(0, out)
+	} else if p.tuning {
 		out, done := p.tuner.pidTunerStep(math.Abs(x[0].GetSignalValueAt(0)), p.logger)
 		if done {
 			p.kD = p.tuner.kD
@@ -85,6 +93,10 @@ func (p *basicPID) Next(ctx context.Context, x []Signal, dt time.Duration) ([]Si
 }
 
 func (p *basicPID) reset() error {
+	if p.autoTune != nil {
+		p.autoTune.finish(TuneResult{}, errors.Errorf("pid block %s was reset while it was being tuned", p.cfg.Name))
+		p.autoTune = nil
+	}
 	p.int = 0
 	p.error = 0
 	p.sat = 0
@@ -141,6 +153,8 @@ func (p *basicPID) UpdateConfig(ctx context.Context, config BlockConfig) error {
 }
 
 func (p *basicPID) Output(ctx context.Context) []Signal {
+	p.mu.Lock()
+	defer p.mu.Unlock()
 	return p.y
 }
 
@@ -148,6 +162,31 @@ func (p *basicPID) Config(ctx context.Context) BlockConfig {
 	return p.cfg
 }
 
+// startAutoTune hands the output of the PID over to an auto tuner until it is done.
+func (p *basicPID) startAutoTune(cfg TuneConfig) (*autoTuner, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.tuning || p.autoTune != nil {
+		return nil, errors.Errorf("pid block %s is already being tuned", p.cfg.Name)
+	}
+	tuner, err := newAutoTuner(cfg, p.limLo, p.limUp, p.y[0].GetSignalValueAt(0), p.logger)
+	if err != nil {
+		return nil, err
+	}
+	p.autoTune = tuner
+	return tuner, nil
+}
+
+// stopAutoTune aborts the auto tuner if it is still running.
+func (p *basicPID) stopAutoTune(tuner *autoTuner, err error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.autoTune == tuner {
+		p.autoTune = nil
+	}
+	tuner.finish(TuneResult{}, err)
+}
+
 type tuneCalcMethod string
 
 const (
@@ -194,65 +233,64 @@ type pidTuner struct {
 	ccT2         time.Duration
 	ccT3         time.Duration
 	out          float64
+	kU           float64
+	pU           float64
 }
 
 func (p *pidTuner) computeGains() {
 	stepPwr := p.limUp * p.stepPct
-	i := 0
-	a := 0.0
-	for ; i < int(math.Min(float64(len(p.pPeakH)), float64(len(p.pPeakL)))); i++ {
-		a += math.Abs(p.pPeakH[i] - p.pPeakL[i])
-	}
-	a /= (2.0 * float64(i+1))
-	d := 0.5 * stepPwr
-	kU := (4 * d) / (math.Pi * a)
-	pU := (p.tC * 2.0).Seconds()
 	switch p.tuneMethod {
+	case tuneMethodCohenCoonsPI:
+		t1, tau, K := p.firstOrderModel()
+		r := t1 / tau
+		p.kP = (1.0 / (K * r)) * (0.9 + r/12)
+		p.kI = p.kP / (t1) * (30 + 3*r) / (9 + 20*r)
+	case tuneMethodCohenCoonsPID:
+		t1, tau, K := p.firstOrderModel()
+		r := t1 / tau
+		p.kP = (1.0 / (K * r)) * (4.0/3.0 + r/4)
+		p.kI = p.kP / (t1) * (32 + 6*r) / (13 + 8*r)
+		p.kD = p.kP / (4 * t1 / (11 + 2*r))
+	default:
+		i := 0
+		a := 0.0
+		for ; i < int(math.Min(float64(len(p.pPeakH)), float64(len(p.pPeakL)))); i++ {
+			a += math.Abs(p.pPeakH[i] - p.pPeakL[i])
+		}
+		a /= (2.0 * float64(i+1))
+		d := 0.5 * stepPwr
+		p.kU = (4 * d) / (math.Pi * a)
+		p.pU = (p.tC * 2.0).Seconds()
+		p.kP, p.kI, p.kD = ultimateGains(p.tuneMethod, p.kU, p.pU)
+	}
+}
+
+// firstOrderModel returns the dead time, time constant and gain of a first order plant matching the step
+// response.
+func (p *pidTuner) firstOrderModel() (float64, float64, float64) {
+	t1 := (p.ccT2.Seconds() - math.Log(2.0)*p.ccT3.Seconds()) / (1.0 - math.Log(2.0))
+	tau := p.ccT3.Seconds() - t1
+	return t1, tau, p.avgSpeedSS / (p.limUp * p.stepPct)
+}
+
+// ultimateGains returns the gains the tuning rule gives for a plant with ultimate gain kU and ultimate
+// period pU in seconds.
+func ultimateGains(method tuneCalcMethod, kU, pU float64) (float64, float64, float64) {
+	switch method {
 	case tuneMethodZiegerNicholsPI:
-		p.kP = 0.4545 * kU
-		p.kI = 0.5454 * (kU / pU)
-		p.kD = 0
+		return 0.4545 * kU, 0.5454 * (kU / pU), 0
 	case tuneMethodZiegerNicholsPID:
-		p.kP = 0.6 * kU
-		p.kI = 1.2 * (kU / pU)
-		p.kD = 0.075 * kU * pU
+		return 0.6 * kU, 1.2 * (kU / pU), 0.075 * kU * pU
 	case tuneMethodZiegerNicholsSomeOvershoot:
-		p.kP = 0.333 * kU
-		p.kI = 0.66666 * (kU / pU)
-		p.kD = 0.1111 * kU * pU
+		return 0.333 * kU, 0.66666 * (kU / pU), 0.1111 * kU * pU
 	case tuneMethodZiegerNicholsNoOvershoot:
-		p.kP = 0.2 * kU
-		p.kI = 0.4 * (kU / pU)
-		p.kD = 0.0666 * kU * pU
+		return 0.2 * kU, 0.4 * (kU / pU), 0.0666 * kU * pU
 	case tuneMethodTyreusLuybenPI:
-		p.kP = 0.3215 * kU
-		p.kI = 0.1420 * (kU / pU)
-		p.kD = 0.0
+		return 0.3215 * kU, 0.1420 * (kU / pU), 0.0
 	case tuneMethodTyreusLuybenPID:
-		p.kP = 0.4545 * kU
-		p.kI = 0.2066 * (kU / pU)
-		p.kD = 0.0721 * kU * pU
-	case tuneMethodCohenCoonsPI:
-		t1 := (p.ccT2.Seconds() - math.Log(2.0)*p.ccT3.Seconds()) / (1.0 - math.Log(2.0))
-		tau := p.ccT3.Seconds() - t1
-		tauD := t1
-		K := (p.avgSpeedSS / stepPwr)
-		r := tauD / tau
-		p.kP = (1.0 / (K * r)) * (0.9 + r/12)
-		p.kI = p.kP / (tauD) * (30 + 3*r) / (9 + 20*r)
-	case tuneMethodCohenCoonsPID:
-		t1 := (p.ccT2.Seconds() - math.Log(2.0)*p.ccT3.Seconds()) / (1.0 - math.Log(2.0))
-		tau := p.ccT3.Seconds() - t1
-		tauD := t1
-		K := (p.avgSpeedSS / stepPwr)
-		r := tauD / tau
-		p.kP = (1.0 / (K * r)) * (4.0/3.0 + r/4)
-		p.kI = p.kP / (tauD) * (32 + 6*r) / (13 + 8*r)
-		p.kD = p.kP / (4 * tauD / (11 + 2*r))
+		return 0.4545 * kU, 0.2066 * (kU / pU), 0.0721 * kU * pU
 	default:
-		p.kP = 0.4545 * kU
-		p.kI = 0.5454 * (kU / pU)
-		p.kD = 0
+		return 0.4545 * kU, 0.5454 * (kU / pU), 0
 	}
 }
 
@@ -293,10 +331,10 @@ func (p *pidTuner) pidTunerStep(pv float64, logger golog.Logger) (float64, bool)
 				p.avgSpeedSS += p.stepRsp[len(p.stepRsp)-6]
 			}
 			p.avgSpeedSS /= 5
+			p.ccT2 = pidTunerFindTCat(p.stepRsp, p.stepRespT, 0.5*p.avgSpeedSS)
+			p.ccT3 = pidTunerFindTCat(p.stepRsp, p.stepRespT, 0.632*p.avgSpeedSS)
 			if p.tuneMethod == tuneMethodCohenCoonsPI || p.tuneMethod == tuneMethodCohenCoonsPID {
 				p.out = 0.0
-				p.ccT2 = pidTunerFindTCat(p.stepRsp, p.stepRespT, 0.5*p.avgSpeedSS)
-				p.ccT3 = pidTunerFindTCat(p.stepRsp, p.stepRespT, 0.632*p.avgSpeedSS)
 				p.computeGains()
 				p.currentPhase = end
 			} else {
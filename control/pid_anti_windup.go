@@ -0,0 +1,135 @@
+package control
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/edaniels/golog"
+	"github.com/pkg/errors"
+)
+
+// antiWindupPID is a PID controller taking the set point and the measurement as its two inputs. The
+// derivative is taken on the measurement so that steps of the set point do not kick the output, and can be
+// low pass filtered with a time constant of derivative_filter seconds. While the output is held at its
+// limits the integral is pulled back by the difference between the limited and unlimited outputs times the
+// tracking gain kT (back-calculation), so that it does not wind up.
+type antiWindupPID struct {
+	mu       sync.Mutex
+	cfg      BlockConfig
+	y        []Signal
+	kP       float64
+	kI       float64
+	kD       float64
+	kT       float64
+	tF       float64
+	limUp    float64
+	limLo    float64
+	int      float64
+	deriv    float64
+	lastMeas float64
+	started  bool
+	logger   golog.Logger
+}
+
+func newAntiWindupPID(config BlockConfig, logger golog.Logger) (Block, error) {
+	p := &antiWindupPID{cfg: config, logger: logger}
+	if err := p.reset(); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+func (p *antiWindupPID) Next(ctx context.Context, x []Signal, dt time.Duration) ([]Signal, bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if len(x) != 2 {
+		return p.y, false
+	}
+	dtS := dt.Seconds()
+	setPoint := x[0].GetSignalValueAt(0)
+	measured := x[1].GetSignalValueAt(0)
+	pvError := setPoint - measured
+	if p.started && dtS > 0 {
+		raw := -(measured - p.lastMeas) / dtS
+		if p.tF > 0 {
+			p.deriv += dtS / (p.tF + dtS) * (raw - p.deriv)
+		} else {
+			p.deriv = raw
+		}
+	}
+	p.lastMeas = measured
+	p.started = true
+
+	unlimited := p.kP*pvError + p.int + p.kD*p.deriv
+	output := math.Max(p.limLo, math.Min(p.limUp, unlimited))
+	p.int += (p.kI*pvError + p.kT*(output-unlimited)) * dtS
+	p.y[0].SetSignalValueAt(0, output)
+	return p.y, true
+}
+
+func (p *antiWindupPID) reset() error {
+	if !p.cfg.Attribute.Has("kI") &&
+		!p.cfg.Attribute.Has("kD") &&
+		!p.cfg.Attribute.Has("kP") {
+		return errors.Errorf("anti windup pid block %s should have at least one kI, kP or kD field", p.cfg.Name)
+	}
+	if len(p.cfg.DependsOn) != 2 {
+		return errors.Errorf("anti windup pid block %s should have 2 inputs, the set point and the measurement, got %d",
+			p.cfg.Name, len(p.cfg.DependsOn))
+	}
+	p.kI = p.cfg.Attribute.Float64("kI", 0.0)
+	p.kD = p.cfg.Attribute.Float64("kD", 0.0)
+	p.kP = p.cfg.Attribute.Float64("kP", 0.0)
+	p.limUp = p.cfg.Attribute.Float64("limit_up", 255.0)
+	p.limLo = p.cfg.Attribute.Float64("limit_lo", 0)
+	p.tF = p.cfg.Attribute.Float64("derivative_filter", 0)
+	if p.limLo > p.limUp {
+		return errors.Errorf("anti windup pid block %s limit_lo should be below limit_up", p.cfg.Name)
+	}
+	if p.tF < 0 {
+		return errors.Errorf("anti windup pid block %s derivative_filter cannot be negative", p.cfg.Name)
+	}
+	// without a tracking gain the integral is reset with the usual time constant, the geometric mean of the
+	// integral and derivative times, or the integral time alone when there is no derivative
+	p.kT = p.cfg.Attribute.Float64("kT", 0)
+	if !p.cfg.Attribute.Has("kT") && p.kI != 0 && p.kP != 0 {
+		tI := p.kP / p.kI
+		tT := tI
+		if p.kD != 0 {
+			tT = math.Sqrt(tI * p.kD / p.kP)
+		}
+		p.kT = 1 / tT
+	}
+	p.int = 0
+	p.deriv = 0
+	p.lastMeas = 0
+	p.started = false
+	p.y = make([]Signal, 1)
+	p.y[0] = makeSignal(p.cfg.Name)
+	return nil
+}
+
+func (p *antiWindupPID) Reset(ctx context.Context) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.reset()
+}
+
+func (p *antiWindupPID) UpdateConfig(ctx context.Context, config BlockConfig) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.cfg = config
+	return p.reset()
+}
+
+func (p *antiWindupPID) Output(ctx context.Context) []Signal {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.y
+}
+
+func (p *antiWindupPID) Config(ctx context.Context) BlockConfig {
+	return p.cfg
+}
@@ -0,0 +1,144 @@
+package control
+
+import (
+	"context"
+	"math"
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/edaniels/golog"
+	"go.viam.com/test"
+	"go.viam.com/utils/testutils"
+
+	"go.viam.com/rdk/config"
+)
+
+func TestAntiWindupPIDConfig(t *testing.T) {
+	logger := golog.NewTestLogger(t)
+	for _, c := range []struct {
+		conf BlockConfig
+		err  string
+	}{
+		{
+			BlockConfig{Name: "PID", Attribute: config.AttributeMap{"kP": 1.0, "kI": 2.0}, DependsOn: []string{"A", "B"}},
+			"",
+		},
+		{
+			BlockConfig{Name: "PID", Attribute: config.AttributeMap{"kX": 1.0}, DependsOn: []string{"A", "B"}},
+			"anti windup pid block PID should have at least one kI, kP or kD field",
+		},
+		{
+			BlockConfig{Name: "PID", Attribute: config.AttributeMap{"kP": 1.0}, DependsOn: []string{"A"}},
+			"anti windup pid block PID should have 2 inputs, the set point and the measurement, got 1",
+		},
+		{
+			BlockConfig{Name: "PID", Attribute: config.AttributeMap{"kP": 1.0, "limit_lo": 1.0, "limit_up": 0.0}, DependsOn: []string{"A", "B"}},
+			"anti windup pid block PID limit_lo should be below limit_up",
+		},
+		{
+			BlockConfig{Name: "PID", Attribute: config.AttributeMap{"kD": 1.0, "derivative_filter": -1.0}, DependsOn: []string{"A", "B"}},
+			"anti windup pid block PID derivative_filter cannot be negative",
+		},
+	} {
+		_, err := newAntiWindupPID(c.conf, logger)
+		if c.err == "" {
+			test.That(t, err, test.ShouldBeNil)
+		} else {
+			test.That(t, err, test.ShouldNotBeNil)
+			test.That(t, err.Error(), test.ShouldEqual, c.err)
+		}
+	}
+
+	// the tracking gain defaults from the integral and derivative times
+	b, err := newAntiWindupPID(
+		BlockConfig{Name: "PID", Attribute: config.AttributeMap{"kP": 2.0, "kI": 4.0}, DependsOn: []string{"A", "B"}}, logger)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, b.(*antiWindupPID).kT, test.ShouldAlmostEqual, 2)
+	b, err = newAntiWindupPID(
+		BlockConfig{Name: "PID", Attribute: config.AttributeMap{"kP": 2.0, "kI": 4.0, "kD": 0.5}, DependsOn: []string{"A", "B"}}, logger)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, b.(*antiWindupPID).kT, test.ShouldAlmostEqual, 1/math.Sqrt(0.5*0.25))
+}
+
+func TestAntiWindupPIDNext(t *testing.T) {
+	ctx := context.Background()
+	b, err := newAntiWindupPID(BlockConfig{
+		Name:      "PID",
+		Attribute: config.AttributeMap{"kP": 1.0, "kI": 10.0, "kD": 0.1, "kT": 5.0, "limit_up": 1.0, "limit_lo": -1.0},
+		DependsOn: []string{"A", "B"},
+	}, golog.NewTestLogger(t))
+	test.That(t, err, test.ShouldBeNil)
+	pid := b.(*antiWindupPID)
+	dt := 100 * time.Millisecond
+
+	// no derivative on the first measurement
+	out, ok := b.Next(ctx, inputSignals(0.3, 0), dt)
+	test.That(t, ok, test.ShouldBeTrue)
+	test.That(t, out[0].GetSignalValueAt(0), test.ShouldAlmostEqual, 0.3)
+	test.That(t, pid.int, test.ShouldAlmostEqual, 0.3)
+
+	// a step of the set point does not kick the derivative, the measurement moving does
+	out, _ = b.Next(ctx, inputSignals(0.4, 0), dt)
+	test.That(t, out[0].GetSignalValueAt(0), test.ShouldAlmostEqual, 0.4+0.3)
+	_, _ = b.Next(ctx, inputSignals(0.4, 0.1), dt)
+	test.That(t, pid.deriv, test.ShouldAlmostEqual, -1)
+
+	// a large error saturates the output and back calculation keeps the integral from winding up
+	for i := 0; i < 100; i++ {
+		out, _ = b.Next(ctx, inputSignals(100, 0.1), dt)
+		test.That(t, out[0].GetSignalValueAt(0), test.ShouldEqual, 1)
+	}
+	// the integral settles where kI*e is balanced by kT*(u-v), which is finite
+	test.That(t, pid.int, test.ShouldBeLessThan, 200)
+	intBefore := pid.int
+	_, _ = b.Next(ctx, inputSignals(100, 0.1), dt)
+	test.That(t, pid.int, test.ShouldAlmostEqual, intBefore, 1e-6)
+}
+
+func TestAntiWindupPIDLoop(t *testing.T) {
+	ctx := context.Background()
+	plant := &lagPlant{gain: 2, tau: 0.05, rnd: rand.New(rand.NewSource(1))}
+	// the plant can only reach 0.6 within the limits of the pid
+	cLoop := startPlantLoop(t, plant, []BlockConfig{
+		{
+			Name:      "set_point",
+			Type:      "constant",
+			Attribute: config.AttributeMap{"constant_val": 2.0},
+		},
+		{
+			Name:      "endpoint",
+			Type:      "endpoint",
+			Attribute: config.AttributeMap{"motor_name": "plant"},
+			DependsOn: []string{"pid"},
+		},
+		{
+			Name: "pid",
+			Type: "antiWindupPID",
+			Attribute: config.AttributeMap{
+				"kP": 0.2, "kI": 2.0, "kD": 0.01, "derivative_filter": 0.05, "limit_up": 0.3, "limit_lo": -0.3,
+			},
+			DependsOn: []string{"set_point", "endpoint"},
+		},
+	})
+	defer cLoop.Stop()
+	testutils.WaitForAssertion(t, func(tb testing.TB) {
+		tb.Helper()
+		test.That(tb, plant.appliedPower(), test.ShouldEqual, 0.3)
+	})
+	time.Sleep(time.Second)
+
+	// once the set point is reachable the pid leaves saturation right away instead of unwinding its integral
+	cfg, err := cLoop.ConfigAt(ctx, "set_point")
+	test.That(t, err, test.ShouldBeNil)
+	cfg.Attribute = config.AttributeMap{"constant_val": 0.4}
+	test.That(t, cLoop.SetConfigAt(ctx, "set_point", cfg), test.ShouldBeNil)
+	time.Sleep(100 * time.Millisecond)
+	test.That(t, plant.appliedPower(), test.ShouldBeLessThan, 0.3)
+	testutils.WaitForAssertion(t, func(tb testing.TB) {
+		tb.Helper()
+		pos, err := plant.Position(ctx, nil)
+		test.That(tb, err, test.ShouldBeNil)
+		test.That(tb, pos, test.ShouldAlmostEqual, 0.4, 0.01)
+	})
+}
@@ -0,0 +1,97 @@
+package control
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/edaniels/golog"
+	"github.com/pkg/errors"
+)
+
+// saturation clamps its input between limit_lo and limit_up and limits how fast its output can rise and
+// fall, in units per second, with rate_limit_up and rate_limit_down. The output starts from 0.
+type saturation struct {
+	mu       sync.Mutex
+	cfg      BlockConfig
+	y        []Signal
+	limUp    float64
+	limLo    float64
+	rateUp   float64
+	rateDown float64
+	logger   golog.Logger
+}
+
+func newSaturation(config BlockConfig, logger golog.Logger) (Block, error) {
+	s := &saturation{cfg: config, logger: logger}
+	if err := s.reset(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+func (b *saturation) Next(ctx context.Context, x []Signal, dt time.Duration) ([]Signal, bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if len(x) != 1 {
+		return b.y, false
+	}
+	out := math.Max(b.limLo, math.Min(b.limUp, x[0].GetSignalValueAt(0)))
+	last := b.y[0].GetSignalValueAt(0)
+	if b.rateUp > 0 {
+		out = math.Min(out, last+b.rateUp*dt.Seconds())
+	}
+	if b.rateDown > 0 {
+		out = math.Max(out, last-b.rateDown*dt.Seconds())
+	}
+	b.y[0].SetSignalValueAt(0, out)
+	return b.y, true
+}
+
+func (b *saturation) reset() error {
+	if !b.cfg.Attribute.Has("limit_up") && !b.cfg.Attribute.Has("limit_lo") &&
+		!b.cfg.Attribute.Has("rate_limit_up") && !b.cfg.Attribute.Has("rate_limit_down") {
+		return errors.Errorf("saturation block %s should have at least one limit_up, limit_lo, rate_limit_up or rate_limit_down field",
+			b.cfg.Name)
+	}
+	if len(b.cfg.DependsOn) != 1 {
+		return errors.Errorf("invalid number of inputs for saturation block %s expected 1 got %d", b.cfg.Name, len(b.cfg.DependsOn))
+	}
+	b.limUp = b.cfg.Attribute.Float64("limit_up", math.Inf(1))
+	b.limLo = b.cfg.Attribute.Float64("limit_lo", math.Inf(-1))
+	b.rateUp = b.cfg.Attribute.Float64("rate_limit_up", 0)
+	b.rateDown = b.cfg.Attribute.Float64("rate_limit_down", 0)
+	if b.limLo > b.limUp {
+		return errors.Errorf("saturation block %s limit_lo should be below limit_up", b.cfg.Name)
+	}
+	if b.rateUp < 0 || b.rateDown < 0 {
+		return errors.Errorf("saturation block %s rate limits cannot be negative", b.cfg.Name)
+	}
+	b.y = make([]Signal, 1)
+	b.y[0] = makeSignal(b.cfg.Name)
+	return nil
+}
+
+func (b *saturation) Reset(ctx context.Context) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.reset()
+}
+
+func (b *saturation) UpdateConfig(ctx context.Context, config BlockConfig) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.cfg = config
+	return b.reset()
+}
+
+func (b *saturation) Output(ctx context.Context) []Signal {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.y
+}
+
+func (b *saturation) Config(ctx context.Context) BlockConfig {
+	return b.cfg
+}
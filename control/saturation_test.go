@@ -0,0 +1,103 @@
+package control
+
+import (
+	"context"
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/edaniels/golog"
+	"go.viam.com/test"
+	"go.viam.com/utils/testutils"
+
+	"go.viam.com/rdk/config"
+)
+
+func TestSaturationConfig(t *testing.T) {
+	logger := golog.NewTestLogger(t)
+	for _, c := range []struct {
+		conf BlockConfig
+		err  string
+	}{
+		{
+			BlockConfig{Name: "Sat", Attribute: config.AttributeMap{"limit_up": 1.0}, DependsOn: []string{"A"}},
+			"",
+		},
+		{
+			BlockConfig{Name: "Sat", Attribute: config.AttributeMap{"rate_limit_up": 1.0}, DependsOn: []string{"A"}},
+			"",
+		},
+		{
+			BlockConfig{Name: "Sat", Attribute: config.AttributeMap{}, DependsOn: []string{"A"}},
+			"saturation block Sat should have at least one limit_up, limit_lo, rate_limit_up or rate_limit_down field",
+		},
+		{
+			BlockConfig{Name: "Sat", Attribute: config.AttributeMap{"limit_up": 1.0}, DependsOn: []string{"A", "B"}},
+			"invalid number of inputs for saturation block Sat expected 1 got 2",
+		},
+		{
+			BlockConfig{Name: "Sat", Attribute: config.AttributeMap{"limit_up": -1.0, "limit_lo": 1.0}, DependsOn: []string{"A"}},
+			"saturation block Sat limit_lo should be below limit_up",
+		},
+		{
+			BlockConfig{Name: "Sat", Attribute: config.AttributeMap{"rate_limit_down": -1.0}, DependsOn: []string{"A"}},
+			"saturation block Sat rate limits cannot be negative",
+		},
+	} {
+		_, err := newSaturation(c.conf, logger)
+		if c.err == "" {
+			test.That(t, err, test.ShouldBeNil)
+		} else {
+			test.That(t, err, test.ShouldNotBeNil)
+			test.That(t, err.Error(), test.ShouldEqual, c.err)
+		}
+	}
+}
+
+func TestSaturationNext(t *testing.T) {
+	ctx := context.Background()
+	b, err := newSaturation(BlockConfig{
+		Name:      "Sat",
+		Attribute: config.AttributeMap{"limit_up": 1.0, "limit_lo": -1.0, "rate_limit_up": 2.0, "rate_limit_down": 10.0},
+		DependsOn: []string{"A"},
+	}, golog.NewTestLogger(t))
+	test.That(t, err, test.ShouldBeNil)
+	dt := 100 * time.Millisecond
+
+	// rises at 2 per second up to the limit
+	for _, expected := range []float64{0.2, 0.4, 0.6, 0.8, 1, 1} {
+		out, ok := b.Next(ctx, inputSignals(5), dt)
+		test.That(t, ok, test.ShouldBeTrue)
+		test.That(t, out[0].GetSignalValueAt(0), test.ShouldAlmostEqual, expected)
+	}
+	// falls at 10 per second
+	out, _ := b.Next(ctx, inputSignals(-5), dt)
+	test.That(t, out[0].GetSignalValueAt(0), test.ShouldAlmostEqual, 0)
+	out, _ = b.Next(ctx, inputSignals(-0.5), dt)
+	test.That(t, out[0].GetSignalValueAt(0), test.ShouldAlmostEqual, -0.5)
+	out, _ = b.Next(ctx, inputSignals(-5), dt)
+	test.That(t, out[0].GetSignalValueAt(0), test.ShouldAlmostEqual, -1)
+
+	test.That(t, b.Reset(ctx), test.ShouldBeNil)
+	test.That(t, b.Output(ctx)[0].GetSignalValueAt(0), test.ShouldEqual, 0)
+}
+
+func TestSaturationLoop(t *testing.T) {
+	plant := &lagPlant{gain: 1, tau: 0.05, rnd: rand.New(rand.NewSource(1))}
+	start := time.Now()
+	cLoop := sourceLoop(t, plant, BlockConfig{
+		Type:      "saturation",
+		Attribute: config.AttributeMap{"limit_up": 0.5, "rate_limit_up": 1.0},
+	}, 2)
+	defer cLoop.Stop()
+
+	// the power ramps up at 1 per second
+	for plant.appliedPower() < 0.5 {
+		test.That(t, plant.appliedPower(), test.ShouldBeLessThanOrEqualTo, time.Since(start).Seconds()+0.05)
+		time.Sleep(10 * time.Millisecond)
+	}
+	testutils.WaitForAssertion(t, func(tb testing.TB) {
+		tb.Helper()
+		test.That(tb, plant.appliedPower(), test.ShouldEqual, 0.5)
+	})
+}
@@ -0,0 +1,98 @@
+package control
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/edaniels/golog"
+	"github.com/pkg/errors"
+)
+
+// scheduledGain multiplies its first input by a gain that depends on its second input. The gain is
+// interpolated linearly between the gains given at each of the increasing points of the schedule and held
+// at the first and last gains outside of it.
+type scheduledGain struct {
+	mu       sync.Mutex
+	cfg      BlockConfig
+	y        []Signal
+	schedule []float64
+	gains    []float64
+	logger   golog.Logger
+}
+
+func newScheduledGain(config BlockConfig, logger golog.Logger) (Block, error) {
+	g := &scheduledGain{cfg: config, logger: logger}
+	if err := g.reset(); err != nil {
+		return nil, err
+	}
+	return g, nil
+}
+
+func (b *scheduledGain) Next(ctx context.Context, x []Signal, dt time.Duration) ([]Signal, bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if len(x) != 2 {
+		return b.y, false
+	}
+	b.y[0].SetSignalValueAt(0, x[0].GetSignalValueAt(0)*b.gainAt(x[1].GetSignalValueAt(0)))
+	return b.y, true
+}
+
+func (b *scheduledGain) gainAt(s float64) float64 {
+	i := sort.SearchFloat64s(b.schedule, s)
+	switch {
+	case i == 0:
+		return b.gains[0]
+	case i == len(b.schedule):
+		return b.gains[len(b.gains)-1]
+	}
+	frac := (s - b.schedule[i-1]) / (b.schedule[i] - b.schedule[i-1])
+	return b.gains[i-1] + frac*(b.gains[i]-b.gains[i-1])
+}
+
+func (b *scheduledGain) reset() error {
+	if !b.cfg.Attribute.Has("schedule") || !b.cfg.Attribute.Has("gains") {
+		return errors.Errorf("scheduled gain block %s should have a schedule and gains fields", b.cfg.Name)
+	}
+	if len(b.cfg.DependsOn) != 2 {
+		return errors.Errorf("invalid number of inputs for scheduled gain block %s expected 2 got %d", b.cfg.Name, len(b.cfg.DependsOn))
+	}
+	b.schedule = b.cfg.Attribute.Float64Slice("schedule")
+	b.gains = b.cfg.Attribute.Float64Slice("gains")
+	if len(b.schedule) == 0 || len(b.schedule) != len(b.gains) {
+		return errors.Errorf("scheduled gain block %s should have as many gains as points in its schedule", b.cfg.Name)
+	}
+	for i := 1; i < len(b.schedule); i++ {
+		if b.schedule[i] <= b.schedule[i-1] {
+			return errors.Errorf("scheduled gain block %s schedule should be increasing", b.cfg.Name)
+		}
+	}
+	b.y = make([]Signal, 1)
+	b.y[0] = makeSignal(b.cfg.Name)
+	return nil
+}
+
+func (b *scheduledGain) Reset(ctx context.Context) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.reset()
+}
+
+func (b *scheduledGain) UpdateConfig(ctx context.Context, config BlockConfig) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.cfg = config
+	return b.reset()
+}
+
+func (b *scheduledGain) Output(ctx context.Context) []Signal {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.y
+}
+
+func (b *scheduledGain) Config(ctx context.Context) BlockConfig {
+	return b.cfg
+}
@@ -0,0 +1,115 @@
+package control
+
+import (
+	"context"
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/edaniels/golog"
+	"go.viam.com/test"
+	"go.viam.com/utils/testutils"
+
+	"go.viam.com/rdk/config"
+)
+
+func TestScheduledGainConfig(t *testing.T) {
+	logger := golog.NewTestLogger(t)
+	for _, c := range []struct {
+		conf BlockConfig
+		err  string
+	}{
+		{
+			BlockConfig{
+				Name:      "SG",
+				Attribute: config.AttributeMap{"schedule": []interface{}{0.0, 1.0}, "gains": []interface{}{1.0, 2.0}},
+				DependsOn: []string{"A", "B"},
+			},
+			"",
+		},
+		{
+			BlockConfig{
+				Name:      "SG",
+				Attribute: config.AttributeMap{"schedule": []interface{}{0.0, 1.0}},
+				DependsOn: []string{"A", "B"},
+			},
+			"scheduled gain block SG should have a schedule and gains fields",
+		},
+		{
+			BlockConfig{
+				Name:      "SG",
+				Attribute: config.AttributeMap{"schedule": []interface{}{0.0, 1.0}, "gains": []interface{}{1.0, 2.0}},
+				DependsOn: []string{"A"},
+			},
+			"invalid number of inputs for scheduled gain block SG expected 2 got 1",
+		},
+		{
+			BlockConfig{
+				Name:      "SG",
+				Attribute: config.AttributeMap{"schedule": []interface{}{0.0, 1.0}, "gains": []interface{}{1.0}},
+				DependsOn: []string{"A", "B"},
+			},
+			"scheduled gain block SG should have as many gains as points in its schedule",
+		},
+		{
+			BlockConfig{
+				Name:      "SG",
+				Attribute: config.AttributeMap{"schedule": []interface{}{1.0, 0.0}, "gains": []interface{}{1.0, 2.0}},
+				DependsOn: []string{"A", "B"},
+			},
+			"scheduled gain block SG schedule should be increasing",
+		},
+	} {
+		_, err := newScheduledGain(c.conf, logger)
+		if c.err == "" {
+			test.That(t, err, test.ShouldBeNil)
+		} else {
+			test.That(t, err, test.ShouldNotBeNil)
+			test.That(t, err.Error(), test.ShouldEqual, c.err)
+		}
+	}
+}
+
+func TestScheduledGainNext(t *testing.T) {
+	ctx := context.Background()
+	b, err := newScheduledGain(BlockConfig{
+		Name: "SG",
+		Attribute: config.AttributeMap{
+			"schedule": []interface{}{0.0, 10.0, 20.0},
+			"gains":    []interface{}{1.0, 2.0, 4.0},
+		},
+		DependsOn: []string{"A", "B"},
+	}, golog.NewTestLogger(t))
+	test.That(t, err, test.ShouldBeNil)
+	for _, c := range []struct{ schedule, gain float64 }{
+		{-5, 1},
+		{0, 1},
+		{5, 1.5},
+		{10, 2},
+		{15, 3},
+		{20, 4},
+		{100, 4},
+	} {
+		out, ok := b.Next(ctx, inputSignals(2, c.schedule), time.Millisecond)
+		test.That(t, ok, test.ShouldBeTrue)
+		test.That(t, out[0].GetSignalValueAt(0), test.ShouldAlmostEqual, 2*c.gain)
+	}
+	_, ok := b.Next(ctx, inputSignals(2), time.Millisecond)
+	test.That(t, ok, test.ShouldBeFalse)
+}
+
+func TestScheduledGainLoop(t *testing.T) {
+	plant := &lagPlant{gain: 1, tau: 0.05, rnd: rand.New(rand.NewSource(1))}
+	cLoop := sourceLoop(t, plant, BlockConfig{
+		Type: "scheduledGain",
+		Attribute: config.AttributeMap{
+			"schedule": []interface{}{0.0, 1.0},
+			"gains":    []interface{}{0.1, 0.3},
+		},
+	}, 2, 0.5)
+	defer cLoop.Stop()
+	testutils.WaitForAssertion(t, func(tb testing.TB) {
+		tb.Helper()
+		test.That(tb, plant.appliedPower(), test.ShouldAlmostEqual, 0.4)
+	})
+}
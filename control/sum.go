@@ -33,6 +33,8 @@ func newSum(config BlockConfig, logger golog.Logger) (Block, error) {
 }
 
 func (b *sum) Next(ctx context.Context, x []Signal, dt time.Duration) ([]Signal, bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
 	if len(x) != len(b.operation) {
 		return b.y, false
 	}
@@ -90,6 +92,8 @@ func (b *sum) UpdateConfig(ctx context.Context, config BlockConfig) error {
 }
 
 func (b *sum) Output(ctx context.Context) []Signal {
+	b.mu.Lock()
+	defer b.mu.Unlock()
 	return b.y
 }
 
@@ -0,0 +1,105 @@
+package control
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// telemetryBufferSize is how many samples a loop keeps for clients that poll for telemetry, which is 10s
+// worth of samples for a loop running at 100Hz.
+const telemetryBufferSize = 1000
+
+// Sample is the output of every block of a loop at one tick of the loop.
+type Sample struct {
+	Time    time.Time
+	Signals map[string]float64
+}
+
+// telemetry keeps the most recent samples of a loop and publishes every new one to subscribers.
+type telemetry struct {
+	mu          sync.Mutex
+	samples     []Sample
+	next        int
+	subscribers map[chan Sample]struct{}
+}
+
+func newTelemetry() *telemetry {
+	return &telemetry{
+		samples:     make([]Sample, 0, telemetryBufferSize),
+		subscribers: make(map[chan Sample]struct{}),
+	}
+}
+
+func (t *telemetry) record(s Sample) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if len(t.samples) < telemetryBufferSize {
+		t.samples = append(t.samples, s)
+	} else {
+		t.samples[t.next] = s
+	}
+	t.next = (t.next + 1) % telemetryBufferSize
+	for sub := range t.subscribers {
+		// a slow subscriber misses samples rather than holding up the loop
+		select {
+		case sub <- s:
+		default:
+		}
+	}
+}
+
+// since returns the buffered samples taken after the given time, oldest first.
+func (t *telemetry) since(after time.Time) []Sample {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	var out []Sample
+	for i := 0; i < len(t.samples); i++ {
+		s := t.samples[(t.next+i)%len(t.samples)]
+		if s.Time.After(after) {
+			out = append(out, s)
+		}
+	}
+	return out
+}
+
+func (t *telemetry) subscribe(size int) (<-chan Sample, func()) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	ch := make(chan Sample, size)
+	t.subscribers[ch] = struct{}{}
+	var once sync.Once
+	return ch, func() {
+		once.Do(func() {
+			t.mu.Lock()
+			defer t.mu.Unlock()
+			delete(t.subscribers, ch)
+			close(ch)
+		})
+	}
+}
+
+// sample reads the current output of every block.
+func (l *Loop) sample(ctx context.Context, now time.Time) Sample {
+	s := Sample{Time: now, Signals: make(map[string]float64, len(l.blocks))}
+	for name, b := range l.blocks {
+		out := b.blk.Output(ctx)
+		if len(out) == 0 {
+			continue
+		}
+		s.Signals[name] = out[0].GetSignalValueAt(0)
+	}
+	return s
+}
+
+// Subscribe returns a channel that receives a Sample of every block's output at each tick of the loop,
+// along with a function to stop the subscription. Samples are dropped when the channel is full.
+func (l *Loop) Subscribe(size int) (<-chan Sample, func()) {
+	return l.telemetry.subscribe(size)
+}
+
+// Telemetry returns the samples of the most recent ticks of the loop that were taken after the given time,
+// oldest first. Only the last telemetryBufferSize samples are kept.
+func (l *Loop) Telemetry(after time.Time) []Sample {
+	return l.telemetry.since(after)
+}
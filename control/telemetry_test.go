@@ -0,0 +1,168 @@
+package control
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/edaniels/golog"
+	"go.viam.com/test"
+	"go.viam.com/utils/testutils"
+
+	"go.viam.com/rdk/config"
+)
+
+func telemetryTestLoop(t *testing.T) *Loop {
+	t.Helper()
+	cfg := Config{
+		Blocks: []BlockConfig{
+			{
+				Name: "A",
+				Type: "endpoint",
+				Attribute: config.AttributeMap{
+					"motor_name": "MotorFake",
+				},
+				DependsOn: []string{"E"},
+			},
+			{
+				Name: "S",
+				Type: "constant",
+				Attribute: config.AttributeMap{
+					"constant_val": 3.0,
+				},
+				DependsOn: []string{},
+			},
+			{
+				Name: "E",
+				Type: "gain",
+				Attribute: config.AttributeMap{
+					"gain": 2.0,
+				},
+				DependsOn: []string{"S"},
+			},
+		},
+		Frequency: 50.0,
+	}
+	cLoop, err := createLoop(golog.NewTestLogger(t), cfg, nil)
+	test.That(t, err, test.ShouldBeNil)
+	cLoop.Start()
+	return cLoop
+}
+
+func TestLoopTelemetry(t *testing.T) {
+	cLoop := telemetryTestLoop(t)
+	defer cLoop.Stop()
+
+	samples, cancel := cLoop.Subscribe(10)
+	var last time.Time
+	for i := 0; i < 5; i++ {
+		s := <-samples
+		test.That(t, s.Time.After(last), test.ShouldBeTrue)
+		last = s.Time
+		test.That(t, s.Signals, test.ShouldHaveLength, 3)
+		test.That(t, s.Signals["S"], test.ShouldEqual, 3.0)
+	}
+	cancel()
+	cancel()
+	_, ok := <-samples
+	for ok {
+		_, ok = <-samples
+	}
+
+	testutils.WaitForAssertion(t, func(tb testing.TB) {
+		tb.Helper()
+		test.That(tb, cLoop.Telemetry(last), test.ShouldNotBeEmpty)
+	})
+	all := cLoop.Telemetry(time.Time{})
+	test.That(t, len(all), test.ShouldBeGreaterThan, 5)
+	for i := 1; i < len(all); i++ {
+		test.That(t, all[i].Time.After(all[i-1].Time), test.ShouldBeTrue)
+	}
+	test.That(t, all[len(all)-1].Signals["E"], test.ShouldEqual, 6.0)
+	test.That(t, cLoop.Telemetry(all[len(all)-1].Time), test.ShouldBeEmpty)
+}
+
+func TestTelemetryBuffer(t *testing.T) {
+	tel := newTelemetry()
+	start := time.Now()
+	for i := 0; i < telemetryBufferSize+10; i++ {
+		tel.record(Sample{Time: start.Add(time.Duration(i) * time.Millisecond)})
+	}
+	all := tel.since(time.Time{})
+	test.That(t, all, test.ShouldHaveLength, telemetryBufferSize)
+	test.That(t, all[0].Time, test.ShouldEqual, start.Add(10*time.Millisecond))
+	test.That(t, all[len(all)-1].Time, test.ShouldEqual, start.Add(time.Duration(telemetryBufferSize+9)*time.Millisecond))
+	test.That(t, tel.since(all[len(all)-3].Time), test.ShouldHaveLength, 2)
+}
+
+func TestLoopCommands(t *testing.T) {
+	ctx := context.Background()
+	cLoop := telemetryTestLoop(t)
+	defer cLoop.Stop()
+
+	test.That(t, IsCommand(map[string]interface{}{"command": TelemetryCommand}), test.ShouldBeTrue)
+	test.That(t, IsCommand(map[string]interface{}{"command": "nope"}), test.ShouldBeFalse)
+
+	resp, err := cLoop.DoCommand(ctx, map[string]interface{}{"command": BlocksCommand})
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, resp["blocks"], test.ShouldHaveLength, 3)
+
+	testutils.WaitForAssertion(t, func(tb testing.TB) {
+		tb.Helper()
+		resp, err := cLoop.DoCommand(ctx, map[string]interface{}{"command": OutputsCommand})
+		test.That(tb, err, test.ShouldBeNil)
+		test.That(tb, resp["E"], test.ShouldEqual, 6.0)
+	})
+
+	_, err = cLoop.DoCommand(ctx, map[string]interface{}{
+		"command":    SetConfigCommand,
+		"block":      "E",
+		"attributes": map[string]interface{}{"gain": 3.0},
+	})
+	test.That(t, err, test.ShouldBeNil)
+	resp, err = cLoop.DoCommand(ctx, map[string]interface{}{"command": ConfigCommand, "block": "E"})
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, resp["name"], test.ShouldEqual, "E")
+	test.That(t, resp["type"], test.ShouldEqual, "gain")
+	test.That(t, resp["depends_on"], test.ShouldResemble, []interface{}{"S"})
+	test.That(t, resp["attributes"], test.ShouldResemble, map[string]interface{}{"gain": 3.0})
+
+	var since time.Time
+	testutils.WaitForAssertion(t, func(tb testing.TB) {
+		tb.Helper()
+		resp, err := cLoop.DoCommand(ctx, map[string]interface{}{"command": TelemetryCommand})
+		test.That(tb, err, test.ShouldBeNil)
+		samples := resp["samples"].([]interface{})
+		test.That(tb, samples, test.ShouldNotBeEmpty)
+		latest := samples[len(samples)-1].(map[string]interface{})
+		test.That(tb, latest["time"], test.ShouldEqual, resp["latest"])
+		test.That(tb, latest["signals"].(map[string]interface{})["E"], test.ShouldEqual, 9.0)
+		since, err = time.Parse(time.RFC3339Nano, resp["latest"].(string))
+		test.That(tb, err, test.ShouldBeNil)
+	})
+
+	// polling with the latest time only returns newer samples
+	testutils.WaitForAssertion(t, func(tb testing.TB) {
+		tb.Helper()
+		resp, err := cLoop.DoCommand(ctx, map[string]interface{}{"command": TelemetryCommand, "since": since.Format(time.RFC3339Nano)})
+		test.That(tb, err, test.ShouldBeNil)
+		samples := resp["samples"].([]interface{})
+		test.That(tb, samples, test.ShouldNotBeEmpty)
+		for _, s := range samples {
+			sampled, err := time.Parse(time.RFC3339Nano, s.(map[string]interface{})["time"].(string))
+			test.That(tb, err, test.ShouldBeNil)
+			test.That(tb, sampled.After(since), test.ShouldBeTrue)
+		}
+	})
+
+	_, err = cLoop.DoCommand(ctx, map[string]interface{}{"command": TelemetryCommand, "since": "yesterday"})
+	test.That(t, err, test.ShouldNotBeNil)
+	_, err = cLoop.DoCommand(ctx, map[string]interface{}{"command": SetConfigCommand, "block": "E"})
+	test.That(t, err, test.ShouldNotBeNil)
+	_, err = cLoop.DoCommand(ctx, map[string]interface{}{"command": ConfigCommand, "block": "nope"})
+	test.That(t, err, test.ShouldNotBeNil)
+	_, err = cLoop.DoCommand(ctx, map[string]interface{}{"command": "nope"})
+	test.That(t, err, test.ShouldNotBeNil)
+	_, err = cLoop.DoCommand(ctx, map[string]interface{}{})
+	test.That(t, err, test.ShouldNotBeNil)
+}
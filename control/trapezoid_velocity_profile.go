@@ -44,6 +44,8 @@ func newTrapezoidVelocityProfile(config BlockConfig, logger golog.Logger) (Block
 }
 
 func (s *trapezoidVelocityGenerator) Next(ctx context.Context, x []Signal, dt time.Duration) ([]Signal, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
 	var pos float64
 	var setPoint float64
 	if len(x) == 2 {
@@ -110,6 +112,8 @@ func (s *trapezoidVelocityGenerator) reset() error {
 	s.posWindow = s.cfg.Attribute.Float64("pos_window", 10.0)
 	s.kppGain = s.cfg.Attribute.Float64("kpp_gain", 0.45)
 	s.currentPhase = rest
+	// forget the last set point so that the current one is picked up again after a reconfiguration
+	s.lastsetPoint = math.NaN()
 	s.y = make([]Signal, 1)
 	s.y[0] = makeSignal(s.cfg.Name)
 	return nil
@@ -129,6 +133,8 @@ func (s *trapezoidVelocityGenerator) UpdateConfig(ctx context.Context, config Bl
 }
 
 func (s *trapezoidVelocityGenerator) Output(ctx context.Context) []Signal {
+	s.mu.Lock()
+	defer s.mu.Unlock()
 	return s.y
 }
 
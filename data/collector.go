@@ -11,6 +11,7 @@ import (
 	"github.com/edaniels/golog"
 	"github.com/pkg/errors"
 	"go.opencensus.io/trace"
+	"go.uber.org/atomic"
 	v1 "go.viam.com/api/app/datasync/v1"
 	"go.viam.com/utils"
 	"go.viam.com/utils/protoutils"
@@ -41,6 +42,8 @@ func (cf CaptureFunc) Capture(ctx context.Context, params map[string]*anypb.Any)
 type Collector interface {
 	SetTarget(file *datacapture.File)
 	GetTarget() *datacapture.File
+	SetPaused(paused bool)
+	Trigger() error
 	Close()
 	Collect()
 }
@@ -57,6 +60,13 @@ type collector struct {
 	cancel            context.CancelFunc
 	capturer          Capturer
 	closed            bool
+	paused            atomic.Bool
+
+	// With a trigger, readings are only written to the target from the trigger until writeUntil. Until then
+	// they are held in preTrigger, oldest first, for as long as the trigger looks back.
+	trigger    *TriggerParams
+	preTrigger []*v1.SensorData
+	writeUntil time.Time
 }
 
 // SetTarget updates the file being written to by the collector.
@@ -76,6 +86,33 @@ func (c *collector) GetTarget() *datacapture.File {
 	return c.target
 }
 
+// SetPaused pauses or resumes capturing. Nothing is captured while the collector is paused, but it keeps
+// running so that capture picks up again on the next interval after it is resumed.
+func (c *collector) SetPaused(paused bool) {
+	c.paused.Store(paused)
+}
+
+// Trigger writes the readings the collector held from before the trigger to its target and keeps writing
+// readings for the post trigger duration of the collector. Triggering again before then extends it. It is a
+// no-op for collectors that were not constructed with a trigger, which always write everything.
+func (c *collector) Trigger() error {
+	if c.trigger == nil {
+		return nil
+	}
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	for _, msg := range c.preTrigger {
+		if err := c.target.WriteNext(msg); err != nil {
+			return err
+		}
+	}
+	c.preTrigger = nil
+	if until := time.Now().Add(c.trigger.PostTrigger); until.After(c.writeUntil) {
+		c.writeUntil = until
+	}
+	return nil
+}
+
 // Close closes the channels backing the Collector. It should always be called before disposing of a Collector to avoid
 // leaking goroutines.
 func (c *collector) Close() {
@@ -187,6 +224,9 @@ func (c *collector) tickerBasedCapture() {
 }
 
 func (c *collector) getAndPushNextReading() {
+	if c.paused.Load() {
+		return
+	}
 	timeRequested := timestamppb.New(time.Now().UTC())
 	reading, err := c.capturer.Capture(c.cancelCtx, c.params)
 	timeReceived := timestamppb.New(time.Now().UTC())
@@ -260,6 +300,7 @@ func NewCollector(capturer Capturer, params CollectorParams) (Collector, error)
 		backgroundWorkers: sync.WaitGroup{},
 		capturer:          capturer,
 		closed:            false,
+		trigger:           params.Trigger,
 	}, nil
 }
 
@@ -275,12 +316,33 @@ func (c *collector) write() error {
 func (c *collector) appendMessage(msg *v1.SensorData) error {
 	c.lock.Lock()
 	defer c.lock.Unlock()
+	if c.trigger != nil && time.Now().After(c.writeUntil) {
+		c.holdMessage(msg)
+		return nil
+	}
 	if err := c.target.WriteNext(msg); err != nil {
 		return err
 	}
 	return nil
 }
 
+// holdMessage adds msg to the readings held until the next trigger and drops those older than the pre trigger
+// duration.
+func (c *collector) holdMessage(msg *v1.SensorData) {
+	if c.trigger.PreTrigger <= 0 {
+		return
+	}
+	c.preTrigger = append(c.preTrigger, msg)
+	cutoff := msg.GetMetadata().GetTimeRequested().AsTime().Add(-c.trigger.PreTrigger)
+	drop := 0
+	for drop < len(c.preTrigger) && c.preTrigger[drop].GetMetadata().GetTimeRequested().AsTime().Before(cutoff) {
+		drop++
+	}
+	if drop > 0 {
+		c.preTrigger = append(c.preTrigger[:0], c.preTrigger[drop:]...)
+	}
+}
+
 // InvalidInterfaceErr is the error describing when an interface not conforming to the expected resource.Subtype was
 // passed into a CollectorConstructor.
 func InvalidInterfaceErr(typeName resource.SubtypeName) error {
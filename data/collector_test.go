@@ -9,6 +9,7 @@ import (
 	"time"
 
 	"github.com/edaniels/golog"
+	"github.com/pkg/errors"
 	"go.uber.org/zap/zapcore"
 	v1 "go.viam.com/api/app/datasync/v1"
 	"go.viam.com/test"
@@ -16,6 +17,7 @@ import (
 	"google.golang.org/protobuf/proto"
 	"google.golang.org/protobuf/types/known/anypb"
 	"google.golang.org/protobuf/types/known/structpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
 
 	"go.viam.com/rdk/services/datamanager/datacapture"
 )
@@ -288,3 +290,109 @@ func validateReadings(t *testing.T, filePath string, n int) {
 		}
 	}
 }
+
+func TestPause(t *testing.T) {
+	l := golog.NewTestLogger(t)
+	tmpDir := os.TempDir()
+	md := v1.DataCaptureMetadata{}
+	target, _ := datacapture.NewFile(tmpDir, &md)
+	defer os.Remove(target.GetPath())
+	params := CollectorParams{
+		ComponentName: "testComponent",
+		Interval:      time.Millisecond * 5,
+		MethodParams:  map[string]*anypb.Any{"name": fakeVal},
+		Target:        target,
+		QueueSize:     queueSize,
+		BufferSize:    bufferSize,
+		Logger:        l,
+	}
+	c, _ := NewCollector(dummyStructCapturer, params)
+	c.SetPaused(true)
+	c.Collect()
+	defer c.Close()
+
+	// Nothing past the metadata is written while paused.
+	initialSize := target.Size()
+	time.Sleep(time.Millisecond * 25)
+	test.That(t, target.Size(), test.ShouldEqual, initialSize)
+
+	c.SetPaused(false)
+	time.Sleep(time.Millisecond * 25)
+	test.That(t, target.Size(), test.ShouldBeGreaterThan, initialSize)
+}
+
+func TestTrigger(t *testing.T) {
+	tmpDir := t.TempDir()
+	md := v1.DataCaptureMetadata{}
+	target, err := datacapture.NewFile(tmpDir, &md)
+	test.That(t, err, test.ShouldBeNil)
+	c, err := NewCollector(dummyStructCapturer, CollectorParams{
+		ComponentName: "testComponent",
+		Interval:      time.Millisecond * 10,
+		Target:        target,
+		QueueSize:     queueSize,
+		BufferSize:    bufferSize,
+		Logger:        golog.NewTestLogger(t),
+		Trigger:       &TriggerParams{PreTrigger: 30 * time.Millisecond, PostTrigger: time.Hour},
+	})
+	test.That(t, err, test.ShouldBeNil)
+	col := c.(*collector)
+
+	readingAt := func(requested time.Time) *v1.SensorData {
+		return &v1.SensorData{
+			Metadata: &v1.SensorMetadata{TimeRequested: timestamppb.New(requested)},
+			Data:     &v1.SensorData_Struct{Struct: dummyStructReadingProto},
+		}
+	}
+	readTimes := func() []time.Time {
+		test.That(t, target.Sync(), test.ShouldBeNil)
+		file, err := os.Open(target.GetPath())
+		test.That(t, err, test.ShouldBeNil)
+		defer file.Close()
+		f, err := datacapture.ReadFile(file)
+		test.That(t, err, test.ShouldBeNil)
+		var times []time.Time
+		for {
+			read, err := f.ReadNext()
+			if errors.Is(err, io.EOF) {
+				return times
+			}
+			test.That(t, err, test.ShouldBeNil)
+			times = append(times, read.GetMetadata().GetTimeRequested().AsTime())
+		}
+	}
+
+	// Only the readings within the last 30ms are held before the trigger.
+	start := time.Now()
+	for i := 0; i < 10; i++ {
+		test.That(t, col.appendMessage(readingAt(start.Add(time.Duration(i)*10*time.Millisecond))), test.ShouldBeNil)
+	}
+	test.That(t, readTimes(), test.ShouldBeEmpty)
+	test.That(t, col.preTrigger, test.ShouldHaveLength, 4)
+
+	// The trigger writes them out and everything after is written until the post trigger duration is over.
+	test.That(t, c.Trigger(), test.ShouldBeNil)
+	test.That(t, col.appendMessage(readingAt(start.Add(100*time.Millisecond))), test.ShouldBeNil)
+	times := readTimes()
+	test.That(t, times, test.ShouldHaveLength, 5)
+	test.That(t, times[0].Equal(start.Add(60*time.Millisecond)), test.ShouldBeTrue)
+	test.That(t, times[4].Equal(start.Add(100*time.Millisecond)), test.ShouldBeTrue)
+
+	col.lock.Lock()
+	col.writeUntil = time.Now()
+	col.lock.Unlock()
+	test.That(t, col.appendMessage(readingAt(start.Add(110*time.Millisecond))), test.ShouldBeNil)
+	test.That(t, readTimes(), test.ShouldHaveLength, 5)
+	test.That(t, col.preTrigger, test.ShouldHaveLength, 1)
+
+	// Collectors without a trigger write everything, and triggering them does nothing.
+	untriggered, err := NewCollector(dummyStructCapturer, CollectorParams{
+		ComponentName: "testComponent",
+		Target:        target,
+		Logger:        golog.NewTestLogger(t),
+	})
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, untriggered.Trigger(), test.ShouldBeNil)
+	test.That(t, untriggered.(*collector).appendMessage(readingAt(start)), test.ShouldBeNil)
+	test.That(t, readTimes(), test.ShouldHaveLength, 6)
+}
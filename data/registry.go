@@ -24,6 +24,15 @@ type CollectorParams struct {
 	QueueSize     int
 	BufferSize    int
 	Logger        golog.Logger
+	// Trigger, if set, makes the collector only write the readings around its triggers.
+	Trigger *TriggerParams
+}
+
+// TriggerParams configure a collector that captures continuously but only writes the readings captured
+// within PreTrigger before a trigger through PostTrigger after it.
+type TriggerParams struct {
+	PreTrigger  time.Duration
+	PostTrigger time.Duration
 }
 
 // Validate validates that p contains all required parameters.
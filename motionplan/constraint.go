@@ -197,13 +197,33 @@ func NewCollisionConstraintFromWorldState(
 	observationInput map[string][]referenceframe.Input,
 	reportDistances bool,
 ) (Constraint, error) {
-	transformGeometriesToWorldFrame := func(gfs []*commonpb.GeometriesInFrame) (*referenceframe.GeometriesInFrame, error) {
-		allGeometries := make(map[string]spatial.Geometry)
-		for name1, gf := range gfs {
-			obstacles, err := referenceframe.ProtobufToGeometriesInFrame(gf)
+	return newCollisionConstraintFromWorldState(frame, fs, worldState, nil, observationInput, reportDistances)
+}
+
+// newCollisionConstraintFromWorldState is NewCollisionConstraintFromWorldState, which also avoids the given obstacles, such as
+// those the API has no message for, alongside the obstacles of the world state.
+func newCollisionConstraintFromWorldState(
+	frame referenceframe.Frame,
+	fs referenceframe.FrameSystem,
+	worldState *commonpb.WorldState,
+	extraObstacles []*referenceframe.GeometriesInFrame,
+	observationInput map[string][]referenceframe.Input,
+	reportDistances bool,
+) (Constraint, error) {
+	fromProtobuf := func(gfs []*commonpb.GeometriesInFrame) ([]*referenceframe.GeometriesInFrame, error) {
+		converted := make([]*referenceframe.GeometriesInFrame, 0, len(gfs))
+		for _, gf := range gfs {
+			geometries, err := referenceframe.ProtobufToGeometriesInFrame(gf)
 			if err != nil {
 				return nil, err
 			}
+			converted = append(converted, geometries)
+		}
+		return converted, nil
+	}
+	transformGeometriesToWorldFrame := func(gfs []*referenceframe.GeometriesInFrame) (*referenceframe.GeometriesInFrame, error) {
+		allGeometries := make(map[string]spatial.Geometry)
+		for name1, obstacles := range gfs {
 			// TODO(rb) it is bad practice to assume that the current inputs of the robot correspond to the passed in world state
 			// the state that observed the worldState should ultimately be included as part of the worldState message
 			tf, err := fs.Transform(observationInput, obstacles, referenceframe.World)
@@ -220,11 +240,19 @@ func NewCollisionConstraintFromWorldState(
 		}
 		return referenceframe.NewGeometriesInFrame(referenceframe.World, allGeometries), nil
 	}
-	obstacles, err := transformGeometriesToWorldFrame(worldState.GetObstacles())
+	worldStateObstacles, err := fromProtobuf(worldState.GetObstacles())
+	if err != nil {
+		return nil, err
+	}
+	obstacles, err := transformGeometriesToWorldFrame(append(worldStateObstacles, extraObstacles...))
+	if err != nil {
+		return nil, err
+	}
+	worldStateInteractionSpaces, err := fromProtobuf(worldState.GetInteractionSpaces())
 	if err != nil {
 		return nil, err
 	}
-	interactionSpaces, err := transformGeometriesToWorldFrame(worldState.GetInteractionSpaces())
+	interactionSpaces, err := transformGeometriesToWorldFrame(worldStateInteractionSpaces)
 	if err != nil {
 		return nil, err
 	}
@@ -210,6 +210,21 @@ func TestCollisionConstraint(t *testing.T) {
 			test.That(t, response, test.ShouldEqual, c.expected)
 		})
 	}
+
+	// the same obstacles modeled as a capsule and a cylinder should give the same results
+	cc, err := spatial.NewCapsuleCreator(1, 2, spatial.NewZeroPose(), "")
+	test.That(t, err, test.ShouldBeNil)
+	obstacles["obstacle1"] = cc.NewGeometry(spatial.NewZeroPose())
+	cyc, err := spatial.NewCylinderCreator(1, 2, spatial.NewZeroPose(), "")
+	test.That(t, err, test.ShouldBeNil)
+	obstacles["obstacle2"] = cyc.NewGeometry(spatial.NewPoseFromPoint(r3.Vector{-130, 0, 300}))
+	handler.AddConstraint("collision", NewCollisionConstraint(model, zeroPos, obstacles, map[string]spatial.Geometry{}, false))
+	for i, c := range cases {
+		t.Run(fmt.Sprintf("Test curved %d", i), func(t *testing.T) {
+			response, _ := handler.CheckConstraints(&ConstraintInput{StartInput: c.input, Frame: model})
+			test.That(t, response, test.ShouldEqual, c.expected)
+		})
+	}
 }
 
 var bt bool
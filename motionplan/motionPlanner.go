@@ -45,6 +45,21 @@ func PlanMotion(ctx context.Context,
 	return PlanWaypoints(ctx, logger, []*frame.PoseInFrame{dst}, f, seedMap, fs, worldState, []map[string]interface{}{planningOpts})
 }
 
+// PlanMotionWithConstraints plans a motion like PlanMotion, and also returns the sorted names of the constraints that the motion was
+// held to by the planner.
+func PlanMotionWithConstraints(ctx context.Context,
+	logger golog.Logger,
+	dst *frame.PoseInFrame,
+	f frame.Frame,
+	seedMap map[string][]frame.Input,
+	fs frame.FrameSystem,
+	worldState *commonpb.WorldState,
+	planningOpts map[string]interface{},
+) ([]map[string][]frame.Input, []string, error) {
+	solvableFS := NewSolvableFrameSystem(fs, logger)
+	return solvableFS.solveWaypoints(ctx, seedMap, []*frame.PoseInFrame{dst}, f.Name(), worldState, []map[string]interface{}{planningOpts})
+}
+
 // PlanRobotMotion plans a motion to destination for a given frame. A robot object is passed in and current position inputs are determined.
 func PlanRobotMotion(ctx context.Context,
 	dst *frame.PoseInFrame,
@@ -114,6 +129,113 @@ func PlanWaypoints(ctx context.Context,
 	return solvableFS.SolveWaypointsWithOptions(ctx, seedMap, dst, f.Name(), worldState, planningOpts)
 }
 
+// CheckPlan checks that moving through the steps of a plan for a given frame, such as one returned by PlanMotion, from the positions
+// in seedMap does not bring the frame or anything that moves with it into collision with the obstacles of the world state or with
+// itself. It returns an error naming the first step of the plan that cannot be reached without a collision.
+func CheckPlan(
+	f frame.Frame,
+	seedMap map[string][]frame.Input,
+	plan []map[string][]frame.Input,
+	fs frame.FrameSystem,
+	worldState *commonpb.WorldState,
+	logger golog.Logger,
+) error {
+	sf, checker, err := newCollisionChecker(f, seedMap, fs, worldState, logger)
+	if err != nil {
+		return err
+	}
+	start, err := sf.mapToSlice(seedMap)
+	if err != nil {
+		return err
+	}
+	for i, step := range plan {
+		end, err := sf.mapToSlice(step)
+		if err != nil {
+			return err
+		}
+		if ok, _ := checker.CheckConstraintPath(&ConstraintInput{StartInput: start, EndInput: end, Frame: sf}, defaultResolution); !ok {
+			return fmt.Errorf("step %d of the plan for %s collides with the world state", i, f.Name())
+		}
+		start = end
+	}
+	return nil
+}
+
+// CheckTrajectories checks that following trajectories, such as those returned by NewPlanTrajectories, with the frames of fs they
+// are named after from the positions in seedMap does not bring the given frame or anything that moves with it into collision with
+// the obstacles of the world state or with itself. Trajectories cut the corners at the steps of the plan they were generated for,
+// which CheckPlan does not see, so they are checked at every one of their points. It returns an error naming the time along the
+// trajectories of the first collision.
+func CheckTrajectories(
+	f frame.Frame,
+	seedMap map[string][]frame.Input,
+	trajectories map[string]Trajectory,
+	fs frame.FrameSystem,
+	worldState *commonpb.WorldState,
+	logger golog.Logger,
+) error {
+	sf, checker, err := newCollisionChecker(f, seedMap, fs, worldState, logger)
+	if err != nil {
+		return err
+	}
+	start, err := sf.mapToSlice(seedMap)
+	if err != nil {
+		return err
+	}
+	// the trajectories share their times, the one with the most points holds all of them
+	var clock Trajectory
+	for _, trajectory := range trajectories {
+		if len(trajectory) > len(clock) {
+			clock = trajectory
+		}
+	}
+	inputs := make(map[string][]frame.Input, len(seedMap))
+	for name, seed := range seedMap {
+		inputs[name] = seed
+	}
+	for _, point := range clock {
+		for name, trajectory := range trajectories {
+			inputs[name] = trajectory.At(point.Time).Inputs
+		}
+		end, err := sf.mapToSlice(inputs)
+		if err != nil {
+			return err
+		}
+		if ok, _ := checker.CheckConstraintPath(&ConstraintInput{StartInput: start, EndInput: end, Frame: sf}, defaultResolution); !ok {
+			return fmt.Errorf("the trajectory for %s collides with the world state %v after it starts", f.Name(), point.Time)
+		}
+		start = end
+	}
+	return nil
+}
+
+// newCollisionChecker returns the frame that solves for f in fs, along with a constraint handler that holds it to avoid collisions
+// with the obstacles of the world state and with itself.
+func newCollisionChecker(
+	f frame.Frame,
+	seedMap map[string][]frame.Input,
+	fs frame.FrameSystem,
+	worldState *commonpb.WorldState,
+	logger golog.Logger,
+) (*solverFrame, *constraintHandler, error) {
+	solvableFS := NewSolvableFrameSystem(fs, logger)
+	solveFrameList, err := solvableFS.TracebackFrame(f)
+	if err != nil {
+		return nil, nil, err
+	}
+	sf, err := newSolverFrame(solvableFS, solveFrameList, frame.World, seedMap)
+	if err != nil {
+		return nil, nil, err
+	}
+	collisionConstraint, err := NewCollisionConstraintFromWorldState(sf, fs, worldState, seedMap, false)
+	if err != nil {
+		return nil, nil, err
+	}
+	checker := &constraintHandler{}
+	checker.AddConstraint(defaultCollisionConstraintName, collisionConstraint)
+	return sf, checker, nil
+}
+
 // FrameStepsFromRobotPath is a helper function which will extract the waypoints of a single frame from the map output of a robot path.
 func FrameStepsFromRobotPath(frameName string, path []map[string][]frame.Input) ([][]frame.Input, error) {
 	solution := make([][]frame.Input, 0, len(path))
@@ -394,4 +516,3 @@ func extractPath(startMap, goalMap map[node]node, pair *nodePair) []node {
 	}
 	return path
 }
-
@@ -134,6 +134,33 @@ func TestPlanningWithGripper(t *testing.T) {
 	test.That(t, len(solutionMap), test.ShouldBeGreaterThanOrEqualTo, 2)
 }
 
+func TestPlanMotionWithConstraints(t *testing.T) {
+	fs := frame.NewEmptySimpleFrameSystem("")
+	limits := []frame.Limit{{Min: -100, Max: 100}, {Min: -100, Max: 100}}
+	geometry, err := spatialmath.NewBoxCreator(r3.Vector{X: 10, Y: 10, Z: 10}, spatialmath.NewZeroPose(), "")
+	test.That(t, err, test.ShouldBeNil)
+	base, err := frame.NewMobile2DFrame("mobile-base", limits, geometry)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, fs.AddFrame(base, fs.World()), test.ShouldBeNil)
+	zeroPos := frame.StartPositions(fs)
+	goal := frame.NewPoseInFrame(frame.World, spatialmath.NewPoseFromPoint(r3.Vector{X: 50, Y: 20}))
+
+	steps, constraints, err := PlanMotionWithConstraints(context.Background(), logger.Sugar(), goal, base, zeroPos, fs, nil, nil)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, len(steps), test.ShouldBeGreaterThanOrEqualTo, 1)
+	test.That(t, constraints, test.ShouldResemble, []string{defaultCollisionConstraintName, defaultJointConstraint})
+
+	// the constraint of a motion profile is returned along with the default ones
+	orientation := map[string]interface{}{"motion_profile": OrientationMotionProfile}
+	_, constraints, err = PlanMotionWithConstraints(context.Background(), logger.Sugar(), goal, base, zeroPos, fs, nil, orientation)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, constraints, test.ShouldResemble, []string{
+		defaultCollisionConstraintName,
+		defaultJointConstraint,
+		defaultOrientationConstraintName,
+	})
+}
+
 // simple2DMapConfig returns a planConfig with the following map
 //   - start at (-9, 9) and end at (9, 9)
 //   - bounds are from (-10, -10) to (10, 10)
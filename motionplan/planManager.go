@@ -7,6 +7,7 @@ import (
 	"math"
 	"math/rand"
 	"runtime"
+	"sort"
 	"time"
 
 	"github.com/edaniels/golog"
@@ -49,13 +50,24 @@ func (mp *planManager) PlanSingleWaypoint(ctx context.Context,
 	worldState *commonpb.WorldState,
 	motionConfig map[string]interface{},
 ) ([][]referenceframe.Input, error) {
+	resultSlices, _, err := mp.planSingleWaypoint(ctx, seedMap, goalPos, worldState, motionConfig)
+	return resultSlices, err
+}
+
+// planSingleWaypoint is PlanSingleWaypoint, which also returns the sorted names of the constraints that the motion was held to.
+func (mp *planManager) planSingleWaypoint(ctx context.Context,
+	seedMap map[string][]referenceframe.Input,
+	goalPos spatialmath.Pose,
+	worldState *commonpb.WorldState,
+	motionConfig map[string]interface{},
+) ([][]referenceframe.Input, []string, error) {
 	seed, err := mp.frame.mapToSlice(seedMap)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 	seedPos, err := mp.frame.Transform(seed)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
 	var cancel func()
@@ -72,7 +84,7 @@ func (mp *planManager) PlanSingleWaypoint(ctx context.Context,
 	if mp.frame.worldRooted {
 		tf, err := mp.frame.fss.Transform(seedMap, referenceframe.NewPoseInFrame(mp.frame.goalFrame.Name(), goalPos), referenceframe.World)
 		if err != nil {
-			return nil, err
+			return nil, nil, err
 		}
 		goalPos = tf.(*referenceframe.PoseInFrame).Pose()
 	}
@@ -95,7 +107,7 @@ func (mp *planManager) PlanSingleWaypoint(ctx context.Context,
 			goals = append(goals, to)
 			opt, err := mp.plannerSetupFromMoveRequest(from, to, seedMap, worldState, motionConfig)
 			if err != nil {
-				return nil, err
+				return nil, nil, err
 			}
 			opts = append(opts, opt)
 
@@ -106,15 +118,36 @@ func (mp *planManager) PlanSingleWaypoint(ctx context.Context,
 	goals = append(goals, goalPos)
 	opt, err := mp.plannerSetupFromMoveRequest(seedPos, goalPos, seedMap, worldState, motionConfig)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 	opts = append(opts, opt)
 
 	resultSlices, err := mp.planMotion(ctx, goals, seed, opts, nil, 0)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
+	}
+	return resultSlices, constraintNames(opts), nil
+}
+
+// constraintNames returns the sorted names of the constraints of any of the given options.
+func constraintNames(opts []*plannerOptions) []string {
+	var names []string
+	for _, opt := range opts {
+		names = append(names, opt.Constraints()...)
 	}
-	return resultSlices, nil
+	return sortedUnique(names)
+}
+
+// sortedUnique returns the given names sorted, without duplicates.
+func sortedUnique(names []string) []string {
+	sort.Strings(names)
+	unique := names[:0]
+	for i, name := range names {
+		if i == 0 || name != names[i-1] {
+			unique = append(unique, name)
+		}
+	}
+	return unique
 }
 
 // planMotion will plan a single motion, which may be composed of one or more waypoints. Waypoints are here used to begin planning the next
@@ -303,7 +336,14 @@ func (mp *planManager) plannerSetupFromMoveRequest(
 	// not yet fully supported, but could be used by cbirrt
 	getColDepth := false
 
-	collisionConstraint, err := NewCollisionConstraintFromWorldState(mp.frame, mp.fs, worldState, seedMap, getColDepth)
+	var obstacles []*referenceframe.GeometriesInFrame
+	if rawObstacles, ok := planningOpts[ObstaclesOption]; ok {
+		obstacles, ok = rawObstacles.([]*referenceframe.GeometriesInFrame)
+		if !ok {
+			return nil, errors.New("could not interpret obstacles field as []*referenceframe.GeometriesInFrame")
+		}
+	}
+	collisionConstraint, err := newCollisionConstraintFromWorldState(mp.frame, mp.fs, worldState, obstacles, seedMap, getColDepth)
 	if err != nil {
 		return nil, err
 	}
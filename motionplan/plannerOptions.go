@@ -55,6 +55,12 @@ const (
 	PositionOnlyMotionProfile = "position_only"
 )
 
+// ObstaclesOption is the planning option under which obstacles are passed to the planner in process, as a
+// []*referenceframe.GeometriesInFrame, to be avoided alongside those of the world state. It is how capsules, cylinders and meshes,
+// which the API has no message for and so cannot be in a world state, are planned around; it cannot be sent through the motion
+// service, and CheckPlan and CheckTrajectories only check the world state.
+const ObstaclesOption = "obstacles"
+
 // defaultDistanceFunc returns the square of the two-norm between the StartInput and EndInput vectors in the given ConstraintInput.
 func defaultDistanceFunc(ci *ConstraintInput) (bool, float64) {
 	diff := make([]float64, 0, len(ci.StartInput))
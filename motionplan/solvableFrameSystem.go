@@ -49,19 +49,33 @@ func (fss *SolvableFrameSystem) SolveWaypointsWithOptions(ctx context.Context,
 	worldState *commonpb.WorldState,
 	motionConfigs []map[string]interface{},
 ) ([]map[string][]frame.Input, error) {
+	steps, _, err := fss.solveWaypoints(ctx, seedMap, goals, solveFrameName, worldState, motionConfigs)
+	return steps, err
+}
+
+// solveWaypoints is SolveWaypointsWithOptions, which also returns the sorted names of the constraints that any of the motions to
+// the goals were held to.
+func (fss *SolvableFrameSystem) solveWaypoints(ctx context.Context,
+	seedMap map[string][]frame.Input,
+	goals []*frame.PoseInFrame,
+	solveFrameName string,
+	worldState *commonpb.WorldState,
+	motionConfigs []map[string]interface{},
+) ([]map[string][]frame.Input, []string, error) {
 	steps := make([]map[string][]frame.Input, 0, len(goals)*2)
 
 	// Get parentage of solver frame. This will also verify the frame is in the frame system
 	solveFrame := fss.Frame(solveFrameName)
 	if solveFrame == nil {
-		return nil, fmt.Errorf("frame with name %s not found in frame system", solveFrameName)
+		return nil, nil, fmt.Errorf("frame with name %s not found in frame system", solveFrameName)
 	}
 	solveFrameList, err := fss.TracebackFrame(solveFrame)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
 	opts := make([]map[string]interface{}, 0, len(goals))
+	var constraints []string
 
 	// If no planning opts, use default. If one, use for all goals. If one per goal, use respective option. Otherwise error.
 	if len(motionConfigs) != len(goals) {
@@ -76,7 +90,7 @@ func (fss *SolvableFrameSystem) SolveWaypointsWithOptions(ctx context.Context,
 				opts = append(opts, motionConfigs[0])
 			}
 		default:
-			return nil, errors.New("goals and motion configs had different lengths")
+			return nil, nil, errors.New("goals and motion configs had different lengths")
 		}
 	} else {
 		opts = motionConfigs
@@ -88,20 +102,21 @@ func (fss *SolvableFrameSystem) SolveWaypointsWithOptions(ctx context.Context,
 		// Create a frame to solve for, and an IK solver with that frame.
 		sf, err := newSolverFrame(fss, solveFrameList, goal.FrameName(), seedMap)
 		if err != nil {
-			return nil, err
+			return nil, nil, err
 		}
 		if len(sf.DoF()) == 0 {
-			return nil, errors.New("solver frame has no degrees of freedom, cannot perform inverse kinematics")
+			return nil, nil, errors.New("solver frame has no degrees of freedom, cannot perform inverse kinematics")
 		}
 
 		sfPlanner, err := newPlanManager(sf, fss, fss.logger, i)
 		if err != nil {
-			return nil, err
+			return nil, nil, err
 		}
-		resultSlices, err := sfPlanner.PlanSingleWaypoint(ctx, seedMap, goal.Pose(), worldState, opts[i])
+		resultSlices, goalConstraints, err := sfPlanner.planSingleWaypoint(ctx, seedMap, goal.Pose(), worldState, opts[i])
 		if err != nil {
-			return nil, err
+			return nil, nil, err
 		}
+		constraints = append(constraints, goalConstraints...)
 		for j, resultSlice := range resultSlices {
 			stepMap := sf.sliceToMap(resultSlice)
 			steps = append(steps, stepMap)
@@ -112,7 +127,7 @@ func (fss *SolvableFrameSystem) SolveWaypointsWithOptions(ctx context.Context,
 		}
 	}
 
-	return steps, nil
+	return steps, sortedUnique(constraints), nil
 }
 
 // solverFrames are meant to be ephemerally created each time a frame system solution is created, and fulfills the
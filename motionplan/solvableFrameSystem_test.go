@@ -161,8 +161,9 @@ func TestMovementWithGripper(t *testing.T) {
 	test.That(t, err, test.ShouldBeNil)
 	geometries := make(map[string]spatial.Geometry)
 	geometries["obstacle"] = obstacle
-	obstacles := []*commonpb.GeometriesInFrame{frame.GeometriesInFrameToProtobuf(frame.NewGeometriesInFrame(frame.World, geometries))}
-	worldState := &commonpb.WorldState{Obstacles: obstacles}
+	obstacles, err := frame.GeometriesInFrameToProtobuf(frame.NewGeometriesInFrame(frame.World, geometries))
+	test.That(t, err, test.ShouldBeNil)
+	worldState := &commonpb.WorldState{Obstacles: []*commonpb.GeometriesInFrame{obstacles}}
 	sfPlanner, err = newPlanManager(sf, solver, solver.logger, 1)
 	test.That(t, err, test.ShouldBeNil)
 	solution, err = sfPlanner.PlanSingleWaypoint(context.Background(), zeroPosition, goal, worldState, nil)
@@ -201,3 +202,133 @@ func TestMovementWithGripper(t *testing.T) {
 	test.That(t, err, test.ShouldBeNil)
 	test.That(t, solution, test.ShouldNotBeNil)
 }
+
+// obstacleAtGripper returns a world state with an obstacle where the gripper of the test frame system is when the gantry is at x and
+// y.
+func obstacleAtGripper(t *testing.T, solver *SolvableFrameSystem, x, y float64) *commonpb.WorldState {
+	t.Helper()
+	gripper := solver.Frame("xArmVgripper")
+	sFrames, err := solver.TracebackFrame(gripper)
+	test.That(t, err, test.ShouldBeNil)
+	sf, err := newSolverFrame(solver, sFrames, frame.World, frame.StartPositions(solver))
+	test.That(t, err, test.ShouldBeNil)
+	inputs := frame.StartPositions(solver)
+	inputs["gantryX"] = []frame.Input{{Value: x}}
+	inputs["gantryY"] = []frame.Input{{Value: y}}
+	slice, err := sf.mapToSlice(inputs)
+	test.That(t, err, test.ShouldBeNil)
+	gf, _ := sf.Geometries(slice)
+	test.That(t, gf, test.ShouldNotBeNil)
+	obstacle, err := spatial.NewBox(gf.Geometries()["xArmVgripper"].Pose(), r3.Vector{10, 10, 10}, "")
+	test.That(t, err, test.ShouldBeNil)
+	geometries := map[string]spatial.Geometry{"obstacle": obstacle}
+	obstacles, err := frame.GeometriesInFrameToProtobuf(frame.NewGeometriesInFrame(frame.World, geometries))
+	test.That(t, err, test.ShouldBeNil)
+	return &commonpb.WorldState{Obstacles: []*commonpb.GeometriesInFrame{obstacles}}
+}
+
+func TestObstaclesOption(t *testing.T) {
+	solver := makeTestFS(t)
+	sFrames, err := solver.TracebackFrame(solver.Frame("xArmVgripper"))
+	test.That(t, err, test.ShouldBeNil)
+	sf, err := newSolverFrame(solver, sFrames, frame.World, frame.StartPositions(solver))
+	test.That(t, err, test.ShouldBeNil)
+	start := frame.StartPositions(solver)
+	moved := frame.StartPositions(solver)
+	moved["gantryX"] = []frame.Input{{Value: 500}}
+	slice, err := sf.mapToSlice(moved)
+	test.That(t, err, test.ShouldBeNil)
+	gf, _ := sf.Geometries(slice)
+	test.That(t, gf, test.ShouldNotBeNil)
+	ci := &ConstraintInput{StartInput: slice, EndInput: slice, Frame: sf}
+
+	// a capsule has no message to put in a world state, so it is passed to the planner as an option, here where the gripper is
+	// once the gantry has moved
+	capsule, err := spatial.NewCapsule(gf.Geometries()["xArmVgripper"].Pose(), 10, 40, "")
+	test.That(t, err, test.ShouldBeNil)
+	obstacles := []*frame.GeometriesInFrame{frame.NewGeometriesInFrame(frame.World, map[string]spatial.Geometry{"capsule": capsule})}
+	sfPlanner, err := newPlanManager(sf, solver, solver.logger, 1)
+	test.That(t, err, test.ShouldBeNil)
+	opt, err := sfPlanner.plannerSetupFromMoveRequest(spatial.NewZeroPose(), spatial.NewZeroPose(), start, nil, map[string]interface{}{
+		"planning_alg": "cbirrt",
+	})
+	test.That(t, err, test.ShouldBeNil)
+	ok, _ := opt.CheckConstraints(ci)
+	test.That(t, ok, test.ShouldBeTrue)
+	opt, err = sfPlanner.plannerSetupFromMoveRequest(spatial.NewZeroPose(), spatial.NewZeroPose(), start, nil, map[string]interface{}{
+		"planning_alg":  "cbirrt",
+		ObstaclesOption: obstacles,
+	})
+	test.That(t, err, test.ShouldBeNil)
+	ok, _ = opt.CheckConstraints(ci)
+	test.That(t, ok, test.ShouldBeFalse)
+
+	_, err = sfPlanner.plannerSetupFromMoveRequest(spatial.NewZeroPose(), spatial.NewZeroPose(), start, nil, map[string]interface{}{
+		ObstaclesOption: "capsule",
+	})
+	test.That(t, err, test.ShouldNotBeNil)
+}
+
+func TestCheckPlan(t *testing.T) {
+	solver := makeTestFS(t)
+	gripper := solver.Frame("xArmVgripper")
+
+	// slide the gantry along x, which carries the arm and gripper with it
+	start := frame.StartPositions(solver)
+	plan := []map[string][]frame.Input{}
+	for _, x := range []float64{500, 1000} {
+		step := frame.StartPositions(solver)
+		step["gantryX"] = []frame.Input{{Value: x}}
+		plan = append(plan, step)
+	}
+	worldStateAt := func(x float64) *commonpb.WorldState {
+		return obstacleAtGripper(t, solver, x, 0)
+	}
+
+	err := CheckPlan(gripper, start, plan, solver, nil, solver.logger)
+	test.That(t, err, test.ShouldBeNil)
+	err = CheckPlan(gripper, start, plan, solver, worldStateAt(5000), solver.logger)
+	test.That(t, err, test.ShouldBeNil)
+
+	// the obstacle is passed through on the way to the first step
+	err = CheckPlan(gripper, start, plan, solver, worldStateAt(250), solver.logger)
+	test.That(t, err, test.ShouldNotBeNil)
+	test.That(t, err.Error(), test.ShouldContainSubstring, "step 0")
+
+	// the obstacle is only in the way of the second step, so the first step alone is clear
+	err = CheckPlan(gripper, start, plan, solver, worldStateAt(750), solver.logger)
+	test.That(t, err, test.ShouldNotBeNil)
+	test.That(t, err.Error(), test.ShouldContainSubstring, "step 1")
+	err = CheckPlan(gripper, start, plan[:1], solver, worldStateAt(750), solver.logger)
+	test.That(t, err, test.ShouldBeNil)
+}
+
+func TestCheckTrajectories(t *testing.T) {
+	solver := makeTestFS(t)
+	gripper := solver.Frame("xArmVgripper")
+
+	// move the gantry along x and then along y, around a corner at x 5000
+	start := frame.StartPositions(solver)
+	plan := []map[string][]frame.Input{start}
+	for _, xy := range [][]float64{{5000, 0}, {5000, 5000}} {
+		step := frame.StartPositions(solver)
+		step["gantryX"] = []frame.Input{{Value: xy[0]}}
+		step["gantryY"] = []frame.Input{{Value: xy[1]}}
+		plan = append(plan, step)
+	}
+	limit := []JointLimit{{MaxVelocity: 500, MaxAcceleration: 1000}}
+	trajectories, err := NewPlanTrajectories(plan, map[string][]JointLimit{"gantryX": limit, "gantryY": limit}, TrajectoryOptions{
+		MaxDeviation: 1000,
+	})
+	test.That(t, err, test.ShouldBeNil)
+
+	err = CheckTrajectories(gripper, start, trajectories, solver, obstacleAtGripper(t, solver, 0, 5000), solver.logger)
+	test.That(t, err, test.ShouldBeNil)
+
+	// the steps of the plan pass clear of an obstacle inside the corner, which the trajectory cuts through 1000 from the corner
+	inCorner := obstacleAtGripper(t, solver, 5000-1000/math.Sqrt2, 1000/math.Sqrt2)
+	test.That(t, CheckPlan(gripper, start, plan[1:], solver, inCorner, solver.logger), test.ShouldBeNil)
+	err = CheckTrajectories(gripper, start, trajectories, solver, inCorner, solver.logger)
+	test.That(t, err, test.ShouldNotBeNil)
+	test.That(t, err.Error(), test.ShouldContainSubstring, "collides")
+}
@@ -0,0 +1,642 @@
+package motionplan
+
+import (
+	"math"
+	"sort"
+	"time"
+
+	"github.com/pkg/errors"
+	"gonum.org/v1/gonum/floats"
+
+	frame "go.viam.com/rdk/referenceframe"
+)
+
+// default values for trajectory generation.
+const (
+	// time between consecutive points of a trajectory.
+	defaultTrajectorySamplePeriod = 10 * time.Millisecond
+
+	// furthest distance in joint space that a trajectory may stray from a waypoint it blends past rather than stopping at.
+	defaultTrajectoryMaxDeviation = 0.05
+
+	// number of steps along each line and each blend of a path at which the fastest speed along it is solved for.
+	trajectoryLineSteps  = 100
+	trajectoryBlendSteps = 50
+
+	// proportion by which a sampled trajectory may exceed a joint limit because of the discretization of its path and time.
+	trajectoryLimitTolerance = 0.01
+
+	// number of times a trajectory is slowed down to fit within the joint limits before giving up.
+	trajectoryMaxRetries = 10
+
+	// distances and angles below this are considered to be zero when building paths.
+	trajectoryEpsilon = 1e-9
+)
+
+// JointLimit holds the limits on the motion of a single joint, in the units of its input per second, per second squared and per
+// second cubed respectively. A MaxJerk of zero leaves the jerk of the joint unlimited.
+type JointLimit struct {
+	MaxVelocity     float64
+	MaxAcceleration float64
+	MaxJerk         float64
+}
+
+// TrajectoryOptions configures the generation of a trajectory, fields that are left as zero take default values.
+type TrajectoryOptions struct {
+	// SamplePeriod is the time between consecutive points of the trajectory.
+	SamplePeriod time.Duration
+
+	// MaxDeviation is the furthest distance in joint space that the trajectory may cut the corner at a waypoint.
+	MaxDeviation float64
+}
+
+// TrajectoryPoint is the state of a set of joints at a time since the start of a trajectory.
+type TrajectoryPoint struct {
+	Time          time.Duration
+	Inputs        []frame.Input
+	Velocities    []float64
+	Accelerations []float64
+}
+
+// Trajectory is a sequence of points, evenly spaced in time, that a set of joints moves through. It starts and ends at rest.
+type Trajectory []TrajectoryPoint
+
+// Duration returns the time it takes to follow the trajectory.
+func (t Trajectory) Duration() time.Duration {
+	if len(t) == 0 {
+		return 0
+	}
+	return t[len(t)-1].Time
+}
+
+// At returns the state of the trajectory at the given time since its start, interpolated linearly between its points. Times before
+// the start or after the end of the trajectory give its first or last point.
+func (t Trajectory) At(elapsed time.Duration) TrajectoryPoint {
+	if len(t) == 0 {
+		return TrajectoryPoint{}
+	}
+	i := sort.Search(len(t), func(i int) bool { return t[i].Time > elapsed })
+	if i == 0 {
+		return t[0]
+	}
+	if i == len(t) {
+		return t[len(t)-1]
+	}
+	before, after := t[i-1], t[i]
+	by := float64(elapsed-before.Time) / float64(after.Time-before.Time)
+	return TrajectoryPoint{
+		Time:          elapsed,
+		Inputs:        frame.InterpolateInputs(before.Inputs, after.Inputs, by),
+		Velocities:    interpolateFloats(before.Velocities, after.Velocities, by),
+		Accelerations: interpolateFloats(before.Accelerations, after.Accelerations, by),
+	}
+}
+
+// NewTrajectory returns a trajectory through the given joint space waypoints, such as those returned by PlanFrameMotion, which is
+// as fast as the given limits on each joint allow. The waypoints are joined by straight lines in joint space with circular blends
+// at the waypoints, so that the trajectory only comes to a stop at its end and at waypoints where it turns back on itself. The
+// fastest speed along this path is found by time-optimal path parameterization, and if jerk is limited the joint positions are
+// then smoothed with a moving average, which rounds the corners of the path a little further.
+// references: Kunz & Stilman, Time-Optimal Trajectory Generation for Path Following with Bounded Acceleration and Velocity
+//
+//	Biagiotti & Melchiorri, FIR filters for online trajectory planning with time- and frequency-domain specifications
+func NewTrajectory(waypoints [][]frame.Input, limits []JointLimit, opts TrajectoryOptions) (Trajectory, error) {
+	if len(waypoints) == 0 {
+		return nil, errors.New("cannot generate a trajectory without waypoints")
+	}
+	for i, limit := range limits {
+		if limit.MaxVelocity <= 0 || limit.MaxAcceleration <= 0 || limit.MaxJerk < 0 {
+			return nil, errors.Errorf("joint %d has invalid limits %+v, velocity and acceleration limits must be positive", i, limit)
+		}
+	}
+	points := make([][]float64, 0, len(waypoints))
+	for i, waypoint := range waypoints {
+		if len(waypoint) != len(limits) {
+			return nil, errors.Errorf("waypoint %d has %d inputs but limits were given for %d", i, len(waypoint), len(limits))
+		}
+		point := frame.InputsToFloats(waypoint)
+		// repeated waypoints would leave the path with lines of no length and no direction
+		if len(points) == 0 || floats.Distance(point, points[len(points)-1], 2) > trajectoryEpsilon {
+			points = append(points, point)
+		}
+	}
+	if opts.SamplePeriod <= 0 {
+		opts.SamplePeriod = defaultTrajectorySamplePeriod
+	}
+	if opts.MaxDeviation <= 0 {
+		opts.MaxDeviation = defaultTrajectoryMaxDeviation
+	}
+
+	var positions [][]float64
+	if len(points) == 1 {
+		positions = points
+	} else {
+		path := newTrajectoryPath(points, opts.MaxDeviation)
+		// a sampled trajectory can exceed the limits a little, in which case it is generated again with proportionally tighter
+		// limits, which is the same as slowing it down
+		scale := 1.
+		for attempt := 0; ; attempt++ {
+			if attempt == trajectoryMaxRetries {
+				return nil, errors.New("cannot generate a trajectory that stays within the joint limits")
+			}
+			scaled := make([]JointLimit, len(limits))
+			for i, limit := range limits {
+				scaled[i] = JointLimit{
+					MaxVelocity:     limit.MaxVelocity / scale,
+					MaxAcceleration: limit.MaxAcceleration / (scale * scale),
+					MaxJerk:         limit.MaxJerk / (scale * scale * scale),
+				}
+			}
+			var err error
+			positions, err = path.sample(scaled, opts.SamplePeriod)
+			if err != nil {
+				return nil, err
+			}
+			ratio := limitRatio(positions, limits, opts.SamplePeriod)
+			if ratio <= 1+trajectoryLimitTolerance {
+				break
+			}
+			scale *= ratio
+		}
+	}
+
+	period := opts.SamplePeriod.Seconds()
+	trajectory := make(Trajectory, len(positions))
+	for k, position := range positions {
+		before, after := positions[clampIndex(k-1, len(positions))], positions[clampIndex(k+1, len(positions))]
+		velocities := make([]float64, len(position))
+		accelerations := make([]float64, len(position))
+		for j := range position {
+			// the trajectory starts and ends at rest, which the differences at its ends would not quite show
+			if k > 0 && k < len(positions)-1 {
+				velocities[j] = (after[j] - before[j]) / (2 * period)
+			}
+			accelerations[j] = (after[j] - 2*position[j] + before[j]) / (period * period)
+		}
+		trajectory[k] = TrajectoryPoint{
+			Time:          time.Duration(k) * opts.SamplePeriod,
+			Inputs:        frame.FloatsToInputs(position),
+			Velocities:    velocities,
+			Accelerations: accelerations,
+		}
+	}
+	return trajectory, nil
+}
+
+// NewPlanTrajectories returns trajectories which move each component that limits are given for through the steps of a plan, such as
+// one returned by PlanMotion. The trajectories are generated together, so that all the components start and arrive at the same
+// time and stay synchronized in between. Components of the plan that no limits are given for are left out.
+func NewPlanTrajectories(
+	plan []map[string][]frame.Input,
+	limits map[string][]JointLimit,
+	opts TrajectoryOptions,
+) (map[string]Trajectory, error) {
+	names := make([]string, 0, len(limits))
+	for name := range limits {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var allLimits []JointLimit
+	for _, name := range names {
+		allLimits = append(allLimits, limits[name]...)
+	}
+	waypoints := make([][]frame.Input, 0, len(plan))
+	for i, step := range plan {
+		var waypoint []frame.Input
+		for _, name := range names {
+			inputs := step[name]
+			if len(inputs) != len(limits[name]) {
+				return nil, errors.Errorf("step %d of plan has %d inputs for %q but limits were given for %d", i, len(inputs), name, len(limits[name]))
+			}
+			waypoint = append(waypoint, inputs...)
+		}
+		waypoints = append(waypoints, waypoint)
+	}
+	trajectory, err := NewTrajectory(waypoints, allLimits, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	trajectories := make(map[string]Trajectory, len(names))
+	offset := 0
+	for _, name := range names {
+		dof := len(limits[name])
+		componentTrajectory := make(Trajectory, len(trajectory))
+		for i, point := range trajectory {
+			componentTrajectory[i] = TrajectoryPoint{
+				Time:          point.Time,
+				Inputs:        point.Inputs[offset : offset+dof],
+				Velocities:    point.Velocities[offset : offset+dof],
+				Accelerations: point.Accelerations[offset : offset+dof],
+			}
+		}
+		trajectories[name] = componentTrajectory
+		offset += dof
+	}
+	return trajectories, nil
+}
+
+// trajectorySegment is a piece of the path that a trajectory follows, parameterized by the distance travelled along it.
+type trajectorySegment interface {
+	length() float64
+	// position returns the point of the segment at the given distance along it, while tangent and curvature return the first and
+	// second derivatives of that point with respect to the distance.
+	position(s float64) []float64
+	tangent(s float64) []float64
+	curvature(s float64) []float64
+}
+
+// lineSegment is a straight line in joint space.
+type lineSegment struct {
+	start     []float64
+	direction []float64
+	distance  float64
+}
+
+func (l *lineSegment) length() float64 {
+	return l.distance
+}
+
+func (l *lineSegment) position(s float64) []float64 {
+	position := make([]float64, len(l.start))
+	for j := range position {
+		position[j] = l.start[j] + s*l.direction[j]
+	}
+	return position
+}
+
+func (l *lineSegment) tangent(s float64) []float64 {
+	return l.direction
+}
+
+func (l *lineSegment) curvature(s float64) []float64 {
+	return make([]float64, len(l.start))
+}
+
+// blendSegment is an arc of a circle in joint space that joins two lines, centered at center and spanning the plane of the unit
+// vectors x and y, which start at angle 0.
+type blendSegment struct {
+	center []float64
+	x      []float64
+	y      []float64
+	radius float64
+	angle  float64
+}
+
+func (b *blendSegment) length() float64 {
+	return b.radius * b.angle
+}
+
+func (b *blendSegment) position(s float64) []float64 {
+	angle := s / b.radius
+	position := make([]float64, len(b.center))
+	for j := range position {
+		position[j] = b.center[j] + b.radius*(b.x[j]*math.Cos(angle)+b.y[j]*math.Sin(angle))
+	}
+	return position
+}
+
+func (b *blendSegment) tangent(s float64) []float64 {
+	angle := s / b.radius
+	tangent := make([]float64, len(b.center))
+	for j := range tangent {
+		tangent[j] = b.y[j]*math.Cos(angle) - b.x[j]*math.Sin(angle)
+	}
+	return tangent
+}
+
+func (b *blendSegment) curvature(s float64) []float64 {
+	angle := s / b.radius
+	curvature := make([]float64, len(b.center))
+	for j := range curvature {
+		curvature[j] = -(b.x[j]*math.Cos(angle) + b.y[j]*math.Sin(angle)) / b.radius
+	}
+	return curvature
+}
+
+// trajectoryPath is a path through waypoints made of segments placed end to end.
+type trajectoryPath struct {
+	segments []trajectorySegment
+	// offsets holds the distance along the path at which each segment starts
+	offsets []float64
+}
+
+// newTrajectoryPath returns a path that joins the given waypoints with straight lines and replaces each corner with a circular
+// blend that passes within maxDeviation of the waypoint. Blends take up at most half of the lines next to them, and corners where
+// the path turns straight back are left sharp.
+func newTrajectoryPath(waypoints [][]float64, maxDeviation float64) *trajectoryPath {
+	path := &trajectoryPath{}
+	addSegment := func(segment trajectorySegment) {
+		if segment.length() > trajectoryEpsilon {
+			var offset float64
+			if len(path.segments) > 0 {
+				last := len(path.segments) - 1
+				offset = path.offsets[last] + path.segments[last].length()
+			}
+			path.segments = append(path.segments, segment)
+			path.offsets = append(path.offsets, offset)
+		}
+	}
+
+	start := waypoints[0]
+	for i := 1; i < len(waypoints); i++ {
+		in := floatsSub(waypoints[i], waypoints[i-1])
+		inLength := floats.Norm(in, 2)
+		inDirection := floatsScale(in, 1/inLength)
+		end := waypoints[i]
+		var blend *blendSegment
+		if i < len(waypoints)-1 {
+			out := floatsSub(waypoints[i+1], waypoints[i])
+			outLength := floats.Norm(out, 2)
+			outDirection := floatsScale(out, 1/outLength)
+			angle := math.Acos(math.Max(-1, math.Min(1, floats.Dot(inDirection, outDirection))))
+			if angle > trajectoryEpsilon && angle < math.Pi-trajectoryEpsilon {
+				// distance from the waypoint along each line at which the blend starts and ends
+				distance := math.Min(math.Min(inLength, outLength)/2, maxDeviation*math.Sin(angle/2)/(1-math.Cos(angle/2)))
+				radius := distance / math.Tan(angle/2)
+				toCenter := floatsSub(outDirection, inDirection)
+				center := floatsAdd(waypoints[i], floatsScale(toCenter, radius/math.Cos(angle/2)/floats.Norm(toCenter, 2)))
+				end = floatsSub(waypoints[i], floatsScale(inDirection, distance))
+				x := floatsSub(end, center)
+				blend = &blendSegment{
+					center: center,
+					x:      floatsScale(x, 1/floats.Norm(x, 2)),
+					y:      inDirection,
+					radius: radius,
+					angle:  angle,
+				}
+			}
+		}
+		addSegment(&lineSegment{start: start, direction: inDirection, distance: floats.Distance(start, end, 2)})
+		start = waypoints[i]
+		if blend != nil {
+			addSegment(blend)
+			start = blend.position(blend.length())
+		}
+	}
+	return path
+}
+
+// length returns the length of the whole path.
+func (p *trajectoryPath) length() float64 {
+	last := len(p.segments) - 1
+	return p.offsets[last] + p.segments[last].length()
+}
+
+// position returns the point at the given distance along the path.
+func (p *trajectoryPath) position(s float64) []float64 {
+	i := sort.Search(len(p.offsets), func(i int) bool { return p.offsets[i] > s }) - 1
+	if i < 0 {
+		i = 0
+	}
+	return p.segments[i].position(math.Min(s-p.offsets[i], p.segments[i].length()))
+}
+
+// pathRow is the derivative of the position of a single joint with respect to the distance along a path, along with its limits.
+type pathRow struct {
+	tangent   float64
+	curvature float64
+	limit     JointLimit
+}
+
+// pathSample is a point along a path at which the fastest speed along it is solved for. Samples where segments meet hold the
+// rows of both of them.
+type pathSample struct {
+	s    float64
+	rows []pathRow
+	// stop is set where the direction of the path changes abruptly, which can only be passed through at rest
+	stop bool
+}
+
+// maxSquaredSpeed returns the square of the fastest speed along the path at which no joint exceeds its velocity limit and the path
+// can still be followed without any joint exceeding its acceleration limit.
+func (ps *pathSample) maxSquaredSpeed() float64 {
+	if ps.stop {
+		return 0
+	}
+	maximum := math.Inf(1)
+	for _, row := range ps.rows {
+		tangent := math.Abs(row.tangent)
+		if tangent <= trajectoryEpsilon {
+			if curvature := math.Abs(row.curvature); curvature > trajectoryEpsilon {
+				maximum = math.Min(maximum, row.limit.MaxAcceleration/curvature)
+			}
+			continue
+		}
+		maximum = math.Min(maximum, math.Pow(row.limit.MaxVelocity/tangent, 2))
+		// every pair of joints has to be able to agree on an acceleration along the path
+		for _, other := range ps.rows {
+			otherTangent := math.Abs(other.tangent)
+			if otherTangent <= trajectoryEpsilon {
+				continue
+			}
+			slope := -row.curvature/row.tangent + other.curvature/other.tangent
+			if slope > trajectoryEpsilon {
+				maximum = math.Min(maximum, (row.limit.MaxAcceleration/tangent+other.limit.MaxAcceleration/otherTangent)/slope)
+			}
+		}
+	}
+	return maximum
+}
+
+// accelerationBounds returns the range of accelerations along the path that keep every joint within its acceleration limit while
+// moving along the path at the speed whose square is given.
+func (ps *pathSample) accelerationBounds(squaredSpeed float64) (float64, float64) {
+	lower, upper := math.Inf(-1), math.Inf(1)
+	for _, row := range ps.rows {
+		tangent := math.Abs(row.tangent)
+		if tangent <= trajectoryEpsilon {
+			continue
+		}
+		bound := row.limit.MaxAcceleration / tangent
+		offset := -row.curvature * squaredSpeed / row.tangent
+		lower = math.Max(lower, offset-bound)
+		upper = math.Min(upper, offset+bound)
+	}
+	if lower > upper {
+		// the speed is just above what the limits allow, which happens because the path is solved for at discrete samples
+		middle := (lower + upper) / 2
+		return middle, middle
+	}
+	return lower, upper
+}
+
+// samples returns the points along the path at which the fastest speed along it is solved for.
+func (p *trajectoryPath) samples(limits []JointLimit) []*pathSample {
+	rows := func(segment trajectorySegment, s float64) []pathRow {
+		tangent := segment.tangent(s)
+		curvature := segment.curvature(s)
+		rows := make([]pathRow, len(limits))
+		for j, limit := range limits {
+			rows[j] = pathRow{tangent: tangent[j], curvature: curvature[j], limit: limit}
+		}
+		return rows
+	}
+
+	var samples []*pathSample
+	for i, segment := range p.segments {
+		steps := trajectoryLineSteps
+		if _, ok := segment.(*blendSegment); ok {
+			steps = trajectoryBlendSteps
+		}
+		for step := 0; step <= steps; step++ {
+			s := segment.length() * float64(step) / float64(steps)
+			if step == 0 && i > 0 {
+				// the end of the previous segment is the start of this one
+				previous := samples[len(samples)-1]
+				previousTangent := p.segments[i-1].tangent(p.segments[i-1].length())
+				if floats.Dot(previousTangent, segment.tangent(0)) < 1-trajectoryEpsilon {
+					previous.stop = true
+				}
+				previous.rows = append(previous.rows, rows(segment, 0)...)
+				continue
+			}
+			samples = append(samples, &pathSample{s: p.offsets[i] + s, rows: rows(segment, s)})
+		}
+	}
+	return samples
+}
+
+// sample returns the joint positions along the fastest trajectory through the path that stays within the given limits, every
+// period apart.
+func (p *trajectoryPath) sample(limits []JointLimit, period time.Duration) ([][]float64, error) {
+	samples := p.samples(limits)
+	n := len(samples)
+
+	// find the square of the fastest speed at each sample by accelerating as hard as possible forwards from the start and
+	// backwards from the end, without exceeding the fastest speed the limits allow at each sample
+	squaredSpeeds := make([]float64, n)
+	for i := 0; i < n-1; i++ {
+		ds := samples[i+1].s - samples[i].s
+		_, upper := samples[i].accelerationBounds(squaredSpeeds[i])
+		squaredSpeeds[i+1] = math.Min(samples[i+1].maxSquaredSpeed(), math.Max(0, squaredSpeeds[i]+2*ds*upper))
+	}
+	squaredSpeeds[n-1] = 0
+	for i := n - 2; i >= 0; i-- {
+		ds := samples[i+1].s - samples[i].s
+		lower, _ := samples[i+1].accelerationBounds(squaredSpeeds[i+1])
+		squaredSpeeds[i] = math.Min(squaredSpeeds[i], math.Max(0, squaredSpeeds[i+1]-2*ds*lower))
+	}
+
+	// the acceleration along the path is constant between samples, which gives the time at which each of them is reached
+	times := make([]float64, n)
+	for i := 0; i < n-1; i++ {
+		ds := samples[i+1].s - samples[i].s
+		speeds := math.Sqrt(squaredSpeeds[i]) + math.Sqrt(squaredSpeeds[i+1])
+		if speeds > trajectoryEpsilon {
+			times[i+1] = times[i] + 2*ds/speeds
+			continue
+		}
+		_, upper := samples[i].accelerationBounds(0)
+		if upper <= 0 {
+			return nil, errors.New("cannot generate a trajectory that leaves a point it stops at")
+		}
+		times[i+1] = times[i] + math.Sqrt(2*ds/upper)
+	}
+
+	seconds := period.Seconds()
+	count := int(math.Ceil(times[n-1]/seconds)) + 1
+	positions := make([][]float64, 0, count)
+	i := 0
+	for k := 0; k < count; k++ {
+		t := math.Min(float64(k)*seconds, times[n-1])
+		for i < n-2 && times[i+1] <= t {
+			i++
+		}
+		ds := samples[i+1].s - samples[i].s
+		dt := t - times[i]
+		acceleration := (squaredSpeeds[i+1] - squaredSpeeds[i]) / (2 * ds)
+		s := samples[i].s + math.Sqrt(squaredSpeeds[i])*dt + acceleration*dt*dt/2
+		positions = append(positions, p.position(math.Max(samples[i].s, math.Min(s, samples[i+1].s))))
+	}
+
+	// a moving average over a window of at least twice the time it takes to reach full acceleration at full jerk keeps the jerk
+	// of each joint within its limit, since it only ever averages velocities and accelerations that are already within their limits
+	var window float64
+	for _, limit := range limits {
+		if limit.MaxJerk > 0 {
+			window = math.Max(window, 2*limit.MaxAcceleration/limit.MaxJerk)
+		}
+	}
+	if width := int(math.Ceil(window / seconds)); width > 1 {
+		positions = movingAverage(positions, width)
+	}
+	return positions, nil
+}
+
+// movingAverage returns the average of each run of width consecutive positions, where the positions are held at rest before their
+// start and after their end, so that the result is width-1 positions longer.
+func movingAverage(positions [][]float64, width int) [][]float64 {
+	averaged := make([][]float64, len(positions)+width-1)
+	sum := floatsScale(positions[0], float64(width))
+	for k := range averaged {
+		sum = floatsAdd(sum, floatsSub(positions[clampIndex(k, len(positions))], positions[clampIndex(k-width, len(positions))]))
+		averaged[k] = floatsScale(sum, 1/float64(width))
+	}
+	return averaged
+}
+
+// limitRatio returns by how much the velocities, accelerations and jerks of joints moving through the given positions, every period
+// apart, exceed their limits, scaled to the amount the trajectory would need to be slowed down by to bring them within them.
+func limitRatio(positions [][]float64, limits []JointLimit, period time.Duration) float64 {
+	seconds := period.Seconds()
+	ratio := 0.
+	for k := range positions {
+		at := func(offset int) []float64 { return positions[clampIndex(k+offset, len(positions))] }
+		for j, limit := range limits {
+			velocity := (at(1)[j] - at(-1)[j]) / (2 * seconds)
+			acceleration := (at(1)[j] - 2*at(0)[j] + at(-1)[j]) / (seconds * seconds)
+			ratio = math.Max(ratio, math.Abs(velocity)/limit.MaxVelocity)
+			ratio = math.Max(ratio, math.Sqrt(math.Abs(acceleration)/limit.MaxAcceleration))
+			if limit.MaxJerk > 0 {
+				jerk := (at(2)[j] - 3*at(1)[j] + 3*at(0)[j] - at(-1)[j]) / (seconds * seconds * seconds)
+				ratio = math.Max(ratio, math.Cbrt(math.Abs(jerk)/limit.MaxJerk))
+			}
+		}
+	}
+	return ratio
+}
+
+// clampIndex returns the closest index to i of a slice of the given length.
+func clampIndex(i, length int) int {
+	if i < 0 {
+		return 0
+	}
+	if i >= length {
+		return length - 1
+	}
+	return i
+}
+
+func interpolateFloats(from, to []float64, by float64) []float64 {
+	interpolated := make([]float64, len(from))
+	for i := range from {
+		interpolated[i] = from[i] + (to[i]-from[i])*by
+	}
+	return interpolated
+}
+
+func floatsAdd(a, b []float64) []float64 {
+	sum := make([]float64, len(a))
+	for i := range a {
+		sum[i] = a[i] + b[i]
+	}
+	return sum
+}
+
+func floatsSub(a, b []float64) []float64 {
+	difference := make([]float64, len(a))
+	for i := range a {
+		difference[i] = a[i] - b[i]
+	}
+	return difference
+}
+
+func floatsScale(a []float64, by float64) []float64 {
+	scaled := make([]float64, len(a))
+	for i := range a {
+		scaled[i] = a[i] * by
+	}
+	return scaled
+}
@@ -0,0 +1,166 @@
+package motionplan
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"go.viam.com/test"
+
+	frame "go.viam.com/rdk/referenceframe"
+)
+
+// checkTrajectory checks that a trajectory starts and ends at rest at the given positions, and that it stays within the limits.
+func checkTrajectory(t *testing.T, trajectory Trajectory, start, end []float64, limits []JointLimit) {
+	t.Helper()
+	test.That(t, len(trajectory), test.ShouldBeGreaterThan, 1)
+	first, last := trajectory[0], trajectory[len(trajectory)-1]
+	for j := range limits {
+		test.That(t, first.Inputs[j].Value, test.ShouldAlmostEqual, start[j])
+		test.That(t, last.Inputs[j].Value, test.ShouldAlmostEqual, end[j])
+		test.That(t, first.Velocities[j], test.ShouldAlmostEqual, 0)
+		test.That(t, last.Velocities[j], test.ShouldAlmostEqual, 0)
+	}
+	ratio := limitRatio(trajectoryFloats(trajectory), limits, trajectory[1].Time)
+	test.That(t, ratio, test.ShouldBeLessThanOrEqualTo, 1+trajectoryLimitTolerance)
+	for i, point := range trajectory {
+		test.That(t, point.Time, test.ShouldEqual, time.Duration(i)*trajectory[1].Time)
+	}
+}
+
+func trajectoryFloats(trajectory Trajectory) [][]float64 {
+	positions := make([][]float64, 0, len(trajectory))
+	for _, point := range trajectory {
+		positions = append(positions, frame.InputsToFloats(point.Inputs))
+	}
+	return positions
+}
+
+func TestTrajectoryStraightLine(t *testing.T) {
+	limits := []JointLimit{{MaxVelocity: 1, MaxAcceleration: 2}, {MaxVelocity: 2, MaxAcceleration: 2}}
+	start := []float64{0, 0}
+	end := []float64{3, -1}
+	trajectory, err := NewTrajectory([][]frame.Input{frame.FloatsToInputs(start), frame.FloatsToInputs(end)}, limits, TrajectoryOptions{})
+	test.That(t, err, test.ShouldBeNil)
+	checkTrajectory(t, trajectory, start, end, limits)
+
+	// the first joint is the slowest to cover its distance, taking 3 seconds at full speed and half a second to speed up and slow
+	// down at full acceleration
+	test.That(t, trajectory.Duration().Seconds(), test.ShouldAlmostEqual, 3.5, 0.05)
+	middle := trajectory.At(trajectory.Duration() / 2)
+	test.That(t, middle.Velocities[0], test.ShouldAlmostEqual, 1, 0.01)
+	test.That(t, middle.Velocities[1], test.ShouldAlmostEqual, -1./3, 0.01)
+
+	// the joints stay on the straight line between the waypoints
+	for _, point := range trajectory {
+		test.That(t, point.Inputs[1].Value, test.ShouldAlmostEqual, -point.Inputs[0].Value/3)
+	}
+}
+
+func TestTrajectoryBlendsCorners(t *testing.T) {
+	limits := []JointLimit{{MaxVelocity: 1, MaxAcceleration: 1}, {MaxVelocity: 1, MaxAcceleration: 1}}
+	waypoints := [][]float64{{0, 0}, {1, 0}, {1, 1}}
+	inputs := make([][]frame.Input, 0, len(waypoints))
+	for _, waypoint := range waypoints {
+		inputs = append(inputs, frame.FloatsToInputs(waypoint))
+	}
+	trajectory, err := NewTrajectory(inputs, limits, TrajectoryOptions{MaxDeviation: 0.1})
+	test.That(t, err, test.ShouldBeNil)
+	checkTrajectory(t, trajectory, waypoints[0], waypoints[2], limits)
+
+	// the corner is cut within the allowed deviation without stopping
+	closest := math.Inf(1)
+	slowest := math.Inf(1)
+	for _, point := range trajectory[1 : len(trajectory)-1] {
+		position := frame.InputsToFloats(point.Inputs)
+		closest = math.Min(closest, math.Hypot(position[0]-1, position[1]))
+		slowest = math.Min(slowest, math.Hypot(point.Velocities[0], point.Velocities[1]))
+	}
+	test.That(t, closest, test.ShouldBeGreaterThan, 0.01)
+	test.That(t, closest, test.ShouldBeLessThanOrEqualTo, 0.1+1e-6)
+	test.That(t, slowest, test.ShouldBeGreaterThan, 0)
+
+	stopping, err := NewTrajectory(inputs, limits, TrajectoryOptions{MaxDeviation: 1e-6})
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, trajectory.Duration(), test.ShouldBeLessThan, stopping.Duration())
+}
+
+func TestTrajectoryStopsAtReversal(t *testing.T) {
+	limits := []JointLimit{{MaxVelocity: 1, MaxAcceleration: 1}}
+	inputs := [][]frame.Input{{{0}}, {{1}}, {{1}}, {{0.5}}}
+	trajectory, err := NewTrajectory(inputs, limits, TrajectoryOptions{})
+	test.That(t, err, test.ShouldBeNil)
+	checkTrajectory(t, trajectory, []float64{0}, []float64{0.5}, limits)
+
+	furthest := 0.
+	for _, point := range trajectory {
+		furthest = math.Max(furthest, point.Inputs[0].Value)
+	}
+	test.That(t, furthest, test.ShouldAlmostEqual, 1, 1e-3)
+}
+
+func TestTrajectoryJerkLimits(t *testing.T) {
+	limits := []JointLimit{{MaxVelocity: 1, MaxAcceleration: 2, MaxJerk: 10}, {MaxVelocity: 1, MaxAcceleration: 2, MaxJerk: 10}}
+	inputs := [][]frame.Input{{{0}, {0}}, {{1}, {0.5}}, {{0}, {1}}}
+	trajectory, err := NewTrajectory(inputs, limits, TrajectoryOptions{SamplePeriod: 5 * time.Millisecond})
+	test.That(t, err, test.ShouldBeNil)
+	checkTrajectory(t, trajectory, []float64{0, 0}, []float64{0, 1}, limits)
+
+	unlimited, err := NewTrajectory(inputs, []JointLimit{{MaxVelocity: 1, MaxAcceleration: 2}, {MaxVelocity: 1, MaxAcceleration: 2}},
+		TrajectoryOptions{SamplePeriod: 5 * time.Millisecond})
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, limitRatio(trajectoryFloats(unlimited), limits, 5*time.Millisecond), test.ShouldBeGreaterThan, 1)
+	test.That(t, trajectory.Duration(), test.ShouldBeGreaterThan, unlimited.Duration())
+}
+
+func TestTrajectoryAt(t *testing.T) {
+	limits := []JointLimit{{MaxVelocity: 1, MaxAcceleration: 1}}
+	trajectory, err := NewTrajectory([][]frame.Input{{{0}}, {{1}}}, limits, TrajectoryOptions{})
+	test.That(t, err, test.ShouldBeNil)
+
+	test.That(t, trajectory.At(-time.Second), test.ShouldResemble, trajectory[0])
+	test.That(t, trajectory.At(trajectory.Duration()+time.Second), test.ShouldResemble, trajectory[len(trajectory)-1])
+	point := trajectory.At(trajectory[3].Time + 5*time.Millisecond)
+	test.That(t, point.Inputs[0].Value, test.ShouldAlmostEqual, (trajectory[3].Inputs[0].Value+trajectory[4].Inputs[0].Value)/2)
+}
+
+func TestTrajectoryErrors(t *testing.T) {
+	limits := []JointLimit{{MaxVelocity: 1, MaxAcceleration: 1}}
+	_, err := NewTrajectory(nil, limits, TrajectoryOptions{})
+	test.That(t, err, test.ShouldNotBeNil)
+	_, err = NewTrajectory([][]frame.Input{{{0}, {1}}}, limits, TrajectoryOptions{})
+	test.That(t, err, test.ShouldNotBeNil)
+	_, err = NewTrajectory([][]frame.Input{{{0}}, {{1}}}, []JointLimit{{MaxVelocity: 1}}, TrajectoryOptions{})
+	test.That(t, err, test.ShouldNotBeNil)
+
+	// a single position gives a trajectory that stays there
+	trajectory, err := NewTrajectory([][]frame.Input{{{1}}, {{1}}}, limits, TrajectoryOptions{})
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, len(trajectory), test.ShouldEqual, 1)
+	test.That(t, trajectory.Duration(), test.ShouldEqual, 0)
+}
+
+func TestPlanTrajectories(t *testing.T) {
+	plan := []map[string][]frame.Input{
+		{"gantry": {{0}}, "arm": {{0}, {0}}, "camera": {}},
+		{"gantry": {{100}}, "arm": {{1}, {-1}}, "camera": {}},
+	}
+	limits := map[string][]JointLimit{
+		"gantry": {{MaxVelocity: 50, MaxAcceleration: 100}},
+		"arm":    {{MaxVelocity: 1, MaxAcceleration: 1}, {MaxVelocity: 1, MaxAcceleration: 1}},
+	}
+	trajectories, err := NewPlanTrajectories(plan, limits, TrajectoryOptions{})
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, len(trajectories), test.ShouldEqual, 2)
+	gantry, arm := trajectories["gantry"], trajectories["arm"]
+	test.That(t, len(gantry), test.ShouldEqual, len(arm))
+	checkTrajectory(t, gantry, []float64{0}, []float64{100}, limits["gantry"])
+	checkTrajectory(t, arm, []float64{0, 0}, []float64{1, -1}, limits["arm"])
+	for i := range gantry {
+		test.That(t, gantry[i].Time, test.ShouldEqual, arm[i].Time)
+		test.That(t, arm[i].Inputs[0].Value, test.ShouldAlmostEqual, gantry[i].Inputs[0].Value/100)
+	}
+
+	_, err = NewPlanTrajectories(plan, map[string][]JointLimit{"camera": {{MaxVelocity: 1, MaxAcceleration: 1}}}, TrajectoryOptions{})
+	test.That(t, err, test.ShouldNotBeNil)
+}
@@ -0,0 +1,598 @@
+package referenceframe
+
+import (
+	"encoding/xml"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/golang/geo/r3"
+	"github.com/pkg/errors"
+
+	spatial "go.viam.com/rdk/spatialmath"
+)
+
+// The URDF joint types that can be parsed.
+const (
+	urdfRevolute   = "revolute"
+	urdfContinuous = "continuous"
+	urdfPrismatic  = "prismatic"
+	urdfFixed      = "fixed"
+)
+
+// urdfOriginSuffix names the frames that hold the origin of movable URDF joints, which is applied before their motion.
+const urdfOriginSuffix = "_origin"
+
+// urdfRobot is the root element of a URDF file. Lengths are in meters and angles in radians.
+type urdfRobot struct {
+	XMLName xml.Name    `xml:"robot"`
+	Name    string      `xml:"name,attr"`
+	Links   []urdfLink  `xml:"link"`
+	Joints  []urdfJoint `xml:"joint"`
+}
+
+type urdfLink struct {
+	Name       string          `xml:"name,attr"`
+	Collisions []urdfCollision `xml:"collision"`
+}
+
+type urdfCollision struct {
+	Origin   *urdfOrigin  `xml:"origin"`
+	Geometry urdfGeometry `xml:"geometry"`
+}
+
+type urdfOrigin struct {
+	XYZ string `xml:"xyz,attr,omitempty"`
+	RPY string `xml:"rpy,attr,omitempty"`
+}
+
+type urdfGeometry struct {
+	Box      *urdfBox      `xml:"box"`
+	Sphere   *urdfSphere   `xml:"sphere"`
+	Cylinder *urdfCylinder `xml:"cylinder"`
+	Mesh     *urdfMesh     `xml:"mesh"`
+}
+
+type urdfBox struct {
+	Size string `xml:"size,attr"`
+}
+
+type urdfSphere struct {
+	Radius float64 `xml:"radius,attr"`
+}
+
+type urdfCylinder struct {
+	Radius float64 `xml:"radius,attr"`
+	Length float64 `xml:"length,attr"`
+}
+
+type urdfMesh struct {
+	Filename string `xml:"filename,attr"`
+	Scale    string `xml:"scale,attr,omitempty"`
+}
+
+type urdfJoint struct {
+	Name   string      `xml:"name,attr"`
+	Type   string      `xml:"type,attr"`
+	Origin *urdfOrigin `xml:"origin"`
+	Parent urdfLinkRef `xml:"parent"`
+	Child  urdfLinkRef `xml:"child"`
+	Axis   *urdfAxis   `xml:"axis"`
+	Limit  *urdfLimit  `xml:"limit"`
+}
+
+type urdfLinkRef struct {
+	Link string `xml:"link,attr"`
+}
+
+type urdfAxis struct {
+	XYZ string `xml:"xyz,attr"`
+}
+
+type urdfLimit struct {
+	Lower    float64 `xml:"lower,attr"`
+	Upper    float64 `xml:"upper,attr"`
+	Effort   float64 `xml:"effort,attr"`
+	Velocity float64 `xml:"velocity,attr"`
+}
+
+// ParseModelURDFFile will read a given file and then parse the contained URDF data.
+func ParseModelURDFFile(filename, modelName string) (Model, error) {
+	//nolint:gosec
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read urdf file")
+	}
+	return unmarshalModelURDF(data, modelName, filepath.Dir(filename))
+}
+
+// ParseModelFile will read a given kinematics file, which is parsed as URDF if it ends in .urdf or .xml and as JSON
+// otherwise.
+func ParseModelFile(filename, modelName string) (Model, error) {
+	switch strings.ToLower(filename[strings.LastIndex(filename, ".")+1:]) {
+	case "urdf", "xml":
+		return ParseModelURDFFile(filename, modelName)
+	default:
+		return ParseModelJSONFile(filename, modelName)
+	}
+}
+
+// UnmarshalModelURDF will parse the given URDF data into a kinematics model. modelName sets the name of the model,
+// will use the name of the robot if string is empty.
+//
+// Models are serial chains, so the chain from the root link to the link with the most movable joints above it is
+// parsed, and any other branch may only hold fixed joints, which are ignored. A root link named world is the world
+// frame. Movable joints are preceded by a static frame named after the joint with an "_origin" suffix that holds their
+// origin, and links are static frames holding the first collision geometry of the link, each further collision
+// geometry being held by a static frame that follows it named after the link with a "_collision" suffix and its index.
+// Continuous joints are limited to a full turn either way. Mesh files are looked for relative to the working
+// directory, and "package://" URIs drop their package name; a mesh file that cannot be found is an error.
+func UnmarshalModelURDF(data []byte, modelName string) (Model, error) {
+	return unmarshalModelURDF(data, modelName, "")
+}
+
+// unmarshalModelURDF parses URDF data whose relative mesh files are found from dir, see UnmarshalModelURDF.
+func unmarshalModelURDF(data []byte, modelName, dir string) (Model, error) {
+	if len(data) == 0 {
+		return nil, ErrNoModelInformation
+	}
+	var robot urdfRobot
+	if err := xml.Unmarshal(data, &robot); err != nil {
+		return nil, errors.Wrap(err, "failed to unmarshal urdf")
+	}
+	if modelName == "" {
+		modelName = robot.Name
+	}
+
+	links := map[string]urdfLink{}
+	for _, link := range robot.Links {
+		for _, collision := range link.Collisions {
+			if collision.Geometry.Mesh != nil {
+				filename := resolveURDFMeshFile(collision.Geometry.Mesh.Filename, dir)
+				if filename == "" {
+					return nil, errors.Errorf("urdf link %q mesh file %q not found", link.Name, collision.Geometry.Mesh.Filename)
+				}
+				collision.Geometry.Mesh.Filename = filename
+			}
+		}
+		if _, ok := links[link.Name]; ok {
+			return nil, errors.Errorf("urdf link %q is defined more than once", link.Name)
+		}
+		links[link.Name] = link
+	}
+	parentJoints := map[string]urdfJoint{}
+	childJoints := map[string][]urdfJoint{}
+	for _, joint := range robot.Joints {
+		if _, ok := links[joint.Parent.Link]; !ok {
+			return nil, errors.Errorf("urdf joint %q has unknown parent link %q", joint.Name, joint.Parent.Link)
+		}
+		if _, ok := links[joint.Child.Link]; !ok {
+			return nil, errors.Errorf("urdf joint %q has unknown child link %q", joint.Name, joint.Child.Link)
+		}
+		if _, ok := parentJoints[joint.Child.Link]; ok {
+			return nil, errors.Errorf("urdf link %q is the child of more than one joint", joint.Child.Link)
+		}
+		if joint.Name == World {
+			return nil, errors.New("reserved word: cannot name a joint 'world'")
+		}
+		parentJoints[joint.Child.Link] = joint
+		childJoints[joint.Parent.Link] = append(childJoints[joint.Parent.Link], joint)
+	}
+
+	var roots []string
+	for _, link := range robot.Links {
+		if _, ok := parentJoints[link.Name]; !ok {
+			roots = append(roots, link.Name)
+		}
+	}
+	if len(roots) != 1 {
+		return nil, errors.Errorf("urdf must have exactly one root link, found %v", roots)
+	}
+
+	chain, err := urdfChain(roots[0], childJoints)
+	if err != nil {
+		return nil, err
+	}
+
+	model := NewSimpleModel(modelName)
+	if roots[0] != World {
+		frames, err := newURDFLinkFrames(links[roots[0]], spatial.NewZeroPose())
+		if err != nil {
+			return nil, err
+		}
+		model.OrdTransforms = append(model.OrdTransforms, frames...)
+	}
+	for _, joint := range chain {
+		frames, err := newURDFJointFrames(joint, links[joint.Child.Link])
+		if err != nil {
+			return nil, err
+		}
+		model.OrdTransforms = append(model.OrdTransforms, frames...)
+	}
+	if len(model.OrdTransforms) == 0 {
+		return nil, errors.New("urdf has no joints or links with geometry")
+	}
+	return model, nil
+}
+
+// urdfChain returns the joints from root to the link with the most movable joints above it, or the deepest such link
+// if there are several. Any other branch must only hold fixed joints.
+func urdfChain(root string, childJoints map[string][]urdfJoint) ([]urdfJoint, error) {
+	var best []urdfJoint
+	bestMovable := -1
+	reached := 0
+	var walk func(link string, path []urdfJoint, movable int)
+	walk = func(link string, path []urdfJoint, movable int) {
+		children := childJoints[link]
+		if len(children) == 0 && (movable > bestMovable || (movable == bestMovable && len(path) > len(best))) {
+			best = append([]urdfJoint{}, path...)
+			bestMovable = movable
+		}
+		for _, joint := range children {
+			reached++
+			m := movable
+			if joint.Type != urdfFixed {
+				m++
+			}
+			walk(joint.Child.Link, append(path, joint), m)
+		}
+	}
+	walk(root, nil, 0)
+
+	numJoints := 0
+	for _, children := range childJoints {
+		numJoints += len(children)
+	}
+	// Every link but the root has a parent joint, so joints that cannot be reached from the root form a loop.
+	if reached != numJoints {
+		return nil, errors.New("infinite loop finding path from root link")
+	}
+
+	onChain := map[string]bool{}
+	for _, joint := range best {
+		onChain[joint.Name] = true
+	}
+	for _, joints := range childJoints {
+		for _, joint := range joints {
+			if !onChain[joint.Name] && joint.Type != urdfFixed {
+				return nil, errors.Errorf("more than one end effector not supported, joint %q is not on the chain to %q",
+					joint.Name, best[len(best)-1].Child.Link)
+			}
+		}
+	}
+	return best, nil
+}
+
+// newURDFJointFrames returns the frames of joint followed by the frames of its child link.
+func newURDFJointFrames(joint urdfJoint, child urdfLink) ([]Frame, error) {
+	origin, err := joint.Origin.pose()
+	if err != nil {
+		return nil, errors.Wrapf(err, "urdf joint %q", joint.Name)
+	}
+	if joint.Type == urdfFixed {
+		return newURDFLinkFrames(child, origin)
+	}
+
+	var frames []Frame
+	if !spatial.PoseAlmostCoincident(origin, spatial.NewZeroPose()) {
+		originFrame, err := NewStaticFrame(joint.Name+urdfOriginSuffix, origin)
+		if err != nil {
+			return nil, err
+		}
+		frames = append(frames, originFrame)
+	}
+	axis := r3.Vector{X: 1}
+	if joint.Axis != nil {
+		if axis, err = parseURDFVector(joint.Axis.XYZ); err != nil {
+			return nil, errors.Wrapf(err, "urdf joint %q axis", joint.Name)
+		}
+	}
+	limit := Limit{Min: -2 * math.Pi, Max: 2 * math.Pi}
+	if joint.Limit != nil && joint.Type != urdfContinuous {
+		limit = Limit{Min: joint.Limit.Lower, Max: joint.Limit.Upper}
+	}
+
+	var jointFrame Frame
+	switch joint.Type {
+	case urdfRevolute, urdfContinuous:
+		jointFrame, err = NewRotationalFrame(joint.Name, spatial.R4AA{RX: axis.X, RY: axis.Y, RZ: axis.Z}, limit)
+	case urdfPrismatic:
+		geometryCreator, geomErr := newURDFGeometryCreator(child, spatial.NewZeroPose())
+		if geomErr != nil {
+			return nil, geomErr
+		}
+		limit = Limit{Min: 1000 * limit.Min, Max: 1000 * limit.Max}
+		jointFrame, err = NewTranslationalFrameWithGeometry(joint.Name, axis, limit, geometryCreator)
+		if err != nil {
+			return nil, err
+		}
+		// The first geometry of the child link is held by the joint.
+		collisionFrames, err := newURDFCollisionFrames(child)
+		if err != nil {
+			return nil, err
+		}
+		return append(append(frames, jointFrame), collisionFrames...), nil
+	default:
+		return nil, errors.Errorf("unsupported urdf joint type %q of joint %q", joint.Type, joint.Name)
+	}
+	if err != nil {
+		return nil, err
+	}
+	frames = append(frames, jointFrame)
+
+	linkFrames, err := newURDFLinkFrames(child, spatial.NewZeroPose())
+	if err != nil {
+		return nil, err
+	}
+	return append(frames, linkFrames...), nil
+}
+
+// newURDFLinkFrames returns a static frame named after link at pose holding the first geometry of link followed by the
+// frames holding its other geometries, or no frames if they would neither move nor hold anything.
+func newURDFLinkFrames(link urdfLink, pose spatial.Pose) ([]Frame, error) {
+	if link.Name == World {
+		return nil, errors.New("reserved word: cannot name a link 'world' unless it is the root link")
+	}
+	geometryCreator, err := newURDFGeometryCreator(link, pose)
+	if err != nil {
+		return nil, err
+	}
+	var frame Frame
+	switch {
+	case geometryCreator != nil:
+		frame, err = NewStaticFrameWithGeometry(link.Name, pose, geometryCreator)
+	case !spatial.PoseAlmostCoincident(pose, spatial.NewZeroPose()):
+		frame, err = NewStaticFrame(link.Name, pose)
+	default:
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	collisionFrames, err := newURDFCollisionFrames(link)
+	if err != nil {
+		return nil, err
+	}
+	return append([]Frame{frame}, collisionFrames...), nil
+}
+
+// newURDFCollisionFrames returns a static frame at the zero pose for each collision geometry of link after the first,
+// to follow the frame that holds the first.
+func newURDFCollisionFrames(link urdfLink) ([]Frame, error) {
+	var frames []Frame
+	for i := 1; i < len(link.Collisions); i++ {
+		geometryCreator, err := newURDFCollisionCreator(link, link.Collisions[i], spatial.NewZeroPose())
+		if err != nil {
+			return nil, err
+		}
+		if geometryCreator == nil {
+			continue
+		}
+		frame, err := NewStaticFrameWithGeometry(fmt.Sprintf("%s_collision%d", link.Name, i), spatial.NewZeroPose(), geometryCreator)
+		if err != nil {
+			return nil, err
+		}
+		frames = append(frames, frame)
+	}
+	return frames, nil
+}
+
+// newURDFGeometryCreator returns the first collision geometry of link in millimeters, or nil if it has none. The
+// geometry of a static frame is placed relative to its parent, so its collision origin follows linkPose, the pose of the
+// link in the parent of its frame.
+func newURDFGeometryCreator(link urdfLink, linkPose spatial.Pose) (spatial.GeometryCreator, error) {
+	if len(link.Collisions) == 0 {
+		return nil, nil
+	}
+	return newURDFCollisionCreator(link, link.Collisions[0], linkPose)
+}
+
+// newURDFCollisionCreator returns the geometry of a collision of link in millimeters, or nil if it has none, see
+// newURDFGeometryCreator.
+func newURDFCollisionCreator(link urdfLink, collision urdfCollision, linkPose spatial.Pose) (spatial.GeometryCreator, error) {
+	origin, err := collision.Origin.pose()
+	if err != nil {
+		return nil, errors.Wrapf(err, "urdf link %q collision", link.Name)
+	}
+	offset := spatial.Compose(linkPose, origin)
+	geometry := collision.Geometry
+	switch {
+	case geometry.Box != nil:
+		size, err := parseURDFVector(geometry.Box.Size)
+		if err != nil {
+			return nil, errors.Wrapf(err, "urdf link %q box", link.Name)
+		}
+		return spatial.NewBoxCreator(size.Mul(1000), offset, link.Name)
+	case geometry.Sphere != nil:
+		return spatial.NewSphereCreator(1000*geometry.Sphere.Radius, offset, link.Name)
+	case geometry.Cylinder != nil:
+		return spatial.NewCylinderCreator(1000*geometry.Cylinder.Radius, 1000*geometry.Cylinder.Length, offset, link.Name)
+	case geometry.Mesh != nil:
+		scale := r3.Vector{X: 1, Y: 1, Z: 1}
+		if geometry.Mesh.Scale != "" {
+			if scale, err = parseURDFVector(geometry.Mesh.Scale); err != nil {
+				return nil, errors.Wrapf(err, "urdf link %q mesh", link.Name)
+			}
+		}
+		if scale.X == scale.Y && scale.Y == scale.Z {
+			gc, err := spatial.NewMeshCreatorFromFile(geometry.Mesh.Filename, 1000*scale.X, offset, link.Name)
+			return gc, errors.Wrapf(err, "urdf link %q mesh", link.Name)
+		}
+		triangles, err := spatial.ReadMeshFile(geometry.Mesh.Filename)
+		if err != nil {
+			return nil, errors.Wrapf(err, "urdf link %q mesh", link.Name)
+		}
+		for i := range triangles {
+			for j, vertex := range triangles[i] {
+				triangles[i][j] = r3.Vector{X: vertex.X * scale.X, Y: vertex.Y * scale.Y, Z: vertex.Z * scale.Z}.Mul(1000)
+			}
+		}
+		return spatial.NewMeshCreator(triangles, offset, link.Name)
+	default:
+		return nil, nil
+	}
+}
+
+// resolveURDFMeshFile returns the path of the mesh file a URDF refers to by filename, or an empty string if it cannot be
+// found. Relative paths are found from dir, and "package://" URIs drop their package name and are looked for in dir and
+// its parent, which is where the package usually sits.
+func resolveURDFMeshFile(filename, dir string) string {
+	var candidates []string
+	switch {
+	case strings.HasPrefix(filename, "package://"):
+		parts := strings.SplitN(strings.TrimPrefix(filename, "package://"), "/", 2)
+		if len(parts) != 2 {
+			return ""
+		}
+		candidates = []string{filepath.Join(dir, parts[1]), filepath.Join(dir, "..", parts[1])}
+	case filepath.IsAbs(strings.TrimPrefix(filename, "file://")):
+		candidates = []string{strings.TrimPrefix(filename, "file://")}
+	default:
+		candidates = []string{filepath.Join(dir, strings.TrimPrefix(filename, "file://"))}
+	}
+	for _, candidate := range candidates {
+		if _, err := os.Stat(candidate); err == nil {
+			return candidate
+		}
+	}
+	return ""
+}
+
+// pose returns the pose of the origin in millimeters, which is the zero pose if it is missing.
+func (o *urdfOrigin) pose() (spatial.Pose, error) {
+	if o == nil {
+		return spatial.NewZeroPose(), nil
+	}
+	var xyz, rpy r3.Vector
+	var err error
+	if o.XYZ != "" {
+		if xyz, err = parseURDFVector(o.XYZ); err != nil {
+			return nil, errors.Wrap(err, "origin xyz")
+		}
+	}
+	if o.RPY != "" {
+		if rpy, err = parseURDFVector(o.RPY); err != nil {
+			return nil, errors.Wrap(err, "origin rpy")
+		}
+	}
+	return spatial.NewPoseFromOrientation(xyz.Mul(1000), &spatial.EulerAngles{Roll: rpy.X, Pitch: rpy.Y, Yaw: rpy.Z}), nil
+}
+
+func newURDFOrigin(pose spatial.Pose) *urdfOrigin {
+	ea := pose.Orientation().EulerAngles()
+	return &urdfOrigin{
+		XYZ: formatURDFVector(pose.Point().Mul(0.001)),
+		RPY: formatURDFVector(r3.Vector{X: ea.Roll, Y: ea.Pitch, Z: ea.Yaw}),
+	}
+}
+
+func parseURDFVector(s string) (r3.Vector, error) {
+	fields := strings.Fields(s)
+	if len(fields) != 3 {
+		return r3.Vector{}, errors.Errorf("expected 3 values, got %q", s)
+	}
+	var v [3]float64
+	for i, field := range fields {
+		f, err := strconv.ParseFloat(field, 64)
+		if err != nil {
+			return r3.Vector{}, err
+		}
+		v[i] = f
+	}
+	return r3.Vector{X: v[0], Y: v[1], Z: v[2]}, nil
+}
+
+func formatURDFVector(v r3.Vector) string {
+	format := func(f float64) string {
+		return strconv.FormatFloat(f, 'g', -1, 64)
+	}
+	return fmt.Sprintf("%s %s %s", format(v.X), format(v.Y), format(v.Z))
+}
+
+// MarshalURDF returns the model as URDF. Every frame becomes a link named after it, joined to the link of the frame before
+// it by a joint: a fixed joint named after the frame with a "_joint" suffix for static frames, and a revolute or
+// prismatic joint named after the frame for rotational and translational frames. The root link is world.
+func (m *SimpleModel) MarshalURDF() ([]byte, error) {
+	robot := urdfRobot{Name: m.name, Links: []urdfLink{{Name: World}}}
+	parent := World
+	for _, f := range m.OrdTransforms {
+		var link urdfLink
+		var joint urdfJoint
+		var geometryCreator spatial.GeometryCreator
+		linkPose := spatial.NewZeroPose()
+		switch frame := f.(type) {
+		case *staticFrame:
+			link = urdfLink{Name: frame.name}
+			joint = urdfJoint{Name: frame.name + "_joint", Type: urdfFixed, Origin: newURDFOrigin(frame.transform)}
+			geometryCreator = frame.geometryCreator
+			linkPose = frame.transform
+		case *rotationalFrame:
+			link = urdfLink{Name: frame.name}
+			joint = urdfJoint{
+				Name:  frame.name,
+				Type:  urdfRevolute,
+				Axis:  &urdfAxis{XYZ: formatURDFVector(frame.rotAxis)},
+				Limit: &urdfLimit{Lower: frame.limits[0].Min, Upper: frame.limits[0].Max},
+			}
+		case *translationalFrame:
+			link = urdfLink{Name: frame.name}
+			joint = urdfJoint{
+				Name:  frame.name,
+				Type:  urdfPrismatic,
+				Axis:  &urdfAxis{XYZ: formatURDFVector(frame.transAxis)},
+				Limit: &urdfLimit{Lower: frame.limits[0].Min / 1000, Upper: frame.limits[0].Max / 1000},
+			}
+			geometryCreator = frame.geometryCreator
+		default:
+			return nil, errors.Errorf("cannot marshal frame %q of type %T as urdf", f.Name(), f)
+		}
+		if geometryCreator != nil {
+			collision, err := newURDFCollision(geometryCreator, linkPose)
+			if err != nil {
+				return nil, errors.Wrapf(err, "frame %q", f.Name())
+			}
+			link.Collisions = []urdfCollision{*collision}
+		}
+		joint.Parent = urdfLinkRef{Link: parent}
+		joint.Child = urdfLinkRef{Link: link.Name}
+		robot.Links = append(robot.Links, link)
+		robot.Joints = append(robot.Joints, joint)
+		parent = link.Name
+	}
+	data, err := xml.MarshalIndent(robot, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte(xml.Header), data...), nil
+}
+
+// newURDFCollision returns the collision element of a link at linkPose, the inverse of newURDFGeometryCreator.
+func newURDFCollision(geometryCreator spatial.GeometryCreator, linkPose spatial.Pose) (*urdfCollision, error) {
+	config, err := spatial.NewGeometryConfig(geometryCreator)
+	if err != nil {
+		return nil, err
+	}
+	collision := &urdfCollision{Origin: newURDFOrigin(spatial.Compose(spatial.PoseInverse(linkPose), geometryCreator.Offset()))}
+	switch config.Type {
+	case spatial.BoxType:
+		collision.Geometry.Box = &urdfBox{Size: formatURDFVector(r3.Vector{X: config.X, Y: config.Y, Z: config.Z}.Mul(0.001))}
+	case spatial.SphereType:
+		collision.Geometry.Sphere = &urdfSphere{Radius: config.R / 1000}
+	case spatial.CylinderType, spatial.CapsuleType:
+		// URDF has no capsules, so they are written as the cylinders that bound them
+		collision.Geometry.Cylinder = &urdfCylinder{Radius: config.R / 1000, Length: config.L / 1000}
+	case spatial.MeshType:
+		filename, err := filepath.Abs(config.MeshPath)
+		if err != nil {
+			return nil, err
+		}
+		scale := config.MeshScale / 1000
+		collision.Geometry.Mesh = &urdfMesh{Filename: filename, Scale: formatURDFVector(r3.Vector{X: scale, Y: scale, Z: scale})}
+	default:
+		return nil, errors.Errorf("cannot marshal geometry of type %q as urdf", config.Type)
+	}
+	return collision, nil
+}
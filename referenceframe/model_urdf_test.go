@@ -0,0 +1,174 @@
+package referenceframe
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+
+	"github.com/golang/geo/r3"
+	"go.viam.com/test"
+
+	spatial "go.viam.com/rdk/spatialmath"
+	"go.viam.com/rdk/utils"
+)
+
+func TestParseURDFFile(t *testing.T) {
+	model, err := ParseModelFile(utils.ResolveFile("referenceframe/testurdf/arm.urdf"), "")
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, model.Name(), test.ShouldEqual, "test_arm")
+	test.That(t, model.DoF(), test.ShouldResemble, []Limit{
+		{Min: -3.14, Max: 3.14},
+		{Min: -2 * math.Pi, Max: 2 * math.Pi},
+		{Min: 0, Max: 200},
+	})
+
+	// The camera branch only holds fixed joints and is left out, as are frames that neither move nor hold geometry.
+	var names []string
+	for _, frame := range model.(*SimpleModel).OrdTransforms {
+		names = append(names, frame.Name())
+	}
+	test.That(t, names, test.ShouldResemble, []string{
+		"base_link", "shoulder_origin", "shoulder", "upper_arm", "elbow_origin", "elbow", "forearm", "slide_origin", "slide",
+	})
+
+	// The elbow points the forearm down, so the slide moves along -z.
+	pose, err := model.Transform(FloatsToInputs([]float64{math.Pi / 2, 0, 50}))
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, spatial.R3VectorAlmostEqual(pose.Point(), r3.Vector{Z: 550}, 1e-6), test.ShouldBeTrue)
+	pose, err = model.Transform(FloatsToInputs([]float64{0, -math.Pi / 2, 0}))
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, spatial.R3VectorAlmostEqual(pose.Point(), r3.Vector{X: 100, Z: 700}, 1e-6), test.ShouldBeTrue)
+
+	// Frames without geometry return errors alongside the geometries of the others.
+	geometries, _ := model.Geometries(make([]Input, 3))
+	test.That(t, geometries, test.ShouldNotBeNil)
+	test.That(t, geometries.Geometries(), test.ShouldHaveLength, 4)
+	base := geometries.Geometries()["test_arm:base_link"]
+	test.That(t, base, test.ShouldNotBeNil)
+	test.That(t, spatial.R3VectorAlmostEqual(base.Pose().Point(), r3.Vector{Z: 50}, 1e-6), test.ShouldBeTrue)
+
+	// The upper arm is a cylinder, so a point just past its radius but inside the box bounding it is clear of it.
+	upperArm := geometries.Geometries()["test_arm:upper_arm"]
+	test.That(t, spatial.R3VectorAlmostEqual(upperArm.Pose().Point(), r3.Vector{Z: 500}, 1e-6), test.ShouldBeTrue)
+	collides, err := upperArm.CollidesWith(spatial.NewPoint(r3.Vector{X: 40, Y: 40, Z: 500}, ""))
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, collides, test.ShouldBeFalse)
+
+	// The tool is read from meshes/tool.stl next to the urdf file, and scaled from millimeters to meters and back.
+	tool := geometries.Geometries()["test_arm:slide"]
+	test.That(t, tool.Vertices(), test.ShouldHaveLength, 8)
+	inside := spatial.Compose(tool.Pose(), spatial.NewPoseFromPoint(r3.Vector{X: 20})).Point()
+	collides, err = tool.CollidesWith(spatial.NewPoint(inside, ""))
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, collides, test.ShouldBeTrue)
+	outside := spatial.Compose(tool.Pose(), spatial.NewPoseFromPoint(r3.Vector{X: 60})).Point()
+	collides, err = tool.CollidesWith(spatial.NewPoint(outside, ""))
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, collides, test.ShouldBeFalse)
+}
+
+func TestURDFRoundTrip(t *testing.T) {
+	files := []string{
+		"referenceframe/testurdf/arm.urdf",
+		"components/arm/trossen/trossen_wx250s_kinematics.json",
+		"components/arm/universalrobots/ur5e_DH.json",
+		"referenceframe/testjson/varm.json",
+	}
+	for _, f := range files {
+		t.Run(f, func(t *testing.T) {
+			model, err := ParseModelFile(utils.ResolveFile(f), "")
+			test.That(t, err, test.ShouldBeNil)
+
+			data, err := model.(*SimpleModel).MarshalURDF()
+			test.That(t, err, test.ShouldBeNil)
+			model2, err := UnmarshalModelURDF(data, "")
+			test.That(t, err, test.ShouldBeNil)
+			test.That(t, model2.Name(), test.ShouldEqual, model.Name())
+			test.That(t, limitsAlmostEqual(model.DoF(), model2.DoF()), test.ShouldBeTrue)
+
+			//nolint:gosec
+			randSeed := rand.New(rand.NewSource(1))
+			for i := 0; i < 10; i++ {
+				inputs := RandomFrameInputs(model, randSeed)
+				pose, err := model.Transform(inputs)
+				test.That(t, err, test.ShouldBeNil)
+				pose2, err := model2.Transform(inputs)
+				test.That(t, err, test.ShouldBeNil)
+				test.That(t, spatial.PoseAlmostCoincidentEps(pose, pose2, 1e-6), test.ShouldBeTrue)
+			}
+
+			geometries, _ := model.Geometries(make([]Input, len(model.DoF())))
+			geometries2, _ := model2.Geometries(make([]Input, len(model.DoF())))
+			if geometries == nil {
+				test.That(t, geometries2, test.ShouldBeNil)
+				return
+			}
+			test.That(t, geometries2.Geometries(), test.ShouldHaveLength, len(geometries.Geometries()))
+			for name, g := range geometries.Geometries() {
+				test.That(t, spatial.PoseAlmostCoincidentEps(g.Pose(), geometries2.Geometries()[name].Pose(), 1e-6), test.ShouldBeTrue)
+			}
+		})
+	}
+}
+
+func TestBadURDF(t *testing.T) {
+	for name, data := range map[string]string{
+		"two roots": `<robot name="r"><link name="a"/><link name="b"/></robot>`,
+		"unknown link": `<robot name="r"><link name="a"/>
+			<joint name="j" type="fixed"><parent link="a"/><child link="b"/></joint></robot>`,
+		"unsupported joint": `<robot name="r"><link name="a"/><link name="b"/>
+			<joint name="j" type="floating"><parent link="a"/><child link="b"/></joint></robot>`,
+		"two end effectors": `<robot name="r"><link name="a"/><link name="b"/><link name="c"/>
+			<joint name="j1" type="revolute"><parent link="a"/><child link="b"/></joint>
+			<joint name="j2" type="revolute"><parent link="a"/><child link="c"/></joint></robot>`,
+		"bad origin": `<robot name="r"><link name="a"/><link name="b"/>
+			<joint name="j" type="revolute"><parent link="a"/><child link="b"/><origin xyz="1 2"/></joint></robot>`,
+		"not xml": `{"name": "r"}`,
+		"missing mesh": `<robot name="r"><link name="a"><collision><geometry><mesh filename="missing.stl"/></geometry></collision></link>
+			</robot>`,
+	} {
+		t.Run(name, func(t *testing.T) {
+			_, err := UnmarshalModelURDF([]byte(data), "")
+			test.That(t, err, test.ShouldNotBeNil)
+		})
+	}
+	_, err := UnmarshalModelURDF(nil, "")
+	test.That(t, err, test.ShouldBeError, ErrNoModelInformation)
+}
+
+func TestURDFMissingMesh(t *testing.T) {
+	data := `<robot name="r"><link name="a"/><link name="b">
+		<collision><geometry><mesh filename="package://r/meshes/missing.stl"/></geometry></collision></link>
+		<joint name="j" type="revolute"><parent link="a"/><child link="b"/></joint></robot>`
+	_, err := UnmarshalModelURDF([]byte(data), "")
+	test.That(t, err, test.ShouldNotBeNil)
+	test.That(t, err.Error(), test.ShouldContainSubstring, `"b"`)
+	test.That(t, err.Error(), test.ShouldContainSubstring, "package://r/meshes/missing.stl")
+}
+
+func TestURDFCollisions(t *testing.T) {
+	data := `<robot name="r"><link name="a"/><link name="b">
+		<collision><origin xyz="0 0 0.1"/><geometry><box size="0.1 0.1 0.1"/></geometry></collision>
+		<collision><origin xyz="0 0 0.3"/><geometry><sphere radius="0.05"/></geometry></collision></link>
+		<joint name="j" type="revolute"><parent link="a"/><child link="b"/><axis xyz="1 0 0"/>
+		<limit lower="-2" upper="2"/></joint></robot>`
+	model, err := UnmarshalModelURDF([]byte(data), "")
+	test.That(t, err, test.ShouldBeNil)
+	var names []string
+	for _, frame := range model.(*SimpleModel).OrdTransforms {
+		names = append(names, frame.Name())
+	}
+	test.That(t, names, test.ShouldResemble, []string{"j", "b", "b_collision1"})
+
+	// Both geometries of the link move with its joint.
+	geometries, err := model.Geometries(FloatsToInputs([]float64{math.Pi / 2}))
+	test.That(t, err, test.ShouldNotBeNil)
+	test.That(t, geometries.Geometries(), test.ShouldHaveLength, 2)
+	box := geometries.Geometries()["r:b"]
+	sphere := geometries.Geometries()["r:b_collision1"]
+	test.That(t, box, test.ShouldNotBeNil)
+	test.That(t, sphere, test.ShouldNotBeNil)
+	test.That(t, box.Pose().Point().Z, test.ShouldAlmostEqual, 0)
+	test.That(t, box.Pose().Point().Norm(), test.ShouldAlmostEqual, 100)
+	test.That(t, spatial.R3VectorAlmostEqual(sphere.Pose().Point(), box.Pose().Point().Mul(3), 1e-6), test.ShouldBeTrue)
+}
@@ -3,6 +3,7 @@ package referenceframe
 import (
 	"strconv"
 
+	"github.com/pkg/errors"
 	commonpb "go.viam.com/api/common/v1"
 
 	"go.viam.com/rdk/spatialmath"
@@ -99,15 +100,20 @@ func NewGeometriesInFrame(frame string, geometries map[string]spatialmath.Geomet
 }
 
 // GeometriesInFrameToProtobuf converts a GeometriesInFrame struct to a GeometriesInFrame message as specified in common.proto.
-func GeometriesInFrameToProtobuf(framedGeometries *GeometriesInFrame) *commonpb.GeometriesInFrame {
+// It fails if any of the geometries has no message in the API.
+func GeometriesInFrameToProtobuf(framedGeometries *GeometriesInFrame) (*commonpb.GeometriesInFrame, error) {
 	var geometries []*commonpb.Geometry
-	for _, geometry := range framedGeometries.geometries {
-		geometries = append(geometries, geometry.ToProtobuf())
+	for name, geometry := range framedGeometries.geometries {
+		g, err := spatialmath.GeometryToProtobuf(geometry)
+		if err != nil {
+			return nil, errors.Wrapf(err, "geometry %q", name)
+		}
+		geometries = append(geometries, g)
 	}
 	return &commonpb.GeometriesInFrame{
 		ReferenceFrame: framedGeometries.frame,
 		Geometries:     geometries,
-	}
+	}, nil
 }
 
 // ProtobufToGeometriesInFrame converts a GeometriesInFrame message as specified in common.proto to a GeometriesInFrame struct.
@@ -5,6 +5,7 @@ import (
 	"testing"
 
 	"github.com/golang/geo/r3"
+	"github.com/pkg/errors"
 	"go.viam.com/test"
 
 	spatial "go.viam.com/rdk/spatialmath"
@@ -29,8 +30,17 @@ func TestGeometriesInFrame(t *testing.T) {
 	gF := NewGeometriesInFrame("frame", geometryMap)
 	test.That(t, gF.FrameName(), test.ShouldEqual, "frame")
 	test.That(t, gF.Geometries()[""].AlmostEqual(geometry), test.ShouldBeTrue)
-	convertedGF, err := ProtobufToGeometriesInFrame(GeometriesInFrameToProtobuf(gF))
+	protoGF, err := GeometriesInFrameToProtobuf(gF)
+	test.That(t, err, test.ShouldBeNil)
+	convertedGF, err := ProtobufToGeometriesInFrame(protoGF)
 	test.That(t, err, test.ShouldBeNil)
 	test.That(t, gF.FrameName(), test.ShouldEqual, convertedGF.FrameName())
 	test.That(t, gF.Geometries()[""].AlmostEqual(convertedGF.Geometries()["0"]), test.ShouldBeTrue)
+
+	// Geometries the API has no message for are not sent at all.
+	capsule, err := spatial.NewCapsule(pose, 1, 4, "capsule")
+	test.That(t, err, test.ShouldBeNil)
+	geometryMap["capsule"] = capsule
+	_, err = GeometriesInFrameToProtobuf(NewGeometriesInFrame("frame", geometryMap))
+	test.That(t, errors.Is(err, spatial.ErrGeometryTypeUnsupported), test.ShouldBeTrue)
 }
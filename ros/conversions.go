@@ -0,0 +1,360 @@
+package ros
+
+import (
+	"bytes"
+	"encoding/binary"
+	"image"
+	"image/color"
+	// Register jpeg so that jpeg compressed images can be decoded.
+	_ "image/jpeg"
+	"image/png"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/golang/geo/r3"
+	"github.com/pkg/errors"
+
+	"go.viam.com/rdk/pointcloud"
+	"go.viam.com/rdk/rimage"
+	"go.viam.com/rdk/spatialmath"
+)
+
+// The encodings of Image messages that can be converted.
+const (
+	encodingRGB8   = "rgb8"
+	encodingRGBA8  = "rgba8"
+	encodingBGR8   = "bgr8"
+	encodingBGRA8  = "bgra8"
+	encodingMono8  = "mono8"
+	encodingMono16 = "mono16"
+	// Depth in millimeters.
+	encoding16UC1 = "16UC1"
+	// Depth in meters.
+	encoding32FC1 = "32FC1"
+)
+
+var bytesPerPixel = map[string]int{
+	encodingRGB8:   3,
+	encodingRGBA8:  4,
+	encodingBGR8:   3,
+	encodingBGRA8:  4,
+	encodingMono8:  1,
+	encodingMono16: 2,
+	encoding16UC1:  2,
+	encoding32FC1:  4,
+}
+
+// ToImage converts the message into an image. Depth images are converted into a *rimage.DepthMap.
+func (img *Image) ToImage() (image.Image, error) {
+	bpp, ok := bytesPerPixel[img.Encoding]
+	if !ok {
+		return nil, errors.Errorf("unsupported image encoding %q", img.Encoding)
+	}
+	width, height, step := int(img.Width), int(img.Height), int(img.Step)
+	if step < width*bpp || (height > 0 && len(img.Data) < step*(height-1)+width*bpp) {
+		return nil, errors.Errorf("image data of %d bytes is too short for a %dx%d %s image with a step of %d",
+			len(img.Data), width, height, img.Encoding, step)
+	}
+	var order binary.ByteOrder = binary.LittleEndian
+	if img.IsBigendian != 0 {
+		order = binary.BigEndian
+	}
+	pixel := func(x, y int) []byte {
+		return img.Data[y*step+x*bpp:]
+	}
+	rect := image.Rect(0, 0, width, height)
+
+	switch img.Encoding {
+	case encodingMono8:
+		out := image.NewGray(rect)
+		for y := 0; y < height; y++ {
+			copy(out.Pix[y*out.Stride:], img.Data[y*step:y*step+width])
+		}
+		return out, nil
+	case encodingMono16:
+		out := image.NewGray16(rect)
+		for y := 0; y < height; y++ {
+			for x := 0; x < width; x++ {
+				out.SetGray16(x, y, color.Gray16{Y: order.Uint16(pixel(x, y))})
+			}
+		}
+		return out, nil
+	case encoding16UC1:
+		dm := rimage.NewEmptyDepthMap(width, height)
+		for y := 0; y < height; y++ {
+			for x := 0; x < width; x++ {
+				dm.Set(x, y, rimage.Depth(order.Uint16(pixel(x, y))))
+			}
+		}
+		return dm, nil
+	case encoding32FC1:
+		dm := rimage.NewEmptyDepthMap(width, height)
+		for y := 0; y < height; y++ {
+			for x := 0; x < width; x++ {
+				dm.Set(x, y, metersToDepth(float64(math.Float32frombits(order.Uint32(pixel(x, y))))))
+			}
+		}
+		return dm, nil
+	default:
+		out := image.NewNRGBA(rect)
+		bgr := img.Encoding == encodingBGR8 || img.Encoding == encodingBGRA8
+		for y := 0; y < height; y++ {
+			for x := 0; x < width; x++ {
+				p := pixel(x, y)
+				c := color.NRGBA{R: p[0], G: p[1], B: p[2], A: math.MaxUint8}
+				if bgr {
+					c.R, c.B = c.B, c.R
+				}
+				if bpp == 4 {
+					c.A = p[3]
+				}
+				out.SetNRGBA(x, y, c)
+			}
+		}
+		return out, nil
+	}
+}
+
+// metersToDepth converts a depth in meters into millimeters. Unknown depths, which are NaN, infinite or not positive,
+// are converted to 0.
+func metersToDepth(meters float64) rimage.Depth {
+	if math.IsNaN(meters) || math.IsInf(meters, 0) || meters <= 0 {
+		return 0
+	}
+	return rimage.Depth(math.Min(math.Round(meters*1000), float64(rimage.MaxDepth)))
+}
+
+// compressedDepthHeaderLen is the length of the header that compressed_depth_image_transport puts in front of the PNG
+// of compressed depth images.
+const compressedDepthHeaderLen = 12
+
+// ToImage decodes the message into an image. Depth images compressed by compressed_depth_image_transport are
+// decoded into a *rimage.DepthMap.
+func (img *CompressedImage) ToImage() (image.Image, error) {
+	if !strings.Contains(img.Format, "compressedDepth") {
+		decoded, _, err := image.Decode(bytes.NewReader(img.Data))
+		return decoded, err
+	}
+	if !strings.HasPrefix(img.Format, encoding16UC1) {
+		return nil, errors.Errorf("unsupported compressed depth image format %q, only 16UC1 is supported", img.Format)
+	}
+	if len(img.Data) < compressedDepthHeaderLen {
+		return nil, errors.New("compressed depth image is too short")
+	}
+	decoded, err := png.Decode(bytes.NewReader(img.Data[compressedDepthHeaderLen:]))
+	if err != nil {
+		return nil, err
+	}
+	bounds := decoded.Bounds()
+	dm := rimage.NewEmptyDepthMap(bounds.Dx(), bounds.Dy())
+	for y := 0; y < bounds.Dy(); y++ {
+		for x := 0; x < bounds.Dx(); x++ {
+			gray := color.Gray16Model.Convert(decoded.At(bounds.Min.X+x, bounds.Min.Y+y)).(color.Gray16)
+			dm.Set(x, y, rimage.Depth(gray.Y))
+		}
+	}
+	return dm, nil
+}
+
+// The datatypes of PointField messages.
+const (
+	pointFieldInt8    = 1
+	pointFieldUint8   = 2
+	pointFieldInt16   = 3
+	pointFieldUint16  = 4
+	pointFieldInt32   = 5
+	pointFieldUint32  = 6
+	pointFieldFloat32 = 7
+	pointFieldFloat64 = 8
+)
+
+var pointFieldSizes = map[uint8]int{
+	pointFieldInt8:    1,
+	pointFieldUint8:   1,
+	pointFieldInt16:   2,
+	pointFieldUint16:  2,
+	pointFieldInt32:   4,
+	pointFieldUint32:  4,
+	pointFieldFloat32: 4,
+	pointFieldFloat64: 8,
+}
+
+// read returns the value of the field in point, and whether point is long enough to hold it.
+func (f PointField) read(point []byte, order binary.ByteOrder) (float64, bool) {
+	offset := int(f.Offset)
+	if offset+pointFieldSizes[f.Datatype] > len(point) {
+		return 0, false
+	}
+	b := point[offset:]
+	switch f.Datatype {
+	case pointFieldInt8:
+		return float64(int8(b[0])), true
+	case pointFieldUint8:
+		return float64(b[0]), true
+	case pointFieldInt16:
+		return float64(int16(order.Uint16(b))), true
+	case pointFieldUint16:
+		return float64(order.Uint16(b)), true
+	case pointFieldInt32:
+		return float64(int32(order.Uint32(b))), true
+	case pointFieldUint32:
+		return float64(order.Uint32(b)), true
+	case pointFieldFloat32:
+		return float64(math.Float32frombits(order.Uint32(b))), true
+	case pointFieldFloat64:
+		return math.Float64frombits(order.Uint64(b)), true
+	default:
+		return 0, false
+	}
+}
+
+// ToPointCloud converts the message into a point cloud in millimeters. Points are colored by an rgb or rgba field
+// packed into 4 bytes, if the message has one. Points whose coordinates are not finite are skipped.
+func (pc *PointCloud2) ToPointCloud() (pointcloud.PointCloud, error) {
+	fields := map[string]PointField{}
+	for _, f := range pc.Fields {
+		if _, ok := pointFieldSizes[f.Datatype]; !ok {
+			return nil, errors.Errorf("point field %s has unknown datatype %d", f.Name, f.Datatype)
+		}
+		fields[f.Name] = f
+	}
+	var xyz [3]PointField
+	for i, name := range []string{"x", "y", "z"} {
+		f, ok := fields[name]
+		if !ok {
+			return nil, errors.Errorf("point cloud has no %s field", name)
+		}
+		xyz[i] = f
+	}
+	rgb, hasColor := fields["rgb"]
+	if !hasColor {
+		rgb, hasColor = fields["rgba"]
+	}
+	var order binary.ByteOrder = binary.LittleEndian
+	if pc.IsBigendian {
+		order = binary.BigEndian
+	}
+
+	width, height, pointStep, rowStep := int(pc.Width), int(pc.Height), int(pc.PointStep), int(pc.RowStep)
+	cloud := pointcloud.NewWithPrealloc(width * height)
+	for row := 0; row < height; row++ {
+		for col := 0; col < width; col++ {
+			start := row*rowStep + col*pointStep
+			if start+pointStep > len(pc.Data) {
+				return nil, errors.Errorf("point cloud data of %d bytes is too short for %dx%d points", len(pc.Data), width, height)
+			}
+			point := pc.Data[start : start+pointStep]
+			var pos [3]float64
+			valid := true
+			for i, f := range xyz {
+				v, ok := f.read(point, order)
+				if !ok {
+					return nil, errors.Errorf("point field %s does not fit in a point step of %d", f.Name, pointStep)
+				}
+				valid = valid && !math.IsNaN(v) && !math.IsInf(v, 0)
+				pos[i] = v
+			}
+			if !valid {
+				continue
+			}
+			data := pointcloud.NewBasicData()
+			if hasColor && int(rgb.Offset)+4 <= len(point) {
+				packed := order.Uint32(point[rgb.Offset:])
+				data = pointcloud.NewColoredData(color.NRGBA{
+					R: uint8(packed >> 16), G: uint8(packed >> 8), B: uint8(packed), A: math.MaxUint8,
+				})
+			}
+			if err := cloud.Set(r3.Vector{X: 1000 * pos[0], Y: 1000 * pos[1], Z: 1000 * pos[2]}, data); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return cloud, nil
+}
+
+// NewPointCloud2 converts cloud in millimeters into a message of float32 points in meters. Points are colored by an
+// rgb field if the cloud has color.
+func NewPointCloud2(cloud pointcloud.PointCloud, header MessageHeader) *PointCloud2 {
+	fields := []PointField{
+		{Name: "x", Offset: 0, Datatype: pointFieldFloat32, Count: 1},
+		{Name: "y", Offset: 4, Datatype: pointFieldFloat32, Count: 1},
+		{Name: "z", Offset: 8, Datatype: pointFieldFloat32, Count: 1},
+	}
+	hasColor := cloud.MetaData().HasColor
+	if hasColor {
+		fields = append(fields, PointField{Name: "rgb", Offset: 12, Datatype: pointFieldFloat32, Count: 1})
+	}
+	pointStep := 4 * len(fields)
+	data := make([]byte, 0, pointStep*cloud.Size())
+	cloud.Iterate(0, 0, func(p r3.Vector, d pointcloud.Data) bool {
+		for _, v := range []float64{p.X, p.Y, p.Z} {
+			data = binary.LittleEndian.AppendUint32(data, math.Float32bits(float32(v/1000)))
+		}
+		if hasColor {
+			var packed uint32
+			if d != nil && d.HasColor() {
+				r, g, b := d.RGB255()
+				packed = uint32(r)<<16 | uint32(g)<<8 | uint32(b)
+			}
+			data = binary.LittleEndian.AppendUint32(data, packed)
+		}
+		return true
+	})
+	n := len(data) / pointStep
+	return &PointCloud2{
+		Header:    header,
+		Height:    1,
+		Width:     uint32(n),
+		Fields:    fields,
+		PointStep: uint32(pointStep),
+		RowStep:   uint32(pointStep * n),
+		Data:      data,
+		IsDense:   true,
+	}
+}
+
+// NewImage converts img into a message. Depth maps are converted into 16UC1 images in millimeters, and all other
+// images into rgb8 images.
+func NewImage(img image.Image, header MessageHeader) *Image {
+	bounds := img.Bounds()
+	width, height := bounds.Dx(), bounds.Dy()
+	msg := &Image{Header: header, Width: uint32(width), Height: uint32(height)}
+	if dm, ok := img.(*rimage.DepthMap); ok {
+		msg.Encoding = encoding16UC1
+		msg.Step = uint32(2 * width)
+		msg.Data = make([]byte, 0, 2*width*height)
+		for y := 0; y < height; y++ {
+			for x := 0; x < width; x++ {
+				msg.Data = binary.LittleEndian.AppendUint16(msg.Data, uint16(dm.GetDepth(x, y)))
+			}
+		}
+		return msg
+	}
+	msg.Encoding = encodingRGB8
+	msg.Step = uint32(3 * width)
+	msg.Data = make([]byte, 0, 3*width*height)
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			c := color.NRGBAModel.Convert(img.At(x, y)).(color.NRGBA)
+			msg.Data = append(msg.Data, c.R, c.G, c.B)
+		}
+	}
+	return msg
+}
+
+// NewTimeStamp converts t into a timestamp.
+func NewTimeStamp(t time.Time) TimeStamp {
+	return TimeStamp{Secs: int(t.Unix()), Nsecs: t.Nanosecond()}
+}
+
+// NewQuaternion converts o into a quaternion.
+func NewQuaternion(o spatialmath.Orientation) Quaternion {
+	q := o.Quaternion()
+	return Quaternion{X: q.Imag, Y: q.Jmag, Z: q.Kmag, W: q.Real}
+}
+
+// Orientation converts the quaternion into an orientation.
+func (q Quaternion) Orientation() spatialmath.Orientation {
+	return &spatialmath.Quaternion{Real: q.W, Imag: q.X, Jmag: q.Y, Kmag: q.Z}
+}
@@ -0,0 +1,171 @@
+package ros
+
+import (
+	"bytes"
+	"encoding/binary"
+	"image"
+	"image/color"
+	"image/png"
+	"math"
+	"testing"
+
+	"github.com/golang/geo/r3"
+	"go.viam.com/test"
+
+	"go.viam.com/rdk/pointcloud"
+	"go.viam.com/rdk/rimage"
+)
+
+func TestImageToImage(t *testing.T) {
+	rgb := Image{Width: 2, Height: 1, Encoding: "rgb8", Step: 8, Data: []byte{1, 2, 3, 4, 5, 6, 0, 0}}
+	img, err := rgb.ToImage()
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, img.Bounds(), test.ShouldResemble, image.Rect(0, 0, 2, 1))
+	test.That(t, img.At(1, 0), test.ShouldResemble, color.NRGBA{R: 4, G: 5, B: 6, A: 255})
+
+	bgra := Image{Width: 1, Height: 1, Encoding: "bgra8", Step: 4, Data: []byte{1, 2, 3, 4}}
+	img, err = bgra.ToImage()
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, img.At(0, 0), test.ShouldResemble, color.NRGBA{R: 3, G: 2, B: 1, A: 4})
+
+	mono := Image{Width: 2, Height: 2, Encoding: "mono8", Step: 2, Data: []byte{1, 2, 3, 4}}
+	img, err = mono.ToImage()
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, img.At(0, 1), test.ShouldResemble, color.Gray{Y: 3})
+
+	depth := Image{Width: 2, Height: 1, Encoding: "16UC1", IsBigendian: 1, Step: 4, Data: []byte{1, 0, 0, 2}}
+	img, err = depth.ToImage()
+	test.That(t, err, test.ShouldBeNil)
+	dm, ok := img.(*rimage.DepthMap)
+	test.That(t, ok, test.ShouldBeTrue)
+	test.That(t, dm.GetDepth(0, 0), test.ShouldEqual, rimage.Depth(256))
+	test.That(t, dm.GetDepth(1, 0), test.ShouldEqual, rimage.Depth(2))
+
+	meters := make([]byte, 8)
+	binary.LittleEndian.PutUint32(meters, math.Float32bits(1.5))
+	binary.LittleEndian.PutUint32(meters[4:], math.Float32bits(float32(math.NaN())))
+	depth = Image{Width: 2, Height: 1, Encoding: "32FC1", Step: 8, Data: meters}
+	img, err = depth.ToImage()
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, img.(*rimage.DepthMap).GetDepth(0, 0), test.ShouldEqual, rimage.Depth(1500))
+	test.That(t, img.(*rimage.DepthMap).GetDepth(1, 0), test.ShouldEqual, rimage.Depth(0))
+
+	_, err = (&Image{Width: 2, Height: 2, Encoding: "rgb8", Step: 6, Data: make([]byte, 6)}).ToImage()
+	test.That(t, err, test.ShouldBeError)
+	test.That(t, err.Error(), test.ShouldContainSubstring, "too short")
+	_, err = (&Image{Encoding: "yuv422"}).ToImage()
+	test.That(t, err, test.ShouldBeError)
+}
+
+func TestCompressedImageToImage(t *testing.T) {
+	gray := image.NewGray16(image.Rect(0, 0, 2, 1))
+	gray.SetGray16(1, 0, color.Gray16{Y: 1234})
+	var buf bytes.Buffer
+	test.That(t, png.Encode(&buf, gray), test.ShouldBeNil)
+
+	compressed := CompressedImage{Format: "png", Data: buf.Bytes()}
+	img, err := compressed.ToImage()
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, img.At(1, 0), test.ShouldResemble, color.Gray16{Y: 1234})
+
+	compressed = CompressedImage{
+		Format: "16UC1; compressedDepth",
+		Data:   append(make([]byte, compressedDepthHeaderLen), buf.Bytes()...),
+	}
+	img, err = compressed.ToImage()
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, img.(*rimage.DepthMap).GetDepth(1, 0), test.ShouldEqual, rimage.Depth(1234))
+
+	_, err = (&CompressedImage{Format: "jpeg", Data: []byte("not a jpeg")}).ToImage()
+	test.That(t, err, test.ShouldBeError)
+}
+
+func TestPointCloud2ToPointCloud(t *testing.T) {
+	const pointStep = 16
+	point := func(x, y, z float32, rgb uint32) []byte {
+		b := make([]byte, pointStep)
+		binary.LittleEndian.PutUint32(b, math.Float32bits(x))
+		binary.LittleEndian.PutUint32(b[4:], math.Float32bits(y))
+		binary.LittleEndian.PutUint32(b[8:], math.Float32bits(z))
+		binary.LittleEndian.PutUint32(b[12:], rgb)
+		return b
+	}
+	var data []byte
+	data = append(data, point(1, 2, 3, 0xff0000)...)
+	data = append(data, point(float32(math.NaN()), 0, 0, 0)...)
+	data = append(data, point(-0.5, 0, 0.25, 0x00ff00)...)
+	pc := PointCloud2{
+		Width:  3,
+		Height: 1,
+		Fields: []PointField{
+			{Name: "x", Offset: 0, Datatype: pointFieldFloat32, Count: 1},
+			{Name: "y", Offset: 4, Datatype: pointFieldFloat32, Count: 1},
+			{Name: "z", Offset: 8, Datatype: pointFieldFloat32, Count: 1},
+			{Name: "rgb", Offset: 12, Datatype: pointFieldFloat32, Count: 1},
+		},
+		PointStep: pointStep,
+		RowStep:   3 * pointStep,
+		Data:      data,
+	}
+	cloud, err := pc.ToPointCloud()
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, cloud.Size(), test.ShouldEqual, 2)
+	d, ok := cloud.At(1000, 2000, 3000)
+	test.That(t, ok, test.ShouldBeTrue)
+	test.That(t, d.Color(), test.ShouldResemble, &color.NRGBA{R: 255, A: 255})
+	d, ok = cloud.At(-500, 0, 250)
+	test.That(t, ok, test.ShouldBeTrue)
+	test.That(t, d.Color(), test.ShouldResemble, &color.NRGBA{G: 255, A: 255})
+
+	pc.Fields = pc.Fields[1:]
+	_, err = pc.ToPointCloud()
+	test.That(t, err, test.ShouldBeError)
+	test.That(t, err.Error(), test.ShouldContainSubstring, "no x field")
+}
+
+func TestNewImage(t *testing.T) {
+	img := image.NewNRGBA(image.Rect(0, 0, 2, 1))
+	img.Set(1, 0, color.NRGBA{R: 1, G: 2, B: 3, A: 255})
+	msg := NewImage(img, MessageHeader{FrameID: "cam"})
+	test.That(t, msg.Encoding, test.ShouldEqual, "rgb8")
+	test.That(t, msg.Step, test.ShouldEqual, 6)
+	converted, err := msg.ToImage()
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, converted.At(1, 0), test.ShouldResemble, color.NRGBA{R: 1, G: 2, B: 3, A: 255})
+
+	dm := rimage.NewEmptyDepthMap(2, 1)
+	dm.Set(1, 0, 1234)
+	msg = NewImage(dm, MessageHeader{})
+	test.That(t, msg.Encoding, test.ShouldEqual, "16UC1")
+	converted, err = msg.ToImage()
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, converted.(*rimage.DepthMap).GetDepth(1, 0), test.ShouldEqual, rimage.Depth(1234))
+}
+
+func TestNewPointCloud2(t *testing.T) {
+	cloud := pointcloud.New()
+	test.That(t, cloud.Set(r3.Vector{X: 1000, Y: -500, Z: 250}, pointcloud.NewColoredData(color.NRGBA{B: 255, A: 255})), test.ShouldBeNil)
+	test.That(t, cloud.Set(r3.Vector{Z: 2000}, pointcloud.NewColoredData(color.NRGBA{R: 255, A: 255})), test.ShouldBeNil)
+	msg := NewPointCloud2(cloud, MessageHeader{FrameID: "cam"})
+	test.That(t, msg.Width, test.ShouldEqual, 2)
+	test.That(t, msg.Fields, test.ShouldHaveLength, 4)
+
+	converted, err := msg.ToPointCloud()
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, converted.Size(), test.ShouldEqual, 2)
+	d, ok := converted.At(1000, -500, 250)
+	test.That(t, ok, test.ShouldBeTrue)
+	test.That(t, d.Color(), test.ShouldResemble, &color.NRGBA{B: 255, A: 255})
+}
+
+func TestQuaternionOrientation(t *testing.T) {
+	// A quarter turn about z.
+	q := Quaternion{Z: math.Sqrt2 / 2, W: math.Sqrt2 / 2}
+	ov := q.Orientation().OrientationVectorDegrees()
+	test.That(t, ov.Theta, test.ShouldAlmostEqual, 90)
+	test.That(t, ov.OZ, test.ShouldAlmostEqual, 1)
+
+	back := NewQuaternion(q.Orientation())
+	test.That(t, back.Z, test.ShouldAlmostEqual, q.Z)
+	test.That(t, back.W, test.ShouldAlmostEqual, q.W)
+}
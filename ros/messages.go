@@ -5,8 +5,8 @@ package ros
 // * TimeStamp.Secs: seconds since epoch
 // * TimeStamp.Nsecs: nanoseconds since TimeStamp.Secs.
 type TimeStamp struct {
-	Secs  int
-	Nsecs int
+	Secs  int `json:"secs"`
+	Nsecs int `json:"nsecs"`
 }
 
 // MultiArrayDimension is a ROS std_msgs/MultiArrayDimension message.
@@ -30,24 +30,24 @@ type ByteMultiArray struct {
 
 // MessageHeader is a ROS std_msgs/Header message.
 type MessageHeader struct {
-	Seq     int
-	Stamp   TimeStamp
-	FrameID string `json:"frame_id"`
+	Seq     int       `json:"seq"`
+	Stamp   TimeStamp `json:"stamp"`
+	FrameID string    `json:"frame_id"`
 }
 
 // Quaternion is a ROS geometry_msgs/Quaternion message.
 type Quaternion struct {
-	X float64
-	Y float64
-	Z float64
-	W float64
+	X float64 `json:"x"`
+	Y float64 `json:"y"`
+	Z float64 `json:"z"`
+	W float64 `json:"w"`
 }
 
 // Vector3 is a ROS geometry_msgs/Vector3 message.
 type Vector3 struct {
-	X float64
-	Y float64
-	Z float64
+	X float64 `json:"x"`
+	Y float64 `json:"y"`
+	Z float64 `json:"z"`
 }
 
 // L515Message reflects the JSON data format for rosbag Intel Realsense data.
@@ -59,13 +59,13 @@ type L515Message struct {
 
 // ImuData contains the IMU data.
 type ImuData struct {
-	Header                       MessageHeader
-	Orientation                  Quaternion
-	OrientationCovariance        [9]int  `json:"orientation_covariance"`
-	AngularVelocity              Vector3 `json:"angular_velocity"`
-	AngularVelocityCovariance    [9]int  `json:"angular_velocity_covariance"`
-	LinearAcceleration           Vector3 `json:"linear_acceleration"`
-	LinearAccelerationCovariance [9]int  `json:"linear_acceleration_covariance"`
+	Header                       MessageHeader `json:"header"`
+	Orientation                  Quaternion    `json:"orientation"`
+	OrientationCovariance        [9]float64    `json:"orientation_covariance"`
+	AngularVelocity              Vector3       `json:"angular_velocity"`
+	AngularVelocityCovariance    [9]float64    `json:"angular_velocity_covariance"`
+	LinearAcceleration           Vector3       `json:"linear_acceleration"`
+	LinearAccelerationCovariance [9]float64    `json:"linear_acceleration_covariance"`
 }
 
 // ImuMessage reflects the JSON data format for rosbag imu data.
@@ -73,3 +73,138 @@ type ImuMessage struct {
 	Meta TimeStamp
 	Data ImuData
 }
+
+// Point is a ROS geometry_msgs/Point message.
+type Point struct {
+	X float64 `json:"x"`
+	Y float64 `json:"y"`
+	Z float64 `json:"z"`
+}
+
+// Pose is a ROS geometry_msgs/Pose message.
+type Pose struct {
+	Position    Point      `json:"position"`
+	Orientation Quaternion `json:"orientation"`
+}
+
+// PoseWithCovariance is a ROS geometry_msgs/PoseWithCovariance message.
+type PoseWithCovariance struct {
+	Pose       Pose        `json:"pose"`
+	Covariance [36]float64 `json:"covariance"`
+}
+
+// Twist is a ROS geometry_msgs/Twist message.
+type Twist struct {
+	Linear  Vector3 `json:"linear"`
+	Angular Vector3 `json:"angular"`
+}
+
+// TwistWithCovariance is a ROS geometry_msgs/TwistWithCovariance message.
+type TwistWithCovariance struct {
+	Twist      Twist       `json:"twist"`
+	Covariance [36]float64 `json:"covariance"`
+}
+
+// Odometry is a ROS nav_msgs/Odometry message.
+type Odometry struct {
+	Header       MessageHeader       `json:"header"`
+	ChildFrameID string              `json:"child_frame_id"`
+	Pose         PoseWithCovariance  `json:"pose"`
+	Twist        TwistWithCovariance `json:"twist"`
+}
+
+// NavSatStatus is a ROS sensor_msgs/NavSatStatus message.
+type NavSatStatus struct {
+	Status  int8   `json:"status"`
+	Service uint16 `json:"service"`
+}
+
+// NavSatFix is a ROS sensor_msgs/NavSatFix message.
+type NavSatFix struct {
+	Header                 MessageHeader `json:"header"`
+	Status                 NavSatStatus  `json:"status"`
+	Latitude               float64       `json:"latitude"`
+	Longitude              float64       `json:"longitude"`
+	Altitude               float64       `json:"altitude"`
+	PositionCovariance     [9]float64    `json:"position_covariance"`
+	PositionCovarianceType uint8         `json:"position_covariance_type"`
+}
+
+// Image is a ROS sensor_msgs/Image message.
+type Image struct {
+	Header      MessageHeader `json:"header"`
+	Height      uint32        `json:"height"`
+	Width       uint32        `json:"width"`
+	Encoding    string        `json:"encoding"`
+	IsBigendian uint8         `json:"is_bigendian"`
+	Step        uint32        `json:"step"`
+	Data        []byte        `json:"data"`
+}
+
+// CompressedImage is a ROS sensor_msgs/CompressedImage message.
+type CompressedImage struct {
+	Header MessageHeader `json:"header"`
+	Format string        `json:"format"`
+	Data   []byte        `json:"data"`
+}
+
+// PointField is a ROS sensor_msgs/PointField message.
+type PointField struct {
+	Name     string `json:"name"`
+	Offset   uint32 `json:"offset"`
+	Datatype uint8  `json:"datatype"`
+	Count    uint32 `json:"count"`
+}
+
+// PointCloud2 is a ROS sensor_msgs/PointCloud2 message.
+type PointCloud2 struct {
+	Header      MessageHeader `json:"header"`
+	Height      uint32        `json:"height"`
+	Width       uint32        `json:"width"`
+	Fields      []PointField  `json:"fields"`
+	IsBigendian bool          `json:"is_bigendian"`
+	PointStep   uint32        `json:"point_step"`
+	RowStep     uint32        `json:"row_step"`
+	Data        []byte        `json:"data"`
+	IsDense     bool          `json:"is_dense"`
+}
+
+// JointState is a ROS sensor_msgs/JointState message.
+type JointState struct {
+	Header   MessageHeader `json:"header"`
+	Name     []string      `json:"name"`
+	Position []float64     `json:"position"`
+	Velocity []float64     `json:"velocity"`
+	Effort   []float64     `json:"effort"`
+}
+
+// Transform is a ROS geometry_msgs/Transform message.
+type Transform struct {
+	Translation Vector3    `json:"translation"`
+	Rotation    Quaternion `json:"rotation"`
+}
+
+// TransformStamped is a ROS geometry_msgs/TransformStamped message.
+type TransformStamped struct {
+	Header       MessageHeader `json:"header"`
+	ChildFrameID string        `json:"child_frame_id"`
+	Transform    Transform     `json:"transform"`
+}
+
+// TFMessage is a ROS tf2_msgs/TFMessage message.
+type TFMessage struct {
+	Transforms []TransformStamped `json:"transforms"`
+}
+
+// The types of the ROS messages that rdk can convert.
+const (
+	ImageType           = "sensor_msgs/Image"
+	CompressedImageType = "sensor_msgs/CompressedImage"
+	PointCloud2Type     = "sensor_msgs/PointCloud2"
+	ImuType             = "sensor_msgs/Imu"
+	NavSatFixType       = "sensor_msgs/NavSatFix"
+	OdometryType        = "nav_msgs/Odometry"
+	JointStateType      = "sensor_msgs/JointState"
+	TFMessageType       = "tf2_msgs/TFMessage"
+	TwistType           = "geometry_msgs/Twist"
+)
@@ -0,0 +1,200 @@
+package ros
+
+import (
+	"context"
+	"os"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	goutils "go.viam.com/utils"
+)
+
+// ErrEnd is returned by a Player that does not loop once it has played all messages of its topic.
+var ErrEnd = errors.New("reached the end of the ros bag topic")
+
+// defaultMaxBagMB is the size in megabytes of the largest bag, and of the messages of its topic as JSON, that a Player
+// plays unless its config sets another.
+const defaultMaxBagMB = 512
+
+// TopicConfig describes a topic of a ros bag to play.
+type TopicConfig struct {
+	// Bag is the path of the ros bag.
+	Bag string `json:"bag"`
+	// Topic is the topic to play, such as /camera/image_raw.
+	Topic string `json:"topic"`
+	// Speed is how many times faster than it was recorded the topic is played. It defaults to 1.
+	Speed float64 `json:"speed,omitempty"`
+	// Loop starts playing the topic over once it reaches its end.
+	Loop bool `json:"loop,omitempty"`
+	// MaxBagMB caps the size in megabytes of the bag, and of the messages of the topic as JSON, since both are held in
+	// memory: the bag while the topic is read, and the messages while it is played. It defaults to 512.
+	MaxBagMB int `json:"max_bag_mb,omitempty"`
+}
+
+// Validate ensures all parts of the config are valid.
+func (cfg *TopicConfig) Validate(path string) ([]string, error) {
+	if cfg.Bag == "" {
+		return nil, goutils.NewConfigValidationFieldRequiredError(path, "bag")
+	}
+	if cfg.Topic == "" {
+		return nil, goutils.NewConfigValidationFieldRequiredError(path, "topic")
+	}
+	if cfg.Speed < 0 {
+		return nil, goutils.NewConfigValidationError(path, errors.New("speed cannot be negative"))
+	}
+	if cfg.MaxBagMB < 0 {
+		return nil, goutils.NewConfigValidationError(path, errors.New("max_bag_mb cannot be negative"))
+	}
+	return nil, nil
+}
+
+// Player plays the messages of a topic of a ros bag with the timing they were recorded with.
+type Player struct {
+	msgType  string
+	messages []BagMessage
+	// offsets are the times of the messages since the first message.
+	offsets []time.Duration
+	// The topic ends, or starts over if it loops, after period, which gives the last message the average time between
+	// messages.
+	period time.Duration
+	speed  float64
+	loop   bool
+
+	mu      sync.Mutex
+	started time.Time
+}
+
+// NewPlayer reads the ros bag of cfg and starts playing its topic. It fails if the bag, or the messages of the topic as
+// JSON, are larger than the MaxBagMB of cfg.
+func NewPlayer(cfg TopicConfig) (*Player, error) {
+	maxBytes := int64(cfg.MaxBagMB) << 20
+	if cfg.MaxBagMB == 0 {
+		maxBytes = defaultMaxBagMB << 20
+	}
+	info, err := os.Stat(cfg.Bag)
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to open input file")
+	}
+	if info.Size() > maxBytes {
+		return nil, errors.Errorf("ros bag %s is %d bytes, more than the %d MB that can be played, split it into smaller bags "+
+			"or raise max_bag_mb", cfg.Bag, info.Size(), maxBytes>>20)
+	}
+	rb, err := ReadBag(cfg.Bag)
+	if err != nil {
+		return nil, err
+	}
+	msgType, err := TopicType(rb, cfg.Topic)
+	if err != nil {
+		return nil, err
+	}
+	messages, err := topicMessages(rb, cfg.Topic, maxBytes)
+	if err != nil {
+		return nil, err
+	}
+	return newPlayer(msgType, messages, cfg), nil
+}
+
+func newPlayer(msgType string, messages []BagMessage, cfg TopicConfig) *Player {
+	p := &Player{
+		msgType:  msgType,
+		messages: messages,
+		offsets:  make([]time.Duration, len(messages)),
+		speed:    cfg.Speed,
+		loop:     cfg.Loop,
+		started:  time.Now(),
+	}
+	if p.speed == 0 {
+		p.speed = 1
+	}
+	for i, msg := range messages {
+		p.offsets[i] = msg.Time.Sub(messages[0].Time)
+	}
+	if n := len(messages); n > 1 {
+		duration := p.offsets[n-1]
+		p.period = duration + duration/time.Duration(n-1)
+	}
+	return p
+}
+
+// MessageType returns the type of the messages of the topic, such as sensor_msgs/Image.
+func (p *Player) MessageType() string {
+	return p.msgType
+}
+
+// elapsed returns how far into the topic the player is.
+func (p *Player) elapsed() time.Duration {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return time.Duration(float64(time.Since(p.started)) * p.speed)
+}
+
+// latest returns the number of the latest message to be played by elapsed. Messages are numbered from 0 and keep
+// being numbered across loops. Past the end of a topic that does not loop, the number is that of no message.
+func (p *Player) latest(elapsed time.Duration) int {
+	var loops int
+	if p.period > 0 {
+		loops = int(elapsed / p.period)
+		if !p.loop && loops > 0 {
+			return len(p.messages)
+		}
+		elapsed -= time.Duration(loops) * p.period
+	}
+	i := sort.Search(len(p.offsets), func(i int) bool {
+		return p.offsets[i] > elapsed
+	}) - 1
+	return loops*len(p.messages) + i
+}
+
+// due returns how far into the topic message number n is played.
+func (p *Player) due(n int) time.Duration {
+	loops := n / len(p.messages)
+	return time.Duration(loops)*p.period + p.offsets[n%len(p.messages)]
+}
+
+// Next waits for the message after message number after to be played and returns it with its number. Messages whose
+// time has passed since are skipped, so that however slowly messages are read they keep the timing they were recorded
+// with. An after of -1 returns the latest message to be played without waiting. Topics of a single message play it
+// for as long as they are played.
+func (p *Player) Next(ctx context.Context, after int) (BagMessage, int, error) {
+	if len(p.messages) == 0 {
+		return BagMessage{}, 0, ErrEnd
+	}
+	if len(p.messages) == 1 {
+		return p.messages[0], 0, nil
+	}
+	elapsed := p.elapsed()
+	n := p.latest(elapsed)
+	// Messages after the latest one to be played can only have been returned before the player restarted, in
+	// which case it is played from the latest message again.
+	if n == after {
+		n++
+	}
+	// Without looping, the message after the last one is the end of the topic, and is played after the period.
+	if wait := time.Duration(float64(p.due(n)-elapsed) / p.speed); wait > 0 {
+		if !goutils.SelectContextOrWait(ctx, wait) {
+			return BagMessage{}, n, ctx.Err()
+		}
+	}
+	if !p.loop && n >= len(p.messages) {
+		return BagMessage{}, n, ErrEnd
+	}
+	return p.messages[n%len(p.messages)], n, nil
+}
+
+// Restart plays the topic from its start.
+func (p *Player) Restart() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.started = time.Now()
+}
+
+// DoCommand restarts the player on {"restart": true}.
+func (p *Player) DoCommand(ctx context.Context, cmd map[string]interface{}) (map[string]interface{}, error) {
+	if restart, ok := cmd["restart"].(bool); ok && restart {
+		p.Restart()
+		return map[string]interface{}{}, nil
+	}
+	return nil, errors.Errorf("unknown ros bag command %v, must be restart", cmd)
+}
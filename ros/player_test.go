@@ -0,0 +1,153 @@
+package ros
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"go.viam.com/test"
+)
+
+// testMessages returns n messages that are interval apart, whose data is their index.
+func testMessages(n int, interval time.Duration) []BagMessage {
+	start := time.Unix(1660000000, 0)
+	msgs := make([]BagMessage, n)
+	for i := range msgs {
+		data, _ := json.Marshal(i)
+		msgs[i] = BagMessage{Time: start.Add(time.Duration(i) * interval), Data: data}
+	}
+	return msgs
+}
+
+func messageIndex(t *testing.T, msg BagMessage) int {
+	t.Helper()
+	var i int
+	test.That(t, json.Unmarshal(msg.Data, &i), test.ShouldBeNil)
+	return i
+}
+
+func TestPlayer(t *testing.T) {
+	ctx := context.Background()
+	const interval = 50 * time.Millisecond
+
+	t.Run("original timing", func(t *testing.T) {
+		p := newPlayer(ImuType, testMessages(3, interval), TopicConfig{})
+		test.That(t, p.MessageType(), test.ShouldEqual, ImuType)
+		start := time.Now()
+		msg, n, err := p.Next(ctx, -1)
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, n, test.ShouldEqual, 0)
+		test.That(t, messageIndex(t, msg), test.ShouldEqual, 0)
+
+		for i := 1; i < 3; i++ {
+			msg, n, err = p.Next(ctx, n)
+			test.That(t, err, test.ShouldBeNil)
+			test.That(t, n, test.ShouldEqual, i)
+			test.That(t, messageIndex(t, msg), test.ShouldEqual, i)
+			test.That(t, time.Since(start), test.ShouldBeGreaterThanOrEqualTo, time.Duration(i)*interval)
+		}
+		// The last message is played for the average time between messages.
+		_, _, err = p.Next(ctx, n)
+		test.That(t, err, test.ShouldBeError, ErrEnd)
+		test.That(t, time.Since(start), test.ShouldBeGreaterThanOrEqualTo, 3*interval)
+		_, _, err = p.Next(ctx, -1)
+		test.That(t, err, test.ShouldBeError, ErrEnd)
+
+		p.Restart()
+		msg, _, err = p.Next(ctx, n)
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, messageIndex(t, msg), test.ShouldEqual, 0)
+	})
+
+	t.Run("skips late messages", func(t *testing.T) {
+		p := newPlayer(ImuType, testMessages(10, interval), TopicConfig{Speed: 2})
+		time.Sleep(2 * interval)
+		msg, n, err := p.Next(ctx, 0)
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, n, test.ShouldBeGreaterThanOrEqualTo, 4)
+		test.That(t, messageIndex(t, msg), test.ShouldEqual, n)
+	})
+
+	t.Run("loop", func(t *testing.T) {
+		p := newPlayer(ImuType, testMessages(2, interval), TopicConfig{Speed: 2, Loop: true})
+		_, n, err := p.Next(ctx, -1)
+		test.That(t, err, test.ShouldBeNil)
+		for i := 1; i < 5; i++ {
+			var msg BagMessage
+			msg, n, err = p.Next(ctx, n)
+			test.That(t, err, test.ShouldBeNil)
+			test.That(t, n, test.ShouldEqual, i)
+			test.That(t, messageIndex(t, msg), test.ShouldEqual, i%2)
+		}
+	})
+
+	t.Run("single message", func(t *testing.T) {
+		p := newPlayer(ImuType, testMessages(1, interval), TopicConfig{})
+		for i := 0; i < 3; i++ {
+			msg, n, err := p.Next(ctx, 0)
+			test.That(t, err, test.ShouldBeNil)
+			test.That(t, n, test.ShouldEqual, 0)
+			test.That(t, messageIndex(t, msg), test.ShouldEqual, 0)
+		}
+	})
+
+	t.Run("canceled", func(t *testing.T) {
+		p := newPlayer(ImuType, testMessages(2, time.Hour), TopicConfig{})
+		cancelCtx, cancel := context.WithTimeout(ctx, interval)
+		defer cancel()
+		_, _, err := p.Next(cancelCtx, 0)
+		test.That(t, err, test.ShouldBeError, context.DeadlineExceeded)
+	})
+}
+
+func TestNewPlayer(t *testing.T) {
+	start := time.Unix(1660000000, 0)
+	image := bagTopic{name: "/camera/image_raw", msgType: ImageType, definition: imageDefinition}
+	for i := 0; i < 2; i++ {
+		stamp := start.Add(time.Duration(i) * time.Second)
+		image.times = append(image.times, stamp)
+		image.messages = append(image.messages, append(serializeHeader(t, uint32(i), stamp),
+			serialize(t, uint32(1), uint32(1), "mono8", uint8(0), uint32(1), []byte{uint8(i)})...))
+	}
+	path := writeBag(t, image)
+
+	p, err := NewPlayer(TopicConfig{Bag: path, Topic: "/camera/image_raw"})
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, p.MessageType(), test.ShouldEqual, ImageType)
+	msg, _, err := p.Next(context.Background(), -1)
+	test.That(t, err, test.ShouldBeNil)
+	var img Image
+	test.That(t, json.Unmarshal(msg.Data, &img), test.ShouldBeNil)
+	test.That(t, img.Data, test.ShouldResemble, []byte{0})
+
+	_, err = NewPlayer(TopicConfig{Bag: path, Topic: "/camera/depth"})
+	test.That(t, err, test.ShouldBeError)
+
+	cfg := TopicConfig{Bag: path}
+	_, err = cfg.Validate("path")
+	test.That(t, err.Error(), test.ShouldContainSubstring, "topic")
+	cfg = TopicConfig{Bag: path, Topic: "/camera/image_raw", MaxBagMB: -1}
+	_, err = cfg.Validate("path")
+	test.That(t, err.Error(), test.ShouldContainSubstring, "max_bag_mb")
+}
+
+func TestNewPlayerMaxBagMB(t *testing.T) {
+	// The image is 1.8 MB, and more than 2 MB as JSON.
+	image := bagTopic{name: "/camera/image_raw", msgType: ImageType, definition: imageDefinition}
+	stamp := time.Unix(1660000000, 0)
+	data := make([]byte, 1800<<10)
+	image.times = append(image.times, stamp)
+	image.messages = append(image.messages, append(serializeHeader(t, 0, stamp),
+		serialize(t, uint32(1), uint32(len(data)), "mono8", uint8(0), uint32(len(data)), data)...))
+	path := writeBag(t, image)
+
+	_, err := NewPlayer(TopicConfig{Bag: path, Topic: "/camera/image_raw"})
+	test.That(t, err, test.ShouldBeNil)
+	_, err = NewPlayer(TopicConfig{Bag: path, Topic: "/camera/image_raw", MaxBagMB: 1})
+	test.That(t, err, test.ShouldNotBeNil)
+	test.That(t, err.Error(), test.ShouldContainSubstring, "max_bag_mb")
+	_, err = NewPlayer(TopicConfig{Bag: path, Topic: "/camera/image_raw", MaxBagMB: 2})
+	test.That(t, err, test.ShouldNotBeNil)
+	test.That(t, err.Error(), test.ShouldContainSubstring, "as JSON")
+}
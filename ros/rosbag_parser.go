@@ -5,6 +5,9 @@ import (
 	"encoding/json"
 	"io"
 	"os"
+	"sort"
+	"strings"
+	"time"
 
 	"github.com/edaniels/gobag/rosbag"
 	"github.com/pkg/errors"
@@ -76,7 +79,7 @@ func AllMessagesForTopic(rb *rosbag.RosBag, topic string) ([]map[string]interfac
 		return nil, errors.Wrapf(err, "error while parsing bag to JSON")
 	}
 
-	msgs := rb.TopicsAsJSON[topic]
+	msgs := rb.TopicsAsJSON[topicKey(topic)]
 	if msgs == nil {
 		return nil, errors.Errorf("no messages for topic %s", topic)
 	}
@@ -102,3 +105,88 @@ func AllMessagesForTopic(rb *rosbag.RosBag, topic string) ([]map[string]interfac
 
 	return all, nil
 }
+
+// topicKey returns the key of the messages of topic in the TopicsAsJSON of a bag, which is the topic in lower case
+// without its leading slash and with its other slashes replaced by underscores.
+func topicKey(topic string) string {
+	return strings.ToLower(strings.ReplaceAll(strings.TrimPrefix(topic, "/"), "/", "_"))
+}
+
+// BagMessage is a message of a topic of a ros bag.
+type BagMessage struct {
+	// Time is when the message was recorded.
+	Time time.Time
+	// Data is the message as JSON, which can be unmarshaled into the message type of its topic.
+	Data json.RawMessage
+}
+
+// TopicType returns the message type of a topic in the ros bag, such as sensor_msgs/Image.
+func TopicType(rb *rosbag.RosBag, topic string) (string, error) {
+	topics := make([]string, 0, len(rb.Connections))
+	for _, conn := range rb.Connections {
+		if conn.HeaderTopic == topic {
+			return conn.ConnectionType, nil
+		}
+		topics = append(topics, conn.HeaderTopic)
+	}
+	sort.Strings(topics)
+	return "", errors.Errorf("no topic %s in ros bag, topics are %v", topic, topics)
+}
+
+// TopicMessages returns all messages for a specific topic in the ros bag in the order they were recorded. Unlike
+// AllMessagesForTopic, the data of the messages is left as JSON so that it can be unmarshaled into their type.
+func TopicMessages(rb *rosbag.RosBag, topic string) ([]BagMessage, error) {
+	return topicMessages(rb, topic, 0)
+}
+
+// topicMessages is TopicMessages, which fails if the messages of the topic as JSON are more than maxBytes, unless it
+// is 0.
+func topicMessages(rb *rosbag.RosBag, topic string, maxBytes int64) ([]BagMessage, error) {
+	if _, err := TopicType(rb, topic); err != nil {
+		return nil, err
+	}
+	if err := rb.ParseTopicsToJSON(
+		"",
+		func(int64) bool { return true },
+		func(t string) bool { return t == topic },
+		false,
+	); err != nil {
+		return nil, errors.Wrapf(err, "error while parsing bag to JSON")
+	}
+
+	msgs := rb.TopicsAsJSON[topicKey(topic)]
+	if msgs == nil {
+		return nil, errors.Errorf("no messages for topic %s", topic)
+	}
+	if maxBytes > 0 && int64(msgs.Len()) > maxBytes {
+		return nil, errors.Errorf("the messages of topic %s are %d bytes as JSON, more than the %d MB that can be played",
+			topic, msgs.Len(), maxBytes>>20)
+	}
+
+	var all []BagMessage
+	for {
+		data, err := msgs.ReadBytes('\n')
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			return nil, err
+		}
+		var message struct {
+			Meta TimeStamp
+			Data json.RawMessage
+		}
+		if err := json.Unmarshal(data, &message); err != nil {
+			return nil, err
+		}
+		all = append(all, BagMessage{
+			Time: time.Unix(int64(message.Meta.Secs), int64(message.Meta.Nsecs)),
+			Data: message.Data,
+		})
+	}
+	// Messages are parsed chunk by chunk, and only ordered within each.
+	sort.SliceStable(all, func(i, j int) bool {
+		return all[i].Time.Before(all[j].Time)
+	})
+	return all, nil
+}
@@ -0,0 +1,223 @@
+package ros
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"go.viam.com/test"
+)
+
+const headerDefinition = `
+================================================================================
+MSG: std_msgs/Header
+uint32 seq
+time stamp
+string frame_id
+`
+
+const imuDefinition = `Header header
+geometry_msgs/Quaternion orientation
+float64[9] orientation_covariance
+geometry_msgs/Vector3 angular_velocity
+float64[9] angular_velocity_covariance
+geometry_msgs/Vector3 linear_acceleration
+float64[9] linear_acceleration_covariance
+` + headerDefinition + `
+================================================================================
+MSG: geometry_msgs/Quaternion
+float64 x
+float64 y
+float64 z
+float64 w
+
+================================================================================
+MSG: geometry_msgs/Vector3
+float64 x
+float64 y
+float64 z`
+
+const imageDefinition = `Header header
+uint32 height
+uint32 width
+string encoding
+uint8 is_bigendian
+uint32 step
+uint8[] data
+` + headerDefinition
+
+// bagTopic is a topic of a bag written by writeBag, whose messages are already serialized.
+type bagTopic struct {
+	name       string
+	msgType    string
+	definition string
+	times      []time.Time
+	messages   [][]byte
+}
+
+// writeBag writes a ROS bag of a single uncompressed chunk holding the messages of topics.
+func writeBag(t *testing.T, topics ...bagTopic) string {
+	t.Helper()
+	field := func(buf *bytes.Buffer, name string, value interface{}) {
+		var v bytes.Buffer
+		if s, ok := value.(string); ok {
+			v.WriteString(s)
+		} else {
+			test.That(t, binary.Write(&v, binary.LittleEndian, value), test.ShouldBeNil)
+		}
+		test.That(t, binary.Write(buf, binary.LittleEndian, int32(len(name)+1+v.Len())), test.ShouldBeNil)
+		buf.WriteString(name + "=")
+		buf.Write(v.Bytes())
+	}
+	record := func(buf *bytes.Buffer, header, data []byte) {
+		test.That(t, binary.Write(buf, binary.LittleEndian, int32(len(header))), test.ShouldBeNil)
+		buf.Write(header)
+		test.That(t, binary.Write(buf, binary.LittleEndian, int32(len(data))), test.ShouldBeNil)
+		buf.Write(data)
+	}
+
+	var chunk, indexes, connections bytes.Buffer
+	for conn, topic := range topics {
+		var index bytes.Buffer
+		for i, msg := range topic.messages {
+			secs, nsecs := int32(topic.times[i].Unix()), int32(topic.times[i].Nanosecond())
+			test.That(t, binary.Write(&index, binary.LittleEndian, []int32{secs, nsecs, int32(chunk.Len())}), test.ShouldBeNil)
+			var header bytes.Buffer
+			field(&header, "op", uint8(2))
+			field(&header, "conn", int32(conn))
+			field(&header, "time", []int32{secs, nsecs})
+			record(&chunk, header.Bytes(), msg)
+		}
+		var header bytes.Buffer
+		field(&header, "op", uint8(4))
+		field(&header, "ver", int32(1))
+		field(&header, "conn", int32(conn))
+		field(&header, "count", int32(len(topic.messages)))
+		record(&indexes, header.Bytes(), index.Bytes())
+
+		header.Reset()
+		field(&header, "op", uint8(7))
+		field(&header, "conn", int32(conn))
+		field(&header, "topic", topic.name)
+		var data bytes.Buffer
+		field(&data, "topic", topic.name)
+		field(&data, "type", topic.msgType)
+		// Message formats are cached by their md5sum, so it only needs to be unique to the definition.
+		field(&data, "md5sum", topic.msgType)
+		field(&data, "message_definition", topic.definition)
+		record(&connections, header.Bytes(), data.Bytes())
+	}
+
+	var bag, header bytes.Buffer
+	bag.WriteString("#ROSBAG V2.0\n")
+	field(&header, "op", uint8(3))
+	record(&bag, header.Bytes(), nil)
+	header.Reset()
+	field(&header, "op", uint8(5))
+	field(&header, "compression", "none")
+	field(&header, "size", int32(chunk.Len()))
+	record(&bag, header.Bytes(), chunk.Bytes())
+	bag.Write(indexes.Bytes())
+	bag.Write(connections.Bytes())
+
+	path := filepath.Join(t.TempDir(), "test.bag")
+	test.That(t, os.WriteFile(path, bag.Bytes(), 0o600), test.ShouldBeNil)
+	return path
+}
+
+// serialize serializes the fields of a message in order, with strings and slices prefixed by their length.
+func serialize(t *testing.T, fields ...interface{}) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	for _, f := range fields {
+		switch v := f.(type) {
+		case string:
+			test.That(t, binary.Write(&buf, binary.LittleEndian, int32(len(v))), test.ShouldBeNil)
+			buf.WriteString(v)
+		case []byte:
+			test.That(t, binary.Write(&buf, binary.LittleEndian, int32(len(v))), test.ShouldBeNil)
+			buf.Write(v)
+		default:
+			test.That(t, binary.Write(&buf, binary.LittleEndian, v), test.ShouldBeNil)
+		}
+	}
+	return buf.Bytes()
+}
+
+func serializeHeader(t *testing.T, seq uint32, stamp time.Time) []byte {
+	t.Helper()
+	return serialize(t, seq, int32(stamp.Unix()), int32(stamp.Nanosecond()), "base_link")
+}
+
+func TestTopicMessages(t *testing.T) {
+	start := time.Unix(1660000000, 0)
+	imu := bagTopic{name: "/imu/data", msgType: ImuType, definition: imuDefinition}
+	for i := 0; i < 3; i++ {
+		stamp := start.Add(time.Duration(i) * 100 * time.Millisecond)
+		imu.times = append(imu.times, stamp)
+		imu.messages = append(imu.messages, append(serializeHeader(t, uint32(i), stamp), serialize(t,
+			[4]float64{0, 0, 0, 1}, [9]float64{0.1},
+			[3]float64{0, 0, float64(i)}, [9]float64{},
+			[3]float64{0, 0, 9.8}, [9]float64{},
+		)...))
+	}
+	image := bagTopic{
+		name:       "/camera/Image_Raw",
+		msgType:    ImageType,
+		definition: imageDefinition,
+		times:      []time.Time{start},
+		messages: [][]byte{append(serializeHeader(t, 0, start),
+			serialize(t, uint32(1), uint32(2), "mono8", uint8(0), uint32(2), []byte{7, 8})...)},
+	}
+	// Out of order times are sorted.
+	image.times = append(image.times, start.Add(-time.Second))
+	image.messages = append(image.messages, image.messages[0])
+
+	rb, err := ReadBag(writeBag(t, imu, image))
+	test.That(t, err, test.ShouldBeNil)
+
+	msgType, err := TopicType(rb, "/imu/data")
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, msgType, test.ShouldEqual, ImuType)
+	_, err = TopicType(rb, "/imu")
+	test.That(t, err, test.ShouldBeError)
+	test.That(t, err.Error(), test.ShouldContainSubstring, "/camera/Image_Raw")
+
+	msgs, err := TopicMessages(rb, "/imu/data")
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, msgs, test.ShouldHaveLength, 3)
+	for i, msg := range msgs {
+		test.That(t, msg.Time.Equal(imu.times[i]), test.ShouldBeTrue)
+		var data ImuData
+		test.That(t, json.Unmarshal(msg.Data, &data), test.ShouldBeNil)
+		test.That(t, data.Header.Seq, test.ShouldEqual, i)
+		test.That(t, data.Header.FrameID, test.ShouldEqual, "base_link")
+		test.That(t, data.Orientation, test.ShouldResemble, Quaternion{W: 1})
+		test.That(t, data.OrientationCovariance[0], test.ShouldEqual, 0.1)
+		test.That(t, data.AngularVelocity, test.ShouldResemble, Vector3{Z: float64(i)})
+		test.That(t, data.LinearAcceleration, test.ShouldResemble, Vector3{Z: 9.8})
+	}
+
+	msgs, err = TopicMessages(rb, "/camera/Image_Raw")
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, msgs, test.ShouldHaveLength, 2)
+	test.That(t, msgs[0].Time.Equal(image.times[1]), test.ShouldBeTrue)
+	var img Image
+	test.That(t, json.Unmarshal(msgs[1].Data, &img), test.ShouldBeNil)
+	test.That(t, img, test.ShouldResemble, Image{
+		Header:   MessageHeader{Stamp: TimeStamp{Secs: int(start.Unix())}, FrameID: "base_link"},
+		Height:   1,
+		Width:    2,
+		Encoding: "mono8",
+		Step:     2,
+		Data:     []byte{7, 8},
+	})
+
+	all, err := AllMessagesForTopic(rb, "/camera/Image_Raw")
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, all, test.ShouldHaveLength, 2)
+}
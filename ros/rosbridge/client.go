@@ -0,0 +1,98 @@
+// Package rosbridge implements a client of the rosbridge websocket protocol, which exposes the topics of a ROS system
+// as JSON.
+package rosbridge
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/pkg/errors"
+	"nhooyr.io/websocket"
+	"nhooyr.io/websocket/wsjson"
+)
+
+// The operations of the rosbridge protocol used by Client.
+const (
+	OpAdvertise   = "advertise"
+	OpUnadvertise = "unadvertise"
+	OpPublish     = "publish"
+	OpSubscribe   = "subscribe"
+	OpUnsubscribe = "unsubscribe"
+	OpStatus      = "status"
+)
+
+// readLimit is the largest message that is read. rosbridge servers publish whole messages, such as images, at once.
+const readLimit = 64 << 20
+
+// Message is a message of the rosbridge protocol.
+type Message struct {
+	Op    string `json:"op"`
+	ID    string `json:"id,omitempty"`
+	Topic string `json:"topic,omitempty"`
+	Type  string `json:"type,omitempty"`
+	// Msg is the ROS message published to or on a topic, or the text of a status message.
+	Msg json.RawMessage `json:"msg,omitempty"`
+	// Level is the level of a status message, such as error.
+	Level string `json:"level,omitempty"`
+}
+
+// Client is a connection to a rosbridge server. It is safe to use concurrently, except for Read.
+type Client struct {
+	conn *websocket.Conn
+}
+
+// Dial connects to the rosbridge server at url, such as ws://localhost:9090.
+func Dial(ctx context.Context, url string) (*Client, error) {
+	//nolint:bodyclose
+	conn, _, err := websocket.Dial(ctx, url, nil)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to connect to rosbridge server at %s", url)
+	}
+	conn.SetReadLimit(readLimit)
+	return &Client{conn: conn}, nil
+}
+
+func (c *Client) write(ctx context.Context, msg Message) error {
+	return wsjson.Write(ctx, c.conn, msg)
+}
+
+// Advertise announces that messages of msgType will be published on topic.
+func (c *Client) Advertise(ctx context.Context, topic, msgType string) error {
+	return c.write(ctx, Message{Op: OpAdvertise, Topic: topic, Type: msgType})
+}
+
+// Unadvertise announces that no more messages will be published on topic.
+func (c *Client) Unadvertise(ctx context.Context, topic string) error {
+	return c.write(ctx, Message{Op: OpUnadvertise, Topic: topic})
+}
+
+// Publish publishes msg, which is marshaled into JSON, on topic.
+func (c *Client) Publish(ctx context.Context, topic string, msg interface{}) error {
+	data, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+	return c.write(ctx, Message{Op: OpPublish, Topic: topic, Msg: data})
+}
+
+// Subscribe asks for the messages of msgType published on topic, which are then returned by Read.
+func (c *Client) Subscribe(ctx context.Context, topic, msgType string) error {
+	return c.write(ctx, Message{Op: OpSubscribe, Topic: topic, Type: msgType})
+}
+
+// Unsubscribe stops the messages published on topic.
+func (c *Client) Unsubscribe(ctx context.Context, topic string) error {
+	return c.write(ctx, Message{Op: OpUnsubscribe, Topic: topic})
+}
+
+// Read waits for the next message from the server, such as a message published on a subscribed topic.
+func (c *Client) Read(ctx context.Context) (Message, error) {
+	var msg Message
+	err := wsjson.Read(ctx, c.conn, &msg)
+	return msg, err
+}
+
+// Close closes the connection.
+func (c *Client) Close() error {
+	return c.conn.Close(websocket.StatusNormalClosure, "")
+}
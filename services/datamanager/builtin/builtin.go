@@ -2,6 +2,7 @@
 package builtin
 
 import (
+	"bytes"
 	"context"
 	"fmt"
 	"os"
@@ -73,7 +74,21 @@ type dataCaptureConfig struct {
 	AdditionalParams   map[string]string    `json:"additional_params"`
 	Disabled           bool                 `json:"disabled"`
 	RemoteRobotName    string               // Empty if this component is locally accessed
+	ResourceType       resource.TypeName    // Empty for components
 	Tags               []string             `json:"tags"`
+	// RetentionAgeMins is how long captured files are kept before they are deleted, whether or not they were
+	// synced. 0 keeps them until sync deletes them.
+	RetentionAgeMins float64 `json:"retention_age_mins"`
+	// RetentionPriority orders collectors for the drop_lowest_priority retention policy.
+	RetentionPriority int `json:"retention_priority"`
+	// SyncPriority orders the upload of files of different collectors, higher first.
+	SyncPriority int `json:"sync_priority"`
+	// Trigger, if set, only saves the readings around the events that trigger it.
+	Trigger *triggerConfig `json:"trigger"`
+	// Compression, gzip or zstd, compresses each reading on disk. Files are decompressed when they are synced.
+	Compression datacapture.Compression `json:"compression"`
+	// Encrypt encrypts files on disk with the encryption key of the service. Files are decrypted when they are synced.
+	Encrypt bool `json:"encrypt"`
 }
 
 type dataCaptureConfigs struct {
@@ -82,12 +97,22 @@ type dataCaptureConfigs struct {
 
 // Config describes how to configure the service.
 type Config struct {
-	CaptureDir            string         `json:"capture_dir"`
-	AdditionalSyncPaths   []string       `json:"additional_sync_paths"`
-	SyncIntervalMins      float64        `json:"sync_interval_mins"`
-	CaptureDisabled       bool           `json:"capture_disabled"`
-	ScheduledSyncDisabled bool           `json:"sync_disabled"`
-	ModelsToDeploy        []*model.Model `json:"models_on_robot"`
+	CaptureDir            string          `json:"capture_dir"`
+	AdditionalSyncPaths   []string        `json:"additional_sync_paths"`
+	SyncIntervalMins      float64         `json:"sync_interval_mins"`
+	CaptureDisabled       bool            `json:"capture_disabled"`
+	ScheduledSyncDisabled bool            `json:"sync_disabled"`
+	ModelsToDeploy        []*model.Model  `json:"models_on_robot"`
+	Retention             RetentionConfig `json:"retention"`
+	// SyncDestination is where data is synced to, the cloud unless configured otherwise.
+	SyncDestination datasync.DestinationConfig `json:"sync_destination"`
+	// SyncOptions limit the bandwidth, times of day and parallelism of sync.
+	SyncOptions datasync.Options `json:"sync_options"`
+	// EncryptionKey is the hex encoded AES-256 key that collectors which encrypt their files use.
+	EncryptionKey string `json:"encryption_key"`
+	// PreviousEncryptionKeys are hex encoded keys that EncryptionKey replaced, which files that were not synced yet
+	// may be encrypted with. Keys that are replaced while the service runs are kept without them until it restarts.
+	PreviousEncryptionKeys []string `json:"previous_encryption_keys"`
 }
 
 // builtIn initializes and orchestrates data capture collectors for registered component/methods.
@@ -97,6 +122,8 @@ type builtIn struct {
 	syncLogger                golog.Logger
 	captureDir                string
 	captureDisabled           bool
+	encryptionKey             []byte
+	replacedKeys              [][]byte
 	collectors                map[componentMethodMetadata]collectorAndConfig
 	lock                      sync.Mutex
 	backgroundWorkers         sync.WaitGroup
@@ -106,11 +133,18 @@ type builtIn struct {
 	additionalSyncPaths []string
 	syncDisabled        bool
 	syncIntervalMins    float64
+	syncDestination     datasync.DestinationConfig
+	syncOptions         datasync.Options
 	syncer              datasync.Manager
 	syncerConstructor   datasync.ManagerConstructor
 
 	modelManager            model.Manager
 	modelManagerConstructor model.ManagerConstructor
+
+	janitor       *janitor
+	freeSpace     func(path string) (uint64, error)
+	evictionsLock sync.Mutex
+	evictions     datamanager.EvictionMetrics
 }
 
 var viamCaptureDotDir = filepath.Join(os.Getenv("HOME"), "capture", ".viam")
@@ -140,8 +174,13 @@ func NewBuiltIn(_ context.Context, r robot.Robot, _ config.Service, logger golog
 		syncIntervalMins:          -1,
 		additionalSyncPaths:       []string{},
 		waitAfterLastModifiedSecs: 10,
-		syncerConstructor:         datasync.NewDefaultManager,
+		syncerConstructor:         datasync.NewManagerForDestination,
 		modelManagerConstructor:   model.NewDefaultManager,
+		freeSpace:                 freeSpace,
+		evictions: datamanager.EvictionMetrics{
+			ByReason:    make(map[string]datamanager.EvictionCount),
+			ByCollector: make(map[string]datamanager.EvictionCount),
+		},
 	}
 
 	return dataManagerSvc, nil
@@ -149,6 +188,8 @@ func NewBuiltIn(_ context.Context, r robot.Robot, _ config.Service, logger golog
 
 // Close releases all resources managed by data_manager.
 func (svc *builtIn) Close(_ context.Context) error {
+	// The janitor needs the lock to stop.
+	svc.stopJanitor()
 	svc.lock.Lock()
 	defer svc.lock.Unlock()
 	svc.closeCollectors()
@@ -224,6 +265,10 @@ func (svc *builtIn) initializeOrUpdateCollector(
 	}
 
 	// TODO: DATA-451 https://viam.atlassian.net/browse/DATA-451 (validate method params)
+	fileOpts, err := svc.fileOptions(attributes)
+	if err != nil {
+		return nil, err
+	}
 
 	if storedCollectorParams, ok := svc.collectors[componentMetadata]; ok {
 		collector := storedCollectorParams.Collector
@@ -232,7 +277,7 @@ func (svc *builtIn) initializeOrUpdateCollector(
 		// If the attributes have not changed, keep the current collector and update the target capture file if needed.
 		if reflect.DeepEqual(previousAttributes, attributes) {
 			if updateCaptureDir {
-				targetFile, err := datacapture.NewFile(svc.captureDir, captureMetadata)
+				targetFile, err := datacapture.NewFile(svc.captureDir, captureMetadata, fileOpts...)
 				if err != nil {
 					return nil, err
 				}
@@ -245,10 +290,14 @@ func (svc *builtIn) initializeOrUpdateCollector(
 		collector.Close()
 	}
 
-	// Get the resource corresponding to the component subtype and name.
+	// Get the resource corresponding to the component or service subtype and name.
+	typeName := attributes.ResourceType
+	if typeName == "" {
+		typeName = resource.ResourceTypeComponent
+	}
 	resourceType := resource.NewSubtype(
 		resource.ResourceNamespaceRDK,
-		resource.ResourceTypeComponent,
+		typeName,
 		attributes.Type,
 	)
 
@@ -275,7 +324,7 @@ func (svc *builtIn) initializeOrUpdateCollector(
 
 	// Parameters to initialize collector.
 	interval := getDurationFromHz(attributes.CaptureFrequencyHz)
-	targetFile, err := datacapture.NewFile(svc.captureDir, captureMetadata)
+	targetFile, err := datacapture.NewFile(svc.captureDir, captureMetadata, fileOpts...)
 	if err != nil {
 		return nil, err
 	}
@@ -296,6 +345,18 @@ func (svc *builtIn) initializeOrUpdateCollector(
 		return nil, err
 	}
 
+	var trigger *data.TriggerParams
+	var triggerCondition triggerCondition
+	if attributes.Trigger != nil {
+		if err := attributes.Trigger.validate(); err != nil {
+			return nil, err
+		}
+		trigger = attributes.Trigger.params()
+		if triggerCondition, err = svc.triggerCondition(attributes.Trigger); err != nil {
+			return nil, err
+		}
+	}
+
 	// Create a collector for this resource and method.
 	params := data.CollectorParams{
 		ComponentName: attributes.Name,
@@ -305,11 +366,15 @@ func (svc *builtIn) initializeOrUpdateCollector(
 		QueueSize:     captureQueueSize,
 		BufferSize:    captureBufferSize,
 		Logger:        svc.logger,
+		Trigger:       trigger,
 	}
 	collector, err := (*collectorConstructor)(res, params)
 	if err != nil {
 		return nil, err
 	}
+	if triggerCondition != nil {
+		collector = watchTrigger(collector, triggerCondition, attributes.Trigger.CheckFrequencyHz, svc.logger)
+	}
 	svc.lock.Lock()
 	svc.collectors[componentMetadata] = collectorAndConfig{collector, attributes}
 	svc.lock.Unlock()
@@ -320,6 +385,21 @@ func (svc *builtIn) initializeOrUpdateCollector(
 	return &componentMetadata, nil
 }
 
+// fileOptions returns how the files of the collector configured by attributes are compressed and encrypted.
+func (svc *builtIn) fileOptions(attributes dataCaptureConfig) ([]datacapture.FileOption, error) {
+	if err := attributes.Compression.Validate(); err != nil {
+		return nil, err
+	}
+	opts := []datacapture.FileOption{datacapture.WithCompression(attributes.Compression)}
+	if attributes.Encrypt {
+		if svc.encryptionKey == nil {
+			return nil, errors.Errorf("%s of %s is encrypted but no encryption_key is configured", attributes.Method, attributes.Name)
+		}
+		opts = append(opts, datacapture.WithEncryption(svc.encryptionKey))
+	}
+	return opts, nil
+}
+
 // getCollectorFromConfig returns the collector and metadata that is referenced based on specific config atrributes
 func (svc *builtIn) getCollectorFromConfig(attributes dataCaptureConfig) (data.Collector, *componentMethodMetadata) {
 	// Create component/method metadata to check if the collector exists.
@@ -343,24 +423,47 @@ func (svc *builtIn) getCollectorFromConfig(attributes dataCaptureConfig) (data.C
 	return nil, nil
 }
 
+// appendKey appends key to keys unless it is in them already.
+func appendKey(keys [][]byte, key []byte) [][]byte {
+	for _, k := range keys {
+		if bytes.Equal(k, key) {
+			return keys
+		}
+	}
+	return append(keys, key)
+}
+
+// setSyncerOptions passes opts on to syncer if it can be configured with them.
+func setSyncerOptions(syncer datasync.Manager, opts datasync.Options) {
+	if setter, ok := syncer.(datasync.OptionsSetter); ok {
+		setter.SetOptions(opts)
+	}
+}
+
 func (svc *builtIn) initOrUpdateSyncer(_ context.Context, intervalMins float64, cfg *config.Config) error {
 	// If user updates sync config while a sync is occurring, the running sync will be cancelled.
 	// TODO DATA-235: fix that
+	// The retention janitor deletes files through the syncer, so it is swapped under the lock.
+	svc.lock.Lock()
 	if svc.syncer != nil {
 		// If previously we were syncing, close the old syncer and cancel the old updateCollectors goroutine.
 		svc.syncer.Close()
 		svc.syncer = nil
 	}
+	svc.lock.Unlock()
 
 	svc.cancelSyncBackgroundRoutine()
 
 	// Kick off syncer if we're running it.
 	if intervalMins > 0 && !svc.syncDisabled {
-		syncer, err := svc.syncerConstructor(svc.syncLogger, cfg)
+		syncer, err := svc.syncerConstructor(svc.syncLogger, cfg, svc.syncDestination)
 		if err != nil {
 			return errors.Wrap(err, "failed to initialize new syncer")
 		}
+		setSyncerOptions(syncer, svc.syncOptions)
+		svc.lock.Lock()
 		svc.syncer = syncer
+		svc.lock.Unlock()
 
 		// Sync existing files in captureDir.
 		var previouslyCaptured []string
@@ -416,7 +519,11 @@ func (svc *builtIn) syncDataCaptureFiles() error {
 			return err
 		}
 
-		nextTarget, err := datacapture.NewFile(svc.captureDir, captureMetadata)
+		fileOpts, err := svc.fileOptions(attributes)
+		if err != nil {
+			return err
+		}
+		nextTarget, err := datacapture.NewFile(svc.captureDir, captureMetadata, fileOpts...)
 		if err != nil {
 			return err
 		}
@@ -477,9 +584,33 @@ func (svc *builtIn) Update(ctx context.Context, cfg *config.Config) error {
 	// Service is not in the config, has been removed from it, or is incorrectly formatted in the config.
 	// Close any collectors.
 	if !ok {
+		svc.stopJanitor()
 		svc.closeCollectors()
 		return err
 	}
+	if err := svcConfig.SyncDestination.Validate(); err != nil {
+		return err
+	}
+	if err := svcConfig.SyncOptions.Validate(); err != nil {
+		return err
+	}
+	if err := svcConfig.Retention.validate(); err != nil {
+		return err
+	}
+	var encryptionKey []byte
+	if svcConfig.EncryptionKey != "" {
+		if encryptionKey, err = datacapture.ParseEncryptionKey(svcConfig.EncryptionKey); err != nil {
+			return err
+		}
+	}
+	previousKeys := make([][]byte, 0, len(svcConfig.PreviousEncryptionKeys))
+	for _, hexKey := range svcConfig.PreviousEncryptionKeys {
+		key, err := datacapture.ParseEncryptionKey(hexKey)
+		if err != nil {
+			return errors.Wrap(err, "invalid previous encryption key")
+		}
+		previousKeys = append(previousKeys, key)
+	}
 
 	// Check that we have models to download and appropriate credentials.
 	if len(svcConfig.ModelsToDeploy) > 0 && cfg.Cloud != nil {
@@ -530,8 +661,23 @@ func (svc *builtIn) Update(ctx context.Context, cfg *config.Config) error {
 	toggledSyncOn := toggledSync && !svc.syncDisabled
 
 	// If sync has been toggled on, sync previously captured files and update the capture directory.
-	updateCaptureDir := (svc.captureDir != svcConfig.CaptureDir) || toggledSyncOn
+	// Files are also started over with a new encryption key so that no file is encrypted with an old one.
+	updateCaptureDir := (svc.captureDir != svcConfig.CaptureDir) || toggledSyncOn ||
+		!bytes.Equal(svc.encryptionKey, encryptionKey)
 	svc.captureDir = svcConfig.CaptureDir
+	if svc.encryptionKey != nil && !bytes.Equal(svc.encryptionKey, encryptionKey) {
+		// Files that were encrypted with the replaced key may still be waiting to be synced.
+		svc.replacedKeys = appendKey(svc.replacedKeys, svc.encryptionKey)
+	}
+	svc.encryptionKey = encryptionKey
+	svc.syncOptions = svcConfig.SyncOptions
+	svc.syncOptions.Priority = syncPriority(svc.captureDir, allComponentAttributes)
+	svc.syncOptions.DecryptionKeys = nil
+	for _, key := range append(append([][]byte{encryptionKey}, previousKeys...), svc.replacedKeys...) {
+		if key != nil {
+			svc.syncOptions.DecryptionKeys = appendKey(svc.syncOptions.DecryptionKeys, key)
+		}
+	}
 
 	// Stop syncing if newly disabled in the config.
 	if toggledSyncOff {
@@ -539,16 +685,22 @@ func (svc *builtIn) Update(ctx context.Context, cfg *config.Config) error {
 			return err
 		}
 	} else if toggledSyncOn || (svcConfig.SyncIntervalMins != svc.syncIntervalMins) ||
-		!reflect.DeepEqual(svcConfig.AdditionalSyncPaths, svc.additionalSyncPaths) {
+		!reflect.DeepEqual(svcConfig.AdditionalSyncPaths, svc.additionalSyncPaths) ||
+		!reflect.DeepEqual(svcConfig.SyncDestination, svc.syncDestination) {
 		// If the sync config has changed, update the syncer.
 		svc.lock.Lock()
 		svc.additionalSyncPaths = svcConfig.AdditionalSyncPaths
 		svc.lock.Unlock()
 		svc.syncIntervalMins = svcConfig.SyncIntervalMins
+		svc.syncDestination = svcConfig.SyncDestination
 		if err := svc.initOrUpdateSyncer(ctx, svcConfig.SyncIntervalMins, cfg); err != nil {
 			return err
 		}
 	}
+	if svc.syncer != nil {
+		// Options and collectors can change without the syncer changing.
+		setSyncerOptions(svc.syncer, svc.syncOptions)
+	}
 
 	// Initialize or add a collector based on changes to the component configurations.
 	newCollectorMetadata := make(map[componentMethodMetadata]bool)
@@ -579,9 +731,30 @@ func (svc *builtIn) Update(ctx context.Context, cfg *config.Config) error {
 		}
 	}
 
+	svc.updateJanitor(svcConfig.Retention, allComponentAttributes)
 	return nil
 }
 
+// syncPriority returns the sync priority of each file, which is that of the collector whose directory it is in, or 0
+// for files that are not captured. When collectors with different method parameters share a directory, the highest
+// priority wins.
+func syncPriority(captureDir string, attrs []dataCaptureConfig) func(path string) int {
+	priorities := make(map[string]int)
+	for _, a := range attrs {
+		dir := filepath.Join(string(a.Type), a.Name, a.Method)
+		if p, ok := priorities[dir]; !ok || a.SyncPriority > p {
+			priorities[dir] = a.SyncPriority
+		}
+	}
+	return func(path string) int {
+		dir, err := filepath.Rel(captureDir, filepath.Dir(path))
+		if err != nil {
+			return 0
+		}
+		return priorities[dir]
+	}
+}
+
 func (svc *builtIn) uploadData(cancelCtx context.Context, intervalMins float64) {
 	svc.backgroundWorkers.Add(1)
 	goutils.PanicCapturingGo(func() {
@@ -682,6 +855,26 @@ func buildDataCaptureConfigs(cfg *config.Config) ([]dataCaptureConfig, error) {
 		}
 	}
 
+	for _, c := range cfg.Services {
+		// Iterate over all service-level service configs of type data_manager.
+		for _, serviceSvcConfig := range c.ServiceConfig {
+			if serviceSvcConfig.Type == datamanager.SubtypeName {
+				attrs, err := getAttrsFromServiceConfig(serviceSvcConfig)
+				if err != nil {
+					return componentDataCaptureConfigs, err
+				}
+
+				for _, attrs := range attrs.Attributes {
+					attrs.Name = c.Name
+					attrs.Model = c.Model
+					attrs.Type = resource.SubtypeName(c.Type)
+					attrs.ResourceType = resource.ResourceTypeService
+					componentDataCaptureConfigs = append(componentDataCaptureConfigs, attrs)
+				}
+			}
+		}
+	}
+
 	for _, r := range cfg.Remotes {
 		// Iterate over all remote-level service configs of type data_manager.
 		for _, resourceSvcConfig := range r.ServiceConfig {
@@ -698,6 +891,7 @@ func buildDataCaptureConfigs(cfg *config.Config) ([]dataCaptureConfig, error) {
 					}
 					attrs.Name = name.Name
 					attrs.Type = name.ResourceSubtype
+					attrs.ResourceType = name.ResourceType
 					attrs.RemoteRobotName = r.Name
 					componentDataCaptureConfigs = append(componentDataCaptureConfigs, attrs)
 				}
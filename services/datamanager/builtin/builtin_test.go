@@ -11,6 +11,7 @@ import (
 	"net/http"
 	"os"
 	"path/filepath"
+	"strings"
 	"sync"
 	"testing"
 	"time"
@@ -23,6 +24,7 @@ import (
 	m1 "go.viam.com/api/app/model/v1"
 	"go.viam.com/test"
 	"go.viam.com/utils/rpc"
+	"go.viam.com/utils/testutils"
 
 	"go.viam.com/rdk/components/arm"
 	"go.viam.com/rdk/components/camera"
@@ -151,6 +153,51 @@ func setupConfig(t *testing.T, relativePath string) *config.Config {
 	return testCfg
 }
 
+func TestBuildDataCaptureConfigs(t *testing.T) {
+	captureMethods := func(method string) config.AttributeMap {
+		return config.AttributeMap{
+			"capture_methods": []interface{}{map[string]interface{}{"method": method, "capture_frequency_hz": 1.0}},
+		}
+	}
+	sensorsService := config.Service{
+		Name:  "sensors1",
+		Type:  "sensors",
+		Model: "builtin",
+		ServiceConfig: []config.ResourceLevelServiceConfig{
+			{Type: "data_manager", Attributes: captureMethods("Readings")},
+			{Type: "some_other_service", Attributes: captureMethods("Ignored")},
+		},
+	}
+	// Services configured in the cloud carry their service configs through the proto.
+	proto, err := config.ServiceConfigToProto(&sensorsService)
+	test.That(t, err, test.ShouldBeNil)
+	fromCloud, err := config.ServiceConfigFromProto(proto)
+	test.That(t, err, test.ShouldBeNil)
+	cfg := &config.Config{
+		Components: []config.Component{{
+			Name:          "arm1",
+			Type:          "arm",
+			Model:         "fake",
+			ServiceConfig: []config.ResourceLevelServiceConfig{{Type: "data_manager", Attributes: captureMethods("EndPosition")}},
+		}},
+		Services: []config.Service{*fromCloud},
+	}
+
+	configs, err := buildDataCaptureConfigs(cfg)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, configs, test.ShouldHaveLength, 2)
+	test.That(t, configs[0].Name, test.ShouldEqual, "arm1")
+	test.That(t, configs[0].Type, test.ShouldEqual, resource.SubtypeName("arm"))
+	test.That(t, configs[0].ResourceType, test.ShouldEqual, resource.TypeName(""))
+	test.That(t, configs[0].Method, test.ShouldEqual, "EndPosition")
+	test.That(t, configs[1].Name, test.ShouldEqual, "sensors1")
+	test.That(t, configs[1].Model, test.ShouldEqual, "builtin")
+	test.That(t, configs[1].Type, test.ShouldEqual, resource.SubtypeName("sensors"))
+	test.That(t, configs[1].ResourceType, test.ShouldEqual, resource.ResourceTypeService)
+	test.That(t, configs[1].Method, test.ShouldEqual, "Readings")
+	test.That(t, configs[1].CaptureFrequencyHz, test.ShouldEqual, 1)
+}
+
 func TestNewDataManager(t *testing.T) {
 	dmsvc := newTestDataManager(t, "arm1", "")
 	testCfg := setupConfig(t, configPath)
@@ -745,6 +792,30 @@ func TestManualAndScheduledSync(t *testing.T) {
 	test.That(t, len(filesInArmDir), test.ShouldEqual, 1)
 }
 
+func TestSyncDestinationUsesSyncerConstructor(t *testing.T) {
+	testCfg := setupConfig(t, configPath)
+	dmCfg, err := getDataManagerConfig(testCfg)
+	test.That(t, err, test.ShouldBeNil)
+	dmCfg.SyncIntervalMins = syncIntervalMins
+	dmCfg.SyncDestination = datasync.DestinationConfig{Type: datasync.DestinationLocal, Path: t.TempDir()}
+
+	captureDir := "/tmp/capture"
+	resetFolder(t, captureDir)
+	defer resetFolder(t, captureDir)
+
+	// Destinations other than the cloud are built by the injected constructor too.
+	var destinations []datasync.DestinationConfig
+	dmsvc := newTestDataManager(t, "arm1", "")
+	dmsvc.SetSyncerConstructor(func(logger golog.Logger, cfg *config.Config, destCfg datasync.DestinationConfig) (datasync.Manager, error) {
+		destinations = append(destinations, destCfg)
+		return &uploadingSyncer{}, nil
+	})
+	err = dmsvc.Update(context.Background(), testCfg)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, destinations, test.ShouldResemble, []datasync.DestinationConfig{dmCfg.SyncDestination})
+	test.That(t, dmsvc.Close(context.Background()), test.ShouldBeNil)
+}
+
 func TestSyncEnabledThenDisabled(t *testing.T) {
 	// Register mock datasync service with a mock server.
 	rpcServer, mockService := buildAndStartLocalServer(t)
@@ -842,6 +913,95 @@ func TestGetDurationFromHz(t *testing.T) {
 	test.That(t, GetDurationFromHz(0), test.ShouldEqual, 0)
 }
 
+func TestSyncPriority(t *testing.T) {
+	priority := syncPriority("/capture", []dataCaptureConfig{
+		{Type: "arm", Name: "arm1", Method: "EndPosition", SyncPriority: 1},
+		{Type: "camera", Name: "c1", Method: "ReadImage", SyncPriority: 2},
+		{Type: "camera", Name: "c1", Method: "ReadImage", AdditionalParams: map[string]string{"mime_type": "image/png"}},
+	})
+	test.That(t, priority("/capture/arm/arm1/EndPosition/2022-11-01T12:00:00Z.capture"), test.ShouldEqual, 1)
+	test.That(t, priority("/capture/camera/c1/ReadImage/2022-11-01T12:00:00Z.capture"), test.ShouldEqual, 2)
+	test.That(t, priority("/additional/notes.txt"), test.ShouldEqual, 0)
+}
+
+func TestFileOptions(t *testing.T) {
+	svc := &builtIn{}
+	_, err := svc.fileOptions(dataCaptureConfig{Compression: datacapture.CompressionZstd})
+	test.That(t, err, test.ShouldBeNil)
+	_, err = svc.fileOptions(dataCaptureConfig{Compression: "lz4"})
+	test.That(t, err, test.ShouldNotBeNil)
+	_, err = svc.fileOptions(dataCaptureConfig{Name: "c1", Method: "ReadImage", Encrypt: true})
+	test.That(t, err, test.ShouldNotBeNil)
+
+	key, err := datacapture.ParseEncryptionKey(strings.Repeat("ab", datacapture.EncryptionKeySize))
+	test.That(t, err, test.ShouldBeNil)
+	svc.encryptionKey = key
+	opts, err := svc.fileOptions(dataCaptureConfig{Name: "c1", Method: "ReadImage", Encrypt: true})
+	test.That(t, err, test.ShouldBeNil)
+	f, err := datacapture.NewFile(t.TempDir(), &v1.DataCaptureMetadata{ComponentName: "c1"}, opts...)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, f.Close(), test.ShouldBeNil)
+	//nolint:gosec
+	file, err := os.Open(f.GetPath())
+	test.That(t, err, test.ShouldBeNil)
+	defer file.Close()
+	_, err = datacapture.ReadFile(file)
+	test.That(t, err, test.ShouldNotBeNil)
+}
+
+func TestEncryptionKeyRotation(t *testing.T) {
+	oldKey := strings.Repeat("ab", datacapture.EncryptionKeySize)
+	newKey := strings.Repeat("cd", datacapture.EncryptionKeySize)
+	testCfg := setupConfig(t, configPath)
+	dmCfg, err := getDataManagerConfig(testCfg)
+	test.That(t, err, test.ShouldBeNil)
+	captureDir := t.TempDir()
+	syncDir := t.TempDir()
+	dmCfg.CaptureDir = captureDir
+	dmCfg.CaptureDisabled = true
+	dmCfg.ScheduledSyncDisabled = true
+	dmCfg.SyncIntervalMins = syncIntervalMins
+	dmCfg.SyncDestination = datasync.DestinationConfig{Type: datasync.DestinationLocal, Path: syncDir}
+	dmCfg.EncryptionKey = oldKey
+
+	dmsvc := newTestDataManager(t, "arm1", "")
+	defer func() {
+		test.That(t, dmsvc.Close(context.Background()), test.ShouldBeNil)
+	}()
+	test.That(t, dmsvc.Update(context.Background(), testCfg), test.ShouldBeNil)
+
+	// A file encrypted with the old key is still waiting to be synced when the key is replaced.
+	key, err := datacapture.ParseEncryptionKey(oldKey)
+	test.That(t, err, test.ShouldBeNil)
+	md := &v1.DataCaptureMetadata{ComponentType: "arm", ComponentName: "arm1", MethodName: "EndPosition"}
+	pending, err := datacapture.NewFile(captureDir, md, datacapture.WithEncryption(key))
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, pending.WriteNext(&v1.SensorData{Data: &v1.SensorData_Binary{Binary: []byte("reading")}}), test.ShouldBeNil)
+	test.That(t, pending.Close(), test.ShouldBeNil)
+
+	dmCfg.EncryptionKey = newKey
+	dmCfg.ScheduledSyncDisabled = false
+	test.That(t, dmsvc.Update(context.Background(), testCfg), test.ShouldBeNil)
+
+	// The file is still decrypted with the key it was encrypted with when it is synced.
+	synced := filepath.Join(syncDir, "part_id", "arm", "arm1", "EndPosition", filepath.Base(pending.GetPath()))
+	testutils.WaitForAssertion(t, func(tb testing.TB) {
+		tb.Helper()
+		_, err := os.Stat(synced)
+		test.That(tb, err, test.ShouldBeNil)
+	})
+	//nolint:gosec
+	file, err := os.Open(synced)
+	test.That(t, err, test.ShouldBeNil)
+	defer file.Close()
+	read, err := datacapture.ReadFile(file)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, read.Encoded(), test.ShouldBeFalse)
+	reading, err := read.ReadNext()
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, reading.GetBinary(), test.ShouldResemble, []byte("reading"))
+}
+
 func TestAdditionalParamsInConfig(t *testing.T) {
 	conf := setupConfig(t, "services/datamanager/data/robot_with_cam_capture.json")
 	r := getInjectedRobotWithCamera(t)
@@ -1021,7 +1181,7 @@ func getLocalServerConn(rpcServer rpc.Server, logger golog.Logger) (rpc.ClientCo
 
 //nolint:thelper
 func getTestSyncerConstructor(t *testing.T, server rpc.Server) datasync.ManagerConstructor {
-	return func(logger golog.Logger, cfg *config.Config) (datasync.Manager, error) {
+	return func(logger golog.Logger, cfg *config.Config, destCfg datasync.DestinationConfig) (datasync.Manager, error) {
 		conn, err := getLocalServerConn(server, logger)
 		test.That(t, err, test.ShouldBeNil)
 		client := datasync.NewClient(conn)
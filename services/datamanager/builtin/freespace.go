@@ -0,0 +1,15 @@
+//go:build unix
+
+package builtin
+
+import "syscall"
+
+// freeSpace returns the bytes available to unprivileged users on the disk holding path.
+func freeSpace(path string) (uint64, error) {
+	var stat syscall.Statfs_t
+	if err := syscall.Statfs(path, &stat); err != nil {
+		return 0, err
+	}
+	//nolint:unconvert
+	return uint64(stat.Bavail) * uint64(stat.Bsize), nil
+}
@@ -0,0 +1,10 @@
+//go:build !unix
+
+package builtin
+
+import "github.com/pkg/errors"
+
+// freeSpace is only supported on unix systems, so max_free_space_percent cannot be used elsewhere.
+func freeSpace(path string) (uint64, error) {
+	return 0, errors.New("getting the free space of a disk is not supported on this platform")
+}
@@ -0,0 +1,407 @@
+package builtin
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/edaniels/golog"
+	"github.com/pkg/errors"
+	goutils "go.viam.com/utils"
+
+	"go.viam.com/rdk/data"
+	"go.viam.com/rdk/services/datamanager"
+	"go.viam.com/rdk/services/datamanager/datasync"
+)
+
+// RetentionPolicy is what the data manager does when the capture directory goes over its disk limits.
+type RetentionPolicy string
+
+// The retention policies. Files that collectors are still writing to or that sync is uploading are never deleted.
+const (
+	// RetentionPolicyDropOldest deletes the oldest capture files first.
+	RetentionPolicyDropOldest = RetentionPolicy("drop_oldest")
+	// RetentionPolicyDropLowestPriority deletes the capture files of the collectors with the lowest
+	// retention_priority first, oldest first within a priority.
+	RetentionPolicyDropLowestPriority = RetentionPolicy("drop_lowest_priority")
+	// RetentionPolicyPauseCapture deletes nothing and pauses all collectors until the capture directory is
+	// back under its limits, e.g. because sync uploaded and deleted files.
+	RetentionPolicyPauseCapture = RetentionPolicy("pause_capture")
+)
+
+const (
+	defaultRetentionCheckInterval = 30 * time.Second
+
+	evictedForAge   = "age"
+	evictedForQuota = "quota"
+)
+
+// RetentionConfig limits how much disk space the capture directory can use. Limits that are 0 are not
+// enforced, and the smallest limit applies when both are set.
+type RetentionConfig struct {
+	// MaxBytes is the most the capture directory can hold.
+	MaxBytes int64 `json:"max_bytes"`
+	// MaxFreeSpacePercent is the most the capture directory can hold as a percentage of the space available
+	// to it, which is the free space on its disk plus what it already holds.
+	MaxFreeSpacePercent float64         `json:"max_free_space_percent"`
+	Policy              RetentionPolicy `json:"policy"`
+	CheckIntervalSecs   float64         `json:"check_interval_secs"`
+}
+
+func (c RetentionConfig) validate() error {
+	switch c.Policy {
+	case "", RetentionPolicyDropOldest, RetentionPolicyDropLowestPriority, RetentionPolicyPauseCapture:
+	default:
+		return errors.Errorf("unknown retention policy %q", c.Policy)
+	}
+	if c.MaxBytes < 0 {
+		return errors.New("retention max_bytes cannot be negative")
+	}
+	if c.MaxFreeSpacePercent < 0 || c.MaxFreeSpacePercent > 100 {
+		return errors.New("retention max_free_space_percent should be between 0 and 100")
+	}
+	if c.CheckIntervalSecs < 0 {
+		return errors.New("retention check_interval_secs cannot be negative")
+	}
+	return nil
+}
+
+// limited returns whether there is anything for the janitor to enforce.
+func (c RetentionConfig) limited() bool {
+	return c.MaxBytes > 0 || c.MaxFreeSpacePercent > 0
+}
+
+// collectorRetention is the retention config of the collectors writing to one directory.
+type collectorRetention struct {
+	maxAge   time.Duration
+	priority int
+}
+
+// captureFile is a file in the capture directory that the janitor can delete.
+type captureFile struct {
+	path    string
+	dir     string
+	size    int64
+	modTime time.Time
+}
+
+// janitor enforces the retention config on the capture directory in the background.
+type janitor struct {
+	captureDir string
+	cfg        RetentionConfig
+	collectors map[string]collectorRetention
+	// active returns the collectors currently capturing, whose target files are never deleted.
+	active func() []data.Collector
+	// remove deletes a file unless sync is uploading it, and returns whether it did.
+	remove    func(path string) (bool, error)
+	freeSpace func(path string) (uint64, error)
+	logger    golog.Logger
+
+	metricsMu *sync.Mutex
+	metrics   *datamanager.EvictionMetrics
+
+	paused            bool
+	cancelFn          func()
+	backgroundWorkers sync.WaitGroup
+}
+
+func (j *janitor) start() {
+	interval := defaultRetentionCheckInterval
+	if j.cfg.CheckIntervalSecs > 0 {
+		interval = time.Duration(j.cfg.CheckIntervalSecs * float64(time.Second))
+	}
+	var cancelCtx context.Context
+	cancelCtx, j.cancelFn = context.WithCancel(context.Background())
+	j.backgroundWorkers.Add(1)
+	goutils.PanicCapturingGo(func() {
+		defer j.backgroundWorkers.Done()
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			if err := j.check(time.Now()); err != nil {
+				j.logger.Errorw("failed to enforce capture retention", "error", err)
+			}
+			select {
+			case <-cancelCtx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	})
+}
+
+// stop stops the janitor and resumes any collectors it paused.
+func (j *janitor) stop() {
+	j.cancelFn()
+	j.backgroundWorkers.Wait()
+	if j.paused {
+		j.setPaused(false)
+	}
+}
+
+// check deletes the files that are past the retention age of their collector and then enforces the disk
+// limits according to the policy.
+func (j *janitor) check(now time.Time) error {
+	activeTargets := make(map[string]bool)
+	collectors := j.active()
+	for _, c := range collectors {
+		if target := c.GetTarget(); target != nil {
+			activeTargets[target.GetPath()] = true
+		}
+	}
+
+	var used int64
+	var files []captureFile
+	//nolint
+	err := filepath.Walk(j.captureDir, func(path string, info os.FileInfo, err error) error {
+		if err != nil || info.IsDir() {
+			return nil
+		}
+		used += info.Size()
+		if activeTargets[path] {
+			return nil
+		}
+		dir, err := filepath.Rel(j.captureDir, filepath.Dir(path))
+		if err != nil {
+			return nil
+		}
+		files = append(files, captureFile{path: path, dir: dir, size: info.Size(), modTime: info.ModTime()})
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	remaining := files[:0]
+	for _, f := range files {
+		if maxAge := j.collectors[f.dir].maxAge; maxAge > 0 && now.Sub(f.modTime) > maxAge {
+			if j.evict(f, evictedForAge) {
+				used -= f.size
+				continue
+			}
+		}
+		remaining = append(remaining, f)
+	}
+
+	limit, err := j.limit(used)
+	if err != nil {
+		return err
+	}
+	if limit > 0 && used > limit {
+		switch j.cfg.Policy {
+		case RetentionPolicyPauseCapture:
+		case RetentionPolicyDropLowestPriority:
+			sort.SliceStable(remaining, func(a, b int) bool {
+				pa, pb := j.collectors[remaining[a].dir].priority, j.collectors[remaining[b].dir].priority
+				if pa != pb {
+					return pa < pb
+				}
+				return remaining[a].modTime.Before(remaining[b].modTime)
+			})
+			used = j.evictUntil(remaining, used, limit)
+		default:
+			sort.SliceStable(remaining, func(a, b int) bool {
+				return remaining[a].modTime.Before(remaining[b].modTime)
+			})
+			used = j.evictUntil(remaining, used, limit)
+		}
+		if used > limit && j.cfg.Policy != RetentionPolicyPauseCapture {
+			j.logger.Warnw("capture directory is over its limit but only holds files still being written",
+				"used_bytes", used, "limit_bytes", limit)
+		}
+	}
+
+	if j.cfg.Policy == RetentionPolicyPauseCapture {
+		paused := limit > 0 && used > limit
+		if paused != j.paused {
+			if paused {
+				j.logger.Warnw("pausing data capture until the capture directory is under its limit",
+					"used_bytes", used, "limit_bytes", limit)
+			} else {
+				j.logger.Info("resuming data capture")
+			}
+		}
+		j.paused = paused
+		// collectors created since the last check need pausing too
+		for _, c := range collectors {
+			c.SetPaused(paused)
+		}
+	}
+
+	j.metricsMu.Lock()
+	defer j.metricsMu.Unlock()
+	j.metrics.UsedBytes = used
+	j.metrics.LimitBytes = limit
+	j.metrics.CapturePaused = j.paused
+	j.metrics.LastCheck = now
+	return nil
+}
+
+// limit returns the most the capture directory can hold given that it holds used bytes now, or 0 for no
+// limit.
+func (j *janitor) limit(used int64) (int64, error) {
+	limit := j.cfg.MaxBytes
+	if j.cfg.MaxFreeSpacePercent > 0 {
+		free, err := j.freeSpace(j.captureDir)
+		if err != nil {
+			return 0, errors.Wrap(err, "failed to get the free space of the capture directory")
+		}
+		percentLimit := int64(j.cfg.MaxFreeSpacePercent / 100 * float64(int64(free)+used))
+		if limit == 0 || percentLimit < limit {
+			limit = percentLimit
+		}
+	}
+	return limit, nil
+}
+
+// evictUntil deletes files in order until used is at most limit and returns what is used after.
+func (j *janitor) evictUntil(files []captureFile, used, limit int64) int64 {
+	for _, f := range files {
+		if used <= limit {
+			break
+		}
+		if j.evict(f, evictedForQuota) {
+			used -= f.size
+		}
+	}
+	return used
+}
+
+// evict deletes the file, unless sync is uploading it, and records it in the metrics.
+func (j *janitor) evict(f captureFile, reason string) bool {
+	removed, err := j.remove(f.path)
+	if err != nil {
+		if !errors.Is(err, os.ErrNotExist) {
+			j.logger.Errorw("failed to delete capture file", "path", f.path, "error", err)
+		}
+		return false
+	}
+	if !removed {
+		j.logger.Debugw("not deleting capture file that is being uploaded", "path", f.path)
+		return false
+	}
+	j.logger.Debugw("deleted capture file", "path", f.path, "reason", reason, "bytes", f.size)
+
+	j.metricsMu.Lock()
+	defer j.metricsMu.Unlock()
+	countEviction(&j.metrics.Total, f.size)
+	byReason := j.metrics.ByReason[reason]
+	countEviction(&byReason, f.size)
+	j.metrics.ByReason[reason] = byReason
+	byCollector := j.metrics.ByCollector[f.dir]
+	countEviction(&byCollector, f.size)
+	j.metrics.ByCollector[f.dir] = byCollector
+	return true
+}
+
+func countEviction(c *datamanager.EvictionCount, size int64) {
+	c.Files++
+	c.Bytes += size
+}
+
+func (j *janitor) setPaused(paused bool) {
+	for _, c := range j.active() {
+		c.SetPaused(paused)
+	}
+}
+
+// buildCollectorRetention maps the capture directory of each collector to its retention config. When
+// collectors with different method parameters share a directory, the longest age and highest priority win.
+func buildCollectorRetention(attrs []dataCaptureConfig) map[string]collectorRetention {
+	retention := make(map[string]collectorRetention)
+	for _, a := range attrs {
+		dir := filepath.Join(string(a.Type), a.Name, a.Method)
+		r, ok := retention[dir]
+		maxAge := time.Duration(a.RetentionAgeMins * float64(time.Minute))
+		if !ok || maxAge == 0 || (r.maxAge != 0 && maxAge > r.maxAge) {
+			r.maxAge = maxAge
+		}
+		if !ok || a.RetentionPriority > r.priority {
+			r.priority = a.RetentionPriority
+		}
+		retention[dir] = r
+	}
+	return retention
+}
+
+// activeCollectors returns the collectors that are currently capturing.
+func (svc *builtIn) activeCollectors() []data.Collector {
+	svc.lock.Lock()
+	defer svc.lock.Unlock()
+	collectors := make([]data.Collector, 0, len(svc.collectors))
+	for _, c := range svc.collectors {
+		collectors = append(collectors, c.Collector)
+	}
+	return collectors
+}
+
+// removeCaptureFile deletes a file from the capture directory through the syncer, if there is one, so that a
+// file is never deleted while it is being uploaded.
+func (svc *builtIn) removeCaptureFile(path string) (bool, error) {
+	svc.lock.Lock()
+	defer svc.lock.Unlock()
+	if remover, ok := svc.syncer.(datasync.Remover); ok {
+		return remover.Remove(path)
+	}
+	return true, os.Remove(path)
+}
+
+// updateJanitor restarts the retention janitor with the current config, or stops it if there is nothing to
+// enforce.
+func (svc *builtIn) updateJanitor(cfg RetentionConfig, attrs []dataCaptureConfig) {
+	svc.stopJanitor()
+	collectors := buildCollectorRetention(attrs)
+	hasAges := false
+	for _, r := range collectors {
+		hasAges = hasAges || r.maxAge > 0
+	}
+	if !cfg.limited() && !hasAges {
+		return
+	}
+	j := &janitor{
+		captureDir: svc.captureDir,
+		cfg:        cfg,
+		collectors: collectors,
+		active:     svc.activeCollectors,
+		remove:     svc.removeCaptureFile,
+		freeSpace:  svc.freeSpace,
+		logger:     svc.logger,
+		metricsMu:  &svc.evictionsLock,
+		metrics:    &svc.evictions,
+	}
+	j.start()
+	svc.lock.Lock()
+	svc.janitor = j
+	svc.lock.Unlock()
+}
+
+// stopJanitor stops the janitor, if there is one. The janitor needs the lock to stop, so it is only held to take
+// the janitor.
+func (svc *builtIn) stopJanitor() {
+	svc.lock.Lock()
+	j := svc.janitor
+	svc.janitor = nil
+	svc.lock.Unlock()
+	if j != nil {
+		j.stop()
+	}
+}
+
+// EvictionMetrics returns what the retention janitor has deleted from the capture directory.
+func (svc *builtIn) EvictionMetrics(ctx context.Context) (datamanager.EvictionMetrics, error) {
+	svc.evictionsLock.Lock()
+	defer svc.evictionsLock.Unlock()
+	metrics := svc.evictions
+	metrics.ByReason = make(map[string]datamanager.EvictionCount, len(svc.evictions.ByReason))
+	for k, v := range svc.evictions.ByReason {
+		metrics.ByReason[k] = v
+	}
+	metrics.ByCollector = make(map[string]datamanager.EvictionCount, len(svc.evictions.ByCollector))
+	for k, v := range svc.evictions.ByCollector {
+		metrics.ByCollector[k] = v
+	}
+	return metrics, nil
+}
@@ -0,0 +1,288 @@
+package builtin
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/edaniels/golog"
+	v1 "go.viam.com/api/app/datasync/v1"
+	"go.viam.com/test"
+	"go.viam.com/utils/testutils"
+
+	"go.viam.com/rdk/data"
+	"go.viam.com/rdk/services/datamanager"
+	"go.viam.com/rdk/services/datamanager/datacapture"
+)
+
+// fakeCollector is a collector that only has a target, can be paused and counts its triggers.
+type fakeCollector struct {
+	mu       sync.Mutex
+	target   *datacapture.File
+	paused   bool
+	triggers int
+}
+
+func (c *fakeCollector) SetTarget(file *datacapture.File) {
+	c.target = file
+}
+
+func (c *fakeCollector) GetTarget() *datacapture.File {
+	return c.target
+}
+
+func (c *fakeCollector) SetPaused(paused bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.paused = paused
+}
+
+func (c *fakeCollector) isPaused() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.paused
+}
+
+func (c *fakeCollector) Close() {}
+
+func (c *fakeCollector) Collect() {}
+
+func (c *fakeCollector) Trigger() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.triggers++
+	return nil
+}
+
+func (c *fakeCollector) triggerCount() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.triggers
+}
+
+// writeCaptureFile writes a file of size bytes into the directory of the collector, last modified age ago.
+func writeCaptureFile(t *testing.T, captureDir, collectorDir, name string, size int, age time.Duration) string {
+	t.Helper()
+	dir := filepath.Join(captureDir, collectorDir)
+	test.That(t, os.MkdirAll(dir, 0o700), test.ShouldBeNil)
+	path := filepath.Join(dir, name+datacapture.FileExt)
+	test.That(t, os.WriteFile(path, make([]byte, size), 0o600), test.ShouldBeNil)
+	modTime := time.Now().Add(-age)
+	test.That(t, os.Chtimes(path, modTime, modTime), test.ShouldBeNil)
+	return path
+}
+
+func exists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
+
+func newTestJanitor(t *testing.T, captureDir string, cfg RetentionConfig, attrs []dataCaptureConfig, active ...data.Collector) *janitor {
+	t.Helper()
+	return &janitor{
+		captureDir: captureDir,
+		cfg:        cfg,
+		collectors: buildCollectorRetention(attrs),
+		active:     func() []data.Collector { return active },
+		remove: func(path string) (bool, error) {
+			return true, os.Remove(path)
+		},
+		freeSpace: freeSpace,
+		logger:    golog.NewTestLogger(t),
+		metricsMu: &sync.Mutex{},
+		metrics: &datamanager.EvictionMetrics{
+			ByReason:    make(map[string]datamanager.EvictionCount),
+			ByCollector: make(map[string]datamanager.EvictionCount),
+		},
+	}
+}
+
+func TestRetentionConfig(t *testing.T) {
+	test.That(t, RetentionConfig{}.validate(), test.ShouldBeNil)
+	test.That(t, RetentionConfig{Policy: RetentionPolicyPauseCapture, MaxBytes: 10}.validate(), test.ShouldBeNil)
+	for _, cfg := range []RetentionConfig{
+		{Policy: "drop_everything"},
+		{MaxBytes: -1},
+		{MaxFreeSpacePercent: 101},
+		{CheckIntervalSecs: -1},
+	} {
+		test.That(t, cfg.validate(), test.ShouldNotBeNil)
+	}
+
+	retention := buildCollectorRetention([]dataCaptureConfig{
+		{Type: "arm", Name: "arm1", Method: "EndPosition", RetentionAgeMins: 1, RetentionPriority: 1},
+		{Type: "arm", Name: "arm1", Method: "EndPosition", RetentionAgeMins: 2},
+		{Type: "camera", Name: "c1", Method: "ReadImage", RetentionAgeMins: 1},
+		{Type: "camera", Name: "c1", Method: "ReadImage"},
+	})
+	test.That(t, retention, test.ShouldResemble, map[string]collectorRetention{
+		filepath.Join("arm", "arm1", "EndPosition"): {maxAge: 2 * time.Minute, priority: 1},
+		filepath.Join("camera", "c1", "ReadImage"):  {},
+	})
+}
+
+func TestJanitorDropOldest(t *testing.T) {
+	captureDir := t.TempDir()
+	armDir := filepath.Join("arm", "arm1", "EndPosition")
+	oldest := writeCaptureFile(t, captureDir, armDir, "oldest", 100, 3*time.Hour)
+	older := writeCaptureFile(t, captureDir, armDir, "older", 100, 2*time.Hour)
+	newest := writeCaptureFile(t, captureDir, armDir, "newest", 100, time.Hour)
+
+	// the file being written to is the oldest of all but is never deleted
+	target, err := datacapture.NewFile(captureDir, &v1.DataCaptureMetadata{ComponentType: "arm", ComponentName: "arm1"})
+	test.That(t, err, test.ShouldBeNil)
+	defer target.Close()
+	test.That(t, os.Chtimes(target.GetPath(), time.Now().Add(-4*time.Hour), time.Now().Add(-4*time.Hour)), test.ShouldBeNil)
+
+	j := newTestJanitor(t, captureDir, RetentionConfig{MaxBytes: 200 + target.Size()}, nil, &fakeCollector{target: target})
+	test.That(t, j.check(time.Now()), test.ShouldBeNil)
+	test.That(t, exists(target.GetPath()), test.ShouldBeTrue)
+	test.That(t, exists(oldest), test.ShouldBeFalse)
+	test.That(t, exists(older), test.ShouldBeTrue)
+	test.That(t, exists(newest), test.ShouldBeTrue)
+
+	test.That(t, j.metrics.Total, test.ShouldResemble, datamanager.EvictionCount{Files: 1, Bytes: 100})
+	test.That(t, j.metrics.ByReason[evictedForQuota], test.ShouldResemble, datamanager.EvictionCount{Files: 1, Bytes: 100})
+	test.That(t, j.metrics.ByCollector[armDir], test.ShouldResemble, datamanager.EvictionCount{Files: 1, Bytes: 100})
+	test.That(t, j.metrics.UsedBytes, test.ShouldEqual, 200+target.Size())
+	test.That(t, j.metrics.LimitBytes, test.ShouldEqual, 200+target.Size())
+
+	// under the limit nothing else goes
+	test.That(t, j.check(time.Now()), test.ShouldBeNil)
+	test.That(t, exists(older), test.ShouldBeTrue)
+	test.That(t, j.metrics.Total.Files, test.ShouldEqual, 1)
+}
+
+// uploadingSyncer is a syncer that is uploading one file, which it refuses to remove.
+type uploadingSyncer struct {
+	uploading string
+}
+
+func (s *uploadingSyncer) Sync(paths []string) {}
+
+func (s *uploadingSyncer) Close() {}
+
+func (s *uploadingSyncer) Remove(path string) (bool, error) {
+	if path == s.uploading {
+		return false, nil
+	}
+	return true, os.Remove(path)
+}
+
+func TestJanitorSkipsUploads(t *testing.T) {
+	captureDir := t.TempDir()
+	armDir := filepath.Join("arm", "arm1", "EndPosition")
+	oldest := writeCaptureFile(t, captureDir, armDir, "oldest", 100, 3*time.Hour)
+	older := writeCaptureFile(t, captureDir, armDir, "older", 100, 2*time.Hour)
+	newest := writeCaptureFile(t, captureDir, armDir, "newest", 100, time.Hour)
+
+	// files are deleted through the syncer, which keeps the oldest file because it is uploading it
+	svc := &builtIn{syncer: &uploadingSyncer{uploading: oldest}}
+	j := newTestJanitor(t, captureDir, RetentionConfig{MaxBytes: 200}, nil)
+	j.remove = svc.removeCaptureFile
+	test.That(t, j.check(time.Now()), test.ShouldBeNil)
+	test.That(t, exists(oldest), test.ShouldBeTrue)
+	test.That(t, exists(older), test.ShouldBeFalse)
+	test.That(t, exists(newest), test.ShouldBeTrue)
+	test.That(t, j.metrics.Total, test.ShouldResemble, datamanager.EvictionCount{Files: 1, Bytes: 100})
+}
+
+func TestJanitorDropLowestPriority(t *testing.T) {
+	captureDir := t.TempDir()
+	armDir := filepath.Join("arm", "arm1", "EndPosition")
+	cameraDir := filepath.Join("camera", "c1", "ReadImage")
+	arm := writeCaptureFile(t, captureDir, armDir, "arm", 100, 3*time.Hour)
+	camera1 := writeCaptureFile(t, captureDir, cameraDir, "camera1", 100, time.Hour)
+	camera2 := writeCaptureFile(t, captureDir, cameraDir, "camera2", 100, 2*time.Hour)
+
+	attrs := []dataCaptureConfig{
+		{Type: "arm", Name: "arm1", Method: "EndPosition", RetentionPriority: 10},
+		{Type: "camera", Name: "c1", Method: "ReadImage", RetentionPriority: 1},
+	}
+	j := newTestJanitor(t, captureDir, RetentionConfig{MaxBytes: 150, Policy: RetentionPolicyDropLowestPriority}, attrs)
+	test.That(t, j.check(time.Now()), test.ShouldBeNil)
+	test.That(t, exists(arm), test.ShouldBeTrue)
+	test.That(t, exists(camera1), test.ShouldBeFalse)
+	test.That(t, exists(camera2), test.ShouldBeFalse)
+	test.That(t, j.metrics.ByCollector[cameraDir], test.ShouldResemble, datamanager.EvictionCount{Files: 2, Bytes: 200})
+}
+
+func TestJanitorRetentionAge(t *testing.T) {
+	captureDir := t.TempDir()
+	armDir := filepath.Join("arm", "arm1", "EndPosition")
+	cameraDir := filepath.Join("camera", "c1", "ReadImage")
+	oldArm := writeCaptureFile(t, captureDir, armDir, "old", 10, 2*time.Hour)
+	newArm := writeCaptureFile(t, captureDir, armDir, "new", 10, time.Minute)
+	oldCamera := writeCaptureFile(t, captureDir, cameraDir, "old", 10, 2*time.Hour)
+
+	attrs := []dataCaptureConfig{
+		{Type: "arm", Name: "arm1", Method: "EndPosition", RetentionAgeMins: 60},
+		{Type: "camera", Name: "c1", Method: "ReadImage"},
+	}
+	j := newTestJanitor(t, captureDir, RetentionConfig{}, attrs)
+	test.That(t, j.check(time.Now()), test.ShouldBeNil)
+	test.That(t, exists(oldArm), test.ShouldBeFalse)
+	test.That(t, exists(newArm), test.ShouldBeTrue)
+	test.That(t, exists(oldCamera), test.ShouldBeTrue)
+	test.That(t, j.metrics.ByReason[evictedForAge], test.ShouldResemble, datamanager.EvictionCount{Files: 1, Bytes: 10})
+	test.That(t, j.metrics.ByReason[evictedForQuota], test.ShouldResemble, datamanager.EvictionCount{})
+}
+
+func TestJanitorPauseCapture(t *testing.T) {
+	captureDir := t.TempDir()
+	armDir := filepath.Join("arm", "arm1", "EndPosition")
+	file := writeCaptureFile(t, captureDir, armDir, "file", 100, time.Hour)
+
+	// the capture directory can use half of the 150 bytes left to it
+	collector := &fakeCollector{}
+	j := newTestJanitor(t, captureDir, RetentionConfig{MaxFreeSpacePercent: 50, Policy: RetentionPolicyPauseCapture}, nil, collector)
+	j.freeSpace = func(path string) (uint64, error) {
+		return 50, nil
+	}
+	test.That(t, j.check(time.Now()), test.ShouldBeNil)
+	test.That(t, exists(file), test.ShouldBeTrue)
+	test.That(t, collector.isPaused(), test.ShouldBeTrue)
+	test.That(t, j.metrics.CapturePaused, test.ShouldBeTrue)
+	test.That(t, j.metrics.LimitBytes, test.ShouldEqual, 75)
+
+	// once sync deletes the file capture resumes
+	test.That(t, os.Remove(file), test.ShouldBeNil)
+	test.That(t, j.check(time.Now()), test.ShouldBeNil)
+	test.That(t, collector.isPaused(), test.ShouldBeFalse)
+	test.That(t, j.metrics.CapturePaused, test.ShouldBeFalse)
+	test.That(t, j.metrics.Total, test.ShouldResemble, datamanager.EvictionCount{})
+}
+
+func TestRetentionInDataManager(t *testing.T) {
+	tmpDir := t.TempDir()
+	armDir := filepath.Join("arm", "arm1", "EndPosition")
+	old := writeCaptureFile(t, tmpDir, armDir, "old", 1000, time.Hour)
+
+	dmsvc := newTestDataManager(t, "arm1", "")
+	testCfg := setupConfig(t, configPath)
+	svcConfig, ok, err := getServiceConfig(testCfg)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, ok, test.ShouldBeTrue)
+	svcConfig.ScheduledSyncDisabled = true
+	svcConfig.CaptureDir = tmpDir
+
+	svcConfig.Retention = RetentionConfig{Policy: "nope"}
+	test.That(t, dmsvc.Update(context.Background(), testCfg), test.ShouldNotBeNil)
+
+	svcConfig.Retention = RetentionConfig{MaxBytes: 500, CheckIntervalSecs: 0.05}
+	test.That(t, dmsvc.Update(context.Background(), testCfg), test.ShouldBeNil)
+	defer dmsvc.Close(context.Background())
+
+	testutils.WaitForAssertion(t, func(tb testing.TB) {
+		tb.Helper()
+		test.That(tb, exists(old), test.ShouldBeFalse)
+	})
+	metrics, err := dmsvc.(datamanager.EvictionReporter).EvictionMetrics(context.Background())
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, metrics.ByCollector[armDir], test.ShouldResemble, datamanager.EvictionCount{Files: 1, Bytes: 1000})
+	test.That(t, metrics.LimitBytes, test.ShouldEqual, 500)
+	test.That(t, len(getAllFiles(tmpDir)), test.ShouldBeGreaterThan, 0)
+}
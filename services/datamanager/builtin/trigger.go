@@ -0,0 +1,262 @@
+package builtin
+
+import (
+	"context"
+	"reflect"
+	"sync"
+	"time"
+
+	"github.com/edaniels/golog"
+	"github.com/pkg/errors"
+	goutils "go.viam.com/utils"
+
+	"go.viam.com/rdk/components/board"
+	"go.viam.com/rdk/components/sensor"
+	"go.viam.com/rdk/data"
+	"go.viam.com/rdk/services/vision"
+)
+
+// The types of trigger a capture method can have.
+const (
+	// triggerTypeThreshold triggers while a reading of a sensor is above or below a value.
+	triggerTypeThreshold = "threshold"
+	// triggerTypeDetection triggers while a vision service detects something with a label in a camera.
+	triggerTypeDetection = "detection"
+	// triggerTypeDigitalInterrupt triggers when a digital interrupt of a board ticks.
+	triggerTypeDigitalInterrupt = "digital_interrupt"
+	// triggerTypeAPI only triggers through TriggerCapture.
+	triggerTypeAPI = "api"
+
+	defaultTriggerCheckFrequencyHz = 10
+)
+
+// triggerConfig makes a capture method capture continuously but only save the readings from PreTriggerSecs
+// before each trigger to PostTriggerSecs after it. Triggers of every type can also be fired by name through
+// TriggerCapture.
+type triggerConfig struct {
+	Type             string  `json:"type"`
+	Name             string  `json:"name"`
+	PreTriggerSecs   float64 `json:"pre_trigger_secs"`
+	PostTriggerSecs  float64 `json:"post_trigger_secs"`
+	CheckFrequencyHz float64 `json:"check_frequency_hz"`
+
+	// Sensor, Reading, Above and Below configure threshold triggers. A trigger with both Above and Below
+	// fires when the reading is outside of them.
+	Sensor  string   `json:"sensor"`
+	Reading string   `json:"reading"`
+	Above   *float64 `json:"above"`
+	Below   *float64 `json:"below"`
+
+	// VisionService, Camera, Detector, Label and MinConfidence configure detection triggers.
+	VisionService string  `json:"vision_service"`
+	Camera        string  `json:"camera"`
+	Detector      string  `json:"detector"`
+	Label         string  `json:"label"`
+	MinConfidence float64 `json:"min_confidence"`
+
+	// Board and DigitalInterrupt configure digital interrupt triggers.
+	Board            string `json:"board"`
+	DigitalInterrupt string `json:"digital_interrupt"`
+}
+
+func (cfg *triggerConfig) validate() error {
+	if cfg.PreTriggerSecs < 0 || cfg.PostTriggerSecs < 0 {
+		return errors.New("trigger pre_trigger_secs and post_trigger_secs cannot be negative")
+	}
+	if cfg.CheckFrequencyHz < 0 {
+		return errors.New("trigger check_frequency_hz cannot be negative")
+	}
+	switch cfg.Type {
+	case triggerTypeThreshold:
+		if cfg.Sensor == "" || cfg.Reading == "" {
+			return errors.New("threshold triggers need a sensor and a reading")
+		}
+		if cfg.Above == nil && cfg.Below == nil {
+			return errors.New("threshold triggers need a value to be above or below")
+		}
+	case triggerTypeDetection:
+		if cfg.VisionService == "" || cfg.Camera == "" || cfg.Detector == "" || cfg.Label == "" {
+			return errors.New("detection triggers need a vision_service, camera, detector and label")
+		}
+	case triggerTypeDigitalInterrupt:
+		if cfg.Board == "" || cfg.DigitalInterrupt == "" {
+			return errors.New("digital interrupt triggers need a board and a digital_interrupt")
+		}
+	case triggerTypeAPI:
+		if cfg.Name == "" {
+			return errors.New("api triggers need a name")
+		}
+	default:
+		return errors.Errorf("unknown trigger type %q", cfg.Type)
+	}
+	return nil
+}
+
+func (cfg *triggerConfig) params() *data.TriggerParams {
+	return &data.TriggerParams{
+		PreTrigger:  time.Duration(cfg.PreTriggerSecs * float64(time.Second)),
+		PostTrigger: time.Duration(cfg.PostTriggerSecs * float64(time.Second)),
+	}
+}
+
+// triggerCondition is checked periodically and the collector is triggered every time it holds.
+type triggerCondition func(ctx context.Context) (bool, error)
+
+// triggerCondition returns the condition of the trigger, or nil if it is only fired through the API.
+func (svc *builtIn) triggerCondition(cfg *triggerConfig) (triggerCondition, error) {
+	switch cfg.Type {
+	case triggerTypeThreshold:
+		s, err := sensor.FromRobot(svc.r, cfg.Sensor)
+		if err != nil {
+			return nil, err
+		}
+		return thresholdCondition(s, cfg.Reading, cfg.Above, cfg.Below), nil
+	case triggerTypeDetection:
+		visionSvc, err := vision.FromRobot(svc.r, cfg.VisionService)
+		if err != nil {
+			return nil, err
+		}
+		return func(ctx context.Context) (bool, error) {
+			detections, err := visionSvc.DetectionsFromCamera(ctx, cfg.Camera, cfg.Detector, nil)
+			if err != nil {
+				return false, err
+			}
+			for _, d := range detections {
+				if d.Label() == cfg.Label && d.Score() >= cfg.MinConfidence {
+					return true, nil
+				}
+			}
+			return false, nil
+		}, nil
+	case triggerTypeDigitalInterrupt:
+		b, err := board.FromRobot(svc.r, cfg.Board)
+		if err != nil {
+			return nil, err
+		}
+		interrupt, ok := b.DigitalInterruptByName(cfg.DigitalInterrupt)
+		if !ok {
+			return nil, errors.Errorf("board %s has no digital interrupt named %s", cfg.Board, cfg.DigitalInterrupt)
+		}
+		return tickCondition(interrupt), nil
+	default:
+		return nil, nil
+	}
+}
+
+// thresholdCondition holds while the reading is above above or below below.
+func thresholdCondition(s sensor.Sensor, reading string, above, below *float64) triggerCondition {
+	return func(ctx context.Context) (bool, error) {
+		readings, err := s.Readings(ctx, nil)
+		if err != nil {
+			return false, err
+		}
+		value, ok := readings[reading]
+		if !ok {
+			return false, errors.Errorf("sensor has no reading %s", reading)
+		}
+		v, ok := toFloat(value)
+		if !ok {
+			return false, errors.Errorf("reading %s is a %T, not a number", reading, value)
+		}
+		return (above != nil && v > *above) || (below != nil && v < *below), nil
+	}
+}
+
+// tickCondition holds when the interrupt ticked since it was last checked. Its value is polled rather than
+// using a callback so that interrupts of remote boards work too.
+func tickCondition(interrupt board.DigitalInterrupt) triggerCondition {
+	var last int64
+	var started bool
+	return func(ctx context.Context) (bool, error) {
+		value, err := interrupt.Value(ctx, nil)
+		if err != nil {
+			return false, err
+		}
+		ticked := started && value != last
+		last, started = value, true
+		return ticked, nil
+	}
+}
+
+func toFloat(value interface{}) (float64, bool) {
+	v := reflect.ValueOf(value)
+	switch {
+	case v.CanFloat():
+		return v.Float(), true
+	case v.CanInt():
+		return float64(v.Int()), true
+	case v.CanUint():
+		return float64(v.Uint()), true
+	default:
+		return 0, false
+	}
+}
+
+// triggeredCollector triggers a collector whenever its condition holds, until it is closed.
+type triggeredCollector struct {
+	data.Collector
+	cancelFn          func()
+	backgroundWorkers sync.WaitGroup
+}
+
+func watchTrigger(collector data.Collector, condition triggerCondition, frequencyHz float64, logger golog.Logger) data.Collector {
+	if frequencyHz == 0 {
+		frequencyHz = defaultTriggerCheckFrequencyHz
+	}
+	cancelCtx, cancelFn := context.WithCancel(context.Background())
+	c := &triggeredCollector{Collector: collector, cancelFn: cancelFn}
+	c.backgroundWorkers.Add(1)
+	goutils.PanicCapturingGo(func() {
+		defer c.backgroundWorkers.Done()
+		ticker := time.NewTicker(time.Duration(float64(time.Second) / frequencyHz))
+		defer ticker.Stop()
+		for {
+			select {
+			case <-cancelCtx.Done():
+				return
+			case <-ticker.C:
+			}
+			triggered, err := condition(cancelCtx)
+			if err != nil {
+				if cancelCtx.Err() == nil {
+					logger.Errorw("failed to check capture trigger", "error", err)
+				}
+				continue
+			}
+			if !triggered {
+				continue
+			}
+			if err := collector.Trigger(); err != nil {
+				logger.Errorw("failed to write triggered capture", "error", err)
+			}
+		}
+	})
+	return c
+}
+
+// Close stops checking the trigger before closing the collector.
+func (c *triggeredCollector) Close() {
+	c.cancelFn()
+	c.backgroundWorkers.Wait()
+	c.Collector.Close()
+}
+
+// TriggerCapture triggers every capture method whose trigger has the given name.
+func (svc *builtIn) TriggerCapture(ctx context.Context, name string) error {
+	svc.lock.Lock()
+	defer svc.lock.Unlock()
+	found := false
+	for _, c := range svc.collectors {
+		if c.Attributes.Trigger == nil || c.Attributes.Trigger.Name != name {
+			continue
+		}
+		found = true
+		if err := c.Collector.Trigger(); err != nil {
+			return err
+		}
+	}
+	if !found {
+		return errors.Errorf("no capture methods have a trigger named %q", name)
+	}
+	return nil
+}
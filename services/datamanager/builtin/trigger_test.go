@@ -0,0 +1,238 @@
+package builtin
+
+import (
+	"context"
+	"image"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/edaniels/golog"
+	"go.uber.org/atomic"
+	"go.viam.com/test"
+	"go.viam.com/utils/testutils"
+
+	"go.viam.com/rdk/components/board"
+	"go.viam.com/rdk/components/sensor"
+	"go.viam.com/rdk/resource"
+	"go.viam.com/rdk/services/datamanager/datacapture"
+	"go.viam.com/rdk/services/vision"
+	"go.viam.com/rdk/testutils/inject"
+	rutils "go.viam.com/rdk/utils"
+	"go.viam.com/rdk/vision/objectdetection"
+)
+
+func floatPtr(f float64) *float64 {
+	return &f
+}
+
+func TestTriggerConfig(t *testing.T) {
+	for _, cfg := range []triggerConfig{
+		{Type: triggerTypeThreshold, Sensor: "s", Reading: "temp", Above: floatPtr(1)},
+		{Type: triggerTypeDetection, VisionService: "v", Camera: "c", Detector: "d", Label: "person"},
+		{Type: triggerTypeDigitalInterrupt, Board: "b", DigitalInterrupt: "i", PreTriggerSecs: 1, PostTriggerSecs: 2},
+		{Type: triggerTypeAPI, Name: "incident"},
+	} {
+		test.That(t, cfg.validate(), test.ShouldBeNil)
+	}
+	for _, cfg := range []triggerConfig{
+		{Type: "sometimes"},
+		{Type: triggerTypeThreshold, Sensor: "s", Reading: "temp"},
+		{Type: triggerTypeThreshold, Sensor: "s", Above: floatPtr(1)},
+		{Type: triggerTypeDetection, VisionService: "v", Camera: "c", Detector: "d"},
+		{Type: triggerTypeDigitalInterrupt, Board: "b"},
+		{Type: triggerTypeAPI},
+		{Type: triggerTypeAPI, Name: "incident", PreTriggerSecs: -1},
+		{Type: triggerTypeAPI, Name: "incident", CheckFrequencyHz: -1},
+	} {
+		test.That(t, cfg.validate(), test.ShouldNotBeNil)
+	}
+
+	params := (&triggerConfig{PreTriggerSecs: 1.5, PostTriggerSecs: 0.25}).params()
+	test.That(t, params.PreTrigger, test.ShouldEqual, 1500*time.Millisecond)
+	test.That(t, params.PostTrigger, test.ShouldEqual, 250*time.Millisecond)
+}
+
+func TestTriggerConditions(t *testing.T) {
+	ctx := context.Background()
+	temp := 20.0
+	injectSensor := &inject.Sensor{}
+	injectSensor.ReadingsFunc = func(ctx context.Context, extra map[string]interface{}) (map[string]interface{}, error) {
+		return map[string]interface{}{"temp": temp, "count": 3, "status": "ok"}, nil
+	}
+	label := "cat"
+	injectVision := &inject.VisionService{}
+	injectVision.DetectionsFromCameraFunc = func(
+		ctx context.Context, cameraName, detectorName string, extra map[string]interface{},
+	) ([]objectdetection.Detection, error) {
+		return []objectdetection.Detection{objectdetection.NewDetection(image.Rect(0, 0, 1, 1), 0.8, label)}, nil
+	}
+	interrupt, err := board.CreateDigitalInterrupt(board.DigitalInterruptConfig{Name: "i"})
+	test.That(t, err, test.ShouldBeNil)
+	injectBoard := &inject.Board{}
+	injectBoard.DigitalInterruptByNameFunc = func(name string) (board.DigitalInterrupt, bool) {
+		return interrupt, name == "i"
+	}
+
+	resources := map[resource.Name]interface{}{
+		sensor.Named("s"): injectSensor,
+		vision.Named("v"): injectVision,
+		board.Named("b"):  injectBoard,
+	}
+	r := &inject.Robot{}
+	r.ResourceByNameFunc = func(name resource.Name) (interface{}, error) {
+		res, ok := resources[name]
+		if !ok {
+			return nil, rutils.NewResourceNotFoundError(name)
+		}
+		return res, nil
+	}
+	svc := &builtIn{r: r}
+
+	t.Run("threshold", func(t *testing.T) {
+		condition, err := svc.triggerCondition(&triggerConfig{Type: triggerTypeThreshold, Sensor: "s", Reading: "temp", Above: floatPtr(30)})
+		test.That(t, err, test.ShouldBeNil)
+		triggered, err := condition(ctx)
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, triggered, test.ShouldBeFalse)
+		temp = 31
+		triggered, err = condition(ctx)
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, triggered, test.ShouldBeTrue)
+
+		// integer readings work too, and outside of above and below triggers
+		condition, err = svc.triggerCondition(&triggerConfig{
+			Type: triggerTypeThreshold, Sensor: "s", Reading: "count", Above: floatPtr(5), Below: floatPtr(4),
+		})
+		test.That(t, err, test.ShouldBeNil)
+		triggered, err = condition(ctx)
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, triggered, test.ShouldBeTrue)
+
+		for _, reading := range []string{"status", "humidity"} {
+			condition, err = svc.triggerCondition(&triggerConfig{Type: triggerTypeThreshold, Sensor: "s", Reading: reading, Above: floatPtr(1)})
+			test.That(t, err, test.ShouldBeNil)
+			_, err = condition(ctx)
+			test.That(t, err, test.ShouldNotBeNil)
+		}
+
+		_, err = svc.triggerCondition(&triggerConfig{Type: triggerTypeThreshold, Sensor: "nope", Reading: "temp", Above: floatPtr(1)})
+		test.That(t, err, test.ShouldNotBeNil)
+	})
+
+	t.Run("detection", func(t *testing.T) {
+		condition, err := svc.triggerCondition(&triggerConfig{
+			Type: triggerTypeDetection, VisionService: "v", Camera: "c", Detector: "d", Label: "person", MinConfidence: 0.5,
+		})
+		test.That(t, err, test.ShouldBeNil)
+		triggered, err := condition(ctx)
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, triggered, test.ShouldBeFalse)
+		label = "person"
+		triggered, err = condition(ctx)
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, triggered, test.ShouldBeTrue)
+
+		condition, err = svc.triggerCondition(&triggerConfig{
+			Type: triggerTypeDetection, VisionService: "v", Camera: "c", Detector: "d", Label: "person", MinConfidence: 0.9,
+		})
+		test.That(t, err, test.ShouldBeNil)
+		triggered, err = condition(ctx)
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, triggered, test.ShouldBeFalse)
+	})
+
+	t.Run("digital interrupt", func(t *testing.T) {
+		condition, err := svc.triggerCondition(&triggerConfig{Type: triggerTypeDigitalInterrupt, Board: "b", DigitalInterrupt: "i"})
+		test.That(t, err, test.ShouldBeNil)
+		triggered, err := condition(ctx)
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, triggered, test.ShouldBeFalse)
+		test.That(t, interrupt.Tick(ctx, true, 1), test.ShouldBeNil)
+		triggered, err = condition(ctx)
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, triggered, test.ShouldBeTrue)
+		triggered, err = condition(ctx)
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, triggered, test.ShouldBeFalse)
+
+		_, err = svc.triggerCondition(&triggerConfig{Type: triggerTypeDigitalInterrupt, Board: "b", DigitalInterrupt: "nope"})
+		test.That(t, err, test.ShouldNotBeNil)
+	})
+
+	t.Run("api", func(t *testing.T) {
+		condition, err := svc.triggerCondition(&triggerConfig{Type: triggerTypeAPI, Name: "incident"})
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, condition, test.ShouldBeNil)
+	})
+}
+
+func TestWatchTrigger(t *testing.T) {
+	collector := &fakeCollector{}
+	triggered := atomic.NewBool(false)
+	c := watchTrigger(collector, func(ctx context.Context) (bool, error) {
+		return triggered.Load(), nil
+	}, 100, golog.NewTestLogger(t))
+
+	time.Sleep(50 * time.Millisecond)
+	test.That(t, collector.triggerCount(), test.ShouldEqual, 0)
+	triggered.Store(true)
+	testutils.WaitForAssertion(t, func(tb testing.TB) {
+		tb.Helper()
+		test.That(tb, collector.triggerCount(), test.ShouldBeGreaterThan, 1)
+	})
+
+	// nothing is triggered once it is closed
+	c.Close()
+	count := collector.triggerCount()
+	time.Sleep(50 * time.Millisecond)
+	test.That(t, collector.triggerCount(), test.ShouldEqual, count)
+}
+
+func TestTriggeredCapture(t *testing.T) {
+	tmpDir := t.TempDir()
+	dmsvc := newTestDataManager(t, "arm1", "")
+	testCfg := setupConfig(t, configPath)
+	svcConfig, ok, err := getServiceConfig(testCfg)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, ok, test.ShouldBeTrue)
+	svcConfig.ScheduledSyncDisabled = true
+	svcConfig.CaptureDir = tmpDir
+	captureMethods := testCfg.Components[0].ServiceConfig[0].Attributes["capture_methods"].([]interface{})
+	captureMethods[0].(map[string]interface{})["trigger"] = map[string]interface{}{
+		"type":              "api",
+		"name":              "incident",
+		"pre_trigger_secs":  0.05,
+		"post_trigger_secs": 0.05,
+	}
+
+	test.That(t, dmsvc.Update(context.Background(), testCfg), test.ShouldBeNil)
+	time.Sleep(300 * time.Millisecond)
+
+	svc := dmsvc.(*builtIn)
+	test.That(t, svc.TriggerCapture(context.Background(), "nope"), test.ShouldNotBeNil)
+	test.That(t, svc.TriggerCapture(context.Background(), "incident"), test.ShouldBeNil)
+	time.Sleep(300 * time.Millisecond)
+	test.That(t, dmsvc.Close(context.Background()), test.ShouldBeNil)
+
+	// At 100Hz the 600ms of capture would be about 60 readings, of which only the 100ms around the trigger
+	// are written.
+	files := getAllFiles(tmpDir)
+	test.That(t, files, test.ShouldHaveLength, 1)
+	f, err := os.Open(filepath.Join(tmpDir, "arm", "arm1", "EndPosition", files[0].Name()))
+	test.That(t, err, test.ShouldBeNil)
+	defer f.Close()
+	captureFile, err := datacapture.ReadFile(f)
+	test.That(t, err, test.ShouldBeNil)
+	readings := 0
+	for {
+		if _, err := captureFile.ReadNext(); err != nil {
+			test.That(t, err, test.ShouldEqual, io.EOF)
+			break
+		}
+		readings++
+	}
+	test.That(t, readings, test.ShouldBeBetweenOrEqual, 3, 30)
+}
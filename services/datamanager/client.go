@@ -8,6 +8,13 @@ import (
 	pb "go.viam.com/api/service/datamanager/v1"
 	"go.viam.com/utils/protoutils"
 	"go.viam.com/utils/rpc"
+
+	"go.viam.com/rdk/components/generic"
+)
+
+var (
+	_ = CaptureTriggerer(&client{})
+	_ = EvictionReporter(&client{})
 )
 
 // client implements DataManagerServiceClient.
@@ -41,3 +48,20 @@ func (c *client) Sync(ctx context.Context, extra map[string]interface{}) error {
 	}
 	return nil
 }
+
+// TriggerCapture triggers the capture methods of the remote data manager whose trigger has the given name.
+func (c *client) TriggerCapture(ctx context.Context, name string) error {
+	cmd := map[string]interface{}{"command": TriggerCaptureCommand, "name": name}
+	_, err := generic.DoFromCommandService(ctx, c.conn, Subtype, c.name, cmd)
+	return err
+}
+
+// EvictionMetrics returns what the retention janitor of the remote data manager has deleted.
+func (c *client) EvictionMetrics(ctx context.Context) (EvictionMetrics, error) {
+	cmd := map[string]interface{}{"command": EvictionMetricsCommand}
+	resp, err := generic.DoFromCommandService(ctx, c.conn, Subtype, c.name, cmd)
+	if err != nil {
+		return EvictionMetrics{}, err
+	}
+	return evictionMetricsFromCommand(resp)
+}
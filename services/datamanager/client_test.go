@@ -4,6 +4,7 @@ import (
 	"context"
 	"net"
 	"testing"
+	"time"
 
 	"github.com/edaniels/golog"
 	"github.com/pkg/errors"
@@ -69,6 +70,37 @@ func TestClient(t *testing.T) {
 		err = client.Sync(context.Background(), extra)
 		test.That(t, err, test.ShouldBeNil)
 		test.That(t, extraOptions, test.ShouldResemble, extra)
+
+		var triggered string
+		injectDS.TriggerCaptureFunc = func(ctx context.Context, name string) error {
+			triggered = name
+			return nil
+		}
+		triggerer, ok := client.(datamanager.CaptureTriggerer)
+		test.That(t, ok, test.ShouldBeTrue)
+		test.That(t, triggerer.TriggerCapture(context.Background(), "door_opened"), test.ShouldBeNil)
+		test.That(t, triggered, test.ShouldEqual, "door_opened")
+
+		lastCheck := time.Date(2022, 11, 30, 12, 0, 0, 500, time.UTC)
+		metrics := datamanager.EvictionMetrics{
+			Total:         datamanager.EvictionCount{Files: 3, Bytes: 3000},
+			ByReason:      map[string]datamanager.EvictionCount{"age": {Files: 1, Bytes: 1000}, "quota": {Files: 2, Bytes: 2000}},
+			ByCollector:   map[string]datamanager.EvictionCount{"arm/arm1/EndPosition": {Files: 3, Bytes: 3000}},
+			UsedBytes:     400,
+			LimitBytes:    500,
+			CapturePaused: true,
+			LastCheck:     lastCheck,
+		}
+		injectDS.EvictionMetricsFunc = func(ctx context.Context) (datamanager.EvictionMetrics, error) {
+			return metrics, nil
+		}
+		reporter, ok := client.(datamanager.EvictionReporter)
+		test.That(t, ok, test.ShouldBeTrue)
+		received, err := reporter.EvictionMetrics(context.Background())
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, received.LastCheck.Equal(lastCheck), test.ShouldBeTrue)
+		received.LastCheck = lastCheck
+		test.That(t, received, test.ShouldResemble, metrics)
 		test.That(t, utils.TryClose(context.Background(), client), test.ShouldBeNil)
 		test.That(t, conn.Close(), test.ShouldBeNil)
 	})
@@ -88,6 +120,20 @@ func TestClient(t *testing.T) {
 
 		err = client2.Sync(context.Background(), map[string]interface{}{})
 		test.That(t, err.Error(), test.ShouldContainSubstring, passedErr.Error())
+
+		injectDS.TriggerCaptureFunc = func(ctx context.Context, name string) error {
+			return errors.Errorf("no capture methods have a trigger named %q", name)
+		}
+		err = client2.(datamanager.CaptureTriggerer).TriggerCapture(context.Background(), "nope")
+		test.That(t, err, test.ShouldNotBeNil)
+		test.That(t, err.Error(), test.ShouldContainSubstring, "nope")
+
+		injectDS.EvictionMetricsFunc = func(ctx context.Context) (datamanager.EvictionMetrics, error) {
+			return datamanager.EvictionMetrics{}, errors.New("no retention janitor")
+		}
+		_, err = client2.(datamanager.EvictionReporter).EvictionMetrics(context.Background())
+		test.That(t, err, test.ShouldNotBeNil)
+		test.That(t, err.Error(), test.ShouldContainSubstring, "no retention janitor")
 		test.That(t, utils.TryClose(context.Background(), client), test.ShouldBeNil)
 		test.That(t, conn.Close(), test.ShouldBeNil)
 	})
@@ -0,0 +1,125 @@
+package datamanager
+
+import (
+	"context"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"go.viam.com/rdk/components/generic"
+	"go.viam.com/rdk/subtype"
+)
+
+// Commands that carry the parts of the data manager the API has no messages for. The client sends them through
+// the command service of the subtype.
+const (
+	// TriggerCaptureCommand triggers the capture methods whose trigger has the "name".
+	TriggerCaptureCommand = "trigger_capture"
+	// EvictionMetricsCommand returns the eviction metrics of the retention janitor. Each count is a map of
+	// "files" and "bytes", and "last_check" is in RFC 3339 format.
+	EvictionMetricsCommand = "eviction_metrics"
+)
+
+// commandHandler passes commands sent to the command service of the subtype on to the named service.
+func commandHandler(subtypeSvc subtype.Service) generic.CommandHandler {
+	server := &subtypeServer{subtypeSvc: subtypeSvc}
+	return func(ctx context.Context, name string, cmd map[string]interface{}) (map[string]interface{}, error) {
+		svc, err := server.service(name)
+		if err != nil {
+			return nil, err
+		}
+		return doCommand(ctx, svc, cmd)
+	}
+}
+
+func doCommand(ctx context.Context, svc Service, cmd map[string]interface{}) (map[string]interface{}, error) {
+	name, ok := cmd["command"]
+	if !ok {
+		return nil, errors.New("missing 'command' value")
+	}
+	switch name {
+	case TriggerCaptureCommand:
+		triggerer, ok := svc.(CaptureTriggerer)
+		if !ok {
+			return nil, errors.New("data manager cannot trigger captures")
+		}
+		triggerName, ok := cmd["name"].(string)
+		if !ok {
+			return nil, errors.New("need the name of a trigger")
+		}
+		return map[string]interface{}{}, triggerer.TriggerCapture(ctx, triggerName)
+	case EvictionMetricsCommand:
+		reporter, ok := svc.(EvictionReporter)
+		if !ok {
+			return nil, errors.New("data manager cannot report evictions")
+		}
+		metrics, err := reporter.EvictionMetrics(ctx)
+		if err != nil {
+			return nil, err
+		}
+		return evictionMetricsToCommand(metrics), nil
+	default:
+		return nil, errors.Errorf("no such command: %s", name)
+	}
+}
+
+func evictionMetricsToCommand(metrics EvictionMetrics) map[string]interface{} {
+	countsToCommand := func(counts map[string]EvictionCount) map[string]interface{} {
+		encoded := make(map[string]interface{}, len(counts))
+		for k, count := range counts {
+			encoded[k] = evictionCountToCommand(count)
+		}
+		return encoded
+	}
+	encoded := map[string]interface{}{
+		"total":          evictionCountToCommand(metrics.Total),
+		"by_reason":      countsToCommand(metrics.ByReason),
+		"by_collector":   countsToCommand(metrics.ByCollector),
+		"used_bytes":     metrics.UsedBytes,
+		"limit_bytes":    metrics.LimitBytes,
+		"capture_paused": metrics.CapturePaused,
+	}
+	if !metrics.LastCheck.IsZero() {
+		encoded["last_check"] = metrics.LastCheck.Format(time.RFC3339Nano)
+	}
+	return encoded
+}
+
+func evictionMetricsFromCommand(encoded map[string]interface{}) (EvictionMetrics, error) {
+	countsFromCommand := func(raw interface{}) map[string]EvictionCount {
+		counts := make(map[string]EvictionCount)
+		encodedCounts, _ := raw.(map[string]interface{})
+		for k, count := range encodedCounts {
+			counts[k] = evictionCountFromCommand(count)
+		}
+		return counts
+	}
+	metrics := EvictionMetrics{
+		Total:       evictionCountFromCommand(encoded["total"]),
+		ByReason:    countsFromCommand(encoded["by_reason"]),
+		ByCollector: countsFromCommand(encoded["by_collector"]),
+	}
+	used, _ := encoded["used_bytes"].(float64)
+	limit, _ := encoded["limit_bytes"].(float64)
+	metrics.UsedBytes = int64(used)
+	metrics.LimitBytes = int64(limit)
+	metrics.CapturePaused, _ = encoded["capture_paused"].(bool)
+	if lastCheck, ok := encoded["last_check"].(string); ok {
+		var err error
+		if metrics.LastCheck, err = time.Parse(time.RFC3339Nano, lastCheck); err != nil {
+			return EvictionMetrics{}, errors.Wrap(err, "last_check")
+		}
+	}
+	return metrics, nil
+}
+
+func evictionCountToCommand(count EvictionCount) map[string]interface{} {
+	return map[string]interface{}{"files": count.Files, "bytes": count.Bytes}
+}
+
+func evictionCountFromCommand(raw interface{}) EvictionCount {
+	encoded, _ := raw.(map[string]interface{})
+	files, _ := encoded["files"].(float64)
+	bytes, _ := encoded["bytes"].(float64)
+	return EvictionCount{Files: int64(files), Bytes: int64(bytes)}
+}
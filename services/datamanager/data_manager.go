@@ -4,6 +4,7 @@ package datamanager
 import (
 	"context"
 	"sync"
+	"time"
 
 	"github.com/edaniels/golog"
 	"github.com/pkg/errors"
@@ -11,6 +12,7 @@ import (
 	goutils "go.viam.com/utils"
 	"go.viam.com/utils/rpc"
 
+	"go.viam.com/rdk/components/generic"
 	"go.viam.com/rdk/config"
 	"go.viam.com/rdk/registry"
 	"go.viam.com/rdk/resource"
@@ -22,12 +24,15 @@ import (
 func init() {
 	registry.RegisterResourceSubtype(Subtype, registry.ResourceSubtype{
 		RegisterRPCService: func(ctx context.Context, rpcServer rpc.Server, subtypeSvc subtype.Service) error {
-			return rpcServer.RegisterServiceServer(
+			if err := rpcServer.RegisterServiceServer(
 				ctx,
 				&servicepb.DataManagerService_ServiceDesc,
 				NewServer(subtypeSvc),
 				servicepb.RegisterDataManagerServiceHandlerFromEndpoint,
-			)
+			); err != nil {
+				return err
+			}
+			return generic.RegisterCommandService(ctx, rpcServer, Subtype, commandHandler(subtypeSvc))
 		},
 		RPCServiceDesc: &servicepb.DataManagerService_ServiceDesc,
 		RPCClient: func(ctx context.Context, conn rpc.ClientConn, name string, logger golog.Logger) interface{} {
@@ -43,8 +48,44 @@ type Service interface {
 	Sync(ctx context.Context, extra map[string]interface{}) error
 }
 
+// CaptureTriggerer is a data manager whose triggered capture methods can be triggered by name. Clients of a
+// remote data manager are CaptureTriggerers too.
+type CaptureTriggerer interface {
+	TriggerCapture(ctx context.Context, name string) error
+}
+
+// EvictionReporter is a data manager that reports what its retention janitor has deleted from the capture
+// directory. Clients of a remote data manager are EvictionReporters too.
+type EvictionReporter interface {
+	EvictionMetrics(ctx context.Context) (EvictionMetrics, error)
+}
+
+// EvictionCount counts capture files deleted by the retention janitor of a data manager.
+type EvictionCount struct {
+	Files int64
+	Bytes int64
+}
+
+// EvictionMetrics describes what the retention janitor has deleted since the data manager started, and the
+// state of the capture directory as of its last check.
+type EvictionMetrics struct {
+	Total EvictionCount
+	// ByReason breaks the total down into files that were older than their collector's retention age ("age")
+	// and files deleted to get under the disk limits ("quota").
+	ByReason map[string]EvictionCount
+	// ByCollector breaks the total down by the capture directory of the collector, which is
+	// <component type>/<component name>/<method>.
+	ByCollector   map[string]EvictionCount
+	UsedBytes     int64
+	LimitBytes    int64
+	CapturePaused bool
+	LastCheck     time.Time
+}
+
 var (
 	_ = Service(&reconfigurableDataManager{})
+	_ = CaptureTriggerer(&reconfigurableDataManager{})
+	_ = EvictionReporter(&reconfigurableDataManager{})
 	_ = resource.Reconfigurable(&reconfigurableDataManager{})
 	_ = goutils.ContextCloser(&reconfigurableDataManager{})
 )
@@ -112,6 +153,26 @@ func (svc *reconfigurableDataManager) Sync(ctx context.Context, extra map[string
 	return svc.actual.Sync(ctx, extra)
 }
 
+func (svc *reconfigurableDataManager) TriggerCapture(ctx context.Context, name string) error {
+	svc.mu.RLock()
+	defer svc.mu.RUnlock()
+	triggerer, ok := svc.actual.(CaptureTriggerer)
+	if !ok {
+		return errors.New("reconfigurable datamanager is not a CaptureTriggerer")
+	}
+	return triggerer.TriggerCapture(ctx, name)
+}
+
+func (svc *reconfigurableDataManager) EvictionMetrics(ctx context.Context) (EvictionMetrics, error) {
+	svc.mu.RLock()
+	defer svc.mu.RUnlock()
+	reporter, ok := svc.actual.(EvictionReporter)
+	if !ok {
+		return EvictionMetrics{}, errors.New("reconfigurable datamanager is not an EvictionReporter")
+	}
+	return reporter.EvictionMetrics(ctx)
+}
+
 func (svc *reconfigurableDataManager) Close(ctx context.Context) error {
 	svc.mu.RLock()
 	defer svc.mu.RUnlock()
@@ -86,3 +86,27 @@ func (m *mock) Close(_ context.Context) error {
 	m.reconfCount++
 	return nil
 }
+
+type triggeringMock struct {
+	mock
+	triggered []string
+}
+
+func (m *triggeringMock) TriggerCapture(_ context.Context, name string) error {
+	m.triggered = append(m.triggered, name)
+	return nil
+}
+
+func TestTriggerCapture(t *testing.T) {
+	reconfSvc1, err := datamanager.WrapWithReconfigurable(&mock{name: testSvcName1}, resource.Name{})
+	test.That(t, err, test.ShouldBeNil)
+	err = reconfSvc1.(datamanager.CaptureTriggerer).TriggerCapture(context.Background(), "incident")
+	test.That(t, err, test.ShouldNotBeNil)
+
+	actualSvc2 := &triggeringMock{mock: mock{name: testSvcName2}}
+	reconfSvc2, err := datamanager.WrapWithReconfigurable(actualSvc2, resource.Name{})
+	test.That(t, err, test.ShouldBeNil)
+	err = reconfSvc2.(datamanager.CaptureTriggerer).TriggerCapture(context.Background(), "incident")
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, actualSvc2.triggered, test.ShouldResemble, []string{"incident"})
+}
@@ -4,6 +4,7 @@ package datacapture
 import (
 	"bufio"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
 	"sync"
@@ -12,6 +13,7 @@ import (
 	"github.com/matttproud/golang_protobuf_extensions/pbutil"
 	"github.com/pkg/errors"
 	v1 "go.viam.com/api/app/datasync/v1"
+	"google.golang.org/protobuf/proto"
 
 	"go.viam.com/rdk/protoutils"
 	"go.viam.com/rdk/resource"
@@ -25,22 +27,37 @@ const (
 	FileExt        = ".capture"
 	readImage      = "ReadImage"
 	nextPointCloud = "NextPointCloud"
+	audioChunks    = "Chunks"
 )
 
 // File is the data structure containing data captured by collectors. It is backed by a file on disk containing
 // length delimited protobuf messages, where the first message is the CaptureMetadata for the file, and ensuing
-// messages contain the captured data.
+// messages contain the captured data. Files may be compressed and encrypted, in which case each message is
+// compressed and encrypted on its own, and they are read the same as other files given the key they were encrypted
+// with.
 type File struct {
 	path     string
 	lock     *sync.Mutex
-	file     *os.File
+	file     ReadableFile
 	writer   *bufio.Writer
 	size     int64
 	metadata *v1.DataCaptureMetadata
+	// codec is nil for files that are neither compressed nor encrypted.
+	codec *codec
 }
 
-// ReadFile creates a File struct from a passed os.File previously constructed using NewFile.
-func ReadFile(f *os.File) (*File, error) {
+// ReadableFile is what a File is read from, such as an os.File or the contents of a file that were decoded in
+// memory.
+type ReadableFile interface {
+	io.ReadSeeker
+	io.Closer
+	Name() string
+	Stat() (os.FileInfo, error)
+}
+
+// ReadFile creates a File struct from a passed file previously constructed using NewFile. Encrypted files need
+// WithDecryptionKey to be read.
+func ReadFile(f ReadableFile, opts ...FileOption) (*File, error) {
 	if !IsDataCaptureFile(f) {
 		return nil, errors.Errorf("%s is not a data capture file", f.Name())
 	}
@@ -49,25 +66,47 @@ func ReadFile(f *os.File) (*File, error) {
 		return nil, err
 	}
 
-	md := &v1.DataCaptureMetadata{}
-	if _, err := pbutil.ReadDelimited(f, md); err != nil {
-		return nil, errors.Wrapf(err, fmt.Sprintf("failed to read DataCaptureMetadata from %s", f.Name()))
+	var options fileOptions
+	for _, opt := range opts {
+		opt(&options)
+	}
+	c, err := readCodec(f, options)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to read %s", f.Name())
 	}
 
+	w, ok := f.(io.Writer)
+	if !ok {
+		w = readOnlyWriter{}
+	}
 	ret := File{
 		path:     f.Name(),
 		lock:     &sync.Mutex{},
 		file:     f,
-		writer:   bufio.NewWriter(f),
+		writer:   bufio.NewWriter(w),
 		size:     finfo.Size(),
-		metadata: md,
+		metadata: &v1.DataCaptureMetadata{},
+		codec:    c,
+	}
+	if err := ret.readMessage(ret.metadata); err != nil {
+		return nil, errors.Wrapf(err, fmt.Sprintf("failed to read DataCaptureMetadata from %s", f.Name()))
 	}
 
 	return &ret, nil
 }
 
-// NewFile creates a new File with the specified md in the specified directory.
-func NewFile(captureDir string, md *v1.DataCaptureMetadata) (*File, error) {
+// NewFile creates a new File with the specified md in the specified directory, compressed and encrypted as
+// configured by opts.
+func NewFile(captureDir string, md *v1.DataCaptureMetadata, opts ...FileOption) (*File, error) {
+	var options fileOptions
+	for _, opt := range opts {
+		opt(&options)
+	}
+	c, err := newCodec(options)
+	if err != nil {
+		return nil, err
+	}
+
 	// First create directories and the file in it.
 	fileDir := filepath.Join(captureDir, md.GetComponentType(), md.GetComponentName(), md.GetMethodName())
 	if err := os.MkdirAll(fileDir, 0o700); err != nil {
@@ -80,18 +119,27 @@ func NewFile(captureDir string, md *v1.DataCaptureMetadata) (*File, error) {
 		return nil, err
 	}
 
-	// Then write first metadata message to the file.
-	n, err := pbutil.WriteDelimited(f, md)
-	if err != nil {
-		return nil, err
-	}
-	return &File{
+	ret := &File{
 		path:   f.Name(),
 		writer: bufio.NewWriter(f),
 		file:   f,
-		size:   int64(n),
 		lock:   &sync.Mutex{},
-	}, nil
+		codec:  c,
+	}
+	// Then write the header of encoded files and the first metadata message to the file.
+	if c != nil {
+		n, err := f.Write(c.header())
+		ret.size += int64(n)
+		if err != nil {
+			return nil, err
+		}
+	}
+	n, err := ret.writeMessage(f, md)
+	ret.size += int64(n)
+	if err != nil {
+		return nil, err
+	}
+	return ret, nil
 }
 
 // ReadMetadata reads and returns the metadata in f.
@@ -105,7 +153,7 @@ func (f *File) ReadNext() (*v1.SensorData, error) {
 	defer f.lock.Unlock()
 
 	r := v1.SensorData{}
-	if _, err := pbutil.ReadDelimited(f.file, &r); err != nil {
+	if err := f.readMessage(&r); err != nil {
 		return nil, err
 	}
 
@@ -117,7 +165,7 @@ func (f *File) WriteNext(data *v1.SensorData) error {
 	f.lock.Lock()
 	defer f.lock.Unlock()
 
-	n, err := pbutil.WriteDelimited(f.writer, data)
+	n, err := f.writeMessage(f.writer, data)
 	if err != nil {
 		return err
 	}
@@ -125,6 +173,79 @@ func (f *File) WriteNext(data *v1.SensorData) error {
 	return nil
 }
 
+// WriteDecoded writes the metadata and the readings of f that have not been read yet to w, neither compressed nor
+// encrypted. Readings are written as they were marshaled when they were captured, so f is decoded to the same bytes
+// every time.
+func (f *File) WriteDecoded(w io.Writer) error {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	md, err := proto.MarshalOptions{Deterministic: true}.Marshal(f.metadata)
+	if err != nil {
+		return err
+	}
+	if _, err := writeDelimited(w, md); err != nil {
+		return err
+	}
+	for {
+		b, err := f.readMessageBytes()
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+			return err
+		}
+		if _, err := writeDelimited(w, b); err != nil {
+			return err
+		}
+	}
+}
+
+// Encoded returns whether f is compressed or encrypted.
+func (f *File) Encoded() bool {
+	return f.codec != nil
+}
+
+// message is a protobuf message that works with both pbutil and proto, as generated messages do.
+type message interface {
+	proto.Message
+	Reset()
+	String() string
+	ProtoMessage()
+}
+
+func (f *File) readMessage(m message) error {
+	if f.codec == nil {
+		_, err := pbutil.ReadDelimited(f.file, m)
+		return err
+	}
+	b, err := readDelimited(f.file)
+	if err != nil {
+		return err
+	}
+	return f.codec.decode(b, m)
+}
+
+// readMessageBytes reads the next message of f marshaled, but neither compressed nor encrypted.
+func (f *File) readMessageBytes() ([]byte, error) {
+	b, err := readDelimited(f.file)
+	if err != nil || f.codec == nil {
+		return b, err
+	}
+	return f.codec.open(b)
+}
+
+func (f *File) writeMessage(w io.Writer, m message) (int, error) {
+	if f.codec == nil {
+		return pbutil.WriteDelimited(w, m)
+	}
+	b, err := f.codec.encode(m)
+	if err != nil {
+		return 0, err
+	}
+	return writeDelimited(w, b)
+}
+
 // Sync flushes any buffered writes to disk.
 func (f *File) Sync() error {
 	f.lock.Lock()
@@ -187,8 +308,15 @@ func BuildCaptureMetadata(compType resource.SubtypeName, compName, compModel, me
 	}, nil
 }
 
+// readOnlyWriter is the writer of files that are read from something that cannot be written to.
+type readOnlyWriter struct{}
+
+func (readOnlyWriter) Write(p []byte) (int, error) {
+	return 0, errors.New("data capture file is read only")
+}
+
 // IsDataCaptureFile returns whether or not f is a data capture file.
-func IsDataCaptureFile(f *os.File) bool {
+func IsDataCaptureFile(f interface{ Name() string }) bool {
 	return filepath.Ext(f.Name()) == FileExt
 }
 
@@ -201,7 +329,7 @@ func getFileTimestampName() string {
 // TODO DATA-246: Implement this in some more robust, programmatic way.
 func getDataType(methodName string) v1.DataType {
 	switch methodName {
-	case nextPointCloud, readImage:
+	case nextPointCloud, readImage, audioChunks:
 		return v1.DataType_DATA_TYPE_BINARY_SENSOR
 	default:
 		return v1.DataType_DATA_TYPE_TABULAR_SENSOR
@@ -220,6 +348,12 @@ func GetFileExt(dataType v1.DataType, methodName string, parameters map[string]s
 		if methodName == nextPointCloud {
 			return ".pcd"
 		}
+		if methodName == audioChunks {
+			if parameters["format"] == "pcm" {
+				return ".pcm"
+			}
+			return ".wav"
+		}
 		if methodName == readImage {
 			// TODO: Add explicit file extensions for all mime types.
 			switch parameters["mime_type"] {
@@ -1,8 +1,13 @@
 package datacapture
 
 import (
+	"bytes"
+	"io"
+	"os"
 	"testing"
 
+	"github.com/matttproud/golang_protobuf_extensions/pbutil"
+	"github.com/pkg/errors"
 	v1 "go.viam.com/api/app/datasync/v1"
 	"go.viam.com/test"
 
@@ -92,3 +97,126 @@ func TestBuildCaptureMetadata(t *testing.T) {
 		})
 	}
 }
+
+func TestEncodedFile(t *testing.T) {
+	key := bytes.Repeat([]byte{1}, EncryptionKeySize)
+	otherKey := bytes.Repeat([]byte{2}, EncryptionKeySize)
+	md := &v1.DataCaptureMetadata{ComponentType: "camera", ComponentName: "cam1", MethodName: readImage}
+	readings := []*v1.SensorData{
+		{Data: &v1.SensorData_Binary{Binary: bytes.Repeat([]byte("image"), 100)}},
+		{Data: &v1.SensorData_Binary{Binary: []byte("another image")}},
+	}
+
+	open := func(t *testing.T, path string, opts ...FileOption) (*File, error) {
+		t.Helper()
+		//nolint:gosec
+		f, err := os.Open(path)
+		test.That(t, err, test.ShouldBeNil)
+		t.Cleanup(func() { f.Close() })
+		return ReadFile(f, opts...)
+	}
+	readAll := func(t *testing.T, f *File) []*v1.SensorData {
+		t.Helper()
+		var read []*v1.SensorData
+		for {
+			next, err := f.ReadNext()
+			if errors.Is(err, io.EOF) {
+				return read
+			}
+			test.That(t, err, test.ShouldBeNil)
+			read = append(read, next)
+		}
+	}
+
+	for _, tc := range []struct {
+		name        string
+		compression Compression
+		encrypt     bool
+	}{
+		{"none", CompressionNone, false},
+		{"gzip", CompressionGzip, false},
+		{"zstd", CompressionZstd, false},
+		{"encrypted", CompressionNone, true},
+		{"zstd encrypted", CompressionZstd, true},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			opts := []FileOption{WithCompression(tc.compression)}
+			if tc.encrypt {
+				opts = append(opts, WithEncryption(key))
+			}
+			f, err := NewFile(t.TempDir(), md, opts...)
+			test.That(t, err, test.ShouldBeNil)
+			for _, r := range readings {
+				test.That(t, f.WriteNext(r), test.ShouldBeNil)
+			}
+			test.That(t, f.Close(), test.ShouldBeNil)
+			info, err := os.Stat(f.GetPath())
+			test.That(t, err, test.ShouldBeNil)
+			test.That(t, f.Size(), test.ShouldEqual, info.Size())
+
+			contents, err := os.ReadFile(f.GetPath())
+			test.That(t, err, test.ShouldBeNil)
+			if tc.compression != CompressionNone {
+				test.That(t, len(contents), test.ShouldBeLessThan, len(readings[0].GetBinary()))
+			}
+			if tc.encrypt {
+				test.That(t, bytes.Contains(contents, []byte("another image")), test.ShouldBeFalse)
+			}
+
+			read, err := open(t, f.GetPath(), WithDecryptionKey(key))
+			test.That(t, err, test.ShouldBeNil)
+			test.That(t, read.Encoded(), test.ShouldEqual, tc.compression != CompressionNone || tc.encrypt)
+			test.That(t, read.ReadMetadata().String(), test.ShouldEqual, md.String())
+			test.That(t, readAll(t, read), test.ShouldHaveLength, len(readings))
+
+			if tc.encrypt {
+				_, err = open(t, f.GetPath())
+				test.That(t, err, test.ShouldNotBeNil)
+				_, err = open(t, f.GetPath(), WithDecryptionKey(otherKey))
+				test.That(t, err, test.ShouldNotBeNil)
+
+				// Of several keys, files are decrypted with the one whose ID is in their header.
+				test.That(t, bytes.Contains(contents, keyID(key)), test.ShouldBeTrue)
+				read, err = open(t, f.GetPath(), WithDecryptionKey(otherKey), WithDecryptionKey(key))
+				test.That(t, err, test.ShouldBeNil)
+				test.That(t, readAll(t, read), test.ShouldHaveLength, len(readings))
+			}
+
+			// Decoded files are read like any unencoded file.
+			read, err = open(t, f.GetPath(), WithDecryptionKey(key))
+			test.That(t, err, test.ShouldBeNil)
+			var decoded bytes.Buffer
+			test.That(t, read.WriteDecoded(&decoded), test.ShouldBeNil)
+			decodedMD := &v1.DataCaptureMetadata{}
+			_, err = pbutil.ReadDelimited(&decoded, decodedMD)
+			test.That(t, err, test.ShouldBeNil)
+			test.That(t, decodedMD.String(), test.ShouldEqual, md.String())
+			for _, r := range readings {
+				next := &v1.SensorData{}
+				_, err = pbutil.ReadDelimited(&decoded, next)
+				test.That(t, err, test.ShouldBeNil)
+				test.That(t, next.GetBinary(), test.ShouldResemble, r.GetBinary())
+			}
+			test.That(t, decoded.Len(), test.ShouldEqual, 0)
+		})
+	}
+
+	// Files written before headers had key IDs are decrypted with the first key.
+	f, err := NewFile(t.TempDir(), md, WithEncryption(key))
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, f.WriteNext(readings[1]), test.ShouldBeNil)
+	test.That(t, f.Close(), test.ShouldBeNil)
+	contents, err := os.ReadFile(f.GetPath())
+	test.That(t, err, test.ShouldBeNil)
+	legacy := append(append([]byte{}, contents[:len(encodedMagic)]...), encodingVersionNoKeyID)
+	legacy = append(append(legacy, contents[len(encodedMagic)+1:encodedHeaderLen]...), contents[encodedHeaderLen+keyIDLen:]...)
+	test.That(t, os.WriteFile(f.GetPath(), legacy, 0o600), test.ShouldBeNil)
+	read, err := open(t, f.GetPath(), WithDecryptionKey(key), WithDecryptionKey(otherKey))
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, readAll(t, read), test.ShouldHaveLength, 1)
+
+	_, err = NewFile(t.TempDir(), md, WithCompression("lz4"))
+	test.That(t, err, test.ShouldNotBeNil)
+	_, err = NewFile(t.TempDir(), md, WithEncryption([]byte("short")))
+	test.That(t, err, test.ShouldNotBeNil)
+}
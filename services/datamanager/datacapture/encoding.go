@@ -0,0 +1,370 @@
+package datacapture
+
+import (
+	"bytes"
+	"compress/gzip"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"io"
+	"sync"
+
+	"github.com/klauspost/compress/zstd"
+	"github.com/pkg/errors"
+	"google.golang.org/protobuf/proto"
+)
+
+// Compression is how the messages of a data capture file are compressed on disk.
+type Compression string
+
+// The compressions of data capture files.
+const (
+	CompressionNone Compression = ""
+	CompressionGzip Compression = "gzip"
+	CompressionZstd Compression = "zstd"
+)
+
+// Validate ensures c is a known compression.
+func (c Compression) Validate() error {
+	switch c {
+	case CompressionNone, CompressionGzip, CompressionZstd:
+		return nil
+	default:
+		return errors.Errorf("unknown compression %q, must be %s or %s", c, CompressionGzip, CompressionZstd)
+	}
+}
+
+// EncryptionKeySize is the size in bytes of the AES-256 keys data capture files are encrypted with.
+const EncryptionKeySize = 32
+
+// ParseEncryptionKey parses a hex encoded encryption key.
+func ParseEncryptionKey(s string) ([]byte, error) {
+	key, err := hex.DecodeString(s)
+	if err != nil {
+		return nil, errors.Wrap(err, "encryption key must be hex encoded")
+	}
+	if len(key) != EncryptionKeySize {
+		return nil, errors.Errorf("encryption key must be %d bytes, got %d", EncryptionKeySize, len(key))
+	}
+	return key, nil
+}
+
+// FileOption configures how a File is stored on disk.
+type FileOption func(*fileOptions)
+
+type fileOptions struct {
+	compression Compression
+	encrypt     bool
+	key         []byte
+	// decryptionKeys are the keys files that are read may be encrypted with.
+	decryptionKeys [][]byte
+}
+
+// WithCompression compresses each message of new files with c.
+func WithCompression(c Compression) FileOption {
+	return func(opts *fileOptions) {
+		opts.compression = c
+	}
+}
+
+// WithEncryption encrypts new files with key.
+func WithEncryption(key []byte) FileOption {
+	return func(opts *fileOptions) {
+		opts.encrypt = true
+		opts.key = key
+	}
+}
+
+// WithDecryptionKey decrypts files that were encrypted with key when they are read. Files that are not encrypted are
+// read as usual. Given more than once, such as with the keys that a key replaced, files are decrypted with whichever
+// key they were encrypted with.
+func WithDecryptionKey(key []byte) FileOption {
+	return func(opts *fileOptions) {
+		opts.decryptionKeys = append(opts.decryptionKeys, key)
+	}
+}
+
+// keyIDLen is the length of the ID of the key that encrypted files are encrypted with.
+const keyIDLen = 8
+
+// keyID identifies key in the header of the files it encrypts, without revealing it.
+func keyID(key []byte) []byte {
+	sum := sha256.Sum256(append([]byte("viam data capture key id\x00"), key...))
+	return sum[:keyIDLen]
+}
+
+// Files that are compressed or encrypted start with encodedMagic, followed by the version of their encoding, their
+// compression and their encryption, and for encrypted files the ID of their key. Unencoded files start with the
+// length of their metadata instead, which is only zero for empty metadata and is then followed by the length of a
+// reading rather than the rest of the magic. The messages of encoded files are each prefixed by their encoded length
+// like those of unencoded files, but are compressed and then encrypted with a nonce of their own.
+var encodedMagic = []byte("\x00VIAMCAP")
+
+const (
+	encodingVersion byte = 2
+	// encodingVersionNoKeyID is the version of files whose header has no key ID, which are decrypted with the first
+	// decryption key.
+	encodingVersionNoKeyID byte = 1
+	encodedHeaderLen            = 11
+
+	compressionCodeNone byte = 0
+	compressionCodeGzip byte = 1
+	compressionCodeZstd byte = 2
+
+	encryptionCodeNone      byte = 0
+	encryptionCodeAES256GCM byte = 1
+)
+
+var compressionCodes = map[Compression]byte{
+	CompressionNone: compressionCodeNone,
+	CompressionGzip: compressionCodeGzip,
+	CompressionZstd: compressionCodeZstd,
+}
+
+// codec compresses and encrypts the messages of an encoded file.
+type codec struct {
+	compression Compression
+	aead        cipher.AEAD
+	keyID       []byte
+}
+
+// newCodec returns the codec of opts, or nil if files are stored unencoded.
+func newCodec(opts fileOptions) (*codec, error) {
+	if err := opts.compression.Validate(); err != nil {
+		return nil, err
+	}
+	if opts.compression == CompressionNone && !opts.encrypt {
+		return nil, nil
+	}
+	c := &codec{compression: opts.compression}
+	if opts.encrypt {
+		aead, err := newAEAD(opts.key)
+		if err != nil {
+			return nil, err
+		}
+		c.aead = aead
+		c.keyID = keyID(opts.key)
+	}
+	return c, nil
+}
+
+func newAEAD(key []byte) (cipher.AEAD, error) {
+	if len(key) != EncryptionKeySize {
+		return nil, errors.Errorf("encryption key must be %d bytes, got %d", EncryptionKeySize, len(key))
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// header returns the header of files encoded by c.
+func (c *codec) header() []byte {
+	encryption := encryptionCodeNone
+	if c.aead != nil {
+		encryption = encryptionCodeAES256GCM
+	}
+	header := append(append([]byte{}, encodedMagic...), encodingVersion, compressionCodes[c.compression], encryption)
+	return append(header, c.keyID...)
+}
+
+// readCodec returns the codec of the encoded file f and leaves f after its header, or returns nil and leaves f at its
+// start if it is not encoded.
+func readCodec(f io.ReadSeeker, opts fileOptions) (*codec, error) {
+	header := make([]byte, encodedHeaderLen)
+	n, err := io.ReadFull(f, header)
+	if err != nil && !errors.Is(err, io.ErrUnexpectedEOF) && !errors.Is(err, io.EOF) {
+		return nil, err
+	}
+	if n < encodedHeaderLen || !bytes.Equal(header[:len(encodedMagic)], encodedMagic) {
+		_, err := f.Seek(0, io.SeekStart)
+		return nil, err
+	}
+
+	version, compression, encryption := header[len(encodedMagic)], header[len(encodedMagic)+1], header[len(encodedMagic)+2]
+	if version != encodingVersion && version != encodingVersionNoKeyID {
+		return nil, errors.Errorf("unsupported data capture file version %d", version)
+	}
+	c := &codec{}
+	for name, code := range compressionCodes {
+		if code == compression {
+			c.compression = name
+		}
+	}
+	if compressionCodes[c.compression] != compression {
+		return nil, errors.Errorf("unsupported data capture file compression %d", compression)
+	}
+	switch encryption {
+	case encryptionCodeNone:
+	case encryptionCodeAES256GCM:
+		if len(opts.decryptionKeys) == 0 {
+			return nil, errors.New("data capture file is encrypted but no encryption key is configured")
+		}
+		key := opts.decryptionKeys[0]
+		if version != encodingVersionNoKeyID {
+			id := make([]byte, keyIDLen)
+			if _, err := io.ReadFull(f, id); err != nil {
+				return nil, err
+			}
+			if key = keyFor(id, opts.decryptionKeys); key == nil {
+				return nil, errors.Errorf("data capture file is encrypted with key %x, which is not configured", id)
+			}
+		}
+		if c.aead, err = newAEAD(key); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, errors.Errorf("unsupported data capture file encryption %d", encryption)
+	}
+	return c, nil
+}
+
+// keyFor returns the key of keys with the ID id, or nil if none has it.
+func keyFor(id []byte, keys [][]byte) []byte {
+	for _, key := range keys {
+		if bytes.Equal(keyID(key), id) {
+			return key
+		}
+	}
+	return nil
+}
+
+// encode marshals, compresses and encrypts m.
+func (c *codec) encode(m proto.Message) ([]byte, error) {
+	b, err := proto.Marshal(m)
+	if err != nil {
+		return nil, err
+	}
+	switch c.compression {
+	case CompressionGzip:
+		var buf bytes.Buffer
+		w := gzip.NewWriter(&buf)
+		if _, err := w.Write(b); err != nil {
+			return nil, err
+		}
+		if err := w.Close(); err != nil {
+			return nil, err
+		}
+		b = buf.Bytes()
+	case CompressionZstd:
+		b = zstdEncoder().EncodeAll(b, nil)
+	case CompressionNone:
+	}
+	if c.aead != nil {
+		nonce := make([]byte, c.aead.NonceSize(), c.aead.NonceSize()+len(b)+c.aead.Overhead())
+		if _, err := rand.Read(nonce); err != nil {
+			return nil, err
+		}
+		b = c.aead.Seal(nonce, nonce, b, nil)
+	}
+	return b, nil
+}
+
+// decode decrypts, decompresses and unmarshals b into m.
+func (c *codec) decode(b []byte, m proto.Message) error {
+	b, err := c.open(b)
+	if err != nil {
+		return err
+	}
+	return proto.Unmarshal(b, m)
+}
+
+// open decrypts and decompresses b into the marshaled message it was encoded from.
+func (c *codec) open(b []byte) ([]byte, error) {
+	if c.aead != nil {
+		if len(b) < c.aead.NonceSize() {
+			return nil, errors.New("encrypted message is too short")
+		}
+		var err error
+		b, err = c.aead.Open(nil, b[:c.aead.NonceSize()], b[c.aead.NonceSize():], nil)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to decrypt data capture file, is the encryption key correct?")
+		}
+	}
+	switch c.compression {
+	case CompressionGzip:
+		r, err := gzip.NewReader(bytes.NewReader(b))
+		if err != nil {
+			return nil, err
+		}
+		return io.ReadAll(r)
+	case CompressionZstd:
+		return zstdDecoder().DecodeAll(b, nil)
+	case CompressionNone:
+	}
+	return b, nil
+}
+
+// writeDelimited writes b prefixed by its length, like pbutil.WriteDelimited does for messages.
+func writeDelimited(w io.Writer, b []byte) (int, error) {
+	var length [binary.MaxVarintLen64]byte
+	n := binary.PutUvarint(length[:], uint64(len(b)))
+	written, err := w.Write(length[:n])
+	if err != nil {
+		return written, err
+	}
+	m, err := w.Write(b)
+	return written + m, err
+}
+
+// readDelimited reads bytes written by writeDelimited. It reads no further than their end since files are read
+// unbuffered.
+func readDelimited(r io.Reader) ([]byte, error) {
+	var length uint64
+	var b [1]byte
+	for shift := uint(0); ; shift += 7 {
+		if shift >= 64 {
+			return nil, errors.New("invalid message length in data capture file")
+		}
+		n, err := r.Read(b[:])
+		if n == 0 {
+			if err == nil {
+				err = io.ErrNoProgress
+			}
+			// Only files that end between messages end with io.EOF.
+			if shift > 0 && errors.Is(err, io.EOF) {
+				err = io.ErrUnexpectedEOF
+			}
+			return nil, err
+		}
+		length |= uint64(b[0]&0x7f) << shift
+		if b[0] < 0x80 {
+			break
+		}
+	}
+	buf := make([]byte, length)
+	if _, err := io.ReadFull(r, buf); err != nil {
+		return nil, err
+	}
+	return buf, nil
+}
+
+var (
+	zstdOnce sync.Once
+	zstdEnc  *zstd.Encoder
+	zstdDec  *zstd.Decoder
+)
+
+// zstdEncoder and zstdDecoder return an encoder and decoder shared by all files, which are created on first use
+// since they start goroutines of their own.
+func zstdEncoder() *zstd.Encoder {
+	initZstd()
+	return zstdEnc
+}
+
+func zstdDecoder() *zstd.Decoder {
+	initZstd()
+	return zstdDec
+}
+
+func initZstd() {
+	zstdOnce.Do(func() {
+		// Neither can fail without options that are invalid.
+		zstdEnc, _ = zstd.NewWriter(nil)
+		zstdDec, _ = zstd.NewReader(nil)
+	})
+}
@@ -0,0 +1,408 @@
+// Package export exports data capture files to open formats so that captured data can be used offline.
+package export
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"image"
+	// Register jpeg so that the format of jpeg readings is detected.
+	_ "image/jpeg"
+	"image/png"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"go.uber.org/multierr"
+	v1 "go.viam.com/api/app/datasync/v1"
+
+	// Register raw RGBA images so that they are converted to PNG.
+	_ "go.viam.com/rdk/rimage"
+	"go.viam.com/rdk/services/datamanager/datacapture"
+)
+
+// Format is a format tabular data is exported to. Binary data is always exported to one file per reading.
+type Format string
+
+// The formats tabular data can be exported to.
+const (
+	FormatCSV     Format = "csv"
+	FormatJSONL   Format = "jsonl"
+	FormatParquet Format = "parquet"
+)
+
+const (
+	timeRequestedColumn = "TimeRequested"
+	timeReceivedColumn  = "TimeReceived"
+	fileColumn          = "File"
+
+	// IndexFileName is the name of the index written next to the files of exported binary data. It is a CSV file
+	// of the name of each file and when it was captured.
+	IndexFileName = "index.csv"
+
+	rawRGBAFormat = "vnd.viam.rgba"
+)
+
+// Export exports the data capture file src, or every data capture file in the directory src such as the capture
+// directory of the data manager, into dst. Each data capture file is exported to the same path relative to dst
+// as it had relative to src, with the file extension of format for tabular data or as a directory of files for
+// binary data. Encrypted data capture files are read with the key given by datacapture.WithDecryptionKey in opts.
+func Export(src, dst string, format Format, opts ...datacapture.FileOption) error {
+	if err := format.validate(); err != nil {
+		return err
+	}
+	info, err := os.Stat(src)
+	if err != nil {
+		return err
+	}
+	if !info.IsDir() {
+		return exportPath(src, filepath.Join(dst, strings.TrimSuffix(filepath.Base(src), datacapture.FileExt)), format, opts)
+	}
+	found := false
+	if err := filepath.WalkDir(src, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() || filepath.Ext(path) != datacapture.FileExt {
+			return nil
+		}
+		found = true
+		rel, err := filepath.Rel(src, path)
+		if err != nil {
+			return err
+		}
+		return exportPath(path, filepath.Join(dst, strings.TrimSuffix(rel, datacapture.FileExt)), format, opts)
+	}); err != nil {
+		return err
+	}
+	if !found {
+		return errors.Errorf("no data capture files in %s", src)
+	}
+	return nil
+}
+
+func exportPath(path, out string, format Format, opts []datacapture.FileOption) (err error) {
+	//nolint:gosec
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		err = multierr.Combine(err, f.Close())
+	}()
+	captureFile, err := datacapture.ReadFile(f, opts...)
+	if err != nil {
+		return err
+	}
+	if err := File(captureFile, out, format); err != nil {
+		return errors.Wrapf(err, "failed to export %s", path)
+	}
+	return nil
+}
+
+// File exports the readings of f. Tabular data is written to out with the file extension of format and binary data
+// to the directory out, along with an index of its files.
+func File(f *datacapture.File, out string, format Format) error {
+	if err := format.validate(); err != nil {
+		return err
+	}
+	readings, err := readAll(f)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(out), 0o700); err != nil {
+		return err
+	}
+	if f.ReadMetadata().GetType() == v1.DataType_DATA_TYPE_BINARY_SENSOR {
+		return exportBinary(readings, f.ReadMetadata().GetFileExtension(), out)
+	}
+
+	path := out + "." + string(format)
+	//nolint:gosec
+	outFile, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	w := bufio.NewWriter(outFile)
+	switch format {
+	case FormatCSV:
+		err = writeCSV(w, readings)
+	case FormatJSONL:
+		err = writeJSONL(w, readings)
+	case FormatParquet:
+		err = writeTabularParquet(w, readings)
+	}
+	if err == nil {
+		err = w.Flush()
+	}
+	return multierr.Combine(err, outFile.Close())
+}
+
+func (format Format) validate() error {
+	switch format {
+	case FormatCSV, FormatJSONL, FormatParquet:
+		return nil
+	default:
+		return errors.Errorf("cannot export to format %q, must be one of %s, %s or %s", format, FormatCSV, FormatJSONL, FormatParquet)
+	}
+}
+
+// readAll reads the readings of f. A file whose last reading is cut off, such as one that is still being captured
+// to, is an io.ErrUnexpectedEOF rather than exported without it.
+func readAll(f *datacapture.File) ([]*v1.SensorData, error) {
+	var readings []*v1.SensorData
+	for {
+		reading, err := f.ReadNext()
+		if errors.Is(err, io.EOF) {
+			return readings, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		readings = append(readings, reading)
+	}
+}
+
+func timeRequested(reading *v1.SensorData) time.Time {
+	return reading.GetMetadata().GetTimeRequested().AsTime().UTC()
+}
+
+func timeReceived(reading *v1.SensorData) time.Time {
+	return reading.GetMetadata().GetTimeReceived().AsTime().UTC()
+}
+
+// flatten adds the leaves of the nested maps and lists in v to columns, named by the keys and indices leading to
+// them joined by dots.
+func flatten(prefix string, v interface{}, columns map[string]interface{}) {
+	join := func(key string) string {
+		if prefix == "" {
+			return key
+		}
+		return prefix + "." + key
+	}
+	switch v := v.(type) {
+	case map[string]interface{}:
+		for key, value := range v {
+			flatten(join(key), value, columns)
+		}
+	case []interface{}:
+		for i, value := range v {
+			flatten(join(strconv.Itoa(i)), value, columns)
+		}
+	case nil:
+	default:
+		columns[prefix] = v
+	}
+}
+
+// table flattens the readings into rows and returns them with the sorted names of their columns.
+func table(readings []*v1.SensorData) ([]string, []map[string]interface{}, error) {
+	rows := make([]map[string]interface{}, 0, len(readings))
+	names := map[string]bool{}
+	for _, reading := range readings {
+		if reading.GetStruct() == nil {
+			return nil, nil, errors.New("tabular data capture file has a reading that is not tabular")
+		}
+		row := map[string]interface{}{}
+		flatten("", reading.GetStruct().AsMap(), row)
+		for name := range row {
+			names[name] = true
+		}
+		rows = append(rows, row)
+	}
+	columns := make([]string, 0, len(names))
+	for name := range names {
+		columns = append(columns, name)
+	}
+	sort.Strings(columns)
+	return columns, rows, nil
+}
+
+func formatValue(v interface{}) string {
+	switch v := v.(type) {
+	case nil:
+		return ""
+	case float64:
+		return strconv.FormatFloat(v, 'g', -1, 64)
+	case string:
+		return v
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// writeCSV writes the readings as rows of the times they were captured followed by their flattened values.
+func writeCSV(w io.Writer, readings []*v1.SensorData) error {
+	columns, rows, err := table(readings)
+	if err != nil {
+		return err
+	}
+	csvWriter := csv.NewWriter(w)
+	if err := csvWriter.Write(append([]string{timeRequestedColumn, timeReceivedColumn}, columns...)); err != nil {
+		return err
+	}
+	for i, row := range rows {
+		record := []string{
+			timeRequested(readings[i]).Format(time.RFC3339Nano),
+			timeReceived(readings[i]).Format(time.RFC3339Nano),
+		}
+		for _, column := range columns {
+			record = append(record, formatValue(row[column]))
+		}
+		if err := csvWriter.Write(record); err != nil {
+			return err
+		}
+	}
+	csvWriter.Flush()
+	return csvWriter.Error()
+}
+
+// writeJSONL writes each reading as a line of JSON in the same format as tabular data downloaded from the cloud.
+func writeJSONL(w io.Writer, readings []*v1.SensorData) error {
+	for _, reading := range readings {
+		if reading.GetStruct() == nil {
+			return errors.New("tabular data capture file has a reading that is not tabular")
+		}
+		m := reading.GetStruct().AsMap()
+		m[timeRequestedColumn] = timeRequested(reading)
+		m[timeReceivedColumn] = timeReceived(reading)
+		j, err := json.Marshal(m)
+		if err != nil {
+			return errors.Wrap(err, "error marshaling reading to json")
+		}
+		if _, err := w.Write(append(j, '\n')); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// writeTabularParquet writes the readings with the same columns as writeCSV. Columns of only numbers or only
+// booleans keep their type and all others are strings.
+func writeTabularParquet(w io.Writer, readings []*v1.SensorData) error {
+	names, rows, err := table(readings)
+	if err != nil {
+		return err
+	}
+	requested := &parquetColumn{name: timeRequestedColumn, kind: parquetTimestamp}
+	received := &parquetColumn{name: timeReceivedColumn, kind: parquetTimestamp}
+	for _, reading := range readings {
+		requested.values = append(requested.values, timeRequested(reading))
+		received.values = append(received.values, timeReceived(reading))
+	}
+	columns := []*parquetColumn{requested, received}
+	for _, name := range names {
+		column := &parquetColumn{name: name, optional: true, kind: parquetKindOf(name, rows)}
+		for _, row := range rows {
+			v, ok := row[name]
+			switch {
+			case !ok:
+				column.values = append(column.values, nil)
+			case column.kind == parquetString:
+				column.values = append(column.values, formatValue(v))
+			default:
+				column.values = append(column.values, v)
+			}
+		}
+		columns = append(columns, column)
+	}
+	return writeParquet(w, columns)
+}
+
+func parquetKindOf(name string, rows []map[string]interface{}) parquetKind {
+	kind := parquetString
+	found := false
+	for _, row := range rows {
+		var rowKind parquetKind
+		switch row[name].(type) {
+		case nil:
+			continue
+		case float64:
+			rowKind = parquetDouble
+		case bool:
+			rowKind = parquetBoolean
+		default:
+			return parquetString
+		}
+		if found && rowKind != kind {
+			return parquetString
+		}
+		kind, found = rowKind, true
+	}
+	return kind
+}
+
+// exportBinary writes each reading to its own file in the directory out, named by its index, and writes an index
+// of the files.
+func exportBinary(readings []*v1.SensorData, fileExt, out string) (err error) {
+	if err := os.MkdirAll(out, 0o700); err != nil {
+		return err
+	}
+	//nolint:gosec
+	indexFile, err := os.Create(filepath.Join(out, IndexFileName))
+	if err != nil {
+		return err
+	}
+	defer func() {
+		err = multierr.Combine(err, indexFile.Close())
+	}()
+	index := csv.NewWriter(indexFile)
+	if err := index.Write([]string{fileColumn, timeRequestedColumn, timeReceivedColumn}); err != nil {
+		return err
+	}
+	for i, reading := range readings {
+		if reading.GetStruct() != nil {
+			return errors.New("binary data capture file has a reading that is not binary")
+		}
+		data, ext, err := binaryFile(reading.GetBinary(), fileExt)
+		if err != nil {
+			return err
+		}
+		name := fmt.Sprintf("%06d%s", i, ext)
+		if err := os.WriteFile(filepath.Join(out, name), data, 0o600); err != nil {
+			return err
+		}
+		if err := index.Write([]string{
+			name,
+			timeRequested(reading).Format(time.RFC3339Nano),
+			timeReceived(reading).Format(time.RFC3339Nano),
+		}); err != nil {
+			return err
+		}
+	}
+	index.Flush()
+	return index.Error()
+}
+
+// binaryFile returns the contents and extension of the file a binary reading is exported to. Point clouds are
+// already PCD files and images are kept in their format, except for raw RGBA images which only Viam can read and
+// are converted to PNG.
+func binaryFile(data []byte, fileExt string) ([]byte, string, error) {
+	_, format, err := image.DecodeConfig(bytes.NewReader(data))
+	switch {
+	case err == nil && format == rawRGBAFormat:
+		img, _, err := image.Decode(bytes.NewReader(data))
+		if err != nil {
+			return nil, "", err
+		}
+		var buf bytes.Buffer
+		if err := png.Encode(&buf, img); err != nil {
+			return nil, "", err
+		}
+		return buf.Bytes(), ".png", nil
+	case fileExt != "":
+		return data, fileExt, nil
+	case err == nil:
+		return data, "." + format, nil
+	default:
+		return data, ".bin", nil
+	}
+}
@@ -0,0 +1,297 @@
+package export
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	v1 "go.viam.com/api/app/datasync/v1"
+	"go.viam.com/test"
+	"google.golang.org/protobuf/types/known/structpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"go.viam.com/rdk/pointcloud"
+	"go.viam.com/rdk/resource"
+	"go.viam.com/rdk/rimage"
+	"go.viam.com/rdk/services/datamanager/datacapture"
+	"go.viam.com/rdk/testutils"
+	"go.viam.com/rdk/utils"
+)
+
+var start = time.Date(2022, 11, 1, 12, 0, 0, 0, time.UTC)
+
+func sensorMetadata(i int) *v1.SensorMetadata {
+	requested := start.Add(time.Duration(i) * time.Second)
+	return &v1.SensorMetadata{
+		TimeRequested: timestamppb.New(requested),
+		TimeReceived:  timestamppb.New(requested.Add(time.Millisecond)),
+	}
+}
+
+func writeCaptureFile(t *testing.T, captureDir, compType, method string, params map[string]string, readings ...*v1.SensorData) {
+	t.Helper()
+	md, err := datacapture.BuildCaptureMetadata(resource.SubtypeName(compType), compType+"1", "", method, params, nil)
+	test.That(t, err, test.ShouldBeNil)
+	testutils.WriteCaptureFile(t, captureDir, md, readings...)
+}
+
+func tabularReadings(t *testing.T) []*v1.SensorData {
+	t.Helper()
+	var readings []*v1.SensorData
+	for i, reading := range []map[string]interface{}{
+		{"pose": map[string]interface{}{"x": 1.5, "y": 2}, "moving": true},
+		{"pose": map[string]interface{}{"x": 3, "y": 4}, "moving": false, "state": "done"},
+		{"pose": map[string]interface{}{"x": 5, "y": 6}, "joints": []interface{}{7, "eight"}},
+	} {
+		s, err := structpb.NewStruct(reading)
+		test.That(t, err, test.ShouldBeNil)
+		readings = append(readings, &v1.SensorData{Metadata: sensorMetadata(i), Data: &v1.SensorData_Struct{Struct: s}})
+	}
+	return readings
+}
+
+// findExported returns the only file or directory in the directory of the exported type and method.
+func findExported(t *testing.T, dst, compType, method string) string {
+	t.Helper()
+	dir := filepath.Join(dst, compType, compType+"1", method)
+	entries, err := os.ReadDir(dir)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, entries, test.ShouldHaveLength, 1)
+	return filepath.Join(dir, entries[0].Name())
+}
+
+func TestExportTabular(t *testing.T) {
+	captureDir := t.TempDir()
+	writeCaptureFile(t, captureDir, "arm", "EndPosition", nil, tabularReadings(t)...)
+	test.That(t, Export(captureDir, t.TempDir(), "xlsx"), test.ShouldNotBeNil)
+	test.That(t, Export(t.TempDir(), t.TempDir(), FormatCSV), test.ShouldNotBeNil)
+
+	t.Run("csv", func(t *testing.T) {
+		dst := t.TempDir()
+		test.That(t, Export(captureDir, dst, FormatCSV), test.ShouldBeNil)
+		path := findExported(t, dst, "arm", "EndPosition")
+		test.That(t, filepath.Ext(path), test.ShouldEqual, ".csv")
+		f, err := os.Open(path)
+		test.That(t, err, test.ShouldBeNil)
+		defer f.Close()
+		records, err := csv.NewReader(f).ReadAll()
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, records, test.ShouldResemble, [][]string{
+			{"TimeRequested", "TimeReceived", "joints.0", "joints.1", "moving", "pose.x", "pose.y", "state"},
+			{"2022-11-01T12:00:00Z", "2022-11-01T12:00:00.001Z", "", "", "true", "1.5", "2", ""},
+			{"2022-11-01T12:00:01Z", "2022-11-01T12:00:01.001Z", "", "", "false", "3", "4", "done"},
+			{"2022-11-01T12:00:02Z", "2022-11-01T12:00:02.001Z", "7", "eight", "", "5", "6", ""},
+		})
+	})
+
+	t.Run("jsonl", func(t *testing.T) {
+		dst := t.TempDir()
+		test.That(t, Export(captureDir, dst, FormatJSONL), test.ShouldBeNil)
+		path := findExported(t, dst, "arm", "EndPosition")
+		test.That(t, filepath.Ext(path), test.ShouldEqual, ".jsonl")
+		contents, err := os.ReadFile(path)
+		test.That(t, err, test.ShouldBeNil)
+		lines := strings.Split(strings.TrimSpace(string(contents)), "\n")
+		test.That(t, lines, test.ShouldHaveLength, 3)
+		var line map[string]interface{}
+		test.That(t, json.Unmarshal([]byte(lines[1]), &line), test.ShouldBeNil)
+		test.That(t, line, test.ShouldResemble, map[string]interface{}{
+			"TimeRequested": "2022-11-01T12:00:01Z",
+			"TimeReceived":  "2022-11-01T12:00:01.001Z",
+			"pose":          map[string]interface{}{"x": 3.0, "y": 4.0},
+			"moving":        false,
+			"state":         "done",
+		})
+	})
+
+	t.Run("parquet", func(t *testing.T) {
+		dst := t.TempDir()
+		test.That(t, Export(captureDir, dst, FormatParquet), test.ShouldBeNil)
+		path := findExported(t, dst, "arm", "EndPosition")
+		test.That(t, filepath.Ext(path), test.ShouldEqual, ".parquet")
+		contents, err := os.ReadFile(path)
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, string(contents[:4]), test.ShouldEqual, parquetMagic)
+		test.That(t, string(contents[len(contents)-4:]), test.ShouldEqual, parquetMagic)
+		footerSize := int(binary.LittleEndian.Uint32(contents[len(contents)-8:]))
+		test.That(t, footerSize, test.ShouldBeLessThan, len(contents)-12)
+		footer := string(contents[len(contents)-8-footerSize : len(contents)-8])
+		for _, column := range []string{"TimeRequested", "TimeReceived", "joints.0", "joints.1", "moving", "pose.x", "pose.y", "state"} {
+			test.That(t, footer, test.ShouldContainSubstring, column)
+		}
+	})
+}
+
+func TestExportCutOff(t *testing.T) {
+	captureDir := t.TempDir()
+	writeCaptureFile(t, captureDir, "arm", "EndPosition", nil, tabularReadings(t)...)
+	var path string
+	test.That(t, filepath.WalkDir(captureDir, func(p string, d fs.DirEntry, err error) error {
+		if err == nil && !d.IsDir() {
+			path = p
+		}
+		return err
+	}), test.ShouldBeNil)
+	info, err := os.Stat(path)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, os.Truncate(path, info.Size()-3), test.ShouldBeNil)
+
+	err = Export(captureDir, t.TempDir(), FormatCSV)
+	test.That(t, errors.Is(err, io.ErrUnexpectedEOF), test.ShouldBeTrue)
+}
+
+func TestParquetColumns(t *testing.T) {
+	_, rows, err := table(tabularReadings(t))
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, parquetKindOf("pose.x", rows), test.ShouldEqual, parquetDouble)
+	test.That(t, parquetKindOf("moving", rows), test.ShouldEqual, parquetBoolean)
+	test.That(t, parquetKindOf("state", rows), test.ShouldEqual, parquetString)
+	test.That(t, parquetKindOf("joints.1", rows), test.ShouldEqual, parquetString)
+
+	optional := &parquetColumn{name: "c", kind: parquetBoolean, optional: true, values: []interface{}{true, nil, nil, false, true}}
+	page, err := optional.page()
+	test.That(t, err, test.ShouldBeNil)
+	// 6 bytes of RLE runs of definition levels 1, 0 and 1, and then the 3 values bit packed
+	test.That(t, page, test.ShouldResemble, []byte{6, 0, 0, 0, 2, 1, 4, 0, 4, 1, 0b101})
+
+	required := &parquetColumn{name: "c", kind: parquetDouble, values: []interface{}{1.0, nil}}
+	_, err = required.page()
+	test.That(t, err, test.ShouldNotBeNil)
+	test.That(t, writeParquet(&bytes.Buffer{}, []*parquetColumn{
+		{name: "a", kind: parquetDouble, values: []interface{}{1.0}},
+		{name: "b", kind: parquetDouble},
+	}), test.ShouldNotBeNil)
+}
+
+// goldenParquetColumns has values of every kind and runs of missing values long enough to take more than one byte to
+// encode.
+func goldenParquetColumns() []*parquetColumn {
+	columns := []*parquetColumn{
+		{name: "time", kind: parquetTimestamp},
+		{name: "double", kind: parquetDouble, optional: true},
+		{name: "boolean", kind: parquetBoolean, optional: true},
+		{name: "string", kind: parquetString, optional: true},
+	}
+	for i := 0; i < 130; i++ {
+		columns[0].values = append(columns[0].values, start.Add(time.Duration(i)*time.Millisecond))
+		if i < 100 {
+			columns[1].values = append(columns[1].values, nil)
+		} else {
+			columns[1].values = append(columns[1].values, float64(i)/4)
+		}
+		if i%3 == 0 {
+			columns[2].values = append(columns[2].values, nil)
+		} else {
+			columns[2].values = append(columns[2].values, i%2 == 0)
+		}
+		if i >= 10 && i < 80 {
+			columns[3].values = append(columns[3].values, nil)
+		} else {
+			columns[3].values = append(columns[3].values, fmt.Sprintf("row %d", i))
+		}
+	}
+	return columns
+}
+
+// The golden files in testdata were read back with github.com/parquet-go/parquet-go, which found the schema and
+// every value they were written from, including the missing ones.
+func TestParquetGolden(t *testing.T) {
+	t.Run("columns", func(t *testing.T) {
+		var written bytes.Buffer
+		test.That(t, writeParquet(&written, goldenParquetColumns()), test.ShouldBeNil)
+		golden, err := os.ReadFile(filepath.Join("testdata", "columns.parquet"))
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, written.Bytes(), test.ShouldResemble, golden)
+	})
+
+	t.Run("tabular", func(t *testing.T) {
+		captureDir := t.TempDir()
+		writeCaptureFile(t, captureDir, "arm", "EndPosition", nil, tabularReadings(t)...)
+		dst := t.TempDir()
+		test.That(t, Export(captureDir, dst, FormatParquet), test.ShouldBeNil)
+		exported, err := os.ReadFile(findExported(t, dst, "arm", "EndPosition"))
+		test.That(t, err, test.ShouldBeNil)
+		golden, err := os.ReadFile(filepath.Join("testdata", "tabular.parquet"))
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, exported, test.ShouldResemble, golden)
+	})
+}
+
+func TestExportBinary(t *testing.T) {
+	img := image.NewNRGBA(image.Rect(0, 0, 2, 2))
+	img.Set(1, 1, color.NRGBA{R: 255, A: 255})
+	var pngBytes bytes.Buffer
+	test.That(t, png.Encode(&pngBytes, img), test.ShouldBeNil)
+	rawRGBA, err := rimage.EncodeImage(context.Background(), img, utils.MimeTypeRawRGBA)
+	test.That(t, err, test.ShouldBeNil)
+	pc := pointcloud.New()
+	test.That(t, pc.Set(pointcloud.NewVector(1, 2, 3), nil), test.ShouldBeNil)
+	var pcd bytes.Buffer
+	test.That(t, pointcloud.ToPCD(pc, &pcd, pointcloud.PCDBinary), test.ShouldBeNil)
+
+	binaryReading := func(i int, data []byte) *v1.SensorData {
+		return &v1.SensorData{Metadata: sensorMetadata(i), Data: &v1.SensorData_Binary{Binary: data}}
+	}
+	captureDir := t.TempDir()
+	writeCaptureFile(t, captureDir, "camera", "ReadImage", map[string]string{"mime_type": utils.MimeTypePNG},
+		binaryReading(0, pngBytes.Bytes()), binaryReading(1, pngBytes.Bytes()))
+	writeCaptureFile(t, captureDir, "camera", "ReadImage", map[string]string{"mime_type": utils.MimeTypeRawRGBA},
+		binaryReading(0, rawRGBA))
+	writeCaptureFile(t, captureDir, "camera", "NextPointCloud", nil, binaryReading(0, pcd.Bytes()))
+
+	dst := t.TempDir()
+	test.That(t, Export(captureDir, dst, FormatCSV), test.ShouldBeNil)
+	readIndex := func(dir string) [][]string {
+		f, err := os.Open(filepath.Join(dir, IndexFileName))
+		test.That(t, err, test.ShouldBeNil)
+		defer f.Close()
+		records, err := csv.NewReader(f).ReadAll()
+		test.That(t, err, test.ShouldBeNil)
+		return records
+	}
+
+	// Both image files are exported to PNG, the first with two readings and the second with one.
+	imageDir := filepath.Join(dst, "camera", "camera1", "ReadImage")
+	dirs, err := os.ReadDir(imageDir)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, dirs, test.ShouldHaveLength, 2)
+	for i, dir := range dirs {
+		index := readIndex(filepath.Join(imageDir, dir.Name()))
+		test.That(t, index, test.ShouldHaveLength, 3-i)
+		test.That(t, index[0], test.ShouldResemble, []string{"File", "TimeRequested", "TimeReceived"})
+		test.That(t, index[1], test.ShouldResemble, []string{"000000.png", "2022-11-01T12:00:00Z", "2022-11-01T12:00:00.001Z"})
+		for _, record := range index[1:] {
+			f, err := os.Open(filepath.Join(imageDir, dir.Name(), record[0]))
+			test.That(t, err, test.ShouldBeNil)
+			decoded, format, err := image.Decode(f)
+			f.Close()
+			test.That(t, err, test.ShouldBeNil)
+			test.That(t, format, test.ShouldEqual, "png")
+			test.That(t, decoded.At(1, 1), test.ShouldResemble, color.NRGBA{R: 255, A: 255})
+		}
+	}
+
+	dir := findExported(t, dst, "camera", "NextPointCloud")
+	test.That(t, readIndex(dir)[1][0], test.ShouldEqual, "000000.pcd")
+	f, err := os.Open(filepath.Join(dir, "000000.pcd"))
+	test.That(t, err, test.ShouldBeNil)
+	defer f.Close()
+	exportedPC, err := pointcloud.ReadPCD(f)
+	test.That(t, err, test.ShouldBeNil)
+	_, ok := exportedPC.At(1, 2, 3)
+	test.That(t, ok, test.ShouldBeTrue)
+}
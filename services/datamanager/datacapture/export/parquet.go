@@ -0,0 +1,326 @@
+package export
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"math"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// The subset of the Parquet format used to write tabular data: a single row group of flat columns, each
+// written as one uncompressed data page of PLAIN encoded values. See https://github.com/apache/parquet-format.
+const (
+	parquetMagic = "PAR1"
+
+	// Physical types.
+	parquetTypeBoolean   = 0
+	parquetTypeInt64     = 2
+	parquetTypeDouble    = 5
+	parquetTypeByteArray = 6
+
+	// Converted types.
+	parquetConvertedUTF8            = 0
+	parquetConvertedTimestampMicros = 10
+
+	// Repetition types.
+	parquetRequired = 0
+	parquetOptional = 1
+
+	parquetEncodingPlain = 0
+	parquetEncodingRLE   = 3
+	parquetCodecNone     = 0
+	parquetPageData      = 0
+)
+
+// parquetKind is the kind of values in a parquet column.
+type parquetKind int
+
+const (
+	parquetString parquetKind = iota
+	parquetDouble
+	parquetBoolean
+	parquetTimestamp
+)
+
+// parquetColumn is a column of values that are all of its kind, or nil if the column is optional.
+type parquetColumn struct {
+	name     string
+	kind     parquetKind
+	optional bool
+	values   []interface{}
+}
+
+func (c *parquetColumn) physicalType() int32 {
+	switch c.kind {
+	case parquetDouble:
+		return parquetTypeDouble
+	case parquetBoolean:
+		return parquetTypeBoolean
+	case parquetTimestamp:
+		return parquetTypeInt64
+	case parquetString:
+		fallthrough
+	default:
+		return parquetTypeByteArray
+	}
+}
+
+// page returns the definition levels, if the column is optional, followed by the non nil values.
+func (c *parquetColumn) page() ([]byte, error) {
+	var page bytes.Buffer
+	if c.optional {
+		var levels bytes.Buffer
+		for i := 0; i < len(c.values); {
+			defined := c.values[i] != nil
+			run := 1
+			for i+run < len(c.values) && (c.values[i+run] != nil) == defined {
+				run++
+			}
+			// An RLE run of a bit width 1 level is the run length shifted left once, then the level in a byte.
+			levels.Write(binary.AppendUvarint(nil, uint64(run)<<1))
+			if defined {
+				levels.WriteByte(1)
+			} else {
+				levels.WriteByte(0)
+			}
+			i += run
+		}
+		if err := binary.Write(&page, binary.LittleEndian, uint32(levels.Len())); err != nil {
+			return nil, err
+		}
+		page.Write(levels.Bytes())
+	}
+
+	var bits []byte
+	var numBits int
+	for _, v := range c.values {
+		if v == nil {
+			if !c.optional {
+				return nil, errors.Errorf("required parquet column %s has a missing value", c.name)
+			}
+			continue
+		}
+		var err error
+		switch c.kind {
+		case parquetDouble:
+			err = binary.Write(&page, binary.LittleEndian, math.Float64bits(v.(float64)))
+		case parquetTimestamp:
+			err = binary.Write(&page, binary.LittleEndian, v.(time.Time).UnixMicro())
+		case parquetBoolean:
+			// Booleans are bit packed, least significant bit first.
+			if numBits%8 == 0 {
+				bits = append(bits, 0)
+			}
+			if v.(bool) {
+				bits[len(bits)-1] |= 1 << (numBits % 8)
+			}
+			numBits++
+		case parquetString:
+			s := v.(string)
+			if err = binary.Write(&page, binary.LittleEndian, uint32(len(s))); err == nil {
+				_, err = page.WriteString(s)
+			}
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	page.Write(bits)
+	return page.Bytes(), nil
+}
+
+// writeParquet writes the columns, which must all have the same number of values, to w as a parquet file.
+func writeParquet(w io.Writer, columns []*parquetColumn) error {
+	numRows := 0
+	if len(columns) > 0 {
+		numRows = len(columns[0].values)
+	}
+
+	var file bytes.Buffer
+	file.WriteString(parquetMagic)
+	chunks := make([]func(t *thriftWriter), 0, len(columns))
+	totalSize := 0
+	for _, c := range columns {
+		if len(c.values) != numRows {
+			return errors.Errorf("parquet column %s has %d values instead of %d", c.name, len(c.values), numRows)
+		}
+		page, err := c.page()
+		if err != nil {
+			return err
+		}
+		header := newThriftWriter()
+		header.i32(1, parquetPageData)
+		header.i32(2, int32(len(page)))
+		header.i32(3, int32(len(page)))
+		header.beginStruct(5)
+		header.i32(1, int32(numRows))
+		header.i32(2, parquetEncodingPlain)
+		header.i32(3, parquetEncodingRLE)
+		header.i32(4, parquetEncodingRLE)
+		header.endStruct()
+		header.stop()
+
+		offset := int64(file.Len())
+		size := int64(header.buf.Len() + len(page))
+		totalSize += int(size)
+		file.Write(header.buf.Bytes())
+		file.Write(page)
+
+		c := c
+		chunks = append(chunks, func(t *thriftWriter) {
+			t.i64(2, offset)
+			t.beginStruct(3)
+			t.i32(1, c.physicalType())
+			t.listHeader(2, thriftI32, 2)
+			t.varint(parquetEncodingPlain)
+			t.varint(parquetEncodingRLE)
+			t.listHeader(3, thriftBinary, 1)
+			t.rawString(c.name)
+			t.i32(4, parquetCodecNone)
+			t.i64(5, int64(numRows))
+			t.i64(6, size)
+			t.i64(7, size)
+			t.i64(9, offset)
+			t.endStruct()
+		})
+	}
+
+	footer := newThriftWriter()
+	footer.i32(1, 1)
+	footer.listHeader(2, thriftStruct, len(columns)+1)
+	footer.beginListStruct()
+	footer.str(4, "schema")
+	footer.i32(5, int32(len(columns)))
+	footer.endStruct()
+	for _, c := range columns {
+		footer.beginListStruct()
+		footer.i32(1, c.physicalType())
+		if c.optional {
+			footer.i32(3, parquetOptional)
+		} else {
+			footer.i32(3, parquetRequired)
+		}
+		footer.str(4, c.name)
+		switch c.kind {
+		case parquetString:
+			footer.i32(6, parquetConvertedUTF8)
+		case parquetTimestamp:
+			footer.i32(6, parquetConvertedTimestampMicros)
+		case parquetDouble, parquetBoolean:
+		}
+		footer.endStruct()
+	}
+	footer.i64(3, int64(numRows))
+	footer.listHeader(4, thriftStruct, 1)
+	footer.beginListStruct()
+	footer.listHeader(1, thriftStruct, len(chunks))
+	for _, chunk := range chunks {
+		footer.beginListStruct()
+		chunk(footer)
+		footer.endStruct()
+	}
+	footer.i64(2, int64(totalSize))
+	footer.i64(3, int64(numRows))
+	footer.endStruct()
+	footer.str(6, "go.viam.com/rdk")
+	footer.stop()
+
+	file.Write(footer.buf.Bytes())
+	if err := binary.Write(&file, binary.LittleEndian, uint32(footer.buf.Len())); err != nil {
+		return err
+	}
+	file.WriteString(parquetMagic)
+	_, err := w.Write(file.Bytes())
+	return err
+}
+
+// Types of the thrift compact protocol used by parquet metadata.
+const (
+	thriftI32    = 5
+	thriftI64    = 6
+	thriftBinary = 8
+	thriftList   = 9
+	thriftStruct = 12
+)
+
+// thriftWriter writes structs with the thrift compact protocol.
+type thriftWriter struct {
+	buf bytes.Buffer
+	// lastField is the id of the last field written in each struct being written, innermost last.
+	lastField []int16
+}
+
+func newThriftWriter() *thriftWriter {
+	return &thriftWriter{lastField: []int16{0}}
+}
+
+func (t *thriftWriter) varint(v int64) {
+	t.buf.Write(binary.AppendVarint(nil, v))
+}
+
+func (t *thriftWriter) uvarint(v uint64) {
+	t.buf.Write(binary.AppendUvarint(nil, v))
+}
+
+func (t *thriftWriter) field(id int16, typ byte) {
+	last := &t.lastField[len(t.lastField)-1]
+	if delta := id - *last; delta > 0 && delta <= 15 {
+		t.buf.WriteByte(byte(delta)<<4 | typ)
+	} else {
+		t.buf.WriteByte(typ)
+		t.varint(int64(id))
+	}
+	*last = id
+}
+
+func (t *thriftWriter) i32(id int16, v int32) {
+	t.field(id, thriftI32)
+	t.varint(int64(v))
+}
+
+func (t *thriftWriter) i64(id int16, v int64) {
+	t.field(id, thriftI64)
+	t.varint(v)
+}
+
+func (t *thriftWriter) str(id int16, v string) {
+	t.field(id, thriftBinary)
+	t.rawString(v)
+}
+
+func (t *thriftWriter) rawString(v string) {
+	t.uvarint(uint64(len(v)))
+	t.buf.WriteString(v)
+}
+
+func (t *thriftWriter) listHeader(id int16, elemType byte, size int) {
+	t.field(id, thriftList)
+	if size < 15 {
+		t.buf.WriteByte(byte(size)<<4 | elemType)
+		return
+	}
+	t.buf.WriteByte(0xf0 | elemType)
+	t.uvarint(uint64(size))
+}
+
+func (t *thriftWriter) beginStruct(id int16) {
+	t.field(id, thriftStruct)
+	t.beginListStruct()
+}
+
+// beginListStruct begins a struct that is an element of a list, which has no field header.
+func (t *thriftWriter) beginListStruct() {
+	t.lastField = append(t.lastField, 0)
+}
+
+func (t *thriftWriter) endStruct() {
+	t.stop()
+	t.lastField = t.lastField[:len(t.lastField)-1]
+}
+
+func (t *thriftWriter) stop() {
+	t.buf.WriteByte(0)
+}
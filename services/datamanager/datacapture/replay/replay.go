@@ -0,0 +1,389 @@
+// Package replay plays back the readings of data capture files, so that components can replay recordings of the
+// components they were captured from.
+package replay
+
+import (
+	"context"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"go.uber.org/multierr"
+	v1 "go.viam.com/api/app/datasync/v1"
+	goutils "go.viam.com/utils"
+
+	"go.viam.com/rdk/resource"
+	"go.viam.com/rdk/services/datamanager/datacapture"
+)
+
+// ErrEnd is returned once all readings have been replayed by replays that do not loop.
+var ErrEnd = errors.New("end of replay")
+
+// Config describes how to replay a recording. Replay models of components use it as their attributes.
+type Config struct {
+	// Source is a directory of data capture files, such as the capture directory of the data manager.
+	Source string `json:"source"`
+	// ComponentName is the name of the component whose readings are replayed. It is only needed if the source has
+	// readings of several components of the same type.
+	ComponentName string `json:"component_name,omitempty"`
+	// Speed scales the timing of the recording, such as 2 to replay it twice as fast. 0 replays it at its original
+	// timing.
+	Speed float64 `json:"speed,omitempty"`
+	// Loop starts the replay over once it reaches its end.
+	Loop bool `json:"loop,omitempty"`
+	// StartSecs is how far into the recording the replay starts.
+	StartSecs float64 `json:"start_secs,omitempty"`
+	// Step, rather than following the timing of the recording, replays the next reading on every call, so that
+	// replays are deterministic however fast they are consumed.
+	Step bool `json:"step,omitempty"`
+	// EncryptionKey is the hex encoded key of encrypted data capture files.
+	EncryptionKey string `json:"encryption_key,omitempty"`
+}
+
+// Validate ensures all parts of the config are valid.
+func (cfg *Config) Validate(path string) ([]string, error) {
+	if cfg.Source == "" {
+		return nil, goutils.NewConfigValidationFieldRequiredError(path, "source")
+	}
+	if cfg.Speed < 0 {
+		return nil, goutils.NewConfigValidationError(path, errors.New("speed cannot be negative"))
+	}
+	if cfg.StartSecs < 0 {
+		return nil, goutils.NewConfigValidationError(path, errors.New("start_secs cannot be negative"))
+	}
+	if cfg.EncryptionKey != "" {
+		if _, err := datacapture.ParseEncryptionKey(cfg.EncryptionKey); err != nil {
+			return nil, goutils.NewConfigValidationError(path, err)
+		}
+	}
+	return nil, nil
+}
+
+// Replay plays back the readings of the methods of one component. All methods share the timeline of the recording,
+// so that readings of different methods are replayed in the order they were captured in.
+type Replay struct {
+	mu     sync.Mutex
+	cfg    Config
+	opts   []datacapture.FileOption
+	tracks map[string]*track
+	// origin is when the earliest reading of any method was captured, and duration how long after it the last one
+	// was. Replays end, or start over if they loop, after period, which gives the last reading the average time
+	// between readings.
+	origin   time.Time
+	duration time.Duration
+	period   time.Duration
+	// The replay is at offset into the recording at the time started.
+	started time.Time
+	offset  time.Duration
+}
+
+// entry locates a reading in the files of a track.
+type entry struct {
+	offset time.Duration
+	file   int
+	index  int
+}
+
+// track is the readings of one method, in the order they were captured in.
+type track struct {
+	files   []string
+	entries []entry
+	// next is the index of the entry replayed next by step replays.
+	next int
+
+	// The file being read, and the index of the reading it is read up to.
+	cursorFile  int
+	cursorIndex int
+	cursor      *os.File
+	captureFile *datacapture.File
+
+	// The reading replayed last, which is replayed again until the replay moves past it.
+	last     int
+	lastData *v1.SensorData
+}
+
+// New returns a Replay of the readings of the component of type componentType that cfg configures. Only the
+// times of readings are read up front, and readings are read from their files as they are replayed.
+func New(cfg Config, componentType resource.SubtypeName) (*Replay, error) {
+	if _, err := cfg.Validate(""); err != nil {
+		return nil, err
+	}
+	r := &Replay{cfg: cfg, tracks: map[string]*track{}}
+	if cfg.EncryptionKey != "" {
+		key, err := datacapture.ParseEncryptionKey(cfg.EncryptionKey)
+		if err != nil {
+			return nil, err
+		}
+		r.opts = append(r.opts, datacapture.WithDecryptionKey(key))
+	}
+
+	componentName := cfg.ComponentName
+	var captured []time.Time
+	type capturedEntry struct {
+		method string
+		entry  entry
+	}
+	var entries []capturedEntry
+	if err := filepath.WalkDir(cfg.Source, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() || filepath.Ext(path) != datacapture.FileExt {
+			return nil
+		}
+		md, times, err := r.index(path)
+		if err != nil {
+			return errors.Wrapf(err, "failed to read %s", path)
+		}
+		if md.GetComponentType() != string(componentType) || len(times) == 0 {
+			return nil
+		}
+		if componentName == "" {
+			componentName = md.GetComponentName()
+		} else if md.GetComponentName() != componentName {
+			if cfg.ComponentName == "" {
+				return errors.Errorf("%s has readings of %s and %s, set component_name to choose which to replay",
+					cfg.Source, componentName, md.GetComponentName())
+			}
+			return nil
+		}
+		t, ok := r.tracks[md.GetMethodName()]
+		if !ok {
+			t = &track{last: -1}
+			r.tracks[md.GetMethodName()] = t
+		}
+		t.files = append(t.files, path)
+		for i, captureTime := range times {
+			captured = append(captured, captureTime)
+			entries = append(entries, capturedEntry{md.GetMethodName(), entry{file: len(t.files) - 1, index: i}})
+		}
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+	if len(entries) == 0 {
+		return nil, errors.Errorf("%s has no readings of a %s to replay", cfg.Source, componentType)
+	}
+
+	r.origin = captured[0]
+	for _, t := range captured {
+		if t.Before(r.origin) {
+			r.origin = t
+		}
+	}
+	for i, e := range entries {
+		e.entry.offset = captured[i].Sub(r.origin)
+		if e.entry.offset > r.duration {
+			r.duration = e.entry.offset
+		}
+		t := r.tracks[e.method]
+		t.entries = append(t.entries, e.entry)
+	}
+	if len(entries) > 1 {
+		r.period = r.duration + r.duration/time.Duration(len(entries)-1)
+	}
+	for _, t := range r.tracks {
+		sort.SliceStable(t.entries, func(i, j int) bool {
+			return t.entries[i].offset < t.entries[j].offset
+		})
+	}
+	r.seekLocked(time.Duration(cfg.StartSecs * float64(time.Second)))
+	return r, nil
+}
+
+// index returns the metadata of the data capture file at path and when each of its readings was captured.
+func (r *Replay) index(path string) (*v1.DataCaptureMetadata, []time.Time, error) {
+	//nolint:gosec
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer goutils.UncheckedErrorFunc(f.Close)
+	captureFile, err := datacapture.ReadFile(f, r.opts...)
+	if err != nil {
+		return nil, nil, err
+	}
+	var times []time.Time
+	for {
+		next, err := captureFile.ReadNext()
+		// Files that are still being written may end in a partial reading.
+		if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+			return captureFile.ReadMetadata(), times, nil
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+		times = append(times, captureTime(next))
+	}
+}
+
+// captureTime returns when reading was captured.
+func captureTime(reading *v1.SensorData) time.Time {
+	if requested := reading.GetMetadata().GetTimeRequested(); requested != nil {
+		return requested.AsTime()
+	}
+	return reading.GetMetadata().GetTimeReceived().AsTime()
+}
+
+// Methods returns the methods there are readings of.
+func (r *Replay) Methods() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	methods := make([]string, 0, len(r.tracks))
+	for method := range r.tracks {
+		methods = append(methods, method)
+	}
+	sort.Strings(methods)
+	return methods
+}
+
+// HasMethod returns whether there are readings of method.
+func (r *Replay) HasMethod(method string) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	_, ok := r.tracks[method]
+	return ok
+}
+
+// Next returns the reading of method at the current time of the replay, which is the last one captured at or
+// before it. Step replays instead return the reading after the one they returned last.
+func (r *Replay) Next(ctx context.Context, method string) (*v1.SensorData, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	t, ok := r.tracks[method]
+	if !ok {
+		return nil, errors.Errorf("the recording has no readings of %s", method)
+	}
+
+	var i int
+	if r.cfg.Step {
+		if t.next >= len(t.entries) {
+			if !r.cfg.Loop {
+				return nil, ErrEnd
+			}
+			t.next = 0
+		}
+		i = t.next
+		t.next++
+	} else {
+		speed := r.cfg.Speed
+		if speed == 0 {
+			speed = 1
+		}
+		offset := r.offset + time.Duration(float64(time.Since(r.started))*speed)
+		// Recordings of a single reading have no period, and replay it for as long as they are replayed.
+		if r.cfg.Loop && r.period > 0 {
+			offset %= r.period
+		} else if offset > r.period && r.period > 0 && !r.cfg.Loop {
+			return nil, ErrEnd
+		}
+		// The last reading at or before offset, or the first if there is none yet.
+		i = sort.Search(len(t.entries), func(i int) bool { return t.entries[i].offset > offset }) - 1
+		if i < 0 {
+			i = 0
+		}
+	}
+	return t.read(ctx, i, r.opts)
+}
+
+// Seek moves the replay to offset into the recording.
+func (r *Replay) Seek(offset time.Duration) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.seekLocked(offset)
+}
+
+func (r *Replay) seekLocked(offset time.Duration) {
+	r.started = time.Now()
+	r.offset = offset
+	for _, t := range r.tracks {
+		t.next = sort.Search(len(t.entries), func(i int) bool { return t.entries[i].offset >= offset })
+	}
+}
+
+// Duration returns how long the recording is, from its first reading to its last.
+func (r *Replay) Duration() time.Duration {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.duration
+}
+
+// DoCommand seeks the replay with {"seek": seconds into the recording}, and returns how long the recording is with
+// {"duration": true}.
+func (r *Replay) DoCommand(ctx context.Context, cmd map[string]interface{}) (map[string]interface{}, error) {
+	if seek, ok := cmd["seek"]; ok {
+		secs, ok := seek.(float64)
+		if !ok || secs < 0 {
+			return nil, errors.Errorf("seek must be a non-negative number of seconds, got %v", seek)
+		}
+		r.Seek(time.Duration(secs * float64(time.Second)))
+		return map[string]interface{}{}, nil
+	}
+	if _, ok := cmd["duration"]; ok {
+		return map[string]interface{}{"duration": r.Duration().Seconds()}, nil
+	}
+	return nil, errors.Errorf("unknown replay command %v, must be seek or duration", cmd)
+}
+
+// Close closes the files being read.
+func (r *Replay) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var err error
+	for _, t := range r.tracks {
+		err = multierr.Combine(err, t.closeCursor())
+	}
+	return err
+}
+
+// read returns the reading of entry i.
+func (t *track) read(ctx context.Context, i int, opts []datacapture.FileOption) (*v1.SensorData, error) {
+	if i == t.last {
+		return t.lastData, nil
+	}
+	e := t.entries[i]
+	// Readings are read in order, so files only need to be reopened to go back in them or to change files.
+	if t.cursor == nil || t.cursorFile != e.file || t.cursorIndex > e.index {
+		if err := t.closeCursor(); err != nil {
+			return nil, err
+		}
+		//nolint:gosec
+		f, err := os.Open(t.files[e.file])
+		if err != nil {
+			return nil, err
+		}
+		captureFile, err := datacapture.ReadFile(f, opts...)
+		if err != nil {
+			return nil, multierr.Combine(err, f.Close())
+		}
+		t.cursor, t.captureFile, t.cursorFile, t.cursorIndex = f, captureFile, e.file, 0
+	}
+	for {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		next, err := t.captureFile.ReadNext()
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to read %s", t.files[e.file])
+		}
+		t.cursorIndex++
+		if t.cursorIndex > e.index {
+			t.last, t.lastData = i, next
+			return next, nil
+		}
+	}
+}
+
+func (t *track) closeCursor() error {
+	if t.cursor == nil {
+		return nil
+	}
+	err := t.cursor.Close()
+	t.cursor, t.captureFile = nil, nil
+	return err
+}
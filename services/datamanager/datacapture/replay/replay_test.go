@@ -0,0 +1,128 @@
+package replay
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	v1 "go.viam.com/api/app/datasync/v1"
+	"go.viam.com/test"
+
+	"go.viam.com/rdk/testutils"
+)
+
+var recordingStart = time.Date(2022, 11, 1, 12, 0, 0, 0, time.UTC)
+
+// writeRecording writes a data capture file of the readings of method of the sensor with the given name, one with
+// the value i at i times interval after recordingStart for each i less than n.
+func writeRecording(t *testing.T, dir, name, method string, n int, interval time.Duration) {
+	t.Helper()
+	readings := make([]*v1.SensorData, 0, n)
+	for i := 0; i < n; i++ {
+		readings = append(readings, testutils.TabularReading(t, recordingStart.Add(time.Duration(i)*interval), map[string]interface{}{"i": i}))
+	}
+	testutils.WriteCaptureFile(t, dir, &v1.DataCaptureMetadata{
+		ComponentType: "sensor",
+		ComponentName: name,
+		MethodName:    method,
+		Type:          v1.DataType_DATA_TYPE_TABULAR_SENSOR,
+	}, readings...)
+}
+
+func nextValue(t *testing.T, r *Replay, method string) (int, error) {
+	t.Helper()
+	reading, err := r.Next(context.Background(), method)
+	if err != nil {
+		return 0, err
+	}
+	return int(reading.GetStruct().AsMap()["i"].(float64)), nil
+}
+
+func TestReplayStep(t *testing.T) {
+	dir := t.TempDir()
+	writeRecording(t, dir, "sensor1", "Readings", 3, time.Second)
+
+	r, err := New(Config{Source: dir, Step: true}, "sensor")
+	test.That(t, err, test.ShouldBeNil)
+	defer r.Close()
+	test.That(t, r.Methods(), test.ShouldResemble, []string{"Readings"})
+	test.That(t, r.Duration(), test.ShouldEqual, 2*time.Second)
+	for i := 0; i < 3; i++ {
+		v, err := nextValue(t, r, "Readings")
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, v, test.ShouldEqual, i)
+	}
+	_, err = nextValue(t, r, "Readings")
+	test.That(t, err, test.ShouldBeError, ErrEnd)
+	_, err = r.Next(context.Background(), "Position")
+	test.That(t, err, test.ShouldNotBeNil)
+
+	// Seeking goes back in the recording, to the first reading at or after the offset.
+	_, err = r.DoCommand(context.Background(), map[string]interface{}{"seek": 0.5})
+	test.That(t, err, test.ShouldBeNil)
+	v, err := nextValue(t, r, "Readings")
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, v, test.ShouldEqual, 1)
+
+	looping, err := New(Config{Source: dir, Step: true, Loop: true, StartSecs: 2}, "sensor")
+	test.That(t, err, test.ShouldBeNil)
+	defer looping.Close()
+	for _, expected := range []int{2, 0, 1, 2, 0} {
+		v, err := nextValue(t, looping, "Readings")
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, v, test.ShouldEqual, expected)
+	}
+}
+
+func TestReplayTiming(t *testing.T) {
+	dir := t.TempDir()
+	writeRecording(t, dir, "sensor1", "Readings", 3, time.Hour)
+
+	// Readings are replayed at the time they were captured, scaled by the speed.
+	r, err := New(Config{Source: dir, Speed: 3600}, "sensor")
+	test.That(t, err, test.ShouldBeNil)
+	defer r.Close()
+	v, err := nextValue(t, r, "Readings")
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, v, test.ShouldEqual, 0)
+	time.Sleep(1100 * time.Millisecond)
+	v, err = nextValue(t, r, "Readings")
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, v, test.ShouldEqual, 1)
+
+	r.Seek(150 * time.Minute)
+	v, err = nextValue(t, r, "Readings")
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, v, test.ShouldEqual, 2)
+	r.Seek(4 * time.Hour)
+	_, err = nextValue(t, r, "Readings")
+	test.That(t, err, test.ShouldBeError, ErrEnd)
+
+	// Looping replays start over once the last reading has been replayed for as long as the others were.
+	looping, err := New(Config{Source: dir, Loop: true, StartSecs: (3*time.Hour + 30*time.Minute).Seconds()}, "sensor")
+	test.That(t, err, test.ShouldBeNil)
+	defer looping.Close()
+	v, err = nextValue(t, looping, "Readings")
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, v, test.ShouldEqual, 0)
+}
+
+func TestReplaySources(t *testing.T) {
+	dir := t.TempDir()
+	writeRecording(t, dir, "sensor1", "Readings", 2, time.Second)
+	writeRecording(t, dir, "sensor2", "Readings", 2, time.Second)
+
+	_, err := New(Config{Source: dir}, "sensor")
+	test.That(t, err, test.ShouldNotBeNil)
+	_, err = New(Config{Source: dir}, "camera")
+	test.That(t, err, test.ShouldNotBeNil)
+	_, err = New(Config{}, "sensor")
+	test.That(t, err, test.ShouldNotBeNil)
+
+	r, err := New(Config{Source: dir, ComponentName: "sensor2", Step: true}, "sensor")
+	test.That(t, err, test.ShouldBeNil)
+	defer r.Close()
+	v, err := nextValue(t, r, "Readings")
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, v, test.ShouldEqual, 0)
+}
@@ -0,0 +1,124 @@
+package datasync
+
+import (
+	"context"
+	"io"
+	"math"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// bandwidthLimiter is a token bucket of bytes shared by all uploads of a Manager. Uploads that take more bytes than
+// are available wait until the bucket has refilled, so the total upload rate converges to bytesPerSec with bursts of
+// up to one second of data.
+type bandwidthLimiter struct {
+	mu          sync.Mutex
+	bytesPerSec float64
+	tokens      float64
+	last        time.Time
+}
+
+// setRate changes the rate of l. A rate of 0 or less disables the limit.
+func (l *bandwidthLimiter) setRate(bytesPerSec int64) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if float64(bytesPerSec) != l.bytesPerSec {
+		l.bytesPerSec = float64(bytesPerSec)
+		l.tokens = l.bytesPerSec
+		l.last = time.Now()
+	}
+}
+
+// wait blocks until n bytes may be uploaded or ctx is done.
+func (l *bandwidthLimiter) wait(ctx context.Context, n int) error {
+	l.mu.Lock()
+	if l.bytesPerSec <= 0 || n <= 0 {
+		l.mu.Unlock()
+		return nil
+	}
+	now := time.Now()
+	l.tokens = math.Min(l.bytesPerSec, l.tokens+now.Sub(l.last).Seconds()*l.bytesPerSec)
+	l.last = now
+	// Taking more tokens than there are puts the bucket into debt that later uploads have to wait out as well.
+	l.tokens -= float64(n)
+	var delay time.Duration
+	if l.tokens < 0 {
+		delay = time.Duration(-l.tokens / l.bytesPerSec * float64(time.Second))
+	}
+	l.mu.Unlock()
+
+	if delay == 0 {
+		return nil
+	}
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+type bandwidthLimiterKey struct{}
+
+// withBandwidthLimiter returns a copy of ctx whose uploads are limited by l.
+func withBandwidthLimiter(ctx context.Context, l *bandwidthLimiter) context.Context {
+	return context.WithValue(ctx, bandwidthLimiterKey{}, l)
+}
+
+// waitForBandwidth blocks until n bytes may be uploaded by the limiter of ctx, if it has one.
+func waitForBandwidth(ctx context.Context, n int) error {
+	l, ok := ctx.Value(bandwidthLimiterKey{}).(*bandwidthLimiter)
+	if !ok {
+		return nil
+	}
+	return l.wait(ctx, n)
+}
+
+// throttledReader limits reads from r to the bandwidth of ctx.
+type throttledReader struct {
+	ctx context.Context
+	r   io.Reader
+}
+
+func (r *throttledReader) Read(p []byte) (int, error) {
+	// Read at most a chunk at a time so that large reads do not burst far beyond the limit.
+	if len(p) > uploadChunkSize {
+		p = p[:uploadChunkSize]
+	}
+	n, err := r.r.Read(p)
+	if waitErr := waitForBandwidth(r.ctx, n); waitErr != nil {
+		return n, waitErr
+	}
+	return n, err
+}
+
+// throttlingTransport limits the request bodies of an HTTP client to the bandwidth of their context.
+type throttlingTransport struct {
+	base http.RoundTripper
+}
+
+func (t *throttlingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Body == nil || req.Body == http.NoBody {
+		return t.base.RoundTrip(req)
+	}
+	throttled := req.Clone(req.Context())
+	throttled.Body = struct {
+		io.Reader
+		io.Closer
+	}{&throttledReader{ctx: req.Context(), r: req.Body}, req.Body}
+	return t.base.RoundTrip(throttled)
+}
+
+// newThrottledHTTPClient returns a copy of client whose request bodies are limited to the bandwidth of their context.
+func newThrottledHTTPClient(client *http.Client) *http.Client {
+	throttled := *client
+	base := client.Transport
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	throttled.Transport = &throttlingTransport{base: base}
+	return &throttled
+}
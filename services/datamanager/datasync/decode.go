@@ -0,0 +1,108 @@
+package datasync
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+
+	"go.viam.com/rdk/services/datamanager/datacapture"
+)
+
+// legacyDecodedDirName is the directory in the progress directory that decoded copies of data capture files used to
+// be kept in. Since they may be decrypted, whatever is left of it is removed.
+const legacyDecodedDirName = "decoded"
+
+// maxParallelDecodes caps how many data capture files are held decoded in memory at once. Each takes as much memory as
+// its readings take decoded, until its upload is done, so uploads of more encoded files than this wait for one to finish
+// however many files may be uploaded at once.
+var maxParallelDecodes = 4
+
+// UploadFile is a file as it is uploaded to a SyncDestination: either the file on disk, or the decoded contents of a
+// data capture file that is compressed or encrypted.
+type UploadFile interface {
+	datacapture.ReadableFile
+	io.ReaderAt
+}
+
+// decodeForUpload returns f, or the contents of f neither compressed nor encrypted if f is a data capture file that
+// is, so that every destination receives decoded files. Files are decoded in memory so that the readings of encrypted
+// files are never written to disk decrypted. They decode to the same bytes every time, so destinations resume
+// uploading them where they left off. Encrypted files are decrypted with whichever of keys they were encrypted with.
+// Decoded files take one of slots, waiting for one to be free, until they are closed.
+func decodeForUpload(ctx context.Context, f *os.File, keys [][]byte, slots chan struct{}) (UploadFile, error) {
+	if !datacapture.IsDataCaptureFile(f) {
+		return f, nil
+	}
+	opts := make([]datacapture.FileOption, 0, len(keys))
+	for _, key := range keys {
+		opts = append(opts, datacapture.WithDecryptionKey(key))
+	}
+	dcFile, err := datacapture.ReadFile(f, opts...)
+	if err != nil {
+		return nil, err
+	}
+	if !dcFile.Encoded() {
+		_, err := f.Seek(0, io.SeekStart)
+		return f, err
+	}
+
+	info, err := f.Stat()
+	if err != nil {
+		return nil, err
+	}
+	select {
+	case slots <- struct{}{}:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+	var decoded bytes.Buffer
+	if err := dcFile.WriteDecoded(&decoded); err != nil {
+		<-slots
+		return nil, err
+	}
+	return &decodedFile{Reader: bytes.NewReader(decoded.Bytes()), name: f.Name(), info: info, slots: slots}, nil
+}
+
+// decodedFile is a data capture file decoded in memory. It has the name and modification time of the file it was
+// decoded from, and frees its slot when it is closed.
+type decodedFile struct {
+	*bytes.Reader
+	name  string
+	info  os.FileInfo
+	slots chan struct{}
+}
+
+func (f *decodedFile) Name() string {
+	return f.name
+}
+
+func (f *decodedFile) Stat() (os.FileInfo, error) {
+	return decodedFileInfo{FileInfo: f.info, size: f.Size()}, nil
+}
+
+func (f *decodedFile) Close() error {
+	if f.slots != nil {
+		<-f.slots
+		f.slots = nil
+	}
+	return nil
+}
+
+// decodedFileInfo is the os.FileInfo of a file that was decoded to size bytes.
+type decodedFileInfo struct {
+	os.FileInfo
+	size int64
+}
+
+func (i decodedFileInfo) Size() int64 {
+	return i.size
+}
+
+// removeLegacyDecoded removes the decoded copies of data capture files that were kept on disk by earlier versions.
+func (s *syncer) removeLegacyDecoded() {
+	if err := os.RemoveAll(filepath.Join(s.progressTracker.progressDir, legacyDecodedDirName)); err != nil {
+		s.logger.Errorw("error while removing decoded files", "error", err)
+	}
+}
@@ -0,0 +1,566 @@
+package datasync
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/edaniels/golog"
+	"github.com/pkg/errors"
+	"go.uber.org/multierr"
+	v1 "go.viam.com/api/app/datasync/v1"
+	"go.viam.com/utils/rpc"
+
+	"go.viam.com/rdk/config"
+	"go.viam.com/rdk/services/datamanager/datacapture"
+	rdkutils "go.viam.com/rdk/utils"
+)
+
+// The types of SyncDestination.
+const (
+	// DestinationCloud syncs to app.viam.com.
+	DestinationCloud = "cloud"
+	// DestinationLocal copies files to a directory, which may be a network file system.
+	DestinationLocal = "local"
+	// DestinationS3 uploads files to a bucket of AWS S3 or an S3-compatible object store such as MinIO.
+	DestinationS3 = "s3"
+	// DestinationHTTP uploads files with a PUT request each, or a PUT request per chunk, to an HTTP endpoint.
+	DestinationHTTP = "http"
+
+	defaultS3Region = "us-east-1"
+)
+
+// SyncDestination is where a Manager uploads the files it syncs.
+type SyncDestination interface {
+	// Upload uploads f, which is either a data capture file or an arbitrary file. A nil error means f is persisted
+	// at the destination and can be deleted.
+	Upload(ctx context.Context, f UploadFile) error
+	Close() error
+}
+
+// DestinationConfig selects and configures the SyncDestination of the data manager. The zero value syncs to the cloud.
+//
+// Destinations other than the cloud store files as they are, at the path of the file relative to the capture
+// directory, under the part ID of the robot if it has one and under Prefix. For example
+// <prefix>/<part id>/<component type>/<component name>/<method>/<file>.capture for data capture files and
+// <prefix>/<part id>/<file> for arbitrary files.
+type DestinationConfig struct {
+	Type   string `json:"type"`
+	Prefix string `json:"prefix"`
+
+	// Path is the directory of local destinations.
+	Path string `json:"path"`
+
+	// Endpoint, Bucket, Region, AccessKeyID and SecretAccessKey configure S3 destinations. Endpoint is only needed for
+	// stores other than AWS S3, which are addressed with path style requests.
+	Endpoint        string `json:"endpoint"`
+	Bucket          string `json:"bucket"`
+	Region          string `json:"region"`
+	AccessKeyID     string `json:"access_key_id"`
+	SecretAccessKey string `json:"secret_access_key"`
+
+	// URL, Headers and ChunkBytes configure HTTP destinations. Each file is uploaded to URL followed by the path of
+	// the file, with Headers such as Authorization. If ChunkBytes is set, files larger than it are uploaded in PUT
+	// requests of ChunkBytes each with a Content-Range header, such as "bytes 0-1023/4096", so that interrupted
+	// uploads resume after the last chunk the endpoint accepted. The endpoint must then accept ranged PUT requests,
+	// and can answer 416 to have an upload start over.
+	URL        string            `json:"url"`
+	Headers    map[string]string `json:"headers"`
+	ChunkBytes int64             `json:"chunk_bytes"`
+}
+
+// IsCloud returns whether cfg syncs to the cloud.
+func (cfg DestinationConfig) IsCloud() bool {
+	return cfg.Type == "" || cfg.Type == DestinationCloud
+}
+
+// Validate ensures all parts of the config are valid.
+func (cfg DestinationConfig) Validate() error {
+	switch cfg.Type {
+	case "", DestinationCloud:
+	case DestinationLocal:
+		if cfg.Path == "" {
+			return errors.New("local sync destinations need a path")
+		}
+	case DestinationS3:
+		if cfg.Bucket == "" {
+			return errors.New("s3 sync destinations need a bucket")
+		}
+		if (cfg.AccessKeyID == "") != (cfg.SecretAccessKey == "") {
+			return errors.New("s3 sync destinations need both an access_key_id and a secret_access_key, or neither")
+		}
+	case DestinationHTTP:
+		u, err := url.Parse(cfg.URL)
+		if err != nil {
+			return errors.Wrap(err, "invalid http sync destination url")
+		}
+		if u.Scheme != "http" && u.Scheme != "https" {
+			return errors.Errorf("http sync destinations need an http or https url, got %q", cfg.URL)
+		}
+		if cfg.ChunkBytes < 0 {
+			return errors.New("http sync destinations need a chunk_bytes of zero or more")
+		}
+	default:
+		return errors.Errorf("unknown sync destination type %q", cfg.Type)
+	}
+	return nil
+}
+
+// NewDestination returns the SyncDestination configured by destCfg for the robot configured by cfg.
+func NewDestination(logger golog.Logger, cfg *config.Config, destCfg DestinationConfig) (SyncDestination, error) {
+	if err := destCfg.Validate(); err != nil {
+		return nil, err
+	}
+	var partID string
+	if cfg.Cloud != nil {
+		partID = cfg.Cloud.ID
+	}
+	switch destCfg.Type {
+	case DestinationLocal:
+		return &localDestination{dir: destCfg.Path, prefix: path.Join(destCfg.Prefix, partID)}, nil
+	case DestinationS3:
+		return newS3Destination(destCfg, path.Join(destCfg.Prefix, partID))
+	case DestinationHTTP:
+		return newHTTPDestination(destCfg, path.Join(destCfg.Prefix, partID))
+	default:
+		if cfg.Cloud == nil {
+			return nil, errors.New("syncing to the cloud needs a robot with a cloud config")
+		}
+		tlsConfig := config.NewTLSConfig(cfg).Config
+		rpcOpts := []rpc.DialOption{
+			rpc.WithTLSConfig(tlsConfig),
+			rpc.WithEntityCredentials(
+				cfg.Cloud.ID,
+				rpc.Credentials{
+					Type:    rdkutils.CredentialsTypeRobotSecret,
+					Payload: cfg.Cloud.Secret,
+				}),
+		}
+		conn, err := NewConnection(logger, appAddress, rpcOpts)
+		if err != nil {
+			return nil, err
+		}
+		return newCloudDestination(NewClient(conn), conn, partID), nil
+	}
+}
+
+// cloudDestination uploads files to the DataSyncService of the cloud, resuming data capture files from the last
+// reading the service acknowledged.
+type cloudDestination struct {
+	client          v1.DataSyncServiceClient
+	conn            rpc.ClientConn
+	partID          string
+	progressTracker progressTracker
+}
+
+func newCloudDestination(client v1.DataSyncServiceClient, conn rpc.ClientConn, partID string) *cloudDestination {
+	return &cloudDestination{client: client, conn: conn, partID: partID, progressTracker: newProgressTracker()}
+}
+
+func (d *cloudDestination) Upload(ctx context.Context, f UploadFile) error {
+	if datacapture.IsDataCaptureFile(f) {
+		dcFile, err := datacapture.ReadFile(f)
+		if err != nil {
+			return err
+		}
+		return uploadDataCaptureFile(ctx, d.progressTracker, d.client, d.partID, dcFile)
+	}
+
+	return uploadArbitraryFile(ctx, d.client, d.partID, f)
+}
+
+func (d *cloudDestination) Close() error {
+	if d.conn == nil {
+		return nil
+	}
+	return d.conn.Close()
+}
+
+// objectKey returns the slash separated path f is stored at under prefix by destinations other than the cloud.
+func objectKey(f UploadFile, prefix string) (string, error) {
+	name := filepath.Base(f.Name())
+	if !datacapture.IsDataCaptureFile(f) {
+		return path.Join(prefix, name), nil
+	}
+	dcFile, err := datacapture.ReadFile(f)
+	if err != nil {
+		return "", err
+	}
+	if _, err := f.Seek(0, io.SeekStart); err != nil {
+		return "", err
+	}
+	md := dcFile.ReadMetadata()
+	return path.Join(prefix, md.GetComponentType(), md.GetComponentName(), md.GetMethodName(), name), nil
+}
+
+// localDestination copies files into a directory. Copies that are interrupted resume where they left off.
+type localDestination struct {
+	dir    string
+	prefix string
+}
+
+func (d *localDestination) Upload(ctx context.Context, f UploadFile) error {
+	key, err := objectKey(f, d.prefix)
+	if err != nil {
+		return err
+	}
+	dst := filepath.Join(d.dir, filepath.FromSlash(key))
+	if err := os.MkdirAll(filepath.Dir(dst), 0o700); err != nil {
+		return err
+	}
+	info, err := f.Stat()
+	if err != nil {
+		return err
+	}
+
+	// Copy to a partial file first so that nothing reading the directory sees partially copied files, and so that
+	// interrupted copies can continue from its end.
+	partial := filepath.Join(filepath.Dir(dst), "."+filepath.Base(dst)+".partial")
+	//nolint:gosec
+	out, err := os.OpenFile(partial, os.O_WRONLY|os.O_CREATE, 0o600)
+	if err != nil {
+		return err
+	}
+	offset, err := out.Seek(0, io.SeekEnd)
+	if err != nil {
+		return multierr.Combine(err, out.Close())
+	}
+	if offset > info.Size() {
+		// The partial file is not of this file, so start over.
+		offset = 0
+		if err := out.Truncate(0); err != nil {
+			return multierr.Combine(err, out.Close())
+		}
+		if _, err := out.Seek(0, io.SeekStart); err != nil {
+			return multierr.Combine(err, out.Close())
+		}
+	}
+	if _, err := f.Seek(offset, io.SeekStart); err != nil {
+		return multierr.Combine(err, out.Close())
+	}
+	if _, err := io.Copy(out, &throttledReader{ctx: ctx, r: f}); err != nil {
+		return multierr.Combine(err, out.Close())
+	}
+	if err := out.Sync(); err != nil {
+		return multierr.Combine(err, out.Close())
+	}
+	if err := out.Close(); err != nil {
+		return err
+	}
+	return os.Rename(partial, dst)
+}
+
+func (d *localDestination) Close() error {
+	return nil
+}
+
+// s3PartSize is the size of the parts of multipart uploads, the smallest S3 allows. Files larger than a part are
+// uploaded in parts, which are resumed after the last uploaded part.
+var s3PartSize int64 = 5 << 20
+
+// s3Destination uploads files as objects of a bucket.
+type s3Destination struct {
+	client          *s3.S3
+	bucket          string
+	prefix          string
+	progressTracker progressTracker
+}
+
+// s3UploadState is the persisted state of a multipart upload.
+type s3UploadState struct {
+	Key      string
+	UploadID string
+	// Size, ModTime and PartSize identify the file the parts were uploaded from.
+	Size     int64
+	ModTime  time.Time
+	PartSize int64
+	Parts    []s3Part
+}
+
+type s3Part struct {
+	Number int64
+	ETag   string
+}
+
+func newS3Destination(cfg DestinationConfig, prefix string) (*s3Destination, error) {
+	awsConfig := aws.NewConfig()
+	if cfg.Region != "" {
+		awsConfig = awsConfig.WithRegion(cfg.Region)
+	} else {
+		awsConfig = awsConfig.WithRegion(defaultS3Region)
+	}
+	if cfg.Endpoint != "" {
+		awsConfig = awsConfig.WithEndpoint(cfg.Endpoint).WithS3ForcePathStyle(true)
+	}
+	if cfg.AccessKeyID != "" {
+		awsConfig = awsConfig.WithCredentials(credentials.NewStaticCredentials(cfg.AccessKeyID, cfg.SecretAccessKey, ""))
+	}
+	sess, err := session.NewSession(awsConfig)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create s3 session")
+	}
+	progressTracker := newProgressTracker()
+	if err := progressTracker.initProgressDir(); err != nil {
+		return nil, errors.Wrap(err, "couldn't initialize progress tracking directory")
+	}
+	return &s3Destination{
+		// The session checks the transport of its own client, so it is throttled once the session is set up.
+		client:          s3.New(sess, aws.NewConfig().WithHTTPClient(newThrottledHTTPClient(sess.Config.HTTPClient))),
+		bucket:          cfg.Bucket,
+		prefix:          prefix,
+		progressTracker: progressTracker,
+	}, nil
+}
+
+func (d *s3Destination) Upload(ctx context.Context, f UploadFile) error {
+	key, err := objectKey(f, d.prefix)
+	if err != nil {
+		return err
+	}
+	info, err := f.Stat()
+	if err != nil {
+		return err
+	}
+	if info.Size() > s3PartSize {
+		return d.uploadParts(ctx, f, key, info)
+	}
+	_, err = d.client.PutObjectWithContext(ctx, &s3.PutObjectInput{
+		Bucket: aws.String(d.bucket),
+		Key:    aws.String(key),
+		Body:   f,
+	})
+	return err
+}
+
+// uploadParts uploads f with a multipart upload, continuing the one of a previous attempt if there is one.
+func (d *s3Destination) uploadParts(ctx context.Context, f UploadFile, key string, info os.FileInfo) error {
+	stateName := filepath.Base(f.Name()) + ".s3"
+	var state s3UploadState
+	found, err := d.progressTracker.readState(stateName, &state)
+	if err != nil {
+		return err
+	}
+	if !found || state.Key != key || state.Size != info.Size() || !state.ModTime.Equal(info.ModTime()) ||
+		state.PartSize != s3PartSize {
+		out, err := d.client.CreateMultipartUploadWithContext(ctx, &s3.CreateMultipartUploadInput{
+			Bucket: aws.String(d.bucket),
+			Key:    aws.String(key),
+		})
+		if err != nil {
+			return err
+		}
+		state = s3UploadState{
+			Key:      key,
+			UploadID: aws.StringValue(out.UploadId),
+			Size:     info.Size(),
+			ModTime:  info.ModTime(),
+			PartSize: s3PartSize,
+		}
+		if err := d.progressTracker.writeState(stateName, state); err != nil {
+			return err
+		}
+	}
+
+	for offset := int64(len(state.Parts)) * state.PartSize; offset < state.Size; offset += state.PartSize {
+		size := state.PartSize
+		if offset+size > state.Size {
+			size = state.Size - offset
+		}
+		number := int64(len(state.Parts) + 1)
+		out, err := d.client.UploadPartWithContext(ctx, &s3.UploadPartInput{
+			Bucket:        aws.String(d.bucket),
+			Key:           aws.String(key),
+			UploadId:      aws.String(state.UploadID),
+			PartNumber:    aws.Int64(number),
+			Body:          io.NewSectionReader(f, offset, size),
+			ContentLength: aws.Int64(size),
+		})
+		if err != nil {
+			return d.checkUploadExists(stateName, err)
+		}
+		state.Parts = append(state.Parts, s3Part{Number: number, ETag: aws.StringValue(out.ETag)})
+		if err := d.progressTracker.writeState(stateName, state); err != nil {
+			return err
+		}
+	}
+
+	parts := make([]*s3.CompletedPart, 0, len(state.Parts))
+	for _, part := range state.Parts {
+		parts = append(parts, &s3.CompletedPart{PartNumber: aws.Int64(part.Number), ETag: aws.String(part.ETag)})
+	}
+	if _, err := d.client.CompleteMultipartUploadWithContext(ctx, &s3.CompleteMultipartUploadInput{
+		Bucket:          aws.String(d.bucket),
+		Key:             aws.String(key),
+		UploadId:        aws.String(state.UploadID),
+		MultipartUpload: &s3.CompletedMultipartUpload{Parts: parts},
+	}); err != nil {
+		return d.checkUploadExists(stateName, err)
+	}
+	return d.progressTracker.deleteState(stateName)
+}
+
+// checkUploadExists forgets the multipart upload stateName if err is because it does not exist anymore, for example
+// because it expired, so that the next attempt starts a new one.
+func (d *s3Destination) checkUploadExists(stateName string, err error) error {
+	var awsErr awserr.Error
+	if errors.As(err, &awsErr) && awsErr.Code() == s3.ErrCodeNoSuchUpload {
+		return multierr.Combine(err, d.progressTracker.deleteState(stateName))
+	}
+	return err
+}
+
+func (d *s3Destination) Close() error {
+	return nil
+}
+
+// httpDestination uploads files with a PUT request each. Files larger than chunkSize, if it is set, are uploaded in
+// PUT requests of a chunk each, which are resumed after the last chunk the endpoint accepted. Without a chunkSize
+// uploads that are interrupted start over, since plain HTTP has no way to ask an endpoint how much of a file it has.
+type httpDestination struct {
+	client          *http.Client
+	url             string
+	headers         map[string]string
+	prefix          string
+	chunkSize       int64
+	progressTracker progressTracker
+}
+
+// httpUploadState is the persisted state of an upload in chunks.
+type httpUploadState struct {
+	Key string
+	// Size, ModTime and ChunkSize identify the file the chunks were uploaded from.
+	Size      int64
+	ModTime   time.Time
+	ChunkSize int64
+	// Offset is the end of the last chunk the endpoint accepted.
+	Offset int64
+}
+
+func newHTTPDestination(cfg DestinationConfig, prefix string) (*httpDestination, error) {
+	progressTracker := newProgressTracker()
+	if cfg.ChunkBytes > 0 {
+		if err := progressTracker.initProgressDir(); err != nil {
+			return nil, errors.Wrap(err, "couldn't initialize progress tracking directory")
+		}
+	}
+	return &httpDestination{
+		client:          newThrottledHTTPClient(&http.Client{}),
+		url:             strings.TrimSuffix(cfg.URL, "/"),
+		headers:         cfg.Headers,
+		prefix:          prefix,
+		chunkSize:       cfg.ChunkBytes,
+		progressTracker: progressTracker,
+	}, nil
+}
+
+func (d *httpDestination) Upload(ctx context.Context, f UploadFile) error {
+	key, err := objectKey(f, d.prefix)
+	if err != nil {
+		return err
+	}
+	info, err := f.Stat()
+	if err != nil {
+		return err
+	}
+	if d.chunkSize > 0 && info.Size() > d.chunkSize {
+		return d.uploadChunks(ctx, f, key, info)
+	}
+	// The client closes request bodies, but f is closed by the Manager once it is done with it.
+	return d.put(ctx, key, io.NopCloser(f), info.Size(), "")
+}
+
+// uploadChunks uploads f in chunks with a Content-Range each, continuing after the last chunk that a previous attempt
+// uploaded if there is one.
+func (d *httpDestination) uploadChunks(ctx context.Context, f UploadFile, key string, info os.FileInfo) error {
+	stateName := filepath.Base(f.Name()) + ".http"
+	var state httpUploadState
+	found, err := d.progressTracker.readState(stateName, &state)
+	if err != nil {
+		return err
+	}
+	if !found || state.Key != key || state.Size != info.Size() || !state.ModTime.Equal(info.ModTime()) ||
+		state.ChunkSize != d.chunkSize {
+		state = httpUploadState{Key: key, Size: info.Size(), ModTime: info.ModTime(), ChunkSize: d.chunkSize}
+	}
+
+	for state.Offset < state.Size {
+		size := state.ChunkSize
+		if state.Offset+size > state.Size {
+			size = state.Size - state.Offset
+		}
+		contentRange := fmt.Sprintf("bytes %d-%d/%d", state.Offset, state.Offset+size-1, state.Size)
+		body := io.NopCloser(io.NewSectionReader(f, state.Offset, size))
+		if err := d.put(ctx, key, body, size, contentRange); err != nil {
+			var httpErr *httpStatusError
+			if errors.As(err, &httpErr) && httpErr.code == http.StatusRequestedRangeNotSatisfiable {
+				// The endpoint lost the chunks uploaded so far, so the next attempt starts over.
+				return multierr.Combine(err, d.progressTracker.deleteState(stateName))
+			}
+			return err
+		}
+		state.Offset += size
+		if err := d.progressTracker.writeState(stateName, state); err != nil {
+			return err
+		}
+	}
+	return d.progressTracker.deleteState(stateName)
+}
+
+// put sends body, of size bytes, to the endpoint at key, as the range contentRange of the file if it is set.
+func (d *httpDestination) put(ctx context.Context, key string, body io.ReadCloser, size int64, contentRange string) error {
+	escaped := make([]string, 0)
+	for _, part := range strings.Split(key, "/") {
+		escaped = append(escaped, url.PathEscape(part))
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, d.url+"/"+strings.Join(escaped, "/"), body)
+	if err != nil {
+		return err
+	}
+	req.ContentLength = size
+	req.Header.Set("Content-Type", "application/octet-stream")
+	if contentRange != "" {
+		req.Header.Set("Content-Range", contentRange)
+	}
+	for k, v := range d.headers {
+		req.Header.Set(k, v)
+	}
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return err
+	}
+	//nolint:errcheck
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return &httpStatusError{code: resp.StatusCode, status: resp.Status, body: strings.TrimSpace(string(body))}
+	}
+	return nil
+}
+
+// httpStatusError is the response of an HTTP destination to an upload that failed.
+type httpStatusError struct {
+	code   int
+	status string
+	body   string
+}
+
+func (e *httpStatusError) Error() string {
+	return fmt.Sprintf("http sync destination responded %s: %s", e.status, e.body)
+}
+
+func (d *httpDestination) Close() error {
+	d.client.CloseIdleConnections()
+	return nil
+}
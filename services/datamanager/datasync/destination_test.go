@@ -0,0 +1,612 @@
+package datasync
+
+import (
+	"bytes"
+	"context"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/edaniels/golog"
+	v1 "go.viam.com/api/app/datasync/v1"
+	"go.viam.com/test"
+	"go.viam.com/utils/testutils"
+	"google.golang.org/protobuf/types/known/structpb"
+
+	"go.viam.com/rdk/config"
+	"go.viam.com/rdk/services/datamanager/datacapture"
+)
+
+func TestDestinationConfig(t *testing.T) {
+	for _, cfg := range []DestinationConfig{
+		{},
+		{Type: DestinationCloud},
+		{Type: DestinationLocal, Path: "/mnt/nfs"},
+		{Type: DestinationS3, Bucket: "b"},
+		{Type: DestinationS3, Bucket: "b", Endpoint: "http://minio:9000", AccessKeyID: "id", SecretAccessKey: "secret"},
+		{Type: DestinationHTTP, URL: "https://example.com/upload"},
+		{Type: DestinationHTTP, URL: "https://example.com/upload", ChunkBytes: 1 << 20},
+	} {
+		test.That(t, cfg.Validate(), test.ShouldBeNil)
+	}
+	for _, cfg := range []DestinationConfig{
+		{Type: "ftp"},
+		{Type: DestinationLocal},
+		{Type: DestinationS3},
+		{Type: DestinationS3, Bucket: "b", AccessKeyID: "id"},
+		{Type: DestinationHTTP},
+		{Type: DestinationHTTP, URL: "ftp://example.com"},
+		{Type: DestinationHTTP, URL: "https://example.com/upload", ChunkBytes: -1},
+	} {
+		test.That(t, cfg.Validate(), test.ShouldNotBeNil)
+	}
+	test.That(t, DestinationConfig{}.IsCloud(), test.ShouldBeTrue)
+	test.That(t, DestinationConfig{Type: DestinationLocal}.IsCloud(), test.ShouldBeFalse)
+
+	_, err := NewDestination(golog.NewTestLogger(t), &config.Config{}, DestinationConfig{})
+	test.That(t, err, test.ShouldNotBeNil)
+}
+
+// objectServer is an HTTP server that stores the bodies of PUT requests by their path, like a minimal S3 bucket.
+type objectServer struct {
+	mu       sync.Mutex
+	objects  map[string][]byte
+	headers  map[string]http.Header
+	failures int
+}
+
+func (s *objectServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if r.Method != http.MethodPut {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	if s.failures > 0 {
+		s.failures--
+		http.Error(w, "try again", http.StatusServiceUnavailable)
+		return
+	}
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	s.objects[r.URL.Path] = body
+	s.headers[r.URL.Path] = r.Header.Clone()
+}
+
+func (s *objectServer) object(path string) ([]byte, http.Header, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	object, ok := s.objects[path]
+	return object, s.headers[path], ok
+}
+
+func TestSyncDestinations(t *testing.T) {
+	initialWaitTimeMillis.Store(50)
+	logger := golog.NewTestLogger(t)
+	robotConfig := &config.Config{Cloud: &config.Cloud{ID: partID}}
+
+	// Each destination returns a function that returns what was uploaded to a path under its prefix.
+	for _, tc := range []struct {
+		name  string
+		setup func(t *testing.T) (DestinationConfig, func(path string) ([]byte, bool))
+	}{
+		{
+			name: "local",
+			setup: func(t *testing.T) (DestinationConfig, func(path string) ([]byte, bool)) {
+				t.Helper()
+				dir := t.TempDir()
+				return DestinationConfig{Type: DestinationLocal, Path: dir, Prefix: "robots"}, func(path string) ([]byte, bool) {
+					//nolint:gosec
+					contents, err := os.ReadFile(filepath.Join(dir, "robots", filepath.FromSlash(path)))
+					return contents, err == nil
+				}
+			},
+		},
+		{
+			name: "s3",
+			setup: func(t *testing.T) (DestinationConfig, func(path string) ([]byte, bool)) {
+				t.Helper()
+				server := &objectServer{objects: map[string][]byte{}, headers: map[string]http.Header{}, failures: 1}
+				httpServer := httptest.NewServer(server)
+				t.Cleanup(httpServer.Close)
+				return DestinationConfig{
+					Type:            DestinationS3,
+					Endpoint:        httpServer.URL,
+					Bucket:          "bucket",
+					Prefix:          "robots",
+					AccessKeyID:     "id",
+					SecretAccessKey: "secret",
+				}, func(path string) ([]byte, bool) {
+					object, headers, ok := server.object("/bucket/robots/" + path)
+					if ok {
+						test.That(t, headers.Get("Authorization"), test.ShouldContainSubstring, "Credential=id/")
+					}
+					return object, ok
+				}
+			},
+		},
+		{
+			name: "http",
+			setup: func(t *testing.T) (DestinationConfig, func(path string) ([]byte, bool)) {
+				t.Helper()
+				server := &objectServer{objects: map[string][]byte{}, headers: map[string]http.Header{}, failures: 1}
+				httpServer := httptest.NewServer(server)
+				t.Cleanup(httpServer.Close)
+				return DestinationConfig{
+					Type:    DestinationHTTP,
+					URL:     httpServer.URL + "/upload/",
+					Prefix:  "robots",
+					Headers: map[string]string{"Authorization": "Bearer token"},
+				}, func(path string) ([]byte, bool) {
+					object, headers, ok := server.object("/upload/robots/" + path)
+					if ok {
+						test.That(t, headers.Get("Authorization"), test.ShouldEqual, "Bearer token")
+					}
+					return object, ok
+				}
+			},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			destCfg, uploaded := tc.setup(t)
+			captureDir := t.TempDir()
+
+			captureFile, err := datacapture.NewFile(captureDir, &v1.DataCaptureMetadata{
+				ComponentType: componentType,
+				ComponentName: componentName,
+				MethodName:    methodName,
+				Type:          v1.DataType_DATA_TYPE_BINARY_SENSOR,
+			})
+			test.That(t, err, test.ShouldBeNil)
+			for _, sd := range createBinarySensorData([][]byte{[]byte("viam"), []byte("robotics")}) {
+				test.That(t, captureFile.WriteNext(sd), test.ShouldBeNil)
+			}
+			test.That(t, captureFile.Close(), test.ShouldBeNil)
+			//nolint:gosec
+			captured, err := os.ReadFile(captureFile.GetPath())
+			test.That(t, err, test.ShouldBeNil)
+
+			arbitraryPath := filepath.Join(captureDir, "notes.txt")
+			test.That(t, os.WriteFile(arbitraryPath, []byte("some notes"), 0o600), test.ShouldBeNil)
+
+			sut, err := NewManagerForDestination(logger, robotConfig, destCfg)
+			test.That(t, err, test.ShouldBeNil)
+			defer sut.Close()
+			sut.Sync([]string{captureFile.GetPath(), arbitraryPath})
+
+			captureKey := strings.Join([]string{partID, componentType, componentName, methodName, filepath.Base(captureFile.GetPath())}, "/")
+			testutils.WaitForAssertion(t, func(tb testing.TB) {
+				tb.Helper()
+				contents, ok := uploaded(captureKey)
+				test.That(tb, ok, test.ShouldBeTrue)
+				test.That(tb, contents, test.ShouldResemble, captured)
+				contents, ok = uploaded(partID + "/notes.txt")
+				test.That(tb, ok, test.ShouldBeTrue)
+				test.That(tb, string(contents), test.ShouldEqual, "some notes")
+			})
+
+			// Uploaded files are deleted.
+			testutils.WaitForAssertion(t, func(tb testing.TB) {
+				tb.Helper()
+				_, err := os.Stat(captureFile.GetPath())
+				test.That(tb, os.IsNotExist(err), test.ShouldBeTrue)
+				_, err = os.Stat(arbitraryPath)
+				test.That(tb, os.IsNotExist(err), test.ShouldBeTrue)
+			})
+		})
+	}
+}
+
+func TestHTTPDestinationRejects(t *testing.T) {
+	initialWaitTimeMillis.Store(50)
+	logger := golog.NewTestLogger(t)
+	var mu sync.Mutex
+	var requests int
+	allowed := false
+	httpServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		requests++
+		if !allowed {
+			http.Error(w, "not allowed", http.StatusForbidden)
+		}
+	}))
+	defer httpServer.Close()
+
+	path := filepath.Join(t.TempDir(), "notes.txt")
+	test.That(t, os.WriteFile(path, []byte("some notes"), 0o600), test.ShouldBeNil)
+	sut, err := NewManagerForDestination(logger, &config.Config{Cloud: &config.Cloud{ID: partID}}, DestinationConfig{
+		Type: DestinationHTTP,
+		URL:  httpServer.URL,
+	})
+	test.That(t, err, test.ShouldBeNil)
+	sut.Sync([]string{path})
+
+	// A rejected upload is not retried until the next sync and the file is kept.
+	testutils.WaitForAssertion(t, func(tb testing.TB) {
+		tb.Helper()
+		mu.Lock()
+		defer mu.Unlock()
+		test.That(tb, requests, test.ShouldEqual, 1)
+	})
+	time.Sleep(time.Millisecond * 300)
+	mu.Lock()
+	test.That(t, requests, test.ShouldEqual, 1)
+	mu.Unlock()
+	_, err = os.Stat(path)
+	test.That(t, err, test.ShouldBeNil)
+
+	// A later sync tries the file again, and it is deleted once the destination accepts it.
+	sut.Sync([]string{path})
+	testutils.WaitForAssertion(t, func(tb testing.TB) {
+		tb.Helper()
+		mu.Lock()
+		defer mu.Unlock()
+		test.That(tb, requests, test.ShouldEqual, 2)
+	})
+	mu.Lock()
+	allowed = true
+	mu.Unlock()
+	sut.Sync([]string{path})
+	testutils.WaitForAssertion(t, func(tb testing.TB) {
+		tb.Helper()
+		_, err := os.Stat(path)
+		test.That(tb, os.IsNotExist(err), test.ShouldBeTrue)
+	})
+	sut.Close()
+	mu.Lock()
+	test.That(t, requests, test.ShouldEqual, 3)
+	mu.Unlock()
+}
+
+func TestSyncDecodesCaptureFiles(t *testing.T) {
+	key := bytes.Repeat([]byte{7}, datacapture.EncryptionKeySize)
+	md := &v1.DataCaptureMetadata{
+		ComponentType: componentType,
+		ComponentName: componentName,
+		MethodName:    methodName,
+		Type:          v1.DataType_DATA_TYPE_BINARY_SENSOR,
+	}
+	write := func(opts ...datacapture.FileOption) string {
+		t.Helper()
+		captureFile, err := datacapture.NewFile(t.TempDir(), md, opts...)
+		test.That(t, err, test.ShouldBeNil)
+		for _, sd := range createBinarySensorData([][]byte{[]byte("viam"), []byte("robotics")}) {
+			test.That(t, captureFile.WriteNext(sd), test.ShouldBeNil)
+		}
+		test.That(t, captureFile.Close(), test.ShouldBeNil)
+		return captureFile.GetPath()
+	}
+	//nolint:gosec
+	plain, err := os.ReadFile(write())
+	test.That(t, err, test.ShouldBeNil)
+	encoded := write(datacapture.WithCompression(datacapture.CompressionZstd), datacapture.WithEncryption(key))
+
+	dir := t.TempDir()
+	sut, err := NewManagerWithDestination(golog.NewTestLogger(t), &localDestination{dir: dir, prefix: partID})
+	test.That(t, err, test.ShouldBeNil)
+	defer sut.Close()
+	// The file was encrypted with a key that has since been replaced by another.
+	otherKey := bytes.Repeat([]byte{8}, datacapture.EncryptionKeySize)
+	sut.(OptionsSetter).SetOptions(Options{DecryptionKeys: [][]byte{otherKey, key}})
+	sut.Sync([]string{encoded})
+
+	// Destinations receive the file decrypted and decompressed.
+	testutils.WaitForAssertion(t, func(tb testing.TB) {
+		tb.Helper()
+		//nolint:gosec
+		uploaded, err := os.ReadFile(filepath.Join(dir, partID, componentType, componentName, methodName, filepath.Base(encoded)))
+		test.That(tb, err, test.ShouldBeNil)
+		test.That(tb, uploaded, test.ShouldResemble, plain)
+		_, err = os.Stat(encoded)
+		test.That(tb, os.IsNotExist(err), test.ShouldBeTrue)
+	})
+}
+
+func TestDecodeForUpload(t *testing.T) {
+	key := bytes.Repeat([]byte{7}, datacapture.EncryptionKeySize)
+	captureFile, err := datacapture.NewFile(t.TempDir(), &v1.DataCaptureMetadata{
+		ComponentType: componentType,
+		ComponentName: componentName,
+		MethodName:    methodName,
+		Type:          v1.DataType_DATA_TYPE_TABULAR_SENSOR,
+	}, datacapture.WithCompression(datacapture.CompressionGzip), datacapture.WithEncryption(key))
+	test.That(t, err, test.ShouldBeNil)
+	// Readings have maps, which are not marshaled in the same order every time.
+	var readings []*structpb.Struct
+	for i := 0; i < 5; i++ {
+		reading, err := structpb.NewStruct(map[string]interface{}{"a": float64(i), "b": "x", "c": true, "d": nil})
+		test.That(t, err, test.ShouldBeNil)
+		readings = append(readings, reading)
+	}
+	for _, sd := range createTabularSensorData(readings) {
+		test.That(t, captureFile.WriteNext(sd), test.ShouldBeNil)
+	}
+	test.That(t, captureFile.Close(), test.ShouldBeNil)
+
+	decode := func() []byte {
+		t.Helper()
+		//nolint:gosec
+		f, err := os.Open(captureFile.GetPath())
+		test.That(t, err, test.ShouldBeNil)
+		defer f.Close()
+		decoded, err := decodeForUpload(context.Background(), f, [][]byte{key}, make(chan struct{}, 1))
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, decoded.Name(), test.ShouldEqual, f.Name())
+		info, err := decoded.Stat()
+		test.That(t, err, test.ShouldBeNil)
+		contents, err := io.ReadAll(decoded)
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, info.Size(), test.ShouldEqual, len(contents))
+		test.That(t, decoded.Close(), test.ShouldBeNil)
+		return contents
+	}
+
+	// Files are decoded in memory, to the same bytes every time so that uploads of them can be resumed.
+	decoded := decode()
+	for i := 0; i < 10; i++ {
+		test.That(t, decode(), test.ShouldResemble, decoded)
+	}
+
+	// Without the key the file was encrypted with, it cannot be decoded.
+	//nolint:gosec
+	f, err := os.Open(captureFile.GetPath())
+	test.That(t, err, test.ShouldBeNil)
+	defer f.Close()
+	slots := make(chan struct{}, 1)
+	_, err = decodeForUpload(context.Background(), f, [][]byte{bytes.Repeat([]byte{8}, datacapture.EncryptionKeySize)}, slots)
+	test.That(t, err, test.ShouldNotBeNil)
+	test.That(t, slots, test.ShouldHaveLength, 0)
+
+	// Decoded files hold their slot until they are closed, so others wait for one to be free.
+	_, err = f.Seek(0, io.SeekStart)
+	test.That(t, err, test.ShouldBeNil)
+	decodedFile, err := decodeForUpload(context.Background(), f, [][]byte{key}, slots)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, slots, test.ShouldHaveLength, 1)
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	_, err = f.Seek(0, io.SeekStart)
+	test.That(t, err, test.ShouldBeNil)
+	_, err = decodeForUpload(ctx, f, [][]byte{key}, slots)
+	test.That(t, err, test.ShouldBeError, context.DeadlineExceeded)
+	test.That(t, decodedFile.Close(), test.ShouldBeNil)
+	test.That(t, slots, test.ShouldHaveLength, 0)
+}
+
+func TestLocalDestinationResumes(t *testing.T) {
+	dir := t.TempDir()
+	src := filepath.Join(t.TempDir(), "notes.txt")
+	contents := []byte("these notes are copied in two attempts")
+	test.That(t, os.WriteFile(src, contents, 0o600), test.ShouldBeNil)
+	destination := &localDestination{dir: dir, prefix: partID}
+	partial := filepath.Join(dir, partID, ".notes.txt.partial")
+	dst := filepath.Join(dir, partID, "notes.txt")
+
+	upload := func() {
+		t.Helper()
+		//nolint:gosec
+		f, err := os.Open(src)
+		test.That(t, err, test.ShouldBeNil)
+		defer f.Close()
+		test.That(t, destination.Upload(context.Background(), f), test.ShouldBeNil)
+	}
+
+	// A copy that was interrupted continues from the end of its partial file.
+	test.That(t, os.MkdirAll(filepath.Dir(partial), 0o700), test.ShouldBeNil)
+	test.That(t, os.WriteFile(partial, contents[:10], 0o600), test.ShouldBeNil)
+	upload()
+	//nolint:gosec
+	copied, err := os.ReadFile(dst)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, copied, test.ShouldResemble, contents)
+	_, err = os.Stat(partial)
+	test.That(t, os.IsNotExist(err), test.ShouldBeTrue)
+
+	// A partial file that cannot be of the file is replaced.
+	test.That(t, os.WriteFile(partial, append(contents, contents...), 0o600), test.ShouldBeNil)
+	upload()
+	//nolint:gosec
+	copied, err = os.ReadFile(dst)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, copied, test.ShouldResemble, contents)
+}
+
+// multipartServer is an S3 compatible HTTP server that only supports multipart uploads, and denies the upload of
+// part failPart once.
+type multipartServer struct {
+	mu          sync.Mutex
+	parts       map[string][]byte
+	partUploads int
+	failPart    string
+	objects     map[string][]byte
+}
+
+func (s *multipartServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	query := r.URL.Query()
+	switch {
+	case r.Method == http.MethodPost && query.Has("uploads"):
+		fmt.Fprintf(w, "<InitiateMultipartUploadResult><UploadId>upload1</UploadId></InitiateMultipartUploadResult>")
+	case r.Method == http.MethodPut && query.Get("uploadId") == "upload1":
+		number := query.Get("partNumber")
+		if number == s.failPart {
+			s.failPart = ""
+			w.WriteHeader(http.StatusForbidden)
+			fmt.Fprintf(w, "<Error><Code>AccessDenied</Code><Message>denied</Message></Error>")
+			return
+		}
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		s.partUploads++
+		s.parts[number] = body
+		w.Header().Set("ETag", `"etag`+number+`"`)
+	case r.Method == http.MethodPost && query.Get("uploadId") == "upload1":
+		var complete struct {
+			Parts []struct {
+				PartNumber string
+				ETag       string
+			} `xml:"Part"`
+		}
+		if err := xml.NewDecoder(r.Body).Decode(&complete); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		var object []byte
+		for _, part := range complete.Parts {
+			if part.ETag != `"etag`+part.PartNumber+`"` {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			object = append(object, s.parts[part.PartNumber]...)
+		}
+		s.objects[r.URL.Path] = object
+		fmt.Fprintf(w, "<CompleteMultipartUploadResult><ETag>etag</ETag></CompleteMultipartUploadResult>")
+	default:
+		w.WriteHeader(http.StatusNotImplemented)
+	}
+}
+
+func TestS3DestinationResumes(t *testing.T) {
+	defer func(partSize int64) {
+		s3PartSize = partSize
+	}(s3PartSize)
+	s3PartSize = 10
+
+	server := &multipartServer{parts: map[string][]byte{}, objects: map[string][]byte{}, failPart: "2"}
+	httpServer := httptest.NewServer(server)
+	defer httpServer.Close()
+	progressDir := t.TempDir()
+	newDestination := func() SyncDestination {
+		t.Helper()
+		destination, err := newS3Destination(DestinationConfig{
+			Type:            DestinationS3,
+			Endpoint:        httpServer.URL,
+			Bucket:          "bucket",
+			AccessKeyID:     "id",
+			SecretAccessKey: "secret",
+		}, partID)
+		test.That(t, err, test.ShouldBeNil)
+		destination.progressTracker.progressDir = progressDir
+		return destination
+	}
+
+	src := filepath.Join(t.TempDir(), "notes.txt")
+	contents := []byte("these notes are uploaded in three parts")
+	test.That(t, os.WriteFile(src, contents, 0o600), test.ShouldBeNil)
+	//nolint:gosec
+	f, err := os.Open(src)
+	test.That(t, err, test.ShouldBeNil)
+	defer f.Close()
+
+	// The upload is interrupted after the first part, and a new destination, as after a restart, resumes it.
+	test.That(t, newDestination().Upload(context.Background(), f), test.ShouldNotBeNil)
+	state, err := os.ReadFile(filepath.Join(progressDir, "notes.txt.s3"))
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, string(state), test.ShouldContainSubstring, "etag1")
+	_, err = f.Seek(0, io.SeekStart)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, newDestination().Upload(context.Background(), f), test.ShouldBeNil)
+
+	test.That(t, server.objects["/bucket/"+partID+"/notes.txt"], test.ShouldResemble, contents)
+	test.That(t, server.partUploads, test.ShouldEqual, 4)
+	_, err = os.Stat(filepath.Join(progressDir, "notes.txt.s3"))
+	test.That(t, os.IsNotExist(err), test.ShouldBeTrue)
+}
+
+// chunkServer is an HTTP server that assembles files from PUT requests with a Content-Range, and denies the chunk
+// at failOffset once.
+type chunkServer struct {
+	mu         sync.Mutex
+	objects    map[string][]byte
+	puts       int
+	failOffset int
+}
+
+func (s *chunkServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var start, end, size int
+	if _, err := fmt.Sscanf(r.Header.Get("Content-Range"), "bytes %d-%d/%d", &start, &end, &size); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	s.puts++
+	if start == s.failOffset {
+		s.failOffset = -1
+		http.Error(w, "try again", http.StatusServiceUnavailable)
+		return
+	}
+	if start != len(s.objects[r.URL.Path]) {
+		w.WriteHeader(http.StatusRequestedRangeNotSatisfiable)
+		return
+	}
+	body, err := io.ReadAll(r.Body)
+	if err != nil || len(body) != end-start+1 {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	s.objects[r.URL.Path] = append(s.objects[r.URL.Path], body...)
+}
+
+func TestHTTPDestinationResumes(t *testing.T) {
+	server := &chunkServer{objects: map[string][]byte{}, failOffset: 10}
+	httpServer := httptest.NewServer(server)
+	defer httpServer.Close()
+	progressDir := t.TempDir()
+	newDestination := func() SyncDestination {
+		t.Helper()
+		destination, err := newHTTPDestination(DestinationConfig{Type: DestinationHTTP, URL: httpServer.URL, ChunkBytes: 10}, partID)
+		test.That(t, err, test.ShouldBeNil)
+		destination.progressTracker.progressDir = progressDir
+		return destination
+	}
+
+	src := filepath.Join(t.TempDir(), "notes.txt")
+	contents := []byte("these notes are uploaded in four chunks")
+	test.That(t, os.WriteFile(src, contents, 0o600), test.ShouldBeNil)
+	//nolint:gosec
+	f, err := os.Open(src)
+	test.That(t, err, test.ShouldBeNil)
+	defer f.Close()
+
+	// The upload is interrupted after the first chunk, and a new destination, as after a restart, resumes it.
+	test.That(t, newDestination().Upload(context.Background(), f), test.ShouldNotBeNil)
+	state, err := os.ReadFile(filepath.Join(progressDir, "notes.txt.http"))
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, string(state), test.ShouldContainSubstring, `"Offset":10`)
+	_, err = f.Seek(0, io.SeekStart)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, newDestination().Upload(context.Background(), f), test.ShouldBeNil)
+
+	test.That(t, server.objects["/"+partID+"/notes.txt"], test.ShouldResemble, contents)
+	test.That(t, server.puts, test.ShouldEqual, 5)
+	_, err = os.Stat(filepath.Join(progressDir, "notes.txt.http"))
+	test.That(t, os.IsNotExist(err), test.ShouldBeTrue)
+
+	// An endpoint that lost the chunks of an upload has it start over.
+	delete(server.objects, "/"+partID+"/notes.txt")
+	test.That(t, os.WriteFile(filepath.Join(progressDir, "notes.txt.http"), state, 0o600), test.ShouldBeNil)
+	_, err = f.Seek(0, io.SeekStart)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, newDestination().Upload(context.Background(), f), test.ShouldNotBeNil)
+	_, err = f.Seek(0, io.SeekStart)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, newDestination().Upload(context.Background(), f), test.ShouldBeNil)
+	test.That(t, server.objects["/"+partID+"/notes.txt"], test.ShouldResemble, contents)
+}
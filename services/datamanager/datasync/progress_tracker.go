@@ -1,6 +1,7 @@
 package datasync
 
 import (
+	"encoding/json"
 	"os"
 	"path/filepath"
 	"strconv"
@@ -13,12 +14,23 @@ import (
 
 var viamProgressDotDir = filepath.Join(os.Getenv("HOME"), ".viam", "progress")
 
+// progressTracker tracks which files are being uploaded, how many readings of data capture files the cloud has
+// acknowledged, and the state of other resumable uploads. All but which files are being uploaded is persisted in
+// progressDir so that uploads resume where they left off after a restart.
 type progressTracker struct {
 	lock        *sync.Mutex
 	m           map[string]struct{}
 	progressDir string
 }
 
+func newProgressTracker() progressTracker {
+	return progressTracker{
+		lock:        &sync.Mutex{},
+		m:           make(map[string]struct{}),
+		progressDir: viamProgressDotDir,
+	}
+}
+
 func (pt *progressTracker) inProgress(k string) bool {
 	pt.lock.Lock()
 	defer pt.lock.Unlock()
@@ -96,3 +108,42 @@ func (pt *progressTracker) initProgressDir() error {
 	}
 	return nil
 }
+
+// readState reads the state of the upload name into v, and returns whether there was one.
+func (pt *progressTracker) readState(name string, v interface{}) (bool, error) {
+	//nolint:gosec
+	bs, err := os.ReadFile(filepath.Join(pt.progressDir, name))
+	if errors.Is(err, os.ErrNotExist) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	if err := json.Unmarshal(bs, v); err != nil {
+		// A state that was cut off by a crash is as good as none.
+		return false, nil //nolint:nilerr
+	}
+	return true, nil
+}
+
+// writeState persists v as the state of the upload name.
+func (pt *progressTracker) writeState(name string, v interface{}) error {
+	bs, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	path := filepath.Join(pt.progressDir, name)
+	// Write to a temporary file first so that a crash never leaves a state that is cut off.
+	if err := os.WriteFile(path+".tmp", bs, 0o600); err != nil {
+		return err
+	}
+	return os.Rename(path+".tmp", path)
+}
+
+// deleteState deletes the state of the upload name, if there is one.
+func (pt *progressTracker) deleteState(name string) error {
+	if err := os.Remove(filepath.Join(pt.progressDir, name)); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,109 @@
+package datasync
+
+import (
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// windowLayout is the layout of the start and end of sync windows, a time of day in local time.
+const windowLayout = "15:04"
+
+// Options configure how fast, when and in which order a Manager uploads. The zero value uploads everything as soon
+// as it is synced, all at once and at full speed.
+type Options struct {
+	// MaxUploadBytesPerSec caps the total upload rate. 0 is unlimited.
+	MaxUploadBytesPerSec int64 `json:"max_upload_bytes_per_sec"`
+	// MaxParallelUploads caps how many files are uploaded at once. Files waiting to be uploaded start in order of
+	// their Priority. 0 is unlimited. Compressed or encrypted data capture files are decoded in memory to be uploaded,
+	// so only a few of them are uploaded at once however many files may be.
+	MaxParallelUploads int `json:"max_parallel_uploads"`
+	// Windows are the times of day uploads run in. Uploads still running when a window closes are stopped and
+	// resume in the next window. If empty, uploads run at any time.
+	Windows []Window `json:"windows"`
+
+	// Priority returns the priority of the file at path. Files of higher priority are uploaded first.
+	Priority func(path string) int `json:"-"`
+	// DecryptionKeys decrypt encrypted data capture files, which are uploaded decrypted and decompressed. Files are
+	// decrypted with whichever key they were encrypted with, so keys that were replaced are kept until no file that
+	// they encrypted is left.
+	DecryptionKeys [][]byte `json:"-"`
+}
+
+// Window is a time of day uploads run in, from Start until End in the local time of the robot, such as "22:00"
+// until "06:00". Windows with an End before their Start span midnight.
+type Window struct {
+	Start string `json:"start"`
+	End   string `json:"end"`
+}
+
+// Validate ensures all parts of the options are valid.
+func (opts Options) Validate() error {
+	if opts.MaxUploadBytesPerSec < 0 {
+		return errors.New("max_upload_bytes_per_sec cannot be negative")
+	}
+	if opts.MaxParallelUploads < 0 {
+		return errors.New("max_parallel_uploads cannot be negative")
+	}
+	for _, w := range opts.Windows {
+		start, end, err := w.parse()
+		if err != nil {
+			return err
+		}
+		if start == end {
+			return errors.Errorf("sync window from %s until %s is empty", w.Start, w.End)
+		}
+	}
+	return nil
+}
+
+// parse returns the start and end of w as durations since midnight.
+func (w Window) parse() (time.Duration, time.Duration, error) {
+	start, err := time.Parse(windowLayout, w.Start)
+	if err != nil {
+		return 0, 0, errors.Wrapf(err, "invalid sync window start %q, must be of the form HH:MM", w.Start)
+	}
+	end, err := time.Parse(windowLayout, w.End)
+	if err != nil {
+		return 0, 0, errors.Wrapf(err, "invalid sync window end %q, must be of the form HH:MM", w.End)
+	}
+	sinceMidnight := func(t time.Time) time.Duration {
+		return time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute
+	}
+	return sinceMidnight(start), sinceMidnight(end), nil
+}
+
+// nextWindow returns the start and end of the window now is in, or of the next window if now is not in one. The end
+// is zero if there are no windows and uploads always run.
+func nextWindow(windows []Window, now time.Time) (time.Time, time.Time) {
+	var nextStart, nextEnd time.Time
+	// Windows that started yesterday may still be open, and those that start tomorrow may be the next one.
+	midnight := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	for _, w := range windows {
+		startOffset, endOffset, err := w.parse()
+		if err != nil {
+			continue
+		}
+		if endOffset <= startOffset {
+			endOffset += 24 * time.Hour
+		}
+		for day := -1; day <= 1; day++ {
+			dayStart := midnight.AddDate(0, 0, day)
+			start, end := dayStart.Add(startOffset), dayStart.Add(endOffset)
+			if !end.After(now) {
+				continue
+			}
+			if start.Before(now) {
+				start = now
+			}
+			// Of overlapping windows, the one that stays open longest wins.
+			if nextEnd.IsZero() || start.Before(nextStart) || (start.Equal(nextStart) && end.After(nextEnd)) {
+				nextStart, nextEnd = start, end
+			}
+		}
+	}
+	if nextEnd.IsZero() {
+		return now, time.Time{}
+	}
+	return nextStart, nextEnd
+}
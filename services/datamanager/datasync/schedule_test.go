@@ -0,0 +1,174 @@
+package datasync
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/edaniels/golog"
+	"go.viam.com/test"
+	"go.viam.com/utils/testutils"
+)
+
+func TestSyncOptions(t *testing.T) {
+	test.That(t, Options{}.Validate(), test.ShouldBeNil)
+	test.That(t, Options{
+		MaxUploadBytesPerSec: 1000,
+		MaxParallelUploads:   2,
+		Windows:              []Window{{Start: "22:00", End: "06:00"}},
+	}.Validate(), test.ShouldBeNil)
+	test.That(t, Options{MaxUploadBytesPerSec: -1}.Validate(), test.ShouldNotBeNil)
+	test.That(t, Options{MaxParallelUploads: -1}.Validate(), test.ShouldNotBeNil)
+	test.That(t, Options{Windows: []Window{{Start: "10pm", End: "06:00"}}}.Validate(), test.ShouldNotBeNil)
+	test.That(t, Options{Windows: []Window{{Start: "22:00", End: "25:00"}}}.Validate(), test.ShouldNotBeNil)
+	test.That(t, Options{Windows: []Window{{Start: "22:00", End: "22:00"}}}.Validate(), test.ShouldNotBeNil)
+}
+
+func TestNextWindow(t *testing.T) {
+	at := func(day, hour, minute int) time.Time {
+		return time.Date(2022, 11, day, hour, minute, 0, 0, time.Local)
+	}
+
+	start, end := nextWindow(nil, at(1, 12, 0))
+	test.That(t, start, test.ShouldEqual, at(1, 12, 0))
+	test.That(t, end.IsZero(), test.ShouldBeTrue)
+
+	overnight := []Window{{Start: "22:00", End: "06:00"}}
+	// Before the window opens today.
+	start, end = nextWindow(overnight, at(1, 12, 0))
+	test.That(t, start, test.ShouldEqual, at(1, 22, 0))
+	test.That(t, end, test.ShouldEqual, at(2, 6, 0))
+	// In the window that opened yesterday.
+	start, end = nextWindow(overnight, at(2, 3, 0))
+	test.That(t, start, test.ShouldEqual, at(2, 3, 0))
+	test.That(t, end, test.ShouldEqual, at(2, 6, 0))
+	// After the window closed this morning.
+	start, end = nextWindow(overnight, at(2, 6, 0))
+	test.That(t, start, test.ShouldEqual, at(2, 22, 0))
+	test.That(t, end, test.ShouldEqual, at(3, 6, 0))
+
+	lunch := append([]Window{{Start: "12:00", End: "13:00"}}, overnight...)
+	start, end = nextWindow(lunch, at(1, 9, 0))
+	test.That(t, start, test.ShouldEqual, at(1, 12, 0))
+	test.That(t, end, test.ShouldEqual, at(1, 13, 0))
+	start, end = nextWindow(lunch, at(1, 12, 30))
+	test.That(t, start, test.ShouldEqual, at(1, 12, 30))
+	test.That(t, end, test.ShouldEqual, at(1, 13, 0))
+	start, end = nextWindow(lunch, at(1, 13, 30))
+	test.That(t, start, test.ShouldEqual, at(1, 22, 0))
+	test.That(t, end, test.ShouldEqual, at(2, 6, 0))
+
+	// Overlapping windows stay open until the last one closes.
+	start, end = nextWindow([]Window{{Start: "12:00", End: "13:00"}, {Start: "12:00", End: "14:00"}}, at(1, 12, 30))
+	test.That(t, start, test.ShouldEqual, at(1, 12, 30))
+	test.That(t, end, test.ShouldEqual, at(1, 14, 0))
+}
+
+func TestBandwidthLimiter(t *testing.T) {
+	limiter := &bandwidthLimiter{}
+	ctx := context.Background()
+	// Unlimited.
+	test.That(t, limiter.wait(ctx, 1<<30), test.ShouldBeNil)
+
+	limiter.setRate(1000)
+	start := time.Now()
+	// A full bucket of one second of data does not wait, but the next half second of data does.
+	test.That(t, limiter.wait(ctx, 1000), test.ShouldBeNil)
+	test.That(t, time.Since(start), test.ShouldBeLessThan, 100*time.Millisecond)
+	test.That(t, limiter.wait(ctx, 500), test.ShouldBeNil)
+	test.That(t, time.Since(start), test.ShouldBeGreaterThanOrEqualTo, 400*time.Millisecond)
+
+	// Waits end with their context, and their debt is paid by later uploads.
+	for _, n := range []int{10000, 1} {
+		timeoutCtx, cancel := context.WithTimeout(ctx, 50*time.Millisecond)
+		test.That(t, limiter.wait(timeoutCtx, n), test.ShouldBeError, context.DeadlineExceeded)
+		cancel()
+	}
+}
+
+// recordingDestination records the names of the files uploaded to it, in order.
+type recordingDestination struct {
+	mu       sync.Mutex
+	uploaded []string
+}
+
+func (d *recordingDestination) Upload(ctx context.Context, f UploadFile) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.uploaded = append(d.uploaded, filepath.Base(f.Name()))
+	return nil
+}
+
+func (d *recordingDestination) Close() error {
+	return nil
+}
+
+func (d *recordingDestination) getUploaded() []string {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return append([]string(nil), d.uploaded...)
+}
+
+func TestSyncScheduling(t *testing.T) {
+	dir := t.TempDir()
+	var paths []string
+	for _, name := range []string{"low1", "high", "low2", "medium"} {
+		path := filepath.Join(dir, name)
+		test.That(t, os.WriteFile(path, []byte(name), 0o600), test.ShouldBeNil)
+		paths = append(paths, path)
+	}
+	priorities := map[string]int{"high": 2, "medium": 1}
+	priority := func(path string) int {
+		return priorities[filepath.Base(path)]
+	}
+
+	t.Run("priority", func(t *testing.T) {
+		destination := &recordingDestination{}
+		sut, err := NewManagerWithDestination(golog.NewTestLogger(t), destination)
+		test.That(t, err, test.ShouldBeNil)
+		defer sut.Close()
+		sut.(OptionsSetter).SetOptions(Options{MaxParallelUploads: 1, Priority: priority})
+		sut.Sync(paths)
+		testutils.WaitForAssertion(t, func(tb testing.TB) {
+			tb.Helper()
+			test.That(tb, destination.getUploaded(), test.ShouldResemble, []string{"high", "medium", "low1", "low2"})
+		})
+	})
+
+	t.Run("window", func(t *testing.T) {
+		for _, path := range paths {
+			test.That(t, os.WriteFile(path, []byte("again"), 0o600), test.ShouldBeNil)
+		}
+		destination := &recordingDestination{}
+		sut, err := NewManagerWithDestination(golog.NewTestLogger(t), destination)
+		test.That(t, err, test.ShouldBeNil)
+		defer sut.Close()
+
+		// A window that opens in an hour does not upload anything now.
+		now := time.Now()
+		sut.(OptionsSetter).SetOptions(Options{Windows: []Window{{
+			Start: now.Add(time.Hour).Format(windowLayout),
+			End:   now.Add(2 * time.Hour).Format(windowLayout),
+		}}})
+		sut.Sync(paths)
+		time.Sleep(syncWaitTime)
+		test.That(t, destination.getUploaded(), test.ShouldBeEmpty)
+		for _, path := range paths {
+			_, err := os.Stat(path)
+			test.That(t, err, test.ShouldBeNil)
+		}
+
+		// Waiting uploads start as soon as the windows change to include now.
+		sut.(OptionsSetter).SetOptions(Options{Windows: []Window{{
+			Start: now.Add(-time.Hour).Format(windowLayout),
+			End:   now.Add(time.Hour).Format(windowLayout),
+		}}})
+		testutils.WaitForAssertion(t, func(tb testing.TB) {
+			tb.Helper()
+			test.That(tb, destination.getUploaded(), test.ShouldHaveLength, len(paths))
+		})
+	})
+}
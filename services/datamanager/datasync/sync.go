@@ -1,12 +1,18 @@
-// Package datasync contains interfaces for syncing data from robots to the app.viam.com cloud.
+// Package datasync contains interfaces for syncing data from robots to the app.viam.com cloud or another
+// SyncDestination.
 package datasync
 
 import (
+	"container/heap"
 	"context"
+	"io"
+	"net/http"
 	"os"
 	"sync"
 	"time"
 
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/s3"
 	"github.com/edaniels/golog"
 	"github.com/pkg/errors"
 	"go.uber.org/atomic"
@@ -17,8 +23,6 @@ import (
 	"google.golang.org/grpc/status"
 
 	"go.viam.com/rdk/config"
-	"go.viam.com/rdk/services/datamanager/datacapture"
-	rdkutils "go.viam.com/rdk/utils"
 )
 
 /**
@@ -41,129 +45,288 @@ var (
 	uploadChunkSize = 32768
 )
 
-// Manager is responsible for enqueuing files in captureDir and uploading them to the cloud.
+// Manager is responsible for enqueuing files in captureDir and uploading them to its SyncDestination.
 type Manager interface {
 	Sync(paths []string)
 	Close()
 }
 
-// syncer is responsible for uploading files in captureDir to the cloud.
+// An OptionsSetter is a Manager whose uploads can be configured with Options.
+type OptionsSetter interface {
+	// SetOptions changes how fast, when and in which order files are uploaded, including those already enqueued.
+	SetOptions(opts Options)
+}
+
+// A Remover deletes files on behalf of others, such as the retention janitor of the data manager, so that they never
+// delete a file from under its upload.
+type Remover interface {
+	// Remove deletes the file unless it is being uploaded, dropping it from the queue if it is waiting to be. It
+	// returns whether the file was deleted.
+	Remove(path string) (bool, error)
+}
+
+// syncer is responsible for uploading files in captureDir to its destination.
 type syncer struct {
-	partID            string
-	conn              rpc.ClientConn
-	client            v1.DataSyncServiceClient
+	destination       SyncDestination
 	logger            golog.Logger
 	progressTracker   progressTracker
 	backgroundWorkers sync.WaitGroup
 	cancelCtx         context.Context
 	cancelFunc        func()
+
+	bandwidth *bandwidthLimiter
+	// decodeSlots holds a value for each data capture file held decoded in memory.
+	decodeSlots chan struct{}
+
+	// lock guards the options, the queue of files waiting to be uploaded and the files being uploaded.
+	lock      sync.Mutex
+	opts      Options
+	queue     uploadQueue
+	queued    uint64
+	running   int
+	uploading map[string]struct{}
+	// optionsChanged is closed and replaced when the options change, to wake up uploads waiting for a window.
+	optionsChanged chan struct{}
 }
 
-// ManagerConstructor is a function for building a Manager.
-type ManagerConstructor func(logger golog.Logger, cfg *config.Config) (Manager, error)
+// ManagerConstructor is a function for building a Manager that syncs to the destination configured by destCfg.
+type ManagerConstructor func(logger golog.Logger, cfg *config.Config, destCfg DestinationConfig) (Manager, error)
 
 // NewDefaultManager returns the default Manager that syncs data to app.viam.com.
 func NewDefaultManager(logger golog.Logger, cfg *config.Config) (Manager, error) {
-	tlsConfig := config.NewTLSConfig(cfg).Config
-	cloudConfig := cfg.Cloud
-	rpcOpts := []rpc.DialOption{
-		rpc.WithTLSConfig(tlsConfig),
-		rpc.WithEntityCredentials(
-			cloudConfig.ID,
-			rpc.Credentials{
-				Type:    rdkutils.CredentialsTypeRobotSecret,
-				Payload: cloudConfig.Secret,
-			}),
-	}
-
-	conn, err := NewConnection(logger, appAddress, rpcOpts)
+	return NewManagerForDestination(logger, cfg, DestinationConfig{})
+}
+
+// NewManagerForDestination returns a Manager that syncs data to the destination configured by destCfg.
+func NewManagerForDestination(logger golog.Logger, cfg *config.Config, destCfg DestinationConfig) (Manager, error) {
+	destination, err := NewDestination(logger, cfg, destCfg)
 	if err != nil {
 		return nil, err
 	}
-	client := NewClient(conn)
-	return NewManager(logger, cfg.Cloud.ID, client, conn)
+	return NewManagerWithDestination(logger, destination)
 }
 
-// NewManager returns a new syncer.
+// NewManager returns a new syncer that syncs data to the cloud with client.
 func NewManager(logger golog.Logger, partID string, client v1.DataSyncServiceClient,
 	conn rpc.ClientConn,
 ) (Manager, error) {
+	return NewManagerWithDestination(logger, newCloudDestination(client, conn, partID))
+}
+
+// NewManagerWithDestination returns a new syncer that syncs data to destination, and closes it when it is closed.
+func NewManagerWithDestination(logger golog.Logger, destination SyncDestination) (Manager, error) {
 	cancelCtx, cancelFunc := context.WithCancel(context.Background())
 	ret := syncer{
-		conn:   conn,
-		client: client,
-		logger: logger,
-		progressTracker: progressTracker{
-			lock:        &sync.Mutex{},
-			m:           make(map[string]struct{}),
-			progressDir: viamProgressDotDir,
-		},
+		destination:       destination,
+		logger:            logger,
+		progressTracker:   newProgressTracker(),
 		backgroundWorkers: sync.WaitGroup{},
 		cancelCtx:         cancelCtx,
 		cancelFunc:        cancelFunc,
-		partID:            partID,
+		bandwidth:         &bandwidthLimiter{},
+		decodeSlots:       make(chan struct{}, maxParallelDecodes),
+		optionsChanged:    make(chan struct{}),
+		uploading:         make(map[string]struct{}),
 	}
 	if err := ret.progressTracker.initProgressDir(); err != nil {
 		return nil, errors.Wrap(err, "couldn't initialize progress tracking directory")
 	}
+	ret.removeLegacyDecoded()
 	return &ret, nil
 }
 
+// SetOptions changes the options of s.
+func (s *syncer) SetOptions(opts Options) {
+	s.bandwidth.setRate(opts.MaxUploadBytesPerSec)
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.opts = opts
+	close(s.optionsChanged)
+	s.optionsChanged = make(chan struct{})
+	for i, item := range s.queue {
+		item.priority = s.priorityLocked(item.path)
+		s.queue[i] = item
+	}
+	heap.Init(&s.queue)
+	s.startUploadsLocked()
+}
+
 // Close closes all resources (goroutines) associated with s.
 func (s *syncer) Close() {
 	s.cancelFunc()
 	s.backgroundWorkers.Wait()
-	if s.conn != nil {
-		if err := s.conn.Close(); err != nil {
-			s.logger.Errorw("error closing datasync server connection", "error", err)
+	if err := s.destination.Close(); err != nil {
+		s.logger.Errorw("error closing sync destination", "error", err)
+	}
+}
+
+func (s *syncer) Sync(paths []string) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	for _, p := range paths {
+		if s.progressTracker.inProgress(p) {
+			continue
 		}
+		s.progressTracker.mark(p)
+		heap.Push(&s.queue, queuedUpload{path: p, priority: s.priorityLocked(p), seq: s.queued})
+		s.queued++
 	}
+	s.startUploadsLocked()
 }
 
+func (s *syncer) priorityLocked(path string) int {
+	if s.opts.Priority == nil {
+		return 0
+	}
+	return s.opts.Priority(path)
+}
+
+// startUploadsLocked starts uploading the queued files of highest priority, up to the maximum of parallel uploads.
+func (s *syncer) startUploadsLocked() {
+	for s.queue.Len() > 0 && (s.opts.MaxParallelUploads <= 0 || s.running < s.opts.MaxParallelUploads) {
+		if s.cancelCtx.Err() != nil {
+			return
+		}
+		item := heap.Pop(&s.queue).(queuedUpload)
+		s.running++
+		s.uploading[item.path] = struct{}{}
+		s.backgroundWorkers.Add(1)
+		goutils.PanicCapturingGo(func() {
+			defer s.backgroundWorkers.Done()
+			defer func() {
+				s.lock.Lock()
+				defer s.lock.Unlock()
+				s.running--
+				delete(s.uploading, item.path)
+				s.startUploadsLocked()
+			}()
+			s.upload(s.cancelCtx, item.path)
+		})
+	}
+}
+
+// Remove deletes the file unless it is being uploaded, dropping it from the queue if it is waiting to be.
+func (s *syncer) Remove(path string) (bool, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if _, ok := s.uploading[path]; ok {
+		return false, nil
+	}
+	if err := os.Remove(path); err != nil {
+		return false, err
+	}
+	for i, item := range s.queue {
+		if item.path == path {
+			heap.Remove(&s.queue, i)
+			break
+		}
+	}
+	s.progressTracker.unmark(path)
+	return true, nil
+}
+
+// upload uploads the file at path and deletes it. Files that fail to upload are unmarked, so that the next Sync tries
+// them again, except when the upload is cancelled, in which case it is resumed by the next Manager.
 func (s *syncer) upload(ctx context.Context, path string) {
-	if s.progressTracker.inProgress(path) {
+	//nolint:gosec
+	f, err := os.Open(path)
+	if err != nil {
+		s.logger.Errorw("error opening file", "error", err)
+		s.progressTracker.unmark(path)
 		return
 	}
-
-	s.progressTracker.mark(path)
-	s.backgroundWorkers.Add(1)
-	goutils.PanicCapturingGo(func() {
-		defer s.backgroundWorkers.Done()
-		//nolint:gosec
-		f, err := os.Open(path)
+	defer func(f *os.File) {
+		err := f.Close()
 		if err != nil {
-			s.logger.Errorw("error opening file", "error", err)
+			s.logger.Errorw("error closing file", "error", err)
+		}
+	}(f)
+
+	s.lock.Lock()
+	keys := s.opts.DecryptionKeys
+	s.lock.Unlock()
+	toUpload, err := decodeForUpload(ctx, f, keys, s.decodeSlots)
+	if err != nil {
+		if ctx.Err() != nil {
 			return
 		}
-		defer func(f *os.File) {
-			err := f.Close()
-			if err != nil {
-				s.logger.Errorw("error closing file", "error", err)
-			}
-		}(f)
+		s.logger.Errorw("error decoding file, will retry on the next sync", "path", path, "error", err)
+		s.progressTracker.unmark(path)
+		return
+	}
+	if decoded, ok := toUpload.(*decodedFile); ok {
+		//nolint:errcheck
+		defer decoded.Close()
+	}
 
+	ctx = withBandwidthLimiter(ctx, s.bandwidth)
+	for {
+		windowCtx, cancel, err := s.waitForWindow(ctx)
+		if err != nil {
+			return
+		}
 		uploadErr := exponentialRetry(
-			ctx,
-			func(ctx context.Context) error { return s.uploadFile(ctx, s.client, f, s.partID) },
+			windowCtx,
+			func(ctx context.Context) error {
+				// Retries upload the file from the start, and destinations skip what they already uploaded.
+				if _, err := toUpload.Seek(0, io.SeekStart); err != nil {
+					return err
+				}
+				return s.destination.Upload(ctx, toUpload)
+			},
 			s.logger,
 		)
-		if uploadErr != nil && !errors.Is(uploadErr, context.Canceled) {
-			s.logger.Error(uploadErr)
+		cancel()
+		if uploadErr == nil {
+			break
+		}
+		// Uploads that are cancelled are resumed by the next Manager, and those whose window closed in the next one.
+		if ctx.Err() != nil {
 			return
 		}
-
-		// Delete the file and indicate that the upload is done.
-		if err := os.Remove(path); err != nil {
-			s.logger.Errorw("error while deleting file", "error", err)
-		} else {
+		if !errors.Is(uploadErr, context.DeadlineExceeded) {
+			// The destination rejected the file, which it may accept once it or its configuration changes.
+			s.logger.Errorw("error uploading file, will retry on the next sync", "path", path, "error", uploadErr)
 			s.progressTracker.unmark(path)
+			return
 		}
-	})
+	}
+
+	// Delete the file and indicate that the upload is done.
+	if err := os.Remove(path); err != nil {
+		s.logger.Errorw("error while deleting file", "error", err)
+	} else {
+		s.progressTracker.unmark(path)
+	}
 }
 
-func (s *syncer) Sync(paths []string) {
-	for _, p := range paths {
-		s.upload(s.cancelCtx, p)
+// waitForWindow waits until uploads may run and returns a context that is done when they may not anymore.
+func (s *syncer) waitForWindow(ctx context.Context) (context.Context, func(), error) {
+	for {
+		s.lock.Lock()
+		windows := s.opts.Windows
+		optionsChanged := s.optionsChanged
+		s.lock.Unlock()
+
+		start, end := nextWindow(windows, time.Now())
+		if end.IsZero() {
+			ctx, cancel := context.WithCancel(ctx)
+			return ctx, cancel, nil
+		}
+		wait := time.Until(start)
+		if wait <= 0 {
+			ctx, cancel := context.WithDeadline(ctx, end)
+			return ctx, cancel, nil
+		}
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, nil, ctx.Err()
+		case <-optionsChanged:
+			timer.Stop()
+		case <-timer.C:
+		}
 	}
 }
 
@@ -176,8 +339,7 @@ func exponentialRetry(cancelCtx context.Context, fn func(cancelCtx context.Conte
 		return nil
 	}
 	// Don't retry non-retryable errors.
-	s := status.Convert(err)
-	if s.Code() == codes.InvalidArgument {
+	if !retryable(err) {
 		return err
 	}
 
@@ -186,7 +348,7 @@ func exponentialRetry(cancelCtx context.Context, fn func(cancelCtx context.Conte
 	ticker := time.NewTicker(nextWait)
 	for {
 		if err := cancelCtx.Err(); err != nil {
-			if !errors.Is(err, context.Canceled) {
+			if !errors.Is(err, context.Canceled) && !errors.Is(err, context.DeadlineExceeded) {
 				log.Errorw("context closed unexpectedly", "error", err)
 			}
 			return err
@@ -199,6 +361,10 @@ func exponentialRetry(cancelCtx context.Context, fn func(cancelCtx context.Conte
 		// Otherwise, try again after nextWait.
 		case <-ticker.C:
 			if err := fn(cancelCtx); err != nil {
+				if !retryable(err) {
+					ticker.Stop()
+					return err
+				}
 				// If error, retry with a new nextWait.
 				log.Errorw("error while uploading file", "error", err)
 				ticker.Stop()
@@ -213,6 +379,32 @@ func exponentialRetry(cancelCtx context.Context, fn func(cancelCtx context.Conte
 	}
 }
 
+// retryable returns whether an upload that failed with err can succeed if it is tried again. Requests that a
+// destination rejects as invalid, with a gRPC InvalidArgument or an HTTP or S3 4xx status, are not retried, except
+// for timeouts, throttling, and S3 multipart uploads and HTTP uploads in chunks that the destination lost and that
+// are started over.
+func retryable(err error) bool {
+	if s, ok := status.FromError(err); ok && s.Code() == codes.InvalidArgument {
+		return false
+	}
+	var httpErr *httpStatusError
+	if errors.As(err, &httpErr) {
+		return retryableStatus(httpErr.code)
+	}
+	var awsErr awserr.RequestFailure
+	if errors.As(err, &awsErr) {
+		return awsErr.Code() == s3.ErrCodeNoSuchUpload || retryableStatus(awsErr.StatusCode())
+	}
+	return true
+}
+
+func retryableStatus(code int) bool {
+	if code == http.StatusRequestTimeout || code == http.StatusTooManyRequests || code == http.StatusRequestedRangeNotSatisfiable {
+		return true
+	}
+	return code < 400 || code >= 500
+}
+
 func getNextWait(lastWait time.Duration) time.Duration {
 	if lastWait == time.Duration(0) {
 		return time.Millisecond * time.Duration(initialWaitTimeMillis.Load())
@@ -224,14 +416,33 @@ func getNextWait(lastWait time.Duration) time.Duration {
 	return nextWait
 }
 
-func (s *syncer) uploadFile(ctx context.Context, client v1.DataSyncServiceClient, f *os.File, partID string) error {
-	if datacapture.IsDataCaptureFile(f) {
-		dcFile, err := datacapture.ReadFile(f)
-		if err != nil {
-			return err
-		}
-		return uploadDataCaptureFile(ctx, s.progressTracker, client, partID, dcFile)
+// queuedUpload is a file waiting to be uploaded.
+type queuedUpload struct {
+	path     string
+	priority int
+	seq      uint64
+}
+
+// uploadQueue is a heap of files waiting to be uploaded, highest priority first and then in the order they were
+// synced.
+type uploadQueue []queuedUpload
+
+func (q uploadQueue) Len() int { return len(q) }
+
+func (q uploadQueue) Less(i, j int) bool {
+	if q[i].priority != q[j].priority {
+		return q[i].priority > q[j].priority
 	}
+	return q[i].seq < q[j].seq
+}
+
+func (q uploadQueue) Swap(i, j int) { q[i], q[j] = q[j], q[i] }
+
+func (q *uploadQueue) Push(x interface{}) { *q = append(*q, x.(queuedUpload)) }
 
-	return uploadArbitraryFile(ctx, client, partID, f)
+func (q *uploadQueue) Pop() interface{} {
+	old := *q
+	item := old[len(old)-1]
+	*q = old[:len(old)-1]
+	return item
 }
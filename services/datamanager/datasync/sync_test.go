@@ -1,15 +1,21 @@
 package datasync
 
 import (
+	"context"
+	"net/http"
 	"os"
 	"path/filepath"
+	"sync"
 	"testing"
 	"time"
 
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/s3"
 	"github.com/edaniels/golog"
 	"github.com/pkg/errors"
 	v1 "go.viam.com/api/app/datasync/v1"
 	"go.viam.com/test"
+	"go.viam.com/utils/testutils"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 	"google.golang.org/protobuf/types/known/structpb"
@@ -536,3 +542,113 @@ func TestPartialUpload(t *testing.T) {
 		})
 	}
 }
+
+// blockingDestination records the files uploaded to it, and blocks each upload until it is released.
+type blockingDestination struct {
+	started  chan string
+	release  chan struct{}
+	mu       sync.Mutex
+	uploaded []string
+}
+
+func (d *blockingDestination) Upload(ctx context.Context, f UploadFile) error {
+	d.started <- f.Name()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-d.release:
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.uploaded = append(d.uploaded, f.Name())
+	return nil
+}
+
+func (d *blockingDestination) Close() error {
+	return nil
+}
+
+func TestRemove(t *testing.T) {
+	dir := t.TempDir()
+	uploading := filepath.Join(dir, "uploading.txt")
+	queued := filepath.Join(dir, "queued.txt")
+	for _, path := range []string{uploading, queued} {
+		test.That(t, os.WriteFile(path, []byte("data"), 0o600), test.ShouldBeNil)
+	}
+	destination := &blockingDestination{started: make(chan string, 2), release: make(chan struct{})}
+	sut, err := NewManagerWithDestination(golog.NewTestLogger(t), destination)
+	test.That(t, err, test.ShouldBeNil)
+	defer sut.Close()
+	sut.(OptionsSetter).SetOptions(Options{MaxParallelUploads: 1})
+	sut.Sync([]string{uploading, queued})
+	test.That(t, <-destination.started, test.ShouldEqual, uploading)
+
+	// The file being uploaded is left alone, and the one waiting to be is deleted and dropped from the queue.
+	remover := sut.(Remover)
+	removed, err := remover.Remove(uploading)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, removed, test.ShouldBeFalse)
+	_, err = os.Stat(uploading)
+	test.That(t, err, test.ShouldBeNil)
+	removed, err = remover.Remove(queued)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, removed, test.ShouldBeTrue)
+	_, err = os.Stat(queued)
+	test.That(t, os.IsNotExist(err), test.ShouldBeTrue)
+
+	close(destination.release)
+	testutils.WaitForAssertion(t, func(tb testing.TB) {
+		tb.Helper()
+		_, err := os.Stat(uploading)
+		test.That(tb, os.IsNotExist(err), test.ShouldBeTrue)
+	})
+	destination.mu.Lock()
+	defer destination.mu.Unlock()
+	test.That(t, destination.uploaded, test.ShouldResemble, []string{uploading})
+}
+
+func TestRetryable(t *testing.T) {
+	for _, tc := range []struct {
+		name      string
+		err       error
+		retryable bool
+	}{
+		{"any error", errors.New("connection reset"), true},
+		{"grpc invalid argument", status.Error(codes.InvalidArgument, "bad"), false},
+		{"grpc unavailable", status.Error(codes.Unavailable, "down"), true},
+		{"http bad request", &httpStatusError{code: http.StatusBadRequest}, false},
+		{"http forbidden", errors.Wrap(&httpStatusError{code: http.StatusForbidden}, "upload"), false},
+		{"http not found", &httpStatusError{code: http.StatusNotFound}, false},
+		{"http request timeout", &httpStatusError{code: http.StatusRequestTimeout}, true},
+		{"http too many requests", &httpStatusError{code: http.StatusTooManyRequests}, true},
+		{"http range not satisfiable", &httpStatusError{code: http.StatusRequestedRangeNotSatisfiable}, true},
+		{"http internal server error", &httpStatusError{code: http.StatusInternalServerError}, true},
+		{"http service unavailable", &httpStatusError{code: http.StatusServiceUnavailable}, true},
+		{"s3 access denied", awserr.NewRequestFailure(awserr.New("AccessDenied", "denied", nil), http.StatusForbidden, ""), false},
+		{"s3 no such upload", awserr.NewRequestFailure(awserr.New(s3.ErrCodeNoSuchUpload, "gone", nil), http.StatusNotFound, ""), true},
+		{"s3 slow down", awserr.NewRequestFailure(awserr.New("SlowDown", "slow", nil), http.StatusServiceUnavailable, ""), true},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			test.That(t, retryable(tc.err), test.ShouldEqual, tc.retryable)
+		})
+	}
+}
+
+func TestExponentialRetryStopsOnRejection(t *testing.T) {
+	defer func(wait int32) {
+		initialWaitTimeMillis.Store(wait)
+	}(initialWaitTimeMillis.Load())
+	initialWaitTimeMillis.Store(10)
+
+	var calls int
+	rejected := &httpStatusError{code: http.StatusBadRequest}
+	err := exponentialRetry(context.Background(), func(ctx context.Context) error {
+		calls++
+		if calls == 1 {
+			return &httpStatusError{code: http.StatusServiceUnavailable}
+		}
+		return rejected
+	}, golog.NewTestLogger(t))
+	test.That(t, err, test.ShouldEqual, rejected)
+	test.That(t, calls, test.ShouldEqual, 2)
+}
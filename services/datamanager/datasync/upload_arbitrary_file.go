@@ -3,17 +3,17 @@ package datasync
 import (
 	"context"
 	"io"
-	"os"
 	"path/filepath"
 	"sync"
 
 	"github.com/pkg/errors"
 	v1 "go.viam.com/api/app/datasync/v1"
 	goutils "go.viam.com/utils"
+	"google.golang.org/protobuf/proto"
 )
 
 func uploadArbitraryFile(ctx context.Context, client v1.DataSyncServiceClient, partID string,
-	f *os.File,
+	f UploadFile,
 ) error {
 	stream, err := client.Upload(ctx)
 	if err != nil {
@@ -75,7 +75,7 @@ func uploadArbitraryFile(ctx context.Context, client v1.DataSyncServiceClient, p
 	return nil
 }
 
-func getNextFileUploadRequest(ctx context.Context, f *os.File) (*v1.UploadRequest, error) {
+func getNextFileUploadRequest(ctx context.Context, f UploadFile) (*v1.UploadRequest, error) {
 	select {
 	case <-ctx.Done():
 		return nil, context.Canceled
@@ -94,7 +94,7 @@ func getNextFileUploadRequest(ctx context.Context, f *os.File) (*v1.UploadReques
 	}
 }
 
-func readNextFileChunk(f *os.File) (*v1.FileData, error) {
+func readNextFileChunk(f UploadFile) (*v1.FileData, error) {
 	byteArr := make([]byte, uploadChunkSize)
 	numBytesRead, err := f.Read(byteArr)
 	if numBytesRead < uploadChunkSize {
@@ -128,7 +128,7 @@ func recvFileUploadResponses(ctx context.Context, stream v1.DataSyncService_Uplo
 	}
 }
 
-func sendFileUploadRequests(ctx context.Context, stream v1.DataSyncService_UploadClient, f *os.File) error {
+func sendFileUploadRequests(ctx context.Context, stream v1.DataSyncService_UploadClient, f UploadFile) error {
 	//nolint:errcheck
 	defer stream.CloseSend()
 	// Loop until there is no more content to be read from file.
@@ -152,6 +152,9 @@ func sendFileUploadRequests(ctx context.Context, stream v1.DataSyncService_Uploa
 				return err
 			}
 
+			if err := waitForBandwidth(ctx, proto.Size(uploadReq)); err != nil {
+				return err
+			}
 			if err = stream.Send(uploadReq); err != nil {
 				return err
 			}
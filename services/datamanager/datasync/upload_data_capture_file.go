@@ -9,6 +9,7 @@ import (
 	"github.com/pkg/errors"
 	v1 "go.viam.com/api/app/datasync/v1"
 	goutils "go.viam.com/utils"
+	"google.golang.org/protobuf/proto"
 
 	"go.viam.com/rdk/services/datamanager/datacapture"
 )
@@ -145,6 +146,9 @@ func sendNextUploadRequest(ctx context.Context, f *datacapture.File, stream v1.D
 		if err != nil {
 			return err
 		}
+		if err := waitForBandwidth(ctx, proto.Size(uploadReq)); err != nil {
+			return err
+		}
 
 		if err = stream.Send(uploadReq); err != nil {
 			return err
@@ -3,6 +3,7 @@ package builtin
 
 import (
 	"context"
+	"errors"
 	"fmt"
 
 	"github.com/edaniels/golog"
@@ -39,6 +40,10 @@ func NewBuiltIn(ctx context.Context, r robot.Robot, config config.Service, logge
 	}, nil
 }
 
+// the furthest distance in joint space that a component can be from where it was at the start of a plan for the plan to still be
+// valid.
+const planStartTolerance = 1e-3
+
 type builtIn struct {
 	r      robot.Robot
 	logger golog.Logger
@@ -55,38 +60,151 @@ func (ms *builtIn) Move(
 	operation.CancelOtherWithLabel(ctx, "motion-service")
 	logger := ms.r.Logger()
 
+	planned, err := ms.planMotion(ctx, componentName, destination, worldState, extra)
+	if err != nil {
+		return false, err
+	}
+	currentInputs := func(ctx context.Context) (map[string][]referenceframe.Input, error) {
+		inputs, _, err := framesystem.RobotFsCurrentInputs(ctx, ms.r, planned.frameSys)
+		return inputs, err
+	}
+	if err := executePlan(ctx, planned, worldState, currentInputs, logger); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// Plan plans a movement of a component specified by its name to a destination, and returns it along with the poses the component
+// passes through without executing it.
+func (ms *builtIn) Plan(
+	ctx context.Context,
+	componentName resource.Name,
+	destination *referenceframe.PoseInFrame,
+	worldState *commonpb.WorldState,
+	extra map[string]interface{},
+) (*motion.Plan, error) {
+	planned, err := ms.planMotion(ctx, componentName, destination, worldState, extra)
+	if err != nil {
+		return nil, err
+	}
+	plan := &motion.Plan{
+		Component:   componentName,
+		Steps:       append([]map[string][]referenceframe.Input{planned.inputs}, planned.steps...),
+		Constraints: planned.constraints,
+	}
+	for i, step := range plan.Steps {
+		tf, err := planned.frameSys.Transform(
+			step,
+			referenceframe.NewPoseInFrame(componentName.ShortName(), spatialmath.NewZeroPose()),
+			referenceframe.World,
+		)
+		if err != nil {
+			return nil, err
+		}
+		plan.Poses = append(plan.Poses, tf.(*referenceframe.PoseInFrame).Pose())
+		if i > 0 {
+			for name, inputs := range step {
+				plan.Cost += motionplan.L2Distance(
+					referenceframe.InputsToFloats(plan.Steps[i-1][name]),
+					referenceframe.InputsToFloats(inputs),
+				)
+			}
+		}
+	}
+	return plan, nil
+}
+
+// CheckPlan checks that the robot is still at the start of a plan returned by Plan, and that the plan does not collide with the
+// obstacles of the given world state.
+func (ms *builtIn) CheckPlan(
+	ctx context.Context,
+	plan *motion.Plan,
+	worldState *commonpb.WorldState,
+	extra map[string]interface{},
+) error {
+	logger := ms.r.Logger()
+	if len(plan.Steps) == 0 {
+		return errors.New("plan has no steps")
+	}
+
+	frameSys, err := framesystem.RobotFrameSystem(ctx, ms.r, worldState.GetTransforms())
+	if err != nil {
+		return err
+	}
+	fsInputs, _, err := framesystem.RobotFsCurrentInputs(ctx, ms.r, frameSys)
+	if err != nil {
+		return err
+	}
+	movingFrame := frameSys.Frame(plan.Component.ShortName())
+	if movingFrame == nil {
+		return fmt.Errorf("component named %s not found in robot frame system", plan.Component.ShortName())
+	}
+
+	for name, inputs := range plan.Steps[0] {
+		current, ok := fsInputs[name]
+		if !ok {
+			return fmt.Errorf("component named %s in plan not found in robot frame system", name)
+		}
+		distance := motionplan.L2Distance(referenceframe.InputsToFloats(current), referenceframe.InputsToFloats(inputs))
+		if distance > planStartTolerance {
+			return fmt.Errorf("component named %s has moved since the plan was made", name)
+		}
+	}
+	return motionplan.CheckPlan(movingFrame, fsInputs, plan.Steps[1:], frameSys, worldState, logger)
+}
+
+// plannedMotion is a motion of a component planned from the current state of the robot.
+type plannedMotion struct {
+	frameSys    referenceframe.FrameSystem
+	inputs      map[string][]referenceframe.Input
+	resources   map[string]referenceframe.InputEnabled
+	movingFrame referenceframe.Frame
+	steps       []map[string][]referenceframe.Input
+	constraints []string
+}
+
+// planMotion plans a movement of a component specified by its name to a destination from the current state of the robot.
+func (ms *builtIn) planMotion(
+	ctx context.Context,
+	componentName resource.Name,
+	destination *referenceframe.PoseInFrame,
+	worldState *commonpb.WorldState,
+	extra map[string]interface{},
+) (*plannedMotion, error) {
+	logger := ms.r.Logger()
+
 	// get goal frame
 	goalFrameName := destination.FrameName()
 	logger.Debugf("goal given in frame of %q", goalFrameName)
 
 	frameSys, err := framesystem.RobotFrameSystem(ctx, ms.r, worldState.GetTransforms())
 	if err != nil {
-		return false, err
+		return nil, err
 	}
 
 	// build maps of relevant components and inputs from initial inputs
 	fsInputs, resources, err := framesystem.RobotFsCurrentInputs(ctx, ms.r, frameSys)
 	if err != nil {
-		return false, err
+		return nil, err
 	}
-	
+
 	movingFrame := frameSys.Frame(componentName.ShortName())
-	
+
 	logger.Debugf("frame system inputs: %v", fsInputs)
 	if movingFrame == nil {
-		return false, fmt.Errorf("component named %s not found in robot frame system", componentName.ShortName())
+		return nil, fmt.Errorf("component named %s not found in robot frame system", componentName.ShortName())
 	}
 
 	// re-evaluate goalPose to be in the frame of World
 	solvingFrame := referenceframe.World // TODO(erh): this should really be the parent of rootName
 	tf, err := frameSys.Transform(fsInputs, destination, solvingFrame)
 	if err != nil {
-		return false, err
+		return nil, err
 	}
 	goalPose, _ := tf.(*referenceframe.PoseInFrame)
 
 	// the goal is to move the component to goalPose which is specified in coordinates of goalFrameName
-	output, err := motionplan.PlanMotion(ctx,
+	output, constraints, err := motionplan.PlanMotionWithConstraints(ctx,
 		logger,
 		goalPose,
 		movingFrame,
@@ -96,23 +214,16 @@ func (ms *builtIn) Move(
 		extra,
 	)
 	if err != nil {
-		return false, err
-	}
-
-	// move all the components
-	for _, step := range output {
-		// TODO(erh): what order? parallel?
-		for name, inputs := range step {
-			if len(inputs) == 0 {
-				continue
-			}
-			err := resources[name].GoToInputs(ctx, inputs)
-			if err != nil {
-				return false, err
-			}
-		}
+		return nil, err
 	}
-	return true, nil
+	return &plannedMotion{
+		frameSys:    frameSys,
+		inputs:      fsInputs,
+		resources:   resources,
+		movingFrame: movingFrame,
+		steps:       output,
+		constraints: constraints,
+	}, nil
 }
 
 // MoveSingleComponent will pass through a move command to a component with a MoveToPosition method that takes a pose. Arms are the only
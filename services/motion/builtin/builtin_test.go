@@ -247,6 +247,60 @@ func TestMultiplePieces(t *testing.T) {
 	test.That(t, err, test.ShouldBeNil)
 }
 
+func TestPlan(t *testing.T) {
+	ms := setupMotionServiceFromConfig(t, "../data/fake_tomato.json")
+	start, err := ms.GetPose(context.Background(), gripper.Named("gr"), "", nil, map[string]interface{}{})
+	test.That(t, err, test.ShouldBeNil)
+
+	grabPose := referenceframe.NewPoseInFrame("c", spatialmath.NewPoseFromPoint(r3.Vector{-0, -30, -50}))
+	plan, err := ms.Plan(context.Background(), gripper.Named("gr"), grabPose, &commonpb.WorldState{}, map[string]interface{}{})
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, plan.Component, test.ShouldResemble, gripper.Named("gr"))
+	test.That(t, len(plan.Steps), test.ShouldBeGreaterThan, 1)
+	test.That(t, len(plan.Poses), test.ShouldEqual, len(plan.Steps))
+	test.That(t, spatialmath.PoseAlmostEqual(plan.Poses[0], start.Pose()), test.ShouldBeTrue)
+	test.That(t, plan.Cost, test.ShouldBeGreaterThan, 0)
+	test.That(t, plan.Constraints, test.ShouldNotBeEmpty)
+
+	// planning does not move anything
+	pose, err := ms.GetPose(context.Background(), gripper.Named("gr"), "", nil, map[string]interface{}{})
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, spatialmath.PoseAlmostEqual(pose.Pose(), start.Pose()), test.ShouldBeTrue)
+
+	t.Run("check a plan against an empty world state", func(t *testing.T) {
+		err := ms.CheckPlan(context.Background(), plan, &commonpb.WorldState{}, map[string]interface{}{})
+		test.That(t, err, test.ShouldBeNil)
+	})
+
+	t.Run("check a plan against a world state with an obstacle in its way", func(t *testing.T) {
+		// a box around the end of the plan, big enough for the wrist of the arm to have to move into it
+		end := plan.Poses[len(plan.Poses)-1]
+		obstacles := []*commonpb.GeometriesInFrame{
+			{
+				ReferenceFrame: referenceframe.World,
+				Geometries: []*commonpb.Geometry{
+					{
+						Center: spatialmath.PoseToProtobuf(spatialmath.NewPoseFromPoint(end.Point())),
+						GeometryType: &commonpb.Geometry_Box{
+							Box: &commonpb.RectangularPrism{DimsMm: &commonpb.Vector3{X: 800, Y: 800, Z: 800}},
+						},
+					},
+				},
+			},
+		}
+		err := ms.CheckPlan(context.Background(), plan, &commonpb.WorldState{Obstacles: obstacles}, map[string]interface{}{})
+		test.That(t, err, test.ShouldNotBeNil)
+	})
+
+	t.Run("check a plan after the robot has moved", func(t *testing.T) {
+		_, err := ms.Move(context.Background(), gripper.Named("gr"), grabPose, &commonpb.WorldState{}, map[string]interface{}{})
+		test.That(t, err, test.ShouldBeNil)
+		err = ms.CheckPlan(context.Background(), plan, &commonpb.WorldState{}, map[string]interface{}{})
+		test.That(t, err, test.ShouldNotBeNil)
+		test.That(t, err.Error(), test.ShouldContainSubstring, "has moved")
+	})
+}
+
 func TestGetPose(t *testing.T) {
 	var err error
 	ms := setupMotionServiceFromConfig(t, "../data/arm_gantry.json")
@@ -0,0 +1,230 @@
+package builtin
+
+import (
+	"context"
+	"sync"
+
+	"github.com/edaniels/golog"
+	"github.com/pkg/errors"
+	"go.uber.org/multierr"
+	commonpb "go.viam.com/api/common/v1"
+	goutils "go.viam.com/utils"
+
+	"go.viam.com/rdk/components/arm"
+	"go.viam.com/rdk/motionplan"
+	"go.viam.com/rdk/referenceframe"
+	"go.viam.com/rdk/resource"
+	"go.viam.com/rdk/utils"
+)
+
+// movingComponents returns the names of the components whose inputs over the steps of a plan differ from their current ones.
+func movingComponents(current map[string][]referenceframe.Input, plan []map[string][]referenceframe.Input) []string {
+	var moving []string
+	for name, inputs := range current {
+		if len(inputs) == 0 {
+			continue
+		}
+		for _, step := range plan {
+			if motionplan.L2Distance(referenceframe.InputsToFloats(inputs), referenceframe.InputsToFloats(step[name])) > 0 {
+				moving = append(moving, name)
+				break
+			}
+		}
+	}
+	return moving
+}
+
+// trajectoryFollowers returns those of the named components that can follow a trajectory.
+func trajectoryFollowers(resources map[string]referenceframe.InputEnabled, names []string) map[string]arm.TrajectoryFollower {
+	followers := map[string]arm.TrajectoryFollower{}
+	for _, name := range names {
+		if follower, ok := utils.UnwrapProxy(resources[name]).(arm.TrajectoryFollower); ok {
+			followers[name] = follower
+		}
+	}
+	return followers
+}
+
+// executePlan moves the components of a planned motion through its steps. Components that can follow trajectories are streamed
+// them through the whole plan together when no other components move, so that they neither stop at each step nor fall out of step
+// with each other. Otherwise, or if those trajectories would cut a corner at a step into a collision, the components of each step
+// are moved at once by goToStep, and each step starts once all of them have arrived at the last.
+//
+// The plan is checked against worldState before it is executed, and again before each step that is moved through on its own. That
+// is always the world state the motion was called with, as obstacles are not sensed again: only the inputs of the components are
+// read again through currentInputs, from wherever they ended up after the last step.
+func executePlan(
+	ctx context.Context,
+	planned *plannedMotion,
+	worldState *commonpb.WorldState,
+	currentInputs func(context.Context) (map[string][]referenceframe.Input, error),
+	logger golog.Logger,
+) error {
+	fsInputs, resources, steps := planned.inputs, planned.resources, planned.steps
+
+	moving := movingComponents(fsInputs, steps)
+	followers := trajectoryFollowers(resources, moving)
+	if len(moving) > 0 && len(followers) == len(moving) {
+		if err := motionplan.CheckPlan(planned.movingFrame, fsInputs, steps, planned.frameSys, worldState, logger); err != nil {
+			return err
+		}
+		trajectories, err := planTrajectories(ctx, followers, fsInputs, steps)
+		if err != nil {
+			return err
+		}
+		// the trajectories cut the corners at the steps, so if that brings anything into collision the steps are moved through one
+		// at a time below instead, which keeps to the straight lines between them that were just checked
+		err = motionplan.CheckTrajectories(planned.movingFrame, fsInputs, trajectories, planned.frameSys, worldState, logger)
+		if err == nil {
+			return followPlan(ctx, resources, followers, trajectories)
+		}
+		logger.Debugw("not following a trajectory through the whole plan", "error", err)
+	}
+
+	for i, step := range steps {
+		// only the inputs are read again here, the obstacles are still those of worldState
+		if i > 0 {
+			var err error
+			fsInputs, err = currentInputs(ctx)
+			if err != nil {
+				return err
+			}
+		}
+		if err := motionplan.CheckPlan(planned.movingFrame, fsInputs, steps[i:i+1], planned.frameSys, worldState, logger); err != nil {
+			return err
+		}
+		if err := goToStep(ctx, resources, followers, fsInputs, step); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// followPlan streams the given components their synchronized trajectories through a plan at once, such as those returned by
+// planTrajectories.
+func followPlan(
+	ctx context.Context,
+	resources map[string]referenceframe.InputEnabled,
+	followers map[string]arm.TrajectoryFollower,
+	trajectories map[string]motionplan.Trajectory,
+) error {
+	return moveGroup(ctx, resources, trajectoryMoves(followers, trajectories))
+}
+
+// planTrajectories returns synchronized trajectories for the given components through the steps of a plan, starting from their
+// current inputs.
+func planTrajectories(
+	ctx context.Context,
+	followers map[string]arm.TrajectoryFollower,
+	current map[string][]referenceframe.Input,
+	plan []map[string][]referenceframe.Input,
+) (map[string]motionplan.Trajectory, error) {
+	if len(followers) == 0 {
+		return map[string]motionplan.Trajectory{}, nil
+	}
+	limits := make(map[string][]motionplan.JointLimit, len(followers))
+	for name, follower := range followers {
+		followerLimits, err := follower.JointLimits(ctx)
+		if err != nil {
+			return nil, err
+		}
+		limits[name] = followerLimits
+	}
+	return motionplan.NewPlanTrajectories(
+		append([]map[string][]referenceframe.Input{current}, plan...),
+		limits,
+		motionplan.TrajectoryOptions{},
+	)
+}
+
+// trajectoryMoves returns moves that stream each of the given components its trajectory.
+func trajectoryMoves(
+	followers map[string]arm.TrajectoryFollower,
+	trajectories map[string]motionplan.Trajectory,
+) map[string]func(context.Context) error {
+	moves := make(map[string]func(context.Context) error, len(followers))
+	for name, follower := range followers {
+		follower, trajectory := follower, trajectories[name]
+		moves[name] = func(ctx context.Context) error {
+			return follower.FollowTrajectory(ctx, trajectory)
+		}
+	}
+	return moves
+}
+
+// goToStep moves every component to its inputs in a step of a plan at once. The components that can follow a trajectory are
+// streamed synchronized trajectories to the step, so that they arrive at the same time, the rest are sent their inputs directly.
+// Those move at their own speed, as component APIs such as that of gantries have no way to give a move a duration. They start with
+// the followers and the step only ends once they have arrived, but in between they are not kept in step with the followers, so
+// the components only keep to the straight line to the step in joint space that CheckPlan checks if they all move alone.
+func goToStep(
+	ctx context.Context,
+	resources map[string]referenceframe.InputEnabled,
+	followers map[string]arm.TrajectoryFollower,
+	current map[string][]referenceframe.Input,
+	step map[string][]referenceframe.Input,
+) error {
+	stepFollowers := map[string]arm.TrajectoryFollower{}
+	for name, inputs := range step {
+		if follower, ok := followers[name]; ok && len(inputs) > 0 {
+			stepFollowers[name] = follower
+		}
+	}
+	trajectories, err := planTrajectories(ctx, stepFollowers, current, []map[string][]referenceframe.Input{step})
+	if err != nil {
+		return err
+	}
+	moves := trajectoryMoves(stepFollowers, trajectories)
+	for name, inputs := range step {
+		if _, ok := moves[name]; ok || len(inputs) == 0 {
+			continue
+		}
+		component, inputs := resources[name], inputs
+		moves[name] = func(ctx context.Context) error {
+			return component.GoToInputs(ctx, inputs)
+		}
+	}
+	return moveGroup(ctx, resources, moves)
+}
+
+// moveGroup runs the given moves of named components at once and waits for all of them to finish. If any of them fails, the
+// others are cancelled and every component in the group is stopped.
+func moveGroup(
+	ctx context.Context,
+	resources map[string]referenceframe.InputEnabled,
+	moves map[string]func(context.Context) error,
+) error {
+	groupCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	var firstErr error
+	for name, move := range moves {
+		name, move := name, move
+		wg.Add(1)
+		goutils.PanicCapturingGo(func() {
+			defer wg.Done()
+			if err := move(groupCtx); err != nil {
+				mu.Lock()
+				defer mu.Unlock()
+				// the errors of the moves that were cancelled because of the first one are not interesting
+				if firstErr == nil {
+					firstErr = errors.Wrapf(err, "failed to move %q", name)
+					cancel()
+				}
+			}
+		})
+	}
+	wg.Wait()
+	if firstErr == nil {
+		return nil
+	}
+
+	for name := range moves {
+		if component, ok := resources[name]; ok {
+			firstErr = multierr.Combine(firstErr, resource.StopResource(ctx, component, nil))
+		}
+	}
+	return firstErr
+}
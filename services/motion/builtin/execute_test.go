@@ -0,0 +1,263 @@
+package builtin
+
+import (
+	"context"
+	"errors"
+	"math"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/edaniels/golog"
+	"github.com/golang/geo/r3"
+	commonpb "go.viam.com/api/common/v1"
+	"go.viam.com/test"
+
+	"go.viam.com/rdk/motionplan"
+	"go.viam.com/rdk/referenceframe"
+	"go.viam.com/rdk/spatialmath"
+)
+
+// fakeComponent is a component whose moves take a while, and which records the moves made and whether it was stopped.
+type fakeComponent struct {
+	mu      sync.Mutex
+	inputs  []referenceframe.Input
+	started time.Time
+	err     error
+	stopped bool
+}
+
+func (c *fakeComponent) CurrentInputs(ctx context.Context) ([]referenceframe.Input, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.inputs, nil
+}
+
+func (c *fakeComponent) GoToInputs(ctx context.Context, goal []referenceframe.Input) error {
+	c.mu.Lock()
+	c.started = time.Now()
+	err := c.err
+	c.mu.Unlock()
+	if err != nil {
+		return err
+	}
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-time.After(50 * time.Millisecond):
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.inputs = goal
+	return nil
+}
+
+func (c *fakeComponent) Stop(ctx context.Context, extra map[string]interface{}) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.stopped = true
+	return nil
+}
+
+// fakeFollower is a component that follows trajectories, which records those it was streamed and the moves made outside of them.
+type fakeFollower struct {
+	fakeComponent
+	trajectories []motionplan.Trajectory
+	moves        int
+}
+
+func (f *fakeFollower) GoToInputs(ctx context.Context, goal []referenceframe.Input) error {
+	f.mu.Lock()
+	f.moves++
+	f.mu.Unlock()
+	return f.fakeComponent.GoToInputs(ctx, goal)
+}
+
+func (f *fakeFollower) JointLimits(ctx context.Context) ([]motionplan.JointLimit, error) {
+	// the jerk limit smooths the trajectories, cutting the corners at the steps of a plan by tens
+	return []motionplan.JointLimit{{MaxVelocity: 500, MaxAcceleration: 500, MaxJerk: 250}}, nil
+}
+
+func (f *fakeFollower) FollowTrajectory(ctx context.Context, trajectory motionplan.Trajectory) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.started = time.Now()
+	f.trajectories = append(f.trajectories, trajectory)
+	f.inputs = trajectory[len(trajectory)-1].Inputs
+	return nil
+}
+
+// newCornerMotion returns a motion of a box moved by the components along x to 1000 and then along y to 1000.
+func newCornerMotion(t *testing.T, x, y referenceframe.InputEnabled) *plannedMotion {
+	t.Helper()
+	limit := referenceframe.Limit{Min: -5000, Max: 5000}
+	fs := referenceframe.NewEmptySimpleFrameSystem("test")
+	xFrame, err := referenceframe.NewTranslationalFrame("x", r3.Vector{X: 1}, limit)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, fs.AddFrame(xFrame, fs.World()), test.ShouldBeNil)
+	yFrame, err := referenceframe.NewTranslationalFrame("y", r3.Vector{Y: 1}, limit)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, fs.AddFrame(yFrame, xFrame), test.ShouldBeNil)
+	box, err := spatialmath.NewBoxCreator(r3.Vector{X: 10, Y: 10, Z: 10}, spatialmath.NewZeroPose(), "")
+	test.That(t, err, test.ShouldBeNil)
+	tool, err := referenceframe.NewStaticFrameWithGeometry("tool", spatialmath.NewZeroPose(), box)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, fs.AddFrame(tool, yFrame), test.ShouldBeNil)
+
+	at := func(x, y float64) map[string][]referenceframe.Input {
+		inputs := referenceframe.StartPositions(fs)
+		inputs["x"] = []referenceframe.Input{{Value: x}}
+		inputs["y"] = []referenceframe.Input{{Value: y}}
+		return inputs
+	}
+	return &plannedMotion{
+		frameSys:    fs,
+		inputs:      at(0, 0),
+		resources:   map[string]referenceframe.InputEnabled{"x": x, "y": y},
+		movingFrame: tool,
+		steps:       []map[string][]referenceframe.Input{at(1000, 0), at(1000, 1000)},
+	}
+}
+
+// obstacleAt returns a world state holding a box the size of that of a corner motion at a position.
+func obstacleAt(t *testing.T, x, y float64) *commonpb.WorldState {
+	t.Helper()
+	obstacle, err := spatialmath.NewBox(spatialmath.NewPoseFromPoint(r3.Vector{X: x, Y: y}), r3.Vector{X: 10, Y: 10, Z: 10}, "")
+	test.That(t, err, test.ShouldBeNil)
+	geometries := map[string]spatialmath.Geometry{"obstacle": obstacle}
+	obstacles, err := referenceframe.GeometriesInFrameToProtobuf(referenceframe.NewGeometriesInFrame(referenceframe.World, geometries))
+	test.That(t, err, test.ShouldBeNil)
+	return &commonpb.WorldState{Obstacles: []*commonpb.GeometriesInFrame{obstacles}}
+}
+
+func TestExecutePlan(t *testing.T) {
+	logger := golog.NewTestLogger(t)
+	newFollower := func() *fakeFollower {
+		return &fakeFollower{fakeComponent: fakeComponent{inputs: []referenceframe.Input{{Value: 0}}}}
+	}
+	currentInputs := func(planned *plannedMotion) func(context.Context) (map[string][]referenceframe.Input, error) {
+		return func(ctx context.Context) (map[string][]referenceframe.Input, error) {
+			inputs := referenceframe.StartPositions(planned.frameSys)
+			for name, resource := range planned.resources {
+				current, err := resource.CurrentInputs(ctx)
+				if err != nil {
+					return nil, err
+				}
+				inputs[name] = current
+			}
+			return inputs, nil
+		}
+	}
+
+	t.Run("followers are streamed one trajectory through the whole plan", func(t *testing.T) {
+		x, y := newFollower(), newFollower()
+		planned := newCornerMotion(t, x, y)
+		err := executePlan(context.Background(), planned, obstacleAt(t, 0, 1000), currentInputs(planned), logger)
+		test.That(t, err, test.ShouldBeNil)
+		for _, follower := range []*fakeFollower{x, y} {
+			test.That(t, follower.trajectories, test.ShouldHaveLength, 1)
+			test.That(t, follower.moves, test.ShouldEqual, 0)
+		}
+		test.That(t, x.inputs[0].Value, test.ShouldAlmostEqual, 1000)
+		test.That(t, y.inputs[0].Value, test.ShouldAlmostEqual, 1000)
+	})
+
+	t.Run("followers move through each step when the trajectory would cut the corner into an obstacle", func(t *testing.T) {
+		x, y := newFollower(), newFollower()
+		planned := newCornerMotion(t, x, y)
+		trajectories, err := planTrajectories(
+			context.Background(), trajectoryFollowers(planned.resources, []string{"x", "y"}), planned.inputs, planned.steps,
+		)
+		test.That(t, err, test.ShouldBeNil)
+
+		// put the obstacle where the trajectories cut deepest into the corner, which is well clear of the steps
+		var deepest, cornerX, cornerY float64
+		for _, point := range trajectories["x"] {
+			atX, atY := point.Inputs[0].Value, trajectories["y"].At(point.Time).Inputs[0].Value
+			if depth := math.Min(1000-atX, atY); depth > deepest {
+				deepest, cornerX, cornerY = depth, atX, atY
+			}
+		}
+		test.That(t, deepest, test.ShouldBeGreaterThan, 50)
+		inCorner := obstacleAt(t, cornerX, cornerY)
+
+		err = executePlan(context.Background(), planned, inCorner, currentInputs(planned), logger)
+		test.That(t, err, test.ShouldBeNil)
+		for _, follower := range []*fakeFollower{x, y} {
+			test.That(t, follower.trajectories, test.ShouldHaveLength, 2)
+			test.That(t, follower.moves, test.ShouldEqual, 0)
+		}
+		test.That(t, x.inputs[0].Value, test.ShouldAlmostEqual, 1000)
+		test.That(t, y.inputs[0].Value, test.ShouldAlmostEqual, 1000)
+	})
+
+	t.Run("components that cannot follow trajectories are moved alongside the followers without being synchronized", func(t *testing.T) {
+		x := newFollower()
+		gantry := &fakeComponent{inputs: []referenceframe.Input{{Value: 0}}}
+		planned := newCornerMotion(t, x, gantry)
+		err := executePlan(context.Background(), planned, obstacleAt(t, 0, 1000), currentInputs(planned), logger)
+		test.That(t, err, test.ShouldBeNil)
+
+		// the follower is streamed a trajectory to each step that takes seconds, while the gantry moves at its own speed, here
+		// 50ms, and both start each step at once
+		test.That(t, x.trajectories, test.ShouldHaveLength, 2)
+		test.That(t, x.trajectories[0][len(x.trajectories[0])-1].Time, test.ShouldBeGreaterThan, time.Second)
+		test.That(t, x.moves, test.ShouldEqual, 0)
+		difference := gantry.started.Sub(x.started)
+		test.That(t, difference, test.ShouldBeLessThan, 25*time.Millisecond)
+		test.That(t, difference, test.ShouldBeGreaterThan, -25*time.Millisecond)
+		test.That(t, x.inputs[0].Value, test.ShouldAlmostEqual, 1000)
+		test.That(t, gantry.inputs, test.ShouldResemble, []referenceframe.Input{{Value: 1000}})
+	})
+
+	t.Run("a step that collides is not moved to", func(t *testing.T) {
+		x, y := newFollower(), newFollower()
+		planned := newCornerMotion(t, x, y)
+		err := executePlan(context.Background(), planned, obstacleAt(t, 1000, 500), currentInputs(planned), logger)
+		test.That(t, err, test.ShouldNotBeNil)
+		test.That(t, x.trajectories, test.ShouldBeEmpty)
+		test.That(t, y.trajectories, test.ShouldBeEmpty)
+	})
+}
+
+func TestGoToStep(t *testing.T) {
+	gantry := &fakeComponent{inputs: []referenceframe.Input{{Value: 0}}}
+	arm := &fakeComponent{inputs: []referenceframe.Input{{Value: 0}, {Value: 0}}}
+	resources := map[string]referenceframe.InputEnabled{"gantry": gantry, "arm": arm}
+	current := map[string][]referenceframe.Input{"gantry": gantry.inputs, "arm": arm.inputs, "camera": {}}
+
+	step := map[string][]referenceframe.Input{"gantry": {{Value: 10}}, "arm": {{Value: 1}, {Value: 2}}, "camera": {}}
+	test.That(t, goToStep(context.Background(), resources, nil, current, step), test.ShouldBeNil)
+	test.That(t, gantry.inputs, test.ShouldResemble, step["gantry"])
+	test.That(t, arm.inputs, test.ShouldResemble, step["arm"])
+
+	// the components are moved at once rather than one after the other
+	difference := gantry.started.Sub(arm.started)
+	test.That(t, difference, test.ShouldBeLessThan, 25*time.Millisecond)
+	test.That(t, difference, test.ShouldBeGreaterThan, -25*time.Millisecond)
+	test.That(t, gantry.stopped, test.ShouldBeFalse)
+	test.That(t, arm.stopped, test.ShouldBeFalse)
+
+	// a failure of one component cancels the move of the other, and stops them both
+	gantry.err = errors.New("gantry is stuck")
+	err := goToStep(context.Background(), resources, nil, current, map[string][]referenceframe.Input{
+		"gantry": {{Value: 20}},
+		"arm":    {{Value: 3}, {Value: 4}},
+	})
+	test.That(t, err, test.ShouldNotBeNil)
+	test.That(t, err.Error(), test.ShouldContainSubstring, "gantry is stuck")
+	test.That(t, err.Error(), test.ShouldNotContainSubstring, "canceled")
+	test.That(t, arm.inputs, test.ShouldResemble, step["arm"])
+	test.That(t, gantry.stopped, test.ShouldBeTrue)
+	test.That(t, arm.stopped, test.ShouldBeTrue)
+}
+
+func TestMovingComponents(t *testing.T) {
+	current := map[string][]referenceframe.Input{"gantry": {{Value: 0}}, "arm": {{Value: 0}, {Value: 0}}, "camera": {}}
+	plan := []map[string][]referenceframe.Input{
+		{"gantry": {{Value: 0}}, "arm": {{Value: 1}, {Value: 0}}, "camera": {}},
+		{"gantry": {{Value: 0}}, "arm": {{Value: 1}, {Value: 1}}, "camera": {}},
+	}
+	test.That(t, movingComponents(current, plan), test.ShouldResemble, []string{"arm"})
+	test.That(t, movingComponents(current, nil), test.ShouldBeEmpty)
+}
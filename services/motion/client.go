@@ -106,3 +106,47 @@ func (c *client) GetPose(
 	}
 	return referenceframe.ProtobufToPoseInFrame(resp.Pose), nil
 }
+
+func (c *client) Plan(
+	ctx context.Context,
+	componentName resource.Name,
+	destination *referenceframe.PoseInFrame,
+	worldState *commonpb.WorldState,
+	extra map[string]interface{},
+) (*Plan, error) {
+	encodedDestination, err := protoToCommand(referenceframe.PoseInFrameToProtobuf(destination))
+	if err != nil {
+		return nil, err
+	}
+	args := map[string]interface{}{"component": componentName.String(), "destination": encodedDestination}
+	if worldState != nil {
+		if args["world_state"], err = protoToCommand(worldState); err != nil {
+			return nil, err
+		}
+	}
+	resp, err := c.doCommand(ctx, PlanCommand, args, extra)
+	if err != nil {
+		return nil, err
+	}
+	return planFromCommand(resp["plan"])
+}
+
+func (c *client) CheckPlan(
+	ctx context.Context,
+	plan *Plan,
+	worldState *commonpb.WorldState,
+	extra map[string]interface{},
+) error {
+	encodedPlan, err := planToCommand(plan)
+	if err != nil {
+		return err
+	}
+	args := map[string]interface{}{"plan": encodedPlan}
+	if worldState != nil {
+		if args["world_state"], err = protoToCommand(worldState); err != nil {
+			return err
+		}
+	}
+	_, err = c.doCommand(ctx, CheckPlanCommand, args, extra)
+	return err
+}
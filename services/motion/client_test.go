@@ -151,6 +151,91 @@ func TestClient(t *testing.T) {
 		test.That(t, conn.Close(), test.ShouldBeNil)
 	})
 
+	t.Run("plan and check a plan through the command service", func(t *testing.T) {
+		conn, err := viamgrpc.Dial(context.Background(), listener1.Addr().String(), logger)
+		test.That(t, err, test.ShouldBeNil)
+		client := motion.NewClientFromConn(context.Background(), conn, testMotionServiceName, logger)
+
+		obstacle, err := spatialmath.NewBox(spatialmath.NewPoseFromPoint(r3.Vector{X: 100}), r3.Vector{X: 10, Y: 20, Z: 30}, "box")
+		test.That(t, err, test.ShouldBeNil)
+		obstacles, err := referenceframe.GeometriesInFrameToProtobuf(
+			referenceframe.NewGeometriesInFrame(referenceframe.World, map[string]spatialmath.Geometry{"box": obstacle}),
+		)
+		test.That(t, err, test.ShouldBeNil)
+		worldState := &commonpb.WorldState{Obstacles: []*commonpb.GeometriesInFrame{obstacles}}
+		destination := referenceframe.NewPoseInFrame("arm1", spatialmath.NewPoseFromPoint(r3.Vector{X: 1, Y: 2, Z: 3}))
+		extra := map[string]interface{}{"motion_profile": "linear"}
+
+		plan := &motion.Plan{
+			Component: resourceName,
+			Steps: []map[string][]referenceframe.Input{
+				{"arm1": {{Value: 0}, {Value: 0.5}}, "camera": {}},
+				{"arm1": {{Value: 1}, {Value: -0.5}}, "camera": {}},
+			},
+			Poses: []spatialmath.Pose{
+				spatialmath.NewZeroPose(),
+				spatialmath.NewPoseFromOrientation(r3.Vector{X: 1, Y: 2, Z: 3}, &spatialmath.OrientationVectorDegrees{OZ: 1, Theta: 90}),
+			},
+			Cost:        1.5,
+			Constraints: []string{"collision", "self-collision"},
+		}
+		var planWorldState, checkWorldState *commonpb.WorldState
+		var planExtra, checkExtra map[string]interface{}
+		var checkedPlan *motion.Plan
+		injectMS.PlanFunc = func(
+			ctx context.Context,
+			componentName resource.Name,
+			dst *referenceframe.PoseInFrame,
+			worldState *commonpb.WorldState,
+			extra map[string]interface{},
+		) (*motion.Plan, error) {
+			test.That(t, componentName, test.ShouldResemble, resourceName)
+			test.That(t, dst.FrameName(), test.ShouldEqual, "arm1")
+			test.That(t, spatialmath.PoseAlmostEqual(dst.Pose(), destination.Pose()), test.ShouldBeTrue)
+			planWorldState, planExtra = worldState, extra
+			return plan, nil
+		}
+		injectMS.CheckPlanFunc = func(
+			ctx context.Context,
+			plan *motion.Plan,
+			worldState *commonpb.WorldState,
+			extra map[string]interface{},
+		) error {
+			checkedPlan, checkWorldState, checkExtra = plan, worldState, extra
+			return errors.New("component named arm1 has moved since the plan was made")
+		}
+
+		received, err := client.Plan(context.Background(), resourceName, destination, worldState, extra)
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, received.Component, test.ShouldResemble, plan.Component)
+		test.That(t, received.Steps, test.ShouldResemble, plan.Steps)
+		test.That(t, received.Poses, test.ShouldHaveLength, len(plan.Poses))
+		for i, pose := range plan.Poses {
+			test.That(t, spatialmath.PoseAlmostEqual(received.Poses[i], pose), test.ShouldBeTrue)
+		}
+		test.That(t, received.Cost, test.ShouldEqual, plan.Cost)
+		test.That(t, received.Constraints, test.ShouldResemble, plan.Constraints)
+		test.That(t, planWorldState.GetObstacles(), test.ShouldHaveLength, 1)
+		test.That(t, planWorldState.GetObstacles()[0].GetGeometries()[0].GetLabel(), test.ShouldEqual, "box")
+		test.That(t, planExtra, test.ShouldResemble, extra)
+
+		err = client.CheckPlan(context.Background(), received, worldState, extra)
+		test.That(t, err, test.ShouldNotBeNil)
+		test.That(t, err.Error(), test.ShouldContainSubstring, "has moved")
+		test.That(t, checkedPlan.Steps, test.ShouldResemble, plan.Steps)
+		test.That(t, checkedPlan.Constraints, test.ShouldResemble, plan.Constraints)
+		test.That(t, checkWorldState.GetObstacles(), test.ShouldHaveLength, 1)
+		test.That(t, checkExtra, test.ShouldResemble, extra)
+
+		// a world state left out stays nil rather than becoming an empty one
+		test.That(t, client.CheckPlan(context.Background(), received, nil, nil), test.ShouldNotBeNil)
+		test.That(t, checkWorldState, test.ShouldBeNil)
+		test.That(t, checkExtra, test.ShouldBeNil)
+
+		test.That(t, utils.TryClose(context.Background(), client), test.ShouldBeNil)
+		test.That(t, conn.Close(), test.ShouldBeNil)
+	})
+
 	// broken
 	t.Run("motion client 2", func(t *testing.T) {
 		conn, err := viamgrpc.Dial(context.Background(), listener1.Addr().String(), logger)
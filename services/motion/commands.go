@@ -0,0 +1,244 @@
+package motion
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/pkg/errors"
+	commonpb "go.viam.com/api/common/v1"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+
+	"go.viam.com/rdk/components/generic"
+	"go.viam.com/rdk/referenceframe"
+	"go.viam.com/rdk/resource"
+	"go.viam.com/rdk/spatialmath"
+	"go.viam.com/rdk/subtype"
+)
+
+// Commands that carry the parts of the Service the motion API has no messages for. The client sends them
+// through the command service of the subtype and each may have its extra parameters under "extra". Protobuf
+// messages such as poses and world states are sent in their JSON form.
+const (
+	// PlanCommand plans a motion of the "component", named in full, to the "destination" pose in frame
+	// among the obstacles of the "world_state", and returns it under "plan".
+	PlanCommand = "plan"
+	// CheckPlanCommand checks the "plan", as returned by PlanCommand, against the "world_state".
+	CheckPlanCommand = "check_plan"
+)
+
+// commandHandler passes commands sent to the command service of the subtype on to the named service.
+func commandHandler(subtypeSvc subtype.Service) generic.CommandHandler {
+	server := &subtypeServer{subtypeSvc: subtypeSvc}
+	return func(ctx context.Context, name string, cmd map[string]interface{}) (map[string]interface{}, error) {
+		svc, err := server.service(name)
+		if err != nil {
+			return nil, err
+		}
+		return doCommand(ctx, svc, cmd)
+	}
+}
+
+func doCommand(ctx context.Context, svc Service, cmd map[string]interface{}) (map[string]interface{}, error) {
+	name, ok := cmd["command"]
+	if !ok {
+		return nil, errors.New("missing 'command' value")
+	}
+	extra, _ := cmd["extra"].(map[string]interface{})
+	worldState, err := worldStateFromCommand(cmd["world_state"])
+	if err != nil {
+		return nil, err
+	}
+	switch name {
+	case PlanCommand:
+		componentName, err := componentNameFromCommand(cmd["component"])
+		if err != nil {
+			return nil, err
+		}
+		destination := &commonpb.PoseInFrame{}
+		if err := protoFromCommand(cmd["destination"], destination); err != nil {
+			return nil, errors.Wrap(err, "destination")
+		}
+		plan, err := svc.Plan(ctx, componentName, referenceframe.ProtobufToPoseInFrame(destination), worldState, extra)
+		if err != nil {
+			return nil, err
+		}
+		encoded, err := planToCommand(plan)
+		if err != nil {
+			return nil, err
+		}
+		return map[string]interface{}{"plan": encoded}, nil
+	case CheckPlanCommand:
+		plan, err := planFromCommand(cmd["plan"])
+		if err != nil {
+			return nil, err
+		}
+		return nil, svc.CheckPlan(ctx, plan, worldState, extra)
+	default:
+		return nil, errors.Errorf("no such command: %s", name)
+	}
+}
+
+func (c *client) doCommand(
+	ctx context.Context,
+	name string,
+	args map[string]interface{},
+	extra map[string]interface{},
+) (map[string]interface{}, error) {
+	cmd := map[string]interface{}{"command": name}
+	for k, v := range args {
+		cmd[k] = v
+	}
+	if extra != nil {
+		cmd["extra"] = extra
+	}
+	return generic.DoFromCommandService(ctx, c.conn, Subtype, c.name, cmd)
+}
+
+func planToCommand(plan *Plan) (map[string]interface{}, error) {
+	steps := make([]interface{}, 0, len(plan.Steps))
+	for _, step := range plan.Steps {
+		encoded := make(map[string]interface{}, len(step))
+		for name, inputs := range step {
+			values := make([]interface{}, 0, len(inputs))
+			for _, input := range inputs {
+				values = append(values, input.Value)
+			}
+			encoded[name] = values
+		}
+		steps = append(steps, encoded)
+	}
+	poses := make([]interface{}, 0, len(plan.Poses))
+	for _, pose := range plan.Poses {
+		encoded, err := protoToCommand(spatialmath.PoseToProtobuf(pose))
+		if err != nil {
+			return nil, err
+		}
+		poses = append(poses, encoded)
+	}
+	constraints := make([]interface{}, 0, len(plan.Constraints))
+	for _, constraint := range plan.Constraints {
+		constraints = append(constraints, constraint)
+	}
+	return map[string]interface{}{
+		"component":   plan.Component.String(),
+		"steps":       steps,
+		"poses":       poses,
+		"cost":        plan.Cost,
+		"constraints": constraints,
+	}, nil
+}
+
+func planFromCommand(raw interface{}) (*Plan, error) {
+	encoded, ok := raw.(map[string]interface{})
+	if !ok {
+		return nil, errors.New("need a plan")
+	}
+	componentName, err := componentNameFromCommand(encoded["component"])
+	if err != nil {
+		return nil, err
+	}
+	plan := &Plan{Component: componentName}
+
+	steps, ok := encoded["steps"].([]interface{})
+	if !ok {
+		return nil, errors.New("steps must be a list")
+	}
+	for i, rawStep := range steps {
+		encodedStep, ok := rawStep.(map[string]interface{})
+		if !ok {
+			return nil, errors.Errorf("step %d must be a map", i)
+		}
+		step := make(map[string][]referenceframe.Input, len(encodedStep))
+		for name, rawValues := range encodedStep {
+			values, ok := rawValues.([]interface{})
+			if !ok {
+				return nil, errors.Errorf("the inputs of %s in step %d must be a list", name, i)
+			}
+			inputs := make([]referenceframe.Input, 0, len(values))
+			for _, rawValue := range values {
+				value, ok := rawValue.(float64)
+				if !ok {
+					return nil, errors.Errorf("the inputs of %s in step %d must be numbers", name, i)
+				}
+				inputs = append(inputs, referenceframe.Input{Value: value})
+			}
+			step[name] = inputs
+		}
+		plan.Steps = append(plan.Steps, step)
+	}
+
+	if rawPoses, ok := encoded["poses"]; ok {
+		poses, ok := rawPoses.([]interface{})
+		if !ok {
+			return nil, errors.New("poses must be a list")
+		}
+		for i, rawPose := range poses {
+			pose := &commonpb.Pose{}
+			if err := protoFromCommand(rawPose, pose); err != nil {
+				return nil, errors.Wrapf(err, "pose %d", i)
+			}
+			plan.Poses = append(plan.Poses, spatialmath.NewPoseFromProtobuf(pose))
+		}
+	}
+	plan.Cost, _ = encoded["cost"].(float64)
+	if rawConstraints, ok := encoded["constraints"]; ok {
+		constraints, ok := rawConstraints.([]interface{})
+		if !ok {
+			return nil, errors.New("constraints must be a list")
+		}
+		for i, rawConstraint := range constraints {
+			constraint, ok := rawConstraint.(string)
+			if !ok {
+				return nil, errors.Errorf("constraint %d must be a name", i)
+			}
+			plan.Constraints = append(plan.Constraints, constraint)
+		}
+	}
+	return plan, nil
+}
+
+func componentNameFromCommand(raw interface{}) (resource.Name, error) {
+	name, ok := raw.(string)
+	if !ok {
+		return resource.Name{}, errors.New("need the name of a component")
+	}
+	return resource.NewFromString(name)
+}
+
+func worldStateFromCommand(raw interface{}) (*commonpb.WorldState, error) {
+	if raw == nil {
+		return nil, nil
+	}
+	worldState := &commonpb.WorldState{}
+	if err := protoFromCommand(raw, worldState); err != nil {
+		return nil, errors.Wrap(err, "world state")
+	}
+	return worldState, nil
+}
+
+// protoToCommand returns the JSON form of a protobuf message as a map.
+func protoToCommand(msg proto.Message) (map[string]interface{}, error) {
+	data, err := protojson.Marshal(msg)
+	if err != nil {
+		return nil, err
+	}
+	var encoded map[string]interface{}
+	if err := json.Unmarshal(data, &encoded); err != nil {
+		return nil, err
+	}
+	return encoded, nil
+}
+
+// protoFromCommand reads a protobuf message from its JSON form as a map.
+func protoFromCommand(raw interface{}, msg proto.Message) error {
+	encoded, ok := raw.(map[string]interface{})
+	if !ok {
+		return errors.New("need a map")
+	}
+	data, err := json.Marshal(encoded)
+	if err != nil {
+		return err
+	}
+	return protojson.Unmarshal(data, msg)
+}
@@ -11,10 +11,12 @@ import (
 	goutils "go.viam.com/utils"
 	"go.viam.com/utils/rpc"
 
+	"go.viam.com/rdk/components/generic"
 	"go.viam.com/rdk/referenceframe"
 	"go.viam.com/rdk/registry"
 	"go.viam.com/rdk/resource"
 	"go.viam.com/rdk/robot"
+	"go.viam.com/rdk/spatialmath"
 	"go.viam.com/rdk/subtype"
 	"go.viam.com/rdk/utils"
 )
@@ -22,12 +24,15 @@ import (
 func init() {
 	registry.RegisterResourceSubtype(Subtype, registry.ResourceSubtype{
 		RegisterRPCService: func(ctx context.Context, rpcServer rpc.Server, subtypeSvc subtype.Service) error {
-			return rpcServer.RegisterServiceServer(
+			if err := rpcServer.RegisterServiceServer(
 				ctx,
 				&servicepb.MotionService_ServiceDesc,
 				NewServer(subtypeSvc),
 				servicepb.RegisterMotionServiceHandlerFromEndpoint,
-			)
+			); err != nil {
+				return err
+			}
+			return generic.RegisterCommandService(ctx, rpcServer, Subtype, commandHandler(subtypeSvc))
 		},
 		RPCServiceDesc: &servicepb.MotionService_ServiceDesc,
 		RPCClient: func(ctx context.Context, conn rpc.ClientConn, name string, logger golog.Logger) interface{} {
@@ -60,6 +65,37 @@ type Service interface {
 		supplementalTransforms []*commonpb.Transform,
 		extra map[string]interface{},
 	) (*referenceframe.PoseInFrame, error)
+	// Plan plans the same motion as Move would, without moving anything.
+	Plan(
+		ctx context.Context,
+		componentName resource.Name,
+		destination *referenceframe.PoseInFrame,
+		worldState *commonpb.WorldState,
+		extra map[string]interface{},
+	) (*Plan, error)
+	// CheckPlan returns an error if a plan returned by Plan no longer starts where the robot is, or would collide with the obstacles
+	// of the given world state.
+	CheckPlan(
+		ctx context.Context,
+		plan *Plan,
+		worldState *commonpb.WorldState,
+		extra map[string]interface{},
+	) error
+}
+
+// A Plan is a motion of a component to a destination that has been planned but not executed.
+type Plan struct {
+	// Component is the component that the plan moves to the destination.
+	Component resource.Name
+	// Steps holds the inputs of the components of the robot at each step of the plan, starting from where they were when it was
+	// planned.
+	Steps []map[string][]referenceframe.Input
+	// Poses holds the pose of the component in the world frame at each step.
+	Poses []spatialmath.Pose
+	// Cost is the total distance that the inputs of the components move in joint space over the plan.
+	Cost float64
+	// Constraints holds the names of the constraints that the motion was planned to satisfy.
+	Constraints []string
 }
 
 var (
@@ -147,6 +183,29 @@ func (svc *reconfigurableMotionService) GetPose(
 	return svc.actual.GetPose(ctx, componentName, destinationFrame, supplementalTransforms, extra)
 }
 
+func (svc *reconfigurableMotionService) Plan(
+	ctx context.Context,
+	componentName resource.Name,
+	destination *referenceframe.PoseInFrame,
+	worldState *commonpb.WorldState,
+	extra map[string]interface{},
+) (*Plan, error) {
+	svc.mu.RLock()
+	defer svc.mu.RUnlock()
+	return svc.actual.Plan(ctx, componentName, destination, worldState, extra)
+}
+
+func (svc *reconfigurableMotionService) CheckPlan(
+	ctx context.Context,
+	plan *Plan,
+	worldState *commonpb.WorldState,
+	extra map[string]interface{},
+) error {
+	svc.mu.RLock()
+	defer svc.mu.RUnlock()
+	return svc.actual.CheckPlan(ctx, plan, worldState, extra)
+}
+
 func (svc *reconfigurableMotionService) Close(ctx context.Context) error {
 	svc.mu.RLock()
 	defer svc.mu.RUnlock()
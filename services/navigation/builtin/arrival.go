@@ -0,0 +1,93 @@
+package builtin
+
+import (
+	"context"
+	"time"
+
+	"github.com/pkg/errors"
+	"go.viam.com/utils"
+
+	"go.viam.com/rdk/components/generic"
+	"go.viam.com/rdk/registry"
+	"go.viam.com/rdk/services/navigation"
+	rdkutils "go.viam.com/rdk/utils"
+)
+
+// arrivalDistanceKm is how close the base needs to get to a waypoint for it to count as visited when the
+// waypoint does not have its own arrival radius.
+const arrivalDistanceKm = .005
+
+// actionResourceFromDependencies finds the resource with the given name in the dependencies, regardless
+// of its type, as long as it can take commands.
+func actionResourceFromDependencies(deps registry.Dependencies, name string) (generic.Generic, error) {
+	for resName, res := range deps {
+		if resName.ShortName() != name {
+			continue
+		}
+		g, ok := res.(generic.Generic)
+		if !ok {
+			return nil, rdkutils.DependencyTypeError(name, (*generic.Generic)(nil), res)
+		}
+		return g, nil
+	}
+	return nil, rdkutils.DependencyNotFoundError(name)
+}
+
+func arrivalRadiusKm(wp navigation.Waypoint) float64 {
+	if wp.ArrivalRadiusMeters > 0 {
+		return wp.ArrivalRadiusMeters / 1000
+	}
+	return arrivalDistanceKm
+}
+
+func (svc *builtIn) mmPerSec(wp navigation.Waypoint) float64 {
+	if wp.MMPerSec > 0 {
+		return wp.MMPerSec
+	}
+	return svc.mmPerSecDefault
+}
+
+// arrive carries out everything that should happen once the base reaches a waypoint: stopping, turning to
+// the heading of the waypoint, running its actions and dwelling. The waypoint is then marked as visited.
+// heading is used to get the current heading of the base if the waypoint has one to turn to.
+func (svc *builtIn) arrive(
+	ctx context.Context,
+	wp navigation.Waypoint,
+	heading func(ctx context.Context) (float64, error),
+) error {
+	if err := svc.base.Stop(ctx, nil); err != nil {
+		return err
+	}
+
+	if wp.Heading != nil {
+		current, err := heading(ctx)
+		if err != nil {
+			return errors.Wrap(err, "cannot turn to waypoint heading")
+		}
+		// counterclockwise spins are positive while compass headings go clockwise
+		turn := computeBearing(*wp.Heading, current)
+		if err := svc.base.Spin(ctx, turn, svc.degPerSecDefault, nil); err != nil {
+			return errors.Wrap(err, "error turning to waypoint heading")
+		}
+	}
+
+	for i, action := range wp.Actions {
+		res, ok := svc.actionResources[action.Resource]
+		if !ok {
+			svc.logger.Errorw("waypoint action resource is not configured", "resource", action.Resource)
+			continue
+		}
+		// a failed action should not keep the robot from moving on
+		if _, err := res.DoCommand(ctx, action.Command); err != nil {
+			svc.logger.Errorw("waypoint action failed", "action", i, "resource", action.Resource, "error", err)
+		}
+	}
+
+	if wp.DwellSecs > 0 {
+		if !utils.SelectContextOrWait(ctx, time.Duration(wp.DwellSecs*float64(time.Second))) {
+			return ctx.Err()
+		}
+	}
+
+	return svc.store.WaypointVisited(ctx, wp.ID)
+}
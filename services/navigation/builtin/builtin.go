@@ -16,6 +16,7 @@ import (
 	"go.viam.com/utils"
 
 	"go.viam.com/rdk/components/base"
+	"go.viam.com/rdk/components/generic"
 	"go.viam.com/rdk/components/movementsensor"
 	"go.viam.com/rdk/config"
 	"go.viam.com/rdk/registry"
@@ -27,6 +28,11 @@ import (
 const (
 	mmPerSecDefault  = 500
 	degPerSecDefault = 45
+
+	// steeringSpinAndMove alternates between spinning toward the next waypoint and driving straight at it.
+	steeringSpinAndMove = "spin_and_move"
+	// steeringPurePursuit continuously steers the base along the path between waypoints with SetVelocity.
+	steeringPurePursuit = "pure_pursuit"
 )
 
 func init() {
@@ -57,12 +63,25 @@ type Config struct {
 	MovementSensorName string                 `json:"movement_sensor"`
 	DegPerSecDefault   float64                `json:"degs_per_sec"`
 	MMPerSecDefault    float64                `json:"mm_per_sec"`
+	Steering           string                 `json:"steering"`
+	LookaheadMeters    float64                `json:"lookahead_m"`
+	// ActionResources are the resources that waypoint actions may send commands to.
+	ActionResources []string `json:"action_resources"`
 }
 
 // Validate creates the list of implicit dependencies.
 func (config *Config) Validate(path string) ([]string, error) {
 	var deps []string
 
+	switch config.Steering {
+	case "", steeringSpinAndMove, steeringPurePursuit:
+	default:
+		return nil, errors.Errorf("unknown steering mode %q", config.Steering)
+	}
+	if config.LookaheadMeters < 0 {
+		return nil, errors.New("lookahead_m cannot be negative")
+	}
+
 	if config.BaseName == "" {
 		return nil, utils.NewConfigValidationFieldRequiredError(path, "base")
 	}
@@ -72,6 +91,7 @@ func (config *Config) Validate(path string) ([]string, error) {
 		return nil, utils.NewConfigValidationFieldRequiredError(path, "movement_sensor")
 	}
 	deps = append(deps, config.MovementSensorName)
+	deps = append(deps, config.ActionResources...)
 
 	return deps, nil
 }
@@ -91,6 +111,15 @@ func NewBuiltIn(ctx context.Context, deps registry.Dependencies, config config.S
 		return nil, err
 	}
 
+	actionResources := make(map[string]generic.Generic, len(svcConfig.ActionResources))
+	for _, name := range svcConfig.ActionResources {
+		res, err := actionResourceFromDependencies(deps, name)
+		if err != nil {
+			return nil, err
+		}
+		actionResources[name] = res
+	}
+
 	var store navigation.NavStore
 	switch svcConfig.Store.Type {
 	case navigation.StoreTypeMemory:
@@ -101,6 +130,12 @@ func NewBuiltIn(ctx context.Context, deps registry.Dependencies, config config.S
 		if err != nil {
 			return nil, err
 		}
+	case navigation.StoreTypeBoltDB:
+		var err error
+		store, err = navigation.NewBoltDBNavigationStore(svcConfig.Store.Config)
+		if err != nil {
+			return nil, err
+		}
 	default:
 		return nil, errors.Errorf("unknown store type %q", svcConfig.Store.Type)
 	}
@@ -114,14 +149,26 @@ func NewBuiltIn(ctx context.Context, deps registry.Dependencies, config config.S
 	if spinSpeed == 0 {
 		spinSpeed = degPerSecDefault
 	}
+	steering := svcConfig.Steering
+	if steering == "" {
+		steering = steeringSpinAndMove
+	}
+	lookahead := svcConfig.LookaheadMeters
+	if lookahead == 0 {
+		lookahead = lookaheadMetersDefault
+	}
 
 	cancelCtx, cancelFunc := context.WithCancel(context.Background())
 	navSvc := &builtIn{
 		store:            store,
 		base:             base1,
 		movementSensor:   movementSensor,
+		actionResources:  actionResources,
 		mmPerSecDefault:  straightSpeed,
 		degPerSecDefault: spinSpeed,
+		steering:         steering,
+		lookaheadMeters:  lookahead,
+		pursuitInterval:  purePursuitInterval,
 		logger:           logger,
 		cancelCtx:        cancelCtx,
 		cancelFunc:       cancelFunc,
@@ -133,12 +180,19 @@ type builtIn struct {
 	mu    sync.RWMutex
 	store navigation.NavStore
 	mode  navigation.Mode
+	// zonesMu is held while waypoints or zones are checked against each other and then added, so that a
+	// waypoint outside of a geofence cannot be added alongside it.
+	zonesMu sync.Mutex
 
-	base           base.Base
-	movementSensor movementsensor.MovementSensor
+	base            base.Base
+	movementSensor  movementsensor.MovementSensor
+	actionResources map[string]generic.Generic
 
 	mmPerSecDefault         float64
 	degPerSecDefault        float64
+	steering                string
+	lookaheadMeters         float64
+	pursuitInterval         time.Duration
 	logger                  golog.Logger
 	cancelCtx               context.Context
 	cancelFunc              func()
@@ -176,6 +230,9 @@ func (svc *builtIn) SetMode(ctx context.Context, mode navigation.Mode, extra map
 }
 
 func (svc *builtIn) startWaypoint(extra map[string]interface{}) error {
+	if svc.steering == steeringPurePursuit {
+		return svc.startPurePursuit(extra)
+	}
 	svc.activeBackgroundWorkers.Add(1)
 	utils.PanicCapturingGo(func() {
 		defer svc.activeBackgroundWorkers.Done()
@@ -207,15 +264,24 @@ func (svc *builtIn) startWaypoint(extra map[string]interface{}) error {
 				pathLen := len(path)
 				currentBearing := fixAngle(path[pathLen-2].BearingTo(path[pathLen-1]))
 
-				bearingToGoal, distanceToGoal, err := svc.waypointDirectionAndDistanceToGo(ctx, currentLoc)
+				wp, err := svc.nextWaypoint(ctx)
 				if err != nil {
 					return err
 				}
-
-				if distanceToGoal < .005 {
+				if currentLoc.GreatCircleDistance(wp.ToPoint()) < arrivalRadiusKm(wp) {
 					svc.logger.Debug("i made it")
-					return svc.waypointReached(ctx)
+					return svc.arrive(ctx, wp, func(ctx context.Context) (float64, error) {
+						return currentBearing, nil
+					})
+				}
+
+				// head for the next point on the route around any keep out zones
+				goal, err := svc.nextGoal(ctx, currentLoc, wp.ToPoint())
+				if err != nil {
+					return err
 				}
+				bearingToGoal := fixAngle(currentLoc.BearingTo(goal))
+				distanceToGoal := currentLoc.GreatCircleDistance(goal)
 
 				bearingDelta := computeBearing(bearingToGoal, currentBearing)
 				steeringDir := -bearingDelta / 180.0
@@ -233,7 +299,7 @@ func (svc *builtIn) startWaypoint(extra map[string]interface{}) error {
 				distanceMm := distanceToGoal * 1000 * 1000
 				distanceMm = math.Min(distanceMm, 10*1000)
 
-				if err := svc.base.MoveStraight(ctx, int(distanceMm), svc.mmPerSecDefault, nil); err != nil {
+				if err := svc.base.MoveStraight(ctx, int(distanceMm), svc.mmPerSec(wp), nil); err != nil {
 					return fmt.Errorf("error moving %w", err)
 				}
 
@@ -242,23 +308,17 @@ func (svc *builtIn) startWaypoint(extra map[string]interface{}) error {
 
 			if err := navOnce(svc.cancelCtx); err != nil {
 				svc.logger.Infof("error navigating: %s", err)
+				if errors.Is(err, errOutsideGeofence) {
+					if err := svc.base.Stop(svc.cancelCtx, nil); err != nil {
+						svc.logger.Errorw("failed to stop base", "error", err)
+					}
+				}
 			}
 		}
 	})
 	return nil
 }
 
-func (svc *builtIn) waypointDirectionAndDistanceToGo(ctx context.Context, currentLoc *geo.Point) (float64, float64, error) {
-	wp, err := svc.nextWaypoint(ctx)
-	if err != nil {
-		return 0, 0, err
-	}
-
-	goal := wp.ToPoint()
-
-	return fixAngle(currentLoc.BearingTo(goal)), currentLoc.GreatCircleDistance(goal), nil
-}
-
 func (svc *builtIn) Location(ctx context.Context, extra map[string]interface{}) (*geo.Point, error) {
 	if svc.movementSensor == nil {
 		return nil, errors.New("no way to get location")
@@ -277,8 +337,27 @@ func (svc *builtIn) Waypoints(ctx context.Context, extra map[string]interface{})
 	return wpsCopy, nil
 }
 
+// AddWaypoint adds a waypoint. Any WaypointOptions in extra are stored with it.
 func (svc *builtIn) AddWaypoint(ctx context.Context, point *geo.Point, extra map[string]interface{}) error {
-	_, err := svc.store.AddWaypoint(ctx, point)
+	opts, err := navigation.WaypointOptionsFromExtra(extra)
+	if err != nil {
+		return err
+	}
+	for _, action := range opts.Actions {
+		if _, ok := svc.actionResources[action.Resource]; !ok {
+			return errors.Errorf("waypoint action resource %q is not one of the configured action_resources", action.Resource)
+		}
+	}
+	svc.zonesMu.Lock()
+	defer svc.zonesMu.Unlock()
+	zs, err := svc.loadZones(ctx)
+	if err != nil {
+		return err
+	}
+	if err := zs.checkLocation(point); err != nil {
+		return errors.Wrap(err, "cannot add waypoint")
+	}
+	_, err = svc.store.AddWaypoint(ctx, point, opts)
 	return err
 }
 
@@ -290,14 +369,6 @@ func (svc *builtIn) nextWaypoint(ctx context.Context) (navigation.Waypoint, erro
 	return svc.store.NextWaypoint(ctx)
 }
 
-func (svc *builtIn) waypointReached(ctx context.Context) error {
-	wp, err := svc.nextWaypoint(ctx)
-	if err != nil {
-		return fmt.Errorf("can't mark waypoint reached: %w", err)
-	}
-	return svc.store.WaypointVisited(ctx, wp.ID)
-}
-
 func (svc *builtIn) Close(ctx context.Context) error {
 	svc.cancelFunc()
 	svc.activeBackgroundWorkers.Wait()
@@ -0,0 +1,712 @@
+package builtin
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/edaniels/golog"
+	"github.com/golang/geo/r3"
+	geo "github.com/kellydunn/golang-geo"
+	"go.viam.com/test"
+	"go.viam.com/utils/testutils"
+
+	"go.viam.com/rdk/components/base"
+	fakebase "go.viam.com/rdk/components/base/fake"
+	"go.viam.com/rdk/components/generic"
+	"go.viam.com/rdk/components/movementsensor"
+	fakemovementsensor "go.viam.com/rdk/components/movementsensor/fake"
+	"go.viam.com/rdk/config"
+	"go.viam.com/rdk/registry"
+	"go.viam.com/rdk/services/navigation"
+	"go.viam.com/rdk/spatialmath"
+	"go.viam.com/rdk/testutils/inject"
+	rdkutils "go.viam.com/rdk/utils"
+)
+
+// simulatedRover is a unicycle model of a base that integrates the last commanded velocity by a fixed
+// time step every time a velocity is set.
+type simulatedRover struct {
+	mu      sync.Mutex
+	loc     *geo.Point
+	heading float64
+	step    time.Duration
+	spins   int
+}
+
+func (sr *simulatedRover) setVelocity(linear, angular r3.Vector) {
+	sr.mu.Lock()
+	defer sr.mu.Unlock()
+	dt := sr.step.Seconds()
+	sr.heading = fixAngle(sr.heading - angular.Z*dt)
+	sr.loc = sr.loc.PointAtDistanceAndBearing(linear.Y*dt/1000/1000, sr.heading)
+}
+
+func (sr *simulatedRover) location() *geo.Point {
+	sr.mu.Lock()
+	defer sr.mu.Unlock()
+	return sr.loc
+}
+
+func setupSimulatedNavigation(t *testing.T, sr *simulatedRover, conf *Config) *builtIn {
+	t.Helper()
+	injectBase := &inject.Base{LocalBase: &fakebase.Base{}}
+	injectBase.SetVelocityFunc = func(ctx context.Context, linear, angular r3.Vector, extra map[string]interface{}) error {
+		sr.setVelocity(linear, angular)
+		return nil
+	}
+	injectBase.SpinFunc = func(ctx context.Context, angleDeg, degsPerSec float64, extra map[string]interface{}) error {
+		sr.mu.Lock()
+		defer sr.mu.Unlock()
+		sr.spins++
+		sr.heading = fixAngle(sr.heading - angleDeg)
+		return nil
+	}
+
+	injectMS := &inject.MovementSensor{MovementSensor: &fakemovementsensor.MovementSensor{}}
+	injectMS.PositionFunc = func(ctx context.Context, extra map[string]interface{}) (*geo.Point, float64, error) {
+		return sr.location(), 0, nil
+	}
+	injectMS.CompassHeadingFunc = func(ctx context.Context, extra map[string]interface{}) (float64, error) {
+		sr.mu.Lock()
+		defer sr.mu.Unlock()
+		return sr.heading, nil
+	}
+
+	deps := registry.Dependencies{
+		base.Named(conf.BaseName):                     injectBase,
+		movementsensor.Named(conf.MovementSensorName): injectMS,
+	}
+	svc, err := NewBuiltIn(context.Background(), deps, config.Service{ConvertedAttributes: conf}, golog.NewTestLogger(t))
+	test.That(t, err, test.ShouldBeNil)
+	navSvc := svc.(*builtIn)
+	navSvc.pursuitInterval = time.Millisecond
+	return navSvc
+}
+
+func TestConfigValidate(t *testing.T) {
+	conf := &Config{BaseName: "base", MovementSensorName: "ms"}
+	deps, err := conf.Validate("path")
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, deps, test.ShouldResemble, []string{"base", "ms"})
+
+	conf.Steering = steeringPurePursuit
+	_, err = conf.Validate("path")
+	test.That(t, err, test.ShouldBeNil)
+
+	conf.Steering = "stanley"
+	_, err = conf.Validate("path")
+	test.That(t, err, test.ShouldBeError, `unknown steering mode "stanley"`)
+}
+
+func TestLookaheadPoint(t *testing.T) {
+	start := geo.NewPoint(40, -74)
+	goal := start.PointAtDistanceAndBearing(.1, 90)
+
+	// on the path, the target is the lookahead distance further along it
+	current := start.PointAtDistanceAndBearing(.02, 90)
+	target := lookaheadPoint(start, goal, current, .01)
+	test.That(t, start.GreatCircleDistance(target), test.ShouldAlmostEqual, .03, 1e-6)
+
+	// off to the side of the path, the target stays on the path
+	current = start.PointAtDistanceAndBearing(.02, 60)
+	target = lookaheadPoint(start, goal, current, .01)
+	test.That(t, start.BearingTo(target), test.ShouldAlmostEqual, start.BearingTo(goal), 1e-3)
+
+	// near the end of the path, the target is the goal
+	current = start.PointAtDistanceAndBearing(.095, 90)
+	test.That(t, lookaheadPoint(start, goal, current, .01), test.ShouldEqual, goal)
+}
+
+func TestPurePursuitVelocity(t *testing.T) {
+	current := geo.NewPoint(40, -74)
+
+	linear, angular := purePursuitVelocity(current, 0, current.PointAtDistanceAndBearing(.003, 0), 500, 45)
+	test.That(t, linear, test.ShouldAlmostEqual, 500)
+	test.That(t, angular, test.ShouldAlmostEqual, 0, 1e-6)
+
+	// target to the right means a clockwise (negative) turn
+	_, angular = purePursuitVelocity(current, 0, current.PointAtDistanceAndBearing(.003, 20), 500, 45)
+	test.That(t, angular, test.ShouldBeLessThan, 0)
+
+	// target to the left means a counterclockwise (positive) turn
+	_, angular = purePursuitVelocity(current, 0, current.PointAtDistanceAndBearing(.003, 340), 500, 45)
+	test.That(t, angular, test.ShouldBeGreaterThan, 0)
+
+	// target behind means turning in place
+	linear, angular = purePursuitVelocity(current, 0, current.PointAtDistanceAndBearing(.003, 200), 500, 45)
+	test.That(t, linear, test.ShouldEqual, 0)
+	test.That(t, angular, test.ShouldEqual, 45)
+}
+
+func TestPurePursuitNavigation(t *testing.T) {
+	start := geo.NewPoint(40, -74)
+	sr := &simulatedRover{loc: start, heading: 180, step: 100 * time.Millisecond}
+	svc := setupSimulatedNavigation(t, sr, &Config{
+		Store:              navigation.StoreConfig{Type: navigation.StoreTypeMemory},
+		BaseName:           "base",
+		MovementSensorName: "ms",
+		Steering:           steeringPurePursuit,
+		MMPerSecDefault:    1000,
+		DegPerSecDefault:   90,
+	})
+	defer func() {
+		test.That(t, svc.Close(context.Background()), test.ShouldBeNil)
+	}()
+
+	ctx := context.Background()
+	wp1 := start.PointAtDistanceAndBearing(.03, 0)
+	wp2 := wp1.PointAtDistanceAndBearing(.03, 90)
+	test.That(t, svc.AddWaypoint(ctx, wp1, nil), test.ShouldBeNil)
+	test.That(t, svc.AddWaypoint(ctx, wp2, nil), test.ShouldBeNil)
+	test.That(t, svc.SetMode(ctx, navigation.ModeWaypoint, nil), test.ShouldBeNil)
+
+	testutils.WaitForAssertionWithSleep(t, 10*time.Millisecond, 1000, func(tb testing.TB) {
+		tb.Helper()
+		wps, err := svc.Waypoints(ctx, nil)
+		test.That(tb, err, test.ShouldBeNil)
+		test.That(tb, wps, test.ShouldBeEmpty)
+	})
+	test.That(t, sr.location().GreatCircleDistance(wp2), test.ShouldBeLessThan, arrivalDistanceKm)
+	sr.mu.Lock()
+	defer sr.mu.Unlock()
+	test.That(t, sr.spins, test.ShouldEqual, 0)
+}
+
+func TestPurePursuitSegmentStart(t *testing.T) {
+	start := geo.NewPoint(40, -74)
+	sr := &simulatedRover{loc: start, heading: 0, step: 100 * time.Millisecond}
+	svc := setupSimulatedNavigation(t, sr, &Config{
+		Store:              navigation.StoreConfig{Type: navigation.StoreTypeMemory},
+		BaseName:           "base",
+		MovementSensorName: "ms",
+		Steering:           steeringPurePursuit,
+	})
+	defer func() {
+		test.That(t, svc.Close(context.Background()), test.ShouldBeNil)
+	}()
+
+	ctx := context.Background()
+	wp2 := start.PointAtDistanceAndBearing(.03, 90)
+	test.That(t, svc.AddWaypoint(ctx, start, nil), test.ShouldBeNil)
+	test.That(t, svc.AddWaypoint(ctx, wp2, nil), test.ShouldBeNil)
+
+	// the first waypoint is reached where the base is, so the path to the second starts from it even
+	// after the base has drifted away
+	state := &pursuitState{}
+	test.That(t, svc.pursueOnce(ctx, state, nil), test.ShouldBeNil)
+	sr.mu.Lock()
+	sr.loc = start.PointAtDistanceAndBearing(.005, 0)
+	sr.mu.Unlock()
+	test.That(t, svc.pursueOnce(ctx, state, nil), test.ShouldBeNil)
+	test.That(t, state.segmentStart.GreatCircleDistance(start), test.ShouldAlmostEqual, 0)
+
+	// without a waypoint reached, the path starts from wherever the base is
+	state = &pursuitState{}
+	test.That(t, svc.pursueOnce(ctx, state, nil), test.ShouldBeNil)
+	test.That(t, state.segmentStart, test.ShouldResemble, sr.location())
+}
+
+func TestHeadingFallback(t *testing.T) {
+	injectMS := &inject.MovementSensor{}
+	injectMS.CompassHeadingFunc = func(ctx context.Context, extra map[string]interface{}) (float64, error) {
+		return 0, movementsensor.ErrMethodUnimplementedCompassHeading
+	}
+	injectMS.OrientationFunc = func(ctx context.Context, extra map[string]interface{}) (spatialmath.Orientation, error) {
+		return nil, movementsensor.ErrMethodUnimplementedOrientation
+	}
+	svc := &builtIn{movementSensor: injectMS}
+	state := &pursuitState{}
+	_, err := svc.heading(context.Background(), state, nil)
+	test.That(t, err, test.ShouldNotBeNil)
+
+	state.prevFix = geo.NewPoint(40, -74)
+	state.lastFix = state.prevFix.PointAtDistanceAndBearing(.01, 45)
+	heading, err := svc.heading(context.Background(), state, nil)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, heading, test.ShouldAlmostEqual, 45, 1e-3)
+}
+
+// squareAround returns the corners of a square with the given half width in km centered on center.
+func squareAround(center *geo.Point, halfWidthKm float64) []*geo.Point {
+	north := center.PointAtDistanceAndBearing(halfWidthKm, 0)
+	south := center.PointAtDistanceAndBearing(halfWidthKm, 180)
+	return []*geo.Point{
+		north.PointAtDistanceAndBearing(halfWidthKm, 270),
+		north.PointAtDistanceAndBearing(halfWidthKm, 90),
+		south.PointAtDistanceAndBearing(halfWidthKm, 90),
+		south.PointAtDistanceAndBearing(halfWidthKm, 270),
+	}
+}
+
+func TestPlanRoute(t *testing.T) {
+	start := geo.NewPoint(40, -74)
+	goal := start.PointAtDistanceAndBearing(.1, 0)
+
+	t.Run("no zones", func(t *testing.T) {
+		route, err := planRoute(start, goal, &zoneSet{})
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, route, test.ShouldResemble, []*geo.Point{start, goal})
+	})
+
+	keepOut, err := navigation.NewZone(navigation.ZoneTypeKeepOut, squareAround(start.PointAtDistanceAndBearing(.05, 0), .01))
+	test.That(t, err, test.ShouldBeNil)
+	zs := &zoneSet{keepOuts: []navigation.Zone{keepOut}}
+
+	t.Run("around a keep out zone", func(t *testing.T) {
+		route, err := planRoute(start, goal, zs)
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, len(route), test.ShouldBeGreaterThan, 2)
+		test.That(t, route[0], test.ShouldEqual, start)
+		test.That(t, route[len(route)-1], test.ShouldEqual, goal)
+		for i := 1; i < len(route); i++ {
+			// sample along each leg to make sure none of it enters the zone
+			for f := 0.; f <= 1; f += .05 {
+				leg := route[i-1].GreatCircleDistance(route[i])
+				p := route[i-1].PointAtDistanceAndBearing(leg*f, route[i-1].BearingTo(route[i]))
+				test.That(t, keepOut.Contains(p), test.ShouldBeFalse)
+			}
+		}
+	})
+
+	t.Run("goal inside keep out zone", func(t *testing.T) {
+		_, err := planRoute(start, start.PointAtDistanceAndBearing(.05, 0), zs)
+		test.That(t, err, test.ShouldNotBeNil)
+	})
+
+	t.Run("fence blocks the way around", func(t *testing.T) {
+		fence, err := navigation.NewZone(navigation.ZoneTypeGeofence, []*geo.Point{
+			start.PointAtDistanceAndBearing(.005, 180).PointAtDistanceAndBearing(.005, 270),
+			start.PointAtDistanceAndBearing(.005, 180).PointAtDistanceAndBearing(.005, 90),
+			goal.PointAtDistanceAndBearing(.005, 0).PointAtDistanceAndBearing(.005, 90),
+			goal.PointAtDistanceAndBearing(.005, 0).PointAtDistanceAndBearing(.005, 270),
+		})
+		test.That(t, err, test.ShouldBeNil)
+		_, err = planRoute(start, goal, &zoneSet{fence: &fence, keepOuts: zs.keepOuts})
+		test.That(t, err, test.ShouldBeError, "no route to goal around keep out zones")
+	})
+}
+
+func TestZones(t *testing.T) {
+	ctx := context.Background()
+	start := geo.NewPoint(40, -74)
+	sr := &simulatedRover{loc: start, step: 100 * time.Millisecond}
+	svc := setupSimulatedNavigation(t, sr, &Config{
+		Store:              navigation.StoreConfig{Type: navigation.StoreTypeMemory},
+		BaseName:           "base",
+		MovementSensorName: "ms",
+	})
+	defer func() {
+		test.That(t, svc.Close(ctx), test.ShouldBeNil)
+	}()
+
+	test.That(t, svc.AddZone(ctx, navigation.ZoneTypeGeofence, squareAround(start, .1), nil), test.ShouldBeNil)
+	err := svc.AddZone(ctx, navigation.ZoneTypeGeofence, squareAround(start, .2), nil)
+	test.That(t, err, test.ShouldNotBeNil)
+	test.That(t, err.Error(), test.ShouldContainSubstring, "already exists")
+	test.That(t, svc.AddZone(ctx, navigation.ZoneTypeKeepOut, squareAround(start.PointAtDistanceAndBearing(.05, 0), .01), nil),
+		test.ShouldBeNil)
+
+	zones, err := svc.Zones(ctx, nil)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, zones, test.ShouldHaveLength, 2)
+
+	test.That(t, svc.AddWaypoint(ctx, start.PointAtDistanceAndBearing(.05, 90), nil), test.ShouldBeNil)
+	err = svc.AddWaypoint(ctx, start.PointAtDistanceAndBearing(.2, 90), nil)
+	test.That(t, err, test.ShouldBeError, "cannot add waypoint: location is outside of the geofence")
+	err = svc.AddWaypoint(ctx, start.PointAtDistanceAndBearing(.05, 0), nil)
+	test.That(t, err, test.ShouldNotBeNil)
+	test.That(t, err.Error(), test.ShouldContainSubstring, "inside of keep out zone")
+
+	_, err = svc.nextGoal(ctx, start.PointAtDistanceAndBearing(.2, 90), start)
+	test.That(t, err, test.ShouldBeError, errOutsideGeofence)
+
+	test.That(t, svc.RemoveZone(ctx, zones[0].ID, nil), test.ShouldBeNil)
+	zones, err = svc.Zones(ctx, nil)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, zones, test.ShouldHaveLength, 1)
+	test.That(t, zones[0].Type, test.ShouldEqual, navigation.ZoneTypeKeepOut)
+
+	// the waypoint .05km east rules out zones that would leave it unreachable
+	err = svc.AddZone(ctx, navigation.ZoneTypeGeofence, squareAround(start, .02), nil)
+	test.That(t, err, test.ShouldNotBeNil)
+	test.That(t, err.Error(), test.ShouldContainSubstring, "is outside of it")
+	err = svc.AddZone(ctx, navigation.ZoneTypeKeepOut, squareAround(start.PointAtDistanceAndBearing(.05, 90), .01), nil)
+	test.That(t, err, test.ShouldNotBeNil)
+	test.That(t, err.Error(), test.ShouldContainSubstring, "is inside of it")
+	zones, err = svc.Zones(ctx, nil)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, zones, test.ShouldHaveLength, 1)
+}
+
+func TestZonesConcurrentWithWaypoints(t *testing.T) {
+	ctx := context.Background()
+	start := geo.NewPoint(40, -74)
+	sr := &simulatedRover{loc: start, step: 100 * time.Millisecond}
+	svc := setupSimulatedNavigation(t, sr, &Config{
+		Store:              navigation.StoreConfig{Type: navigation.StoreTypeMemory},
+		BaseName:           "base",
+		MovementSensorName: "ms",
+	})
+	defer func() {
+		test.That(t, svc.Close(ctx), test.ShouldBeNil)
+	}()
+
+	// a geofence and a waypoint outside of it are added at the same time, only one of them can win
+	for i := 0; i < 20; i++ {
+		var wg sync.WaitGroup
+		var fenceErr, wpErr error
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			fenceErr = svc.AddZone(ctx, navigation.ZoneTypeGeofence, squareAround(start, .1), nil)
+		}()
+		go func() {
+			defer wg.Done()
+			wpErr = svc.AddWaypoint(ctx, start.PointAtDistanceAndBearing(.2, 90), nil)
+		}()
+		wg.Wait()
+		test.That(t, fenceErr == nil && wpErr == nil, test.ShouldBeFalse)
+		test.That(t, fenceErr == nil || wpErr == nil, test.ShouldBeTrue)
+
+		zones, err := svc.Zones(ctx, nil)
+		test.That(t, err, test.ShouldBeNil)
+		for _, zone := range zones {
+			test.That(t, svc.RemoveZone(ctx, zone.ID, nil), test.ShouldBeNil)
+		}
+		wps, err := svc.Waypoints(ctx, nil)
+		test.That(t, err, test.ShouldBeNil)
+		for _, wp := range wps {
+			test.That(t, svc.RemoveWaypoint(ctx, wp.ID, nil), test.ShouldBeNil)
+		}
+	}
+}
+
+func TestPurePursuitAroundKeepOut(t *testing.T) {
+	ctx := context.Background()
+	start := geo.NewPoint(40, -74)
+	sr := &simulatedRover{loc: start, step: 100 * time.Millisecond}
+	svc := setupSimulatedNavigation(t, sr, &Config{
+		Store:              navigation.StoreConfig{Type: navigation.StoreTypeMemory},
+		BaseName:           "base",
+		MovementSensorName: "ms",
+		Steering:           steeringPurePursuit,
+		MMPerSecDefault:    1000,
+		DegPerSecDefault:   90,
+	})
+	defer func() {
+		test.That(t, svc.Close(ctx), test.ShouldBeNil)
+	}()
+
+	keepOut := squareAround(start.PointAtDistanceAndBearing(.03, 0), .01)
+	test.That(t, svc.AddZone(ctx, navigation.ZoneTypeKeepOut, keepOut, nil), test.ShouldBeNil)
+	zone, err := navigation.NewZone(navigation.ZoneTypeKeepOut, keepOut)
+	test.That(t, err, test.ShouldBeNil)
+
+	var mu sync.Mutex
+	entered := false
+	injectMS := svc.movementSensor.(*inject.MovementSensor)
+	injectMS.PositionFunc = func(ctx context.Context, extra map[string]interface{}) (*geo.Point, float64, error) {
+		loc := sr.location()
+		mu.Lock()
+		defer mu.Unlock()
+		entered = entered || zone.Contains(loc)
+		return loc, 0, nil
+	}
+
+	wp := start.PointAtDistanceAndBearing(.06, 0)
+	test.That(t, svc.AddWaypoint(ctx, wp, nil), test.ShouldBeNil)
+	test.That(t, svc.SetMode(ctx, navigation.ModeWaypoint, nil), test.ShouldBeNil)
+
+	testutils.WaitForAssertionWithSleep(t, 10*time.Millisecond, 1000, func(tb testing.TB) {
+		tb.Helper()
+		wps, err := svc.Waypoints(ctx, nil)
+		test.That(tb, err, test.ShouldBeNil)
+		test.That(tb, wps, test.ShouldBeEmpty)
+	})
+	mu.Lock()
+	defer mu.Unlock()
+	test.That(t, entered, test.ShouldBeFalse)
+}
+
+func TestActionResourceFromDependencies(t *testing.T) {
+	sprayer := &inject.Generic{}
+	deps := registry.Dependencies{
+		generic.Named("sprayer"): sprayer,
+		base.Named("base"):       "not a resource",
+	}
+	res, err := actionResourceFromDependencies(deps, "sprayer")
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, res, test.ShouldEqual, sprayer)
+
+	_, err = actionResourceFromDependencies(deps, "base")
+	test.That(t, err, test.ShouldNotBeNil)
+	_, err = actionResourceFromDependencies(deps, "missing")
+	test.That(t, err, test.ShouldBeError, rdkutils.DependencyNotFoundError("missing"))
+}
+
+func TestWaypointArrival(t *testing.T) {
+	ctx := context.Background()
+	start := geo.NewPoint(40, -74)
+	sr := &simulatedRover{loc: start, step: 100 * time.Millisecond}
+	svc := setupSimulatedNavigation(t, sr, &Config{
+		Store:              navigation.StoreConfig{Type: navigation.StoreTypeMemory},
+		BaseName:           "base",
+		MovementSensorName: "ms",
+		Steering:           steeringPurePursuit,
+		MMPerSecDefault:    1000,
+		DegPerSecDefault:   90,
+	})
+	defer func() {
+		test.That(t, svc.Close(ctx), test.ShouldBeNil)
+	}()
+
+	var mu sync.Mutex
+	var commands []map[string]interface{}
+	svc.actionResources = map[string]generic.Generic{"sprayer": &inject.Generic{
+		DoFunc: func(ctx context.Context, cmd map[string]interface{}) (map[string]interface{}, error) {
+			mu.Lock()
+			defer mu.Unlock()
+			commands = append(commands, cmd)
+			return nil, nil
+		},
+	}}
+
+	heading := 90.
+	opts := navigation.WaypointOptions{
+		ArrivalRadiusMeters: 1,
+		MMPerSec:            500,
+		DwellSecs:           .1,
+		Heading:             &heading,
+		Actions:             []navigation.WaypointAction{{Resource: "sprayer", Command: map[string]interface{}{"spray": true}}},
+	}
+	wp := start.PointAtDistanceAndBearing(.01, 0)
+	test.That(t, svc.AddWaypoint(ctx, wp, opts.Extra()), test.ShouldBeNil)
+
+	badOpts := navigation.WaypointOptions{Actions: []navigation.WaypointAction{{Resource: "unknown"}}}
+	err := svc.AddWaypoint(ctx, wp, badOpts.Extra())
+	test.That(t, err, test.ShouldNotBeNil)
+	test.That(t, err.Error(), test.ShouldContainSubstring, "not one of the configured action_resources")
+
+	test.That(t, svc.SetMode(ctx, navigation.ModeWaypoint, nil), test.ShouldBeNil)
+	testutils.WaitForAssertionWithSleep(t, 10*time.Millisecond, 1000, func(tb testing.TB) {
+		tb.Helper()
+		wps, err := svc.Waypoints(ctx, nil)
+		test.That(tb, err, test.ShouldBeNil)
+		test.That(tb, wps, test.ShouldBeEmpty)
+	})
+
+	test.That(t, sr.location().GreatCircleDistance(wp), test.ShouldBeLessThan, .001)
+	sr.mu.Lock()
+	test.That(t, sr.spins, test.ShouldEqual, 1)
+	test.That(t, sr.heading, test.ShouldAlmostEqual, heading)
+	sr.mu.Unlock()
+	mu.Lock()
+	defer mu.Unlock()
+	test.That(t, commands, test.ShouldResemble, []map[string]interface{}{{"spray": true}})
+}
+
+func TestCoveragePath(t *testing.T) {
+	center := geo.NewPoint(40, -74)
+	// a 40m x 40m square field
+	field := squareAround(center, .02)
+
+	t.Run("invalid", func(t *testing.T) {
+		_, err := coveragePath(navigation.CoverageArea{Boundary: field[:2], SwathWidthMeters: 1})
+		test.That(t, err, test.ShouldNotBeNil)
+		_, err = coveragePath(navigation.CoverageArea{Boundary: field})
+		test.That(t, err, test.ShouldBeError, "swath width must be positive")
+		_, err = coveragePath(navigation.CoverageArea{Boundary: field, SwathWidthMeters: 100})
+		test.That(t, err, test.ShouldBeError, "coverage area is too small for the swath width and headland")
+	})
+
+	t.Run("square", func(t *testing.T) {
+		path, err := coveragePath(navigation.CoverageArea{Boundary: field, SwathWidthMeters: 10, HeadingDegs: 90})
+		test.That(t, err, test.ShouldBeNil)
+		// 4 east-west passes with 2 waypoints each
+		test.That(t, path, test.ShouldHaveLength, 8)
+		fence := navigation.Zone{Vertices: navigation.VerticesOf(field)}
+		for i := 0; i < len(path); i += 2 {
+			test.That(t, fence.Contains(path[i]), test.ShouldBeTrue)
+			// each pass is 40m minus a 5m headland on each end
+			test.That(t, path[i].GreatCircleDistance(path[i+1]), test.ShouldAlmostEqual, .03, 1e-4)
+			bearing := path[i].BearingTo(path[i+1])
+			if i%4 == 0 {
+				test.That(t, bearing, test.ShouldAlmostEqual, 90, .1)
+			} else {
+				test.That(t, fixAngle(bearing), test.ShouldAlmostEqual, 270, .1)
+			}
+			if i > 0 {
+				// turns between passes are a swath width apart
+				test.That(t, path[i-1].GreatCircleDistance(path[i]), test.ShouldAlmostEqual, .01, 1e-4)
+			}
+		}
+	})
+
+	t.Run("with a hole", func(t *testing.T) {
+		hole := squareAround(center, .005)
+		path, err := coveragePath(navigation.CoverageArea{
+			Boundary:         field,
+			Holes:            [][]*geo.Point{hole},
+			SwathWidthMeters: 4,
+			HeadlandMeters:   1,
+		})
+		test.That(t, err, test.ShouldBeNil)
+		holeZone := navigation.Zone{Vertices: navigation.VerticesOf(hole)}
+		for i, p := range path {
+			test.That(t, holeZone.Contains(p), test.ShouldBeFalse)
+			if i == 0 {
+				continue
+			}
+			// no leg of the path may cross the hole
+			for f := 0.; f <= 1; f += .05 {
+				leg := path[i-1].GreatCircleDistance(p)
+				sample := path[i-1].PointAtDistanceAndBearing(leg*f, path[i-1].BearingTo(p))
+				test.That(t, holeZone.Contains(sample), test.ShouldBeFalse)
+			}
+		}
+	})
+}
+
+func TestCoverageProgress(t *testing.T) {
+	ctx := context.Background()
+	center := geo.NewPoint(40, -74)
+	svc := setupSimulatedNavigation(t, &simulatedRover{loc: center}, &Config{
+		Store:              navigation.StoreConfig{Type: navigation.StoreTypeMemory},
+		BaseName:           "base",
+		MovementSensorName: "ms",
+	})
+	defer func() {
+		test.That(t, svc.Close(ctx), test.ShouldBeNil)
+	}()
+
+	_, err := svc.CoverageProgress(ctx, nil)
+	test.That(t, err, test.ShouldBeError, "no coverage pattern has been added")
+
+	wps, err := svc.AddCoverage(ctx, navigation.CoverageArea{
+		Boundary:         squareAround(center, .02),
+		SwathWidthMeters: 10,
+	}, map[string]interface{}{"mm_per_sec": 200.})
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, wps, test.ShouldHaveLength, 8)
+	test.That(t, wps[0].MMPerSec, test.ShouldEqual, 200)
+
+	stored, err := svc.Waypoints(ctx, nil)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, stored, test.ShouldResemble, wps)
+
+	progress, err := svc.CoverageProgress(ctx, nil)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, progress.TotalWaypoints, test.ShouldEqual, 8)
+	test.That(t, progress.VisitedWaypoints, test.ShouldEqual, 0)
+	test.That(t, progress.TotalMeters, test.ShouldAlmostEqual, 4*30+3*10, .1)
+	test.That(t, progress.CoveredMeters, test.ShouldEqual, 0)
+
+	test.That(t, svc.store.WaypointVisited(ctx, wps[0].ID), test.ShouldBeNil)
+	test.That(t, svc.store.WaypointVisited(ctx, wps[1].ID), test.ShouldBeNil)
+	progress, err = svc.CoverageProgress(ctx, nil)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, progress.VisitedWaypoints, test.ShouldEqual, 2)
+	test.That(t, progress.CoveredMeters, test.ShouldAlmostEqual, 30, .1)
+
+	// a waypoint removed before it is reached is not covered
+	test.That(t, svc.RemoveWaypoint(ctx, wps[2].ID, nil), test.ShouldBeNil)
+	progress, err = svc.CoverageProgress(ctx, nil)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, progress.TotalWaypoints, test.ShouldEqual, 8)
+	test.That(t, progress.VisitedWaypoints, test.ShouldEqual, 2)
+	test.That(t, progress.RemovedWaypoints, test.ShouldEqual, 1)
+	test.That(t, progress.CoveredMeters, test.ShouldAlmostEqual, 30, .1)
+}
+
+func TestCoverageProgressPersists(t *testing.T) {
+	ctx := context.Background()
+	center := geo.NewPoint(40, -74)
+	conf := &Config{
+		Store: navigation.StoreConfig{
+			Type:   navigation.StoreTypeBoltDB,
+			Config: map[string]interface{}{"path": filepath.Join(t.TempDir(), "navigation.db")},
+		},
+		BaseName:           "base",
+		MovementSensorName: "ms",
+	}
+	svc := setupSimulatedNavigation(t, &simulatedRover{loc: center}, conf)
+	wps, err := svc.AddCoverage(ctx, navigation.CoverageArea{
+		Boundary:         squareAround(center, .02),
+		SwathWidthMeters: 10,
+	}, nil)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, svc.store.WaypointVisited(ctx, wps[0].ID), test.ShouldBeNil)
+	test.That(t, svc.Close(ctx), test.ShouldBeNil)
+
+	svc = setupSimulatedNavigation(t, &simulatedRover{loc: center}, conf)
+	defer func() {
+		test.That(t, svc.Close(ctx), test.ShouldBeNil)
+	}()
+	progress, err := svc.CoverageProgress(ctx, nil)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, progress.TotalWaypoints, test.ShouldEqual, 8)
+	test.That(t, progress.VisitedWaypoints, test.ShouldEqual, 1)
+}
+
+// failingStore fails to add waypoints once it has added a number of them.
+type failingStore struct {
+	navigation.NavStore
+	addsLeft int
+}
+
+func (store *failingStore) AddWaypoint(
+	ctx context.Context,
+	point *geo.Point,
+	opts navigation.WaypointOptions,
+) (navigation.Waypoint, error) {
+	if store.addsLeft == 0 {
+		return navigation.Waypoint{}, errors.New("store is full")
+	}
+	store.addsLeft--
+	return store.NavStore.AddWaypoint(ctx, point, opts)
+}
+
+func TestAddCoverage(t *testing.T) {
+	ctx := context.Background()
+	center := geo.NewPoint(40, -74)
+	svc := setupSimulatedNavigation(t, &simulatedRover{loc: center}, &Config{
+		Store:              navigation.StoreConfig{Type: navigation.StoreTypeMemory},
+		BaseName:           "base",
+		MovementSensorName: "ms",
+	})
+	defer func() {
+		test.That(t, svc.Close(ctx), test.ShouldBeNil)
+	}()
+	field := squareAround(center, .02)
+
+	t.Run("keep out zones do not change the holes of the caller", func(t *testing.T) {
+		test.That(t, svc.AddZone(ctx, navigation.ZoneTypeKeepOut, squareAround(center, .003), nil), test.ShouldBeNil)
+		holes := make([][]*geo.Point, 0, 2)
+		area := navigation.CoverageArea{Boundary: field, Holes: holes, SwathWidthMeters: 10}
+		wps, err := svc.AddCoverage(ctx, area, nil)
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, area.Holes, test.ShouldBeEmpty)
+		test.That(t, holes[:1][0], test.ShouldBeNil)
+		for _, wp := range wps {
+			test.That(t, svc.RemoveWaypoint(ctx, wp.ID, nil), test.ShouldBeNil)
+		}
+	})
+
+	t.Run("waypoints are rolled back when one cannot be added", func(t *testing.T) {
+		memory := svc.store
+		svc.store = &failingStore{NavStore: memory, addsLeft: 3}
+		defer func() {
+			svc.store = memory
+		}()
+		_, err := svc.AddCoverage(ctx, navigation.CoverageArea{Boundary: field, SwathWidthMeters: 10}, nil)
+		test.That(t, err, test.ShouldNotBeNil)
+		test.That(t, err.Error(), test.ShouldContainSubstring, "store is full")
+		wps, err := svc.Waypoints(ctx, nil)
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, wps, test.ShouldBeEmpty)
+	})
+}
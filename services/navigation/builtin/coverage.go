@@ -0,0 +1,240 @@
+package builtin
+
+import (
+	"context"
+	"math"
+	"sort"
+
+	"github.com/golang/geo/r2"
+	geo "github.com/kellydunn/golang-geo"
+	"github.com/pkg/errors"
+	"go.uber.org/multierr"
+
+	"go.viam.com/rdk/services/navigation"
+	rdkutils "go.viam.com/rdk/utils"
+)
+
+// AddCoverage generates a boustrophedon pattern of waypoints covering the area and adds them after any
+// existing waypoints. The keep out zones of the service are treated as additional holes in the area.
+// Any WaypointOptions in extra are applied to every generated waypoint. The pattern is recorded in the
+// store so that progress along it survives restarts. If any waypoint cannot be added, the ones that were
+// are removed again.
+func (svc *builtIn) AddCoverage(
+	ctx context.Context,
+	area navigation.CoverageArea,
+	extra map[string]interface{},
+) (_ []navigation.Waypoint, err error) {
+	opts, err := navigation.WaypointOptionsFromExtra(extra)
+	if err != nil {
+		return nil, err
+	}
+	svc.zonesMu.Lock()
+	defer svc.zonesMu.Unlock()
+	zs, err := svc.loadZones(ctx)
+	if err != nil {
+		return nil, err
+	}
+	// copy the holes so that the keep out zones are not appended to the array of the caller
+	holes := make([][]*geo.Point, 0, len(area.Holes)+len(zs.keepOuts))
+	holes = append(holes, area.Holes...)
+	for _, zone := range zs.keepOuts {
+		holes = append(holes, zone.Points())
+	}
+	area.Holes = holes
+	points, err := coveragePath(area)
+	if err != nil {
+		return nil, err
+	}
+	for i, p := range points {
+		if err := zs.checkLocation(p); err != nil {
+			return nil, errors.Wrapf(err, "coverage waypoint %d", i)
+		}
+	}
+
+	var coverage navigation.Coverage
+	wps := make([]navigation.Waypoint, 0, len(points))
+	defer func() {
+		if err == nil {
+			return
+		}
+		for _, wp := range wps {
+			err = multierr.Combine(err, svc.store.RemoveWaypoint(ctx, wp.ID))
+		}
+	}()
+	for i, p := range points {
+		wp, err := svc.store.AddWaypoint(ctx, p, opts)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to add coverage waypoint %d", i)
+		}
+		wps = append(wps, wp)
+		coverage.WaypointIDs = append(coverage.WaypointIDs, wp.ID)
+		leg := 0.
+		if i > 0 {
+			leg = points[i-1].GreatCircleDistance(p) * 1000
+		}
+		coverage.LegMeters = append(coverage.LegMeters, leg)
+	}
+	if err := svc.store.SetCoverage(ctx, coverage); err != nil {
+		return nil, err
+	}
+	return wps, nil
+}
+
+// CoverageProgress reports how much of the most recently added coverage pattern has been driven.
+func (svc *builtIn) CoverageProgress(ctx context.Context, extra map[string]interface{}) (navigation.CoverageProgress, error) {
+	coverage, err := svc.store.Coverage(ctx)
+	if err != nil {
+		return navigation.CoverageProgress{}, err
+	}
+	if coverage == nil {
+		return navigation.CoverageProgress{}, errors.New("no coverage pattern has been added")
+	}
+
+	progress := navigation.CoverageProgress{TotalWaypoints: len(coverage.WaypointIDs)}
+	for i, id := range coverage.WaypointIDs {
+		progress.TotalMeters += coverage.LegMeters[i]
+		wp, err := svc.store.Waypoint(ctx, id)
+		if errors.Is(err, navigation.ErrWaypointNotFound) {
+			progress.RemovedWaypoints++
+			continue
+		}
+		if err != nil {
+			return navigation.CoverageProgress{}, err
+		}
+		if !wp.Visited {
+			continue
+		}
+		progress.VisitedWaypoints++
+		progress.CoveredMeters += coverage.LegMeters[i]
+	}
+	return progress, nil
+}
+
+// coveragePath returns the waypoints of a boustrophedon pattern over the area. Passes are laid out one
+// swath width apart along the heading of the area and are trimmed back from the boundary and holes by the
+// headland distance. Passes are joined in order of whichever unvisited pass end is closest, which gives
+// the usual back and forth pattern, and joins that would cross a hole are routed around it. Holes must not
+// overlap each other or the boundary.
+func coveragePath(area navigation.CoverageArea) ([]*geo.Point, error) {
+	if len(area.Boundary) < 3 {
+		return nil, errors.Errorf("coverage boundary needs at least 3 vertices but got %d", len(area.Boundary))
+	}
+	if area.SwathWidthMeters <= 0 {
+		return nil, errors.New("swath width must be positive")
+	}
+	if area.HeadlandMeters < 0 {
+		return nil, errors.New("headland cannot be negative")
+	}
+	headland := area.HeadlandMeters
+	if headland == 0 {
+		headland = area.SwathWidthMeters / 2
+	}
+
+	// work in a frame where passes run along the x axis
+	lf := newLocalFrame(area.Boundary[0])
+	passAngle := math.Atan2(
+		math.Cos(rdkutils.DegToRad(area.HeadingDegs)),
+		math.Sin(rdkutils.DegToRad(area.HeadingDegs)),
+	)
+	toPass := func(p *geo.Point) r2.Point {
+		return rotate(lf.toLocal(p), -passAngle)
+	}
+	fromPass := func(p r2.Point) *geo.Point {
+		return lf.toGeo(rotate(p, passAngle))
+	}
+
+	polygons := make([][]r2.Point, 0, len(area.Holes)+1)
+	for _, poly := range append([][]*geo.Point{area.Boundary}, area.Holes...) {
+		local := make([]r2.Point, 0, len(poly))
+		for _, p := range poly {
+			local = append(local, toPass(p))
+		}
+		polygons = append(polygons, local)
+	}
+
+	minY, maxY := math.Inf(1), math.Inf(-1)
+	for _, p := range polygons[0] {
+		minY = math.Min(minY, p.Y)
+		maxY = math.Max(maxY, p.Y)
+	}
+
+	var passes [][2]r2.Point
+	for y := minY + area.SwathWidthMeters/2; y < maxY; y += area.SwathWidthMeters {
+		xs := scanlineCrossings(polygons, y)
+		for i := 0; i+1 < len(xs); i += 2 {
+			start, end := xs[i]+headland, xs[i+1]-headland
+			if end <= start {
+				continue
+			}
+			passes = append(passes, [2]r2.Point{{X: start, Y: y}, {X: end, Y: y}})
+		}
+	}
+	if len(passes) == 0 {
+		return nil, errors.New("coverage area is too small for the swath width and headland")
+	}
+
+	routeZones := &zoneSet{fence: &navigation.Zone{}}
+	routeZones.fence.Vertices = navigation.VerticesOf(area.Boundary)
+	for _, hole := range area.Holes {
+		keepOut := navigation.Zone{Type: navigation.ZoneTypeKeepOut, Vertices: navigation.VerticesOf(hole)}
+		routeZones.keepOuts = append(routeZones.keepOuts, keepOut)
+	}
+
+	var path []*geo.Point
+	current := passes[0][0]
+	done := make([]bool, len(passes))
+	for range passes {
+		best, bestReversed, bestDist := -1, false, math.Inf(1)
+		for i, pass := range passes {
+			if done[i] {
+				continue
+			}
+			if d := current.Sub(pass[0]).Norm(); d < bestDist {
+				best, bestReversed, bestDist = i, false, d
+			}
+			if d := current.Sub(pass[1]).Norm(); d < bestDist {
+				best, bestReversed, bestDist = i, true, d
+			}
+		}
+		done[best] = true
+		start, end := passes[best][0], passes[best][1]
+		if bestReversed {
+			start, end = end, start
+		}
+
+		startGeo := fromPass(start)
+		if len(path) > 0 {
+			route, err := planRoute(path[len(path)-1], startGeo, routeZones)
+			if err != nil {
+				return nil, errors.Wrap(err, "cannot join coverage passes")
+			}
+			path = append(path, route[1:len(route)-1]...)
+		}
+		path = append(path, startGeo, fromPass(end))
+		current = end
+	}
+	return path, nil
+}
+
+// scanlineCrossings returns the sorted x coordinates where the horizontal line at y crosses the edges of
+// the polygons. Consecutive pairs of crossings bound the parts of the line inside the first polygon and
+// outside of the rest.
+func scanlineCrossings(polygons [][]r2.Point, y float64) []float64 {
+	var xs []float64
+	for _, poly := range polygons {
+		for i, j := 0, len(poly)-1; i < len(poly); j, i = i, i+1 {
+			a, b := poly[j], poly[i]
+			if (a.Y > y) == (b.Y > y) {
+				continue
+			}
+			xs = append(xs, a.X+(y-a.Y)*(b.X-a.X)/(b.Y-a.Y))
+		}
+	}
+	sort.Float64s(xs)
+	return xs
+}
+
+func rotate(p r2.Point, angle float64) r2.Point {
+	sin, cos := math.Sincos(angle)
+	return r2.Point{X: p.X*cos - p.Y*sin, Y: p.X*sin + p.Y*cos}
+}
@@ -0,0 +1,194 @@
+package builtin
+
+import (
+	"context"
+	"math"
+	"time"
+
+	"github.com/golang/geo/r3"
+	geo "github.com/kellydunn/golang-geo"
+	"github.com/pkg/errors"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.viam.com/utils"
+
+	rdkutils "go.viam.com/rdk/utils"
+)
+
+const (
+	lookaheadMetersDefault = 3.
+	purePursuitInterval    = 100 * time.Millisecond
+)
+
+// pursuitState is the state carried between iterations of the pure pursuit loop.
+type pursuitState struct {
+	// segmentStart is where the path to the current waypoint begins. It is either the previously
+	// visited waypoint or the location of the base when it started heading to the current waypoint.
+	segmentStart *geo.Point
+	waypointID   primitive.ObjectID
+	// reached is the waypoint the base last arrived at, which the path to the next waypoint starts from.
+	reached *geo.Point
+	// lastFix and prevFix are used to infer a heading when the movement sensor cannot report one.
+	lastFix *geo.Point
+	prevFix *geo.Point
+}
+
+func (svc *builtIn) startPurePursuit(extra map[string]interface{}) error {
+	svc.activeBackgroundWorkers.Add(1)
+	utils.PanicCapturingGo(func() {
+		defer svc.activeBackgroundWorkers.Done()
+		defer func() {
+			// the loop context is gone by now, so stop with a fresh one
+			if err := svc.base.Stop(context.Background(), nil); err != nil {
+				svc.logger.Errorw("failed to stop base", "error", err)
+			}
+		}()
+
+		state := &pursuitState{}
+		for {
+			if !utils.SelectContextOrWait(svc.cancelCtx, svc.pursuitInterval) {
+				return
+			}
+			if err := svc.pursueOnce(svc.cancelCtx, state, extra); err != nil {
+				if svc.cancelCtx.Err() != nil {
+					return
+				}
+				svc.logger.Infof("error navigating: %s", err)
+				if err := svc.base.Stop(svc.cancelCtx, nil); err != nil {
+					svc.logger.Errorw("failed to stop base", "error", err)
+				}
+			}
+		}
+	})
+	return nil
+}
+
+// pursueOnce runs a single iteration of pure pursuit: it picks a lookahead point on the great circle
+// path to the next waypoint and sets the base velocity to follow the arc through that point.
+func (svc *builtIn) pursueOnce(ctx context.Context, state *pursuitState, extra map[string]interface{}) error {
+	currentLoc, _, err := svc.movementSensor.Position(ctx, extra)
+	if err != nil {
+		return errors.Wrap(err, "failed to get gps location")
+	}
+	if state.lastFix == nil || currentLoc.GreatCircleDistance(state.lastFix) > .0001 {
+		state.prevFix, state.lastFix = state.lastFix, currentLoc
+	}
+
+	wp, err := svc.nextWaypoint(ctx)
+	if err != nil {
+		return err
+	}
+	goal := wp.ToPoint()
+	if state.segmentStart == nil || state.waypointID != wp.ID {
+		state.segmentStart = currentLoc
+		if state.reached != nil {
+			state.segmentStart = state.reached
+		}
+		state.waypointID = wp.ID
+	}
+	state.reached = nil
+
+	if currentLoc.GreatCircleDistance(goal) < arrivalRadiusKm(wp) {
+		svc.logger.Debug("i made it")
+		// the path to the next waypoint starts from this one
+		state.reached = goal
+		return svc.arrive(ctx, wp, func(ctx context.Context) (float64, error) {
+			return svc.heading(ctx, state, extra)
+		})
+	}
+
+	// follow the route around any keep out zones, taking a direct path to each point along it
+	segmentStart := state.segmentStart
+	nextGoal, err := svc.nextGoal(ctx, currentLoc, goal)
+	if err != nil {
+		return err
+	}
+	if nextGoal != goal {
+		segmentStart = currentLoc
+	}
+
+	heading, err := svc.heading(ctx, state, extra)
+	if err != nil {
+		return err
+	}
+
+	target := lookaheadPoint(segmentStart, nextGoal, currentLoc, svc.lookaheadMeters/1000)
+	linear, angular := purePursuitVelocity(
+		currentLoc, heading, target, svc.mmPerSec(wp), svc.degPerSecDefault,
+	)
+
+	svc.logger.Debugf("heading: %0.1f target: (%f, %f) linear: %0.1f angular: %0.1f",
+		heading, target.Lat(), target.Lng(), linear, angular)
+
+	return svc.base.SetVelocity(ctx, r3.Vector{Y: linear}, r3.Vector{Z: angular}, nil)
+}
+
+// heading returns the compass heading of the base in degrees. It prefers the compass heading of the
+// movement sensor, then its orientation, and finally falls back to the bearing between the last two
+// gps fixes.
+func (svc *builtIn) heading(ctx context.Context, state *pursuitState, extra map[string]interface{}) (float64, error) {
+	heading, err := svc.movementSensor.CompassHeading(ctx, extra)
+	if err == nil {
+		return fixAngle(heading), nil
+	}
+	orientation, err := svc.movementSensor.Orientation(ctx, extra)
+	if err == nil && orientation != nil {
+		// yaw is counterclockwise from north while compass headings are clockwise
+		return fixAngle(-rdkutils.RadToDeg(orientation.EulerAngles().Yaw)), nil
+	}
+	if state.prevFix == nil {
+		return 0, errors.New("not enough gps data to determine heading")
+	}
+	return fixAngle(state.prevFix.BearingTo(state.lastFix)), nil
+}
+
+// lookaheadPoint returns the point on the great circle from start to goal that is lookaheadKm further
+// along the path than the projection of current onto it. If that would overshoot the goal, the goal is
+// returned.
+func lookaheadPoint(start, goal, current *geo.Point, lookaheadKm float64) *geo.Point {
+	pathKm := start.GreatCircleDistance(goal)
+	if pathKm == 0 {
+		return goal
+	}
+
+	// cross track and along track distances as angular distances on the sphere
+	d13 := start.GreatCircleDistance(current) / geo.EARTH_RADIUS
+	theta13 := rdkutils.DegToRad(start.BearingTo(current))
+	theta12 := rdkutils.DegToRad(start.BearingTo(goal))
+	crossTrack := math.Asin(math.Sin(d13) * math.Sin(theta13-theta12))
+	alongTrackKm := math.Acos(math.Cos(d13)/math.Cos(crossTrack)) * geo.EARTH_RADIUS
+	if math.Cos(theta13-theta12) < 0 {
+		// the base is behind the start of the path
+		alongTrackKm = -alongTrackKm
+	}
+
+	targetKm := math.Max(alongTrackKm, 0) + lookaheadKm
+	if math.IsNaN(targetKm) || targetKm >= pathKm {
+		return goal
+	}
+	return start.PointAtDistanceAndBearing(targetKm, start.BearingTo(goal))
+}
+
+// purePursuitVelocity returns the linear (mm/s) and angular (deg/s, counterclockwise positive) velocities
+// that drive a base at current with the given compass heading along the arc through target.
+func purePursuitVelocity(current *geo.Point, heading float64, target *geo.Point, mmPerSec, degsPerSec float64) (float64, float64) {
+	// positive alpha means the target is to the right of the base
+	alpha := -computeBearing(current.BearingTo(target), heading)
+	if math.Abs(alpha) > 90 {
+		// the target is behind us, turn in place until it is in front
+		return 0, -math.Copysign(degsPerSec, alpha)
+	}
+
+	lookaheadMm := current.GreatCircleDistance(target) * 1000 * 1000
+	if lookaheadMm == 0 {
+		return 0, 0
+	}
+	curvature := 2 * math.Sin(rdkutils.DegToRad(alpha)) / lookaheadMm
+	angular := -rdkutils.RadToDeg(mmPerSec * curvature)
+	linear := mmPerSec
+	if math.Abs(angular) > degsPerSec {
+		// slow down so that the base can still follow the arc at its max turning rate
+		linear *= degsPerSec / math.Abs(angular)
+		angular = math.Copysign(degsPerSec, angular)
+	}
+	return linear, angular
+}
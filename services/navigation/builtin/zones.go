@@ -0,0 +1,353 @@
+package builtin
+
+import (
+	"container/heap"
+	"context"
+	"math"
+
+	"github.com/golang/geo/r2"
+	geo "github.com/kellydunn/golang-geo"
+	"github.com/pkg/errors"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"go.viam.com/rdk/services/navigation"
+	rdkutils "go.viam.com/rdk/utils"
+)
+
+// zoneMarginMeters is how far from the corners of zones routes are placed.
+const zoneMarginMeters = 1.
+
+var errOutsideGeofence = errors.New("location is outside of the geofence")
+
+func (svc *builtIn) Zones(ctx context.Context, extra map[string]interface{}) ([]navigation.Zone, error) {
+	return svc.store.Zones(ctx)
+}
+
+// AddZone adds a zone. Waypoints that have yet to be visited must all be inside of a new geofence and
+// outside of a new keep out zone, otherwise the zone is rejected.
+func (svc *builtIn) AddZone(
+	ctx context.Context,
+	zoneType navigation.ZoneType,
+	vertices []*geo.Point,
+	extra map[string]interface{},
+) error {
+	zone, err := navigation.NewZone(zoneType, vertices)
+	if err != nil {
+		return err
+	}
+
+	svc.zonesMu.Lock()
+	defer svc.zonesMu.Unlock()
+	if zoneType == navigation.ZoneTypeGeofence {
+		zs, err := svc.loadZones(ctx)
+		if err != nil {
+			return err
+		}
+		if zs.fence != nil {
+			return errors.Errorf("a geofence (%s) already exists, remove it first", zs.fence.ID.Hex())
+		}
+	}
+	wps, err := svc.store.Waypoints(ctx)
+	if err != nil {
+		return err
+	}
+	for _, wp := range wps {
+		inside := zone.Contains(geo.NewPoint(wp.Lat, wp.Long))
+		if zoneType == navigation.ZoneTypeGeofence && !inside {
+			return errors.Errorf("cannot add geofence, waypoint %s is outside of it", wp.ID.Hex())
+		}
+		if zoneType == navigation.ZoneTypeKeepOut && inside {
+			return errors.Errorf("cannot add keep out zone, waypoint %s is inside of it", wp.ID.Hex())
+		}
+	}
+	_, err = svc.store.AddZone(ctx, zoneType, vertices)
+	return err
+}
+
+func (svc *builtIn) RemoveZone(ctx context.Context, id primitive.ObjectID, extra map[string]interface{}) error {
+	svc.zonesMu.Lock()
+	defer svc.zonesMu.Unlock()
+	return svc.store.RemoveZone(ctx, id)
+}
+
+// zoneSet is the geofence and keep out zones of the navigation service at a point in time.
+type zoneSet struct {
+	fence    *navigation.Zone
+	keepOuts []navigation.Zone
+}
+
+func (svc *builtIn) loadZones(ctx context.Context) (*zoneSet, error) {
+	zones, err := svc.store.Zones(ctx)
+	if err != nil {
+		return nil, err
+	}
+	zs := &zoneSet{}
+	for i := range zones {
+		switch zones[i].Type {
+		case navigation.ZoneTypeGeofence:
+			zs.fence = &zones[i]
+		case navigation.ZoneTypeKeepOut:
+			zs.keepOuts = append(zs.keepOuts, zones[i])
+		}
+	}
+	return zs, nil
+}
+
+// checkLocation returns an error if the point is outside of the geofence or inside of a keep out zone.
+func (zs *zoneSet) checkLocation(point *geo.Point) error {
+	if zs.fence != nil && !zs.fence.Contains(point) {
+		return errOutsideGeofence
+	}
+	for _, zone := range zs.keepOuts {
+		if zone.Contains(point) {
+			return errors.Errorf("location is inside of keep out zone %s", zone.ID.Hex())
+		}
+	}
+	return nil
+}
+
+// nextGoal returns the point the base should head to next in order to get from current to goal without
+// entering a keep out zone or leaving the geofence.
+func (svc *builtIn) nextGoal(ctx context.Context, current, goal *geo.Point) (*geo.Point, error) {
+	zs, err := svc.loadZones(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if zs.fence != nil && !zs.fence.Contains(current) {
+		return nil, errOutsideGeofence
+	}
+	if zs.fence == nil && len(zs.keepOuts) == 0 {
+		return goal, nil
+	}
+	route, err := planRoute(current, goal, zs)
+	if err != nil {
+		return nil, err
+	}
+	return route[1], nil
+}
+
+// localFrame projects geo points onto a plane tangent to the earth at an origin. Distances are in meters,
+// x is east and y is north. This is accurate enough over the few kilometers a field robot covers.
+type localFrame struct {
+	origin       *geo.Point
+	metersPerLat float64
+	metersPerLng float64
+}
+
+func newLocalFrame(origin *geo.Point) *localFrame {
+	metersPerDeg := rdkutils.DegToRad(geo.EARTH_RADIUS * 1000)
+	return &localFrame{
+		origin:       origin,
+		metersPerLat: metersPerDeg,
+		metersPerLng: metersPerDeg * math.Cos(rdkutils.DegToRad(origin.Lat())),
+	}
+}
+
+func (lf *localFrame) toLocal(p *geo.Point) r2.Point {
+	return r2.Point{
+		X: (p.Lng() - lf.origin.Lng()) * lf.metersPerLng,
+		Y: (p.Lat() - lf.origin.Lat()) * lf.metersPerLat,
+	}
+}
+
+func (lf *localFrame) toGeo(p r2.Point) *geo.Point {
+	return geo.NewPoint(lf.origin.Lat()+p.Y/lf.metersPerLat, lf.origin.Lng()+p.X/lf.metersPerLng)
+}
+
+func (lf *localFrame) toLocalPolygon(zone *navigation.Zone) []r2.Point {
+	poly := make([]r2.Point, 0, len(zone.Vertices))
+	for _, p := range zone.Points() {
+		poly = append(poly, lf.toLocal(p))
+	}
+	return poly
+}
+
+// polygonContains uses ray casting to determine if p is within poly.
+func polygonContains(poly []r2.Point, p r2.Point) bool {
+	contains := false
+	for i, j := 0, len(poly)-1; i < len(poly); j, i = i, i+1 {
+		a, b := poly[i], poly[j]
+		if (a.Y > p.Y) != (b.Y > p.Y) && p.X < (b.X-a.X)*(p.Y-a.Y)/(b.Y-a.Y)+a.X {
+			contains = !contains
+		}
+	}
+	return contains
+}
+
+// segmentsCross returns whether segments ab and cd properly cross each other. Touching does not count.
+func segmentsCross(a, b, c, d r2.Point) bool {
+	d1 := b.Sub(a).Cross(c.Sub(a))
+	d2 := b.Sub(a).Cross(d.Sub(a))
+	d3 := d.Sub(c).Cross(a.Sub(c))
+	d4 := d.Sub(c).Cross(b.Sub(c))
+	return d1*d2 < 0 && d3*d4 < 0
+}
+
+func segmentCrossesPolygon(a, b r2.Point, poly []r2.Point) bool {
+	for i, j := 0, len(poly)-1; i < len(poly); j, i = i, i+1 {
+		if segmentsCross(a, b, poly[j], poly[i]) {
+			return true
+		}
+	}
+	return false
+}
+
+// offsetVertices returns points that are a margin away from each corner of poly, either on the outside
+// or the inside of it.
+func offsetVertices(poly []r2.Point, margin float64, inside bool) []r2.Point {
+	offsets := make([]r2.Point, 0, len(poly))
+	for i, v := range poly {
+		prev := poly[(i+len(poly)-1)%len(poly)]
+		next := poly[(i+1)%len(poly)]
+		dir := v.Sub(prev).Normalize().Add(v.Sub(next).Normalize())
+		if dir.Norm() < 1e-9 {
+			// straight edge, go perpendicular to it
+			dir = v.Sub(prev).Ortho()
+		}
+		dir = dir.Normalize().Mul(margin)
+		for _, candidate := range []r2.Point{v.Add(dir), v.Sub(dir)} {
+			if polygonContains(poly, candidate) == inside {
+				offsets = append(offsets, candidate)
+				break
+			}
+		}
+	}
+	return offsets
+}
+
+// planRoute finds the shortest path from start to goal that stays within the geofence and out of the
+// keep out zones by searching a visibility graph built from the corners of the zones. The returned route
+// begins with start and ends with goal. Keep out zones that start is already inside of are ignored so
+// that the base is able to drive out of them.
+func planRoute(start, goal *geo.Point, zs *zoneSet) ([]*geo.Point, error) {
+	lf := newLocalFrame(start)
+
+	var fence []r2.Point
+	if zs.fence != nil {
+		fence = lf.toLocalPolygon(zs.fence)
+	}
+	var keepOuts [][]r2.Point
+	startLocal := lf.toLocal(start)
+	for i := range zs.keepOuts {
+		poly := lf.toLocalPolygon(&zs.keepOuts[i])
+		if polygonContains(poly, startLocal) {
+			continue
+		}
+		keepOuts = append(keepOuts, poly)
+	}
+
+	inBounds := func(p r2.Point) bool {
+		if fence != nil && !polygonContains(fence, p) {
+			return false
+		}
+		for _, poly := range keepOuts {
+			if polygonContains(poly, p) {
+				return false
+			}
+		}
+		return true
+	}
+	visible := func(a, b r2.Point) bool {
+		mid := a.Add(b).Mul(.5)
+		if !inBounds(mid) {
+			return false
+		}
+		if fence != nil && segmentCrossesPolygon(a, b, fence) {
+			return false
+		}
+		for _, poly := range keepOuts {
+			if segmentCrossesPolygon(a, b, poly) {
+				return false
+			}
+		}
+		return true
+	}
+
+	goalLocal := lf.toLocal(goal)
+	if !inBounds(goalLocal) {
+		return nil, errors.New("goal is outside of the geofence or inside of a keep out zone")
+	}
+	nodes := []r2.Point{startLocal, goalLocal}
+	candidates := offsetVertices(fence, zoneMarginMeters, true)
+	for _, poly := range keepOuts {
+		candidates = append(candidates, offsetVertices(poly, zoneMarginMeters, false)...)
+	}
+	for _, c := range candidates {
+		if inBounds(c) {
+			nodes = append(nodes, c)
+		}
+	}
+
+	path := shortestVisiblePath(nodes, 0, 1, visible)
+	if path == nil {
+		return nil, errors.New("no route to goal around keep out zones")
+	}
+	route := make([]*geo.Point, 0, len(path))
+	route = append(route, start)
+	for _, idx := range path[1 : len(path)-1] {
+		route = append(route, lf.toGeo(nodes[idx]))
+	}
+	return append(route, goal), nil
+}
+
+// shortestVisiblePath runs Dijkstra's algorithm over the graph where every pair of mutually visible nodes
+// is connected. It returns the indices of the nodes on the path or nil if there is none.
+func shortestVisiblePath(nodes []r2.Point, from, to int, visible func(a, b r2.Point) bool) []int {
+	dist := make([]float64, len(nodes))
+	prev := make([]int, len(nodes))
+	for i := range dist {
+		dist[i] = math.Inf(1)
+		prev[i] = -1
+	}
+	dist[from] = 0
+	pq := &nodeQueue{{idx: from}}
+	for pq.Len() > 0 {
+		cur := heap.Pop(pq).(queuedNode)
+		if cur.dist > dist[cur.idx] {
+			continue
+		}
+		if cur.idx == to {
+			break
+		}
+		for next := range nodes {
+			if next == cur.idx || !visible(nodes[cur.idx], nodes[next]) {
+				continue
+			}
+			alt := dist[cur.idx] + nodes[cur.idx].Sub(nodes[next]).Norm()
+			if alt < dist[next] {
+				dist[next] = alt
+				prev[next] = cur.idx
+				heap.Push(pq, queuedNode{idx: next, dist: alt})
+			}
+		}
+	}
+	if math.IsInf(dist[to], 1) {
+		return nil
+	}
+	path := []int{}
+	for idx := to; idx != -1; idx = prev[idx] {
+		path = append([]int{idx}, path...)
+	}
+	return path
+}
+
+type queuedNode struct {
+	idx  int
+	dist float64
+}
+
+// nodeQueue is a min heap of nodes ordered by distance.
+type nodeQueue []queuedNode
+
+func (nq nodeQueue) Len() int            { return len(nq) }
+func (nq nodeQueue) Less(i, j int) bool  { return nq[i].dist < nq[j].dist }
+func (nq nodeQueue) Swap(i, j int)       { nq[i], nq[j] = nq[j], nq[i] }
+func (nq *nodeQueue) Push(x interface{}) { *nq = append(*nq, x.(queuedNode)) }
+
+func (nq *nodeQueue) Pop() interface{} {
+	old := *nq
+	n := len(old)
+	item := old[n-1]
+	*nq = old[:n-1]
+	return item
+}
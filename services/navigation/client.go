@@ -149,3 +149,38 @@ func (c *client) RemoveWaypoint(ctx context.Context, id primitive.ObjectID, extr
 	}
 	return nil
 }
+
+func (c *client) Zones(ctx context.Context, extra map[string]interface{}) ([]Zone, error) {
+	resp, err := c.doCommand(ctx, ZonesCommand, nil, extra)
+	if err != nil {
+		return nil, err
+	}
+	return zonesFromCommand(resp["zones"])
+}
+
+func (c *client) AddZone(ctx context.Context, zoneType ZoneType, vertices []*geo.Point, extra map[string]interface{}) error {
+	args := map[string]interface{}{"type": string(zoneType), "vertices": pointsToCommand(vertices)}
+	_, err := c.doCommand(ctx, AddZoneCommand, args, extra)
+	return err
+}
+
+func (c *client) RemoveZone(ctx context.Context, id primitive.ObjectID, extra map[string]interface{}) error {
+	_, err := c.doCommand(ctx, RemoveZoneCommand, map[string]interface{}{"id": id.Hex()}, extra)
+	return err
+}
+
+func (c *client) AddCoverage(ctx context.Context, area CoverageArea, extra map[string]interface{}) ([]Waypoint, error) {
+	resp, err := c.doCommand(ctx, AddCoverageCommand, map[string]interface{}{"area": coverageAreaToCommand(area)}, extra)
+	if err != nil {
+		return nil, err
+	}
+	return waypointsFromCommand(resp["waypoints"])
+}
+
+func (c *client) CoverageProgress(ctx context.Context, extra map[string]interface{}) (CoverageProgress, error) {
+	resp, err := c.doCommand(ctx, CoverageProgressCommand, nil, extra)
+	if err != nil {
+		return CoverageProgress{}, err
+	}
+	return coverageProgressFromCommand(resp), nil
+}
@@ -82,6 +82,66 @@ func TestClient(t *testing.T) {
 		return nil
 	}
 
+	var zones []navigation.Zone
+	workingNavigationService.ZonesFunc = func(ctx context.Context, extra map[string]interface{}) ([]navigation.Zone, error) {
+		extraOptions = extra
+		return zones, nil
+	}
+	workingNavigationService.AddZoneFunc = func(
+		ctx context.Context,
+		zoneType navigation.ZoneType,
+		vertices []*geo.Point,
+		extra map[string]interface{},
+	) error {
+		extraOptions = extra
+		zone, err := navigation.NewZone(zoneType, vertices)
+		if err != nil {
+			return err
+		}
+		zones = append(zones, zone)
+		return nil
+	}
+	workingNavigationService.RemoveZoneFunc = func(ctx context.Context, id primitive.ObjectID, extra map[string]interface{}) error {
+		extraOptions = extra
+		for i, zone := range zones {
+			if zone.ID == id {
+				zones = append(zones[:i], zones[i+1:]...)
+				return nil
+			}
+		}
+		return errors.New("no such zone")
+	}
+
+	var receivedArea navigation.CoverageArea
+	heading := 45.
+	coverageWaypoints := []navigation.Waypoint{
+		{ID: primitive.NewObjectID(), Lat: 40, Long: 20, WaypointOptions: navigation.WaypointOptions{MMPerSec: 200, Heading: &heading}},
+		{ID: primitive.NewObjectID(), Order: 1, Lat: 40.5, Long: 20.5},
+	}
+	workingNavigationService.AddCoverageFunc = func(
+		ctx context.Context,
+		area navigation.CoverageArea,
+		extra map[string]interface{},
+	) ([]navigation.Waypoint, error) {
+		extraOptions = extra
+		receivedArea = area
+		return coverageWaypoints, nil
+	}
+	expectedProgress := navigation.CoverageProgress{
+		TotalWaypoints:   8,
+		VisitedWaypoints: 3,
+		RemovedWaypoints: 1,
+		TotalMeters:      150,
+		CoveredMeters:    42.5,
+	}
+	workingNavigationService.CoverageProgressFunc = func(
+		ctx context.Context,
+		extra map[string]interface{},
+	) (navigation.CoverageProgress, error) {
+		extraOptions = extra
+		return expectedProgress, nil
+	}
+
 	failingNavigationService.ModeFunc = func(ctx context.Context, extra map[string]interface{}) (navigation.Mode, error) {
 		return navigation.ModeManual, errors.New("failure to retrieve mode")
 	}
@@ -202,6 +262,61 @@ func TestClient(t *testing.T) {
 		test.That(t, conn.Close(), test.ShouldBeNil)
 	})
 
+	t.Run("dialed client zone tests for working navigation service", func(t *testing.T) {
+		conn, err := viamgrpc.Dial(context.Background(), listener1.Addr().String(), logger)
+		test.That(t, err, test.ShouldBeNil)
+		workingDialedClient := navigation.NewClientFromConn(context.Background(), conn, testSvcName1, logger)
+
+		vertices := []*geo.Point{geo.NewPoint(40, -74), geo.NewPoint(40, -73), geo.NewPoint(41, -73)}
+		extra := map[string]interface{}{"foo": "AddZone"}
+		err = workingDialedClient.AddZone(context.Background(), navigation.ZoneTypeKeepOut, vertices, extra)
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, extraOptions, test.ShouldResemble, extra)
+		err = workingDialedClient.AddZone(context.Background(), navigation.ZoneTypeKeepOut, vertices[:2], nil)
+		test.That(t, err, test.ShouldNotBeNil)
+		test.That(t, err.Error(), test.ShouldContainSubstring, "at least 3 vertices")
+
+		extra = map[string]interface{}{"foo": "Zones"}
+		receivedZones, err := workingDialedClient.Zones(context.Background(), extra)
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, receivedZones, test.ShouldResemble, zones)
+		test.That(t, extraOptions, test.ShouldResemble, extra)
+
+		extra = map[string]interface{}{"foo": "RemoveZone"}
+		err = workingDialedClient.RemoveZone(context.Background(), receivedZones[0].ID, extra)
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, extraOptions, test.ShouldResemble, extra)
+		test.That(t, zones, test.ShouldBeEmpty)
+		test.That(t, conn.Close(), test.ShouldBeNil)
+	})
+
+	t.Run("dialed client coverage tests for working navigation service", func(t *testing.T) {
+		conn, err := viamgrpc.Dial(context.Background(), listener1.Addr().String(), logger)
+		test.That(t, err, test.ShouldBeNil)
+		workingDialedClient := navigation.NewClientFromConn(context.Background(), conn, testSvcName1, logger)
+
+		area := navigation.CoverageArea{
+			Boundary:         []*geo.Point{geo.NewPoint(40, -74), geo.NewPoint(40, -73), geo.NewPoint(41, -73)},
+			Holes:            [][]*geo.Point{{geo.NewPoint(40.1, -73.5), geo.NewPoint(40.1, -73.4), geo.NewPoint(40.2, -73.4)}},
+			SwathWidthMeters: 2,
+			HeadlandMeters:   1.5,
+			HeadingDegs:      30,
+		}
+		extra := map[string]interface{}{"foo": "AddCoverage"}
+		wps, err := workingDialedClient.AddCoverage(context.Background(), area, extra)
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, wps, test.ShouldResemble, coverageWaypoints)
+		test.That(t, receivedArea, test.ShouldResemble, area)
+		test.That(t, extraOptions, test.ShouldResemble, extra)
+
+		extra = map[string]interface{}{"foo": "CoverageProgress"}
+		progress, err := workingDialedClient.CoverageProgress(context.Background(), extra)
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, progress, test.ShouldResemble, expectedProgress)
+		test.That(t, extraOptions, test.ShouldResemble, extra)
+		test.That(t, conn.Close(), test.ShouldBeNil)
+	})
+
 	go failingServer.Serve(listener2)
 	defer failingServer.Stop()
 
@@ -250,6 +365,10 @@ func TestClient(t *testing.T) {
 		err = failingDialedClient.RemoveWaypoint(context.Background(), wptID, map[string]interface{}{})
 		test.That(t, err, test.ShouldNotBeNil)
 		test.That(t, wptID, test.ShouldEqual, receivedFailingID)
+
+		// the failing server has no command service to carry zones
+		_, err = failingDialedClient.Zones(context.Background(), map[string]interface{}{})
+		test.That(t, err, test.ShouldNotBeNil)
 		test.That(t, conn.Close(), test.ShouldBeNil)
 	})
 }
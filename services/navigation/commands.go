@@ -0,0 +1,288 @@
+package navigation
+
+import (
+	"context"
+
+	geo "github.com/kellydunn/golang-geo"
+	"github.com/pkg/errors"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"go.viam.com/rdk/components/generic"
+	"go.viam.com/rdk/subtype"
+)
+
+// Commands that carry the parts of the Service the navigation API has no messages for. The client sends
+// them through the command service of the subtype and each may have its extra parameters under "extra".
+const (
+	// ZonesCommand returns the zones of the service under "zones".
+	ZonesCommand = "zones"
+	// AddZoneCommand adds a zone of the "type" with the "vertices", each a map of "lat" and "lng".
+	AddZoneCommand = "add_zone"
+	// RemoveZoneCommand removes the zone with the hex "id".
+	RemoveZoneCommand = "remove_zone"
+	// AddCoverageCommand adds a coverage pattern over the "area" and returns its waypoints under
+	// "waypoints". The area has a "boundary" and a list of "holes", each a list of points, along with the
+	// "swath_width_m", "headland_m" and "heading_degs" of the passes.
+	AddCoverageCommand = "add_coverage"
+	// CoverageProgressCommand returns the progress along the most recently added coverage pattern.
+	CoverageProgressCommand = "coverage_progress"
+)
+
+// commandHandler passes commands sent to the command service of the subtype on to the named service.
+func commandHandler(subtypeSvc subtype.Service) generic.CommandHandler {
+	server := &subtypeServer{subtypeSvc: subtypeSvc}
+	return func(ctx context.Context, name string, cmd map[string]interface{}) (map[string]interface{}, error) {
+		svc, err := server.service(name)
+		if err != nil {
+			return nil, err
+		}
+		return doCommand(ctx, svc, cmd)
+	}
+}
+
+func doCommand(ctx context.Context, svc Service, cmd map[string]interface{}) (map[string]interface{}, error) {
+	name, ok := cmd["command"]
+	if !ok {
+		return nil, errors.New("missing 'command' value")
+	}
+	extra, _ := cmd["extra"].(map[string]interface{})
+	switch name {
+	case ZonesCommand:
+		zones, err := svc.Zones(ctx, extra)
+		if err != nil {
+			return nil, err
+		}
+		encoded := make([]interface{}, 0, len(zones))
+		for _, zone := range zones {
+			encoded = append(encoded, map[string]interface{}{
+				"id":       zone.ID.Hex(),
+				"type":     string(zone.Type),
+				"vertices": pointsToCommand(zone.Points()),
+			})
+		}
+		return map[string]interface{}{"zones": encoded}, nil
+	case AddZoneCommand:
+		zoneType, ok := cmd["type"].(string)
+		if !ok {
+			return nil, errors.New("need a zone type")
+		}
+		vertices, err := pointsFromCommand(cmd["vertices"])
+		if err != nil {
+			return nil, err
+		}
+		return nil, svc.AddZone(ctx, ZoneType(zoneType), vertices, extra)
+	case RemoveZoneCommand:
+		id, err := idFromCommand(cmd["id"])
+		if err != nil {
+			return nil, err
+		}
+		return nil, svc.RemoveZone(ctx, id, extra)
+	case AddCoverageCommand:
+		area, err := coverageAreaFromCommand(cmd["area"])
+		if err != nil {
+			return nil, err
+		}
+		wps, err := svc.AddCoverage(ctx, area, extra)
+		if err != nil {
+			return nil, err
+		}
+		encoded := make([]interface{}, 0, len(wps))
+		for _, wp := range wps {
+			encoded = append(encoded, map[string]interface{}{
+				"id":      wp.ID.Hex(),
+				"order":   wp.Order,
+				"lat":     wp.Lat,
+				"lng":     wp.Long,
+				"options": wp.WaypointOptions.Extra(),
+			})
+		}
+		return map[string]interface{}{"waypoints": encoded}, nil
+	case CoverageProgressCommand:
+		progress, err := svc.CoverageProgress(ctx, extra)
+		if err != nil {
+			return nil, err
+		}
+		return map[string]interface{}{
+			"total_waypoints":   progress.TotalWaypoints,
+			"visited_waypoints": progress.VisitedWaypoints,
+			"removed_waypoints": progress.RemovedWaypoints,
+			"total_m":           progress.TotalMeters,
+			"covered_m":         progress.CoveredMeters,
+		}, nil
+	default:
+		return nil, errors.Errorf("no such command: %s", name)
+	}
+}
+
+func (c *client) doCommand(
+	ctx context.Context,
+	name string,
+	args map[string]interface{},
+	extra map[string]interface{},
+) (map[string]interface{}, error) {
+	cmd := map[string]interface{}{"command": name}
+	for k, v := range args {
+		cmd[k] = v
+	}
+	if extra != nil {
+		cmd["extra"] = extra
+	}
+	return generic.DoFromCommandService(ctx, c.conn, Subtype, c.name, cmd)
+}
+
+func zonesFromCommand(raw interface{}) ([]Zone, error) {
+	list, ok := raw.([]interface{})
+	if !ok {
+		return nil, errors.New("zones must be a list")
+	}
+	zones := make([]Zone, 0, len(list))
+	for i, r := range list {
+		encoded, ok := r.(map[string]interface{})
+		if !ok {
+			return nil, errors.Errorf("zone %d must be a map", i)
+		}
+		id, err := idFromCommand(encoded["id"])
+		if err != nil {
+			return nil, errors.Wrapf(err, "zone %d", i)
+		}
+		zoneType, ok := encoded["type"].(string)
+		if !ok {
+			return nil, errors.Errorf("zone %d is missing its type", i)
+		}
+		points, err := pointsFromCommand(encoded["vertices"])
+		if err != nil {
+			return nil, errors.Wrapf(err, "zone %d", i)
+		}
+		zones = append(zones, Zone{ID: id, Type: ZoneType(zoneType), Vertices: VerticesOf(points)})
+	}
+	return zones, nil
+}
+
+func coverageAreaToCommand(area CoverageArea) map[string]interface{} {
+	holes := make([]interface{}, 0, len(area.Holes))
+	for _, hole := range area.Holes {
+		holes = append(holes, pointsToCommand(hole))
+	}
+	return map[string]interface{}{
+		"boundary":      pointsToCommand(area.Boundary),
+		"holes":         holes,
+		"swath_width_m": area.SwathWidthMeters,
+		"headland_m":    area.HeadlandMeters,
+		"heading_degs":  area.HeadingDegs,
+	}
+}
+
+func coverageAreaFromCommand(raw interface{}) (CoverageArea, error) {
+	encoded, ok := raw.(map[string]interface{})
+	if !ok {
+		return CoverageArea{}, errors.New("need a coverage area")
+	}
+	boundary, err := pointsFromCommand(encoded["boundary"])
+	if err != nil {
+		return CoverageArea{}, errors.Wrap(err, "boundary")
+	}
+	area := CoverageArea{Boundary: boundary}
+	if rawHoles, ok := encoded["holes"]; ok {
+		holes, ok := rawHoles.([]interface{})
+		if !ok {
+			return CoverageArea{}, errors.New("holes must be a list")
+		}
+		for i, rawHole := range holes {
+			hole, err := pointsFromCommand(rawHole)
+			if err != nil {
+				return CoverageArea{}, errors.Wrapf(err, "hole %d", i)
+			}
+			area.Holes = append(area.Holes, hole)
+		}
+	}
+	area.SwathWidthMeters, _ = encoded["swath_width_m"].(float64)
+	area.HeadlandMeters, _ = encoded["headland_m"].(float64)
+	area.HeadingDegs, _ = encoded["heading_degs"].(float64)
+	return area, nil
+}
+
+func waypointsFromCommand(raw interface{}) ([]Waypoint, error) {
+	list, ok := raw.([]interface{})
+	if !ok {
+		return nil, errors.New("waypoints must be a list")
+	}
+	wps := make([]Waypoint, 0, len(list))
+	for i, r := range list {
+		encoded, ok := r.(map[string]interface{})
+		if !ok {
+			return nil, errors.Errorf("waypoint %d must be a map", i)
+		}
+		id, err := idFromCommand(encoded["id"])
+		if err != nil {
+			return nil, errors.Wrapf(err, "waypoint %d", i)
+		}
+		points, err := pointsFromCommand([]interface{}{encoded})
+		if err != nil {
+			return nil, errors.Wrapf(err, "waypoint %d", i)
+		}
+		options, _ := encoded["options"].(map[string]interface{})
+		opts, err := WaypointOptionsFromExtra(options)
+		if err != nil {
+			return nil, errors.Wrapf(err, "waypoint %d", i)
+		}
+		order, _ := encoded["order"].(float64)
+		wps = append(wps, Waypoint{
+			ID:              id,
+			Order:           int(order),
+			Lat:             points[0].Lat(),
+			Long:            points[0].Lng(),
+			WaypointOptions: opts,
+		})
+	}
+	return wps, nil
+}
+
+func coverageProgressFromCommand(encoded map[string]interface{}) CoverageProgress {
+	var progress CoverageProgress
+	total, _ := encoded["total_waypoints"].(float64)
+	visited, _ := encoded["visited_waypoints"].(float64)
+	removed, _ := encoded["removed_waypoints"].(float64)
+	progress.TotalWaypoints = int(total)
+	progress.VisitedWaypoints = int(visited)
+	progress.RemovedWaypoints = int(removed)
+	progress.TotalMeters, _ = encoded["total_m"].(float64)
+	progress.CoveredMeters, _ = encoded["covered_m"].(float64)
+	return progress
+}
+
+func idFromCommand(raw interface{}) (primitive.ObjectID, error) {
+	hex, ok := raw.(string)
+	if !ok {
+		return primitive.ObjectID{}, errors.New("need an id")
+	}
+	return primitive.ObjectIDFromHex(hex)
+}
+
+func pointsToCommand(points []*geo.Point) []interface{} {
+	encoded := make([]interface{}, 0, len(points))
+	for _, p := range points {
+		encoded = append(encoded, map[string]interface{}{"lat": p.Lat(), "lng": p.Lng()})
+	}
+	return encoded
+}
+
+func pointsFromCommand(raw interface{}) ([]*geo.Point, error) {
+	list, ok := raw.([]interface{})
+	if !ok {
+		return nil, errors.New("points must be a list")
+	}
+	points := make([]*geo.Point, 0, len(list))
+	for i, r := range list {
+		encoded, ok := r.(map[string]interface{})
+		if !ok {
+			return nil, errors.Errorf("point %d must be a map", i)
+		}
+		lat, latOK := encoded["lat"].(float64)
+		lng, lngOK := encoded["lng"].(float64)
+		if !latOK || !lngOK {
+			return nil, errors.Errorf("point %d needs a lat and lng", i)
+		}
+		points = append(points, geo.NewPoint(lat, lng))
+	}
+	return points, nil
+}
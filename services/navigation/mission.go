@@ -0,0 +1,241 @@
+package navigation
+
+import (
+	"context"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// Missions, ordered lists of waypoints along with their options, can be exchanged with desktop GIS tools
+// as GeoJSON or KML. Only the locations and options of waypoints are exported; IDs and visited state
+// belong to a particular store and are not.
+
+// AddWaypoints adds the waypoints of a mission, in order, to the navigation service.
+func AddWaypoints(ctx context.Context, svc Service, wps []Waypoint) error {
+	for i, wp := range wps {
+		if err := svc.AddWaypoint(ctx, wp.ToPoint(), wp.WaypointOptions.Extra()); err != nil {
+			return errors.Wrapf(err, "failed to add waypoint %d", i)
+		}
+	}
+	return nil
+}
+
+type geoJSONGeometry struct {
+	Type        string          `json:"type"`
+	Coordinates json.RawMessage `json:"coordinates"`
+}
+
+type geoJSONFeature struct {
+	Type       string                 `json:"type"`
+	Geometry   *geoJSONGeometry       `json:"geometry"`
+	Properties map[string]interface{} `json:"properties"`
+}
+
+type geoJSONFeatureCollection struct {
+	Type     string           `json:"type"`
+	Features []geoJSONFeature `json:"features"`
+}
+
+// WaypointsToGeoJSON encodes the waypoints as a GeoJSON FeatureCollection of Points whose properties are
+// the waypoint options.
+func WaypointsToGeoJSON(wps []Waypoint) ([]byte, error) {
+	fc := geoJSONFeatureCollection{Type: "FeatureCollection", Features: make([]geoJSONFeature, 0, len(wps))}
+	for _, wp := range wps {
+		coords, err := json.Marshal([]float64{wp.Long, wp.Lat})
+		if err != nil {
+			return nil, err
+		}
+		fc.Features = append(fc.Features, geoJSONFeature{
+			Type:       "Feature",
+			Geometry:   &geoJSONGeometry{Type: "Point", Coordinates: coords},
+			Properties: wp.WaypointOptions.Extra(),
+		})
+	}
+	return json.MarshalIndent(fc, "", "  ")
+}
+
+// WaypointsFromGeoJSON decodes waypoints from a GeoJSON FeatureCollection or Feature. Point and MultiPoint
+// geometries become one waypoint per point and LineStrings become one waypoint per vertex. The properties
+// of each feature are read as the options of its waypoints.
+func WaypointsFromGeoJSON(data []byte) ([]Waypoint, error) {
+	var fc geoJSONFeatureCollection
+	if err := json.Unmarshal(data, &fc); err != nil {
+		return nil, err
+	}
+	switch fc.Type {
+	case "FeatureCollection":
+	case "Feature":
+		var f geoJSONFeature
+		if err := json.Unmarshal(data, &f); err != nil {
+			return nil, err
+		}
+		fc.Features = []geoJSONFeature{f}
+	default:
+		return nil, errors.Errorf("unsupported GeoJSON type %q", fc.Type)
+	}
+
+	var wps []Waypoint
+	for i, f := range fc.Features {
+		if f.Geometry == nil {
+			continue
+		}
+		opts, err := WaypointOptionsFromExtra(f.Properties)
+		if err != nil {
+			return nil, errors.Wrapf(err, "feature %d", i)
+		}
+		var positions [][]float64
+		switch f.Geometry.Type {
+		case "Point":
+			var position []float64
+			if err := json.Unmarshal(f.Geometry.Coordinates, &position); err != nil {
+				return nil, errors.Wrapf(err, "feature %d", i)
+			}
+			positions = [][]float64{position}
+		case "MultiPoint", "LineString":
+			if err := json.Unmarshal(f.Geometry.Coordinates, &positions); err != nil {
+				return nil, errors.Wrapf(err, "feature %d", i)
+			}
+		default:
+			return nil, errors.Errorf("feature %d has unsupported geometry type %q", i, f.Geometry.Type)
+		}
+		for _, position := range positions {
+			if len(position) < 2 {
+				return nil, errors.Errorf("feature %d has a position with fewer than 2 coordinates", i)
+			}
+			wps = append(wps, Waypoint{Lat: position[1], Long: position[0], WaypointOptions: opts})
+		}
+	}
+	return wps, nil
+}
+
+const kmlNamespace = "http://www.opengis.net/kml/2.2"
+
+type kmlData struct {
+	Name  string `xml:"name,attr"`
+	Value string `xml:"value"`
+}
+
+type kmlPlacemark struct {
+	Name         string     `xml:"name,omitempty"`
+	ExtendedData []kmlData  `xml:"ExtendedData>Data,omitempty"`
+	Point        *kmlCoords `xml:"Point,omitempty"`
+	LineString   *kmlCoords `xml:"LineString,omitempty"`
+}
+
+type kmlCoords struct {
+	Coordinates string `xml:"coordinates"`
+}
+
+// kmlContainer is a Document or Folder, both of which can hold placemarks and other containers.
+type kmlContainer struct {
+	Name       string         `xml:"name,omitempty"`
+	Placemarks []kmlPlacemark `xml:"Placemark"`
+	Folders    []kmlContainer `xml:"Folder"`
+	Documents  []kmlContainer `xml:"Document"`
+}
+
+type kmlRoot struct {
+	XMLName xml.Name `xml:"kml"`
+	Xmlns   string   `xml:"xmlns,attr,omitempty"`
+	kmlContainer
+}
+
+// WaypointsToKML encodes the waypoints as a KML Document of Point Placemarks. Waypoint options are stored
+// in the ExtendedData of each Placemark.
+func WaypointsToKML(wps []Waypoint) ([]byte, error) {
+	doc := kmlContainer{Name: "mission", Placemarks: make([]kmlPlacemark, 0, len(wps))}
+	for i, wp := range wps {
+		pm := kmlPlacemark{
+			Name:  strconv.Itoa(i + 1),
+			Point: &kmlCoords{Coordinates: fmt.Sprintf("%v,%v", wp.Long, wp.Lat)},
+		}
+		extra := wp.WaypointOptions.Extra()
+		for _, key := range []string{"arrival_radius_m", "mm_per_sec", "dwell_secs", "heading", "actions"} {
+			v, ok := extra[key]
+			if !ok {
+				continue
+			}
+			encoded, err := json.Marshal(v)
+			if err != nil {
+				return nil, err
+			}
+			pm.ExtendedData = append(pm.ExtendedData, kmlData{Name: key, Value: string(encoded)})
+		}
+		doc.Placemarks = append(doc.Placemarks, pm)
+	}
+	out, err := xml.MarshalIndent(kmlRoot{Xmlns: kmlNamespace, kmlContainer: kmlContainer{Documents: []kmlContainer{doc}}}, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte(xml.Header), out...), nil
+}
+
+// WaypointsFromKML decodes waypoints from the Point and LineString Placemarks of a KML file. Placemarks
+// directly in a Document or Folder come before those in nested Folders. ExtendedData on a Placemark is
+// read as the options of its waypoints.
+func WaypointsFromKML(data []byte) ([]Waypoint, error) {
+	var root kmlRoot
+	if err := xml.Unmarshal(data, &root); err != nil {
+		return nil, err
+	}
+	return root.waypoints()
+}
+
+func (c *kmlContainer) waypoints() ([]Waypoint, error) {
+	var wps []Waypoint
+	for i, pm := range c.Placemarks {
+		extra := map[string]interface{}{}
+		for _, d := range pm.ExtendedData {
+			var v interface{}
+			if err := json.Unmarshal([]byte(d.Value), &v); err != nil {
+				// not json, so keep it as the raw string
+				v = d.Value
+			}
+			extra[d.Name] = v
+		}
+		opts, err := WaypointOptionsFromExtra(extra)
+		if err != nil {
+			return nil, errors.Wrapf(err, "placemark %d", i)
+		}
+
+		var coords string
+		switch {
+		case pm.Point != nil:
+			coords = pm.Point.Coordinates
+		case pm.LineString != nil:
+			coords = pm.LineString.Coordinates
+		default:
+			continue
+		}
+		for _, tuple := range strings.Fields(coords) {
+			parts := strings.Split(tuple, ",")
+			if len(parts) < 2 {
+				return nil, errors.Errorf("placemark %d has invalid coordinates %q", i, tuple)
+			}
+			lng, err := strconv.ParseFloat(parts[0], 64)
+			if err != nil {
+				return nil, errors.Wrapf(err, "placemark %d", i)
+			}
+			lat, err := strconv.ParseFloat(parts[1], 64)
+			if err != nil {
+				return nil, errors.Wrapf(err, "placemark %d", i)
+			}
+			wps = append(wps, Waypoint{Lat: lat, Long: lng, WaypointOptions: opts})
+		}
+	}
+	for _, children := range [][]kmlContainer{c.Documents, c.Folders} {
+		for i := range children {
+			childWps, err := children[i].waypoints()
+			if err != nil {
+				return nil, err
+			}
+			wps = append(wps, childWps...)
+		}
+	}
+	return wps, nil
+}
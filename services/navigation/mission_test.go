@@ -0,0 +1,113 @@
+package navigation_test
+
+import (
+	"context"
+	"testing"
+
+	geo "github.com/kellydunn/golang-geo"
+	"go.viam.com/test"
+
+	"go.viam.com/rdk/services/navigation"
+	"go.viam.com/rdk/testutils/inject"
+)
+
+func createMission() []navigation.Waypoint {
+	heading := 45.
+	return []navigation.Waypoint{
+		{Lat: 40.1, Long: -74.2},
+		{
+			Lat:  40.2,
+			Long: -74.3,
+			WaypointOptions: navigation.WaypointOptions{
+				ArrivalRadiusMeters: 2,
+				MMPerSec:            250,
+				DwellSecs:           10,
+				Heading:             &heading,
+				Actions: []navigation.WaypointAction{
+					{Resource: "camera_arm", Command: map[string]interface{}{"command": "photo", "count": 3.}},
+				},
+			},
+		},
+	}
+}
+
+func TestGeoJSON(t *testing.T) {
+	mission := createMission()
+	data, err := navigation.WaypointsToGeoJSON(mission)
+	test.That(t, err, test.ShouldBeNil)
+	wps, err := navigation.WaypointsFromGeoJSON(data)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, wps, test.ShouldResemble, mission)
+
+	t.Run("line string feature", func(t *testing.T) {
+		wps, err := navigation.WaypointsFromGeoJSON([]byte(`{
+			"type": "Feature",
+			"geometry": {"type": "LineString", "coordinates": [[-74.1, 40.1, 12], [-74.2, 40.2]]},
+			"properties": {"dwell_secs": 2, "name": "fence line"}
+		}`))
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, wps, test.ShouldResemble, []navigation.Waypoint{
+			{Lat: 40.1, Long: -74.1, WaypointOptions: navigation.WaypointOptions{DwellSecs: 2}},
+			{Lat: 40.2, Long: -74.2, WaypointOptions: navigation.WaypointOptions{DwellSecs: 2}},
+		})
+	})
+
+	t.Run("unsupported", func(t *testing.T) {
+		_, err := navigation.WaypointsFromGeoJSON([]byte(`{"type": "Polygon", "coordinates": []}`))
+		test.That(t, err, test.ShouldBeError, `unsupported GeoJSON type "Polygon"`)
+		_, err = navigation.WaypointsFromGeoJSON([]byte(`{"type": "FeatureCollection", "features": [
+			{"type": "Feature", "geometry": {"type": "Polygon", "coordinates": []}}
+		]}`))
+		test.That(t, err, test.ShouldBeError, `feature 0 has unsupported geometry type "Polygon"`)
+	})
+}
+
+func TestKML(t *testing.T) {
+	mission := createMission()
+	data, err := navigation.WaypointsToKML(mission)
+	test.That(t, err, test.ShouldBeNil)
+	wps, err := navigation.WaypointsFromKML(data)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, wps, test.ShouldResemble, mission)
+
+	t.Run("folders and line strings", func(t *testing.T) {
+		wps, err := navigation.WaypointsFromKML([]byte(`<?xml version="1.0" encoding="UTF-8"?>
+<kml xmlns="http://www.opengis.net/kml/2.2">
+  <Document>
+    <Folder>
+      <Placemark>
+        <ExtendedData><Data name="mm_per_sec"><value>100</value></Data></ExtendedData>
+        <LineString><coordinates>-74.1,40.1,0 -74.2,40.2,0</coordinates></LineString>
+      </Placemark>
+      <Placemark><name>no geometry</name></Placemark>
+    </Folder>
+  </Document>
+</kml>`))
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, wps, test.ShouldResemble, []navigation.Waypoint{
+			{Lat: 40.1, Long: -74.1, WaypointOptions: navigation.WaypointOptions{MMPerSec: 100}},
+			{Lat: 40.2, Long: -74.2, WaypointOptions: navigation.WaypointOptions{MMPerSec: 100}},
+		})
+	})
+
+	t.Run("bad coordinates", func(t *testing.T) {
+		_, err := navigation.WaypointsFromKML([]byte(`<kml><Placemark><Point><coordinates>1</coordinates></Point></Placemark></kml>`))
+		test.That(t, err, test.ShouldBeError, `placemark 0 has invalid coordinates "1"`)
+	})
+}
+
+func TestAddWaypoints(t *testing.T) {
+	mission := createMission()
+	var added []navigation.Waypoint
+	injectSvc := &inject.NavigationService{}
+	injectSvc.AddWaypointFunc = func(ctx context.Context, point *geo.Point, extra map[string]interface{}) error {
+		opts, err := navigation.WaypointOptionsFromExtra(extra)
+		if err != nil {
+			return err
+		}
+		added = append(added, navigation.Waypoint{Lat: point.Lat(), Long: point.Lng(), WaypointOptions: opts})
+		return nil
+	}
+	test.That(t, navigation.AddWaypoints(context.Background(), injectSvc, mission), test.ShouldBeNil)
+	test.That(t, added, test.ShouldResemble, mission)
+}
@@ -13,6 +13,7 @@ import (
 	"go.viam.com/utils"
 	"go.viam.com/utils/rpc"
 
+	"go.viam.com/rdk/components/generic"
 	"go.viam.com/rdk/registry"
 	"go.viam.com/rdk/resource"
 	"go.viam.com/rdk/subtype"
@@ -22,12 +23,15 @@ import (
 func init() {
 	registry.RegisterResourceSubtype(Subtype, registry.ResourceSubtype{
 		RegisterRPCService: func(ctx context.Context, rpcServer rpc.Server, subtypeSvc subtype.Service) error {
-			return rpcServer.RegisterServiceServer(
+			if err := rpcServer.RegisterServiceServer(
 				ctx,
 				&servicepb.NavigationService_ServiceDesc,
 				NewServer(subtypeSvc),
 				servicepb.RegisterNavigationServiceHandlerFromEndpoint,
-			)
+			); err != nil {
+				return err
+			}
+			return generic.RegisterCommandService(ctx, rpcServer, Subtype, commandHandler(subtypeSvc))
 		},
 		RPCServiceDesc: &servicepb.NavigationService_ServiceDesc,
 		RPCClient: func(ctx context.Context, conn rpc.ClientConn, name string, logger golog.Logger) interface{} {
@@ -57,6 +61,40 @@ type Service interface {
 	Waypoints(ctx context.Context, extra map[string]interface{}) ([]Waypoint, error)
 	AddWaypoint(ctx context.Context, point *geo.Point, extra map[string]interface{}) error
 	RemoveWaypoint(ctx context.Context, id primitive.ObjectID, extra map[string]interface{}) error
+
+	// Zones
+	Zones(ctx context.Context, extra map[string]interface{}) ([]Zone, error)
+	AddZone(ctx context.Context, zoneType ZoneType, vertices []*geo.Point, extra map[string]interface{}) error
+	RemoveZone(ctx context.Context, id primitive.ObjectID, extra map[string]interface{}) error
+
+	// Coverage
+	AddCoverage(ctx context.Context, area CoverageArea, extra map[string]interface{}) ([]Waypoint, error)
+	CoverageProgress(ctx context.Context, extra map[string]interface{}) (CoverageProgress, error)
+}
+
+// A CoverageArea is a polygon, possibly with holes, that the navigation service covers by driving back
+// and forth across it in passes one swath width apart.
+type CoverageArea struct {
+	Boundary []*geo.Point
+	// Holes are areas within the boundary that must not be driven through, such as obstacles.
+	Holes            [][]*geo.Point
+	SwathWidthMeters float64
+	// HeadlandMeters is how far from the boundary and holes each pass ends, leaving room to turn. It
+	// defaults to half of the swath width.
+	HeadlandMeters float64
+	// HeadingDegs is the compass heading the passes run along.
+	HeadingDegs float64
+}
+
+// CoverageProgress reports how much of the most recently added coverage pattern has been driven.
+type CoverageProgress struct {
+	TotalWaypoints   int
+	VisitedWaypoints int
+	// RemovedWaypoints were removed before they were visited. They count towards the totals but are never
+	// visited or covered.
+	RemovedWaypoints int
+	TotalMeters      float64
+	CoveredMeters    float64
 }
 
 var (
@@ -150,6 +188,47 @@ func (svc *reconfigurableNavigation) RemoveWaypoint(ctx context.Context, id prim
 	return svc.actual.RemoveWaypoint(ctx, id, extra)
 }
 
+// Zones.
+func (svc *reconfigurableNavigation) Zones(ctx context.Context, extra map[string]interface{}) ([]Zone, error) {
+	svc.mu.RLock()
+	defer svc.mu.RUnlock()
+	return svc.actual.Zones(ctx, extra)
+}
+
+func (svc *reconfigurableNavigation) AddZone(
+	ctx context.Context,
+	zoneType ZoneType,
+	vertices []*geo.Point,
+	extra map[string]interface{},
+) error {
+	svc.mu.RLock()
+	defer svc.mu.RUnlock()
+	return svc.actual.AddZone(ctx, zoneType, vertices, extra)
+}
+
+func (svc *reconfigurableNavigation) RemoveZone(ctx context.Context, id primitive.ObjectID, extra map[string]interface{}) error {
+	svc.mu.RLock()
+	defer svc.mu.RUnlock()
+	return svc.actual.RemoveZone(ctx, id, extra)
+}
+
+// Coverage.
+func (svc *reconfigurableNavigation) AddCoverage(
+	ctx context.Context,
+	area CoverageArea,
+	extra map[string]interface{},
+) ([]Waypoint, error) {
+	svc.mu.RLock()
+	defer svc.mu.RUnlock()
+	return svc.actual.AddCoverage(ctx, area, extra)
+}
+
+func (svc *reconfigurableNavigation) CoverageProgress(ctx context.Context, extra map[string]interface{}) (CoverageProgress, error) {
+	svc.mu.RLock()
+	defer svc.mu.RUnlock()
+	return svc.actual.CoverageProgress(ctx, extra)
+}
+
 func (svc *reconfigurableNavigation) Close(ctx context.Context) error {
 	svc.mu.RLock()
 	defer svc.mu.RUnlock()
@@ -3,11 +3,17 @@ package navigation
 
 import (
 	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sort"
 	"sync"
 	"time"
 
 	geo "github.com/kellydunn/golang-geo"
+	"github.com/mitchellh/mapstructure"
 	"github.com/pkg/errors"
+	bolt "go.etcd.io/bbolt"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
@@ -19,13 +25,26 @@ import (
 
 var errNoMoreWaypoints = errors.New("no more waypoints")
 
+// ErrWaypointNotFound is returned when a waypoint has been removed or never existed.
+var ErrWaypointNotFound = errors.New("waypoint not found")
+
 // NavStore handles the waypoints for a navigation service.
 type NavStore interface {
 	Waypoints(ctx context.Context) ([]Waypoint, error)
-	AddWaypoint(ctx context.Context, point *geo.Point) (Waypoint, error)
+	// Waypoint returns the waypoint with the id whether or not it has been visited.
+	Waypoint(ctx context.Context, id primitive.ObjectID) (Waypoint, error)
+	AddWaypoint(ctx context.Context, point *geo.Point, opts WaypointOptions) (Waypoint, error)
 	RemoveWaypoint(ctx context.Context, id primitive.ObjectID) error
 	NextWaypoint(ctx context.Context) (Waypoint, error)
 	WaypointVisited(ctx context.Context, id primitive.ObjectID) error
+
+	Zones(ctx context.Context) ([]Zone, error)
+	AddZone(ctx context.Context, zoneType ZoneType, vertices []*geo.Point) (Zone, error)
+	RemoveZone(ctx context.Context, id primitive.ObjectID) error
+
+	// Coverage returns the most recently set coverage record, or nil if there is none.
+	Coverage(ctx context.Context) (*Coverage, error)
+	SetCoverage(ctx context.Context, coverage Coverage) error
 }
 
 type storeType string
@@ -35,6 +54,8 @@ const (
 	StoreTypeMemory = "memory"
 	// StoreTypeMongoDB is the constant for the mongodb store type.
 	StoreTypeMongoDB = "mongodb"
+	// StoreTypeBoltDB is the constant for the boltdb store type.
+	StoreTypeBoltDB = "boltdb"
 )
 
 // StoreConfig describes how to configure data storage.
@@ -46,7 +67,7 @@ type StoreConfig struct {
 // Validate ensures all parts of the config are valid.
 func (config *StoreConfig) Validate(path string) error {
 	switch config.Type {
-	case StoreTypeMemory, StoreTypeMongoDB:
+	case StoreTypeMemory, StoreTypeMongoDB, StoreTypeBoltDB:
 	default:
 		return errors.Errorf("unknown store type %q", config.Type)
 	}
@@ -55,11 +76,86 @@ func (config *StoreConfig) Validate(path string) error {
 
 // A Waypoint designates a location within a path to navigate to.
 type Waypoint struct {
-	ID      primitive.ObjectID `bson:"_id"`
-	Visited bool               `bson:"visited"`
-	Order   int                `bson:"order"`
-	Lat     float64            `bson:"latitude"`
-	Long    float64            `bson:"longitude"`
+	ID              primitive.ObjectID `bson:"_id"`
+	Visited         bool               `bson:"visited"`
+	Order           int                `bson:"order"`
+	Lat             float64            `bson:"latitude"`
+	Long            float64            `bson:"longitude"`
+	WaypointOptions `bson:",inline"`
+}
+
+// WaypointOptions describe how the navigation service approaches and behaves at a Waypoint. Zero values
+// mean the service defaults are used.
+type WaypointOptions struct {
+	// ArrivalRadiusMeters is how close the robot must get for the waypoint to be reached.
+	ArrivalRadiusMeters float64 `bson:"arrival_radius_m,omitempty" json:"arrival_radius_m,omitempty"`
+	// MMPerSec is the speed to drive at on the way to the waypoint.
+	MMPerSec float64 `bson:"mm_per_sec,omitempty" json:"mm_per_sec,omitempty"`
+	// DwellSecs is how long to stay at the waypoint before moving on.
+	DwellSecs float64 `bson:"dwell_secs,omitempty" json:"dwell_secs,omitempty"`
+	// Heading is the compass heading, in degrees, to turn to once the waypoint is reached.
+	Heading *float64 `bson:"heading,omitempty" json:"heading,omitempty"`
+	// Actions are run in order once the waypoint is reached.
+	Actions []WaypointAction `bson:"actions,omitempty" json:"actions,omitempty"`
+}
+
+// A WaypointAction is a DoCommand to send to a resource when a waypoint is reached.
+type WaypointAction struct {
+	Resource string                 `bson:"resource" json:"resource"`
+	Command  map[string]interface{} `bson:"command" json:"command"`
+}
+
+// WaypointOptionsFromExtra reads the options of a waypoint out of the extra parameters of AddWaypoint.
+// Keys that are not waypoint options are ignored.
+func WaypointOptionsFromExtra(extra map[string]interface{}) (WaypointOptions, error) {
+	var opts WaypointOptions
+	decoder, err := mapstructure.NewDecoder(&mapstructure.DecoderConfig{TagName: "json", Result: &opts})
+	if err != nil {
+		return WaypointOptions{}, err
+	}
+	if err := decoder.Decode(extra); err != nil {
+		return WaypointOptions{}, errors.Wrap(err, "invalid waypoint options")
+	}
+	if opts.ArrivalRadiusMeters < 0 {
+		return WaypointOptions{}, errors.New("arrival_radius_m cannot be negative")
+	}
+	if opts.MMPerSec < 0 {
+		return WaypointOptions{}, errors.New("mm_per_sec cannot be negative")
+	}
+	if opts.DwellSecs < 0 {
+		return WaypointOptions{}, errors.New("dwell_secs cannot be negative")
+	}
+	for i, action := range opts.Actions {
+		if action.Resource == "" {
+			return WaypointOptions{}, errors.Errorf("action %d is missing a resource", i)
+		}
+	}
+	return opts, nil
+}
+
+// Extra returns the options in the form accepted by the extra parameters of AddWaypoint.
+func (opts WaypointOptions) Extra() map[string]interface{} {
+	extra := map[string]interface{}{}
+	if opts.ArrivalRadiusMeters != 0 {
+		extra["arrival_radius_m"] = opts.ArrivalRadiusMeters
+	}
+	if opts.MMPerSec != 0 {
+		extra["mm_per_sec"] = opts.MMPerSec
+	}
+	if opts.DwellSecs != 0 {
+		extra["dwell_secs"] = opts.DwellSecs
+	}
+	if opts.Heading != nil {
+		extra["heading"] = *opts.Heading
+	}
+	if len(opts.Actions) != 0 {
+		actions := make([]interface{}, 0, len(opts.Actions))
+		for _, action := range opts.Actions {
+			actions = append(actions, map[string]interface{}{"resource": action.Resource, "command": action.Command})
+		}
+		extra["actions"] = actions
+	}
+	return extra
 }
 
 // ToPoint converts the waypoint to a geo.Point.
@@ -67,6 +163,72 @@ func (wp *Waypoint) ToPoint() *geo.Point {
 	return geo.NewPoint(wp.Lat, wp.Long)
 }
 
+// Coverage records the waypoints of a coverage pattern so that progress along it can be reported.
+type Coverage struct {
+	WaypointIDs []primitive.ObjectID `bson:"waypoint_ids" json:"waypoint_ids"`
+	// LegMeters[i] is the distance from waypoint i-1 to waypoint i.
+	LegMeters []float64 `bson:"leg_meters" json:"leg_meters"`
+}
+
+// ZoneType describes how the navigation service treats a Zone.
+type ZoneType string
+
+const (
+	// ZoneTypeGeofence is the boundary that the robot must stay within.
+	ZoneTypeGeofence = ZoneType("geofence")
+	// ZoneTypeKeepOut is an obstacle that the robot must route around.
+	ZoneTypeKeepOut = ZoneType("keep_out")
+)
+
+// A Vertex is a corner of a Zone.
+type Vertex struct {
+	Lat  float64 `bson:"latitude"`
+	Long float64 `bson:"longitude"`
+}
+
+// VerticesOf returns the vertices at the given points.
+func VerticesOf(points []*geo.Point) []Vertex {
+	vertices := make([]Vertex, 0, len(points))
+	for _, p := range points {
+		vertices = append(vertices, Vertex{Lat: p.Lat(), Long: p.Lng()})
+	}
+	return vertices
+}
+
+// A Zone is a polygonal area that the navigation service either keeps the robot inside of or out of.
+type Zone struct {
+	ID       primitive.ObjectID `bson:"_id"`
+	Type     ZoneType           `bson:"type"`
+	Vertices []Vertex           `bson:"vertices"`
+}
+
+// NewZone validates the given vertices and returns a new Zone made from them.
+func NewZone(zoneType ZoneType, vertices []*geo.Point) (Zone, error) {
+	switch zoneType {
+	case ZoneTypeGeofence, ZoneTypeKeepOut:
+	default:
+		return Zone{}, errors.Errorf("unknown zone type %q", zoneType)
+	}
+	if len(vertices) < 3 {
+		return Zone{}, errors.Errorf("zone needs at least 3 vertices but got %d", len(vertices))
+	}
+	return Zone{ID: primitive.NewObjectID(), Type: zoneType, Vertices: VerticesOf(vertices)}, nil
+}
+
+// Points returns the vertices of the zone as geo.Points.
+func (z *Zone) Points() []*geo.Point {
+	points := make([]*geo.Point, 0, len(z.Vertices))
+	for _, v := range z.Vertices {
+		points = append(points, geo.NewPoint(v.Lat, v.Long))
+	}
+	return points
+}
+
+// Contains returns whether or not the point is within the zone.
+func (z *Zone) Contains(point *geo.Point) bool {
+	return geo.NewPolygon(z.Points()).Contains(point)
+}
+
 // NewMemoryNavigationStore returns and empty MemoryNavigationStore.
 func NewMemoryNavigationStore() *MemoryNavigationStore {
 	return &MemoryNavigationStore{}
@@ -76,6 +238,8 @@ func NewMemoryNavigationStore() *MemoryNavigationStore {
 type MemoryNavigationStore struct {
 	mu        sync.RWMutex
 	waypoints []*Waypoint
+	zones     []Zone
+	coverage  *Coverage
 }
 
 // Waypoints returns a copy of all of the waypoints in the MemoryNavigationStore.
@@ -93,14 +257,27 @@ func (store *MemoryNavigationStore) Waypoints(ctx context.Context) ([]Waypoint,
 	return wps, nil
 }
 
+// Waypoint returns the waypoint with the id in the MemoryNavigationStore.
+func (store *MemoryNavigationStore) Waypoint(ctx context.Context, id primitive.ObjectID) (Waypoint, error) {
+	store.mu.RLock()
+	defer store.mu.RUnlock()
+	for _, wp := range store.waypoints {
+		if wp.ID == id {
+			return *wp, nil
+		}
+	}
+	return Waypoint{}, ErrWaypointNotFound
+}
+
 // AddWaypoint adds a waypoint to the MemoryNavigationStore.
-func (store *MemoryNavigationStore) AddWaypoint(ctx context.Context, point *geo.Point) (Waypoint, error) {
+func (store *MemoryNavigationStore) AddWaypoint(ctx context.Context, point *geo.Point, opts WaypointOptions) (Waypoint, error) {
 	store.mu.Lock()
 	defer store.mu.Unlock()
 	newPoint := Waypoint{
-		ID:   primitive.NewObjectID(),
-		Lat:  point.Lat(),
-		Long: point.Lng(),
+		ID:              primitive.NewObjectID(),
+		Lat:             point.Lat(),
+		Long:            point.Lng(),
+		WaypointOptions: opts,
 	}
 	store.waypoints = append(store.waypoints, &newPoint)
 	return newPoint, nil
@@ -146,11 +323,76 @@ func (store *MemoryNavigationStore) WaypointVisited(ctx context.Context, id prim
 	return nil
 }
 
+// Zones returns a copy of all of the zones in the MemoryNavigationStore.
+func (store *MemoryNavigationStore) Zones(ctx context.Context) ([]Zone, error) {
+	store.mu.RLock()
+	defer store.mu.RUnlock()
+	zones := make([]Zone, 0, len(store.zones))
+	for _, zone := range store.zones {
+		zone.Vertices = append([]Vertex(nil), zone.Vertices...)
+		zones = append(zones, zone)
+	}
+	return zones, nil
+}
+
+// AddZone adds a zone to the MemoryNavigationStore.
+func (store *MemoryNavigationStore) AddZone(ctx context.Context, zoneType ZoneType, vertices []*geo.Point) (Zone, error) {
+	zone, err := NewZone(zoneType, vertices)
+	if err != nil {
+		return Zone{}, err
+	}
+	store.mu.Lock()
+	defer store.mu.Unlock()
+	store.zones = append(store.zones, zone)
+	return zone, nil
+}
+
+// RemoveZone removes a zone from the MemoryNavigationStore.
+func (store *MemoryNavigationStore) RemoveZone(ctx context.Context, id primitive.ObjectID) error {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+	newZones := make([]Zone, 0, len(store.zones))
+	for _, zone := range store.zones {
+		if zone.ID == id {
+			continue
+		}
+		newZones = append(newZones, zone)
+	}
+	store.zones = newZones
+	return nil
+}
+
+// Coverage returns a copy of the coverage record in the MemoryNavigationStore.
+func (store *MemoryNavigationStore) Coverage(ctx context.Context) (*Coverage, error) {
+	store.mu.RLock()
+	defer store.mu.RUnlock()
+	if store.coverage == nil {
+		return nil, nil
+	}
+	return &Coverage{
+		WaypointIDs: append([]primitive.ObjectID(nil), store.coverage.WaypointIDs...),
+		LegMeters:   append([]float64(nil), store.coverage.LegMeters...),
+	}, nil
+}
+
+// SetCoverage replaces the coverage record in the MemoryNavigationStore.
+func (store *MemoryNavigationStore) SetCoverage(ctx context.Context, coverage Coverage) error {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+	store.coverage = &Coverage{
+		WaypointIDs: append([]primitive.ObjectID(nil), coverage.WaypointIDs...),
+		LegMeters:   append([]float64(nil), coverage.LegMeters...),
+	}
+	return nil
+}
+
 // Database and collection names used by the MongoDBNavigationStore.
 var (
 	defaultMongoDBURI                = "mongodb://127.0.0.1:27017"
 	MongoDBNavStoreDBName            = "navigation"
 	MongoDBNavStoreWaypointsCollName = "waypoints"
+	MongoDBNavStoreZonesCollName     = "zones"
+	MongoDBNavStoreCoverageCollName  = "coverage"
 	mongoDBNavStoreIndexes           = []mongo.IndexModel{
 		{
 			Keys: bson.D{
@@ -187,13 +429,17 @@ func NewMongoDBNavigationStore(ctx context.Context, config map[string]interface{
 	return &MongoDBNavigationStore{
 		mongoClient:   mongoClient,
 		waypointsColl: waypoints,
+		zonesColl:     mongoClient.Database(MongoDBNavStoreDBName).Collection(MongoDBNavStoreZonesCollName),
+		coverageColl:  mongoClient.Database(MongoDBNavStoreDBName).Collection(MongoDBNavStoreCoverageCollName),
 	}, nil
 }
 
-// MongoDBNavigationStore holds the mongodb client and waypoints collection.
+// MongoDBNavigationStore holds the mongodb client and the waypoints, zones and coverage collections.
 type MongoDBNavigationStore struct {
 	mongoClient   *mongo.Client
 	waypointsColl *mongo.Collection
+	zonesColl     *mongo.Collection
+	coverageColl  *mongo.Collection
 }
 
 // Close closes the connection with the mongodb client.
@@ -220,12 +466,25 @@ func (store *MongoDBNavigationStore) Waypoints(ctx context.Context) ([]Waypoint,
 	return all, nil
 }
 
+// Waypoint returns the waypoint with the id in the MongoDBNavigationStore.
+func (store *MongoDBNavigationStore) Waypoint(ctx context.Context, id primitive.ObjectID) (Waypoint, error) {
+	var wp Waypoint
+	if err := store.waypointsColl.FindOne(ctx, bson.D{{"_id", id}}).Decode(&wp); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return Waypoint{}, ErrWaypointNotFound
+		}
+		return Waypoint{}, err
+	}
+	return wp, nil
+}
+
 // AddWaypoint adds a waypoint to the MongoDBNavigationStore.
-func (store *MongoDBNavigationStore) AddWaypoint(ctx context.Context, point *geo.Point) (Waypoint, error) {
+func (store *MongoDBNavigationStore) AddWaypoint(ctx context.Context, point *geo.Point, opts WaypointOptions) (Waypoint, error) {
 	newPoint := Waypoint{
-		ID:   primitive.NewObjectID(),
-		Lat:  point.Lat(),
-		Long: point.Lng(),
+		ID:              primitive.NewObjectID(),
+		Lat:             point.Lat(),
+		Long:            point.Lng(),
+		WaypointOptions: opts,
 	}
 	if _, err := store.waypointsColl.InsertOne(ctx, newPoint); err != nil {
 		return Waypoint{}, err
@@ -263,3 +522,280 @@ func (store *MongoDBNavigationStore) WaypointVisited(ctx context.Context, id pri
 	_, err := store.waypointsColl.UpdateOne(ctx, bson.D{{"_id", id}}, bson.D{{"$set", bson.D{{"visited", true}}}})
 	return err
 }
+
+// Zones returns all the zones in the MongoDBNavigationStore.
+func (store *MongoDBNavigationStore) Zones(ctx context.Context) ([]Zone, error) {
+	cursor, err := store.zonesColl.Find(ctx, bson.D{}, options.Find().SetSort(bson.D{{"_id", 1}}))
+	if err != nil {
+		return nil, err
+	}
+
+	all := []Zone{}
+	if err := cursor.All(ctx, &all); err != nil {
+		return nil, err
+	}
+	return all, nil
+}
+
+// AddZone adds a zone to the MongoDBNavigationStore.
+func (store *MongoDBNavigationStore) AddZone(ctx context.Context, zoneType ZoneType, vertices []*geo.Point) (Zone, error) {
+	zone, err := NewZone(zoneType, vertices)
+	if err != nil {
+		return Zone{}, err
+	}
+	if _, err := store.zonesColl.InsertOne(ctx, zone); err != nil {
+		return Zone{}, err
+	}
+	return zone, nil
+}
+
+// RemoveZone removes a zone from the MongoDBNavigationStore.
+func (store *MongoDBNavigationStore) RemoveZone(ctx context.Context, id primitive.ObjectID) error {
+	_, err := store.zonesColl.DeleteOne(ctx, bson.D{{"_id", id}})
+	return err
+}
+
+// mongoDBCoverageID is the id of the only document in the coverage collection.
+const mongoDBCoverageID = "latest"
+
+// Coverage returns the coverage record in the MongoDBNavigationStore.
+func (store *MongoDBNavigationStore) Coverage(ctx context.Context) (*Coverage, error) {
+	var coverage Coverage
+	if err := store.coverageColl.FindOne(ctx, bson.D{{"_id", mongoDBCoverageID}}).Decode(&coverage); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &coverage, nil
+}
+
+// SetCoverage replaces the coverage record in the MongoDBNavigationStore.
+func (store *MongoDBNavigationStore) SetCoverage(ctx context.Context, coverage Coverage) error {
+	_, err := store.coverageColl.ReplaceOne(
+		ctx,
+		bson.D{{"_id", mongoDBCoverageID}},
+		coverage,
+		options.Replace().SetUpsert(true),
+	)
+	return err
+}
+
+// Bucket names used by the BoltDBNavigationStore.
+var (
+	defaultBoltDBPath             = filepath.Join(os.Getenv("HOME"), ".viam", "navigation.db")
+	boltDBNavStoreWaypointsBucket = []byte("waypoints")
+	boltDBNavStoreZonesBucket     = []byte("zones")
+	boltDBNavStoreCoverageBucket  = []byte("coverage")
+	// boltDBCoverageKey is the key of the only entry in the coverage bucket.
+	boltDBCoverageKey = []byte("latest")
+)
+
+// NewBoltDBNavigationStore creates a new navigation store in a BoltDB file so that waypoints and zones
+// survive restarts without needing a database server. The file is given by the "path" config key.
+func NewBoltDBNavigationStore(config map[string]interface{}) (*BoltDBNavigationStore, error) {
+	path, ok := config["path"].(string)
+	if !ok || path == "" {
+		path = defaultBoltDBPath
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return nil, err
+	}
+
+	// the timeout keeps us from blocking forever if another process holds the file lock
+	db, err := bolt.Open(path, 0o600, &bolt.Options{Timeout: 5 * time.Second})
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to open navigation store %q", path)
+	}
+	if err := db.Update(func(tx *bolt.Tx) error {
+		for _, bucket := range [][]byte{boltDBNavStoreWaypointsBucket, boltDBNavStoreZonesBucket, boltDBNavStoreCoverageBucket} {
+			if _, err := tx.CreateBucketIfNotExists(bucket); err != nil {
+				return err
+			}
+		}
+		return nil
+	}); err != nil {
+		return nil, multierr.Combine(err, db.Close())
+	}
+	return &BoltDBNavigationStore{db: db}, nil
+}
+
+// BoltDBNavigationStore holds the waypoints, zones and coverage in a BoltDB file. Entries are keyed by their IDs
+// and stored as JSON so that action commands read back the same way they were given.
+type BoltDBNavigationStore struct {
+	db *bolt.DB
+}
+
+// Close closes the BoltDB file.
+func (store *BoltDBNavigationStore) Close(ctx context.Context) error {
+	return store.db.Close()
+}
+
+// Waypoints returns a copy of all the waypoints that have not been visited in the BoltDBNavigationStore.
+func (store *BoltDBNavigationStore) Waypoints(ctx context.Context) ([]Waypoint, error) {
+	var wps []Waypoint
+	if err := store.db.View(func(tx *bolt.Tx) error {
+		return tx.Bucket(boltDBNavStoreWaypointsBucket).ForEach(func(k, v []byte) error {
+			var wp Waypoint
+			if err := json.Unmarshal(v, &wp); err != nil {
+				return err
+			}
+			if !wp.Visited {
+				wps = append(wps, wp)
+			}
+			return nil
+		})
+	}); err != nil {
+		return nil, err
+	}
+	// match the ordering of the MongoDBNavigationStore
+	sort.SliceStable(wps, func(i, j int) bool {
+		return wps[i].Order > wps[j].Order
+	})
+	return wps, nil
+}
+
+// Waypoint returns the waypoint with the id in the BoltDBNavigationStore.
+func (store *BoltDBNavigationStore) Waypoint(ctx context.Context, id primitive.ObjectID) (Waypoint, error) {
+	var wp Waypoint
+	if err := store.db.View(func(tx *bolt.Tx) error {
+		v := tx.Bucket(boltDBNavStoreWaypointsBucket).Get(id[:])
+		if v == nil {
+			return ErrWaypointNotFound
+		}
+		return json.Unmarshal(v, &wp)
+	}); err != nil {
+		return Waypoint{}, err
+	}
+	return wp, nil
+}
+
+// AddWaypoint adds a waypoint to the BoltDBNavigationStore.
+func (store *BoltDBNavigationStore) AddWaypoint(ctx context.Context, point *geo.Point, opts WaypointOptions) (Waypoint, error) {
+	newPoint := Waypoint{
+		ID:              primitive.NewObjectID(),
+		Lat:             point.Lat(),
+		Long:            point.Lng(),
+		WaypointOptions: opts,
+	}
+	if err := store.put(boltDBNavStoreWaypointsBucket, newPoint.ID, newPoint); err != nil {
+		return Waypoint{}, err
+	}
+	return newPoint, nil
+}
+
+// RemoveWaypoint removes a waypoint from the BoltDBNavigationStore.
+func (store *BoltDBNavigationStore) RemoveWaypoint(ctx context.Context, id primitive.ObjectID) error {
+	return store.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(boltDBNavStoreWaypointsBucket).Delete(id[:])
+	})
+}
+
+// NextWaypoint gets the next waypoint that has not been visited.
+func (store *BoltDBNavigationStore) NextWaypoint(ctx context.Context) (Waypoint, error) {
+	wps, err := store.Waypoints(ctx)
+	if err != nil {
+		return Waypoint{}, err
+	}
+	if len(wps) == 0 {
+		return Waypoint{}, errNoMoreWaypoints
+	}
+	return wps[0], nil
+}
+
+// WaypointVisited sets that a waypoint has been visited.
+func (store *BoltDBNavigationStore) WaypointVisited(ctx context.Context, id primitive.ObjectID) error {
+	return store.db.Update(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(boltDBNavStoreWaypointsBucket)
+		v := bucket.Get(id[:])
+		if v == nil {
+			return nil
+		}
+		var wp Waypoint
+		if err := json.Unmarshal(v, &wp); err != nil {
+			return err
+		}
+		wp.Visited = true
+		encoded, err := json.Marshal(wp)
+		if err != nil {
+			return err
+		}
+		return bucket.Put(id[:], encoded)
+	})
+}
+
+// Zones returns all the zones in the BoltDBNavigationStore.
+func (store *BoltDBNavigationStore) Zones(ctx context.Context) ([]Zone, error) {
+	zones := []Zone{}
+	if err := store.db.View(func(tx *bolt.Tx) error {
+		return tx.Bucket(boltDBNavStoreZonesBucket).ForEach(func(k, v []byte) error {
+			var zone Zone
+			if err := json.Unmarshal(v, &zone); err != nil {
+				return err
+			}
+			zones = append(zones, zone)
+			return nil
+		})
+	}); err != nil {
+		return nil, err
+	}
+	return zones, nil
+}
+
+// AddZone adds a zone to the BoltDBNavigationStore.
+func (store *BoltDBNavigationStore) AddZone(ctx context.Context, zoneType ZoneType, vertices []*geo.Point) (Zone, error) {
+	zone, err := NewZone(zoneType, vertices)
+	if err != nil {
+		return Zone{}, err
+	}
+	if err := store.put(boltDBNavStoreZonesBucket, zone.ID, zone); err != nil {
+		return Zone{}, err
+	}
+	return zone, nil
+}
+
+// RemoveZone removes a zone from the BoltDBNavigationStore.
+func (store *BoltDBNavigationStore) RemoveZone(ctx context.Context, id primitive.ObjectID) error {
+	return store.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(boltDBNavStoreZonesBucket).Delete(id[:])
+	})
+}
+
+// Coverage returns the coverage record in the BoltDBNavigationStore.
+func (store *BoltDBNavigationStore) Coverage(ctx context.Context) (*Coverage, error) {
+	var coverage *Coverage
+	if err := store.db.View(func(tx *bolt.Tx) error {
+		v := tx.Bucket(boltDBNavStoreCoverageBucket).Get(boltDBCoverageKey)
+		if v == nil {
+			return nil
+		}
+		coverage = &Coverage{}
+		return json.Unmarshal(v, coverage)
+	}); err != nil {
+		return nil, err
+	}
+	return coverage, nil
+}
+
+// SetCoverage replaces the coverage record in the BoltDBNavigationStore.
+func (store *BoltDBNavigationStore) SetCoverage(ctx context.Context, coverage Coverage) error {
+	encoded, err := json.Marshal(coverage)
+	if err != nil {
+		return err
+	}
+	return store.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(boltDBNavStoreCoverageBucket).Put(boltDBCoverageKey, encoded)
+	})
+}
+
+// put stores the JSON encoding of val under id in the bucket. ObjectIDs sort by creation time so
+// iterating over a bucket returns entries in the order they were added.
+func (store *BoltDBNavigationStore) put(bucket []byte, id primitive.ObjectID, val interface{}) error {
+	encoded, err := json.Marshal(val)
+	if err != nil {
+		return err
+	}
+	return store.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(bucket).Put(id[:], encoded)
+	})
+}
@@ -0,0 +1,205 @@
+package navigation_test
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	geo "github.com/kellydunn/golang-geo"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.viam.com/test"
+
+	"go.viam.com/rdk/services/navigation"
+)
+
+func TestMemoryNavigationStore(t *testing.T) {
+	testNavStore(t, navigation.NewMemoryNavigationStore())
+}
+
+func TestMongoDBNavigationStore(t *testing.T) {
+	ctx := context.Background()
+	store, err := navigation.NewMongoDBNavigationStore(ctx, map[string]interface{}{})
+	if err != nil {
+		t.Skipf("cannot run TestMongoDBNavigationStore because no mongo: %s\n", err)
+		return
+	}
+	defer func() {
+		test.That(t, store.Close(ctx), test.ShouldBeNil)
+	}()
+	clearNavStore(t, store)
+	testNavStore(t, store)
+	clearNavStore(t, store)
+}
+
+// clearNavStore removes everything from a store that may have been left behind by another run.
+func clearNavStore(t *testing.T, store navigation.NavStore) {
+	t.Helper()
+	ctx := context.Background()
+	wps, err := store.Waypoints(ctx)
+	test.That(t, err, test.ShouldBeNil)
+	for _, wp := range wps {
+		test.That(t, store.RemoveWaypoint(ctx, wp.ID), test.ShouldBeNil)
+	}
+	zones, err := store.Zones(ctx)
+	test.That(t, err, test.ShouldBeNil)
+	for _, zone := range zones {
+		test.That(t, store.RemoveZone(ctx, zone.ID), test.ShouldBeNil)
+	}
+}
+
+// testNavStore is the set of tests every NavStore implementation must pass. The store must start empty.
+func testNavStore(t *testing.T, store navigation.NavStore) {
+	t.Helper()
+	ctx := context.Background()
+
+	t.Run("waypoints", func(t *testing.T) {
+		_, err := store.NextWaypoint(ctx)
+		test.That(t, err, test.ShouldNotBeNil)
+
+		wp1, err := store.AddWaypoint(ctx, geo.NewPoint(40, 20), navigation.WaypointOptions{})
+		test.That(t, err, test.ShouldBeNil)
+		heading := 90.
+		wp2, err := store.AddWaypoint(ctx, geo.NewPoint(50, 30), navigation.WaypointOptions{
+			ArrivalRadiusMeters: 2,
+			MMPerSec:            300,
+			DwellSecs:           5,
+			Heading:             &heading,
+			Actions:             []navigation.WaypointAction{{Resource: "arm", Command: map[string]interface{}{"foo": "bar"}}},
+		})
+		test.That(t, err, test.ShouldBeNil)
+		wp3, err := store.AddWaypoint(ctx, geo.NewPoint(60, 40), navigation.WaypointOptions{})
+		test.That(t, err, test.ShouldBeNil)
+
+		wps, err := store.Waypoints(ctx)
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, wps, test.ShouldResemble, []navigation.Waypoint{wp1, wp2, wp3})
+
+		next, err := store.NextWaypoint(ctx)
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, next, test.ShouldResemble, wp1)
+
+		test.That(t, store.WaypointVisited(ctx, wp1.ID), test.ShouldBeNil)
+		next, err = store.NextWaypoint(ctx)
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, next, test.ShouldResemble, wp2)
+
+		test.That(t, store.RemoveWaypoint(ctx, wp2.ID), test.ShouldBeNil)
+		wps, err = store.Waypoints(ctx)
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, wps, test.ShouldResemble, []navigation.Waypoint{wp3})
+
+		// visited waypoints can still be looked up but removed ones cannot
+		visited, err := store.Waypoint(ctx, wp1.ID)
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, visited.Visited, test.ShouldBeTrue)
+		_, err = store.Waypoint(ctx, wp2.ID)
+		test.That(t, err, test.ShouldBeError, navigation.ErrWaypointNotFound)
+
+		test.That(t, store.RemoveWaypoint(ctx, wp3.ID), test.ShouldBeNil)
+		_, err = store.NextWaypoint(ctx)
+		test.That(t, err, test.ShouldNotBeNil)
+	})
+
+	t.Run("coverage", func(t *testing.T) {
+		first := navigation.Coverage{WaypointIDs: []primitive.ObjectID{primitive.NewObjectID()}, LegMeters: []float64{0}}
+		test.That(t, store.SetCoverage(ctx, first), test.ShouldBeNil)
+		second := navigation.Coverage{
+			WaypointIDs: []primitive.ObjectID{primitive.NewObjectID(), primitive.NewObjectID()},
+			LegMeters:   []float64{0, 12.5},
+		}
+		test.That(t, store.SetCoverage(ctx, second), test.ShouldBeNil)
+		coverage, err := store.Coverage(ctx)
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, *coverage, test.ShouldResemble, second)
+	})
+
+	t.Run("zones", func(t *testing.T) {
+		zones, err := store.Zones(ctx)
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, zones, test.ShouldBeEmpty)
+
+		square := []*geo.Point{geo.NewPoint(0, 0), geo.NewPoint(0, 1), geo.NewPoint(1, 1), geo.NewPoint(1, 0)}
+		_, err = store.AddZone(ctx, navigation.ZoneTypeKeepOut, square[:2])
+		test.That(t, err, test.ShouldNotBeNil)
+		_, err = store.AddZone(ctx, "bad", square)
+		test.That(t, err, test.ShouldNotBeNil)
+
+		fence, err := store.AddZone(ctx, navigation.ZoneTypeGeofence, square)
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, fence.Contains(geo.NewPoint(.5, .5)), test.ShouldBeTrue)
+		test.That(t, fence.Contains(geo.NewPoint(1.5, .5)), test.ShouldBeFalse)
+		keepOut, err := store.AddZone(ctx, navigation.ZoneTypeKeepOut, square)
+		test.That(t, err, test.ShouldBeNil)
+
+		zones, err = store.Zones(ctx)
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, zones, test.ShouldResemble, []navigation.Zone{fence, keepOut})
+
+		test.That(t, store.RemoveZone(ctx, fence.ID), test.ShouldBeNil)
+		zones, err = store.Zones(ctx)
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, zones, test.ShouldResemble, []navigation.Zone{keepOut})
+		test.That(t, store.RemoveZone(ctx, keepOut.ID), test.ShouldBeNil)
+	})
+}
+
+func TestWaypointOptionsFromExtra(t *testing.T) {
+	opts, err := navigation.WaypointOptionsFromExtra(nil)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, opts, test.ShouldResemble, navigation.WaypointOptions{})
+
+	heading := 270.
+	expected := navigation.WaypointOptions{
+		ArrivalRadiusMeters: 1.5,
+		MMPerSec:            200,
+		DwellSecs:           3,
+		Heading:             &heading,
+		Actions: []navigation.WaypointAction{
+			{Resource: "sprayer", Command: map[string]interface{}{"spray": true}},
+		},
+	}
+	extra := expected.Extra()
+	extra["other"] = "ignored"
+	opts, err = navigation.WaypointOptionsFromExtra(extra)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, opts, test.ShouldResemble, expected)
+
+	_, err = navigation.WaypointOptionsFromExtra(map[string]interface{}{"dwell_secs": -1.})
+	test.That(t, err, test.ShouldBeError, "dwell_secs cannot be negative")
+	_, err = navigation.WaypointOptionsFromExtra(map[string]interface{}{"mm_per_sec": "fast"})
+	test.That(t, err, test.ShouldNotBeNil)
+	_, err = navigation.WaypointOptionsFromExtra(map[string]interface{}{
+		"actions": []interface{}{map[string]interface{}{"command": map[string]interface{}{}}},
+	})
+	test.That(t, err, test.ShouldBeError, "action 0 is missing a resource")
+}
+
+func TestBoltDBNavigationStore(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "nav", "navigation.db")
+	store, err := navigation.NewBoltDBNavigationStore(map[string]interface{}{"path": path})
+	test.That(t, err, test.ShouldBeNil)
+	testNavStore(t, store)
+
+	// everything should still be there after reopening the file
+	wp, err := store.AddWaypoint(ctx, geo.NewPoint(10, 20), navigation.WaypointOptions{
+		Actions: []navigation.WaypointAction{{Resource: "arm", Command: map[string]interface{}{"nested": map[string]interface{}{"a": 1.}}}},
+	})
+	test.That(t, err, test.ShouldBeNil)
+	zone, err := store.AddZone(ctx, navigation.ZoneTypeKeepOut,
+		[]*geo.Point{geo.NewPoint(0, 0), geo.NewPoint(0, 1), geo.NewPoint(1, 1)})
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, store.Close(ctx), test.ShouldBeNil)
+
+	store, err = navigation.NewBoltDBNavigationStore(map[string]interface{}{"path": path})
+	test.That(t, err, test.ShouldBeNil)
+	defer func() {
+		test.That(t, store.Close(ctx), test.ShouldBeNil)
+	}()
+	wps, err := store.Waypoints(ctx)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, wps, test.ShouldResemble, []navigation.Waypoint{wp})
+	zones, err := store.Zones(ctx)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, zones, test.ShouldResemble, []navigation.Zone{zone})
+}
@@ -8,6 +8,7 @@ import (
 	_ "go.viam.com/rdk/services/datamanager/register"
 	_ "go.viam.com/rdk/services/motion/register"
 	_ "go.viam.com/rdk/services/navigation/register"
+	_ "go.viam.com/rdk/services/rosbridge/register"
 	_ "go.viam.com/rdk/services/sensors/register"
 	_ "go.viam.com/rdk/services/shell/register"
 	_ "go.viam.com/rdk/services/slam/register"
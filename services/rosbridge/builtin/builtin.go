@@ -0,0 +1,609 @@
+// Package builtin implements a bridge that publishes resources of a robot to a rosbridge server and drives a base from
+// the velocity commands of a ROS topic.
+package builtin
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/edaniels/golog"
+	"github.com/golang/geo/r3"
+	"github.com/pkg/errors"
+	goutils "go.viam.com/utils"
+
+	"go.viam.com/rdk/components/arm"
+	"go.viam.com/rdk/components/base"
+	"go.viam.com/rdk/components/camera"
+	"go.viam.com/rdk/components/movementsensor"
+	"go.viam.com/rdk/config"
+	"go.viam.com/rdk/referenceframe"
+	"go.viam.com/rdk/registry"
+	"go.viam.com/rdk/resource"
+	"go.viam.com/rdk/robot"
+	"go.viam.com/rdk/robot/framesystem"
+	"go.viam.com/rdk/ros"
+	bridgeclient "go.viam.com/rdk/ros/rosbridge"
+	"go.viam.com/rdk/services/rosbridge"
+	"go.viam.com/rdk/spatialmath"
+	"go.viam.com/rdk/utils"
+)
+
+func init() {
+	registry.RegisterService(rosbridge.Subtype, resource.DefaultModelName, registry.Service{
+		RobotConstructor: func(ctx context.Context, r robot.Robot, c config.Service, logger golog.Logger) (interface{}, error) {
+			return NewBuiltIn(ctx, r, c, logger)
+		},
+	})
+	config.RegisterServiceAttributeMapConverter(config.ServiceType(rosbridge.SubtypeName),
+		func(attributes config.AttributeMap) (interface{}, error) {
+			var conf Config
+			return config.TransformAttributeMapToStruct(&conf, attributes)
+		},
+		&Config{})
+}
+
+const (
+	defaultRateHz        = 10.
+	defaultCmdVelTopic   = "/cmd_vel"
+	defaultCmdVelTimeout = 500 * time.Millisecond
+	reconnectInterval    = time.Second
+	tfTopic              = "/tf"
+	// odomFrame is the frame_id of odometry messages, whose child_frame_id is the frame of the movement sensor.
+	odomFrame = "odom"
+)
+
+// The kinds of resources that can be published, which determine the type of the messages of their topic.
+const (
+	kindImage       = "image"
+	kindPointCloud  = "point_cloud"
+	kindOdometry    = "odometry"
+	kindIMU         = "imu"
+	kindJointStates = "joint_states"
+)
+
+var kindTypes = map[string]string{
+	kindImage:       ros.ImageType,
+	kindPointCloud:  ros.PointCloud2Type,
+	kindOdometry:    ros.OdometryType,
+	kindIMU:         ros.ImuType,
+	kindJointStates: ros.JointStateType,
+}
+
+// PublishConfig describes a resource that is published on a ROS topic.
+type PublishConfig struct {
+	Resource string `json:"resource"`
+	// Kind is one of image or point_cloud for cameras, odometry or imu for movement sensors and joint_states for arms.
+	Kind  string `json:"kind"`
+	Topic string `json:"topic"`
+	// FrameID is the frame_id of the messages, which defaults to the name of the resource.
+	FrameID string `json:"frame_id,omitempty"`
+}
+
+// Config describes how to configure the service.
+type Config struct {
+	// URL is the address of the rosbridge server, such as ws://localhost:9090.
+	URL    string          `json:"url"`
+	RateHz float64         `json:"rate_hz,omitempty"`
+	Topics []PublishConfig `json:"publish,omitempty"`
+	// PublishTF publishes the transform of every frame of the frame system from its parent on /tf.
+	PublishTF bool `json:"publish_tf,omitempty"`
+	// BaseName is the base driven by the geometry_msgs/Twist messages of CmdVelTopic.
+	BaseName    string `json:"base,omitempty"`
+	CmdVelTopic string `json:"cmd_vel_topic,omitempty"`
+	// CmdVelTimeoutMs is how long the base keeps moving without a new velocity command before it is stopped.
+	CmdVelTimeoutMs int `json:"cmd_vel_timeout_ms,omitempty"`
+}
+
+// Validate ensures all parts of the config are valid and creates the list of implicit dependencies.
+func (config *Config) Validate(path string) ([]string, error) {
+	if config.URL == "" {
+		return nil, goutils.NewConfigValidationFieldRequiredError(path, "url")
+	}
+	if config.RateHz < 0 {
+		return nil, goutils.NewConfigValidationError(path, errors.New("rate_hz cannot be negative"))
+	}
+	if config.CmdVelTimeoutMs < 0 {
+		return nil, goutils.NewConfigValidationError(path, errors.New("cmd_vel_timeout_ms cannot be negative"))
+	}
+	var deps []string
+	topics := map[string]bool{}
+	if config.PublishTF {
+		topics[tfTopic] = true
+	}
+	for i, topic := range config.Topics {
+		topicPath := fmt.Sprintf("%s.publish.%d", path, i)
+		if topic.Resource == "" {
+			return nil, goutils.NewConfigValidationFieldRequiredError(topicPath, "resource")
+		}
+		if topic.Topic == "" {
+			return nil, goutils.NewConfigValidationFieldRequiredError(topicPath, "topic")
+		}
+		if _, ok := kindTypes[topic.Kind]; !ok {
+			return nil, goutils.NewConfigValidationError(topicPath, errors.Errorf(
+				"kind must be one of %s, %s, %s, %s or %s, not %q",
+				kindImage, kindPointCloud, kindOdometry, kindIMU, kindJointStates, topic.Kind))
+		}
+		if topics[topic.Topic] {
+			return nil, goutils.NewConfigValidationError(topicPath, errors.Errorf("topic %s is published more than once", topic.Topic))
+		}
+		topics[topic.Topic] = true
+		deps = append(deps, topic.Resource)
+	}
+	if config.BaseName != "" {
+		deps = append(deps, config.BaseName)
+	}
+	return deps, nil
+}
+
+// NewBuiltIn returns a new rosbridge service for the given robot, which keeps connecting to the rosbridge server until
+// it is closed.
+func NewBuiltIn(ctx context.Context, r robot.Robot, c config.Service, logger golog.Logger) (rosbridge.Service, error) {
+	svcConfig, ok := c.ConvertedAttributes.(*Config)
+	if !ok {
+		return nil, utils.NewUnexpectedTypeError(svcConfig, c.ConvertedAttributes)
+	}
+	rateHz := svcConfig.RateHz
+	if rateHz == 0 {
+		rateHz = defaultRateHz
+	}
+	cmdVelTopic := svcConfig.CmdVelTopic
+	if cmdVelTopic == "" {
+		cmdVelTopic = defaultCmdVelTopic
+	}
+	cmdVelTimeout := time.Duration(svcConfig.CmdVelTimeoutMs) * time.Millisecond
+	if cmdVelTimeout == 0 {
+		cmdVelTimeout = defaultCmdVelTimeout
+	}
+
+	cancelCtx, cancel := context.WithCancel(context.Background())
+	svc := &builtIn{
+		r:             r,
+		config:        svcConfig,
+		logger:        logger,
+		period:        time.Duration(float64(time.Second) / rateHz),
+		cmdVelTopic:   cmdVelTopic,
+		cmdVelTimeout: cmdVelTimeout,
+		seqs:          map[string]int{},
+		errs:          map[string]string{},
+		cmdVel:        make(chan struct{}, 1),
+		cancel:        cancel,
+	}
+	svc.activeBackgroundWorkers.Add(1)
+	goutils.PanicCapturingGo(func() {
+		defer svc.activeBackgroundWorkers.Done()
+		svc.run(cancelCtx)
+	})
+	return svc, nil
+}
+
+type builtIn struct {
+	r             robot.Robot
+	config        *Config
+	logger        golog.Logger
+	period        time.Duration
+	cmdVelTopic   string
+	cmdVelTimeout time.Duration
+
+	// seqs holds the seq of the next message of each topic.
+	seqs map[string]int
+
+	mu sync.Mutex
+	// errs holds the last error of each topic, so that a resource that keeps failing is only logged once.
+	errs       map[string]string
+	lastCmdVel time.Time
+	moving     bool
+	// cmdVel is signaled on every velocity command, so that the timeout is counted from the latest one.
+	cmdVel chan struct{}
+
+	cancel                  func()
+	activeBackgroundWorkers sync.WaitGroup
+}
+
+func (svc *builtIn) Topics() map[string]string {
+	topics := map[string]string{}
+	for _, topic := range svc.config.Topics {
+		topics[topic.Topic] = kindTypes[topic.Kind]
+	}
+	if svc.config.PublishTF {
+		topics[tfTopic] = ros.TFMessageType
+	}
+	return topics
+}
+
+// Close disconnects from the rosbridge server and stops the base if it is moving.
+func (svc *builtIn) Close(ctx context.Context) error {
+	svc.cancel()
+	svc.activeBackgroundWorkers.Wait()
+	return svc.stopBase(ctx)
+}
+
+// run connects to the rosbridge server and reconnects whenever the connection is lost.
+func (svc *builtIn) run(ctx context.Context) {
+	for {
+		err := svc.bridge(ctx)
+		if ctx.Err() != nil {
+			return
+		}
+		svc.logger.Warnw("lost connection to rosbridge server, reconnecting", "url", svc.config.URL, "error", err)
+		if err := svc.stopBase(ctx); err != nil {
+			svc.logger.Errorw("failed to stop base", "error", err)
+		}
+		if !goutils.SelectContextOrWait(ctx, reconnectInterval) {
+			return
+		}
+	}
+}
+
+// bridge connects to the rosbridge server and publishes every period until the connection fails.
+func (svc *builtIn) bridge(ctx context.Context) error {
+	client, err := bridgeclient.Dial(ctx, svc.config.URL)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err := client.Close(); err != nil {
+			svc.logger.Debugw("failed to close connection to rosbridge server", "error", err)
+		}
+	}()
+	for topic, msgType := range svc.Topics() {
+		if err := client.Advertise(ctx, topic, msgType); err != nil {
+			return err
+		}
+	}
+	if svc.config.BaseName != "" {
+		if err := client.Subscribe(ctx, svc.cmdVelTopic, ros.TwistType); err != nil {
+			return err
+		}
+	}
+	svc.logger.Infow("connected to rosbridge server", "url", svc.config.URL)
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	readErr := make(chan error, 1)
+	svc.activeBackgroundWorkers.Add(1)
+	goutils.PanicCapturingGo(func() {
+		defer svc.activeBackgroundWorkers.Done()
+		readErr <- svc.read(ctx, client)
+	})
+	if svc.config.BaseName != "" {
+		// The timeout is watched apart from publishing, so that a resource that is slow to read does not keep the base
+		// moving.
+		svc.activeBackgroundWorkers.Add(1)
+		goutils.PanicCapturingGo(func() {
+			defer svc.activeBackgroundWorkers.Done()
+			svc.watchCmdVel(ctx)
+		})
+	}
+
+	ticker := time.NewTicker(svc.period)
+	defer ticker.Stop()
+	for {
+		if err := svc.publish(ctx, client); err != nil {
+			return err
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case err := <-readErr:
+			return err
+		case <-ticker.C:
+		}
+	}
+}
+
+// read handles the messages from the rosbridge server until the connection fails.
+func (svc *builtIn) read(ctx context.Context, client *bridgeclient.Client) error {
+	for {
+		msg, err := client.Read(ctx)
+		if err != nil {
+			return err
+		}
+		switch msg.Op {
+		case bridgeclient.OpPublish:
+			if svc.config.BaseName != "" && msg.Topic == svc.cmdVelTopic {
+				svc.logError(svc.cmdVelTopic, svc.handleCmdVel(ctx, msg.Msg))
+			}
+		case bridgeclient.OpStatus:
+			if msg.Level == "error" {
+				svc.logger.Warnw("rosbridge server reported an error", "id", msg.ID, "msg", string(msg.Msg))
+			}
+		}
+	}
+}
+
+// publish publishes a message for every topic. A resource that fails is logged and skipped, so that only a failure to
+// send to the server is returned.
+func (svc *builtIn) publish(ctx context.Context, client *bridgeclient.Client) error {
+	for _, topic := range svc.config.Topics {
+		msg, err := svc.message(ctx, topic, svc.header(topic.Topic, topic.FrameID, topic.Resource))
+		svc.logError(topic.Topic, err)
+		if err != nil {
+			continue
+		}
+		if err := client.Publish(ctx, topic.Topic, msg); err != nil {
+			return err
+		}
+	}
+	if svc.config.PublishTF {
+		msg, err := svc.tf(ctx)
+		svc.logError(tfTopic, err)
+		if err == nil {
+			return client.Publish(ctx, tfTopic, msg)
+		}
+	}
+	return nil
+}
+
+// header returns the header of the next message of topic.
+func (svc *builtIn) header(topic, frameID, resourceName string) ros.MessageHeader {
+	if frameID == "" {
+		frameID = resourceName
+	}
+	seq := svc.seqs[topic]
+	svc.seqs[topic]++
+	return ros.MessageHeader{Seq: seq, Stamp: ros.NewTimeStamp(time.Now()), FrameID: frameID}
+}
+
+func (svc *builtIn) logError(topic string, err error) {
+	svc.mu.Lock()
+	defer svc.mu.Unlock()
+	if err == nil {
+		delete(svc.errs, topic)
+		return
+	}
+	if svc.errs[topic] != err.Error() {
+		svc.errs[topic] = err.Error()
+		svc.logger.Warnw("rosbridge topic failed", "topic", topic, "error", err)
+	}
+}
+
+// message returns the current message of the resource of topic.
+func (svc *builtIn) message(ctx context.Context, topic PublishConfig, header ros.MessageHeader) (interface{}, error) {
+	switch topic.Kind {
+	case kindImage:
+		cam, err := camera.FromRobot(svc.r, topic.Resource)
+		if err != nil {
+			return nil, err
+		}
+		img, release, err := camera.ReadImage(ctx, cam)
+		if err != nil {
+			return nil, err
+		}
+		defer release()
+		return ros.NewImage(img, header), nil
+	case kindPointCloud:
+		cam, err := camera.FromRobot(svc.r, topic.Resource)
+		if err != nil {
+			return nil, err
+		}
+		pc, err := cam.NextPointCloud(ctx)
+		if err != nil {
+			return nil, err
+		}
+		return ros.NewPointCloud2(pc, header), nil
+	case kindOdometry:
+		ms, err := movementsensor.FromRobot(svc.r, topic.Resource)
+		if err != nil {
+			return nil, err
+		}
+		return newOdometry(ctx, ms, header)
+	case kindIMU:
+		ms, err := movementsensor.FromRobot(svc.r, topic.Resource)
+		if err != nil {
+			return nil, err
+		}
+		return newImu(ctx, ms, header)
+	case kindJointStates:
+		a, err := arm.FromRobot(svc.r, topic.Resource)
+		if err != nil {
+			return nil, err
+		}
+		return newJointState(ctx, a, header)
+	default:
+		return nil, errors.Errorf("unknown kind %q", topic.Kind)
+	}
+}
+
+// newOdometry returns the velocity and orientation of ms, which are left zero when ms does not support them. The
+// position is always zero since movement sensors only report global positions.
+func newOdometry(ctx context.Context, ms movementsensor.MovementSensor, header ros.MessageHeader) (*ros.Odometry, error) {
+	msg := &ros.Odometry{ChildFrameID: header.FrameID}
+	msg.Header = header
+	msg.Header.FrameID = odomFrame
+	msg.Pose.Pose.Orientation = ros.Quaternion{W: 1}
+
+	linear, err := ms.LinearVelocity(ctx, nil)
+	if err == nil {
+		msg.Twist.Twist.Linear = ros.Vector3{X: linear.X / 1000, Y: linear.Y / 1000, Z: linear.Z / 1000}
+	} else if !errors.Is(err, movementsensor.ErrMethodUnimplementedLinearVelocity) {
+		return nil, err
+	}
+	angular, err := ms.AngularVelocity(ctx, nil)
+	if err == nil {
+		msg.Twist.Twist.Angular = angularVelocity(angular)
+	} else if !errors.Is(err, movementsensor.ErrMethodUnimplementedAngularVelocity) {
+		return nil, err
+	}
+	orientation, err := ms.Orientation(ctx, nil)
+	if err == nil {
+		msg.Pose.Pose.Orientation = ros.NewQuaternion(orientation)
+	} else if !errors.Is(err, movementsensor.ErrMethodUnimplementedOrientation) {
+		return nil, err
+	}
+	return msg, nil
+}
+
+// newImu returns the orientation and angular velocity of ms. As movement sensors do not report linear acceleration,
+// it is always marked unknown, as is the orientation or angular velocity of a movement sensor that does not support
+// them.
+func newImu(ctx context.Context, ms movementsensor.MovementSensor, header ros.MessageHeader) (*ros.ImuData, error) {
+	msg := &ros.ImuData{Header: header, Orientation: ros.Quaternion{W: 1}}
+	msg.LinearAccelerationCovariance[0] = -1
+
+	orientation, err := ms.Orientation(ctx, nil)
+	switch {
+	case err == nil:
+		msg.Orientation = ros.NewQuaternion(orientation)
+	case errors.Is(err, movementsensor.ErrMethodUnimplementedOrientation):
+		msg.OrientationCovariance[0] = -1
+	default:
+		return nil, err
+	}
+	angular, err := ms.AngularVelocity(ctx, nil)
+	switch {
+	case err == nil:
+		msg.AngularVelocity = angularVelocity(angular)
+	case errors.Is(err, movementsensor.ErrMethodUnimplementedAngularVelocity):
+		msg.AngularVelocityCovariance[0] = -1
+	default:
+		return nil, err
+	}
+	return msg, nil
+}
+
+// angularVelocity converts an angular velocity in deg/s to rad/s.
+func angularVelocity(v spatialmath.AngularVelocity) ros.Vector3 {
+	return ros.Vector3{X: utils.DegToRad(v.X), Y: utils.DegToRad(v.Y), Z: utils.DegToRad(v.Z)}
+}
+
+// newJointState returns the joint positions of a in radians, named after the frames of the joints of its model.
+func newJointState(ctx context.Context, a arm.Arm, header ros.MessageHeader) (*ros.JointState, error) {
+	positions, err := a.JointPositions(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	radians := referenceframe.JointPositionsToRadians(positions)
+	return &ros.JointState{
+		Header:   header,
+		Name:     jointNames(a, header.FrameID, len(radians)),
+		Position: radians,
+	}, nil
+}
+
+// jointNames returns the names of the n joints of a, or names made from prefix if its model does not have n joints.
+func jointNames(a arm.Arm, prefix string, n int) []string {
+	names := make([]string, 0, n)
+	if model, ok := a.ModelFrame().(*referenceframe.SimpleModel); ok {
+		for _, frame := range model.OrdTransforms {
+			if len(frame.DoF()) > 0 {
+				names = append(names, frame.Name())
+			}
+		}
+	}
+	if len(names) == n {
+		return names
+	}
+	names = names[:0]
+	for i := 0; i < n; i++ {
+		names = append(names, fmt.Sprintf("%s_joint_%d", prefix, i))
+	}
+	return names
+}
+
+// tf returns the transform of every frame of the frame system of the robot from its parent.
+func (svc *builtIn) tf(ctx context.Context) (*ros.TFMessage, error) {
+	fs, err := framesystem.RobotFrameSystem(ctx, svc.r, nil)
+	if err != nil {
+		return nil, err
+	}
+	inputs, _, err := framesystem.RobotFsCurrentInputs(ctx, svc.r, fs)
+	if err != nil {
+		return nil, err
+	}
+	stamp := ros.NewTimeStamp(time.Now())
+	msg := &ros.TFMessage{Transforms: []ros.TransformStamped{}}
+	for _, name := range fs.FrameNames() {
+		parent, err := fs.Parent(fs.Frame(name))
+		if err != nil {
+			return nil, err
+		}
+		tf, err := fs.Transform(inputs, referenceframe.NewPoseInFrame(name, spatialmath.NewZeroPose()), parent.Name())
+		if err != nil {
+			return nil, err
+		}
+		pose := tf.(*referenceframe.PoseInFrame).Pose()
+		pt := pose.Point()
+		msg.Transforms = append(msg.Transforms, ros.TransformStamped{
+			Header:       ros.MessageHeader{Stamp: stamp, FrameID: parent.Name()},
+			ChildFrameID: name,
+			Transform: ros.Transform{
+				Translation: ros.Vector3{X: pt.X / 1000, Y: pt.Y / 1000, Z: pt.Z / 1000},
+				Rotation:    ros.NewQuaternion(pose.Orientation()),
+			},
+		})
+	}
+	return msg, nil
+}
+
+// handleCmdVel drives the base at the velocity of a geometry_msgs/Twist message. ROS bases move forward along x and
+// turn about z in m/s and rad/s, while bases move forward along y in mm/s and turn about z in deg/s.
+func (svc *builtIn) handleCmdVel(ctx context.Context, data json.RawMessage) error {
+	var twist ros.Twist
+	if err := json.Unmarshal(data, &twist); err != nil {
+		return errors.Wrapf(err, "invalid %s message", ros.TwistType)
+	}
+	b, err := base.FromRobot(svc.r, svc.config.BaseName)
+	if err != nil {
+		return err
+	}
+	svc.mu.Lock()
+	svc.lastCmdVel = time.Now()
+	svc.moving = true
+	svc.mu.Unlock()
+	select {
+	case svc.cmdVel <- struct{}{}:
+	default:
+	}
+	return b.SetVelocity(ctx,
+		r3.Vector{Y: 1000 * twist.Linear.X},
+		r3.Vector{Z: utils.RadToDeg(twist.Angular.Z)},
+		nil)
+}
+
+// watchCmdVel stops the base whenever the timeout passes without a velocity command, until ctx is done.
+func (svc *builtIn) watchCmdVel(ctx context.Context) {
+	timer := time.NewTimer(svc.cmdVelTimeout)
+	defer timer.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-svc.cmdVel:
+			if !timer.Stop() {
+				<-timer.C
+			}
+		case <-timer.C:
+			svc.checkCmdVelTimeout(ctx)
+		}
+		timer.Reset(svc.cmdVelTimeout)
+	}
+}
+
+// checkCmdVelTimeout stops the base if it has not been sent a velocity command within the timeout.
+func (svc *builtIn) checkCmdVelTimeout(ctx context.Context) {
+	svc.mu.Lock()
+	expired := svc.moving && time.Since(svc.lastCmdVel) >= svc.cmdVelTimeout
+	svc.mu.Unlock()
+	if expired {
+		svc.logError(svc.cmdVelTopic, svc.stopBase(ctx))
+	}
+}
+
+// stopBase stops the base if a velocity command has moved it since it was last stopped.
+func (svc *builtIn) stopBase(ctx context.Context) error {
+	svc.mu.Lock()
+	moving := svc.moving
+	svc.moving = false
+	svc.mu.Unlock()
+	if !moving {
+		return nil
+	}
+	b, err := base.FromRobot(svc.r, svc.config.BaseName)
+	if err != nil {
+		return err
+	}
+	return b.Stop(ctx, nil)
+}
@@ -0,0 +1,407 @@
+package builtin
+
+import (
+	"context"
+	"encoding/json"
+	"image"
+	"image/color"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/edaniels/golog"
+	"github.com/edaniels/gostream"
+	"github.com/golang/geo/r3"
+	"github.com/pkg/errors"
+	commonpb "go.viam.com/api/common/v1"
+	pb "go.viam.com/api/component/arm/v1"
+	"go.viam.com/test"
+	"go.viam.com/utils"
+	"nhooyr.io/websocket"
+	"nhooyr.io/websocket/wsjson"
+
+	"go.viam.com/rdk/components/arm"
+	fakearm "go.viam.com/rdk/components/arm/fake"
+	"go.viam.com/rdk/components/base"
+	"go.viam.com/rdk/components/camera"
+	"go.viam.com/rdk/components/movementsensor"
+	"go.viam.com/rdk/config"
+	"go.viam.com/rdk/referenceframe"
+	"go.viam.com/rdk/resource"
+	framesystemparts "go.viam.com/rdk/robot/framesystem/parts"
+	"go.viam.com/rdk/ros"
+	bridgeclient "go.viam.com/rdk/ros/rosbridge"
+	"go.viam.com/rdk/spatialmath"
+	"go.viam.com/rdk/testutils/inject"
+	rutils "go.viam.com/rdk/utils"
+)
+
+// rosbridgeServer is a stand-in for a rosbridge server, which records the messages sent to it and sends messages to the
+// last client that connected. It refuses the first rejects connections.
+type rosbridgeServer struct {
+	*httptest.Server
+	received chan bridgeclient.Message
+	mu       sync.Mutex
+	rejects  int
+	conn     *websocket.Conn
+}
+
+func newRosbridgeServer(t *testing.T, rejects int) *rosbridgeServer {
+	t.Helper()
+	s := &rosbridgeServer{received: make(chan bridgeclient.Message, 1000), rejects: rejects}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.mu.Lock()
+		if s.rejects > 0 {
+			s.rejects--
+			s.mu.Unlock()
+			http.Error(w, "unavailable", http.StatusServiceUnavailable)
+			return
+		}
+		s.mu.Unlock()
+		conn, err := websocket.Accept(w, r, nil)
+		if err != nil {
+			return
+		}
+		conn.SetReadLimit(64 << 20)
+		s.mu.Lock()
+		s.conn = conn
+		s.mu.Unlock()
+		for {
+			var msg bridgeclient.Message
+			if err := wsjson.Read(r.Context(), conn, &msg); err != nil {
+				return
+			}
+			select {
+			case s.received <- msg:
+			default:
+			}
+		}
+	}))
+	t.Cleanup(s.Close)
+	return s
+}
+
+func (s *rosbridgeServer) url() string {
+	return "ws" + strings.TrimPrefix(s.URL, "http")
+}
+
+func (s *rosbridgeServer) publish(ctx context.Context, topic string, msg interface{}) error {
+	data, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return wsjson.Write(ctx, s.conn, bridgeclient.Message{Op: bridgeclient.OpPublish, Topic: topic, Msg: data})
+}
+
+// waitFor returns the first message received that matches op and topic.
+func (s *rosbridgeServer) waitFor(t *testing.T, op, topic string) bridgeclient.Message {
+	t.Helper()
+	timeout := time.After(5 * time.Second)
+	for {
+		select {
+		case msg := <-s.received:
+			if msg.Op == op && msg.Topic == topic {
+				return msg
+			}
+		case <-timeout:
+			t.Fatalf("timed out waiting for %s on %s", op, topic)
+		}
+	}
+}
+
+func setupRobot(t *testing.T) (*inject.Robot, *inject.Base) {
+	t.Helper()
+	logger := golog.NewTestLogger(t)
+
+	cam := &inject.Camera{}
+	cam.StreamFunc = func(ctx context.Context, errHandlers ...gostream.ErrorHandler) (gostream.VideoStream, error) {
+		return gostream.NewEmbeddedVideoStreamFromReader(gostream.VideoReaderFunc(func(ctx context.Context) (image.Image, func(), error) {
+			img := image.NewNRGBA(image.Rect(0, 0, 2, 1))
+			img.Set(1, 0, color.NRGBA{R: 10, G: 20, B: 30, A: 255})
+			return img, func() {}, nil
+		})), nil
+	}
+
+	ms := &inject.MovementSensor{}
+	ms.LinearVelocityFunc = func(ctx context.Context, extra map[string]interface{}) (r3.Vector, error) {
+		return r3.Vector{Y: 500}, nil
+	}
+	ms.AngularVelocityFunc = func(ctx context.Context, extra map[string]interface{}) (spatialmath.AngularVelocity, error) {
+		return spatialmath.AngularVelocity{Z: 90}, nil
+	}
+	ms.OrientationFunc = func(ctx context.Context, extra map[string]interface{}) (spatialmath.Orientation, error) {
+		return nil, movementsensor.ErrMethodUnimplementedOrientation
+	}
+
+	a, err := fakearm.NewArm(config.Component{Name: "arm1"}, logger)
+	test.That(t, err, test.ShouldBeNil)
+	positions := make([]float64, len(a.ModelFrame().DoF()))
+	positions[0] = 90
+	test.That(t, a.MoveToJointPositions(context.Background(), &pb.JointPositions{Values: positions}, nil), test.ShouldBeNil)
+
+	b := &inject.Base{}
+	b.SetVelocityFunc = func(ctx context.Context, linear, angular r3.Vector, extra map[string]interface{}) error {
+		return nil
+	}
+	b.StopFunc = func(ctx context.Context, extra map[string]interface{}) error {
+		return nil
+	}
+
+	r := &inject.Robot{}
+	r.LoggerFunc = func() golog.Logger {
+		return logger
+	}
+	resources := map[resource.Name]interface{}{
+		camera.Named("cam1"):         cam,
+		movementsensor.Named("imu1"): ms,
+		arm.Named("arm1"):            a,
+		base.Named("base1"):          b,
+	}
+	r.ResourceNamesFunc = func() []resource.Name {
+		names := []resource.Name{}
+		for name := range resources {
+			names = append(names, name)
+		}
+		return names
+	}
+	r.ResourceByNameFunc = func(name resource.Name) (interface{}, error) {
+		res, ok := resources[name]
+		if !ok {
+			return nil, rutils.NewResourceNotFoundError(name)
+		}
+		return res, nil
+	}
+	r.FrameSystemConfigFunc = func(
+		ctx context.Context, additionalTransforms []*commonpb.Transform,
+	) (framesystemparts.Parts, error) {
+		return framesystemparts.Parts{
+			{
+				Name:        "cam1",
+				FrameConfig: &config.Frame{Parent: referenceframe.World, Translation: r3.Vector{X: 100, Z: 500}},
+			},
+		}, nil
+	}
+	return r, b
+}
+
+func TestValidate(t *testing.T) {
+	cfg := &Config{}
+	_, err := cfg.Validate("path")
+	test.That(t, err, test.ShouldBeError, utils.NewConfigValidationFieldRequiredError("path", "url"))
+
+	cfg = &Config{
+		URL:      "ws://localhost:9090",
+		Topics:   []PublishConfig{{Resource: "cam1", Kind: "image", Topic: "/image"}},
+		BaseName: "base1",
+	}
+	deps, err := cfg.Validate("path")
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, deps, test.ShouldResemble, []string{"cam1", "base1"})
+
+	cfg.Topics = append(cfg.Topics, PublishConfig{Resource: "cam1", Kind: "depth", Topic: "/depth"})
+	_, err = cfg.Validate("path")
+	test.That(t, err, test.ShouldBeError)
+	test.That(t, err.Error(), test.ShouldContainSubstring, "kind")
+
+	cfg.Topics[1] = PublishConfig{Resource: "cam1", Kind: "point_cloud", Topic: "/image"}
+	_, err = cfg.Validate("path")
+	test.That(t, err, test.ShouldBeError)
+	test.That(t, err.Error(), test.ShouldContainSubstring, "more than once")
+}
+
+func TestBridge(t *testing.T) {
+	ctx := context.Background()
+	logger := golog.NewTestLogger(t)
+	server := newRosbridgeServer(t, 0)
+	r, b := setupRobot(t)
+
+	var mu sync.Mutex
+	var velocities []r3.Vector
+	stopped := make(chan struct{}, 1)
+	b.SetVelocityFunc = func(ctx context.Context, linear, angular r3.Vector, extra map[string]interface{}) error {
+		mu.Lock()
+		defer mu.Unlock()
+		velocities = append(velocities, linear, angular)
+		return nil
+	}
+	b.StopFunc = func(ctx context.Context, extra map[string]interface{}) error {
+		select {
+		case stopped <- struct{}{}:
+		default:
+		}
+		return nil
+	}
+
+	cfg := &Config{
+		URL:    server.url(),
+		RateHz: 50,
+		Topics: []PublishConfig{
+			{Resource: "cam1", Kind: kindImage, Topic: "/cam1/image_raw"},
+			{Resource: "imu1", Kind: kindIMU, Topic: "/imu", FrameID: "imu_link"},
+			{Resource: "imu1", Kind: kindOdometry, Topic: "/odom"},
+			{Resource: "arm1", Kind: kindJointStates, Topic: "/joint_states"},
+		},
+		PublishTF:       true,
+		BaseName:        "base1",
+		CmdVelTimeoutMs: 100,
+	}
+	svc, err := NewBuiltIn(ctx, r, config.Service{Name: "bridge", Type: "rosbridge", ConvertedAttributes: cfg}, logger)
+	test.That(t, err, test.ShouldBeNil)
+	defer func() {
+		test.That(t, utils.TryClose(ctx, svc), test.ShouldBeNil)
+	}()
+	test.That(t, svc.Topics(), test.ShouldResemble, map[string]string{
+		"/cam1/image_raw": ros.ImageType,
+		"/imu":            ros.ImuType,
+		"/odom":           ros.OdometryType,
+		"/joint_states":   ros.JointStateType,
+		"/tf":             ros.TFMessageType,
+	})
+
+	advertised := server.waitFor(t, bridgeclient.OpAdvertise, "/joint_states")
+	test.That(t, advertised.Type, test.ShouldEqual, ros.JointStateType)
+	subscribed := server.waitFor(t, bridgeclient.OpSubscribe, "/cmd_vel")
+	test.That(t, subscribed.Type, test.ShouldEqual, ros.TwistType)
+
+	t.Run("image", func(t *testing.T) {
+		var img ros.Image
+		test.That(t, json.Unmarshal(server.waitFor(t, bridgeclient.OpPublish, "/cam1/image_raw").Msg, &img), test.ShouldBeNil)
+		test.That(t, img.Header.FrameID, test.ShouldEqual, "cam1")
+		test.That(t, img.Encoding, test.ShouldEqual, "rgb8")
+		test.That(t, img.Data, test.ShouldResemble, []byte{0, 0, 0, 10, 20, 30})
+	})
+
+	t.Run("imu", func(t *testing.T) {
+		var imu ros.ImuData
+		test.That(t, json.Unmarshal(server.waitFor(t, bridgeclient.OpPublish, "/imu").Msg, &imu), test.ShouldBeNil)
+		test.That(t, imu.Header.FrameID, test.ShouldEqual, "imu_link")
+		test.That(t, imu.OrientationCovariance[0], test.ShouldEqual, -1)
+		test.That(t, imu.LinearAccelerationCovariance[0], test.ShouldEqual, -1)
+		test.That(t, imu.AngularVelocity.Z, test.ShouldAlmostEqual, math.Pi/2)
+	})
+
+	t.Run("odometry", func(t *testing.T) {
+		var odom ros.Odometry
+		test.That(t, json.Unmarshal(server.waitFor(t, bridgeclient.OpPublish, "/odom").Msg, &odom), test.ShouldBeNil)
+		test.That(t, odom.Header.FrameID, test.ShouldEqual, odomFrame)
+		test.That(t, odom.ChildFrameID, test.ShouldEqual, "imu1")
+		test.That(t, odom.Twist.Twist.Linear, test.ShouldResemble, ros.Vector3{Y: 0.5})
+		test.That(t, odom.Pose.Pose.Orientation, test.ShouldResemble, ros.Quaternion{W: 1})
+	})
+
+	t.Run("joint states", func(t *testing.T) {
+		var joints ros.JointState
+		test.That(t, json.Unmarshal(server.waitFor(t, bridgeclient.OpPublish, "/joint_states").Msg, &joints), test.ShouldBeNil)
+		test.That(t, joints.Position, test.ShouldHaveLength, len(joints.Name))
+		test.That(t, joints.Position[0], test.ShouldAlmostEqual, math.Pi/2)
+		test.That(t, joints.Name[0], test.ShouldNotContainSubstring, "_joint_")
+	})
+
+	t.Run("tf", func(t *testing.T) {
+		var tf ros.TFMessage
+		test.That(t, json.Unmarshal(server.waitFor(t, bridgeclient.OpPublish, "/tf").Msg, &tf), test.ShouldBeNil)
+		// The offset of a component is the transform of its origin frame from its parent.
+		var cam *ros.TransformStamped
+		for i, transform := range tf.Transforms {
+			if transform.ChildFrameID == "cam1_origin" {
+				cam = &tf.Transforms[i]
+			}
+		}
+		test.That(t, cam, test.ShouldNotBeNil)
+		test.That(t, cam.Header.FrameID, test.ShouldEqual, referenceframe.World)
+		test.That(t, cam.Transform.Translation.X, test.ShouldAlmostEqual, 0.1)
+		test.That(t, cam.Transform.Translation.Z, test.ShouldAlmostEqual, 0.5)
+		test.That(t, cam.Transform.Rotation.W, test.ShouldAlmostEqual, 1)
+	})
+
+	t.Run("cmd_vel", func(t *testing.T) {
+		twist := ros.Twist{Linear: ros.Vector3{X: 0.25}, Angular: ros.Vector3{Z: math.Pi}}
+		test.That(t, server.publish(ctx, "/cmd_vel", twist), test.ShouldBeNil)
+		select {
+		case <-stopped:
+		case <-time.After(5 * time.Second):
+			t.Fatal("base was not stopped after cmd_vel timeout")
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		test.That(t, velocities, test.ShouldHaveLength, 2)
+		test.That(t, velocities[0], test.ShouldResemble, r3.Vector{Y: 250})
+		test.That(t, velocities[1].Z, test.ShouldAlmostEqual, 180)
+	})
+}
+
+func TestReconnect(t *testing.T) {
+	ctx := context.Background()
+	logger := golog.NewTestLogger(t)
+	server := newRosbridgeServer(t, 2)
+	r, _ := setupRobot(t)
+
+	cfg := &Config{URL: server.url(), Topics: []PublishConfig{{Resource: "missing", Kind: kindIMU, Topic: "/imu"}}, PublishTF: true}
+	svc, err := NewBuiltIn(ctx, r, config.Service{Name: "bridge", Type: "rosbridge", ConvertedAttributes: cfg}, logger)
+	test.That(t, err, test.ShouldBeNil)
+	defer func() {
+		test.That(t, utils.TryClose(ctx, svc), test.ShouldBeNil)
+	}()
+
+	// The service keeps connecting until the server accepts, and a missing resource does not stop the other topics
+	// from being published.
+	server.waitFor(t, bridgeclient.OpAdvertise, "/imu")
+	server.waitFor(t, bridgeclient.OpPublish, "/tf")
+}
+
+func TestCmdVelTimeoutWhilePublishing(t *testing.T) {
+	ctx := context.Background()
+	logger := golog.NewTestLogger(t)
+	server := newRosbridgeServer(t, 0)
+	r, b := setupRobot(t)
+
+	// The camera does not return an image until it is released, so publishing blocks until then.
+	release := make(chan struct{})
+	cam, err := camera.FromRobot(r, "cam1")
+	test.That(t, err, test.ShouldBeNil)
+	cam.(*inject.Camera).StreamFunc = func(ctx context.Context, errHandlers ...gostream.ErrorHandler) (gostream.VideoStream, error) {
+		return gostream.NewEmbeddedVideoStreamFromReader(gostream.VideoReaderFunc(func(ctx context.Context) (image.Image, func(), error) {
+			<-release
+			return nil, nil, errors.New("released")
+		})), nil
+	}
+	stopped := make(chan time.Time, 1)
+	b.StopFunc = func(ctx context.Context, extra map[string]interface{}) error {
+		select {
+		case stopped <- time.Now():
+		default:
+		}
+		return nil
+	}
+
+	const timeout = 100 * time.Millisecond
+	cfg := &Config{
+		URL:             server.url(),
+		Topics:          []PublishConfig{{Resource: "cam1", Kind: kindImage, Topic: "/cam1/image_raw"}},
+		BaseName:        "base1",
+		CmdVelTimeoutMs: int(timeout / time.Millisecond),
+	}
+	svc, err := NewBuiltIn(ctx, r, config.Service{Name: "bridge", Type: "rosbridge", ConvertedAttributes: cfg}, logger)
+	test.That(t, err, test.ShouldBeNil)
+	defer func() {
+		close(release)
+		test.That(t, utils.TryClose(ctx, svc), test.ShouldBeNil)
+	}()
+
+	server.waitFor(t, bridgeclient.OpSubscribe, "/cmd_vel")
+	sent := time.Now()
+	test.That(t, server.publish(ctx, "/cmd_vel", ros.Twist{Linear: ros.Vector3{X: 0.25}}), test.ShouldBeNil)
+	select {
+	case at := <-stopped:
+		test.That(t, at.Sub(sent), test.ShouldBeGreaterThanOrEqualTo, timeout)
+		test.That(t, at.Sub(sent), test.ShouldBeLessThan, 3*timeout)
+	case <-time.After(time.Second):
+		t.Fatal("base was not stopped after cmd_vel timeout while publishing was blocked")
+	}
+}
@@ -0,0 +1,7 @@
+// Package register registers all relevant rosbridge models and also subtype specific functions
+package register
+
+import (
+	// for rosbridge models.
+	_ "go.viam.com/rdk/services/rosbridge/builtin"
+)
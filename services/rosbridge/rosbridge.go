@@ -0,0 +1,97 @@
+// Package rosbridge implements a bridge between the resources of a robot and ROS through a rosbridge server.
+package rosbridge
+
+import (
+	"context"
+	"sync"
+
+	"github.com/edaniels/golog"
+	viamutils "go.viam.com/utils"
+
+	"go.viam.com/rdk/registry"
+	"go.viam.com/rdk/resource"
+	"go.viam.com/rdk/utils"
+)
+
+// SubtypeName is the name of the type of service.
+const SubtypeName = resource.SubtypeName("rosbridge")
+
+// Subtype is a constant that identifies the rosbridge resource subtype.
+var Subtype = resource.NewSubtype(
+	resource.ResourceNamespaceRDK,
+	resource.ResourceTypeService,
+	SubtypeName,
+)
+
+// Named is a helper for getting the named rosbridge service's typed resource name.
+func Named(name string) resource.Name {
+	return resource.NameFromSubtype(Subtype, name)
+}
+
+func init() {
+	registry.RegisterResourceSubtype(Subtype, registry.ResourceSubtype{
+		Reconfigurable: WrapWithReconfigurable,
+	})
+}
+
+var (
+	_ = resource.Reconfigurable(&reconfigurableRosbridge{})
+	_ = viamutils.ContextCloser(&reconfigurableRosbridge{})
+)
+
+// A Service publishes resources of a robot as ROS topics and drives a base from the velocity commands of a ROS topic.
+type Service interface {
+	// Topics returns the topics published to ROS and the types of their messages.
+	Topics() map[string]string
+	// Close disconnects from the rosbridge server and stops the base.
+	Close(ctx context.Context) error
+}
+
+type reconfigurableRosbridge struct {
+	mu     sync.RWMutex
+	name   resource.Name
+	actual Service
+}
+
+func (svc *reconfigurableRosbridge) Name() resource.Name {
+	return svc.name
+}
+
+func (svc *reconfigurableRosbridge) Topics() map[string]string {
+	svc.mu.RLock()
+	defer svc.mu.RUnlock()
+	return svc.actual.Topics()
+}
+
+func (svc *reconfigurableRosbridge) Close(ctx context.Context) error {
+	svc.mu.RLock()
+	defer svc.mu.RUnlock()
+	return viamutils.TryClose(ctx, svc.actual)
+}
+
+func (svc *reconfigurableRosbridge) Reconfigure(ctx context.Context, newSvc resource.Reconfigurable) error {
+	svc.mu.Lock()
+	defer svc.mu.Unlock()
+	rSvc, ok := newSvc.(*reconfigurableRosbridge)
+	if !ok {
+		return utils.NewUnexpectedTypeError(svc, newSvc)
+	}
+	if err := viamutils.TryClose(ctx, svc.actual); err != nil {
+		golog.Global().Errorw("error closing old", "error", err)
+	}
+	svc.actual = rSvc.actual
+	return nil
+}
+
+// WrapWithReconfigurable wraps a rosbridge service as a Reconfigurable.
+func WrapWithReconfigurable(s interface{}, name resource.Name) (resource.Reconfigurable, error) {
+	if reconfigurable, ok := s.(*reconfigurableRosbridge); ok {
+		return reconfigurable, nil
+	}
+	svc, ok := s.(Service)
+	if !ok {
+		return nil, utils.NewUnimplementedInterfaceError("rosbridge.Service", s)
+	}
+
+	return &reconfigurableRosbridge{name: name, actual: svc}, nil
+}
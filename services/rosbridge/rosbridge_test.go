@@ -0,0 +1,65 @@
+package rosbridge_test
+
+import (
+	"context"
+	"testing"
+
+	"go.viam.com/test"
+
+	"go.viam.com/rdk/registry"
+	"go.viam.com/rdk/resource"
+	"go.viam.com/rdk/services/rosbridge"
+	rutils "go.viam.com/rdk/utils"
+)
+
+func TestRegisteredReconfigurable(t *testing.T) {
+	s := registry.ResourceSubtypeLookup(rosbridge.Subtype)
+	test.That(t, s, test.ShouldNotBeNil)
+	r := s.Reconfigurable
+	test.That(t, r, test.ShouldNotBeNil)
+}
+
+func TestWrapWithReconfigurable(t *testing.T) {
+	actualSvc := &mock{}
+	reconfSvc, err := rosbridge.WrapWithReconfigurable(actualSvc, resource.Name{})
+	test.That(t, err, test.ShouldBeNil)
+
+	_, err = rosbridge.WrapWithReconfigurable(nil, resource.Name{})
+	test.That(t, err, test.ShouldBeError, rutils.NewUnimplementedInterfaceError("rosbridge.Service", nil))
+
+	reconfSvc2, err := rosbridge.WrapWithReconfigurable(reconfSvc, resource.Name{})
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, reconfSvc2, test.ShouldEqual, reconfSvc)
+	test.That(t, reconfSvc.(rosbridge.Service).Topics(), test.ShouldResemble, map[string]string{"/tf": "tf2_msgs/TFMessage"})
+}
+
+func TestReconfigure(t *testing.T) {
+	actualSvc := &mock{}
+	reconfSvc, err := rosbridge.WrapWithReconfigurable(actualSvc, resource.Name{})
+	test.That(t, err, test.ShouldBeNil)
+
+	actualSvc2 := &mock{}
+	reconfSvc2, err := rosbridge.WrapWithReconfigurable(actualSvc2, resource.Name{})
+	test.That(t, err, test.ShouldBeNil)
+
+	err = reconfSvc.Reconfigure(context.Background(), reconfSvc2)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, reconfSvc, test.ShouldResemble, reconfSvc2)
+	test.That(t, actualSvc.closeCount, test.ShouldEqual, 1)
+
+	err = reconfSvc.Reconfigure(context.Background(), nil)
+	test.That(t, err, test.ShouldBeError, rutils.NewUnexpectedTypeError(reconfSvc, nil))
+}
+
+type mock struct {
+	closeCount int
+}
+
+func (m *mock) Topics() map[string]string {
+	return map[string]string{"/tf": "tf2_msgs/TFMessage"}
+}
+
+func (m *mock) Close(ctx context.Context) error {
+	m.closeCount++
+	return nil
+}
@@ -0,0 +1,55 @@
+package sensors
+
+import (
+	"context"
+
+	"google.golang.org/protobuf/types/known/anypb"
+
+	"go.viam.com/rdk/data"
+)
+
+type method int64
+
+const (
+	readings method = iota
+)
+
+func (m method) String() string {
+	if m == readings {
+		return "Readings"
+	}
+	return "Unknown"
+}
+
+// newReadingsCollector captures the readings of all sensors of the service, by the short name of each sensor.
+func newReadingsCollector(resource interface{}, params data.CollectorParams) (data.Collector, error) {
+	svc, err := assertSensors(resource)
+	if err != nil {
+		return nil, err
+	}
+
+	cFunc := data.CaptureFunc(func(ctx context.Context, _ map[string]*anypb.Any) (interface{}, error) {
+		names, err := svc.Sensors(ctx, nil)
+		if err != nil {
+			return nil, data.FailedToReadErr(params.ComponentName, readings.String(), err)
+		}
+		all, err := svc.Readings(ctx, names, nil)
+		if err != nil {
+			return nil, data.FailedToReadErr(params.ComponentName, readings.String(), err)
+		}
+		v := make(map[string]interface{}, len(all))
+		for _, r := range all {
+			v[r.Name.ShortName()] = r.Readings
+		}
+		return v, nil
+	})
+	return data.NewCollector(cFunc, params)
+}
+
+func assertSensors(resource interface{}) (Service, error) {
+	svc, ok := resource.(Service)
+	if !ok {
+		return nil, data.InvalidInterfaceErr(SubtypeName)
+	}
+	return svc, nil
+}
@@ -0,0 +1,43 @@
+package sensors_test
+
+import (
+	"context"
+	"testing"
+
+	"go.viam.com/test"
+
+	"go.viam.com/rdk/components/movementsensor"
+	"go.viam.com/rdk/components/sensor"
+	"go.viam.com/rdk/data"
+	"go.viam.com/rdk/resource"
+	"go.viam.com/rdk/services/sensors"
+	"go.viam.com/rdk/testutils"
+	"go.viam.com/rdk/testutils/inject"
+)
+
+func TestReadingsCollector(t *testing.T) {
+	names := []resource.Name{movementsensor.Named("gps"), sensor.Named("thermometer")}
+	svc := &inject.SensorsService{}
+	svc.SensorsFunc = func(ctx context.Context, extra map[string]interface{}) ([]resource.Name, error) {
+		return names, nil
+	}
+	svc.ReadingsFunc = func(ctx context.Context, resources []resource.Name, extra map[string]interface{}) ([]sensors.Readings, error) {
+		test.That(t, resources, test.ShouldResemble, names)
+		return []sensors.Readings{
+			{Name: names[0], Readings: map[string]interface{}{"speed": 2.5}},
+			{Name: names[1], Readings: map[string]interface{}{"temperature": 21.5}},
+		}, nil
+	}
+	method := data.MethodMetadata{Subtype: sensors.SubtypeName, MethodName: "Readings"}
+
+	// Readings are captured by the short name of each sensor.
+	reading, err := testutils.CaptureReading(t, method, svc, nil)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, reading.GetStruct().AsMap(), test.ShouldResemble, map[string]interface{}{
+		"gps":         map[string]interface{}{"speed": 2.5},
+		"thermometer": map[string]interface{}{"temperature": 21.5},
+	})
+
+	_, err = testutils.CaptureReading(t, method, "not a sensors service", nil)
+	test.That(t, err, test.ShouldNotBeNil)
+}
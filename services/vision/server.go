@@ -13,6 +13,7 @@ import (
 	"go.viam.com/rdk/config"
 	"go.viam.com/rdk/pointcloud"
 	"go.viam.com/rdk/rimage"
+	"go.viam.com/rdk/spatialmath"
 	"go.viam.com/rdk/subtype"
 	"go.viam.com/rdk/utils"
 	"go.viam.com/rdk/vision"
@@ -412,10 +413,14 @@ func segmentsToProto(frame string, segs []*vision.Object) ([]*commonpb.PointClou
 		if err != nil {
 			return nil, err
 		}
+		geometry, err := spatialmath.GeometryToProtobuf(seg.Geometry)
+		if err != nil {
+			return nil, err
+		}
 		ps := &commonpb.PointCloudObject{
 			PointCloud: buf.Bytes(),
 			Geometries: &commonpb.GeometriesInFrame{
-				Geometries:     []*commonpb.Geometry{seg.Geometry.ToProtobuf()},
+				Geometries:     []*commonpb.Geometry{geometry},
 				ReferenceFrame: frame,
 			},
 		}
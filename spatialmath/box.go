@@ -148,6 +148,15 @@ func (b *box) CollidesWith(g Geometry) (bool, error) {
 	if other, ok := g.(*point); ok {
 		return pointVsBoxCollision(b, other.pose.Point()), nil
 	}
+	if other, ok := g.(*capsule); ok {
+		return other.CollidesWith(b)
+	}
+	if other, ok := g.(*cylinder); ok {
+		return other.CollidesWith(b)
+	}
+	if other, ok := g.(*mesh); ok {
+		return other.CollidesWith(b)
+	}
 	return true, newCollisionTypeUnsupportedError(b, g)
 }
 
@@ -161,6 +170,15 @@ func (b *box) DistanceFrom(g Geometry) (float64, error) {
 	if other, ok := g.(*point); ok {
 		return pointVsBoxDistance(b, other.pose.Point()), nil
 	}
+	if other, ok := g.(*capsule); ok {
+		return other.DistanceFrom(b)
+	}
+	if other, ok := g.(*cylinder); ok {
+		return other.DistanceFrom(b)
+	}
+	if other, ok := g.(*mesh); ok {
+		return other.DistanceFrom(b)
+	}
 	return math.Inf(-1), newCollisionTypeUnsupportedError(b, g)
 }
 
@@ -174,6 +192,15 @@ func (b *box) EncompassedBy(g Geometry) (bool, error) {
 	if _, ok := g.(*point); ok {
 		return false, nil
 	}
+	if other, ok := g.(*mesh); ok {
+		return convexInMesh(b, b.pose.Point(), other), nil
+	}
+	if _, ok := g.(*capsule); ok {
+		return pointsInGeometry(b.Vertices(), 0, g)
+	}
+	if _, ok := g.(*cylinder); ok {
+		return pointsInGeometry(b.Vertices(), 0, g)
+	}
 	return false, newCollisionTypeUnsupportedError(b, g)
 }
 
@@ -0,0 +1,189 @@
+package spatialmath
+
+import (
+	"encoding/json"
+	"math"
+
+	"github.com/golang/geo/r3"
+	commonpb "go.viam.com/api/common/v1"
+
+	"go.viam.com/rdk/utils"
+)
+
+// capsuleCreator implements the GeometryCreator interface for capsule structs.
+type capsuleCreator struct {
+	radius float64
+	length float64
+	pointCreator
+}
+
+// capsule is a collision geometry that represents a cylinder capped with hemispheres, it has a pose, a radius and a length that
+// fully define it. The capsule is centered at its pose and runs along its Z axis, with the length measured between the tips of
+// its caps.
+type capsule struct {
+	pose   Pose
+	radius float64
+	length float64
+	label  string
+
+	// segA and segB are the endpoints of the segment which the capsule sweeps a sphere along
+	segA r3.Vector
+	segB r3.Vector
+}
+
+// NewCapsuleCreator instantiates a CapsuleCreator class, which allows instantiating capsules given only a pose which is applied
+// at the specified offset from the pose. These capsules have a radius and a length, measured between the tips of their caps,
+// specified by the radius and length arguments.
+func NewCapsuleCreator(radius, length float64, offset Pose, label string) (GeometryCreator, error) {
+	if radius <= 0 || length < 2*radius {
+		return nil, newBadGeometryDimensionsError(&capsule{})
+	}
+	return &capsuleCreator{radius, length, pointCreator{offset, label}}, nil
+}
+
+// NewGeometry instantiates a new capsule from a CapsuleCreator class.
+func (cc *capsuleCreator) NewGeometry(pose Pose) Geometry {
+	return newCapsule(Compose(cc.offset, pose), cc.radius, cc.length, cc.label)
+}
+
+func (cc *capsuleCreator) MarshalJSON() ([]byte, error) {
+	config, err := NewGeometryConfig(cc)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(config)
+}
+
+// NewCapsule instantiates a new capsule Geometry.
+func NewCapsule(pose Pose, radius, length float64, label string) (Geometry, error) {
+	if radius < 0 || length < 2*radius {
+		return nil, newBadGeometryDimensionsError(&capsule{})
+	}
+	return newCapsule(pose, radius, length, label), nil
+}
+
+func newCapsule(pose Pose, radius, length float64, label string) *capsule {
+	halfSegment := pose.Orientation().RotationMatrix().Row(2).Mul(length/2 - radius)
+	return &capsule{
+		pose:   pose,
+		radius: radius,
+		length: length,
+		label:  label,
+		segA:   pose.Point().Sub(halfSegment),
+		segB:   pose.Point().Add(halfSegment),
+	}
+}
+
+// Label returns the label of this capsule.
+func (c *capsule) Label() string {
+	if c != nil {
+		return c.label
+	}
+	return ""
+}
+
+// Pose returns the pose of the capsule.
+func (c *capsule) Pose() Pose {
+	return c.pose
+}
+
+// Vertices returns the endpoints of the segment at the core of the capsule (the bounding geometry of a capsule cannot be described
+// by a finite number of points, so these points along with the known radius should be used).
+func (c *capsule) Vertices() []r3.Vector {
+	return []r3.Vector{c.segA, c.segB}
+}
+
+// AlmostEqual compares the capsule with another geometry and checks if they are equivalent.
+func (c *capsule) AlmostEqual(g Geometry) bool {
+	other, ok := g.(*capsule)
+	if !ok {
+		return false
+	}
+	return PoseAlmostEqual(c.pose, other.pose) &&
+		utils.Float64AlmostEqual(c.radius, other.radius, 1e-8) &&
+		utils.Float64AlmostEqual(c.length, other.length, 1e-8)
+}
+
+// Transform premultiplies the capsule pose with a transform, allowing the capsule to be moved in space.
+func (c *capsule) Transform(toPremultiply Pose) Geometry {
+	return newCapsule(Compose(toPremultiply, c.pose), c.radius, c.length, c.label)
+}
+
+// ToProtobuf returns nil, as the API has no message for capsules. They are not sent as the boxes that bound them, since they
+// would be checked for collisions as the larger geometries the boxes are. GeometryToProtobuf reports this as an error.
+func (c *capsule) ToProtobuf() *commonpb.Geometry {
+	return nil
+}
+
+// CollidesWith checks if the given capsule collides with the given geometry and returns true if it does.
+func (c *capsule) CollidesWith(g Geometry) (bool, error) {
+	distance, err := c.DistanceFrom(g)
+	if err != nil {
+		return true, err
+	}
+	return distance <= CollisionBuffer, nil
+}
+
+// DistanceFrom returns the distance between the capsule and the given geometry, which is negative if they are in collision.
+func (c *capsule) DistanceFrom(g Geometry) (float64, error) {
+	switch other := g.(type) {
+	case *point:
+		return capsuleVsPointDistance(c, other.pose.Point()), nil
+	case *sphere:
+		return capsuleVsPointDistance(c, other.pose.Point()) - other.radius, nil
+	case *box:
+		return convexDistance(c, other), nil
+	case *capsule:
+		return convexDistance(c, other), nil
+	case *cylinder:
+		return convexDistance(c, other), nil
+	case *mesh:
+		return meshVsConvexDistance(other, c, c.pose.Point()), nil
+	}
+	return math.Inf(-1), newCollisionTypeUnsupportedError(c, g)
+}
+
+// EncompassedBy returns a bool describing if the given capsule is completely encompassed by the given geometry.
+func (c *capsule) EncompassedBy(g Geometry) (bool, error) {
+	if _, ok := g.(*point); ok {
+		return false, nil
+	}
+	if other, ok := g.(*mesh); ok {
+		return convexInMesh(c, c.pose.Point(), other), nil
+	}
+	return pointsInGeometry(c.Vertices(), c.radius, g)
+}
+
+func (c *capsule) support(direction r3.Vector) r3.Vector {
+	if c.segA.Dot(direction) > c.segB.Dot(direction) {
+		return c.segA
+	}
+	return c.segB
+}
+
+func (c *capsule) margin() float64 {
+	return c.radius
+}
+
+// capsuleVsPointDistance takes a capsule and a point as arguments and returns a floating point number. If this number is
+// nonpositive it represents the penetration depth of the point within the capsule. If the returned float is positive it
+// represents the separation distance between the point and the capsule, which are not in collision.
+func capsuleVsPointDistance(c *capsule, pt r3.Vector) float64 {
+	closest, _ := closestPointSegment(pt, c.segA, c.segB)
+	return closest.Sub(pt).Norm() - c.radius
+}
+
+// pointsInGeometry returns a bool describing if every one of the given points, grown into a sphere of the given radius, is
+// encompassed by the given convex geometry. Because the geometry is convex this holds for the convex hull of the points as well.
+func pointsInGeometry(points []r3.Vector, radius float64, g Geometry) (bool, error) {
+	for _, pt := range points {
+		distance, err := NewPoint(pt, "").DistanceFrom(g)
+		if err != nil {
+			return false, err
+		}
+		if distance > CollisionBuffer-radius {
+			return false, nil
+		}
+	}
+	return true, nil
+}
@@ -0,0 +1,51 @@
+package spatialmath
+
+import (
+	"math"
+	"testing"
+
+	"github.com/golang/geo/r3"
+	"go.viam.com/test"
+)
+
+func makeTestCapsule(o Orientation, point r3.Vector, radius, length float64, label string) Geometry {
+	capsule, _ := NewCapsule(NewPoseFromOrientation(point, o), radius, length, label)
+	return capsule
+}
+
+func TestNewCapsule(t *testing.T) {
+	offset := NewPoseFromOrientation(r3.Vector{X: 1, Y: 0, Z: 0}, &EulerAngles{0, math.Pi / 2, 0})
+
+	// test capsule created from NewCapsule method
+	geometry, err := NewCapsule(offset, 1, 4, "")
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, geometry.(*capsule).radius, test.ShouldEqual, 1)
+	test.That(t, geometry.(*capsule).length, test.ShouldEqual, 4)
+	_, err = NewCapsule(offset, 1, 1, "")
+	test.That(t, err.Error(), test.ShouldContainSubstring, newBadGeometryDimensionsError(&capsule{}).Error())
+	_, err = NewCapsuleCreator(0, 1, offset, "")
+	test.That(t, err.Error(), test.ShouldContainSubstring, newBadGeometryDimensionsError(&capsule{}).Error())
+
+	// test capsule created from GeometryCreator with offset
+	gc, err := NewCapsuleCreator(1, 4, offset, "")
+	test.That(t, err, test.ShouldBeNil)
+	geometry = gc.NewGeometry(PoseInverse(offset))
+	test.That(t, PoseAlmostCoincident(geometry.Pose(), NewZeroPose()), test.ShouldBeTrue)
+}
+
+func TestCapsuleAlmostEqual(t *testing.T) {
+	original := makeTestCapsule(NewZeroOrientation(), r3.Vector{}, 1, 4, "")
+	good := makeTestCapsule(NewZeroOrientation(), r3.Vector{1e-16, 1e-16, 1e-16}, 1+1e-16, 4+1e-16, "")
+	bad := makeTestCapsule(NewZeroOrientation(), r3.Vector{1e-2, 1e-2, 1e-2}, 1+1e-2, 4, "")
+	test.That(t, original.AlmostEqual(good), test.ShouldBeTrue)
+	test.That(t, original.AlmostEqual(bad), test.ShouldBeFalse)
+}
+
+func TestCapsuleVertices(t *testing.T) {
+	// the segment at the core of the capsule ends where the caps begin
+	capsule := makeTestCapsule(&EulerAngles{0, math.Pi / 2, 0}, r3.Vector{1, 0, 0}, 1, 4, "")
+	vertices := capsule.Vertices()
+	test.That(t, vertices, test.ShouldHaveLength, 2)
+	test.That(t, R3VectorAlmostEqual(vertices[0], r3.Vector{0, 0, 0}, 1e-8), test.ShouldBeTrue)
+	test.That(t, R3VectorAlmostEqual(vertices[1], r3.Vector{2, 0, 0}, 1e-8), test.ShouldBeTrue)
+}
@@ -0,0 +1,276 @@
+package spatialmath
+
+import (
+	"math"
+
+	"github.com/golang/geo/r3"
+)
+
+const (
+	// gjkMaxIterations bounds the number of simplex refinements done by gjkDistance, curved shapes converge asymptotically.
+	gjkMaxIterations = 64
+	// gjkTolerance is the relative improvement of the distance estimate below which gjkDistance stops iterating.
+	gjkTolerance = 1e-9
+)
+
+// convex is a convex collision shape described by the support function of a core shape that is swept by a sphere with
+// a radius given by its margin, as for example a capsule is a segment swept by a sphere.
+type convex interface {
+	// support returns the point of the core shape that lies furthest in the given direction.
+	support(direction r3.Vector) r3.Vector
+	margin() float64
+}
+
+// penetrationDirections are the directions along which the depth of intersecting convex shapes is estimated.
+var penetrationDirections = func() []r3.Vector {
+	directions := make([]r3.Vector, 0, 26)
+	for _, x := range []float64{-1, 0, 1} {
+		for _, y := range []float64{-1, 0, 1} {
+			for _, z := range []float64{-1, 0, 1} {
+				if x != 0 || y != 0 || z != 0 {
+					directions = append(directions, r3.Vector{X: x, Y: y, Z: z}.Normalize())
+				}
+			}
+		}
+	}
+	return directions
+}()
+
+// convexDistance takes two convex shapes and returns a floating point number. If this number is nonpositive it represents
+// an estimate of the penetration depth of the two shapes, which are in collision. If the returned float is positive it
+// represents the separation distance between the shapes, which are not in collision.
+func convexDistance(a, b convex) float64 {
+	if distance := gjkDistance(a, b); distance > 0 {
+		return distance - a.margin() - b.margin()
+	}
+	return -(penetrationDepth(a, b) + a.margin() + b.margin())
+}
+
+// penetrationDepth returns an upper bound on the distance the cores of two intersecting convex shapes need to be moved
+// apart to no longer intersect, found by measuring the extent of their Minkowski difference along a fixed set of directions.
+// reference: https://en.wikipedia.org/wiki/Support_function
+func penetrationDepth(a, b convex) float64 {
+	depth := math.Inf(1)
+	for _, direction := range penetrationDirections {
+		extent := a.support(direction).Sub(b.support(direction.Mul(-1))).Dot(direction)
+		if extent < depth {
+			depth = extent
+		}
+	}
+	return math.Max(depth, 0)
+}
+
+// gjkDistance returns the distance between the cores of two convex shapes, or 0 if they intersect, by using the
+// Gilbert-Johnson-Keerthi algorithm to find the point of their Minkowski difference that is closest to the origin.
+// references: https://en.wikipedia.org/wiki/Gilbert%E2%80%93Johnson%E2%80%93Keerthi_distance_algorithm
+//
+//	https://graphics.stanford.edu/courses/cs448b-00-winter/papers/gilbert.pdf
+func gjkDistance(a, b convex) float64 {
+	support := func(direction r3.Vector) r3.Vector {
+		return a.support(direction).Sub(b.support(direction.Mul(-1)))
+	}
+	closest := support(r3.Vector{X: 1})
+	simplex := []r3.Vector{closest}
+	for i := 0; i < gjkMaxIterations; i++ {
+		norm2 := closest.Norm2()
+		if norm2 <= CollisionBuffer*CollisionBuffer {
+			return 0
+		}
+		w := support(closest.Mul(-1))
+		if norm2-closest.Dot(w) <= gjkTolerance*norm2 {
+			break
+		}
+		simplex = append(simplex, w)
+		closest, simplex = closestSimplexPoint(simplex)
+		if len(simplex) == 4 {
+			// the origin is enclosed by a tetrahedron of the Minkowski difference
+			return 0
+		}
+	}
+	return closest.Norm()
+}
+
+// closestSimplexPoint returns the point of a simplex of up to four points that is closest to the origin, along with the
+// smallest subset of the simplex that still contains that point.
+func closestSimplexPoint(simplex []r3.Vector) (r3.Vector, []r3.Vector) {
+	origin := r3.Vector{}
+	switch len(simplex) {
+	case 1:
+		return simplex[0], simplex
+	case 2:
+		return closestPointSegment(origin, simplex[0], simplex[1])
+	case 3:
+		return closestPointTriangle(origin, simplex[0], simplex[1], simplex[2])
+	default:
+		return closestPointTetrahedron(origin, simplex[0], simplex[1], simplex[2], simplex[3])
+	}
+}
+
+// closestPointSegment returns the point of the segment ab closest to p, along with the endpoints of the segment that
+// are needed to express it.
+func closestPointSegment(p, a, b r3.Vector) (r3.Vector, []r3.Vector) {
+	ab := b.Sub(a)
+	length2 := ab.Norm2()
+	if length2 == 0 {
+		return a, []r3.Vector{a}
+	}
+	t := p.Sub(a).Dot(ab) / length2
+	switch {
+	case t <= 0:
+		return a, []r3.Vector{a}
+	case t >= 1:
+		return b, []r3.Vector{b}
+	default:
+		return a.Add(ab.Mul(t)), []r3.Vector{a, b}
+	}
+}
+
+// closestPointTriangle returns the point of the triangle abc closest to p, along with the vertices of the triangle that
+// are needed to express it.
+// reference: Christer Ericson, Real-Time Collision Detection, section 5.1.5.
+func closestPointTriangle(p, a, b, c r3.Vector) (r3.Vector, []r3.Vector) {
+	ab := b.Sub(a)
+	ac := c.Sub(a)
+	ap := p.Sub(a)
+	d1 := ab.Dot(ap)
+	d2 := ac.Dot(ap)
+	if d1 <= 0 && d2 <= 0 {
+		return a, []r3.Vector{a}
+	}
+	bp := p.Sub(b)
+	d3 := ab.Dot(bp)
+	d4 := ac.Dot(bp)
+	if d3 >= 0 && d4 <= d3 {
+		return b, []r3.Vector{b}
+	}
+	vc := d1*d4 - d3*d2
+	if vc <= 0 && d1 >= 0 && d3 <= 0 {
+		return a.Add(ab.Mul(d1 / (d1 - d3))), []r3.Vector{a, b}
+	}
+	cp := p.Sub(c)
+	d5 := ab.Dot(cp)
+	d6 := ac.Dot(cp)
+	if d6 >= 0 && d5 <= d6 {
+		return c, []r3.Vector{c}
+	}
+	vb := d5*d2 - d1*d6
+	if vb <= 0 && d2 >= 0 && d6 <= 0 {
+		return a.Add(ac.Mul(d2 / (d2 - d6))), []r3.Vector{a, c}
+	}
+	va := d3*d6 - d5*d4
+	if va <= 0 && d4-d3 >= 0 && d5-d6 >= 0 {
+		return b.Add(c.Sub(b).Mul((d4 - d3) / ((d4 - d3) + (d5 - d6)))), []r3.Vector{b, c}
+	}
+	denominator := va + vb + vc
+	if denominator == 0 {
+		// the triangle is degenerate, so the closest point lies on one of its edges
+		closest, subset := closestPointSegment(p, a, b)
+		for _, edge := range [][2]r3.Vector{{b, c}, {a, c}} {
+			if edgeClosest, edgeSubset := closestPointSegment(p, edge[0], edge[1]); edgeClosest.Sub(p).Norm2() < closest.Sub(p).Norm2() {
+				closest, subset = edgeClosest, edgeSubset
+			}
+		}
+		return closest, subset
+	}
+	v := vb / denominator
+	w := vc / denominator
+	return a.Add(ab.Mul(v)).Add(ac.Mul(w)), []r3.Vector{a, b, c}
+}
+
+// closestPointTetrahedron returns the point of the tetrahedron abcd closest to p, along with the vertices of the
+// tetrahedron that are needed to express it. If p is inside the tetrahedron it is returned along with all four vertices.
+// reference: Christer Ericson, Real-Time Collision Detection, section 5.1.6.
+func closestPointTetrahedron(p, a, b, c, d r3.Vector) (r3.Vector, []r3.Vector) {
+	closest := p
+	subset := []r3.Vector{a, b, c, d}
+	best := math.Inf(1)
+	for _, face := range [][4]r3.Vector{{a, b, c, d}, {a, c, d, b}, {a, d, b, c}, {b, d, c, a}} {
+		normal := face[1].Sub(face[0]).Cross(face[2].Sub(face[0]))
+		signP := p.Sub(face[0]).Dot(normal)
+		signOpposite := face[3].Sub(face[0]).Dot(normal)
+		// only faces that p lies outside of can hold the closest point, every face is checked if the tetrahedron is flat
+		if signP*signOpposite < 0 || signOpposite == 0 {
+			faceClosest, faceSubset := closestPointTriangle(p, face[0], face[1], face[2])
+			if distance := faceClosest.Sub(p).Norm2(); distance < best {
+				closest, subset, best = faceClosest, faceSubset, distance
+			}
+		}
+	}
+	return closest, subset
+}
+
+// triangle is a convex shape used to check collisions against the faces of a mesh.
+type triangle [3]r3.Vector
+
+func (t *triangle) support(direction r3.Vector) r3.Vector {
+	furthest := t[0]
+	for _, vertex := range t[1:] {
+		if vertex.Dot(direction) > furthest.Dot(direction) {
+			furthest = vertex
+		}
+	}
+	return furthest
+}
+
+func (t *triangle) margin() float64 {
+	return 0
+}
+
+// centroid returns the center of the triangle.
+func (t *triangle) centroid() r3.Vector {
+	return t[0].Add(t[1]).Add(t[2]).Mul(1. / 3)
+}
+
+// boundingRadius returns the radius of the sphere around the centroid of the triangle that encloses it.
+func (t *triangle) boundingRadius() float64 {
+	centroid := t.centroid()
+	return math.Max(t[0].Sub(centroid).Norm(), math.Max(t[1].Sub(centroid).Norm(), t[2].Sub(centroid).Norm()))
+}
+
+// closestPoint returns the point of the triangle closest to pt.
+func (t *triangle) closestPoint(pt r3.Vector) r3.Vector {
+	closest, _ := closestPointTriangle(pt, t[0], t[1], t[2])
+	return closest
+}
+
+// rayIntersects reports whether a ray starting at origin and heading in the given direction crosses the triangle.
+// reference: https://en.wikipedia.org/wiki/M%C3%B6ller%E2%80%93Trumbore_intersection_algorithm
+func (t *triangle) rayIntersects(origin, direction r3.Vector) bool {
+	edge1 := t[1].Sub(t[0])
+	edge2 := t[2].Sub(t[0])
+	h := direction.Cross(edge2)
+	determinant := edge1.Dot(h)
+	if math.Abs(determinant) < 1e-12 {
+		return false // the ray is parallel to the triangle
+	}
+	s := origin.Sub(t[0])
+	u := s.Dot(h) / determinant
+	if u < 0 || u > 1 {
+		return false
+	}
+	q := s.Cross(edge1)
+	v := direction.Dot(q) / determinant
+	if v < 0 || u+v > 1 {
+		return false
+	}
+	return edge2.Dot(q)/determinant > 0
+}
+
+// support returns the vertex of the box furthest in the given direction.
+func (b *box) support(direction r3.Vector) r3.Vector {
+	result := b.pose.Point()
+	rm := b.pose.Orientation().RotationMatrix()
+	for i := 0; i < 3; i++ {
+		axis := rm.Row(i)
+		if axis.Dot(direction) >= 0 {
+			result = result.Add(axis.Mul(b.halfSize[i]))
+		} else {
+			result = result.Sub(axis.Mul(b.halfSize[i]))
+		}
+	}
+	return result
+}
+
+func (b *box) margin() float64 {
+	return 0
+}
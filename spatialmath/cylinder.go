@@ -0,0 +1,188 @@
+package spatialmath
+
+import (
+	"encoding/json"
+	"math"
+
+	"github.com/golang/geo/r3"
+	commonpb "go.viam.com/api/common/v1"
+
+	"go.viam.com/rdk/utils"
+)
+
+// cylinderRimPoints is the number of points along each rim of a cylinder used to check if it is encompassed by another geometry.
+const cylinderRimPoints = 32
+
+// cylinderCreator implements the GeometryCreator interface for cylinder structs.
+type cylinderCreator struct {
+	radius float64
+	length float64
+	pointCreator
+}
+
+// cylinder is a collision geometry that represents a right circular cylinder, it has a pose, a radius and a length that fully
+// define it. The cylinder is centered at its pose and its axis runs along its Z axis.
+type cylinder struct {
+	pose   Pose
+	radius float64
+	length float64
+	label  string
+
+	// axis is the unit vector along the axis of the cylinder
+	axis r3.Vector
+}
+
+// NewCylinderCreator instantiates a CylinderCreator class, which allows instantiating cylinders given only a pose which is applied
+// at the specified offset from the pose. These cylinders have a radius and length specified by the radius and length arguments.
+func NewCylinderCreator(radius, length float64, offset Pose, label string) (GeometryCreator, error) {
+	if radius <= 0 || length <= 0 {
+		return nil, newBadGeometryDimensionsError(&cylinder{})
+	}
+	return &cylinderCreator{radius, length, pointCreator{offset, label}}, nil
+}
+
+// NewGeometry instantiates a new cylinder from a CylinderCreator class.
+func (cc *cylinderCreator) NewGeometry(pose Pose) Geometry {
+	return newCylinder(Compose(cc.offset, pose), cc.radius, cc.length, cc.label)
+}
+
+func (cc *cylinderCreator) MarshalJSON() ([]byte, error) {
+	config, err := NewGeometryConfig(cc)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(config)
+}
+
+// NewCylinder instantiates a new cylinder Geometry.
+func NewCylinder(pose Pose, radius, length float64, label string) (Geometry, error) {
+	if radius < 0 || length < 0 {
+		return nil, newBadGeometryDimensionsError(&cylinder{})
+	}
+	return newCylinder(pose, radius, length, label), nil
+}
+
+func newCylinder(pose Pose, radius, length float64, label string) *cylinder {
+	return &cylinder{
+		pose:   pose,
+		radius: radius,
+		length: length,
+		label:  label,
+		axis:   pose.Orientation().RotationMatrix().Row(2),
+	}
+}
+
+// Label returns the label of this cylinder.
+func (c *cylinder) Label() string {
+	if c != nil {
+		return c.label
+	}
+	return ""
+}
+
+// Pose returns the pose of the cylinder.
+func (c *cylinder) Pose() Pose {
+	return c.pose
+}
+
+// Vertices returns points spaced evenly along both rims of the cylinder.
+func (c *cylinder) Vertices() []r3.Vector {
+	rm := c.pose.Orientation().RotationMatrix()
+	vertices := make([]r3.Vector, 0, 2*cylinderRimPoints)
+	for _, z := range []float64{c.length / 2, -c.length / 2} {
+		for i := 0; i < cylinderRimPoints; i++ {
+			theta := 2 * math.Pi * float64(i) / cylinderRimPoints
+			vertex := rm.Row(0).Mul(c.radius * math.Cos(theta)).Add(rm.Row(1).Mul(c.radius * math.Sin(theta))).Add(c.axis.Mul(z))
+			vertices = append(vertices, c.pose.Point().Add(vertex))
+		}
+	}
+	return vertices
+}
+
+// AlmostEqual compares the cylinder with another geometry and checks if they are equivalent.
+func (c *cylinder) AlmostEqual(g Geometry) bool {
+	other, ok := g.(*cylinder)
+	if !ok {
+		return false
+	}
+	return PoseAlmostEqual(c.pose, other.pose) &&
+		utils.Float64AlmostEqual(c.radius, other.radius, 1e-8) &&
+		utils.Float64AlmostEqual(c.length, other.length, 1e-8)
+}
+
+// Transform premultiplies the cylinder pose with a transform, allowing the cylinder to be moved in space.
+func (c *cylinder) Transform(toPremultiply Pose) Geometry {
+	return newCylinder(Compose(toPremultiply, c.pose), c.radius, c.length, c.label)
+}
+
+// ToProtobuf returns nil, as the API has no message for cylinders. They are not sent as the boxes that bound them, since they
+// would be checked for collisions as the larger geometries the boxes are. GeometryToProtobuf reports this as an error.
+func (c *cylinder) ToProtobuf() *commonpb.Geometry {
+	return nil
+}
+
+// CollidesWith checks if the given cylinder collides with the given geometry and returns true if it does.
+func (c *cylinder) CollidesWith(g Geometry) (bool, error) {
+	distance, err := c.DistanceFrom(g)
+	if err != nil {
+		return true, err
+	}
+	return distance <= CollisionBuffer, nil
+}
+
+// DistanceFrom returns the distance between the cylinder and the given geometry, which is negative if they are in collision.
+func (c *cylinder) DistanceFrom(g Geometry) (float64, error) {
+	switch other := g.(type) {
+	case *point:
+		return cylinderVsPointDistance(c, other.pose.Point()), nil
+	case *sphere:
+		return cylinderVsPointDistance(c, other.pose.Point()) - other.radius, nil
+	case *box:
+		return convexDistance(c, other), nil
+	case *capsule:
+		return convexDistance(c, other), nil
+	case *cylinder:
+		return convexDistance(c, other), nil
+	case *mesh:
+		return meshVsConvexDistance(other, c, c.pose.Point()), nil
+	}
+	return math.Inf(-1), newCollisionTypeUnsupportedError(c, g)
+}
+
+// EncompassedBy returns a bool describing if the given cylinder is completely encompassed by the given geometry. The rims of the
+// cylinder are sampled at a finite number of points, so a cylinder that barely pokes out of a curved geometry may be missed.
+func (c *cylinder) EncompassedBy(g Geometry) (bool, error) {
+	if _, ok := g.(*point); ok {
+		return false, nil
+	}
+	if other, ok := g.(*mesh); ok {
+		return convexInMesh(c, c.pose.Point(), other), nil
+	}
+	return pointsInGeometry(c.Vertices(), 0, g)
+}
+
+func (c *cylinder) support(direction r3.Vector) r3.Vector {
+	axial := direction.Dot(c.axis)
+	result := c.pose.Point().Add(c.axis.Mul(math.Copysign(c.length/2, axial)))
+	if radial := direction.Sub(c.axis.Mul(axial)); radial.Norm() > 1e-12 {
+		result = result.Add(radial.Normalize().Mul(c.radius))
+	}
+	return result
+}
+
+func (c *cylinder) margin() float64 {
+	return 0
+}
+
+// cylinderVsPointDistance takes a cylinder and a point as arguments and returns a floating point number. If this number is
+// nonpositive it represents the penetration depth of the point within the cylinder. If the returned float is positive it
+// represents the separation distance between the point and the cylinder, which are not in collision.
+func cylinderVsPointDistance(c *cylinder, pt r3.Vector) float64 {
+	direction := pt.Sub(c.pose.Point())
+	axial := direction.Dot(c.axis)
+	radialDistance := direction.Sub(c.axis.Mul(axial)).Norm() - c.radius
+	axialDistance := math.Abs(axial) - c.length/2
+	outside := math.Hypot(math.Max(radialDistance, 0), math.Max(axialDistance, 0))
+	inside := math.Min(math.Max(radialDistance, axialDistance), 0)
+	return outside + inside
+}
@@ -0,0 +1,56 @@
+package spatialmath
+
+import (
+	"math"
+	"testing"
+
+	"github.com/golang/geo/r3"
+	"go.viam.com/test"
+)
+
+func makeTestCylinder(o Orientation, point r3.Vector, radius, length float64, label string) Geometry {
+	cylinder, _ := NewCylinder(NewPoseFromOrientation(point, o), radius, length, label)
+	return cylinder
+}
+
+func TestNewCylinder(t *testing.T) {
+	offset := NewPoseFromOrientation(r3.Vector{X: 1, Y: 0, Z: 0}, &EulerAngles{0, math.Pi / 2, 0})
+
+	// test cylinder created from NewCylinder method
+	geometry, err := NewCylinder(offset, 1, 2, "")
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, geometry.(*cylinder).radius, test.ShouldEqual, 1)
+	test.That(t, geometry.(*cylinder).length, test.ShouldEqual, 2)
+	_, err = NewCylinder(offset, -1, 2, "")
+	test.That(t, err.Error(), test.ShouldContainSubstring, newBadGeometryDimensionsError(&cylinder{}).Error())
+	_, err = NewCylinderCreator(1, 0, offset, "")
+	test.That(t, err.Error(), test.ShouldContainSubstring, newBadGeometryDimensionsError(&cylinder{}).Error())
+
+	// test cylinder created from GeometryCreator with offset
+	gc, err := NewCylinderCreator(1, 2, offset, "")
+	test.That(t, err, test.ShouldBeNil)
+	geometry = gc.NewGeometry(PoseInverse(offset))
+	test.That(t, PoseAlmostCoincident(geometry.Pose(), NewZeroPose()), test.ShouldBeTrue)
+}
+
+func TestCylinderAlmostEqual(t *testing.T) {
+	original := makeTestCylinder(NewZeroOrientation(), r3.Vector{}, 1, 2, "")
+	good := makeTestCylinder(NewZeroOrientation(), r3.Vector{1e-16, 1e-16, 1e-16}, 1+1e-16, 2+1e-16, "")
+	bad := makeTestCylinder(NewZeroOrientation(), r3.Vector{}, 1, 2+1e-2, "")
+	test.That(t, original.AlmostEqual(good), test.ShouldBeTrue)
+	test.That(t, original.AlmostEqual(bad), test.ShouldBeFalse)
+}
+
+func TestCylinderVertices(t *testing.T) {
+	cylinder := makeTestCylinder(NewZeroOrientation(), r3.Vector{0, 0, 5}, 1, 2, "")
+	vertices := cylinder.Vertices()
+	test.That(t, vertices, test.ShouldHaveLength, 2*cylinderRimPoints)
+	for i, vertex := range vertices {
+		test.That(t, math.Hypot(vertex.X, vertex.Y), test.ShouldAlmostEqual, 1)
+		if i < cylinderRimPoints {
+			test.That(t, vertex.Z, test.ShouldAlmostEqual, 6)
+		} else {
+			test.That(t, vertex.Z, test.ShouldAlmostEqual, 4)
+		}
+	}
+}
@@ -6,6 +6,12 @@ import "github.com/pkg/errors"
 // (either implicitly or explicitly) in a GeometryConfig.
 var ErrGeometryTypeUnsupported = errors.New("unsupported Geometry type")
 
+// newGeometryProtoUnsupportedError is returned when a geometry is converted to a proto message, for which the API has no
+// message. It wraps ErrGeometryTypeUnsupported.
+func newGeometryProtoUnsupportedError(g Geometry) error {
+	return errors.Wrapf(ErrGeometryTypeUnsupported, "the API has no message for geometry %q of type %T", g.Label(), g)
+}
+
 func newBadGeometryDimensionsError(g Geometry) error {
 	return errors.Errorf("Invalid dimension(s) for Geometry type %T", g)
 }
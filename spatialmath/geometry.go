@@ -37,6 +37,9 @@ const (
 	BoxType         = GeometryType("box")
 	SphereType      = GeometryType("sphere")
 	PointType       = GeometryType("point")
+	CapsuleType     = GeometryType("capsule")
+	CylinderType    = GeometryType("cylinder")
+	MeshType        = GeometryType("mesh")
 	CollisionBuffer = 1e-8 // objects must be separated by this many mm to not be in collision
 )
 
@@ -49,9 +52,17 @@ type GeometryConfig struct {
 	Y float64 `json:"y"`
 	Z float64 `json:"z"`
 
-	// parameter used for defining a sphere's radius'
+	// parameter used for defining a sphere's radius', or the radius of a capsule or cylinder
 	R float64 `json:"r"`
 
+	// parameter used for defining the length of a capsule or cylinder along its Z axis
+	L float64 `json:"l,omitempty"`
+
+	// parameters used for defining a mesh from a STL, OBJ or PLY file, whose coordinates are multiplied by the scale (1 if unset)
+	// to convert them to millimeters
+	MeshPath  string  `json:"mesh_path,omitempty"`
+	MeshScale float64 `json:"mesh_scale,omitempty"`
+
 	// define an offset to position the geometry
 	TranslationOffset TranslationConfig `json:"translation"`
 	OrientationOffset OrientationConfig `json:"orientation"`
@@ -76,6 +87,25 @@ func NewGeometryConfig(gc GeometryCreator) (*GeometryConfig, error) {
 	case *pointCreator:
 		config.Type = PointType
 		config.Label = gc.(*pointCreator).label
+	case *capsuleCreator:
+		config.Type = CapsuleType
+		config.R = gc.(*capsuleCreator).radius
+		config.L = gc.(*capsuleCreator).length
+		config.Label = gc.(*capsuleCreator).label
+	case *cylinderCreator:
+		config.Type = CylinderType
+		config.R = gc.(*cylinderCreator).radius
+		config.L = gc.(*cylinderCreator).length
+		config.Label = gc.(*cylinderCreator).label
+	case *meshCreator:
+		// meshes built from triangles in memory have no file to refer to
+		if gc.(*meshCreator).path == "" {
+			return nil, fmt.Errorf("%w %s", ErrGeometryTypeUnsupported, "mesh without a file")
+		}
+		config.Type = MeshType
+		config.MeshPath = gc.(*meshCreator).path
+		config.MeshScale = gc.(*meshCreator).scale
+		config.Label = gc.(*meshCreator).label
 	default:
 		return nil, fmt.Errorf("%w %s", ErrGeometryTypeUnsupported, fmt.Sprintf("%T", gcType))
 	}
@@ -107,6 +137,16 @@ func (config *GeometryConfig) ParseConfig() (GeometryCreator, error) {
 		return NewSphereCreator(config.R, offset, config.Label)
 	case PointType:
 		return NewPointCreator(offset, config.Label), nil
+	case CapsuleType:
+		return NewCapsuleCreator(config.R, config.L, offset, config.Label)
+	case CylinderType:
+		return NewCylinderCreator(config.R, config.L, offset, config.Label)
+	case MeshType:
+		scale := config.MeshScale
+		if scale == 0 {
+			scale = 1
+		}
+		return NewMeshCreatorFromFile(config.MeshPath, scale, offset, config.Label)
 	case UnknownType:
 		// no type specified, iterate through supported types and try to infer intent
 		if creator, err := NewBoxCreator(r3.Vector{X: config.X, Y: config.Y, Z: config.Z}, offset, config.Label); err == nil {
@@ -120,6 +160,15 @@ func (config *GeometryConfig) ParseConfig() (GeometryCreator, error) {
 	return nil, fmt.Errorf("%w %s", ErrGeometryTypeUnsupported, string(config.Type))
 }
 
+// GeometryToProtobuf converts a Geometry to a Geometry proto message like its ToProtobuf method, but fails for geometries the API
+// has no message for, such as capsules, cylinders and meshes, whose ToProtobuf method returns nil.
+func GeometryToProtobuf(g Geometry) (*commonpb.Geometry, error) {
+	if m := g.ToProtobuf(); m != nil {
+		return m, nil
+	}
+	return nil, newGeometryProtoUnsupportedError(g)
+}
+
 // NewGeometryFromProto instantiates a new Geometry from a protobuf Geometry message.
 func NewGeometryFromProto(geometry *commonpb.Geometry) (Geometry, error) {
 	pose := NewPoseFromProtobuf(geometry.Center)
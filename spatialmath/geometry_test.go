@@ -7,6 +7,7 @@ import (
 	"testing"
 
 	"github.com/golang/geo/r3"
+	"github.com/pkg/errors"
 	commonpb "go.viam.com/api/common/v1"
 	"go.viam.com/test"
 )
@@ -34,6 +35,24 @@ func TestGeometrySerialization(t *testing.T) {
 		{"sphere bad dims", GeometryConfig{Type: "sphere", R: -1}, false},
 		{"infer sphere", GeometryConfig{R: 1, OrientationOffset: orientation, Label: "infer sphere"}, true},
 		{"point", GeometryConfig{Type: "point", TranslationOffset: translation, OrientationOffset: orientation, Label: "point"}, true},
+		{
+			"capsule",
+			GeometryConfig{Type: "capsule", R: 1, L: 4, TranslationOffset: translation, OrientationOffset: orientation, Label: "capsule"},
+			true,
+		},
+		{"capsule bad dims", GeometryConfig{Type: "capsule", R: 1, L: 1}, false},
+		{
+			"cylinder",
+			GeometryConfig{Type: "cylinder", R: 1, L: 4, TranslationOffset: translation, OrientationOffset: orientation, Label: "cylinder"},
+			true,
+		},
+		{"cylinder bad dims", GeometryConfig{Type: "cylinder", R: 1}, false},
+		{
+			"mesh",
+			GeometryConfig{Type: "mesh", MeshPath: "data/cube.stl", MeshScale: 10, TranslationOffset: translation, Label: "mesh"},
+			true,
+		},
+		{"mesh missing file", GeometryConfig{Type: "mesh", MeshPath: "data/missing.stl"}, false},
 		{"infer point", GeometryConfig{}, false},
 		{"bad type", GeometryConfig{Type: "bad"}, false},
 	}
@@ -76,6 +95,26 @@ func TestGeometryToFromProtobuf(t *testing.T) {
 			test.That(t, err, test.ShouldBeNil)
 			test.That(t, testCase.geometry.AlmostEqual(newVol), test.ShouldBeTrue)
 			test.That(t, testCase.geometry.Label(), test.ShouldEqual, testCase.name)
+			checked, err := GeometryToProtobuf(testCase.geometry)
+			test.That(t, err, test.ShouldBeNil)
+			newVol, err = NewGeometryFromProto(checked)
+			test.That(t, err, test.ShouldBeNil)
+			test.That(t, testCase.geometry.AlmostEqual(newVol), test.ShouldBeTrue)
+		})
+	}
+
+	// capsules, cylinders and meshes have no message of their own and fail the checked conversion
+	for _, g := range []Geometry{
+		makeTestCapsule(&EulerAngles{0, 0, deg45}, r3.Vector{3, 4, 5}, 1, 4, "capsule"),
+		makeTestCylinder(&EulerAngles{0, 0, deg45}, r3.Vector{3, 4, 5}, 1, 4, "cylinder"),
+		makeTestMesh(t, &EulerAngles{0, 0, deg45}, r3.Vector{3, 4, 5}, 2, "mesh"),
+	} {
+		t.Run(g.Label(), func(t *testing.T) {
+			test.That(t, g.ToProtobuf(), test.ShouldBeNil)
+			protoGeometry, err := GeometryToProtobuf(g)
+			test.That(t, protoGeometry, test.ShouldBeNil)
+			test.That(t, errors.Is(err, ErrGeometryTypeUnsupported), test.ShouldBeTrue)
+			test.That(t, err.Error(), test.ShouldContainSubstring, g.Label())
 		})
 	}
 
@@ -530,3 +569,95 @@ func TestSphereVsPointEncompassed(t *testing.T) {
 	}
 	testGeometryEncompassed(t, cases)
 }
+
+func TestCapsuleVsGeometryCollision(t *testing.T) {
+	capsule := makeTestCapsule(NewZeroOrientation(), r3.Vector{}, 1, 4, "")
+	cases := []geometryComparisonTestCase{
+		{"point separated", [2]Geometry{capsule, NewPoint(r3.Vector{0, 0, 3.5}, "")}, 1.5},
+		{"point inside", [2]Geometry{capsule, NewPoint(r3.Vector{0.5, 0, 0}, "")}, -0.5},
+		{"sphere separated", [2]Geometry{capsule, makeTestSphere(r3.Vector{0, 0, 4}, 1, "")}, 1},
+		{"box separated", [2]Geometry{capsule, makeTestBox(NewZeroOrientation(), r3.Vector{3, 0, 0}, r3.Vector{2, 2, 2}, "")}, 1},
+		{"box tangent", [2]Geometry{capsule, makeTestBox(NewZeroOrientation(), r3.Vector{2, 0, 0}, r3.Vector{2, 2, 2}, "")}, 0},
+		{"box overlap", [2]Geometry{capsule, makeTestBox(NewZeroOrientation(), r3.Vector{1.5, 0, 0}, r3.Vector{2, 2, 2}, "")}, -0.5},
+		{
+			"box edge closest",
+			[2]Geometry{capsule, makeTestBox(&EulerAngles{0, 0, math.Pi / 4}, r3.Vector{3, 0, 0}, r3.Vector{2, 2, 6}, "")},
+			2 - math.Sqrt2,
+		},
+		{"capsule crossed", [2]Geometry{capsule, makeTestCapsule(&EulerAngles{0, math.Pi / 2, 0}, r3.Vector{0, 0, 4}, 1, 4, "")}, 1},
+		{"capsule parallel", [2]Geometry{capsule, makeTestCapsule(NewZeroOrientation(), r3.Vector{1.5, 0, 1}, 1, 4, "")}, -0.5},
+		{"cylinder separated", [2]Geometry{capsule, makeTestCylinder(NewZeroOrientation(), r3.Vector{3.5, 0, 0}, 1, 2, "")}, 1.5},
+		{"mesh separated", [2]Geometry{capsule, makeTestMesh(t, NewZeroOrientation(), r3.Vector{0, 0, 4.5}, 2, "")}, 1.5},
+	}
+	testGeometryCollision(t, cases)
+}
+
+func TestCylinderVsGeometryCollision(t *testing.T) {
+	cylinder := makeTestCylinder(NewZeroOrientation(), r3.Vector{}, 1, 2, "")
+	cases := []geometryComparisonTestCase{
+		{"point above", [2]Geometry{cylinder, NewPoint(r3.Vector{0, 0, 3}, "")}, 2},
+		{"point beside", [2]Geometry{cylinder, NewPoint(r3.Vector{2, 2, 0}, "")}, 2*math.Sqrt2 - 1},
+		{"point past rim", [2]Geometry{cylinder, NewPoint(r3.Vector{2, 0, 3}, "")}, math.Sqrt(5)},
+		{"point inside", [2]Geometry{cylinder, NewPoint(r3.Vector{0, 0, 0.5}, "")}, -0.5},
+		{"sphere separated", [2]Geometry{cylinder, makeTestSphere(r3.Vector{3, 0, 0}, 1, "")}, 1},
+		{"sphere past rim", [2]Geometry{cylinder, makeTestSphere(r3.Vector{2, 0, 2}, math.Sqrt2, "")}, 0},
+		{"box above", [2]Geometry{cylinder, makeTestBox(NewZeroOrientation(), r3.Vector{0, 0, 2.5}, r3.Vector{2, 2, 2}, "")}, 0.5},
+		{
+			"box edge closest",
+			[2]Geometry{cylinder, makeTestBox(&EulerAngles{0, 0, math.Pi / 4}, r3.Vector{3, 0, 0}, r3.Vector{2, 2, 2}, "")},
+			2 - math.Sqrt2,
+		},
+		{"cylinder crossed", [2]Geometry{cylinder, makeTestCylinder(&EulerAngles{0, math.Pi / 2, 0}, r3.Vector{0, 0, 3}, 1, 2, "")}, 1},
+		{"cylinder coaxial overlap", [2]Geometry{cylinder, makeTestCylinder(NewZeroOrientation(), r3.Vector{0, 0, 1.5}, 1, 2, "")}, -0.5},
+		{"mesh separated", [2]Geometry{cylinder, makeTestMesh(t, NewZeroOrientation(), r3.Vector{3, 0, 0}, 2, "")}, 1},
+	}
+	testGeometryCollision(t, cases)
+}
+
+func TestMeshVsGeometryCollision(t *testing.T) {
+	mesh := makeTestMesh(t, NewZeroOrientation(), r3.Vector{}, 2, "")
+	cases := []geometryComparisonTestCase{
+		{"point separated", [2]Geometry{mesh, NewPoint(r3.Vector{3, 0, 0}, "")}, 2},
+		{"point inside", [2]Geometry{mesh, NewPoint(r3.Vector{0.5, 0, 0}, "")}, -0.5},
+		{"sphere separated", [2]Geometry{mesh, makeTestSphere(r3.Vector{3, 0, 0}, 1, "")}, 1},
+		{"box separated", [2]Geometry{mesh, makeTestBox(NewZeroOrientation(), r3.Vector{3, 0, 0}, r3.Vector{2, 2, 2}, "")}, 1},
+		{"box face to face contact", [2]Geometry{mesh, makeTestBox(NewZeroOrientation(), r3.Vector{2, 0, 0}, r3.Vector{2, 2, 2}, "")}, 0},
+		{"box inside", [2]Geometry{mesh, makeTestBox(NewZeroOrientation(), r3.Vector{}, r3.Vector{0.5, 0.5, 0.5}, "")}, -0.75},
+		{"mesh separated", [2]Geometry{mesh, makeTestMesh(t, &EulerAngles{0, 0, math.Pi / 4}, r3.Vector{3.3, 0, 0}, 2, "")}, 2.3 - math.Sqrt2},
+		{"mesh inside", [2]Geometry{mesh, makeTestMesh(t, NewZeroOrientation(), r3.Vector{}, 1, "")}, -0.5},
+	}
+	testGeometryCollision(t, cases)
+}
+
+func TestCurvedGeometryEncompassed(t *testing.T) {
+	capsule := makeTestCapsule(NewZeroOrientation(), r3.Vector{}, 1, 4, "")
+	cylinder := makeTestCylinder(NewZeroOrientation(), r3.Vector{}, 1, 2, "")
+	mesh := makeTestMesh(t, NewZeroOrientation(), r3.Vector{}, 2, "")
+	cases := []geometryComparisonTestCase{
+		{"capsule in box", [2]Geometry{capsule, makeTestBox(NewZeroOrientation(), r3.Vector{}, r3.Vector{2, 2, 4}, "")}, 0},
+		{"capsule not in box", [2]Geometry{capsule, makeTestBox(NewZeroOrientation(), r3.Vector{}, r3.Vector{2, 2, 3.9}, "")}, 1},
+		{"capsule in sphere", [2]Geometry{capsule, makeTestSphere(r3.Vector{}, 2, "")}, 0},
+		{"capsule not in sphere", [2]Geometry{capsule, makeTestSphere(r3.Vector{}, 1.9, "")}, 1},
+		{"capsule in cylinder", [2]Geometry{capsule, makeTestCylinder(NewZeroOrientation(), r3.Vector{}, 1, 4, "")}, 0},
+		{"capsule in mesh", [2]Geometry{capsule, makeTestMesh(t, NewZeroOrientation(), r3.Vector{}, 4.1, "")}, 0},
+		{"capsule not in mesh", [2]Geometry{capsule, makeTestMesh(t, NewZeroOrientation(), r3.Vector{}, 3.9, "")}, 1},
+		{"capsule not in point", [2]Geometry{capsule, NewPoint(r3.Vector{}, "")}, 1},
+		{"cylinder in box", [2]Geometry{cylinder, makeTestBox(NewZeroOrientation(), r3.Vector{}, r3.Vector{2, 2, 2}, "")}, 0},
+		{"cylinder in capsule", [2]Geometry{cylinder, capsule}, 0},
+		{"cylinder not in capsule", [2]Geometry{makeTestCylinder(NewZeroOrientation(), r3.Vector{}, 1, 3, ""), capsule}, 1},
+		{"cylinder in sphere", [2]Geometry{cylinder, makeTestSphere(r3.Vector{}, math.Sqrt2, "")}, 0},
+		{"cylinder in mesh", [2]Geometry{cylinder, makeTestMesh(t, NewZeroOrientation(), r3.Vector{}, 2.1, "")}, 0},
+		{"box in capsule", [2]Geometry{makeTestBox(NewZeroOrientation(), r3.Vector{}, r3.Vector{1, 1, 1}, ""), capsule}, 0},
+		{"box not in capsule", [2]Geometry{makeTestBox(NewZeroOrientation(), r3.Vector{}, r3.Vector{2, 2, 2}, ""), capsule}, 1},
+		{"box in cylinder", [2]Geometry{makeTestBox(NewZeroOrientation(), r3.Vector{}, r3.Vector{1, 1, 2}, ""), cylinder}, 0},
+		{"box in mesh", [2]Geometry{makeTestBox(NewZeroOrientation(), r3.Vector{}, r3.Vector{1, 1, 1}, ""), mesh}, 0},
+		{"sphere in capsule", [2]Geometry{makeTestSphere(r3.Vector{0, 0, 1}, 1, ""), capsule}, 0},
+		{"sphere not in cylinder", [2]Geometry{makeTestSphere(r3.Vector{0, 0, 0.5}, 1, ""), cylinder}, 0.5},
+		{"sphere in mesh", [2]Geometry{makeTestSphere(r3.Vector{}, 1, ""), mesh}, 0},
+		{"sphere not in mesh", [2]Geometry{makeTestSphere(r3.Vector{0.5, 0, 0}, 1, ""), mesh}, 0.5},
+		{"mesh in sphere", [2]Geometry{mesh, makeTestSphere(r3.Vector{}, math.Sqrt(3), "")}, 0},
+		{"mesh in mesh", [2]Geometry{makeTestMesh(t, NewZeroOrientation(), r3.Vector{}, 1, ""), mesh}, 0},
+		{"mesh not in mesh", [2]Geometry{makeTestMesh(t, NewZeroOrientation(), r3.Vector{1, 0, 0}, 1, ""), mesh}, 1},
+	}
+	testGeometryEncompassed(t, cases)
+}
@@ -0,0 +1,336 @@
+package spatialmath
+
+import (
+	"encoding/json"
+	"math"
+
+	"github.com/golang/geo/r3"
+	commonpb "go.viam.com/api/common/v1"
+)
+
+// meshRayDirection is the direction of the ray cast to check if a point is inside of a mesh, it is chosen to be unlikely to
+// graze the edges of meshes that are aligned with their axes.
+var meshRayDirection = r3.Vector{X: 0.5462, Y: 0.6391, Z: 0.5416}.Normalize()
+
+// meshCreator implements the GeometryCreator interface for mesh structs.
+type meshCreator struct {
+	data *meshData
+	pointCreator
+
+	// path and scale record the file the mesh was read from, if any, so that it can be written back to a GeometryConfig
+	path  string
+	scale float64
+}
+
+// meshData holds the triangles of a mesh in its own frame along with the box that bounds them, it is shared by every mesh
+// instantiated from the same triangles.
+type meshData struct {
+	triangles []triangle
+	center    r3.Vector
+	halfSize  r3.Vector
+}
+
+// mesh is a collision geometry that represents a closed surface made of triangles, it has a pose and a set of triangles in the
+// frame of that pose that fully define it. Meshes do not need to be convex, but they need to be closed for points to be
+// correctly classified as inside of them.
+type mesh struct {
+	pose  Pose
+	data  *meshData
+	label string
+
+	// triangles are the triangles of the mesh placed at its pose
+	triangles []triangle
+	// center and radius define the sphere that bounds the mesh
+	center r3.Vector
+	radius float64
+}
+
+// NewMeshCreator instantiates a MeshCreator class, which allows instantiating meshes given only a pose which is applied at the
+// specified offset from the pose. These meshes are made of the given triangles.
+func NewMeshCreator(triangles [][3]r3.Vector, offset Pose, label string) (GeometryCreator, error) {
+	data, err := newMeshData(triangles)
+	if err != nil {
+		return nil, err
+	}
+	return &meshCreator{data: data, pointCreator: pointCreator{offset, label}}, nil
+}
+
+// NewMeshCreatorFromFile instantiates a MeshCreator class from the triangles of a STL, OBJ or PLY file, with every coordinate of
+// the file multiplied by scale to convert it to millimeters.
+func NewMeshCreatorFromFile(path string, scale float64, offset Pose, label string) (GeometryCreator, error) {
+	if scale <= 0 {
+		return nil, newBadGeometryDimensionsError(&mesh{})
+	}
+	triangles, err := ReadMeshFile(path)
+	if err != nil {
+		return nil, err
+	}
+	for i := range triangles {
+		for j := range triangles[i] {
+			triangles[i][j] = triangles[i][j].Mul(scale)
+		}
+	}
+	data, err := newMeshData(triangles)
+	if err != nil {
+		return nil, err
+	}
+	return &meshCreator{data: data, pointCreator: pointCreator{offset, label}, path: path, scale: scale}, nil
+}
+
+// NewGeometry instantiates a new mesh from a MeshCreator class.
+func (mc *meshCreator) NewGeometry(pose Pose) Geometry {
+	return newMesh(Compose(mc.offset, pose), mc.data, mc.label)
+}
+
+func (mc *meshCreator) MarshalJSON() ([]byte, error) {
+	config, err := NewGeometryConfig(mc)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(config)
+}
+
+// NewMesh instantiates a new mesh Geometry made of the given triangles, which are expressed in the frame of the given pose.
+func NewMesh(pose Pose, triangles [][3]r3.Vector, label string) (Geometry, error) {
+	data, err := newMeshData(triangles)
+	if err != nil {
+		return nil, err
+	}
+	return newMesh(pose, data, label), nil
+}
+
+func newMeshData(triangles [][3]r3.Vector) (*meshData, error) {
+	if len(triangles) == 0 {
+		return nil, newBadGeometryDimensionsError(&mesh{})
+	}
+	data := &meshData{triangles: make([]triangle, len(triangles))}
+	min := r3.Vector{X: math.Inf(1), Y: math.Inf(1), Z: math.Inf(1)}
+	max := r3.Vector{X: math.Inf(-1), Y: math.Inf(-1), Z: math.Inf(-1)}
+	for i, t := range triangles {
+		data.triangles[i] = t
+		for _, vertex := range t {
+			min = r3.Vector{X: math.Min(min.X, vertex.X), Y: math.Min(min.Y, vertex.Y), Z: math.Min(min.Z, vertex.Z)}
+			max = r3.Vector{X: math.Max(max.X, vertex.X), Y: math.Max(max.Y, vertex.Y), Z: math.Max(max.Z, vertex.Z)}
+		}
+	}
+	data.center = min.Add(max).Mul(0.5)
+	data.halfSize = max.Sub(min).Mul(0.5)
+	return data, nil
+}
+
+func newMesh(pose Pose, data *meshData, label string) *mesh {
+	m := &mesh{
+		pose:      pose,
+		data:      data,
+		label:     label,
+		triangles: make([]triangle, len(data.triangles)),
+		center:    Compose(pose, NewPoseFromPoint(data.center)).Point(),
+		radius:    data.halfSize.Norm(),
+	}
+	rm := pose.Orientation().RotationMatrix()
+	for i, t := range data.triangles {
+		for j, vertex := range t {
+			m.triangles[i][j] = pose.Point().Add(rm.Row(0).Mul(vertex.X)).Add(rm.Row(1).Mul(vertex.Y)).Add(rm.Row(2).Mul(vertex.Z))
+		}
+	}
+	return m
+}
+
+// Label returns the label of this mesh.
+func (m *mesh) Label() string {
+	if m != nil {
+		return m.label
+	}
+	return ""
+}
+
+// Pose returns the pose of the mesh.
+func (m *mesh) Pose() Pose {
+	return m.pose
+}
+
+// Vertices returns the distinct vertices of the triangles of the mesh.
+func (m *mesh) Vertices() []r3.Vector {
+	seen := make(map[r3.Vector]bool)
+	vertices := make([]r3.Vector, 0, len(m.triangles))
+	for _, t := range m.triangles {
+		for _, vertex := range t {
+			if !seen[vertex] {
+				seen[vertex] = true
+				vertices = append(vertices, vertex)
+			}
+		}
+	}
+	return vertices
+}
+
+// AlmostEqual compares the mesh with another geometry and checks if they are equivalent.
+func (m *mesh) AlmostEqual(g Geometry) bool {
+	other, ok := g.(*mesh)
+	if !ok || len(m.data.triangles) != len(other.data.triangles) || !PoseAlmostEqual(m.pose, other.pose) {
+		return false
+	}
+	for i, t := range m.data.triangles {
+		for j, vertex := range t {
+			if !R3VectorAlmostEqual(vertex, other.data.triangles[i][j], 1e-8) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// Transform premultiplies the mesh pose with a transform, allowing the mesh to be moved in space.
+func (m *mesh) Transform(toPremultiply Pose) Geometry {
+	return newMesh(Compose(toPremultiply, m.pose), m.data, m.label)
+}
+
+// ToProtobuf returns nil, as the API has no message for meshes. They are not sent as the boxes that bound them, since they
+// would be checked for collisions as the larger geometries the boxes are. GeometryToProtobuf reports this as an error.
+func (m *mesh) ToProtobuf() *commonpb.Geometry {
+	return nil
+}
+
+// CollidesWith checks if the given mesh collides with the given geometry and returns true if it does.
+func (m *mesh) CollidesWith(g Geometry) (bool, error) {
+	distance, err := m.DistanceFrom(g)
+	if err != nil {
+		return true, err
+	}
+	return distance <= CollisionBuffer, nil
+}
+
+// DistanceFrom returns the distance between the mesh and the given geometry, which is negative if they are in collision.
+func (m *mesh) DistanceFrom(g Geometry) (float64, error) {
+	switch other := g.(type) {
+	case *point:
+		return meshVsPointDistance(m, other.pose.Point()), nil
+	case *sphere:
+		return meshVsPointDistance(m, other.pose.Point()) - other.radius, nil
+	case *box:
+		return meshVsConvexDistance(m, other, other.pose.Point()), nil
+	case *capsule:
+		return meshVsConvexDistance(m, other, other.pose.Point()), nil
+	case *cylinder:
+		return meshVsConvexDistance(m, other, other.pose.Point()), nil
+	case *mesh:
+		return meshVsMeshDistance(m, other), nil
+	}
+	return math.Inf(-1), newCollisionTypeUnsupportedError(m, g)
+}
+
+// EncompassedBy returns a bool describing if the given mesh is completely encompassed by the given geometry.
+func (m *mesh) EncompassedBy(g Geometry) (bool, error) {
+	switch other := g.(type) {
+	case *point:
+		return false, nil
+	case *mesh:
+		return meshInMesh(m, other), nil
+	}
+	return pointsInGeometry(m.Vertices(), 0, g)
+}
+
+// contains reports whether the given point is inside of the mesh, by counting how many of its triangles a ray leaving the point
+// crosses.
+func (m *mesh) contains(pt r3.Vector) bool {
+	if pt.Sub(m.center).Norm() > m.radius {
+		return false
+	}
+	crossings := 0
+	for i := range m.triangles {
+		if m.triangles[i].rayIntersects(pt, meshRayDirection) {
+			crossings++
+		}
+	}
+	return crossings%2 == 1
+}
+
+// surfaceDistance returns the distance from the given point to the closest triangle of the mesh.
+func (m *mesh) surfaceDistance(pt r3.Vector) float64 {
+	min := math.Inf(1)
+	for i := range m.triangles {
+		if distance := m.triangles[i].closestPoint(pt).Sub(pt).Norm(); distance < min {
+			min = distance
+		}
+	}
+	return min
+}
+
+// meshVsPointDistance takes a mesh and a point as arguments and returns a floating point number. If this number is nonpositive it
+// represents the penetration depth of the point within the mesh. If the returned float is positive it represents the separation
+// distance between the point and the mesh, which are not in collision.
+func meshVsPointDistance(m *mesh, pt r3.Vector) float64 {
+	distance := m.surfaceDistance(pt)
+	if m.contains(pt) {
+		return -distance
+	}
+	return distance
+}
+
+// meshVsConvexDistance takes a mesh and a convex shape whose core contains the given center as arguments and returns a floating
+// point number. If this number is nonpositive it represents an estimate of the penetration depth for the two geometries, which
+// are in collision. If the returned float is positive it represents the separation distance for the two geometries, which are
+// not in collision.
+func meshVsConvexDistance(m *mesh, c convex, center r3.Vector) float64 {
+	min := math.Inf(1)
+	for i := range m.triangles {
+		if distance := convexDistance(&m.triangles[i], c); distance < min {
+			min = distance
+		}
+	}
+	// a shape that does not touch the surface of the mesh may still be wholly inside of it
+	if min > CollisionBuffer && m.contains(center) {
+		return -min
+	}
+	return min
+}
+
+// meshVsMeshDistance takes two meshes as arguments and returns a floating point number. If this number is nonpositive it
+// represents an estimate of the penetration depth for the two meshes, which are in collision. If the returned float is positive
+// it represents a lower bound on the separation distance for the two meshes, which are not in collision.
+func meshVsMeshDistance(a, b *mesh) float64 {
+	// check if there is a distance between bounding spheres to potentially exit early
+	if boundingSphereDist := a.center.Sub(b.center).Norm() - a.radius - b.radius; boundingSphereDist > CollisionBuffer {
+		return boundingSphereDist
+	}
+	min := math.Inf(1)
+	for i := range a.triangles {
+		centroidA := a.triangles[i].centroid()
+		radiusA := a.triangles[i].boundingRadius()
+		for j := range b.triangles {
+			// skip triangles whose bounding spheres are further apart than the closest triangles found so far
+			if centroidA.Sub(b.triangles[j].centroid()).Norm()-radiusA-b.triangles[j].boundingRadius() >= min {
+				continue
+			}
+			if distance := convexDistance(&a.triangles[i], &b.triangles[j]); distance < min {
+				min = distance
+			}
+		}
+	}
+	// a mesh that does not touch the surface of the other may still be wholly inside of it
+	if min > CollisionBuffer && (a.contains(b.triangles[0][0]) || b.contains(a.triangles[0][0])) {
+		return -min
+	}
+	return min
+}
+
+// convexInMesh returns a bool describing if the given convex shape, whose core contains the given center, is completely
+// encompassed by the given mesh.
+func convexInMesh(c convex, center r3.Vector, m *mesh) bool {
+	for i := range m.triangles {
+		if convexDistance(&m.triangles[i], c) <= CollisionBuffer {
+			return false
+		}
+	}
+	return m.contains(center)
+}
+
+// meshInMesh returns a bool describing if the inner mesh is completely encompassed by the outer mesh.
+func meshInMesh(inner, outer *mesh) bool {
+	for i := range inner.triangles {
+		if !convexInMesh(&inner.triangles[i], inner.triangles[i][0], outer) {
+			return false
+		}
+	}
+	return true
+}
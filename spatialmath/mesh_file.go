@@ -0,0 +1,337 @@
+package spatialmath
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/golang/geo/r3"
+	"github.com/pkg/errors"
+)
+
+// ReadMeshFile reads the triangles of a mesh from a STL (ASCII or binary), OBJ or PLY (ASCII or binary) file, in the units of
+// the file. Faces with more than three vertices are split into triangles that fan out from their first vertex.
+func ReadMeshFile(path string) ([][3]r3.Vector, error) {
+	//nolint:gosec
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var triangles [][3]r3.Vector
+	switch ext := strings.ToLower(filepath.Ext(path)); ext {
+	case ".stl":
+		triangles, err = parseSTL(data)
+	case ".obj":
+		triangles, err = parseOBJ(data)
+	case ".ply":
+		triangles, err = parsePLY(data)
+	default:
+		return nil, errors.Errorf("unsupported mesh file extension %q", ext)
+	}
+	if err != nil {
+		return nil, errors.Wrapf(err, "cannot read mesh file %q", path)
+	}
+	if len(triangles) == 0 {
+		return nil, errors.Errorf("mesh file %q has no faces", path)
+	}
+	return triangles, nil
+}
+
+// parseSTL reads the triangles of a STL file, which is binary unless it starts with "solid" and its size does not match that of a
+// binary file (some exporters start binary headers with "solid" as well).
+// reference: https://en.wikipedia.org/wiki/STL_(file_format)
+func parseSTL(data []byte) ([][3]r3.Vector, error) {
+	const headerSize, triangleSize = 84, 50
+	if len(data) >= headerSize {
+		count := int(binary.LittleEndian.Uint32(data[80:headerSize]))
+		if len(data) == headerSize+count*triangleSize || !bytes.HasPrefix(bytes.TrimSpace(data), []byte("solid")) {
+			if len(data) < headerSize+count*triangleSize {
+				return nil, errors.Errorf("binary STL holds %d bytes but declares %d triangles", len(data), count)
+			}
+			triangles := make([][3]r3.Vector, count)
+			for i := range triangles {
+				// skip the normal at the start of each triangle
+				offset := headerSize + i*triangleSize + 12
+				for j := range triangles[i] {
+					triangles[i][j] = r3.Vector{
+						X: float64(math.Float32frombits(binary.LittleEndian.Uint32(data[offset+12*j:]))),
+						Y: float64(math.Float32frombits(binary.LittleEndian.Uint32(data[offset+12*j+4:]))),
+						Z: float64(math.Float32frombits(binary.LittleEndian.Uint32(data[offset+12*j+8:]))),
+					}
+				}
+			}
+			return triangles, nil
+		}
+	}
+
+	var triangles [][3]r3.Vector
+	var face []r3.Vector
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 {
+			continue
+		}
+		switch fields[0] {
+		case "outer":
+			face = face[:0]
+		case "vertex":
+			vertex, err := parseMeshVector(fields[1:])
+			if err != nil {
+				return nil, err
+			}
+			face = append(face, vertex)
+		case "endloop":
+			triangles = append(triangles, fanTriangles(face)...)
+		}
+	}
+	return triangles, scanner.Err()
+}
+
+// parseOBJ reads the triangles of the faces of an OBJ file, ignoring texture coordinates and normals.
+// reference: https://en.wikipedia.org/wiki/Wavefront_.obj_file
+func parseOBJ(data []byte) ([][3]r3.Vector, error) {
+	var vertices []r3.Vector
+	var triangles [][3]r3.Vector
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 {
+			continue
+		}
+		switch fields[0] {
+		case "v":
+			vertex, err := parseMeshVector(fields[1:])
+			if err != nil {
+				return nil, err
+			}
+			vertices = append(vertices, vertex)
+		case "f":
+			face := make([]r3.Vector, 0, len(fields)-1)
+			for _, field := range fields[1:] {
+				// vertices of faces may be given as index/texture/normal
+				index, err := strconv.Atoi(strings.Split(field, "/")[0])
+				if err != nil {
+					return nil, err
+				}
+				// indices start at 1, negative indices count back from the last vertex read
+				if index < 0 {
+					index += len(vertices) + 1
+				}
+				if index < 1 || index > len(vertices) {
+					return nil, errors.Errorf("face refers to vertex %s which does not exist", field)
+				}
+				face = append(face, vertices[index-1])
+			}
+			triangles = append(triangles, fanTriangles(face)...)
+		}
+	}
+	return triangles, scanner.Err()
+}
+
+// plyProperty is a property of an element of a PLY file, list properties hold a count of type countType followed by that many
+// values of type valueType.
+type plyProperty struct {
+	name      string
+	valueType string
+	countType string
+}
+
+// plyElement is an element of a PLY file along with the number of times it occurs.
+type plyElement struct {
+	name       string
+	count      int
+	properties []plyProperty
+}
+
+// parsePLY reads the triangles of the faces of a PLY file.
+// reference: http://paulbourke.net/dataformats/ply/
+func parsePLY(data []byte) ([][3]r3.Vector, error) {
+	reader := bufio.NewReader(bytes.NewReader(data))
+	var format string
+	var elements []*plyElement
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			return nil, errors.Wrap(err, "PLY header does not end")
+		}
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+		switch fields[0] {
+		case "format":
+			if len(fields) < 2 {
+				return nil, errors.New("PLY format is missing")
+			}
+			format = fields[1]
+		case "element":
+			if len(fields) < 3 {
+				return nil, errors.Errorf("bad PLY element %q", strings.TrimSpace(line))
+			}
+			count, err := strconv.Atoi(fields[2])
+			if err != nil {
+				return nil, err
+			}
+			elements = append(elements, &plyElement{name: fields[1], count: count})
+		case "property":
+			if len(elements) == 0 {
+				return nil, errors.New("PLY property precedes any element")
+			}
+			element := elements[len(elements)-1]
+			switch {
+			case len(fields) == 5 && fields[1] == "list":
+				element.properties = append(element.properties, plyProperty{name: fields[4], valueType: fields[3], countType: fields[2]})
+			case len(fields) == 3:
+				element.properties = append(element.properties, plyProperty{name: fields[2], valueType: fields[1]})
+			default:
+				return nil, errors.Errorf("bad PLY property %q", strings.TrimSpace(line))
+			}
+		}
+		if fields[0] == "end_header" {
+			break
+		}
+	}
+
+	var read func(valueType string) (float64, error)
+	switch format {
+	case "ascii":
+		scanner := bufio.NewScanner(reader)
+		scanner.Split(bufio.ScanWords)
+		read = func(string) (float64, error) {
+			if !scanner.Scan() {
+				if err := scanner.Err(); err != nil {
+					return 0, err
+				}
+				return 0, io.ErrUnexpectedEOF
+			}
+			return strconv.ParseFloat(scanner.Text(), 64)
+		}
+	case "binary_little_endian":
+		read = func(valueType string) (float64, error) { return readPLYBinary(reader, binary.LittleEndian, valueType) }
+	case "binary_big_endian":
+		read = func(valueType string) (float64, error) { return readPLYBinary(reader, binary.BigEndian, valueType) }
+	default:
+		return nil, errors.Errorf("unsupported PLY format %q", format)
+	}
+
+	var vertices []r3.Vector
+	var triangles [][3]r3.Vector
+	for _, element := range elements {
+		for i := 0; i < element.count; i++ {
+			var vertex r3.Vector
+			var face []r3.Vector
+			for _, property := range element.properties {
+				if property.countType == "" {
+					value, err := read(property.valueType)
+					if err != nil {
+						return nil, err
+					}
+					switch property.name {
+					case "x":
+						vertex.X = value
+					case "y":
+						vertex.Y = value
+					case "z":
+						vertex.Z = value
+					}
+					continue
+				}
+				count, err := read(property.countType)
+				if err != nil {
+					return nil, err
+				}
+				for j := 0; j < int(count); j++ {
+					value, err := read(property.valueType)
+					if err != nil {
+						return nil, err
+					}
+					if element.name != "face" || (property.name != "vertex_indices" && property.name != "vertex_index") {
+						continue
+					}
+					if int(value) < 0 || int(value) >= len(vertices) {
+						return nil, errors.Errorf("face refers to vertex %d which does not exist", int(value))
+					}
+					face = append(face, vertices[int(value)])
+				}
+			}
+			switch element.name {
+			case "vertex":
+				vertices = append(vertices, vertex)
+			case "face":
+				triangles = append(triangles, fanTriangles(face)...)
+			}
+		}
+	}
+	return triangles, nil
+}
+
+// readPLYBinary reads a single value of the given PLY type from a binary PLY file.
+func readPLYBinary(reader io.Reader, order binary.ByteOrder, valueType string) (float64, error) {
+	var err error
+	switch valueType {
+	case "char", "int8":
+		var v int8
+		err = binary.Read(reader, order, &v)
+		return float64(v), err
+	case "uchar", "uint8":
+		var v uint8
+		err = binary.Read(reader, order, &v)
+		return float64(v), err
+	case "short", "int16":
+		var v int16
+		err = binary.Read(reader, order, &v)
+		return float64(v), err
+	case "ushort", "uint16":
+		var v uint16
+		err = binary.Read(reader, order, &v)
+		return float64(v), err
+	case "int", "int32":
+		var v int32
+		err = binary.Read(reader, order, &v)
+		return float64(v), err
+	case "uint", "uint32":
+		var v uint32
+		err = binary.Read(reader, order, &v)
+		return float64(v), err
+	case "float", "float32":
+		var v float32
+		err = binary.Read(reader, order, &v)
+		return float64(v), err
+	case "double", "float64":
+		var v float64
+		err = binary.Read(reader, order, &v)
+		return v, err
+	}
+	return 0, errors.Errorf("unsupported PLY type %q", valueType)
+}
+
+// parseMeshVector parses the first three of the given fields as the coordinates of a vector.
+func parseMeshVector(fields []string) (r3.Vector, error) {
+	if len(fields) < 3 {
+		return r3.Vector{}, errors.Errorf("expected 3 coordinates but got %d", len(fields))
+	}
+	var coordinates [3]float64
+	for i := range coordinates {
+		var err error
+		if coordinates[i], err = strconv.ParseFloat(fields[i], 64); err != nil {
+			return r3.Vector{}, err
+		}
+	}
+	return r3.Vector{X: coordinates[0], Y: coordinates[1], Z: coordinates[2]}, nil
+}
+
+// fanTriangles splits a convex polygon into triangles that fan out from its first vertex.
+func fanTriangles(face []r3.Vector) [][3]r3.Vector {
+	var triangles [][3]r3.Vector
+	for i := 2; i < len(face); i++ {
+		triangles = append(triangles, [3]r3.Vector{face[0], face[i-1], face[i]})
+	}
+	return triangles
+}
@@ -0,0 +1,97 @@
+package spatialmath
+
+import (
+	"encoding/binary"
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/golang/geo/r3"
+	"go.viam.com/test"
+)
+
+// makeTestMesh returns the cube that is 2 units wide on each side read from data/cube.stl, scaled by the given size.
+func makeTestMesh(t *testing.T, o Orientation, point r3.Vector, size float64, label string) Geometry {
+	t.Helper()
+	gc, err := NewMeshCreatorFromFile("data/cube.stl", size/2, NewZeroPose(), label)
+	test.That(t, err, test.ShouldBeNil)
+	return gc.NewGeometry(NewPoseFromOrientation(point, o))
+}
+
+func TestNewMesh(t *testing.T) {
+	offset := NewPoseFromOrientation(r3.Vector{X: 1, Y: 0, Z: 0}, &EulerAngles{0, 0, math.Pi})
+	triangles := [][3]r3.Vector{{{0, 0, 0}, {1, 0, 0}, {0, 1, 0}}}
+
+	// test mesh created from NewMesh method
+	geometry, err := NewMesh(offset, triangles, "")
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, geometry.Vertices(), test.ShouldHaveLength, 3)
+	test.That(t, R3VectorAlmostEqual(geometry.Vertices()[1], r3.Vector{0, 0, 0}, 1e-8), test.ShouldBeTrue)
+	_, err = NewMesh(offset, nil, "")
+	test.That(t, err.Error(), test.ShouldContainSubstring, newBadGeometryDimensionsError(&mesh{}).Error())
+
+	// test mesh created from GeometryCreator with offset
+	gc, err := NewMeshCreator(triangles, offset, "")
+	test.That(t, err, test.ShouldBeNil)
+	geometry = gc.NewGeometry(PoseInverse(offset))
+	test.That(t, PoseAlmostCoincident(geometry.Pose(), NewZeroPose()), test.ShouldBeTrue)
+
+	// meshes that were not read from a file cannot be written to a config
+	_, err = gc.MarshalJSON()
+	test.That(t, err.Error(), test.ShouldContainSubstring, ErrGeometryTypeUnsupported.Error())
+}
+
+func TestMeshAlmostEqual(t *testing.T) {
+	original := makeTestMesh(t, NewZeroOrientation(), r3.Vector{}, 2, "")
+	good := makeTestMesh(t, NewZeroOrientation(), r3.Vector{1e-16, 1e-16, 1e-16}, 2, "")
+	bad := makeTestMesh(t, NewZeroOrientation(), r3.Vector{}, 2.01, "")
+	test.That(t, original.AlmostEqual(good), test.ShouldBeTrue)
+	test.That(t, original.AlmostEqual(bad), test.ShouldBeFalse)
+}
+
+func TestMeshVertices(t *testing.T) {
+	vertices := makeTestMesh(t, NewZeroOrientation(), r3.Vector{2, 2, 2}, 2, "").Vertices()
+	test.That(t, vertices, test.ShouldHaveLength, 8)
+	for _, vertex := range vertices {
+		test.That(t, math.Abs(vertex.X-2), test.ShouldAlmostEqual, 1)
+		test.That(t, math.Abs(vertex.Y-2), test.ShouldAlmostEqual, 1)
+		test.That(t, math.Abs(vertex.Z-2), test.ShouldAlmostEqual, 1)
+	}
+}
+
+func TestReadMeshFile(t *testing.T) {
+	// write the cube as a binary STL as well
+	ascii, err := ReadMeshFile("data/cube.stl")
+	test.That(t, err, test.ShouldBeNil)
+	data := make([]byte, 84, 84+50*len(ascii))
+	binary.LittleEndian.PutUint32(data[80:], uint32(len(ascii)))
+	for _, t := range ascii {
+		data = append(data, make([]byte, 12)...)
+		for _, vertex := range t {
+			for _, coordinate := range []float64{vertex.X, vertex.Y, vertex.Z} {
+				data = binary.LittleEndian.AppendUint32(data, math.Float32bits(float32(coordinate)))
+			}
+		}
+		data = append(data, 0, 0)
+	}
+	binarySTL := filepath.Join(t.TempDir(), "cube.stl")
+	test.That(t, os.WriteFile(binarySTL, data, 0o600), test.ShouldBeNil)
+
+	for _, path := range []string{"data/cube.stl", binarySTL, "data/cube.obj", "data/cube.ply"} {
+		t.Run(path, func(t *testing.T) {
+			triangles, err := ReadMeshFile(path)
+			test.That(t, err, test.ShouldBeNil)
+			test.That(t, triangles, test.ShouldHaveLength, 12)
+			m, err := NewMesh(NewZeroPose(), triangles, "")
+			test.That(t, err, test.ShouldBeNil)
+			test.That(t, m.(*mesh).contains(r3.Vector{0.5, -0.2, 0.1}), test.ShouldBeTrue)
+			test.That(t, m.(*mesh).contains(r3.Vector{1.5, -0.2, 0.1}), test.ShouldBeFalse)
+		})
+	}
+
+	_, err = ReadMeshFile("data/orientations.json")
+	test.That(t, err, test.ShouldNotBeNil)
+	_, err = ReadMeshFile("data/missing.stl")
+	test.That(t, err, test.ShouldNotBeNil)
+}
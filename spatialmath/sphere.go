@@ -112,6 +112,15 @@ func (s *sphere) CollidesWith(g Geometry) (bool, error) {
 	if other, ok := g.(*point); ok {
 		return sphereVsPointDistance(s, other.pose.Point()) <= CollisionBuffer, nil
 	}
+	if other, ok := g.(*capsule); ok {
+		return other.CollidesWith(s)
+	}
+	if other, ok := g.(*cylinder); ok {
+		return other.CollidesWith(s)
+	}
+	if other, ok := g.(*mesh); ok {
+		return other.CollidesWith(s)
+	}
 	return true, newCollisionTypeUnsupportedError(s, g)
 }
 
@@ -126,6 +135,15 @@ func (s *sphere) DistanceFrom(g Geometry) (float64, error) {
 	if other, ok := g.(*point); ok {
 		return sphereVsPointDistance(s, other.pose.Point()), nil
 	}
+	if other, ok := g.(*capsule); ok {
+		return other.DistanceFrom(s)
+	}
+	if other, ok := g.(*cylinder); ok {
+		return other.DistanceFrom(s)
+	}
+	if other, ok := g.(*mesh); ok {
+		return other.DistanceFrom(s)
+	}
 	return math.Inf(-1), newCollisionTypeUnsupportedError(s, g)
 }
 
@@ -139,6 +157,15 @@ func (s *sphere) EncompassedBy(g Geometry) (bool, error) {
 	if _, ok := g.(*point); ok {
 		return false, nil
 	}
+	if _, ok := g.(*capsule); ok {
+		return pointsInGeometry(s.Vertices(), s.radius, g)
+	}
+	if _, ok := g.(*cylinder); ok {
+		return pointsInGeometry(s.Vertices(), s.radius, g)
+	}
+	if other, ok := g.(*mesh); ok {
+		return meshVsPointDistance(other, s.pose.Point()) <= -s.radius, nil
+	}
 	return true, newCollisionTypeUnsupportedError(s, g)
 }
 
@@ -0,0 +1,43 @@
+package testutils
+
+import (
+	"testing"
+	"time"
+
+	v1 "go.viam.com/api/app/datasync/v1"
+	"go.viam.com/test"
+	"go.viam.com/utils/protoutils"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"go.viam.com/rdk/services/datamanager/datacapture"
+)
+
+// WriteCaptureFile writes a data capture file of the readings with the metadata to dir and returns its path.
+func WriteCaptureFile(t *testing.T, dir string, md *v1.DataCaptureMetadata, readings ...*v1.SensorData) string {
+	t.Helper()
+	f, err := datacapture.NewFile(dir, md)
+	test.That(t, err, test.ShouldBeNil)
+	for _, reading := range readings {
+		test.That(t, f.WriteNext(reading), test.ShouldBeNil)
+	}
+	test.That(t, f.Close(), test.ShouldBeNil)
+	return f.GetPath()
+}
+
+// TabularReading returns the reading, a map or a struct, as captured at the given time.
+func TabularReading(t *testing.T, captured time.Time, reading interface{}) *v1.SensorData {
+	t.Helper()
+	pbReading, err := protoutils.StructToStructPb(reading)
+	test.That(t, err, test.ShouldBeNil)
+	return &v1.SensorData{Metadata: capturedAt(captured), Data: &v1.SensorData_Struct{Struct: pbReading}}
+}
+
+// BinaryReading returns the binary reading as captured at the given time.
+func BinaryReading(captured time.Time, reading []byte) *v1.SensorData {
+	return &v1.SensorData{Metadata: capturedAt(captured), Data: &v1.SensorData_Binary{Binary: reading}}
+}
+
+func capturedAt(captured time.Time) *v1.SensorMetadata {
+	ts := timestamppb.New(captured)
+	return &v1.SensorMetadata{TimeRequested: ts, TimeReceived: ts}
+}
@@ -0,0 +1,60 @@
+package testutils
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/edaniels/golog"
+	v1 "go.viam.com/api/app/datasync/v1"
+	"go.viam.com/test"
+	"go.viam.com/utils/testutils"
+	"google.golang.org/protobuf/types/known/anypb"
+
+	"go.viam.com/rdk/data"
+	"go.viam.com/rdk/services/datamanager/datacapture"
+)
+
+// CaptureReading constructs the collector registered for method on resource, lets it capture until it wrote a
+// reading and returns the first reading it wrote. The error is that of constructing the collector.
+func CaptureReading(
+	t *testing.T,
+	method data.MethodMetadata,
+	resource interface{},
+	methodParams map[string]*anypb.Any,
+) (*v1.SensorData, error) {
+	t.Helper()
+	constructor := data.CollectorLookup(method)
+	test.That(t, constructor, test.ShouldNotBeNil)
+
+	target, err := datacapture.NewFile(t.TempDir(), &v1.DataCaptureMetadata{})
+	test.That(t, err, test.ShouldBeNil)
+	empty := target.Size()
+	collector, err := (*constructor)(resource, data.CollectorParams{
+		ComponentName: "test",
+		Interval:      time.Millisecond * 10,
+		MethodParams:  methodParams,
+		Target:        target,
+		QueueSize:     10,
+		BufferSize:    10,
+		Logger:        golog.NewTestLogger(t),
+	})
+	if err != nil {
+		return nil, err
+	}
+	collector.Collect()
+	testutils.WaitForAssertion(t, func(tb testing.TB) {
+		tb.Helper()
+		test.That(tb, target.Size(), test.ShouldBeGreaterThan, empty)
+	})
+	collector.Close()
+
+	f, err := os.Open(target.GetPath())
+	test.That(t, err, test.ShouldBeNil)
+	defer f.Close()
+	written, err := datacapture.ReadFile(f)
+	test.That(t, err, test.ShouldBeNil)
+	reading, err := written.ReadNext()
+	test.That(t, err, test.ShouldBeNil)
+	return reading, nil
+}
@@ -3,6 +3,7 @@ package inject
 import (
 	"context"
 
+	"github.com/golang/geo/r3"
 	"go.viam.com/utils"
 
 	"go.viam.com/rdk/components/base"
@@ -14,6 +15,7 @@ type Base struct {
 	DoFunc           func(ctx context.Context, cmd map[string]interface{}) (map[string]interface{}, error)
 	MoveStraightFunc func(ctx context.Context, distanceMm int, mmPerSec float64, extra map[string]interface{}) error
 	SpinFunc         func(ctx context.Context, angleDeg, degsPerSec float64, extra map[string]interface{}) error
+	SetVelocityFunc  func(ctx context.Context, linear, angular r3.Vector, extra map[string]interface{}) error
 	WidthFunc        func(ctx context.Context) (int, error)
 	StopFunc         func(ctx context.Context, extra map[string]interface{}) error
 	IsMovingFunc     func(context.Context) (bool, error)
@@ -36,6 +38,14 @@ func (b *Base) Spin(ctx context.Context, angleDeg, degsPerSec float64, extra map
 	return b.SpinFunc(ctx, angleDeg, degsPerSec, extra)
 }
 
+// SetVelocity calls the injected SetVelocity or the real version.
+func (b *Base) SetVelocity(ctx context.Context, linear, angular r3.Vector, extra map[string]interface{}) error {
+	if b.SetVelocityFunc == nil {
+		return b.LocalBase.SetVelocity(ctx, linear, angular, extra)
+	}
+	return b.SetVelocityFunc(ctx, linear, angular, extra)
+}
+
 // Width calls the injected Width or the real version.
 func (b *Base) Width(ctx context.Context) (int, error) {
 	if b.WidthFunc == nil {
@@ -3,6 +3,8 @@ package inject
 import (
 	"context"
 
+	"github.com/pkg/errors"
+
 	"go.viam.com/rdk/services/datamanager"
 )
 
@@ -10,7 +12,9 @@ import (
 // service.
 type DataManagerService struct {
 	datamanager.Service
-	SyncFunc func(ctx context.Context, extra map[string]interface{}) error
+	SyncFunc            func(ctx context.Context, extra map[string]interface{}) error
+	TriggerCaptureFunc  func(ctx context.Context, name string) error
+	EvictionMetricsFunc func(ctx context.Context) (datamanager.EvictionMetrics, error)
 }
 
 // Sync calls the injected Sync or the real variant.
@@ -20,3 +24,27 @@ func (svc *DataManagerService) Sync(ctx context.Context, extra map[string]interf
 	}
 	return svc.SyncFunc(ctx, extra)
 }
+
+// TriggerCapture calls the injected TriggerCapture or the real variant.
+func (svc *DataManagerService) TriggerCapture(ctx context.Context, name string) error {
+	if svc.TriggerCaptureFunc == nil {
+		triggerer, ok := svc.Service.(datamanager.CaptureTriggerer)
+		if !ok {
+			return errors.New("data manager cannot trigger captures")
+		}
+		return triggerer.TriggerCapture(ctx, name)
+	}
+	return svc.TriggerCaptureFunc(ctx, name)
+}
+
+// EvictionMetrics calls the injected EvictionMetrics or the real variant.
+func (svc *DataManagerService) EvictionMetrics(ctx context.Context) (datamanager.EvictionMetrics, error) {
+	if svc.EvictionMetricsFunc == nil {
+		reporter, ok := svc.Service.(datamanager.EvictionReporter)
+		if !ok {
+			return datamanager.EvictionMetrics{}, errors.New("data manager cannot report evictions")
+		}
+		return reporter.EvictionMetrics(ctx)
+	}
+	return svc.EvictionMetricsFunc(ctx)
+}
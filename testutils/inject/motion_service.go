@@ -28,6 +28,19 @@ type MotionService struct {
 		supplementalTransforms []*commonpb.Transform,
 		extra map[string]interface{},
 	) (*referenceframe.PoseInFrame, error)
+	PlanFunc func(
+		ctx context.Context,
+		componentName resource.Name,
+		destination *referenceframe.PoseInFrame,
+		worldState *commonpb.WorldState,
+		extra map[string]interface{},
+	) (*motion.Plan, error)
+	CheckPlanFunc func(
+		ctx context.Context,
+		plan *motion.Plan,
+		worldState *commonpb.WorldState,
+		extra map[string]interface{},
+	) error
 }
 
 // Move calls the injected Move or the real variant.
@@ -71,3 +84,30 @@ func (mgs *MotionService) GetPose(
 	}
 	return mgs.GetPoseFunc(ctx, componentName, destinationFrame, supplementalTransforms, extra)
 }
+
+// Plan calls the injected Plan or the real variant.
+func (mgs *MotionService) Plan(
+	ctx context.Context,
+	componentName resource.Name,
+	destination *referenceframe.PoseInFrame,
+	worldState *commonpb.WorldState,
+	extra map[string]interface{},
+) (*motion.Plan, error) {
+	if mgs.PlanFunc == nil {
+		return mgs.Service.Plan(ctx, componentName, destination, worldState, extra)
+	}
+	return mgs.PlanFunc(ctx, componentName, destination, worldState, extra)
+}
+
+// CheckPlan calls the injected CheckPlan or the real variant.
+func (mgs *MotionService) CheckPlan(
+	ctx context.Context,
+	plan *motion.Plan,
+	worldState *commonpb.WorldState,
+	extra map[string]interface{},
+) error {
+	if mgs.CheckPlanFunc == nil {
+		return mgs.Service.CheckPlan(ctx, plan, worldState, extra)
+	}
+	return mgs.CheckPlanFunc(ctx, plan, worldState, extra)
+}
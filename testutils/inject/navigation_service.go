@@ -20,6 +20,13 @@ type NavigationService struct {
 	WaypointsFunc      func(ctx context.Context, extra map[string]interface{}) ([]navigation.Waypoint, error)
 	AddWaypointFunc    func(ctx context.Context, point *geo.Point, extra map[string]interface{}) error
 	RemoveWaypointFunc func(ctx context.Context, id primitive.ObjectID, extra map[string]interface{}) error
+
+	ZonesFunc      func(ctx context.Context, extra map[string]interface{}) ([]navigation.Zone, error)
+	AddZoneFunc    func(ctx context.Context, zoneType navigation.ZoneType, vertices []*geo.Point, extra map[string]interface{}) error
+	RemoveZoneFunc func(ctx context.Context, id primitive.ObjectID, extra map[string]interface{}) error
+
+	AddCoverageFunc      func(ctx context.Context, area navigation.CoverageArea, extra map[string]interface{}) ([]navigation.Waypoint, error)
+	CoverageProgressFunc func(ctx context.Context, extra map[string]interface{}) (navigation.CoverageProgress, error)
 }
 
 // Mode calls the injected ModeFunc or the real version.
@@ -69,3 +76,52 @@ func (ns *NavigationService) RemoveWaypoint(ctx context.Context, id primitive.Ob
 	}
 	return ns.RemoveWaypointFunc(ctx, id, extra)
 }
+
+// Zones calls the injected ZonesFunc or the real version.
+func (ns *NavigationService) Zones(ctx context.Context, extra map[string]interface{}) ([]navigation.Zone, error) {
+	if ns.ZonesFunc == nil {
+		return ns.Service.Zones(ctx, extra)
+	}
+	return ns.ZonesFunc(ctx, extra)
+}
+
+// AddZone calls the injected AddZoneFunc or the real version.
+func (ns *NavigationService) AddZone(
+	ctx context.Context,
+	zoneType navigation.ZoneType,
+	vertices []*geo.Point,
+	extra map[string]interface{},
+) error {
+	if ns.AddZoneFunc == nil {
+		return ns.Service.AddZone(ctx, zoneType, vertices, extra)
+	}
+	return ns.AddZoneFunc(ctx, zoneType, vertices, extra)
+}
+
+// RemoveZone calls the injected RemoveZoneFunc or the real version.
+func (ns *NavigationService) RemoveZone(ctx context.Context, id primitive.ObjectID, extra map[string]interface{}) error {
+	if ns.RemoveZoneFunc == nil {
+		return ns.Service.RemoveZone(ctx, id, extra)
+	}
+	return ns.RemoveZoneFunc(ctx, id, extra)
+}
+
+// AddCoverage calls the injected AddCoverageFunc or the real version.
+func (ns *NavigationService) AddCoverage(
+	ctx context.Context,
+	area navigation.CoverageArea,
+	extra map[string]interface{},
+) ([]navigation.Waypoint, error) {
+	if ns.AddCoverageFunc == nil {
+		return ns.Service.AddCoverage(ctx, area, extra)
+	}
+	return ns.AddCoverageFunc(ctx, area, extra)
+}
+
+// CoverageProgress calls the injected CoverageProgressFunc or the real version.
+func (ns *NavigationService) CoverageProgress(ctx context.Context, extra map[string]interface{}) (navigation.CoverageProgress, error) {
+	if ns.CoverageProgressFunc == nil {
+		return ns.Service.CoverageProgress(ctx, extra)
+	}
+	return ns.CoverageProgressFunc(ctx, extra)
+}